@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// backupOutput is the directory backup archives are written to
+	backupOutput string
+	// restoreYes restores without asking for confirmation
+	restoreYes bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Archive the SSH config and the sshm data",
+	Long: `Save the SSH config, the files it includes and the sshm data (history,
+app config, per-host settings) into a timestamped tar.gz archive.
+
+Archives are written to ~/.config/sshm/backups unless --output is given,
+and can be brought back with 'sshm restore'.
+
+Examples:
+  sshm backup               # Archive into ~/.config/sshm/backups
+  sshm backup --output ~/   # Archive into the home directory`,
+	Args: cobra.NoArgs,
+	RunE: runBackup,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore the files of an archive made by 'sshm backup'",
+	Long: `Write the files saved by 'sshm backup' back in place, overwriting the
+current ones. The files are listed and confirmation is asked first.
+
+Examples:
+  sshm restore ~/.config/sshm/backups/sshm-backup-20250101-120000.tar.gz
+  sshm restore backup.tar.gz --yes   # Restore without confirmation`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	entries, err := config.BackupEntries(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to list the files to back up: %w", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("nothing to back up")
+	}
+
+	outputDir := backupOutput
+	if outputDir == "" {
+		if outputDir, err = config.GetSSHMBackupDir(); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputDir, err)
+	}
+
+	// The archive holds the whole SSH config, keep it private
+	archivePath := filepath.Join(outputDir, config.BackupArchiveName(time.Now()))
+	file, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := config.WriteBackupArchive(file, entries); err != nil {
+		file.Close()
+		os.Remove(archivePath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, entry := range entries {
+		fmt.Fprintf(out, "  %s\n", entry.Path)
+	}
+	fmt.Fprintf(out, "✅ Backed up %d file(s) to %s\n", len(entries), archivePath)
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	entries, err := config.ListBackupArchive(args[0])
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("%s holds no files", args[0])
+	}
+
+	out := cmd.OutOrStdout()
+	if !restoreYes && !confirmRestore(cmd.InOrStdin(), out, entries) {
+		fmt.Fprintln(out, "Restore cancelled.")
+		return nil
+	}
+
+	restored, err := config.RestoreBackupArchive(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✅ Restored %d file(s) from %s\n", len(restored), args[0])
+	return nil
+}
+
+// confirmRestore lists the files a restore overwrites and asks to go on
+func confirmRestore(in io.Reader, out io.Writer, entries []config.BackupEntry) bool {
+	fmt.Fprintln(out, "The following files will be overwritten:")
+	for _, entry := range entries {
+		fmt.Fprintf(out, "  %s\n", entry.Path)
+	}
+	fmt.Fprint(out, "Restore them? [y/N]: ")
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	RootCmd.AddCommand(backupCmd)
+	RootCmd.AddCommand(restoreCmd)
+
+	backupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "Directory to write the archive to (default ~/.config/sshm/backups)")
+	restoreCmd.Flags().BoolVarP(&restoreYes, "yes", "y", false, "Restore without asking for confirmation")
+}
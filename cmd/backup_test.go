@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	sshConfig := filepath.Join(home, ".ssh", "config")
+	if err := os.MkdirAll(filepath.Dir(sshConfig), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sshConfig, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		backupOutput = ""
+		restoreYes = false
+		RootCmd.SetIn(nil)
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	run := func(stdin string, args ...string) string {
+		t.Helper()
+		out := new(bytes.Buffer)
+		RootCmd.SetIn(strings.NewReader(stdin))
+		RootCmd.SetOut(out)
+		RootCmd.SetArgs(args)
+		if err := RootCmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return out.String()
+	}
+
+	outputDir := t.TempDir()
+	out := run("", "backup", "--output", outputDir)
+	match := regexp.MustCompile(`Backed up 1 file\(s\) to (\S+)`).FindStringSubmatch(out)
+	if match == nil {
+		t.Fatalf("Expected the archive path, got %q", out)
+	}
+	archive := match[1]
+	if info, err := os.Stat(archive); err != nil || info.Mode().Perm() != 0600 || filepath.Dir(archive) != outputDir {
+		t.Fatalf("Expected a private archive in %s, got %s (%v)", outputDir, archive, err)
+	}
+
+	if err := os.WriteFile(sshConfig, []byte("Host changed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out = run("n\n", "restore", archive)
+	if !strings.Contains(out, sshConfig) || !strings.Contains(out, "Restore cancelled") {
+		t.Errorf("Expected the files to be listed and the restore cancelled, got %q", out)
+	}
+	if content, _ := os.ReadFile(sshConfig); string(content) != "Host changed\n" {
+		t.Error("Expected nothing to be restored without confirmation")
+	}
+
+	out = run("y\n", "restore", archive)
+	if !strings.Contains(out, "Restored 1 file(s)") {
+		t.Errorf("Expected the restore to succeed, got %q", out)
+	}
+	if content, _ := os.ReadFile(sshConfig); string(content) != "Host web\n    HostName web.example.com\n" {
+		t.Errorf("Expected the config to be restored, got %q", content)
+	}
+}
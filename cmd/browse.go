@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// browsePrint prints the selected remote path instead of only browsing
+	browsePrint bool
+	// browseDirs selects a directory rather than a file with --print
+	browseDirs bool
+	// browseNewFile selects a directory, then asks for the name of a new file in it
+	browseNewFile bool
+)
+
+// runRemoteBrowser opens the remote browser; replaced in tests
+var runRemoteBrowser = ui.RunRemoteBrowser
+
+var browseCmd = &cobra.Command{
+	Use:   "browse <host> [path]",
+	Short: "Browse the files of a host",
+	Long: `Open the remote file browser on a host, without starting a transfer.
+
+By default the browser is navigate-only. With --print, selecting a file (or a
+directory with --dirs) prints its path to stdout, for use in scripts. With
+--new-file, pressing s in a directory (or Enter on a file, to start from its
+name) asks for a file name, and the path of that new file is printed.
+
+Examples:
+  # Look around the home directory of a host
+  sshm browse myhost
+
+  # Start in a given directory
+  sshm browse myhost /var/log
+
+  # Pick a remote file and use its path
+  less "$(sshm browse --print myhost /var/log)"
+
+  # Pick a remote directory
+  sshm browse --print --dirs myhost
+
+  # Choose where a file that does not exist yet goes
+  scp report.pdf "myhost:$(sshm browse --print --new-file myhost)"`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runBrowse,
+}
+
+func runBrowse(cmd *cobra.Command, args []string) error {
+	hostName := args[0]
+
+	if browseDirs && !browsePrint {
+		return fmt.Errorf("--dirs selects a directory to print and requires --print")
+	}
+	if browseNewFile && !browsePrint {
+		return fmt.Errorf("--new-file selects a path to print and requires --print")
+	}
+	if browseNewFile && browseDirs {
+		return fmt.Errorf("--new-file and --dirs cannot be used together")
+	}
+
+	// Verify the host exists
+	var hostExists bool
+	var err error
+	if configFile != "" {
+		hostExists, err = config.QuickHostExistsInFile(hostName, configFile)
+	} else {
+		hostExists, err = config.QuickHostExists(hostName)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking SSH config: %w", err)
+	}
+	if !hostExists {
+		return fmt.Errorf("host '%s' not found in SSH configuration", hostName)
+	}
+
+	startPath := ""
+	if len(args) == 2 {
+		startPath = args[1]
+	}
+
+	mode := ui.BrowseNavigate
+	if browsePrint {
+		mode = ui.BrowseFiles
+		if browseDirs {
+			mode = ui.BrowseDirectories
+		} else if browseNewFile {
+			mode = ui.BrowseSaveAs
+		}
+	}
+
+	path, selected, err := runRemoteBrowser(hostName, startPath, configFile, mode)
+	if err != nil {
+		return fmt.Errorf("remote browser error: %w", err)
+	}
+	if !browsePrint {
+		return nil
+	}
+	if !selected {
+		return fmt.Errorf("nothing selected")
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), path)
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(browseCmd)
+
+	browseCmd.Flags().BoolVar(&browsePrint, "print", false, "Print the selected remote path to stdout")
+	browseCmd.Flags().BoolVar(&browseDirs, "dirs", false, "Select a directory instead of a file (with --print)")
+	browseCmd.Flags().BoolVar(&browseNewFile, "new-file", false, "Select a directory and type the name of a new file in it (with --print)")
+}
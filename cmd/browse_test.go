@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/ui"
+)
+
+func TestBrowseCommandRegistration(t *testing.T) {
+	found := false
+	for _, cmd := range RootCmd.Commands() {
+		if cmd.Name() == "browse" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Browse command not found in root command")
+	}
+}
+
+func TestBrowseCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type call struct {
+		host, startPath, configFile string
+		mode                        ui.BrowserMode
+	}
+	var got call
+	runRemoteBrowser = func(host, startPath, configFile string, mode ui.BrowserMode) (string, bool, error) {
+		got = call{host, startPath, configFile, mode}
+		return "/var/log/app.log", true, nil
+	}
+
+	defer func() {
+		runRemoteBrowser = ui.RunRemoteBrowser
+		browsePrint, browseDirs, browseNewFile = false, false, false
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	tests := []struct {
+		name   string
+		args   []string
+		mode   ui.BrowserMode
+		start  string
+		output string
+	}{
+		{
+			name: "navigate only",
+			args: []string{"browse", "-c", sshConfig, "web"},
+			mode: ui.BrowseNavigate,
+		},
+		{
+			name:   "print selected file",
+			args:   []string{"browse", "-c", sshConfig, "--print", "web", "/var/log"},
+			mode:   ui.BrowseFiles,
+			start:  "/var/log",
+			output: "/var/log/app.log\n",
+		},
+		{
+			name:   "print selected directory",
+			args:   []string{"browse", "-c", sshConfig, "--print", "--dirs", "web"},
+			mode:   ui.BrowseDirectories,
+			output: "/var/log/app.log\n",
+		},
+		{
+			name:   "print new file path",
+			args:   []string{"browse", "-c", sshConfig, "--print", "--new-file", "web"},
+			mode:   ui.BrowseSaveAs,
+			output: "/var/log/app.log\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			browsePrint, browseDirs, browseNewFile = false, false, false
+			got = call{}
+			out := new(bytes.Buffer)
+			RootCmd.SetOut(out)
+			RootCmd.SetArgs(tt.args)
+
+			if err := RootCmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			want := call{"web", tt.start, sshConfig, tt.mode}
+			if got != want {
+				t.Errorf("RunRemoteBrowser called with %+v, want %+v", got, want)
+			}
+			if out.String() != tt.output {
+				t.Errorf("Output = %q, want %q", out.String(), tt.output)
+			}
+		})
+	}
+
+	browsePrint, browseDirs, browseNewFile = false, false, false
+	RootCmd.SetArgs([]string{"browse", "-c", sshConfig, "--dirs", "web"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected --dirs without --print to fail")
+	}
+
+	browsePrint, browseDirs, browseNewFile = false, false, false
+	RootCmd.SetArgs([]string{"browse", "-c", sshConfig, "--print", "--dirs", "--new-file", "web"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected --new-file with --dirs to fail")
+	}
+
+	browsePrint, browseDirs, browseNewFile = false, false, false
+	RootCmd.SetArgs([]string{"browse", "-c", sshConfig, "missing"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected an unknown host to fail")
+	}
+
+	browsePrint, browseDirs, browseNewFile = false, false, false
+	runRemoteBrowser = func(string, string, string, ui.BrowserMode) (string, bool, error) {
+		return "", false, nil
+	}
+	RootCmd.SetArgs([]string{"browse", "-c", sshConfig, "--print", "web"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected --print to fail when nothing was selected")
+	}
+}
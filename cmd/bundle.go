@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
+
+	"github.com/spf13/cobra"
+)
+
+// bundleVersion is the format of the files written by sshm export
+const bundleVersion = 1
+
+var (
+	// bundleOut is the file sshm export writes to, stdout when empty
+	bundleOut string
+	// bundleWithHistory adds the connection and transfer history to the bundle
+	bundleWithHistory bool
+	// bundleOnConflict is what sshm import does with hosts that already exist
+	bundleOnConflict string
+)
+
+// hostBundle is the portable set of hosts written by sshm export
+type hostBundle struct {
+	Version  int          `json:"version"`
+	Exported time.Time    `json:"exported"`
+	Hosts    []bundleHost `json:"hosts"`
+}
+
+// bundleHost is a host of a bundle: its config block, and the bookmarks and
+// history sshm recorded for it
+type bundleHost struct {
+	Name          string                  `json:"name"`
+	Hostname      string                  `json:"hostname,omitempty"`
+	User          string                  `json:"user,omitempty"`
+	Port          string                  `json:"port,omitempty"`
+	IdentityFile  string                  `json:"identity_file,omitempty"`
+	ProxyJump     string                  `json:"proxy_jump,omitempty"`
+	RemoteCommand string                  `json:"remote_command,omitempty"`
+	RequestTTY    string                  `json:"request_tty,omitempty"`
+	Options       []string                `json:"options,omitempty"` // Other directives, as "Key value"
+	Tags          []string                `json:"tags,omitempty"`
+	Data          *history.ConnectionInfo `json:"data,omitempty"`
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the hosts to a portable JSON bundle",
+	Long: `Write every host of the SSH config, with its settings and tags, to a JSON
+bundle that 'sshm import' reads on another machine. The remote directories
+bookmarked in the file browser come along; --with-history adds the
+connection and transfer history.
+
+The bundle holds paths to identity files, not the keys themselves.
+
+Examples:
+  sshm export --out hosts.json
+  sshm export --with-history --out hosts.json
+  sshm export -c ~/.ssh/work.conf > work.json`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Add the hosts of a bundle made by 'sshm export' to the SSH config",
+	Long: `Add the hosts of a bundle written by 'sshm export' to the SSH config, and
+merge their bookmarks into the sshm data. --with-history merges the
+connection and transfer history too, when the bundle has it.
+
+For each host that already exists, sshm asks whether to skip it, replace it
+or import it under another name; --on-conflict skip or replace decides for
+all of them.
+
+Examples:
+  sshm import hosts.json
+  sshm import hosts.json --with-history
+  sshm import hosts.json --on-conflict skip -c ~/.ssh/work.conf`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	var hosts []config.SSHHost
+	var err error
+	if configFile != "" {
+		hosts, err = config.ParseSSHConfigFile(configFile)
+	} else {
+		hosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read SSH config: %w", err)
+	}
+
+	// The history is optional: without it, the bundle has no bookmarks
+	historyManager, _ := history.NewHistoryManager()
+	bundle := hostBundle{Version: bundleVersion, Exported: time.Now().UTC(), Hosts: []bundleHost{}}
+	for _, host := range hosts {
+		entry := bundleHostFrom(host)
+		if historyManager != nil {
+			if data, ok := historyManager.HostData(host.Name, bundleWithHistory); ok {
+				entry.Data = &data
+			}
+		}
+		bundle.Hosts = append(bundle.Hosts, entry)
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if bundleOut == "" {
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+	// The bundle describes the whole SSH config, keep it private
+	if err := os.WriteFile(bundleOut, data, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "✅ Exported %d host(s) to %s\n", len(bundle.Hosts), bundleOut)
+	return nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	switch bundleOnConflict {
+	case "ask", "skip", "replace":
+	default:
+		return fmt.Errorf("unknown --on-conflict %q, use ask, skip or replace", bundleOnConflict)
+	}
+
+	bundle, err := readHostBundle(args[0])
+	if err != nil {
+		return err
+	}
+
+	targetFile := configFile
+	if targetFile == "" {
+		if targetFile, err = config.GetDefaultSSHConfigPath(); err != nil {
+			return err
+		}
+	}
+	existing, err := config.ParseSSHConfigFile(targetFile)
+	if err != nil {
+		return fmt.Errorf("failed to read SSH config: %w", err)
+	}
+	sources := make(map[string]string)
+	for _, host := range existing {
+		sources[host.Name] = host.SourceFile
+	}
+
+	historyManager, err := history.NewHistoryManager()
+	if err != nil {
+		return fmt.Errorf("could not open connection history: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	in := bufio.NewReader(cmd.InOrStdin())
+	var added, replaced, skipped int
+	for _, entry := range bundle.Hosts {
+		host := entry.sshHost()
+		if !validation.ValidateHostName(host.Name) {
+			fmt.Fprintf(out, "Skipping %q: not a valid host name\n", host.Name)
+			skipped++
+			continue
+		}
+
+		action := "add"
+		if source, exists := sources[host.Name]; exists {
+			action = bundleOnConflict
+			if action == "ask" {
+				action, host.Name = askImportConflict(in, out, host.Name, sources)
+			}
+			switch action {
+			case "skip":
+				skipped++
+				continue
+			case "replace":
+				if err := config.UpdateSSHHostInFile(host.Name, host, source); err != nil {
+					return fmt.Errorf("failed to replace %s: %w", host.Name, err)
+				}
+				replaced++
+			}
+		}
+		if action != "replace" {
+			if err := config.AddSSHHostToFile(host, targetFile); err != nil {
+				return fmt.Errorf("failed to add %s: %w", host.Name, err)
+			}
+			sources[host.Name] = targetFile
+			added++
+		}
+
+		if entry.Data != nil && (bundleWithHistory || len(entry.Data.RemoteBookmarks) > 0) {
+			data := *entry.Data
+			if !bundleWithHistory {
+				data = history.ConnectionInfo{RemoteBookmarks: data.RemoteBookmarks}
+			}
+			if err := historyManager.MergeHostData(host.Name, data); err != nil {
+				return fmt.Errorf("failed to import the history of %s: %w", host.Name, err)
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "✅ Imported %s: %d added, %d replaced, %d skipped\n", args[0], added, replaced, skipped)
+	return nil
+}
+
+// readHostBundle reads a bundle written by sshm export
+func readHostBundle(path string) (*hostBundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bundle hostBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("%s is not a bundle made by 'sshm export': %w", path, err)
+	}
+	if bundle.Version < 1 || bundle.Version > bundleVersion {
+		return nil, fmt.Errorf("%s has bundle version %d, this sshm reads version %d", path, bundle.Version, bundleVersion)
+	}
+	return &bundle, nil
+}
+
+// askImportConflict asks what to do with an imported host whose name is
+// taken: skip it, replace the existing one, or add it under a new name. The
+// action and the name to use are returned.
+func askImportConflict(in *bufio.Reader, out io.Writer, name string, sources map[string]string) (string, string) {
+	for {
+		fmt.Fprintf(out, "Host %q already exists in %s: [s]kip, [r]eplace or [n]ew name? [s]: ", name, sources[name])
+		answer, err := in.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "r", "replace":
+			return "replace", name
+		case "n", "new":
+			fmt.Fprint(out, "New name: ")
+			newName, _ := in.ReadString('\n')
+			newName = strings.TrimSpace(newName)
+			if _, taken := sources[newName]; taken || !validation.ValidateHostName(newName) {
+				fmt.Fprintf(out, "%q cannot be used\n", newName)
+				continue
+			}
+			return "add", newName
+		case "", "s", "skip":
+			return "skip", name
+		}
+		if err != nil {
+			return "skip", name
+		}
+	}
+}
+
+// bundleHostFrom returns the bundle entry of a host, without its bookmarks
+func bundleHostFrom(host config.SSHHost) bundleHost {
+	entry := bundleHost{
+		Name:          host.Name,
+		Hostname:      host.Hostname,
+		User:          host.User,
+		Port:          host.Port,
+		IdentityFile:  host.Identity,
+		ProxyJump:     host.ProxyJump,
+		RemoteCommand: host.RemoteCommand,
+		RequestTTY:    host.RequestTTY,
+		Tags:          host.Tags,
+	}
+	for _, option := range strings.Split(host.Options, "\n") {
+		if option = strings.TrimSpace(option); option != "" {
+			entry.Options = append(entry.Options, option)
+		}
+	}
+	return entry
+}
+
+// sshHost returns the host to write to the SSH config. Without a HostName,
+// ssh connects to the alias itself, which is what gets written.
+func (e bundleHost) sshHost() config.SSHHost {
+	host := config.SSHHost{
+		Name:          e.Name,
+		Hostname:      e.Hostname,
+		User:          e.User,
+		Port:          e.Port,
+		Identity:      e.IdentityFile,
+		ProxyJump:     e.ProxyJump,
+		RemoteCommand: e.RemoteCommand,
+		RequestTTY:    e.RequestTTY,
+		Options:       strings.Join(e.Options, "\n"),
+		Tags:          e.Tags,
+	}
+	if host.Hostname == "" {
+		host.Hostname = host.Name
+	}
+	return host
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(importCmd)
+
+	exportCmd.Flags().StringVarP(&bundleOut, "out", "o", "", "File to write the bundle to (default: stdout)")
+	exportCmd.Flags().BoolVar(&bundleWithHistory, "with-history", false, "Include the connection and transfer history")
+	importCmd.Flags().BoolVar(&bundleWithHistory, "with-history", false, "Merge the connection and transfer history of the bundle")
+	importCmd.Flags().StringVar(&bundleOnConflict, "on-conflict", "ask", "What to do with hosts that already exist (ask, skip, replace)")
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+)
+
+// useHome points HOME and the sshm data to a new temporary directory, as on
+// another machine
+func useHome(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	return dir
+}
+
+func TestExportImportBundle(t *testing.T) {
+	defer func() {
+		bundleOut = ""
+		bundleWithHistory = false
+		bundleOnConflict = "ask"
+		configFile = ""
+		RootCmd.SetIn(nil)
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+	run := func(stdin string, args ...string) string {
+		t.Helper()
+		bundleOut, bundleWithHistory, bundleOnConflict = "", false, "ask"
+		out := new(bytes.Buffer)
+		RootCmd.SetIn(strings.NewReader(stdin))
+		RootCmd.SetOut(out)
+		RootCmd.SetArgs(args)
+		if err := RootCmd.Execute(); err != nil {
+			t.Fatalf("sshm %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	// First machine: two hosts, a bookmark and a connection
+	home := useHome(t)
+	sshConfig := filepath.Join(home, "ssh_config")
+	content := `# Tags: prod
+Host web
+    HostName 10.0.0.1
+    User deploy
+    Port 2222
+    ServerAliveInterval 30
+
+Host db
+    HostName db.internal
+`
+	if err := os.WriteFile(sshConfig, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordRemoteBookmark("web", "/var/www"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordConnection("db"); err != nil {
+		t.Fatal(err)
+	}
+
+	bundlePath := filepath.Join(home, "hosts.json")
+	run("", "export", "--out", bundlePath, "--config", sshConfig)
+	bundle, err := readHostBundle(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Hosts) != 2 {
+		t.Fatalf("Expected 2 hosts in the bundle, got %+v", bundle.Hosts)
+	}
+	web := bundle.Hosts[0]
+	if web.Name != "web" || web.Port != "2222" || !reflect.DeepEqual(web.Options, []string{"ServerAliveInterval 30"}) ||
+		!reflect.DeepEqual(web.Tags, []string{"prod"}) || web.Data == nil || len(web.Data.RemoteBookmarks) != 1 {
+		t.Errorf("Unexpected bundle entry for web: %+v", web)
+	}
+	if bundle.Hosts[1].Data != nil {
+		t.Errorf("Expected no history for db without --with-history, got %+v", bundle.Hosts[1].Data)
+	}
+	if info, _ := os.Stat(bundlePath); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a private bundle, got %v", info.Mode().Perm())
+	}
+
+	// With history, on stdout
+	var withHistory hostBundle
+	out := run("", "export", "--with-history", "--config", sshConfig)
+	if err := json.Unmarshal([]byte(out), &withHistory); err != nil {
+		t.Fatal(err)
+	}
+	if data := withHistory.Hosts[1].Data; data == nil || data.ConnectCount != 1 {
+		t.Errorf("Expected the connections of db with --with-history, got %+v", data)
+	}
+
+	// Second machine, where web already exists: import it as web2
+	home = useHome(t)
+	target := filepath.Join(home, "config")
+	if err := os.WriteFile(target, []byte("Host web\n    HostName other.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out = run("n\nweb2\n", "import", bundlePath, "--config", target)
+	if !strings.Contains(out, `Host "web" already exists`) || !strings.Contains(out, "2 added, 0 replaced, 0 skipped") {
+		t.Errorf("Unexpected import output %q", out)
+	}
+
+	hosts, err := config.ParseSSHConfigFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]config.SSHHost)
+	for _, host := range hosts {
+		byName[host.Name] = host
+	}
+	if byName["web"].Hostname != "other.example.com" {
+		t.Errorf("Expected the existing web kept, got %+v", byName["web"])
+	}
+	if web2 := byName["web2"]; web2.Hostname != "10.0.0.1" || web2.Port != "2222" || web2.Options != "ServerAliveInterval 30" || len(web2.Tags) != 1 {
+		t.Errorf("Expected web imported as web2, got %+v", web2)
+	}
+	if byName["db"].Hostname != "db.internal" {
+		t.Errorf("Expected db imported, got %+v", byName["db"])
+	}
+	hm, err = history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hm.GetRemoteBookmarks("web2"); !reflect.DeepEqual(got, []string{"/var/www"}) {
+		t.Errorf("Expected the bookmarks imported for web2, got %v", got)
+	}
+
+	// Importing again replaces every host without asking
+	out = run("", "import", bundlePath, "--config", target, "--on-conflict", "replace")
+	if !strings.Contains(out, "0 added, 2 replaced, 0 skipped") {
+		t.Errorf("Unexpected import output %q", out)
+	}
+	if host, err := config.GetSSHHostFromFile("web", target); err != nil || host.Hostname != "10.0.0.1" {
+		t.Errorf("Expected web replaced, got %+v, %v", host, err)
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/atotto/clipboard"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// configCopy copies the resolved configuration to the clipboard
+	configCopy bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config <hostname>",
+	Short: "Show the fully resolved SSH configuration for a host",
+	Long: `Show the fully resolved SSH options for a host, as computed by 'ssh -G'.
+This is useful for debugging which values win when several config blocks match.
+
+Examples:
+  sshm config myserver         # Print all resolved options
+  sshm config myserver --copy  # Also copy them to the clipboard`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := transfer.RequireBinary("ssh"); err != nil {
+			return err
+		}
+
+		options, err := config.GetResolvedConfig(args[0], configFile)
+		if err != nil {
+			return err
+		}
+
+		output := config.FormatResolvedConfig(options)
+		fmt.Print(output)
+
+		if configCopy {
+			if err := clipboard.WriteAll(output); err != nil {
+				return fmt.Errorf("failed to copy to clipboard: %w", err)
+			}
+			fmt.Println("Copied to clipboard.")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.Flags().BoolVar(&configCopy, "copy", false, "Copy the resolved configuration to the clipboard")
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/history"
@@ -14,8 +19,32 @@ import (
 
 var (
 	cpRecursive bool
+	// scpArgs holds extra arguments passed through to scp via --scp-arg
+	scpArgs []string
+	// cpUser and cpPort override the SSH config user and port for one copy
+	cpUser string
+	cpPort string
+	// printCommand prints the scp command instead of running it
+	printCommand bool
+	// cpRetryLast re-runs the last attempted transfer, successful or not
+	cpRetryLast bool
+	// cpRsync copies with rsync instead of scp, when rsync is installed
+	cpRsync bool
+	// getResume continues an interrupted download instead of restarting it
+	getResume bool
+	// onExists is what a download does when its local destination exists
+	onExists string
+	// getOpen downloads to a temporary directory and opens the file instead
+	getOpen bool
+	// cpConcurrency limits how many hosts a comma-separated destination uploads to at once
+	cpConcurrency int
+	// transferLimit throttles cp, send and get to this many KB/s, unlimited when zero
+	transferLimit int
 )
 
+// executeBatch runs one upload of a multi-host copy; replaced in tests
+var executeBatch = (*transfer.TransferRequest).ExecuteBatch
+
 var cpCmd = &cobra.Command{
 	Use:   "cp <source> <destination>",
 	Short: "Copy files to/from SSH hosts",
@@ -34,10 +63,40 @@ Examples:
   # Upload a directory (recursive)
   sshm cp -r ./my-folder myhost:/remote/path/
 
+  # Override the user and port from the SSH config for this copy
+  sshm cp --user deploy --port 2222 ./app.tar.gz myhost:/srv/
+
+  # Print the scp command instead of running it
+  sshm cp --print-command ./app.tar.gz myhost:/srv/
+
+  # Copy a large directory tree with rsync (falls back to scp without rsync)
+  sshm cp --rsync -r ./site myhost:/var/www/
+
+  # Throttle the copy to 500 KB/s on a shared link
+  sshm cp --limit 500 ./backup.tar.gz myhost:/srv/
+
+  # Re-run the last transfer, e.g. after it failed
+  sshm cp --retry-last
+
+  # Upload to several hosts at once, two at a time
+  sshm cp -j 2 ./app.conf web1,web2,web3:/etc/app/
+
   # Interactive mode (opens transfer UI)
   sshm cp myhost`,
-	Args: cobra.RangeArgs(1, 2),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cpRetryLast {
+			if len(args) > 0 {
+				return fmt.Errorf("--retry-last does not take arguments")
+			}
+			return nil
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if cpRetryLast {
+			return retryLastTransfer(cmd)
+		}
+
 		// If only one argument (host), open interactive transfer UI
 		if len(args) == 1 {
 			hostName := args[0]
@@ -62,6 +121,31 @@ Examples:
 		// Set config file if specified
 		req.ConfigFile = configFile
 
+		// One-off user and port overrides
+		if err := transfer.ValidateOverrides(cpUser, cpPort); err != nil {
+			return err
+		}
+		req.User = cpUser
+		req.Port = cpPort
+		if err := transfer.ValidateBandwidthLimit(transferLimit); err != nil {
+			return err
+		}
+		req.BandwidthLimitKBps = transferLimit
+		if cpRsync {
+			req.Backend = transfer.BackendRsync
+		}
+
+		req.ExtraArgs, err = scpExtraArgs()
+		if err != nil {
+			return err
+		}
+		req.Concurrency = transfer.DefaultConcurrency()
+
+		// A comma-separated destination uploads to each of its hosts
+		if strings.Contains(req.Host, ",") {
+			return runCopyFanOut(cmd, req)
+		}
+
 		// Verify the host exists in SSH config
 		var hostExists bool
 		if configFile != "" {
@@ -78,6 +162,13 @@ Examples:
 			return fmt.Errorf("host '%s' not found in SSH configuration", req.Host)
 		}
 
+		if printCommand {
+			return printSCPCommand(cmd, req)
+		}
+		if proceed, err := checkExistingDownload(cmd, req); err != nil || !proceed {
+			return err
+		}
+
 		// Execute the transfer
 		direction := "upload"
 		if req.Direction == transfer.Download {
@@ -86,7 +177,8 @@ Examples:
 
 		fmt.Printf("Transferring %s %s...\n", direction, req.LocalPath)
 
-		result := req.ExecuteWithProgress()
+		result := req.Execute()
+		recordTransferAttempt(req, result.Error)
 		if !result.Success {
 			return fmt.Errorf("transfer failed: %w", result.Error)
 		}
@@ -96,12 +188,203 @@ Examples:
 		if err == nil {
 			_ = historyManager.RecordTransfer(req.Host, direction, req.LocalPath, req.RemotePath)
 		}
+		if pathStore, err := history.NewRemotePathStore(); err == nil {
+			_ = pathStore.RecordTransfer(req.Host, direction, req.RemotePath, req.Recursive)
+		}
 
 		fmt.Println("Transfer complete!")
 		return nil
 	},
 }
 
+// recordTransferAttempt saves req as the last attempted transfer so that
+// `sshm cp --retry-last` can run it again, whether it failed or not
+func recordTransferAttempt(req *transfer.TransferRequest, err error) {
+	if historyManager, herr := history.NewHistoryManager(); herr == nil {
+		_ = historyManager.RecordTransferAttempt(history.NewTransferAttempt(req, err))
+	}
+}
+
+// retryLastTransfer shows the parameters of the last attempted transfer and runs it again
+func retryLastTransfer(cmd *cobra.Command) error {
+	historyManager, err := history.NewHistoryManager()
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+	attempt := historyManager.GetLastTransferAttempt()
+	if attempt == nil {
+		return fmt.Errorf("no transfer to retry yet")
+	}
+
+	req := attempt.Request()
+	if printCommand {
+		return printSCPCommand(cmd, req)
+	}
+
+	if err := transfer.ValidateSCPExtraArgs(req.ExtraArgs); err != nil {
+		return fmt.Errorf("invalid scp arguments: %w", err)
+	}
+
+	var hostExists bool
+	if req.ConfigFile != "" {
+		hostExists, err = config.QuickHostExistsInFile(req.Host, req.ConfigFile)
+	} else {
+		hostExists, err = config.QuickHostExists(req.Host)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking SSH config: %w", err)
+	}
+	if !hostExists {
+		return fmt.Errorf("host '%s' not found in SSH configuration", req.Host)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, "Retrying the last transfer:")
+	for _, line := range attempt.Parameters() {
+		fmt.Fprintln(out, "  "+line)
+	}
+	fmt.Fprintln(out, "  Command:   "+req.CommandString())
+	fmt.Fprintln(out)
+
+	result := req.Execute()
+	recordTransferAttempt(req, result.Error)
+	if !result.Success {
+		return fmt.Errorf("transfer failed: %w", result.Error)
+	}
+
+	_ = historyManager.RecordTransfer(req.Host, attempt.Direction, req.LocalPath, req.RemotePath)
+	if pathStore, err := history.NewRemotePathStore(); err == nil {
+		_ = pathStore.RecordTransfer(req.Host, attempt.Direction, req.RemotePath, req.Recursive)
+	}
+
+	fmt.Fprintln(out, "Transfer complete!")
+	return nil
+}
+
+// checkHostsExist fails on the first of hostNames missing from the SSH
+// config, so that a fan-out never starts with a mistyped host
+func checkHostsExist(hostNames []string) error {
+	for _, hostName := range hostNames {
+		var hostExists bool
+		var err error
+		if configFile != "" {
+			hostExists, err = config.QuickHostExistsInFile(hostName, configFile)
+		} else {
+			hostExists, err = config.QuickHostExists(hostName)
+		}
+		if err != nil {
+			return fmt.Errorf("error checking SSH config: %w", err)
+		}
+		if !hostExists {
+			return fmt.Errorf("host '%s' not found in SSH configuration", hostName)
+		}
+	}
+	return nil
+}
+
+// fanOutRequests copies req once per host, so that every upload of a fan-out
+// keeps its user, port, jump host, backend and bandwidth limit
+func fanOutRequests(req *transfer.TransferRequest, hostNames []string) []*transfer.TransferRequest {
+	requests := make([]*transfer.TransferRequest, 0, len(hostNames))
+	for _, hostName := range hostNames {
+		r := *req
+		r.Host = hostName
+		requests = append(requests, &r)
+	}
+	return requests
+}
+
+// runCopyFanOut uploads req to each host of its comma-separated host list,
+// cpConcurrency at a time, then prints which hosts succeeded. Every host is
+// attempted; the copy fails if any of them did.
+func runCopyFanOut(cmd *cobra.Command, req *transfer.TransferRequest) error {
+	if req.Direction == transfer.Download {
+		return fmt.Errorf("cannot download from several hosts at once")
+	}
+	if req.Backend == transfer.BackendRsync {
+		return fmt.Errorf("--rsync is not supported when copying to several hosts")
+	}
+
+	hostNames := parseHostList(req.Host)
+	if len(hostNames) == 0 {
+		return fmt.Errorf("no hosts given")
+	}
+	if err := checkHostsExist(hostNames); err != nil {
+		return err
+	}
+
+	requests := fanOutRequests(req, hostNames)
+
+	out := cmd.OutOrStdout()
+	if printCommand {
+		for _, r := range requests {
+			fmt.Fprintln(out, r.CommandString())
+		}
+		return nil
+	}
+
+	fmt.Fprintf(out, "Uploading %s to %d host(s)...\n", req.LocalPath, len(requests))
+	results := transfer.RunFanOut(requests, cpConcurrency, executeBatch)
+
+	if historyManager, err := history.NewHistoryManager(); err == nil {
+		for _, r := range results {
+			if r.Result.Success {
+				_ = historyManager.RecordTransfer(r.Host, "upload", req.LocalPath, req.RemotePath)
+			}
+		}
+	}
+
+	fmt.Fprintln(out)
+	for _, line := range transfer.FormatFanOutSummary(results) {
+		fmt.Fprintln(out, line)
+	}
+
+	succeeded, failed := transfer.SummarizeFanOut(results)
+	fmt.Fprintf(out, "\n%d succeeded, %d failed\n", succeeded, failed)
+	if failed > 0 {
+		return fmt.Errorf("upload failed on %d host(s)", failed)
+	}
+	return nil
+}
+
+// lastRemoteDir returns the most recently used remote directory for a host, or ~
+func lastRemoteDir(hostName string) string {
+	if pathStore, err := history.NewRemotePathStore(); err == nil {
+		if dir := pathStore.Last(hostName); dir != "" {
+			return dir
+		}
+	}
+	return "~"
+}
+
+// scpExtraArgs returns the extra scp arguments from the app config followed by
+// those given with --scp-arg, after checking they don't conflict with managed args
+func scpExtraArgs() ([]string, error) {
+	var args []string
+	if appConfig, err := config.LoadAppConfig(); err == nil {
+		args = append(args, appConfig.ScpExtraArgs...)
+	}
+	args = append(args, scpArgs...)
+
+	if err := transfer.ValidateSCPExtraArgs(args); err != nil {
+		return nil, fmt.Errorf("invalid scp arguments: %w", err)
+	}
+	return args, nil
+}
+
+// printSCPCommand writes the shell-quoted scp command for req to stdout
+// without running it, and nothing else, so it can be captured by scripts
+func printSCPCommand(cmd *cobra.Command, req *transfer.TransferRequest) error {
+	_, err := fmt.Fprintln(cmd.OutOrStdout(), req.CommandString())
+	return err
+}
+
+// preferTUIPicker reports whether the app config asks for the TUI browser over native dialogs
+func preferTUIPicker() bool {
+	appConfig, err := config.LoadAppConfig()
+	return err == nil && appConfig.PreferTUIPicker
+}
+
 func runInteractiveTransfer(hostName string) error {
 	// Verify the host exists
 	var hostExists bool
@@ -129,6 +412,15 @@ func init() {
 	RootCmd.AddCommand(cpCmd)
 
 	cpCmd.Flags().BoolVarP(&cpRecursive, "recursive", "r", false, "Copy directories recursively")
+	cpCmd.Flags().StringArrayVar(&scpArgs, "scp-arg", nil, "Extra argument to pass to scp (repeatable, e.g. --scp-arg=-O)")
+	cpCmd.Flags().StringVar(&cpUser, "user", "", "SSH user for this copy, overriding the config")
+	cpCmd.Flags().StringVar(&cpPort, "port", "", "SSH port for this copy, overriding the config (passed to scp as -P)")
+	cpCmd.Flags().BoolVar(&printCommand, "print-command", false, "Print the scp command instead of running it")
+	cpCmd.Flags().BoolVar(&cpRsync, "rsync", false, "Copy with rsync -avz instead of scp, falling back to scp when rsync is not installed")
+	cpCmd.Flags().BoolVar(&cpRetryLast, "retry-last", false, "Show and re-run the last attempted transfer, even if it failed")
+	cpCmd.Flags().IntVar(&transferLimit, "limit", 0, "Limit the bandwidth of the copy to this many KB/s (passed to scp as -l in Kbit/s)")
+	cpCmd.Flags().IntVarP(&cpConcurrency, "concurrency", "j", transfer.DefaultFanOutConcurrency, "Maximum number of concurrent uploads when copying to several hosts")
+	cpCmd.Flags().StringVar(&onExists, "on-exists", "", "When the local destination of a download exists: ask, overwrite, skip or rename (default from download_on_exists, else ask)")
 }
 
 var sendCmd = &cobra.Command{
@@ -141,7 +433,10 @@ Examples:
   sshm send myhost
 
   # Upload a specific file
-  sshm send myhost ./file.txt`,
+  sshm send myhost ./file.txt
+
+  # Print the scp command for the upload instead of running it
+  sshm send --print-command myhost ./file.txt`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		hostName := args[0]
@@ -167,8 +462,9 @@ Examples:
 		var localPath string
 
 		if len(args) == 1 {
-			// No path given - try native file picker first
-			if transfer.IsPickerAvailable() {
+			// No path given - try native file picker first, unless the TUI browser is preferred
+			preferTUI := preferTUIPicker()
+			if transfer.UseNativePicker(preferTUI) {
 				cwd, _ := os.Getwd()
 				result, err := transfer.OpenFilePicker(transfer.PickFile, "Select file to upload", cwd)
 				if err != nil {
@@ -179,6 +475,16 @@ Examples:
 					return nil
 				}
 				localPath = result.Path
+			} else if preferTUI {
+				path, selected, err := ui.RunLocalBrowser("", ui.BrowseFiles)
+				if err != nil {
+					return fmt.Errorf("file browser error: %w", err)
+				}
+				if !selected {
+					fmt.Println("No file selected, cancelled.")
+					return nil
+				}
+				localPath = path
 			} else {
 				// Fall back to TUI
 				return ui.RunTransferFormWithDirection(hostName, configFile, transfer.Upload)
@@ -197,9 +503,20 @@ Examples:
 			return err
 		}
 
-		// Get remote destination - use TUI browser
+		info, _ := os.Stat(expandedPath)
+		isDir := info != nil && info.IsDir()
+
+		// Get remote destination - use TUI browser: a directory for a folder,
+		// the name to save a file as, starting from its own
 		var remotePath string
-		path, selected, err := ui.RunRemoteBrowser(hostName, "~", configFile, ui.BrowseDirectories)
+		remoteFile := false // remotePath names the file written
+		var path string
+		var selected bool
+		if isDir {
+			path, selected, err = ui.RunRemoteBrowser(hostName, lastRemoteDir(hostName), configFile, ui.BrowseDirectories)
+		} else {
+			path, selected, err = ui.RunRemoteSaveAs(hostName, lastRemoteDir(hostName), configFile, filepath.Base(expandedPath))
+		}
 		if err != nil {
 			fmt.Printf("Remote browser error: %v\n", err)
 			fmt.Print("Remote destination path (default ~/): ")
@@ -209,28 +526,42 @@ Examples:
 			return nil
 		} else {
 			remotePath = path
+			remoteFile = !isDir
 		}
 
 		if remotePath == "" {
 			remotePath = "~/"
 		}
 
+		extraArgs, err := scpExtraArgs()
+		if err != nil {
+			return err
+		}
+		if err := transfer.ValidateBandwidthLimit(transferLimit); err != nil {
+			return err
+		}
+
 		req := &transfer.TransferRequest{
 			Host:       hostName,
 			Direction:  transfer.Upload,
 			LocalPath:  expandedPath,
 			RemotePath: remotePath,
+			RemoteFile: remoteFile,
+			Recursive:  isDir,
 			ConfigFile: configFile,
+			ExtraArgs:  extraArgs,
+
+			BandwidthLimitKBps: transferLimit,
+			Concurrency:        transfer.DefaultConcurrency(),
 		}
 
-		// Check if it's a directory
-		info, _ := os.Stat(expandedPath)
-		if info != nil && info.IsDir() {
-			req.Recursive = true
+		if printCommand {
+			return printSCPCommand(cmd, req)
 		}
 
 		fmt.Printf("Uploading %s to %s:%s...\n", localPath, hostName, remotePath)
-		result := req.ExecuteWithProgress()
+		result := req.Execute()
+		recordTransferAttempt(req, result.Error)
 
 		if !result.Success {
 			return fmt.Errorf("upload failed: %w", result.Error)
@@ -241,6 +572,13 @@ Examples:
 		if err == nil {
 			_ = historyManager.RecordTransfer(hostName, "upload", expandedPath, remotePath)
 		}
+		if pathStore, err := history.NewRemotePathStore(); err == nil {
+			if remoteFile {
+				_ = pathStore.RecordParent(hostName, remotePath)
+			} else {
+				_ = pathStore.RecordTransfer(hostName, "upload", remotePath, req.Recursive)
+			}
+		}
 
 		fmt.Println("Upload complete!")
 		return nil
@@ -260,7 +598,19 @@ Examples:
   sshm get myhost /var/log/app.log
 
   # Download to specific location (no pickers)
-  sshm get myhost /var/log/app.log ./downloads/`,
+  sshm get myhost /var/log/app.log ./downloads/
+
+  # Continue an interrupted download instead of starting over
+  sshm get --resume myhost /backups/db.tar.gz ./downloads/
+
+  # Keep an existing local file, saving the download as app (1).log
+  sshm get --on-exists rename myhost /var/log/app.log ./downloads/
+
+  # Print the scp command for the download instead of running it
+  sshm get --print-command myhost /var/log/app.log ./downloads/
+
+  # Glance at a file: download it to a temporary directory and open it
+  sshm get --open myhost /srv/reports/summary.pdf`,
 	Args: cobra.RangeArgs(1, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		hostName := args[0]
@@ -291,7 +641,7 @@ Examples:
 			remotePath = args[1]
 		} else {
 			// No remote path - use TUI browser
-			path, selected, err := ui.RunRemoteBrowser(hostName, "~", configFile, ui.BrowseFiles)
+			path, selected, err := ui.RunRemoteBrowser(hostName, lastRemoteDir(hostName), configFile, ui.BrowseFiles)
 			if err != nil {
 				return fmt.Errorf("remote browser error: %w", err)
 			}
@@ -302,12 +652,20 @@ Examples:
 			remotePath = path
 		}
 
+		if getOpen {
+			if len(args) >= 3 {
+				return fmt.Errorf("--open downloads to a temporary directory, drop the local path")
+			}
+			return openRemoteFile(cmd, hostName, remotePath)
+		}
+
 		// Handle local path
 		if len(args) >= 3 {
 			localPath = args[2]
 		} else {
-			// No local path given - try native folder picker
-			if transfer.IsPickerAvailable() {
+			// No local path given - try native folder picker, unless the TUI browser is preferred
+			preferTUI := preferTUIPicker()
+			if transfer.UseNativePicker(preferTUI) {
 				cwd, _ := os.Getwd()
 				result, err := transfer.OpenFilePicker(transfer.PickDirectory, "Select download destination", cwd)
 				if err != nil {
@@ -318,6 +676,16 @@ Examples:
 					return nil
 				}
 				localPath = result.Path
+			} else if preferTUI {
+				path, selected, err := ui.RunLocalBrowser("", ui.BrowseDirectories)
+				if err != nil {
+					return fmt.Errorf("file browser error: %w", err)
+				}
+				if !selected {
+					fmt.Println("No destination selected, cancelled.")
+					return nil
+				}
+				localPath = path
 			} else {
 				// Fall back to asking
 				fmt.Print("Local destination path (default: ./): ")
@@ -334,16 +702,41 @@ Examples:
 			return fmt.Errorf("invalid path: %w", err)
 		}
 
+		extraArgs, err := scpExtraArgs()
+		if err != nil {
+			return err
+		}
+		if err := transfer.ValidateBandwidthLimit(transferLimit); err != nil {
+			return err
+		}
+
 		req := &transfer.TransferRequest{
 			Host:       hostName,
 			Direction:  transfer.Download,
 			LocalPath:  expandedPath,
 			RemotePath: remotePath,
 			ConfigFile: configFile,
+			ExtraArgs:  extraArgs,
+			Resumable:  getResume,
+
+			BandwidthLimitKBps: transferLimit,
+			Concurrency:        transfer.DefaultConcurrency(),
+		}
+
+		if printCommand {
+			return printSCPCommand(cmd, req)
+		}
+		if proceed, err := checkExistingDownload(cmd, req); err != nil || !proceed {
+			return err
 		}
 
-		fmt.Printf("Downloading %s:%s to %s...\n", hostName, remotePath, localPath)
-		result := req.ExecuteWithProgress()
+		fmt.Printf("Downloading %s:%s to %s...\n", hostName, remotePath, req.LocalPath)
+		result := req.Execute()
+		if errors.Is(result.Error, transfer.ErrPartialLarger) && confirmRestartDownload(cmd.InOrStdin(), cmd.OutOrStdout(), result.Error) {
+			req.Resumable = false
+			result = req.Execute()
+		}
+		recordTransferAttempt(req, result.Error)
 
 		if !result.Success {
 			return fmt.Errorf("download failed: %w", result.Error)
@@ -354,13 +747,109 @@ Examples:
 		if err == nil {
 			_ = historyManager.RecordTransfer(hostName, "download", expandedPath, remotePath)
 		}
+		if pathStore, err := history.NewRemotePathStore(); err == nil {
+			_ = pathStore.RecordTransfer(hostName, "download", remotePath, req.Recursive)
+		}
 
 		fmt.Println("Download complete!")
 		return nil
 	},
 }
 
+// checkExistingDownload applies --on-exists, or the download_on_exists
+// preference, to a download whose local destination exists. It returns false
+// when the download is skipped. Resumed downloads expect the partial file.
+func checkExistingDownload(cmd *cobra.Command, req *transfer.TransferRequest) (bool, error) {
+	action := transfer.DefaultOnExists()
+	if cmd.Flags().Changed("on-exists") {
+		var err error
+		if action, err = transfer.ParseOnExists(onExists); err != nil {
+			return false, err
+		}
+	}
+
+	existing := req.ExistingDestination()
+	if existing == "" || req.Resumable {
+		return true, nil
+	}
+
+	out := cmd.OutOrStdout()
+	if action == transfer.OnExistsAsk {
+		action = transfer.PromptOnExists(cmd.InOrStdin(), out, existing)
+	}
+	if !req.ApplyOnExists(action) {
+		fmt.Fprintf(out, "Skipped, %s already exists\n", existing)
+		return false, nil
+	}
+	if action == transfer.OnExistsRename {
+		fmt.Fprintf(out, "Saving as %s\n", req.LocalPath)
+	}
+	return true, nil
+}
+
+// openRemoteFile downloads a remote file to a temporary directory, opens it
+// with its default application and removes it once the user is done
+func openRemoteFile(cmd *cobra.Command, hostName, remotePath string) error {
+	in, out := cmd.InOrStdin(), cmd.OutOrStdout()
+
+	session, err := transfer.NewSFTPSession(hostName, configFile)
+	var unknown *transfer.UnknownHostKeyError
+	if errors.As(err, &unknown) {
+		if accepted, _ := transfer.PromptHostKey(in, out, unknown); accepted {
+			if err = transfer.AcceptHostKey(unknown); err == nil {
+				session, err = transfer.NewSFTPSession(hostName, configFile)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", hostName, err)
+	}
+
+	dir, err := transfer.NewOpenDir()
+	if err != nil {
+		session.Close()
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	localPath, err := session.DownloadForOpen(remotePath, dir, transfer.OpenMaxSize())
+	session.Close()
+	if err != nil {
+		return err
+	}
+	if err := transfer.OpenLocalFile(localPath); err != nil {
+		return err
+	}
+
+	// The application may still be reading the file, keep it until asked
+	fmt.Fprintf(out, "Opened %s. Press Enter when done to remove the temporary copy...", localPath)
+	bufio.NewReader(in).ReadString('\n')
+	fmt.Fprintln(out)
+	return nil
+}
+
+// confirmRestartDownload warns that a download cannot be resumed and asks
+// whether to download it again from the start, overwriting the local file
+func confirmRestartDownload(in io.Reader, out io.Writer, err error) bool {
+	fmt.Fprintf(out, "⚠️  Cannot resume: %v\n", err)
+	fmt.Fprint(out, "Download it again and overwrite the local file? [y/N]: ")
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 func init() {
 	RootCmd.AddCommand(sendCmd)
 	RootCmd.AddCommand(getCmd)
+
+	sendCmd.Flags().StringArrayVar(&scpArgs, "scp-arg", nil, "Extra argument to pass to scp (repeatable, e.g. --scp-arg=-O)")
+	getCmd.Flags().StringArrayVar(&scpArgs, "scp-arg", nil, "Extra argument to pass to scp (repeatable, e.g. --scp-arg=-O)")
+	sendCmd.Flags().BoolVar(&printCommand, "print-command", false, "Print the scp command instead of running it")
+	getCmd.Flags().BoolVar(&printCommand, "print-command", false, "Print the scp command instead of running it")
+	sendCmd.Flags().IntVar(&transferLimit, "limit", 0, "Limit the bandwidth of the upload to this many KB/s")
+	getCmd.Flags().IntVar(&transferLimit, "limit", 0, "Limit the bandwidth of the download to this many KB/s")
+	getCmd.Flags().BoolVar(&getResume, "resume", false, "Continue an interrupted download from the partial local file")
+	getCmd.Flags().BoolVar(&getOpen, "open", false, "Download the file to a temporary directory and open it with its default application")
+	getCmd.Flags().StringVar(&onExists, "on-exists", "", "When the local destination exists: ask, overwrite, skip or rename (default from download_on_exists, else ask)")
 }
@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+func TestPrintCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(dir, "my file.txt")
+	if err := os.WriteFile(local, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	localDir := filepath.Join(dir, "site")
+	if err := os.Mkdir(localDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		printCommand = false
+		cpRecursive = false
+		transferLimit = 0
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "upload",
+			args:     []string{"cp", "--print-command", local, "web:/tmp/"},
+			expected: "scp -F " + sshConfig + " '" + local + "' web:/tmp/\n",
+		},
+		{
+			name:     "download",
+			args:     []string{"cp", "--print-command", "web:/var/log/app.log", dir},
+			expected: "scp -F " + sshConfig + " web:/var/log/app.log " + dir + "\n",
+		},
+		{
+			name:     "recursive upload",
+			args:     []string{"cp", "--print-command", localDir, "web:/srv/www"},
+			expected: "scp -r -F " + sshConfig + " " + localDir + " web:/srv/www\n",
+		},
+		{
+			name:     "get",
+			args:     []string{"get", "--print-command", "web", "/var/log/app.log", dir},
+			expected: "scp -F " + sshConfig + " web:/var/log/app.log " + dir + "\n",
+		},
+		{
+			name:     "bandwidth limit",
+			args:     []string{"cp", "--print-command", "--limit", "100", local, "web:/tmp/"},
+			expected: "scp -F " + sshConfig + " -l 800 '" + local + "' web:/tmp/\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			printCommand = false
+			transferLimit = 0
+			out := new(bytes.Buffer)
+			RootCmd.SetOut(out)
+			RootCmd.SetArgs(append(tt.args, "--config", sshConfig))
+
+			if err := RootCmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestCopyRejectsNegativeLimit(t *testing.T) {
+	defer func() {
+		transferLimit = 0
+		RootCmd.SetArgs([]string{})
+	}()
+
+	RootCmd.SetArgs([]string{"cp", "--limit", "-5", "web:/var/log/app.log", t.TempDir()})
+	err := RootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid bandwidth limit") {
+		t.Errorf("Execute() error = %v, want an invalid bandwidth limit", err)
+	}
+}
+
+func TestRetryLastRebuildsFailedTransfer(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	defer func() {
+		printCommand = false
+		cpRetryLast = false
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	RootCmd.SetOut(new(bytes.Buffer))
+	RootCmd.SetArgs([]string{"cp", "--retry-last"})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "no transfer to retry") {
+		t.Fatalf("Expected an error without a previous transfer, got %v", err)
+	}
+	cpRetryLast = false
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	req := &transfer.TransferRequest{
+		Host:       "web",
+		Direction:  transfer.Download,
+		LocalPath:  filepath.Join(dir, "logs"),
+		RemotePath: "/var/log/app",
+		Recursive:  true,
+		ConfigFile: sshConfig,
+		ExtraArgs:  []string{"-O"},
+		User:       "deploy",
+		Port:       "2222",
+	}
+	recordTransferAttempt(req, errors.New("lost connection"))
+
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempt := hm.GetLastTransferAttempt(); attempt == nil || !attempt.Failed() {
+		t.Fatalf("Expected the failed transfer to be recorded, got %+v", attempt)
+	}
+
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetArgs([]string{"cp", "--retry-last", "--print-command"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if out.String() != req.CommandString()+"\n" {
+		t.Errorf("Output = %q, want %q", out.String(), req.CommandString()+"\n")
+	}
+
+	cpRetryLast, printCommand = false, false
+	RootCmd.SetArgs([]string{"cp", "--retry-last", "web:/etc/hosts", dir})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected --retry-last to reject paths")
+	}
+}
+
+func TestPrintCommandRsync(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not installed, cp --rsync falls back to scp")
+	}
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		printCommand = false
+		cpRsync = false
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetArgs([]string{"cp", "--rsync", "--print-command", "web:/var/log/app.log", dir, "--config", sshConfig})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	expected := "rsync -avz --progress -e 'ssh -F " + sshConfig + "' web:/var/log/app.log " + dir + "\n"
+	if out.String() != expected {
+		t.Errorf("Output = %q, want %q", out.String(), expected)
+	}
+}
+
+func TestConfirmRestartDownload(t *testing.T) {
+	err := fmt.Errorf("%w: ./a.txt is 2.0KB, web:/a.txt is 1.0KB", transfer.ErrPartialLarger)
+
+	for answer, expected := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		out := new(bytes.Buffer)
+		if got := confirmRestartDownload(strings.NewReader(answer), out, err); got != expected {
+			t.Errorf("Answer %q = %v, want %v", answer, got, expected)
+		}
+		if !strings.Contains(out.String(), "larger than the remote file") {
+			t.Errorf("Expected the warning to explain why, got %q", out.String())
+		}
+	}
+
+	if getCmd.Flags().Lookup("resume") == nil {
+		t.Error("Expected get to have a --resume flag")
+	}
+}
+
+func TestDownloadOnExistsSkip(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(existing, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		onExists = ""
+		cpCmd.Flags().Lookup("on-exists").Changed = false
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetArgs([]string{"cp", "--on-exists", "skip", "web:/var/log/app.log", dir, "--config", sshConfig})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Skipped, "+existing+" already exists") {
+		t.Errorf("Expected the download to be skipped, got %q", out.String())
+	}
+	if content, _ := os.ReadFile(existing); string(content) != "keep me" {
+		t.Error("Expected the local file to be kept")
+	}
+
+	RootCmd.SetArgs([]string{"cp", "--on-exists", "merge", "web:/var/log/app.log", dir, "--config", sshConfig})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid on-exists value") {
+		t.Errorf("Expected an invalid value to be refused, got %v", err)
+	}
+}
+
+func TestFanOutRequestsKeepConnectionOptions(t *testing.T) {
+	req := &transfer.TransferRequest{
+		Host:               "web1,web2",
+		Direction:          transfer.Upload,
+		LocalPath:          "./app.conf",
+		RemotePath:         "/etc/app/",
+		ConfigFile:         "/tmp/ssh_config",
+		ExtraArgs:          []string{"-O"},
+		User:               "deploy",
+		Port:               "2222",
+		JumpHost:           "bastion",
+		BandwidthLimitKBps: 500,
+		Concurrency:        4,
+	}
+
+	requests := fanOutRequests(req, []string{"web1", "web2"})
+	if len(requests) != 2 {
+		t.Fatalf("Expected one request per host, got %d", len(requests))
+	}
+	for i, host := range []string{"web1", "web2"} {
+		want := *req
+		want.Host = host
+		if !reflect.DeepEqual(*requests[i], want) {
+			t.Errorf("Expected %+v, got %+v", want, *requests[i])
+		}
+	}
+	if req.Host != "web1,web2" {
+		t.Errorf("Expected the original request to be left alone, got host %q", req.Host)
+	}
+}
+
+func TestCopyToSeveralHosts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("Host web1\n    HostName 10.0.0.1\n\nHost web2\n    HostName 10.0.0.2\n\nHost web3\n    HostName 10.0.0.3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(dir, "app.conf")
+	if err := os.WriteFile(local, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var uploaded []string
+	var mu sync.Mutex
+	executeBatch = func(req *transfer.TransferRequest) *transfer.TransferResult {
+		mu.Lock()
+		uploaded = append(uploaded, req.Host+":"+req.RemotePath)
+		mu.Unlock()
+		if req.Host == "web2" {
+			return &transfer.TransferResult{Success: false, Error: errors.New("connection refused")}
+		}
+		return &transfer.TransferResult{Success: true}
+	}
+	defer func() {
+		executeBatch = (*transfer.TransferRequest).ExecuteBatch
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetArgs([]string{"cp", local, "web1,web2,web3:/etc/app/", "--config", sshConfig})
+	err := RootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "upload failed on 1 host(s)") {
+		t.Errorf("Expected the copy to fail for one host, got %v", err)
+	}
+	if len(uploaded) != 3 {
+		t.Errorf("Expected every host to be attempted, got %v", uploaded)
+	}
+	for _, want := range []string{"web1  ok", "web2  failed: connection refused", "web3  ok", "2 succeeded, 1 failed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the summary, got:\n%s", want, out.String())
+		}
+	}
+
+	historyManager, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := historyManager.GetTransferHistory("web1"); len(entries) != 1 || entries[0].RemotePath != "/etc/app/" {
+		t.Errorf("Expected the upload to web1 in history, got %+v", entries)
+	}
+	if entries := historyManager.GetTransferHistory("web2"); len(entries) != 0 {
+		t.Errorf("Expected no history for the failed host, got %+v", entries)
+	}
+
+	RootCmd.SetArgs([]string{"cp", "web1,web2:/var/log/app.log", dir, "--config", sshConfig})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "cannot download from several hosts") {
+		t.Errorf("Expected downloads from several hosts to be refused, got %v", err)
+	}
+	RootCmd.SetArgs([]string{"cp", local, "web1,nope:/etc/app/", "--config", sshConfig})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "host 'nope' not found") {
+		t.Errorf("Expected an unknown host to be refused before uploading, got %v", err)
+	}
+}
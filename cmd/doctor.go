@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// doctorFix rewrites the config files with problems instead of only reporting them
+	doctorFix bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the SSH config files for common problems",
+	Long: `Check the SSH config file and the files it includes for problems.
+
+Configs edited on Windows can start with a UTF-8 BOM or use CRLF line endings.
+sshm reads them fine, but ssh and other strict parsers may reject them.
+
+Examples:
+  sshm doctor        # Report problems
+  sshm doctor --fix  # Rewrite affected files without BOM and with LF line endings`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	files, err := config.GetAllConfigFilesFromBase(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to list SSH config files: %w", err)
+	}
+	sort.Strings(files)
+
+	out := cmd.OutOrStdout()
+	problems, fixed := 0, 0
+	for _, file := range files {
+		issues, err := config.CheckLineEndings(file)
+		if err != nil {
+			fmt.Fprintf(out, "⚠️  %s: %v\n", file, err)
+			problems++
+			continue
+		}
+		if !issues.Any() {
+			continue
+		}
+
+		if !doctorFix {
+			fmt.Fprintf(out, "⚠️  %s: %s\n", file, issues)
+			problems++
+			continue
+		}
+		if _, err := config.FixLineEndings(file); err != nil {
+			return fmt.Errorf("failed to fix %s: %w", file, err)
+		}
+		fmt.Fprintf(out, "✅ %s: removed %s\n", file, issues)
+		fixed++
+	}
+
+	switch {
+	case problems > 0 && !doctorFix:
+		fmt.Fprintln(out, "Run 'sshm doctor --fix' to normalize the affected files (a backup is kept in ~/.config/sshm/backups).")
+	case problems == 0 && fixed == 0:
+		fmt.Fprintf(out, "No problems found in %d config file(s).\n", len(files))
+	}
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Rewrite config files without BOM and with LF line endings")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorLineEndings(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("\ufeffHost web\r\n    HostName web.example.com\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		doctorFix = false
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	run := func(args ...string) string {
+		t.Helper()
+		out := new(bytes.Buffer)
+		RootCmd.SetOut(out)
+		RootCmd.SetArgs(append(args, "--config", sshConfig))
+		if err := RootCmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return out.String()
+	}
+
+	if out := run("doctor"); !strings.Contains(out, sshConfig+": UTF-8 BOM, CRLF line endings") || !strings.Contains(out, "--fix") {
+		t.Errorf("Expected a warning for the config, got %q", out)
+	}
+	if content, _ := os.ReadFile(sshConfig); !bytes.HasPrefix(content, []byte("\ufeff")) {
+		t.Error("Expected the file to be left alone without --fix")
+	}
+
+	if out := run("doctor", "--fix"); !strings.Contains(out, "removed UTF-8 BOM, CRLF line endings") {
+		t.Errorf("Expected the config to be fixed, got %q", out)
+	}
+	if content, _ := os.ReadFile(sshConfig); string(content) != "Host web\n    HostName web.example.com\n" {
+		t.Errorf("Unexpected fixed content %q", content)
+	}
+
+	doctorFix = false
+	if out := run("doctor"); !strings.Contains(out, "No problems found in 1 config file(s)") {
+		t.Errorf("Expected no problems left, got %q", out)
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+// runRemoteCommand runs a command on a host; replaced in tests
+var runRemoteCommand = transfer.RunRemoteCommand
+
+// exitProcess ends sshm with the exit status of the remote command; replaced in tests
+var exitProcess = os.Exit
+
+var (
+	// execParallel limits how many hosts run the command at once
+	execParallel int
+	// execTimeout kills the command on a host that runs longer
+	execTimeout time.Duration
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <host>[,host2,...] -- <command...>",
+	Short: "Run a command on one or several hosts without an interactive shell",
+	Long: `Run a single command on a host and print its output, without opening an
+interactive shell. The output is streamed as the command runs, and sshm exits
+with the exit status of the remote command.
+
+Given a comma-separated list of hosts, the command runs on all of them at once
+(at most --parallel at a time) and every line of output is prefixed with the
+host it came from, as pdsh does. A summary of each host's exit status follows,
+and sshm exits with a non-zero status if the command failed anywhere. Input is
+not forwarded to several hosts.
+
+The host is resolved from the SSH config (or the file given with -c, or -F as
+with ssh) and the connection authenticates with the SSH agent and the host's
+IdentityFile keys. Options of sshm go before the host: everything after it is
+the command, and "--" makes that explicit.
+
+Examples:
+  # Check the load of a host
+  sshm exec myhost uptime
+
+  # Options and pipes are passed to the remote shell
+  sshm exec myhost -- df -h /var
+  sshm exec myhost -- 'journalctl -u nginx | tail -n 20'
+
+  # Feed a local file to the command
+  sshm exec myhost -- 'cat > /tmp/notes.txt' < notes.txt
+
+  # Check a fleet, giving each host 10 seconds
+  sshm exec --timeout 10s web1,web2,db1 -- uptime`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runExec,
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	hostNames := parseHostList(args[0])
+	if len(hostNames) == 0 {
+		return fmt.Errorf("no hosts given")
+	}
+	words := args[1:]
+	if words[0] == "--" {
+		// Options are not parsed after the host, so "--" reaches the arguments
+		words = words[1:]
+	}
+	command := strings.Join(words, " ")
+	if strings.TrimSpace(command) == "" {
+		return fmt.Errorf("no command given to run on %s", args[0])
+	}
+	if execTimeout < 0 {
+		return fmt.Errorf("invalid timeout %s: must not be negative", execTimeout)
+	}
+
+	// Verify the hosts exist
+	for _, hostName := range hostNames {
+		var hostExists bool
+		var err error
+		if configFile != "" {
+			hostExists, err = config.QuickHostExistsInFile(hostName, configFile)
+		} else {
+			hostExists, err = config.QuickHostExists(hostName)
+		}
+		if err != nil {
+			return fmt.Errorf("error checking SSH config: %w", err)
+		}
+		if !hostExists {
+			return fmt.Errorf("host '%s' not found in SSH configuration", hostName)
+		}
+	}
+
+	// Record the connections in history, without preventing the command
+	if historyManager, err := history.NewHistoryManager(); err == nil {
+		for _, hostName := range hostNames {
+			if err := historyManager.RecordConnection(hostName); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: Could not record connection history: %v\n", err)
+				break
+			}
+		}
+	}
+
+	if len(hostNames) > 1 {
+		return runExecFanOut(cmd, hostNames, command)
+	}
+
+	hostName := hostNames[0]
+	status, err := runRemoteCommand(hostName, configFile, command, execTimeout, cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	if err != nil {
+		return fmt.Errorf("exec on %s: %w", hostName, err)
+	}
+	if status != 0 {
+		exitProcess(status)
+	}
+	return nil
+}
+
+// runExecFanOut runs the command on several hosts at once, prefixing each
+// line of output with its host, and prints the exit status of every host
+func runExecFanOut(cmd *cobra.Command, hostNames []string, command string) error {
+	width := 0
+	for _, hostName := range hostNames {
+		if len(hostName) > width {
+			width = len(hostName)
+		}
+	}
+
+	// One lock for both streams so lines of different hosts never mix
+	var mu sync.Mutex
+	results := transfer.RunExecFanOut(hostNames, execParallel, func(hostName string) transfer.ExecResult {
+		prefix := fmt.Sprintf("%-*s | ", width, hostName)
+		stdout := transfer.NewPrefixWriter(cmd.OutOrStdout(), &mu, prefix)
+		stderr := transfer.NewPrefixWriter(cmd.ErrOrStderr(), &mu, prefix)
+
+		status, err := runRemoteCommand(hostName, configFile, command, execTimeout, nil, stdout, stderr)
+		_ = stdout.Flush()
+		_ = stderr.Flush()
+		return transfer.ExecResult{ExitStatus: status, Err: err}
+	})
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out)
+	for _, line := range transfer.FormatExecSummary(results) {
+		fmt.Fprintln(out, line)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Succeeded() {
+			failed++
+		}
+	}
+	fmt.Fprintf(out, "\n%d succeeded, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("command failed on %d host(s)", failed)
+	}
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(execCmd)
+
+	// Options after the host belong to the remote command
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().StringVarP(&configFile, "ssh-config", "F", "", "SSH config file to use, as ssh -F (same as --config)")
+	execCmd.Flags().IntVarP(&execParallel, "parallel", "p", transfer.DefaultExecConcurrency, "Maximum number of hosts running the command at once")
+	execCmd.Flags().DurationVar(&execTimeout, "timeout", 0, "Kill the command on a host after this long, such as 30s (0 waits forever)")
+}
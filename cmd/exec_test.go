@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+func TestExecCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type call struct {
+		host, configFile, command string
+	}
+	var got call
+	status := 0
+	runRemoteCommand = func(host, configFile, command string, timeout time.Duration, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+		got = call{host, configFile, command}
+		io.WriteString(stdout, "ok\n")
+		return status, nil
+	}
+	exited := -1
+	exitProcess = func(code int) { exited = code }
+
+	defer func() {
+		runRemoteCommand = transfer.RunRemoteCommand
+		exitProcess = os.Exit
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	tests := []struct {
+		name    string
+		args    []string
+		command string
+	}{
+		{
+			name:    "words joined",
+			args:    []string{"exec", "-c", sshConfig, "web", "uptime"},
+			command: "uptime",
+		},
+		{
+			name:    "options after --",
+			args:    []string{"exec", "-F", sshConfig, "web", "--", "df", "-h", "/var"},
+			command: "df -h /var",
+		},
+		{
+			name:    "options without --",
+			args:    []string{"exec", "-c", sshConfig, "web", "ls", "-la"},
+			command: "ls -la",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile = ""
+			got = call{}
+			out := new(bytes.Buffer)
+			RootCmd.SetOut(out)
+			RootCmd.SetArgs(tt.args)
+
+			if err := RootCmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			want := call{"web", sshConfig, tt.command}
+			if got != want {
+				t.Errorf("RunRemoteCommand called with %+v, want %+v", got, want)
+			}
+			if out.String() != "ok\n" {
+				t.Errorf("Output = %q, want the remote output", out.String())
+			}
+			if exited != -1 {
+				t.Errorf("Expected no exit on success, got %d", exited)
+			}
+		})
+	}
+
+	// The connection is recorded in history
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hm.GetConnectionCount("web") != len(tests) {
+		t.Errorf("Expected %d connections recorded, got %d", len(tests), hm.GetConnectionCount("web"))
+	}
+
+	// The exit status of the remote command becomes the one of sshm
+	status = 3
+	RootCmd.SetArgs([]string{"exec", "-c", sshConfig, "web", "false"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if exited != 3 {
+		t.Errorf("Expected sshm to exit with 3, got %d", exited)
+	}
+
+	RootCmd.SetArgs([]string{"exec", "-c", sshConfig, "missing", "uptime"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected an unknown host to fail")
+	}
+
+	RootCmd.SetArgs([]string{"exec", "-c", sshConfig, "web", "--"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected a missing command to fail")
+	}
+
+	runRemoteCommand = func(string, string, string, time.Duration, io.Reader, io.Writer, io.Writer) (int, error) {
+		return 0, errors.New("failed to connect: connection refused")
+	}
+	RootCmd.SetArgs([]string{"exec", "-c", sshConfig, "web", "uptime"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected a connection failure to be returned")
+	}
+}
+
+func TestExecFanOut(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("Host web1 web2 db1\n    User admin\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	timeouts := make(chan time.Duration, 3)
+	runRemoteCommand = func(host, configFile, command string, timeout time.Duration, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+		timeouts <- timeout
+		if stdin != nil {
+			t.Errorf("Expected no input to be forwarded to %s", host)
+		}
+		switch host {
+		case "web2":
+			io.WriteString(stderr, "disk full\n")
+			return 1, nil
+		case "db1":
+			return 0, fmt.Errorf("%w after %s", transfer.ErrCommandTimeout, timeout)
+		}
+		io.WriteString(stdout, " 10:00 up 3 days\npartial")
+		return 0, nil
+	}
+	exitProcess = func(code int) { t.Errorf("Expected no exit with several hosts, got %d", code) }
+
+	defer func() {
+		runRemoteCommand = transfer.RunRemoteCommand
+		exitProcess = os.Exit
+		execParallel, execTimeout = transfer.DefaultExecConcurrency, 0
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetErr(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetErr(errOut)
+	RootCmd.SetArgs([]string{"exec", "-c", sshConfig, "--timeout", "5s", "-p", "2", "web1,web2,db1", "--", "uptime"})
+	err := RootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "failed on 2 host(s)") {
+		t.Fatalf("Expected the command to fail on 2 hosts, got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if timeout := <-timeouts; timeout != 5*time.Second {
+			t.Errorf("Expected a 5s timeout per host, got %s", timeout)
+		}
+	}
+
+	for _, want := range []string{"web1 |  10:00 up 3 days\n", "web1 | partial\n", "web1  ok", "web2  exit 1", "db1   failed: remote command timed out after 5s", "1 succeeded, 2 failed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the output, got:\n%s", want, out.String())
+		}
+	}
+	if !strings.Contains(errOut.String(), "web2 | disk full\n") {
+		t.Errorf("Expected the errors prefixed with their host, got %q", errOut.String())
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// exportTags keeps only the hosts having every one of these tags
+	exportTags []string
+	// exportFilter keeps only the hosts whose name, hostname or tags contain it
+	exportFilter string
+	// exportIdentity forces an identity file in every command
+	exportIdentity string
+	// exportResolved prints commands resolved by ssh -G, without -F
+	exportResolved bool
+)
+
+// resolveHostConfig resolves the options of a host with ssh -G (replaced in tests)
+var resolveHostConfig = config.GetResolvedConfig
+
+var exportCommandsCmd = &cobra.Command{
+	Use:   "export-commands",
+	Short: "Print the ssh command connecting to each host",
+	Long: `Print the ssh command sshm runs to connect to each host, one per line, in
+the order of the config file. The commands pass -F when a config file is given
+with --config, like sshm itself.
+
+With --resolved, each host is resolved with "ssh -G" and the command spells
+out the hostname, user, port, identity files and jump host, so it works
+without the SSH config.
+
+Examples:
+  sshm export-commands                         # Every host
+  sshm export-commands --tag prod              # Hosts tagged prod
+  sshm export-commands --filter web -i ~/.ssh/deploy_key
+  sshm export-commands --resolved > hosts.sh   # Standalone commands`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var hosts []config.SSHHost
+		var err error
+		if configFile != "" {
+			hosts, err = config.ParseSSHConfigFile(configFile)
+		} else {
+			hosts, err = config.ParseSSHConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read SSH config: %w", err)
+		}
+		hosts = filterHosts(config.FilterHostsByTags(hosts, exportTags), exportFilter, false, false)
+
+		for _, host := range hosts {
+			line, err := hostConnectCommand(host.Name, configFile, exportIdentity, exportResolved)
+			if err != nil {
+				return fmt.Errorf("%s: %w", host.Name, err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), line)
+		}
+		return nil
+	},
+}
+
+// hostConnectCommand returns the shell command connecting to a host, with the
+// arguments sshm passes to ssh or, when resolved is set, with those ssh -G
+// resolves from the config
+func hostConnectCommand(hostName, configFile, identity string, resolved bool) (string, error) {
+	if !resolved {
+		return transfer.ShellCommand("ssh", config.BuildConnectArgs(hostName, configFile, identity)...), nil
+	}
+	options, err := resolveHostConfig(hostName, configFile)
+	if err != nil {
+		return "", err
+	}
+	return transfer.ShellCommand("ssh", config.ResolvedConnectArgs(options, identity)...), nil
+}
+
+func init() {
+	RootCmd.AddCommand(exportCommandsCmd)
+	exportCommandsCmd.Flags().StringArrayVar(&exportTags, "tag", nil, "Only export hosts with this tag (repeatable)")
+	exportCommandsCmd.Flags().StringVar(&exportFilter, "filter", "", "Only export hosts whose name, hostname or tags contain this text")
+	exportCommandsCmd.Flags().StringVarP(&exportIdentity, "identity", "i", "", "Identity file to add to every command (with IdentitiesOnly=yes)")
+	exportCommandsCmd.Flags().BoolVar(&exportResolved, "resolved", false, "Resolve each host with ssh -G and print standalone commands")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+func TestExportCommands(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh config")
+	content := `# Tags: prod, web
+Host web1
+    HostName web1.example.com
+
+# Tags: prod, db
+Host db1
+    HostName db1.internal
+    User postgres
+    Port 2222
+
+Host dev
+    HostName dev.example.com
+`
+	if err := os.WriteFile(sshConfig, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	origResolve := resolveHostConfig
+	resolveHostConfig = func(hostName, configFile string) ([]config.ResolvedOption, error) {
+		if configFile != sshConfig {
+			t.Errorf("Expected ssh -G to read %s, got %q", sshConfig, configFile)
+		}
+		if hostName == "dev" {
+			return nil, errors.New("ssh -G failed")
+		}
+		return config.ParseResolvedConfig("hostname db1.internal\nuser postgres\nport 2222\n"), nil
+	}
+	defer func() {
+		resolveHostConfig = origResolve
+		exportTags = nil
+		exportFilter = ""
+		exportIdentity = ""
+		exportResolved = false
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		exportTags, exportFilter, exportIdentity, exportResolved = nil, "", "", false
+		out := new(bytes.Buffer)
+		RootCmd.SetOut(out)
+		RootCmd.SetArgs(append(append([]string{"export-commands"}, args...), "--config", sshConfig))
+		err := RootCmd.Execute()
+		return out.String(), err
+	}
+
+	quoted := "'" + sshConfig + "'"
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "ssh -F " + quoted + " web1\nssh -F " + quoted + " db1\nssh -F " + quoted + " dev\n"},
+		{[]string{"--tag", "prod", "--filter", "db"}, "ssh -F " + quoted + " db1\n"},
+		{[]string{"--filter", "example.com", "-i", "/keys/ci"}, "ssh -F " + quoted + " -i /keys/ci -o IdentitiesOnly=yes web1\n" +
+			"ssh -F " + quoted + " -i /keys/ci -o IdentitiesOnly=yes dev\n"},
+		{[]string{"--tag", "db", "--resolved"}, "ssh -p 2222 -l postgres db1.internal\n"},
+	}
+	for _, tt := range tests {
+		got, err := run(tt.args...)
+		if err != nil {
+			t.Fatalf("sshm export-commands %v: %v", tt.args, err)
+		}
+		if got != tt.want {
+			t.Errorf("sshm export-commands %v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	if _, err := run("--resolved"); err == nil {
+		t.Error("Expected an error when a host cannot be resolved")
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+var forwardsCmd = &cobra.Command{
+	Use:   "forwards",
+	Short: "List the port forwarding tunnels running in the background",
+	Long: `List the tunnels started in the background from the port forwarding form
+of the TUI (Ctrl+B), which keep running after sshm exits. Tunnels that ended
+since are forgotten.
+
+Examples:
+  sshm forwards
+
+  # Stop the tunnel with ID 2
+  sshm forwards stop 2`,
+	Args: cobra.NoArgs,
+	RunE: runForwards,
+}
+
+var forwardsStopCmd = &cobra.Command{
+	Use:   "stop <id>",
+	Short: "Stop a tunnel running in the background",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runForwardsStop,
+}
+
+func runForwards(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	registry, err := transfer.NewForwardRegistry()
+	if err != nil {
+		return err
+	}
+	forwards, err := registry.Active()
+	if err != nil {
+		return fmt.Errorf("failed to read the background tunnels: %w", err)
+	}
+	if len(forwards) == 0 {
+		fmt.Fprintln(out, "No tunnel running in the background.")
+		return nil
+	}
+
+	hostWidth, forwardWidth := len("HOST"), len("FORWARD")
+	for _, f := range forwards {
+		if len(f.Host) > hostWidth {
+			hostWidth = len(f.Host)
+		}
+		if len(f.Forward) > forwardWidth {
+			forwardWidth = len(f.Forward)
+		}
+	}
+	fmt.Fprintf(out, "%-4s  %-*s  %-*s  %-8s  %s\n", "ID", hostWidth, "HOST", forwardWidth, "FORWARD", "PID", "STARTED")
+	for _, f := range forwards {
+		fmt.Fprintf(out, "%-4d  %-*s  %-*s  %-8d  %s\n", f.ID, hostWidth, f.Host, forwardWidth, f.Forward, f.PID, f.Started.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func runForwardsStop(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid tunnel ID %q", args[0])
+	}
+
+	registry, err := transfer.NewForwardRegistry()
+	if err != nil {
+		return err
+	}
+	forward, err := registry.Stop(id)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Stopped the tunnel %s to %s\n", forward.Forward, forward.Host)
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(forwardsCmd)
+	forwardsCmd.AddCommand(forwardsStopCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+func TestForwards(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	defer func() {
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetArgs([]string{"forwards"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No tunnel running") {
+		t.Errorf("Expected no tunnel, got %q", out.String())
+	}
+
+	registry, err := transfer.NewForwardRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A fake ssh reports every tunnel as running to ssh -O check
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses a shell script to stand in for ssh")
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho 'Master running (pid=4242)'\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if err := registry.Add(&transfer.BackgroundForward{Host: "web", Forward: "-L 8080:localhost:80", PID: 4242}); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	RootCmd.SetArgs([]string{"forwards"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "web") || !strings.Contains(lines[1], "-L 8080:localhost:80") {
+		t.Errorf("Unexpected listing %q", out.String())
+	}
+
+	RootCmd.SetArgs([]string{"forwards", "stop", "nope"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("Expected an invalid ID to be rejected")
+	}
+}
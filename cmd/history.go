@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// compactMaxEntries overrides the number of hosts kept in the active history
+	compactMaxEntries int
+	// compactMaxAgeDays overrides how long unused hosts stay in the active history
+	compactMaxAgeDays int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Manage the connection history",
+}
+
+var historyCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Archive old connection history to keep the history file small",
+	Long: `Move hosts that exceed the configured history limits from sshm_history.json
+into a compressed archive (sshm_history_archive.jsonl.gz) next to it.
+
+Limits come from the "history" section of ~/.config/sshm/config.json
+(max_entries, max_age_days) and can be overridden with flags. Recording history
+applies the configured limits on its own; compact applies them right away.
+
+Examples:
+  sshm history compact                    # Apply the configured limits
+  sshm history compact --max-entries 50   # Keep only the 50 most recent hosts
+  sshm history compact --max-age-days 30  # Archive hosts unused for a month`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		historyConfig := config.GetDefaultHistoryConfig()
+		if appConfig, err := config.LoadAppConfig(); err == nil {
+			historyConfig = appConfig.History
+		}
+		if cmd.Flags().Changed("max-entries") {
+			historyConfig.MaxEntries = compactMaxEntries
+		}
+		if cmd.Flags().Changed("max-age-days") {
+			historyConfig.MaxAgeDays = compactMaxAgeDays
+		}
+
+		historyManager, err := history.NewHistoryManager()
+		if err != nil {
+			return fmt.Errorf("could not open connection history: %w", err)
+		}
+
+		start := time.Now()
+		archived, archivePath, err := historyManager.Compact(history.RotationPolicyFromConfig(historyConfig))
+		if err != nil {
+			return fmt.Errorf("failed to compact history: %w", err)
+		}
+
+		if archived == 0 {
+			fmt.Println("History is already within limits, nothing to archive.")
+			return nil
+		}
+		fmt.Printf("Archived %d host(s) to %s in %s\n", archived, archivePath, time.Since(start).Round(time.Millisecond))
+		return nil
+	},
+}
+
+var historyRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Move the archived connection history back into the history file",
+	Long: `Move every host of sshm_history_archive.jsonl.gz back into sshm_history.json
+and remove the archive. Hosts connected to again since they were archived keep
+their current entry. Restored hosts still outside the history limits are
+archived again the next time history is recorded.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		historyManager, err := history.NewHistoryManager()
+		if err != nil {
+			return fmt.Errorf("could not open connection history: %w", err)
+		}
+
+		restored, err := historyManager.Restore()
+		if err != nil {
+			return fmt.Errorf("failed to restore history: %w", err)
+		}
+		if restored == 0 {
+			fmt.Println("No archived history to restore.")
+			return nil
+		}
+		fmt.Printf("Restored %d host(s) from the archive\n", restored)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyCompactCmd)
+	historyCmd.AddCommand(historyRestoreCmd)
+	historyCompactCmd.Flags().IntVar(&compactMaxEntries, "max-entries", 0, "Number of hosts to keep (negative for unlimited)")
+	historyCompactCmd.Flags().IntVar(&compactMaxAgeDays, "max-age-days", 0, "Archive hosts unused for more days than this (negative to disable)")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import "testing"
+
+func TestHistoryCommandRegistration(t *testing.T) {
+	found := false
+	for _, cmd := range RootCmd.Commands() {
+		if cmd.Name() == "history" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatal("History command not found in root command")
+	}
+
+	found = false
+	for _, cmd := range historyCmd.Commands() {
+		if cmd.Name() == "compact" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Compact subcommand not found in history command")
+	}
+}
+
+func TestHistoryCompactCommandArgs(t *testing.T) {
+	if err := historyCompactCmd.Args(historyCompactCmd, []string{}); err != nil {
+		t.Errorf("Expected no error with no args, got %v", err)
+	}
+	if err := historyCompactCmd.Args(historyCompactCmd, []string{"extra"}); err == nil {
+		t.Error("Expected error with extra args")
+	}
+}
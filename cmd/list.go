@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// listTags keeps only the hosts having every one of these tags
+	listTags []string
+	// listFormat is the output format (names, table, json, csv)
+	listFormat string
+	// listJSON is a shorthand for --format json
+	listJSON bool
+)
+
+// listEntry is a host as printed by sshm list
+type listEntry struct {
+	Name        string     `json:"name"`
+	Hostname    string     `json:"hostname"`
+	User        string     `json:"user"`
+	Port        string     `json:"port"`
+	Tags        []string   `json:"tags"`
+	LastConnect *time.Time `json:"last_connect"`
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List SSH hosts for scripts and other tools",
+	Long: `List the SSH hosts of the config, in the order of the config file.
+
+By default only the host names are printed, one per line. --format table,
+json or csv adds the hostname, user, port, tags and the time of the last
+connection made with sshm (empty when never connected).
+
+Tags come from a "# Tags: prod, web" or "#sshm:tags prod,web" comment right
+above a Host line. With several --tag flags, hosts need every tag.
+
+Examples:
+  sshm list                        # All host names
+  sshm list --tag prod --tag web   # Hosts tagged both prod and web
+  sshm list --format table         # Aligned columns for reading
+  sshm list --json | jq -r '.[] | select(.user == "root") | .name'
+  sshm list --format csv -c ~/.ssh/work.conf > hosts.csv
+  for h in $(sshm list --tag web); do sshm exec "$h" -- uptime; done`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := listFormat
+		if listJSON {
+			format = "json"
+		}
+		switch format {
+		case "names", "table", "json", "csv":
+		default:
+			return fmt.Errorf("unknown format %q, use names, table, json or csv", format)
+		}
+
+		var hosts []config.SSHHost
+		var err error
+		if configFile != "" {
+			hosts, err = config.ParseSSHConfigFile(configFile)
+		} else {
+			hosts, err = config.ParseSSHConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read SSH config: %w", err)
+		}
+		hosts = config.FilterHostsByTags(hosts, listTags)
+
+		out := cmd.OutOrStdout()
+		if format == "names" {
+			for _, host := range hosts {
+				fmt.Fprintln(out, host.Name)
+			}
+			return nil
+		}
+
+		entries := listEntries(hosts)
+		switch format {
+		case "table":
+			return writeListTable(out, entries)
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(entries)
+		default:
+			return writeListCSV(out, entries)
+		}
+	},
+}
+
+// listEntries adds the last connection times of the history to hosts. The
+// history is optional: without it, no host has a last connection.
+func listEntries(hosts []config.SSHHost) []listEntry {
+	historyManager, _ := history.NewHistoryManager()
+	entries := make([]listEntry, 0, len(hosts))
+	for _, host := range hosts {
+		entry := listEntry{
+			Name:     host.Name,
+			Hostname: host.Hostname,
+			User:     host.User,
+			Port:     host.Port,
+			Tags:     host.Tags,
+		}
+		if entry.Tags == nil {
+			entry.Tags = []string{}
+		}
+		if historyManager != nil {
+			if last, ok := historyManager.GetLastConnectionTime(host.Name); ok {
+				entry.LastConnect = &last
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// writeListTable writes the entries as aligned columns, with - for empty fields
+func writeListTable(out io.Writer, entries []listEntry) error {
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tHOSTNAME\tUSER\tPORT\tTAGS\tLAST CONNECT")
+	for _, e := range entries {
+		last := "never"
+		if e.LastConnect != nil {
+			last = e.LastConnect.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Name, orDash(e.Hostname), orDash(e.User),
+			orDash(e.Port), orDash(strings.Join(e.Tags, ",")), last)
+	}
+	return w.Flush()
+}
+
+// writeListCSV writes the entries as CSV with a header row, tags separated by
+// semicolons and the last connection in RFC 3339
+func writeListCSV(out io.Writer, entries []listEntry) error {
+	w := csv.NewWriter(out)
+	_ = w.Write([]string{"name", "hostname", "user", "port", "tags", "last_connect"})
+	for _, e := range entries {
+		last := ""
+		if e.LastConnect != nil {
+			last = e.LastConnect.Format(time.RFC3339)
+		}
+		_ = w.Write([]string{e.Name, e.Hostname, e.User, e.Port, strings.Join(e.Tags, ";"), last})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func init() {
+	RootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVarP(&configFile, "ssh-config", "F", "", "SSH config file to use, as ssh -F (same as --config)")
+	listCmd.Flags().StringArrayVar(&listTags, "tag", nil, "Only list hosts with this tag (repeatable)")
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", "names", "Output format (names, table, json, csv)")
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "Same as --format json")
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/history"
+)
+
+// writeListConfig writes an SSH config with tagged hosts to a temporary home
+// and returns its path
+func writeListConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	content := `# Tags: prod, web
+Host web1
+    HostName web1.example.com
+
+#sshm:tags prod,db
+Host db1
+    HostName db1.example.com
+    User postgres
+    Port 2222
+
+Host dev
+    HostName dev.example.com
+`
+	if err := os.WriteFile(sshConfig, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		listTags = nil
+		listFormat = "names"
+		listJSON = false
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	})
+	return sshConfig
+}
+
+// runList runs sshm list with args against sshConfig and returns its output
+func runList(t *testing.T, sshConfig string, args ...string) (string, error) {
+	t.Helper()
+	listTags = nil
+	listFormat = "names"
+	listJSON = false
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetArgs(append(append([]string{"list"}, args...), "--config", sshConfig))
+	err := RootCmd.Execute()
+	return out.String(), err
+}
+
+func TestListByTag(t *testing.T) {
+	sshConfig := writeListConfig(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "web1\ndb1\ndev\n"},
+		{[]string{"--tag", "prod"}, "web1\ndb1\n"},
+		{[]string{"--tag", "PROD", "--tag", "db"}, "db1\n"},
+		{[]string{"--tag", "missing"}, ""},
+	}
+	for _, tt := range tests {
+		got, err := runList(t, sshConfig, tt.args...)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("sshm list %v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestListSSHConfigFlag(t *testing.T) {
+	sshConfig := writeListConfig(t)
+
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetArgs([]string{"list", "-F", sshConfig, "--tag", "db"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if out.String() != "db1\n" {
+		t.Errorf("sshm list -F = %q, want %q", out.String(), "db1\n")
+	}
+}
+
+func TestListFormats(t *testing.T) {
+	sshConfig := writeListConfig(t)
+	historyManager, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := historyManager.RecordConnection("db1"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runList(t, sshConfig, "--json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var entries []listEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 hosts, got %+v", entries)
+	}
+	db := entries[1]
+	if db.Name != "db1" || db.User != "postgres" || db.Port != "2222" || strings.Join(db.Tags, ",") != "prod,db" {
+		t.Errorf("Unexpected entry for db1: %+v", db)
+	}
+	if db.LastConnect == nil || time.Since(*db.LastConnect) > time.Minute {
+		t.Errorf("Expected the last connection of db1 from the history, got %v", db.LastConnect)
+	}
+	if entries[0].LastConnect != nil || entries[0].Tags == nil {
+		t.Errorf("Expected web1 never connected, got %+v", entries[0])
+	}
+
+	out, err = runList(t, sshConfig, "--format", "csv", "--tag", "web")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := "name,hostname,user,port,tags,last_connect\nweb1,web1.example.com,,22,prod;web,\n"
+	if out != want {
+		t.Errorf("Expected CSV %q, got %q", want, out)
+	}
+
+	out, err = runList(t, sshConfig, "--format", "table")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "NAME") || !strings.Contains(lines[1], "never") || strings.Contains(lines[2], "never") {
+		t.Errorf("Unexpected table:\n%s", out)
+	}
+
+	if _, err := runList(t, sshConfig, "--format", "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
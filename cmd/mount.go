@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+// sshfsAvailable, mountSSHFS and unmountSSHFS run sshfs; replaced in tests
+var (
+	sshfsAvailable = transfer.IsSSHFSAvailable
+	mountSSHFS     = (*transfer.SSHFSMount).Mount
+	unmountSSHFS   = (*transfer.SSHFSMount).Unmount
+)
+
+var mountCmd = &cobra.Command{
+	Use:   "mount <host> [remote-path] [mount-point]",
+	Short: "Mount the files of a host with SSHFS",
+	Long: `Mount a remote directory locally with SSHFS, so any local tool can use
+its files. The home directory is mounted unless a remote path is given, on a
+new temporary directory unless a mount point is given.
+
+Mounts are remembered until 'sshm unmount' removes them. Requires sshfs.
+
+Examples:
+  # Mount the home directory of a host
+  sshm mount myhost
+
+  # Mount a directory on a chosen mount point
+  sshm mount myhost /var/www ~/mnt/www
+
+  # Unmount everything mounted from the host
+  sshm unmount myhost`,
+	Args: cobra.RangeArgs(1, 3),
+	RunE: runMount,
+}
+
+var unmountCmd = &cobra.Command{
+	Use:   "unmount <host> [mount-point]",
+	Short: "Unmount what 'sshm mount' mounted from a host",
+	Long: `Unmount the SSHFS mounts made by 'sshm mount' for a host, or only the
+one on the given mount point.
+
+Examples:
+  sshm unmount myhost
+  sshm unmount myhost ~/mnt/www`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runUnmount,
+}
+
+func runMount(cmd *cobra.Command, args []string) error {
+	hostName := args[0]
+	out := cmd.OutOrStdout()
+
+	if !sshfsAvailable() {
+		fmt.Fprintln(cmd.ErrOrStderr(), transfer.GetSSHFSInstallInstructions())
+		return fmt.Errorf("sshfs is not installed")
+	}
+
+	// Verify the host exists
+	var hostExists bool
+	var err error
+	if configFile != "" {
+		hostExists, err = config.QuickHostExistsInFile(hostName, configFile)
+	} else {
+		hostExists, err = config.QuickHostExists(hostName)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking SSH config: %w", err)
+	}
+	if !hostExists {
+		return fmt.Errorf("host '%s' not found in SSH configuration", hostName)
+	}
+
+	// An empty remote path mounts the home directory
+	remotePath := ""
+	if len(args) >= 2 {
+		remotePath = args[1]
+	}
+
+	var mount *transfer.SSHFSMount
+	if len(args) == 3 {
+		mount, err = transfer.NewSSHFSMountAt(hostName, remotePath, args[2], configFile)
+	} else {
+		mount, err = transfer.NewSSHFSMount(hostName, remotePath, configFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Mounting %s...\n", transfer.FormatRemoteSpec(hostName, remotePath))
+	if err := mountSSHFS(mount); err != nil {
+		return err
+	}
+	if err := transfer.RecordMount(mount); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not remember the mount, unmount it by hand: %v\n", err)
+	}
+
+	fmt.Fprintf(out, "Mounted on %s\n", mount.MountPoint)
+	fmt.Fprintf(out, "Unmount with: sshm unmount %s\n", hostName)
+	return nil
+}
+
+func runUnmount(cmd *cobra.Command, args []string) error {
+	hostName := args[0]
+	out := cmd.OutOrStdout()
+
+	mounts, err := transfer.HostMounts(hostName)
+	if err != nil {
+		return fmt.Errorf("failed to read the mounts: %w", err)
+	}
+
+	if len(args) == 2 {
+		mountPoint, err := transfer.ExpandPath(args[1])
+		if err != nil {
+			return err
+		}
+		var matching []*transfer.SSHFSMount
+		for _, m := range mounts {
+			if m.MountPoint == mountPoint {
+				matching = append(matching, m)
+			}
+		}
+		if len(matching) == 0 {
+			return fmt.Errorf("nothing from %s is mounted on %s", hostName, mountPoint)
+		}
+		mounts = matching
+	}
+	if len(mounts) == 0 {
+		return fmt.Errorf("nothing is mounted from %s", hostName)
+	}
+
+	var failed int
+	for _, m := range mounts {
+		if err := unmountSSHFS(m); err != nil {
+			// A mount point gone since (e.g. a temporary directory after a reboot) is not mounted anymore
+			if _, statErr := os.Stat(m.MountPoint); !os.IsNotExist(statErr) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to unmount %s: %v\n", m.MountPoint, err)
+				failed++
+				continue
+			}
+		}
+		if err := transfer.ForgetMount(m.MountPoint); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Unmounted %s\n", m.MountPoint)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d mount(s) could not be unmounted", failed)
+	}
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(mountCmd)
+	RootCmd.AddCommand(unmountCmd)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+func TestMountWithoutSSHFS(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	sshfsAvailable = func() bool { return false }
+	defer func() {
+		sshfsAvailable = transfer.IsSSHFSAvailable
+		RootCmd.SetErr(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	errOut := new(bytes.Buffer)
+	RootCmd.SetErr(errOut)
+	RootCmd.SetArgs([]string{"mount", "web"})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "sshfs is not installed") {
+		t.Errorf("Expected the missing sshfs error, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), transfer.GetSSHFSInstallInstructions()) {
+		t.Errorf("Expected the install instructions, got %q", errOut.String())
+	}
+}
+
+func TestUnmount(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	www := &transfer.SSHFSMount{Host: "web", RemotePath: "/var/www", MountPoint: t.TempDir()}
+	logs := &transfer.SSHFSMount{Host: "web", RemotePath: "/var/log", MountPoint: t.TempDir()}
+	db := &transfer.SSHFSMount{Host: "db", MountPoint: t.TempDir()}
+	for _, m := range []*transfer.SSHFSMount{www, logs, db} {
+		if err := transfer.RecordMount(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var unmounted []string
+	unmountSSHFS = func(m *transfer.SSHFSMount) error {
+		if m.MountPoint == logs.MountPoint {
+			return errors.New("device busy")
+		}
+		unmounted = append(unmounted, m.MountPoint)
+		return nil
+	}
+	defer func() {
+		unmountSSHFS = (*transfer.SSHFSMount).Unmount
+		RootCmd.SetOut(nil)
+		RootCmd.SetErr(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetErr(new(bytes.Buffer))
+	RootCmd.SetArgs([]string{"unmount", "web"})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "1 mount(s) could not be unmounted") {
+		t.Errorf("Expected the busy mount to be reported, got %v", err)
+	}
+	if len(unmounted) != 1 || unmounted[0] != www.MountPoint {
+		t.Errorf("Expected only the mounts of web to be unmounted, got %v", unmounted)
+	}
+
+	// The busy mount is still tracked, the others of web are forgotten
+	mounts, err := transfer.LoadMounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remaining []string
+	for _, m := range mounts {
+		remaining = append(remaining, m.MountPoint)
+	}
+	if len(remaining) != 2 || remaining[0] != logs.MountPoint || remaining[1] != db.MountPoint {
+		t.Errorf("Expected the busy mount and the mount of db to remain, got %v", remaining)
+	}
+
+	RootCmd.SetArgs([]string{"unmount", "web", www.MountPoint})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "nothing from web is mounted on") {
+		t.Errorf("Expected an unknown mount point to be refused, got %v", err)
+	}
+}
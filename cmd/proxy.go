@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/connectivity"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+// runSSH runs ssh attached to the terminal; replaced in tests
+var runSSH = func(args []string) error {
+	if err := transfer.RequireBinary("ssh"); err != nil {
+		return err
+	}
+	sshCmd := exec.Command("ssh", args...)
+	sshCmd.Stdin = os.Stdin
+	sshCmd.Stdout = os.Stdout
+	sshCmd.Stderr = os.Stderr
+	return sshCmd.Run()
+}
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy <host> [local-port]",
+	Short: "Run a SOCKS proxy through a host",
+	Long: `Open a SOCKS proxy (ssh -D) on 127.0.0.1 that sends traffic through a host,
+without opening a shell. The proxy listens on port 1080 unless another port is
+given, and runs until Ctrl+C. Point a browser or any SOCKS-aware application
+at it.
+
+The proxy is saved in the port forwarding history of the host, as with the
+forwarding form of the TUI (where P starts one in the background).
+
+Examples:
+  sshm proxy myhost
+  sshm proxy myhost 9050`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runProxy,
+}
+
+func runProxy(cmd *cobra.Command, args []string) error {
+	hostName := args[0]
+	out := cmd.OutOrStdout()
+
+	port := config.DefaultSOCKSPort
+	if len(args) == 2 {
+		var err error
+		port, err = strconv.Atoi(args[1])
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q", args[1])
+		}
+	}
+
+	// Verify the host exists
+	var hostExists bool
+	var err error
+	if configFile != "" {
+		hostExists, err = config.QuickHostExistsInFile(hostName, configFile)
+	} else {
+		hostExists, err = config.QuickHostExists(hostName)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking SSH config: %w", err)
+	}
+	if !hostExists {
+		return fmt.Errorf("host '%s' not found in SSH configuration", hostName)
+	}
+
+	if err := connectivity.CheckLocalPort("", port); err != nil {
+		return err
+	}
+
+	// Record the proxy and the connection, without preventing them
+	if historyManager, err := history.NewHistoryManager(); err == nil {
+		if err := historyManager.RecordPortForwarding(hostName, "dynamic", strconv.Itoa(port), "", "", ""); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: Could not record port forwarding history: %v\n", err)
+		}
+		if err := historyManager.RecordConnection(hostName); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: Could not record connection history: %v\n", err)
+		}
+	}
+
+	fmt.Fprintf(out, "SOCKS proxy on 127.0.0.1:%d through %s (Ctrl+C to stop)\n", port, hostName)
+	return runSSH(config.BuildSOCKSProxyArgs(hostName, configFile, port))
+}
+
+func init() {
+	RootCmd.AddCommand(proxyCmd)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/history"
+)
+
+func TestProxyCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	sshConfig := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(sshConfig, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A free port, so the test doesn't depend on 1080
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("Cannot listen on the loopback interface")
+	}
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	var sshArgs []string
+	originalRunSSH := runSSH
+	runSSH = func(args []string) error {
+		sshArgs = args
+		return nil
+	}
+	defer func() {
+		runSSH = originalRunSSH
+		configFile = ""
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+
+	out := new(bytes.Buffer)
+	RootCmd.SetOut(out)
+
+	// The port is still taken by the listener
+	RootCmd.SetArgs([]string{"proxy", "-c", sshConfig, "web", port})
+	if err := RootCmd.Execute(); err == nil || err.Error() != "local port "+port+" already in use" {
+		t.Errorf("Expected the busy port to be reported, got %v", err)
+	}
+	listener.Close()
+
+	RootCmd.SetArgs([]string{"proxy", "-c", sshConfig, "web", port})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"-D", port, "-N", "-F", sshConfig, "web"}; !reflect.DeepEqual(sshArgs, want) {
+		t.Errorf("ssh args = %q, want %q", sshArgs, want)
+	}
+	if !strings.Contains(out.String(), "SOCKS proxy on 127.0.0.1:"+port) {
+		t.Errorf("Expected the proxy address, got %q", out.String())
+	}
+
+	historyManager, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forward := historyManager.GetPortForwardingConfig("web")
+	if forward == nil || forward.Type != "dynamic" || forward.LocalPort != port {
+		t.Errorf("Expected the proxy in the port forwarding history, got %+v", forward)
+	}
+
+	for _, args := range [][]string{
+		{"proxy", "-c", sshConfig, "missing"},
+		{"proxy", "-c", sshConfig, "web", "70000"},
+	} {
+		RootCmd.SetArgs(args)
+		if err := RootCmd.Execute(); err == nil {
+			t.Errorf("Expected %q to fail", args)
+		}
+	}
+}
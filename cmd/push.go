@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// pushTag selects target hosts by tag instead of an explicit list
+	pushTag string
+	// pushConcurrency limits how many uploads run at once
+	pushConcurrency int
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push <local-file> [host1,host2,...] :<remote-path>",
+	Short: "Upload a file to several hosts at once",
+	Long: `Upload one local file or directory to several SSH hosts concurrently.
+Every host is attempted even if some fail; a summary is printed at the end and
+the command exits with a non-zero status if any upload failed.
+
+Uploads run non-interactively (BatchMode), so hosts must not need a password prompt.
+
+Examples:
+  # Push a file to three hosts
+  sshm push ./nginx.conf web1,web2,web3 :/etc/nginx/
+
+  # Push to every host tagged "web"
+  sshm push ./nginx.conf --tag web :/etc/nginx/
+
+  # Limit to two uploads at a time
+  sshm push ./app.tar.gz web1,web2,web3,web4 :/tmp/ -j 2`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if pushTag != "" {
+			return cobra.ExactArgs(2)(cmd, args)
+		}
+		return cobra.ExactArgs(3)(cmd, args)
+	},
+	RunE: runPush,
+}
+
+func runPush(cmd *cobra.Command, args []string) error {
+	localArg := args[0]
+	remotePath := strings.TrimPrefix(args[len(args)-1], ":")
+
+	// Resolve target hosts
+	var hostNames []string
+	if pushTag != "" {
+		var hosts []config.SSHHost
+		var err error
+		if configFile != "" {
+			hosts, err = config.ParseSSHConfigFile(configFile)
+		} else {
+			hosts, err = config.ParseSSHConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("error reading SSH config: %w", err)
+		}
+		hostNames = hostsWithTag(hosts, pushTag)
+		if len(hostNames) == 0 {
+			return fmt.Errorf("no hosts found with tag '%s'", pushTag)
+		}
+	} else {
+		hostNames = parseHostList(args[1])
+		if len(hostNames) == 0 {
+			return fmt.Errorf("no hosts given")
+		}
+		if err := checkHostsExist(hostNames); err != nil {
+			return err
+		}
+	}
+
+	// Validate the local path
+	localPath, err := transfer.ExpandPath(localArg)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if err := transfer.ValidateLocalPath(localPath, transfer.Upload); err != nil {
+		return err
+	}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+
+	extraArgs, err := scpExtraArgs()
+	if err != nil {
+		return err
+	}
+
+	requests := transfer.BuildFanOutRequests(hostNames, localPath, remotePath, configFile, info.IsDir(), extraArgs)
+
+	fmt.Printf("Uploading %s to %d host(s)...\n", localArg, len(requests))
+	results := transfer.RunFanOut(requests, pushConcurrency, func(req *transfer.TransferRequest) *transfer.TransferResult {
+		result := req.ExecuteBatch()
+		if result.Success {
+			fmt.Printf("  ✓ %s\n", req.Host)
+		} else {
+			fmt.Printf("  ✗ %s: %v\n", req.Host, result.Error)
+		}
+		return result
+	})
+
+	// Record successful uploads in history
+	historyManager, _ := history.NewHistoryManager()
+	if historyManager != nil {
+		for _, r := range results {
+			if r.Result.Success {
+				_ = historyManager.RecordTransfer(r.Host, "upload", localPath, remotePath)
+			}
+		}
+	}
+
+	succeeded, failed := transfer.SummarizeFanOut(results)
+	fmt.Printf("\n%d succeeded, %d failed\n", succeeded, failed)
+	if failed > 0 {
+		return fmt.Errorf("upload failed on %d host(s)", failed)
+	}
+	return nil
+}
+
+// parseHostList splits a comma-separated host list, dropping blanks and duplicates
+func parseHostList(arg string) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, h := range strings.Split(arg, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+// hostsWithTag returns the names of hosts carrying the given tag (case-insensitive)
+func hostsWithTag(hosts []config.SSHHost, tag string) []string {
+	var names []string
+	for _, host := range config.FilterHostsByTags(hosts, []string{tag}) {
+		names = append(names, host.Name)
+	}
+	return names
+}
+
+func init() {
+	RootCmd.AddCommand(pushCmd)
+
+	pushCmd.Flags().StringVar(&pushTag, "tag", "", "Push to all hosts with this tag instead of a host list")
+	pushCmd.Flags().IntVarP(&pushConcurrency, "concurrency", "j", transfer.DefaultFanOutConcurrency, "Maximum number of concurrent uploads")
+	pushCmd.Flags().StringArrayVar(&scpArgs, "scp-arg", nil, "Extra argument to pass to scp (repeatable, e.g. --scp-arg=-O)")
+}
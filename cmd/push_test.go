@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+func TestPushCommandRegistration(t *testing.T) {
+	found := false
+	for _, cmd := range RootCmd.Commands() {
+		if cmd.Name() == "push" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Push command not found in root command")
+	}
+}
+
+func TestPushCommandArgs(t *testing.T) {
+	defer func() { pushTag = "" }()
+
+	// Host list form needs local file, hosts and remote path
+	pushTag = ""
+	if err := pushCmd.Args(pushCmd, []string{"./file", ":/tmp/"}); err == nil {
+		t.Error("Expected error for missing host list")
+	}
+	if err := pushCmd.Args(pushCmd, []string{"./file", "web1,web2", ":/tmp/"}); err != nil {
+		t.Errorf("Expected no error for host list form, got %v", err)
+	}
+
+	// Tag form takes only local file and remote path
+	pushTag = "web"
+	if err := pushCmd.Args(pushCmd, []string{"./file", ":/tmp/"}); err != nil {
+		t.Errorf("Expected no error for tag form, got %v", err)
+	}
+	if err := pushCmd.Args(pushCmd, []string{"./file", "web1", ":/tmp/"}); err == nil {
+		t.Error("Expected error when combining --tag with a host list")
+	}
+}
+
+func TestParseHostList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"web1", []string{"web1"}},
+		{"web1,web2,web3", []string{"web1", "web2", "web3"}},
+		{" web1 , web2 ,", []string{"web1", "web2"}},
+		{"web1,web1,web2", []string{"web1", "web2"}},
+		{",,", nil},
+	}
+
+	for _, tt := range tests {
+		if got := parseHostList(tt.input); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("parseHostList(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestHostsWithTag(t *testing.T) {
+	hosts := []config.SSHHost{
+		{Name: "web1", Tags: []string{"web", "prod"}},
+		{Name: "web2", Tags: []string{"Web"}},
+		{Name: "db1", Tags: []string{"db", "prod"}},
+		{Name: "dev", Tags: nil},
+	}
+
+	if got := hostsWithTag(hosts, "web"); !reflect.DeepEqual(got, []string{"web1", "web2"}) {
+		t.Errorf("hostsWithTag(web) = %v", got)
+	}
+	if got := hostsWithTag(hosts, "prod"); !reflect.DeepEqual(got, []string{"web1", "db1"}) {
+		t.Errorf("hostsWithTag(prod) = %v", got)
+	}
+	if got := hostsWithTag(hosts, "staging"); got != nil {
+		t.Errorf("hostsWithTag(staging) = %v, want none", got)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
 	"github.com/Gu1llaum-3/sshm/internal/ui"
 	"github.com/Gu1llaum-3/sshm/internal/version"
 
@@ -24,6 +25,17 @@ var AppVersion = "dev"
 // configFile holds the path to the SSH config file
 var configFile string
 
+var (
+	// connectIdentity forces a specific identity file for a single connection
+	connectIdentity string
+	// saveIdentity persists connectIdentity as the host's IdentityFile
+	saveIdentity bool
+	// noAltScreen runs the TUI without the alternate screen
+	noAltScreen bool
+	// favoritesOnly starts the TUI listing only the favorite hosts
+	favoritesOnly bool
+)
+
 // RootCmd is the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "sshm [host]",
@@ -41,6 +53,11 @@ Hosts are read from your ~/.ssh/config file by default.`,
 	Args:          cobra.ArbitraryArgs,
 	SilenceUsage:  true,
 	SilenceErrors: true, // We'll handle errors ourselves
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if noAltScreen {
+			ui.DisableAltScreen()
+		}
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// If no arguments provided, run interactive mode
 		if len(args) == 0 {
@@ -97,7 +114,7 @@ func runInteractiveMode() {
 	}
 
 	// Run the interactive TUI
-	if err := ui.RunInteractiveMode(hosts, configFile, AppVersion); err != nil {
+	if err := ui.RunInteractiveMode(hosts, configFile, AppVersion, favoritesOnly); err != nil {
 		log.Fatalf("Error running interactive mode: %v", err)
 	}
 }
@@ -123,12 +140,20 @@ func connectToHost(hostName string) {
 		os.Exit(1)
 	}
 
+	if err := transfer.RequireBinary("ssh"); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Record the connection in history
 	historyManager, err := history.NewHistoryManager()
 	if err != nil {
 		// Log the error but don't prevent the connection
 		fmt.Printf("Warning: Could not initialize connection history: %v\n", err)
 	} else {
+		if warning := historyManager.RecoveryWarning(); warning != "" {
+			fmt.Printf("Warning: %s\n", warning)
+		}
 		err = historyManager.RecordConnection(hostName)
 		if err != nil {
 			// Log the error but don't prevent the connection
@@ -136,16 +161,18 @@ func connectToHost(hostName string) {
 		}
 	}
 
+	// Persist the identity only when explicitly asked
+	if connectIdentity != "" && saveIdentity {
+		if err := config.SetHostIdentity(hostName, connectIdentity, configFile); err != nil {
+			fmt.Printf("Warning: Could not save identity for %s: %v\n", hostName, err)
+		}
+	}
+
 	// Build and execute the SSH command
 	fmt.Printf("Connecting to %s...\n", hostName)
 
 	var sshCmd *exec.Cmd
-	var args []string
-
-	if configFile != "" {
-		args = append(args, "-F", configFile)
-	}
-	args = append(args, hostName)
+	args := config.BuildConnectArgs(hostName, configFile, connectIdentity)
 
 	// Note: We don't add RemoteCommand here because if it's configured in SSH config,
 	// SSH will handle it automatically. Adding it as a command line argument would conflict.
@@ -197,6 +224,11 @@ func getVersionWithUpdateCheck() string {
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
+	// scp and rsync run sshm as their SSH_ASKPASS helper for password logins
+	if code, ok := transfer.RunAskpass(os.Args[1:], os.Stdout); ok {
+		os.Exit(code)
+	}
+
 	// Custom error handling for unknown commands that might be host names
 	if err := RootCmd.Execute(); err != nil {
 		// Check if this is an "unknown command" error and the argument might be a host name
@@ -216,9 +248,29 @@ func Execute() {
 	}
 }
 
+// favoritesFlagUsage describes --favorites, whose mode key shows all hosts
+func favoritesFlagUsage(key string) string {
+	return fmt.Sprintf("Start the TUI listing only the favorite hosts (%s shows all)", key)
+}
+
 func init() {
 	// Add the config file flag
 	RootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "SSH config file to use (default: ~/.ssh/config)")
+	RootCmd.PersistentFlags().BoolVar(&noAltScreen, "no-altscreen", false, "Run the TUI in the normal terminal buffer, keeping scrollback")
+
+	// Identity override for direct connections
+	RootCmd.Flags().StringVarP(&connectIdentity, "identity", "i", "", "Identity file to use for this connection only (adds -i and IdentitiesOnly=yes)")
+	RootCmd.Flags().BoolVar(&saveIdentity, "save-identity", false, "Save the --identity file as the host's IdentityFile")
+	RootCmd.Flags().BoolVar(&favoritesOnly, "favorites", false, favoritesFlagUsage("O"))
+
+	// The key showing all hosts again is the one configured, read when needed
+	defaultHelp := RootCmd.HelpFunc()
+	RootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if cmd == RootCmd {
+			RootCmd.Flags().Lookup("favorites").Usage = favoritesFlagUsage(ui.FavoritesOnlyKey())
+		}
+		defaultHelp(cmd, args)
+	})
 
 	// Set custom version template with update check
 	RootCmd.SetVersionTemplate(getVersionWithUpdateCheck())
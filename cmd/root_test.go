@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -34,6 +36,11 @@ func TestRootCommandFlags(t *testing.T) {
 	if configFlag.Shorthand != "c" {
 		t.Errorf("Expected config flag shorthand 'c', got '%s'", configFlag.Shorthand)
 	}
+
+	// Check no-altscreen flag, inherited by the transfer and form subcommands
+	if flags.Lookup("no-altscreen") == nil {
+		t.Error("Expected --no-altscreen flag to be defined")
+	}
 }
 
 func TestRootCommandSubcommands(t *testing.T) {
@@ -80,6 +87,31 @@ func TestRootCommandHelp(t *testing.T) {
 	}
 }
 
+func TestFavoritesFlagHelpUsesConfiguredKey(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.KeyBindings.Actions = map[string]string{config.ActionFavoritesOnly: "ctrl+o"}
+	if err := config.SaveAppConfig(&appConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	RootCmd.SetOut(buf)
+	RootCmd.SetArgs([]string{"--help"})
+	defer func() {
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs([]string{})
+	}()
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "favorite hosts (ctrl+o shows all)") {
+		t.Errorf("Expected --favorites to name the configured key, got %q", buf.String())
+	}
+}
+
 func TestRootCommandVersion(t *testing.T) {
 	// Test that version command executes without error
 	// Note: Cobra handles version output internally, so we just check for no error
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Prefixes of the files in a backup archive, telling where they are restored
+const (
+	backupSSHMPrefix = "sshm/" // Relative to the sshm config dir
+	backupHomePrefix = "home/" // Relative to the home directory
+	backupAbsPrefix  = "abs/"  // Absolute path, for configs outside the home directory
+)
+
+// BackupEntry is a file saved in a backup archive
+type BackupEntry struct {
+	Name string // Path inside the archive
+	Path string // Path on disk
+}
+
+// BackupArchiveName returns the file name of a backup archive made at t
+func BackupArchiveName(t time.Time) string {
+	return "sshm-backup-" + t.Format("20060102-150405") + ".tar.gz"
+}
+
+// BackupEntries lists the files to back up: the SSH config starting at
+// baseConfigPath (the default one when empty) with the files it includes,
+// and the sshm config dir without its backups
+func BackupEntries(baseConfigPath string) ([]BackupEntry, error) {
+	configFiles, err := GetAllConfigFilesFromBase(baseConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(configFiles)
+
+	var paths []string
+	for _, file := range configFiles {
+		if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() {
+			paths = append(paths, file)
+		}
+	}
+
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	backupDir, err := GetSSHMBackupDir()
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.WalkDir(configDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && file == configDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() && file == backupDir {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			paths = append(paths, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []BackupEntry
+	seen := make(map[string]bool)
+	for _, file := range paths {
+		name, err := backupEntryName(file)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			entries = append(entries, BackupEntry{Name: name, Path: file})
+		}
+	}
+	return entries, nil
+}
+
+// backupEntryName returns the name a file is stored under in a backup archive
+func backupEntryName(file string) (string, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(configDir, file); err == nil && filepath.IsLocal(rel) {
+		return backupSSHMPrefix + filepath.ToSlash(rel), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(homeDir, file); err == nil && filepath.IsLocal(rel) {
+		return backupHomePrefix + filepath.ToSlash(rel), nil
+	}
+
+	return backupAbsPrefix + strings.TrimPrefix(filepath.ToSlash(file), "/"), nil
+}
+
+// backupEntryPath returns where a file of a backup archive is restored,
+// refusing names that would escape their directory
+func backupEntryPath(name string) (string, error) {
+	if rel, ok := strings.CutPrefix(name, backupAbsPrefix); ok {
+		file := filepath.FromSlash(rel)
+		if !filepath.IsAbs(file) {
+			file = filepath.FromSlash("/" + rel)
+		}
+		if !filepath.IsAbs(file) || path.Clean("/"+rel) != "/"+rel {
+			return "", fmt.Errorf("invalid path %q in backup archive", name)
+		}
+		return file, nil
+	}
+
+	var base, rel string
+	var err error
+	if r, ok := strings.CutPrefix(name, backupSSHMPrefix); ok {
+		rel = r
+		base, err = GetSSHMConfigDir()
+	} else if r, ok := strings.CutPrefix(name, backupHomePrefix); ok {
+		rel = r
+		base, err = os.UserHomeDir()
+	} else {
+		return "", fmt.Errorf("unexpected file %q in backup archive", name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	rel = filepath.FromSlash(rel)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid path %q in backup archive", name)
+	}
+	return filepath.Join(base, rel), nil
+}
+
+// WriteBackupArchive writes entries to w as a gzipped tar archive
+func WriteBackupArchive(w io.Writer, entries []BackupEntry) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, entry := range entries {
+		if err := addBackupFile(tw, entry); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", entry.Path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func addBackupFile(tw *tar.Writer, entry BackupEntry) error {
+	file, err := os.Open(entry.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{
+		Name:    entry.Name,
+		Mode:    int64(info.Mode().Perm()),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, file)
+	return err
+}
+
+// ListBackupArchive lists the files of a backup archive with where they are restored
+func ListBackupArchive(archivePath string) ([]BackupEntry, error) {
+	var entries []BackupEntry
+	err := readBackupArchive(archivePath, func(entry BackupEntry, _ *tar.Header, _ io.Reader) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// RestoreBackupArchive writes the files of a backup archive back in place,
+// overwriting the current ones, and returns them
+func RestoreBackupArchive(archivePath string) ([]BackupEntry, error) {
+	// Check every name before writing anything
+	if _, err := ListBackupArchive(archivePath); err != nil {
+		return nil, err
+	}
+
+	var restored []BackupEntry
+	err := readBackupArchive(archivePath, func(entry BackupEntry, header *tar.Header, content io.Reader) error {
+		if err := os.MkdirAll(filepath.Dir(entry.Path), 0700); err != nil {
+			return err
+		}
+		mode := os.FileMode(header.Mode).Perm()
+		file, err := os.OpenFile(entry.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, content); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		// OpenFile keeps the mode of files that already exist
+		if err := os.Chmod(entry.Path, mode); err != nil {
+			return err
+		}
+		restored = append(restored, entry)
+		return nil
+	})
+	return restored, err
+}
+
+// readBackupArchive calls fn for every file of a backup archive
+func readBackupArchive(archivePath string, fn func(BackupEntry, *tar.Header, io.Reader) error) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("%s is not a backup archive: %w", archivePath, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("unexpected entry %q in backup archive", header.Name)
+		}
+
+		target, err := backupEntryPath(header.Name)
+		if err != nil {
+			return err
+		}
+		if err := fn(BackupEntry{Name: header.Name, Path: target}, header, tr); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", target, err)
+		}
+	}
+}
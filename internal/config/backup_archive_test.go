@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// setupBackupHome creates an SSH config with an include and some sshm data in a temp HOME
+func setupBackupHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	files := map[string]string{
+		".ssh/config":                     "Include conf.d/*\n\nHost web\n    HostName web.example.com\n",
+		".ssh/conf.d/work":                "Host db\n    HostName db.example.com\n",
+		".config/sshm/sshm_history.json":  `{"connections":{}}`,
+		".config/sshm/config.json":        `{}`,
+		".config/sshm/backups/old.backup": "skipped",
+	}
+	for name, content := range files {
+		path := filepath.Join(home, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return home
+}
+
+func backupEntryNames(entries []BackupEntry) []string {
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+func TestBackupEntries(t *testing.T) {
+	setupBackupHome(t)
+
+	entries, err := BackupEntries("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"home/.ssh/conf.d/work",
+		"home/.ssh/config",
+		"sshm/config.json",
+		"sshm/sshm_history.json",
+	}
+	if got := backupEntryNames(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("BackupEntries() = %v, want %v", got, want)
+	}
+}
+
+func TestBackupArchiveRestore(t *testing.T) {
+	home := setupBackupHome(t)
+	entries, err := BackupEntries("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), BackupArchiveName(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)))
+	var buf bytes.Buffer
+	if err := WriteBackupArchive(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archive, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := ListBackupArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(listed, entries) {
+		t.Errorf("ListBackupArchive() = %v, want %v", listed, entries)
+	}
+
+	// Restore into another home
+	newHome := t.TempDir()
+	t.Setenv("HOME", newHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(newHome, ".config"))
+	if err := os.MkdirAll(filepath.Join(newHome, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(newHome, ".ssh", "config"), []byte("Host other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := RestoreBackupArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != len(entries) {
+		t.Fatalf("Expected %d restored files, got %v", len(entries), restored)
+	}
+	for _, entry := range entries {
+		rel, _ := filepath.Rel(home, entry.Path)
+		target := filepath.Join(newHome, rel)
+		want, _ := os.ReadFile(entry.Path)
+		got, err := os.ReadFile(target)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("Expected %s to be restored, got %q (%v)", target, got, err)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(newHome, ".ssh", "config")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the restored config to keep mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestBackupEntryPathRejectsEscapes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, name := range []string{"home/../etc/passwd", "sshm/../../x", "abs/etc/../root/x", "other/file", "home/"} {
+		if _, err := backupEntryPath(name); err == nil {
+			t.Errorf("Expected %q to be refused", name)
+		}
+	}
+	if got, err := backupEntryPath("abs/etc/ssh/ssh_config"); err != nil || got != filepath.FromSlash("/etc/ssh/ssh_config") {
+		t.Errorf("backupEntryPath() = %q, %v", got, err)
+	}
+}
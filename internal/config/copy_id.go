@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ListPublicKeys returns the public keys from the ~/.ssh directory, the
+// candidates for installing on a host with ssh-copy-id
+func ListPublicKeys() ([]string, error) {
+	sshDir, err := GetSSHDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return listPublicKeysInDir(sshDir)
+}
+
+// listPublicKeysInDir returns the .pub files found in dir, leaving out certificates
+func listPublicKeysInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".pub") || strings.HasSuffix(name, "-cert.pub") {
+			continue
+		}
+		keys = append(keys, filepath.Join(dir, name))
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// BuildCopyIDArgs returns the ssh-copy-id arguments installing publicKey on a host
+func BuildCopyIDArgs(hostName, configFile, publicKey string) []string {
+	args := []string{"-i", publicKey}
+	if configFile != "" {
+		args = append(args, "-F", configFile)
+	}
+	return append(args, hostName)
+}
+
+// publicKeyBlob returns the base64 key material of a public key line,
+// which identifies the key whatever its comment or options
+func publicKeyBlob(line string) string {
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		if isPublicKeyType(field) && i+1 < len(fields) {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+// isPublicKeyType reports whether field names an SSH key type, e.g.
+// ssh-ed25519, ecdsa-sha2-nistp256 or sk-ssh-ed25519@openssh.com
+func isPublicKeyType(field string) bool {
+	return strings.HasPrefix(field, "ssh-") ||
+		strings.HasPrefix(field, "ecdsa-") ||
+		strings.HasPrefix(field, "sk-")
+}
+
+// PublicKeyInstalled reports whether the public key is listed in the
+// content of an authorized_keys file
+func PublicKeyInstalled(publicKey, authorizedKeys string) bool {
+	blob := publicKeyBlob(publicKey)
+	if blob == "" {
+		return false
+	}
+
+	for _, line := range strings.Split(authorizedKeys, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if publicKeyBlob(line) == blob {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListPublicKeysInDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"id_ed25519", "id_ed25519.pub", "work.pub", "work-cert.pub", "config", "known_hosts"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "old.pub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := listPublicKeysInDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join(dir, "id_ed25519.pub"), filepath.Join(dir, "work.pub")}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("listPublicKeysInDir() = %v, want %v", keys, expected)
+	}
+}
+
+func TestBuildCopyIDArgs(t *testing.T) {
+	expected := []string{"-i", "/home/user/.ssh/work.pub", "myserver"}
+	if args := BuildCopyIDArgs("myserver", "", "/home/user/.ssh/work.pub"); !reflect.DeepEqual(args, expected) {
+		t.Errorf("BuildCopyIDArgs() = %v, want %v", args, expected)
+	}
+
+	expected = []string{"-i", "/home/user/.ssh/work.pub", "-F", "/tmp/ssh_config", "myserver"}
+	if args := BuildCopyIDArgs("myserver", "/tmp/ssh_config", "/home/user/.ssh/work.pub"); !reflect.DeepEqual(args, expected) {
+		t.Errorf("BuildCopyIDArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestPublicKeyInstalled(t *testing.T) {
+	key := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKey1 me@laptop\n"
+	authorized := `# managed by hand
+ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQOther other@host
+from="10.0.0.0/8",no-pty ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKey1 renamed comment
+`
+
+	if !PublicKeyInstalled(key, authorized) {
+		t.Error("Expected the key to be found despite its options and comment")
+	}
+	if PublicKeyInstalled("ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKey2 me@laptop", authorized) {
+		t.Error("Expected a different key not to be found")
+	}
+	if PublicKeyInstalled(key, "") {
+		t.Error("Expected nothing to be installed with an empty authorized_keys")
+	}
+	if PublicKeyInstalled("not a key", authorized) {
+		t.Error("Expected an unparsable key not to match")
+	}
+}
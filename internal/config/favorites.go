@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// favoritesData is the on-disk format of the favorite hosts
+type favoritesData struct {
+	Hosts []string `json:"hosts"`
+}
+
+// GetFavoritesPath returns the path to the file of favorite hosts
+func GetFavoritesPath() (string, error) {
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "sshm_favorites.json"), nil
+}
+
+// LoadFavorites returns the names of the favorite hosts. A missing file
+// means none was marked yet.
+func LoadFavorites() (map[string]bool, error) {
+	favorites := make(map[string]bool)
+
+	path, err := GetFavoritesPath()
+	if err != nil {
+		return favorites, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return favorites, nil
+		}
+		return favorites, err
+	}
+
+	var stored favoritesData
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return favorites, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, host := range stored.Hosts {
+		favorites[host] = true
+	}
+	return favorites, nil
+}
+
+// SetFavorite marks a host as favorite, or removes the mark
+func SetFavorite(hostName string, favorite bool) error {
+	favorites, err := LoadFavorites()
+	if err != nil {
+		return err
+	}
+	if favorite {
+		favorites[hostName] = true
+	} else {
+		delete(favorites, hostName)
+	}
+
+	stored := favoritesData{Hosts: make([]string, 0, len(favorites))}
+	for host := range favorites {
+		stored.Hosts = append(stored.Hosts, host)
+	}
+	sort.Strings(stored.Hosts)
+
+	path, err := GetFavoritesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0600)
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFavorite(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	favorites, err := LoadFavorites()
+	if err != nil || len(favorites) != 0 {
+		t.Fatalf("Expected no favorites before any is marked, got %v, %v", favorites, err)
+	}
+
+	for _, host := range []string{"web", "db"} {
+		if err := SetFavorite(host, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := SetFavorite("web", false); err != nil {
+		t.Fatal(err)
+	}
+
+	favorites, err = LoadFavorites()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorites) != 1 || !favorites["db"] {
+		t.Errorf("Expected only db to stay a favorite, got %v", favorites)
+	}
+
+	// The file is replaced whole, no temporary file is left next to it
+	path, err := GetFavoritesPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("Unexpected temporary file %s next to the favorites", entry.Name())
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the favorites readable by the user only, got %v, %v", info, err)
+	}
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConfiguredForward is a LocalForward, RemoteForward or DynamicForward from
+// the SSH config of a host, as resolved by `ssh -G`
+type ConfiguredForward struct {
+	Flag   string // ssh flag opening the forward: -L, -R or -D
+	Listen string // e.g. 8080 or [127.0.0.1]:8080
+	Target string // e.g. [localhost]:80, empty for dynamic forwards
+}
+
+// forwardFlags maps the `ssh -G` forwarding keys to their ssh flags
+var forwardFlags = map[string]string{
+	"localforward":   "-L",
+	"remoteforward":  "-R",
+	"dynamicforward": "-D",
+}
+
+// Spec returns the forward in the syntax of its ssh flag
+func (f ConfiguredForward) Spec() string {
+	if f.Target == "" {
+		return f.Listen
+	}
+	return f.Listen + ":" + f.Target
+}
+
+func (f ConfiguredForward) String() string {
+	switch f.Flag {
+	case "-L":
+		return "LocalForward " + f.Listen + " → " + f.Target
+	case "-R":
+		if f.Target == "" {
+			return "RemoteForward " + f.Listen + " (SOCKS)"
+		}
+		return "RemoteForward " + f.Listen + " → " + f.Target
+	default:
+		return "DynamicForward " + f.Listen + " (SOCKS)"
+	}
+}
+
+// ConfiguredForwards returns the port forwardings among resolved options
+func ConfiguredForwards(options []ResolvedOption) []ConfiguredForward {
+	var forwards []ConfiguredForward
+	for _, opt := range options {
+		flag, ok := forwardFlags[opt.Key]
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(opt.Value)
+		if len(fields) == 0 {
+			continue
+		}
+		forward := ConfiguredForward{Flag: flag, Listen: fields[0]}
+		// ssh -G shows a RemoteForward without target (remote SOCKS) as [socks]:0
+		if len(fields) > 1 && fields[1] != "[socks]:0" {
+			forward.Target = fields[1]
+		}
+		forwards = append(forwards, forward)
+	}
+	return forwards
+}
+
+// isForwardLine reports whether a config line is a LocalForward,
+// RemoteForward or DynamicForward directive
+func isForwardLine(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	keyword, _, _ := strings.Cut(fields[0], "=")
+	_, ok := forwardFlags[strings.ToLower(keyword)]
+	return ok
+}
+
+// WriteForwardlessConfig copies an ssh config file, the default one when
+// configFile is empty, to a temporary directory without its port forwardings,
+// and returns the path of the copy. The files it includes are copied the same
+// way and its Include lines name the copies, so Host and Match blocks,
+// multi-host lines and Include apply as they do in the original. The forwards
+// of every block are left out, as the picker lists all those applying to the
+// host and the selected ones are given on the command line.
+// ClearAllForwardings can't be used instead: it also clears the -L/-R/-D
+// flags given on the command line. RemoveForwardlessConfig removes the copy.
+func WriteForwardlessConfig(configFile string) (string, error) {
+	if configFile == "" {
+		var err error
+		if configFile, err = GetDefaultSSHConfigPath(); err != nil {
+			return "", err
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "sshm-forwards-*")
+	if err != nil {
+		return "", err
+	}
+	w := forwardlessWriter{dir: dir, copies: make(map[string]string)}
+	path, err := w.copy(configFile)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return path, nil
+}
+
+// RemoveForwardlessConfig removes a config written by WriteForwardlessConfig
+// along with the copies of the files it includes
+func RemoveForwardlessConfig(path string) error {
+	return os.RemoveAll(filepath.Dir(path))
+}
+
+// forwardlessWriter copies config files for WriteForwardlessConfig
+type forwardlessWriter struct {
+	dir    string
+	copies map[string]string // Path of the copy of each file copied
+}
+
+// copy copies a config file and those it includes, and returns the path of
+// the copy
+func (w *forwardlessWriter) copy(path string) (string, error) {
+	if copied, ok := w.copies[path]; ok {
+		return copied, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	// Known before its includes are copied, so that include loops end
+	copied := filepath.Join(w.dir, fmt.Sprintf("config-%d", len(w.copies)))
+	w.copies[path] = copied
+
+	var lines []string
+	for _, line := range configLines(content) {
+		switch {
+		case isForwardLine(line):
+			continue
+		case isIncludeLine(line):
+			if line, err = w.copyIncludes(line, path); err != nil {
+				return "", err
+			}
+			if line == "" {
+				continue // It includes nothing
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	if err := os.WriteFile(copied, joinConfigLines(lines, content), 0600); err != nil {
+		return "", err
+	}
+	return copied, nil
+}
+
+// copyIncludes copies the files an Include line of the file at basePath
+// names, and returns the line naming the copies, empty when it names none
+func (w *forwardlessWriter) copyIncludes(line, basePath string) (string, error) {
+	var copies []string
+	for _, pattern := range strings.Fields(line)[1:] {
+		if strings.HasPrefix(pattern, "#") {
+			break
+		}
+		matches, err := includeMatches(strings.Trim(pattern, `"`), basePath)
+		if err != nil {
+			return "", err
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() {
+				continue
+			}
+			copied, err := w.copy(match)
+			if err != nil {
+				return "", err
+			}
+			copies = append(copies, formatSSHConfigValue(copied))
+		}
+	}
+	if len(copies) == 0 {
+		return "", nil
+	}
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	return indent + "Include " + strings.Join(copies, " "), nil
+}
+
+// BuildSelectiveForwardArgs returns the ssh arguments connecting to a host
+// with only the selected forwards, using a config written by WriteForwardlessConfig
+func BuildSelectiveForwardArgs(hostName, forwardlessConfig string, selected []ConfiguredForward) []string {
+	args := []string{"-F", forwardlessConfig}
+	for _, forward := range selected {
+		args = append(args, forward.Flag, forward.Spec())
+	}
+	return append(args, hostName)
+}
+
+// DefaultSOCKSPort is the local port a SOCKS proxy listens on by default
+const DefaultSOCKSPort = 1080
+
+// BuildSOCKSProxyArgs returns the ssh arguments running only a SOCKS proxy
+// (a dynamic forward) through a host, without a shell
+func BuildSOCKSProxyArgs(hostName, configFile string, port int) []string {
+	args := []string{"-D", strconv.Itoa(port), "-N"}
+	if configFile != "" {
+		args = append(args, "-F", configFile)
+	}
+	return append(args, hostName)
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// forwardsOutput is `ssh -G` output for a host with every kind of forward
+const forwardsOutput = `host db
+hostname db.internal
+user deploy
+clearallforwardings no
+dynamicforward 1080
+localforward 5432 [localhost]:5432
+localforward [127.0.0.1]:8080 [web.internal]:80
+remoteforward 9000 [localhost]:9000
+remoteforward 9090 [socks]:0
+forwardagent no
+`
+
+func TestConfiguredForwards(t *testing.T) {
+	forwards := ConfiguredForwards(ParseResolvedConfig(forwardsOutput))
+
+	expected := []ConfiguredForward{
+		{Flag: "-D", Listen: "1080"},
+		{Flag: "-L", Listen: "5432", Target: "[localhost]:5432"},
+		{Flag: "-L", Listen: "[127.0.0.1]:8080", Target: "[web.internal]:80"},
+		{Flag: "-R", Listen: "9000", Target: "[localhost]:9000"},
+		{Flag: "-R", Listen: "9090"},
+	}
+	if !reflect.DeepEqual(forwards, expected) {
+		t.Fatalf("ConfiguredForwards() = %+v, want %+v", forwards, expected)
+	}
+
+	specs := []string{"1080", "5432:[localhost]:5432", "[127.0.0.1]:8080:[web.internal]:80", "9000:[localhost]:9000", "9090"}
+	for i, forward := range forwards {
+		if forward.Spec() != specs[i] {
+			t.Errorf("Spec() = %q, want %q", forward.Spec(), specs[i])
+		}
+	}
+
+	if got := ConfiguredForwards(ParseResolvedConfig("hostname web\nforwardagent yes\n")); got != nil {
+		t.Errorf("Expected no forwards, got %+v", got)
+	}
+}
+
+func TestBuildSelectiveForwardArgs(t *testing.T) {
+	selected := []ConfiguredForward{
+		{Flag: "-L", Listen: "5432", Target: "[localhost]:5432"},
+		{Flag: "-D", Listen: "1080"},
+	}
+
+	expected := []string{"-F", "/tmp/sshm-forwards.conf", "-L", "5432:[localhost]:5432", "-D", "1080", "db"}
+	if args := BuildSelectiveForwardArgs("db", "/tmp/sshm-forwards.conf", selected); !reflect.DeepEqual(args, expected) {
+		t.Errorf("BuildSelectiveForwardArgs() = %v, want %v", args, expected)
+	}
+
+	// With nothing selected, no forward is opened at all
+	expected = []string{"-F", "/tmp/sshm-forwards.conf", "db"}
+	if args := BuildSelectiveForwardArgs("db", "/tmp/sshm-forwards.conf", nil); !reflect.DeepEqual(args, expected) {
+		t.Errorf("BuildSelectiveForwardArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestWriteForwardlessConfig(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(configFile, `Include conf.d/*
+Include missing/*
+
+Host db db-replica
+    HostName db.internal
+    User deploy
+    LocalForward 5432 localhost:5432
+    DynamicForward=1080
+
+Match host db exec "true"
+    RemoteForward 9000 localhost:9000
+    ForwardAgent no
+`)
+	writeFile(filepath.Join(dir, "conf.d", "web"), "Host web\n    HostName web.internal\n    LocalForward 8080 localhost:80\n    Include ../config\n")
+
+	path, err := WriteForwardlessConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveForwardlessConfig(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	include := strings.Fields(lines[0])
+	if len(include) != 2 || include[0] != "Include" || filepath.Dir(include[1]) != filepath.Dir(path) {
+		t.Fatalf("Expected the include to name a copy next to the config, got %q", lines[0])
+	}
+	expected := `
+Host db db-replica
+    HostName db.internal
+    User deploy
+
+Match host db exec "true"
+    ForwardAgent no
+`
+	if rest := strings.Join(lines[1:], "\n"); rest != expected {
+		t.Errorf("Config = %q, want %q", rest, expected)
+	}
+
+	// The included file loses its forwards too, and its include of the
+	// config comes back to the copy
+	included, err := os.ReadFile(include[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Host web\n    HostName web.internal\n    Include " + path + "\n"; string(included) != want {
+		t.Errorf("Included config = %q, want %q", included, want)
+	}
+
+	if err := RemoveForwardlessConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Error("Expected the copies to be removed")
+	}
+}
+
+func TestBuildSOCKSProxyArgs(t *testing.T) {
+	expected := []string{"-D", "1080", "-N", "web"}
+	if args := BuildSOCKSProxyArgs("web", "", DefaultSOCKSPort); !reflect.DeepEqual(args, expected) {
+		t.Errorf("BuildSOCKSProxyArgs() = %v, want %v", args, expected)
+	}
+
+	expected = []string{"-D", "9050", "-N", "-F", "/tmp/my config", "web"}
+	if args := BuildSOCKSProxyArgs("web", "/tmp/my config", 9050); !reflect.DeepEqual(args, expected) {
+		t.Errorf("BuildSOCKSProxyArgs() = %v, want %v", args, expected)
+	}
+}
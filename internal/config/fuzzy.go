@@ -0,0 +1,186 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Fuzzy match scoring, in the spirit of fzf: every matched character scores,
+// matches at the start of a word and right after the previous match score
+// more, and characters skipped between two matches cost a little
+const (
+	fuzzyScoreMatch       = 16
+	fuzzyBonusBoundary    = 8
+	fuzzyBonusFirstChar   = 8
+	fuzzyBonusConsecutive = 8
+	fuzzyPenaltyGapStart  = 3
+	fuzzyPenaltyGapExtend = 1
+	fuzzyBonusNameMatch   = 8
+)
+
+// HostMatch is a host found by FuzzyMatchHosts
+type HostMatch struct {
+	Host  SSHHost
+	Score int
+	// NamePositions are the byte offsets in Host.Name of the characters
+	// matched by the query, for highlighting
+	NamePositions []int
+}
+
+// FuzzyMatchHosts returns the hosts matching every word of the query as a
+// case-insensitive subsequence of their name, hostname, user or one of their
+// tags, best matches first. Hosts with the same score keep their order. An
+// empty query returns every host.
+//
+// The positions of all hosts share one buffer, so searching a long list does
+// not allocate per host.
+func FuzzyMatchHosts(hosts []SSHHost, query string) []HostMatch {
+	words := strings.Fields(query)
+	matches := make([]HostMatch, 0, len(hosts))
+	if len(words) == 0 {
+		for _, host := range hosts {
+			matches = append(matches, HostMatch{Host: host})
+		}
+		return matches
+	}
+
+	var positions []int
+	for _, host := range hosts {
+		start := len(positions)
+		total, matched := 0, true
+		for _, word := range words {
+			best, found := 0, false
+			if score, pos, ok := FuzzyMatch(host.Name, word, positions); ok {
+				best, found = score+fuzzyBonusNameMatch, true
+				positions = pos
+			}
+			for _, field := range [...]string{host.Hostname, host.User} {
+				if score, _, ok := FuzzyMatch(field, word, positions); ok && (!found || score > best) {
+					best, found = score, true
+				}
+			}
+			for _, tag := range host.Tags {
+				if score, _, ok := FuzzyMatch(tag, word, positions); ok && (!found || score > best) {
+					best, found = score, true
+				}
+			}
+			if !found {
+				matched = false
+				break
+			}
+			total += best
+		}
+		if !matched {
+			positions = positions[:start]
+			continue
+		}
+		matches = append(matches, HostMatch{
+			Host:          host,
+			Score:         total,
+			NamePositions: positions[start:len(positions):len(positions)],
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// FuzzyMatch reports whether pattern is a case-insensitive subsequence of
+// text and scores the match. The byte offsets of the matched characters are
+// appended to positions, which is returned unchanged when there is no match.
+func FuzzyMatch(text, pattern string, positions []int) (int, []int, bool) {
+	if pattern == "" {
+		return 0, positions, true
+	}
+
+	// Find the first window of text that contains the pattern, then walk it
+	// back from its end so that the match is as tight as possible
+	end := -1
+	p := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		pr, psize := utf8.DecodeRuneInString(pattern[p:])
+		if foldRune(r) == foldRune(pr) {
+			p += psize
+			if p == len(pattern) {
+				end = i + size
+				break
+			}
+		}
+		i += size
+	}
+	if end < 0 {
+		return 0, positions, false
+	}
+
+	begin := end
+	p = len(pattern)
+	for begin > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:begin])
+		pr, psize := utf8.DecodeLastRuneInString(pattern[:p])
+		begin -= size
+		if foldRune(r) == foldRune(pr) {
+			p -= psize
+			if p == 0 {
+				break
+			}
+		}
+	}
+
+	score := 0
+	p = 0
+	last := -1
+	var prev rune
+	if begin > 0 {
+		prev, _ = utf8.DecodeLastRuneInString(text[:begin])
+	}
+	for i := begin; i < end && p < len(pattern); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		pr, psize := utf8.DecodeRuneInString(pattern[p:])
+		if foldRune(r) == foldRune(pr) {
+			score += fuzzyScoreMatch
+			if i == 0 {
+				score += fuzzyBonusBoundary + fuzzyBonusFirstChar
+			} else if isWordStart(prev, r) {
+				score += fuzzyBonusBoundary
+			}
+			if last >= 0 {
+				if gap := i - last; gap == 0 {
+					score += fuzzyBonusConsecutive
+				} else {
+					score -= fuzzyPenaltyGapStart + (gap-1)*fuzzyPenaltyGapExtend
+				}
+			}
+			positions = append(positions, i)
+			last = i + size
+			p += psize
+		}
+		prev = r
+		i += size
+	}
+	return score, positions, true
+}
+
+// foldRune lowers a rune, with a fast path for ASCII
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}
+	return unicode.ToLower(r)
+}
+
+// isWordStart reports whether r starts a word, after a separator such as
+// "-", "." or "_" or as the upper case letter of a camelCase name
+func isWordStart(prev, r rune) bool {
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(r)
+}
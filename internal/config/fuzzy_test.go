@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func matchNames(matches []HostMatch) []string {
+	var names []string
+	for _, match := range matches {
+		names = append(names, match.Host.Name)
+	}
+	return names
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		text      string
+		pattern   string
+		match     bool
+		positions []int
+	}{
+		{"web-server", "wsv", true, []int{0, 4, 7}},
+		{"web-server", "WEB", true, []int{0, 1, 2}},
+		{"web-server", "svw", false, nil},
+		{"prod-db", "pdb", true, []int{0, 3, 6}},
+		// The match is tightened back from where the pattern first ends
+		{"axxab", "ab", true, []int{3, 4}},
+		{"Büro", "bü", true, []int{0, 1}},
+	}
+
+	for _, tt := range tests {
+		_, positions, ok := FuzzyMatch(tt.text, tt.pattern, nil)
+		if ok != tt.match {
+			t.Errorf("FuzzyMatch(%q, %q) matched = %v, want %v", tt.text, tt.pattern, ok, tt.match)
+			continue
+		}
+		if !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("FuzzyMatch(%q, %q) positions = %v, want %v", tt.text, tt.pattern, positions, tt.positions)
+		}
+	}
+}
+
+func TestFuzzyMatchScoresWordStartsAndRuns(t *testing.T) {
+	consecutive, _, _ := FuzzyMatch("webserver", "web", nil)
+	scattered, _, _ := FuzzyMatch("wxexb", "web", nil)
+	if consecutive <= scattered {
+		t.Errorf("Expected a consecutive match to score more: %d <= %d", consecutive, scattered)
+	}
+
+	boundary, _, _ := FuzzyMatch("prod-db", "db", nil)
+	inside, _, _ := FuzzyMatch("proddb", "db", nil)
+	if boundary <= inside {
+		t.Errorf("Expected a match at a word start to score more: %d <= %d", boundary, inside)
+	}
+}
+
+func TestFuzzyMatchHostsRanksMatches(t *testing.T) {
+	hosts := []SSHHost{
+		{Name: "backup-store", Hostname: "10.0.0.5"},
+		{Name: "db", Hostname: "db.internal", User: "postgres"},
+		{Name: "web", Hostname: "web.example.com", User: "deploy", Tags: []string{"prod"}},
+		{Name: "dev-box", Hostname: "dev.local", User: "bob"},
+	}
+
+	if names := matchNames(FuzzyMatchHosts(hosts, "db")); !reflect.DeepEqual(names, []string{"db", "dev-box"}) {
+		t.Errorf("Expected the exact name first, got %v", names)
+	}
+
+	// Every word must match, in any of the fields
+	if names := matchNames(FuzzyMatchHosts(hosts, "web prod")); !reflect.DeepEqual(names, []string{"web"}) {
+		t.Errorf("Expected name and tag words to both match web, got %v", names)
+	}
+	if names := matchNames(FuzzyMatchHosts(hosts, "pgs")); !reflect.DeepEqual(names, []string{"db"}) {
+		t.Errorf("Expected the user to be searched, got %v", names)
+	}
+	if matches := FuzzyMatchHosts(hosts, "xyz"); len(matches) != 0 {
+		t.Errorf("Expected no match, got %v", matchNames(matches))
+	}
+	if matches := FuzzyMatchHosts(hosts, "  "); len(matches) != len(hosts) {
+		t.Errorf("Expected an empty query to keep every host, got %v", matchNames(matches))
+	}
+}
+
+func TestFuzzyMatchHostsNamePositions(t *testing.T) {
+	hosts := []SSHHost{
+		{Name: "web-server", Hostname: "web.example.com"},
+		{Name: "gateway", Hostname: "10.0.0.1", User: "admin"},
+	}
+
+	matches := FuzzyMatchHosts(hosts, "ws adm")
+	if len(matches) != 0 {
+		t.Fatalf("Expected no host to match both words, got %v", matchNames(matches))
+	}
+
+	matches = FuzzyMatchHosts(hosts, "ws srv")
+	if len(matches) != 1 {
+		t.Fatalf("Expected web-server to match, got %v", matchNames(matches))
+	}
+	if want := []int{0, 4, 4, 6, 7}; !reflect.DeepEqual(matches[0].NamePositions, want) {
+		t.Errorf("Expected name positions %v, got %v", want, matches[0].NamePositions)
+	}
+
+	// A host matched on its user only has nothing to highlight in its name
+	matches = FuzzyMatchHosts(hosts, "admin")
+	if len(matches) != 1 || len(matches[0].NamePositions) != 0 {
+		t.Errorf("Expected gateway with no name positions, got %+v", matches)
+	}
+}
+
+func TestFuzzyMatchHostsAllocations(t *testing.T) {
+	hosts := make([]SSHHost, 1000)
+	for i := range hosts {
+		hosts[i] = SSHHost{
+			Name:     fmt.Sprintf("server-%04d", i),
+			Hostname: fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+			User:     "deploy",
+			Tags:     []string{"prod", "eu-west"},
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		FuzzyMatchHosts(hosts, "srv 01")
+	})
+	if allocs > 40 {
+		t.Errorf("Expected allocations not to grow with the hosts, got %.0f for %d hosts", allocs, len(hosts))
+	}
+}
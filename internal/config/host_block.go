@@ -0,0 +1,170 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// hostBlock locates a Host block in the lines of a config file. lines[start:end]
+// is the block, from its Tags comments to its last directive. The comments
+// and blank lines before the next block are left out: they describe what
+// follows, so edits of this block keep them.
+type hostBlock struct {
+	start    int // First Tags comment, or the Host line
+	hostLine int
+	end      int
+}
+
+// hostLineNames returns the names of a Host line, up to its trailing comment
+func hostLineNames(line string) ([]string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "Host") {
+		return nil, false
+	}
+	var names []string
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "#") {
+			break
+		}
+		names = append(names, field)
+	}
+	return names, true
+}
+
+// findHostBlock returns the first block whose Host line names hostName
+func findHostBlock(lines []string, hostName string) (hostBlock, bool) {
+	for i, line := range lines {
+		names, ok := hostLineNames(line)
+		if !ok || !slices.Contains(names, hostName) {
+			continue
+		}
+
+		block := hostBlock{start: i, hostLine: i, end: len(lines)}
+		for block.start > 0 && isTagsComment(strings.TrimSpace(lines[block.start-1])) {
+			block.start--
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if isBlockStart(lines[j]) || isIncludeLine(lines[j]) {
+				block.end = j
+				break
+			}
+		}
+		for block.end > i+1 && isBlankOrComment(lines[block.end-1]) {
+			block.end--
+		}
+		return block, true
+	}
+	return hostBlock{}, false
+}
+
+// isIncludeLine reports whether a config line is an Include directive, which
+// sshm treats as global wherever it is
+func isIncludeLine(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 0 && strings.EqualFold(fields[0], "Include")
+}
+
+// isBlankOrComment reports whether a config line holds no directive
+func isBlankOrComment(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// editHostBlock returns the block of a host edited in place to hold host:
+// its Tags comment and Host line are rewritten, each directive it still has
+// keeps its line, with the new value if it changed, and those it no longer
+// has are removed. New directives go after the last one. Comments and blank
+// lines inside the block are left where they are.
+func editHostBlock(lines []string, block hostBlock, oldName string, host SSHHost) []string {
+	stanza := hostStanzaLines(host)
+	var edited []string
+	if len(host.Tags) > 0 {
+		edited = append(edited, stanza[0])
+		stanza = stanza[1:]
+	}
+	hostLine, _ := renameHostLine(lines[block.hostLine], oldName, host.Name)
+	edited = append(edited, hostLine)
+
+	directives := stanza[1:]
+	used := make([]bool, len(directives))
+	lastDirective := len(edited)
+	for _, line := range lines[block.hostLine+1 : block.end] {
+		if isBlankOrComment(line) {
+			edited = append(edited, line)
+			continue
+		}
+		i := matchDirective(line, directives, used)
+		if i < 0 {
+			continue // No longer set
+		}
+		used[i] = true
+		if !sameDirective(line, directives[i]) {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			line = indent + strings.TrimSpace(directives[i])
+		}
+		edited = append(edited, line)
+		lastDirective = len(edited)
+	}
+
+	var added []string
+	for i, directive := range directives {
+		if !used[i] {
+			added = append(added, directive)
+		}
+	}
+	return slices.Insert(edited, lastDirective, added...)
+}
+
+// matchDirective returns the first directive not used yet with the keyword of
+// line, -1 if there is none
+func matchDirective(line string, directives []string, used []bool) int {
+	keyword := strings.Fields(line)[0]
+	for i, directive := range directives {
+		if !used[i] && strings.EqualFold(strings.Fields(directive)[0], keyword) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameDirective reports whether two directive lines differ only in spacing
+// and the case of their keyword
+func sameDirective(a, b string) bool {
+	fa, fb := strings.Fields(a), strings.Fields(b)
+	return strings.EqualFold(fa[0], fb[0]) && slices.Equal(fa[1:], fb[1:])
+}
+
+// hostStanzaLines returns the lines sshm writes for a host: its Tags comment,
+// Host line and directives
+func hostStanzaLines(host SSHHost) []string {
+	var lines []string
+	if len(host.Tags) > 0 {
+		lines = append(lines, "# Tags: "+strings.Join(host.Tags, ", "))
+	}
+	lines = append(lines, "Host "+host.Name)
+	lines = append(lines, "    HostName "+host.Hostname)
+	if host.User != "" {
+		lines = append(lines, "    User "+host.User)
+	}
+	if host.Port != "" && host.Port != "22" {
+		lines = append(lines, "    Port "+host.Port)
+	}
+	if host.Identity != "" {
+		lines = append(lines, "    IdentityFile "+formatSSHConfigValue(host.Identity))
+	}
+	if host.ProxyJump != "" {
+		lines = append(lines, "    ProxyJump "+host.ProxyJump)
+	}
+	if host.RemoteCommand != "" {
+		lines = append(lines, "    RemoteCommand "+host.RemoteCommand)
+	}
+	if host.RequestTTY != "" {
+		lines = append(lines, "    RequestTTY "+host.RequestTTY)
+	}
+	for _, option := range strings.Split(host.Options, "\n") {
+		if option = strings.TrimSpace(option); option != "" {
+			lines = append(lines, "    "+option)
+		}
+	}
+	return lines
+}
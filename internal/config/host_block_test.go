@@ -0,0 +1,250 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// commentedConfig has global options, comments around and inside blocks,
+// blank lines within a block and a Match block
+const commentedConfig = `# Personal SSH config
+
+Include ~/.ssh/conf.d/*
+
+Host *
+    ServerAliveInterval 60
+    # Keep connections alive on flaky networks
+    ServerAliveCountMax 3
+
+# Production web server
+# Tags: prod, web
+Host web
+    HostName 10.0.0.1
+    User deploy
+
+    # Moved to the new key in 2024
+    IdentityFile ~/.ssh/web_ed25519
+
+
+# Database, through the bastion
+Host db
+    HostName db.internal
+    ProxyJump bastion
+Host api1 api2   # behind the load balancer
+    User api
+
+Match host *.corp
+    User corp
+`
+
+// webBlock is the block of web in commentedConfig, Tags comment included
+const webBlock = `# Tags: prod, web
+Host web
+    HostName 10.0.0.1
+    User deploy
+
+    # Moved to the new key in 2024
+    IdentityFile ~/.ssh/web_ed25519
+`
+
+// writeCommentedConfig writes commentedConfig to a temporary file, with the
+// backups kept in the temporary home
+func writeCommentedConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, []byte(commentedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return configPath
+}
+
+// assertConfig fails unless the file at configPath holds want, byte for byte
+func assertConfig(t *testing.T, configPath, want string) {
+	t.Helper()
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("Unexpected config.\nGot:\n%s\nWant:\n%s", data, want)
+	}
+}
+
+func TestFindHostBlock(t *testing.T) {
+	lines := configLines([]byte(commentedConfig))
+
+	tests := []struct {
+		host  string
+		first string // Line the block starts with
+		last  string // Last line of the block
+	}{
+		{host: "web", first: "# Tags: prod, web", last: "    IdentityFile ~/.ssh/web_ed25519"},
+		{host: "db", first: "Host db", last: "    ProxyJump bastion"},
+		{host: "api2", first: "Host api1 api2   # behind the load balancer", last: "    User api"},
+	}
+	for _, tt := range tests {
+		block, ok := findHostBlock(lines, tt.host)
+		if !ok {
+			t.Errorf("Expected to find %s", tt.host)
+			continue
+		}
+		if lines[block.start] != tt.first || lines[block.end-1] != tt.last {
+			t.Errorf("Block of %s: got %q to %q, want %q to %q", tt.host, lines[block.start], lines[block.end-1], tt.first, tt.last)
+		}
+	}
+
+	// Names after a comment, Match criteria and unknown hosts have no block
+	for _, host := range []string{"balancer", "corp", "missing"} {
+		if _, ok := findHostBlock(lines, host); ok {
+			t.Errorf("Expected no block for %q", host)
+		}
+	}
+}
+
+func TestUpdateSSHHostKeepsUnrelatedSections(t *testing.T) {
+	configPath := writeCommentedConfig(t)
+
+	host := SSHHost{Name: "web", Hostname: "10.0.0.2", User: "deploy", Identity: "~/.ssh/web_ed25519", Tags: []string{"prod"}}
+	if err := UpdateSSHHostInFile("web", host, configPath); err != nil {
+		t.Fatal(err)
+	}
+	// The block is edited in place: its comment and blank line stay
+	assertConfig(t, configPath, strings.Replace(commentedConfig, webBlock, `# Tags: prod
+Host web
+    HostName 10.0.0.2
+    User deploy
+
+    # Moved to the new key in 2024
+    IdentityFile ~/.ssh/web_ed25519
+`, 1))
+}
+
+func TestUpdateSSHHostAddsAndRemovesDirectives(t *testing.T) {
+	configPath := writeCommentedConfig(t)
+
+	host := SSHHost{Name: "web", Hostname: "10.0.0.1", Port: "2222", Identity: "~/.ssh/web_ed25519", Tags: []string{"prod", "web"}}
+	if err := UpdateSSHHostInFile("web", host, configPath); err != nil {
+		t.Fatal(err)
+	}
+	// User is gone, Port follows the last directive
+	assertConfig(t, configPath, strings.Replace(commentedConfig, webBlock, `# Tags: prod, web
+Host web
+    HostName 10.0.0.1
+
+    # Moved to the new key in 2024
+    IdentityFile ~/.ssh/web_ed25519
+    Port 2222
+`, 1))
+}
+
+func TestUpdateSSHHostRoundTrip(t *testing.T) {
+	configPath := writeCommentedConfig(t)
+
+	// db is written the way sshm writes hosts: saving it unchanged changes nothing
+	db, err := GetSSHHostFromFile("db", configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateSSHHostInFile("db", *db, configPath); err != nil {
+		t.Fatal(err)
+	}
+	assertConfig(t, configPath, commentedConfig)
+}
+
+func TestUpdateSSHHostKeepsLineEndings(t *testing.T) {
+	configPath := writeCommentedConfig(t)
+	windows := func(content string) string {
+		return utf8BOM + strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	if err := os.WriteFile(configPath, []byte(windows(commentedConfig)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving a host unchanged leaves the file byte for byte as it was
+	db, err := GetSSHHostFromFile("db", configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateSSHHostInFile("db", *db, configPath); err != nil {
+		t.Fatal(err)
+	}
+	assertConfig(t, configPath, windows(commentedConfig))
+
+	db.Hostname = "db2.internal"
+	if err := UpdateSSHHostInFile("db", *db, configPath); err != nil {
+		t.Fatal(err)
+	}
+	assertConfig(t, configPath, windows(strings.Replace(commentedConfig, "db.internal", "db2.internal", 1)))
+}
+
+func TestUpdateSSHHostOfMultiHostLine(t *testing.T) {
+	configPath := writeCommentedConfig(t)
+
+	host := SSHHost{Name: "api2", Hostname: "10.0.1.2", User: "api"}
+	if err := UpdateSSHHostInFile("api2", host, configPath); err != nil {
+		t.Fatal(err)
+	}
+	assertConfig(t, configPath, strings.Replace(commentedConfig, `Host api1 api2   # behind the load balancer
+    User api
+`, `Host api1   # behind the load balancer
+    User api
+
+Host api2
+    HostName 10.0.1.2
+    User api
+`, 1))
+}
+
+func TestDeleteSSHHostKeepsUnrelatedSections(t *testing.T) {
+	configPath := writeCommentedConfig(t)
+
+	if err := DeleteSSHHostFromFile("web", configPath); err != nil {
+		t.Fatal(err)
+	}
+	// The comment above the block and the blank lines after it stay
+	want := strings.Replace(commentedConfig, webBlock, "", 1)
+	assertConfig(t, configPath, want)
+
+	if err := DeleteSSHHostFromFile("api1", configPath); err != nil {
+		t.Fatal(err)
+	}
+	want = strings.Replace(want, "Host api1 api2   #", "Host api2   #", 1)
+	assertConfig(t, configPath, want)
+
+	if err := DeleteSSHHostFromFile("missing", configPath); err == nil {
+		t.Error("Expected an error for an unknown host")
+	}
+}
+
+func TestDeleteSSHHostDropsItsSeparator(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	configPath := filepath.Join(dir, "config")
+	content := "Host a\n    HostName a.example.com\n\nHost b\n    HostName b.example.com\n\n# c\nHost c\n    HostName c.example.com\n"
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteSSHHostFromFile("b", configPath); err != nil {
+		t.Fatal(err)
+	}
+	assertConfig(t, configPath, "Host a\n    HostName a.example.com\n\n# c\nHost c\n    HostName c.example.com\n")
+
+	if err := DeleteSSHHostFromFile("c", configPath); err != nil {
+		t.Fatal(err)
+	}
+	assertConfig(t, configPath, "Host a\n    HostName a.example.com\n\n# c\n")
+
+	if err := DeleteSSHHostFromFile("a", configPath); err != nil {
+		t.Fatal(err)
+	}
+	assertConfig(t, configPath, "# c\n")
+}
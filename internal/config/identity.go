@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ListIdentityFiles returns candidate private keys from the ~/.ssh directory
+func ListIdentityFiles() ([]string, error) {
+	sshDir, err := GetSSHDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return listIdentityFilesInDir(sshDir)
+}
+
+// listIdentityFilesInDir returns the keys found in dir.
+// A key is any file with a matching .pub file, or any id_* file. If only the .pub
+// half exists (private key held in an agent or token), the .pub path is returned,
+// which ssh also accepts for -i.
+func listIdentityFilesInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names[entry.Name()] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			keys = append(keys, filepath.Join(dir, name))
+		}
+	}
+
+	for name := range names {
+		// Certificates are picked up by ssh alongside their key
+		if strings.HasSuffix(name, "-cert.pub") {
+			continue
+		}
+
+		if strings.HasSuffix(name, ".pub") {
+			private := strings.TrimSuffix(name, ".pub")
+			if names[private] {
+				add(private)
+			} else {
+				add(name)
+			}
+			continue
+		}
+
+		// Private keys without a .pub counterpart, using the default naming scheme
+		if strings.HasPrefix(name, "id_") {
+			add(name)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// BuildIdentityArgs returns the ssh arguments that force a specific identity file
+func BuildIdentityArgs(identity string) []string {
+	if identity == "" {
+		return nil
+	}
+	return []string{"-i", identity, "-o", "IdentitiesOnly=yes"}
+}
+
+// BuildConnectArgs returns the ssh arguments to connect to a host, optionally
+// with a config file and a forced identity
+func BuildConnectArgs(hostName, configFile, identity string) []string {
+	var args []string
+	if configFile != "" {
+		args = append(args, "-F", configFile)
+	}
+	args = append(args, BuildIdentityArgs(identity)...)
+	return append(args, hostName)
+}
+
+// SetHostIdentity persists identity as the IdentityFile of a host
+func SetHostIdentity(hostName, identity, configFile string) error {
+	var host *SSHHost
+	var err error
+
+	if configFile != "" {
+		host, err = GetSSHHostFromFile(hostName, configFile)
+	} else {
+		host, err = GetSSHHost(hostName)
+	}
+	if err != nil {
+		return err
+	}
+
+	targetFile := host.SourceFile
+	if targetFile == "" {
+		if configFile != "" {
+			targetFile = configFile
+		} else if targetFile, err = GetDefaultSSHConfigPath(); err != nil {
+			return err
+		}
+	}
+
+	host.Identity = identity
+	if err := UpdateSSHHostInFile(hostName, *host, targetFile); err != nil {
+		return fmt.Errorf("failed to save identity: %w", err)
+	}
+	return nil
+}
+
+// defaultIdentityNames are the keys ssh falls back to when no IdentityFile is
+// configured. `ssh -G` lists them whether they exist or not.
+var defaultIdentityNames = map[string]bool{
+	"id_rsa":        true,
+	"id_ecdsa":      true,
+	"id_ecdsa_sk":   true,
+	"id_ed25519":    true,
+	"id_ed25519_sk": true,
+	"id_xmss":       true,
+	"id_dsa":        true,
+}
+
+// MissingIdentityFiles resolves the IdentityFile settings of a host with
+// `ssh -G` and returns those that don't exist on disk
+func MissingIdentityFiles(host SSHHost, configFile string) ([]string, error) {
+	options, err := GetResolvedConfig(host.Name, configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	home, _ := os.UserHomeDir()
+	return missingIdentityFiles(options, host.Identity != "", home, func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}), nil
+}
+
+// missingIdentityFiles returns the resolved identity files for which exists is
+// false. The default keys are only candidates, so they are skipped unless the
+// host sets an IdentityFile itself.
+func missingIdentityFiles(options []ResolvedOption, configured bool, home string, exists func(string) bool) []string {
+	var missing []string
+	for _, opt := range options {
+		if opt.Key != "identityfile" || opt.Value == "" || strings.EqualFold(opt.Value, "none") {
+			continue
+		}
+
+		path := opt.Value
+		if home != "" && (path == "~" || strings.HasPrefix(path, "~/")) {
+			path = filepath.Join(home, path[1:])
+		}
+
+		if !configured && defaultIdentityNames[filepath.Base(path)] {
+			continue
+		}
+		if !exists(path) {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
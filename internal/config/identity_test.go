@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildIdentityArgs(t *testing.T) {
+	if args := BuildIdentityArgs(""); args != nil {
+		t.Errorf("Expected no args for empty identity, got %v", args)
+	}
+
+	expected := []string{"-i", "/home/user/.ssh/id_work", "-o", "IdentitiesOnly=yes"}
+	if args := BuildIdentityArgs("/home/user/.ssh/id_work"); !reflect.DeepEqual(args, expected) {
+		t.Errorf("BuildIdentityArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestBuildConnectArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		hostName   string
+		configFile string
+		identity   string
+		expected   []string
+	}{
+		{
+			name:     "Host only",
+			hostName: "myserver",
+			expected: []string{"myserver"},
+		},
+		{
+			name:       "With config file",
+			hostName:   "myserver",
+			configFile: "/tmp/ssh_config",
+			expected:   []string{"-F", "/tmp/ssh_config", "myserver"},
+		},
+		{
+			name:     "With identity",
+			hostName: "myserver",
+			identity: "~/.ssh/id_ed25519",
+			expected: []string{"-i", "~/.ssh/id_ed25519", "-o", "IdentitiesOnly=yes", "myserver"},
+		},
+		{
+			name:       "With config file and identity",
+			hostName:   "myserver",
+			configFile: "/tmp/ssh_config",
+			identity:   "~/.ssh/id_rsa",
+			expected:   []string{"-F", "/tmp/ssh_config", "-i", "~/.ssh/id_rsa", "-o", "IdentitiesOnly=yes", "myserver"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := BuildConnectArgs(tt.hostName, tt.configFile, tt.identity)
+			if !reflect.DeepEqual(args, tt.expected) {
+				t.Errorf("BuildConnectArgs() = %v, want %v", args, tt.expected)
+			}
+		})
+	}
+}
+
+func TestListIdentityFilesInDir(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := []string{
+		// Key pairs
+		"id_ed25519", "id_ed25519.pub",
+		"work_key", "work_key.pub",
+		// Private key without .pub
+		"id_rsa",
+		// Public half only (private key in an agent)
+		"agent_only.pub",
+		// Not keys
+		"config", "known_hosts", "authorized_keys", "id_ed25519-cert.pub",
+	}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("x"), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tempDir, "id_dir"), 0700); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	keys, err := listIdentityFilesInDir(tempDir)
+	if err != nil {
+		t.Fatalf("listIdentityFilesInDir() error = %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(tempDir, "agent_only.pub"),
+		filepath.Join(tempDir, "id_ed25519"),
+		filepath.Join(tempDir, "id_rsa"),
+		filepath.Join(tempDir, "work_key"),
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("listIdentityFilesInDir() = %v, want %v", keys, expected)
+	}
+}
+
+func TestMissingIdentityFiles(t *testing.T) {
+	home := "/home/user"
+	present := map[string]bool{
+		"/home/user/.ssh/id_work":    true,
+		"/home/user/.ssh/id_ed25519": true,
+	}
+	exists := func(path string) bool { return present[path] }
+
+	tests := []struct {
+		name       string
+		output     string
+		configured bool
+		expected   []string
+	}{
+		{
+			name:       "configured key exists",
+			output:     "hostname web\nidentityfile ~/.ssh/id_work\n",
+			configured: true,
+			expected:   nil,
+		},
+		{
+			name:       "configured key missing",
+			output:     "identityfile ~/.ssh/id_gone\nidentityfile /opt/keys/deploy.pem\nidentityfile ~/.ssh/id_work\n",
+			configured: true,
+			expected:   []string{"/home/user/.ssh/id_gone", "/opt/keys/deploy.pem"},
+		},
+		{
+			name:       "default candidates are not reported",
+			output:     "identityfile ~/.ssh/id_rsa\nidentityfile ~/.ssh/id_ecdsa\nidentityfile ~/.ssh/id_ed25519\n",
+			configured: false,
+			expected:   nil,
+		},
+		{
+			name:       "configured default name missing",
+			output:     "identityfile ~/.ssh/id_rsa\n",
+			configured: true,
+			expected:   []string{"/home/user/.ssh/id_rsa"},
+		},
+		{
+			name:       "inherited key missing",
+			output:     "identityfile ~/.ssh/id_shared\nidentityfile ~/.ssh/id_rsa\n",
+			configured: false,
+			expected:   []string{"/home/user/.ssh/id_shared"},
+		},
+		{
+			name:       "none",
+			output:     "identityfile none\n",
+			configured: true,
+			expected:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		got := missingIdentityFiles(ParseResolvedConfig(tt.output), tt.configured, home, exists)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: missingIdentityFiles() = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
@@ -3,8 +3,12 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
 // KeyBindings represents configurable key bindings for the application
@@ -14,11 +18,342 @@ type KeyBindings struct {
 
 	// DisableEscQuit - if true, ESC key won't quit the application (useful for vim users)
 	DisableEscQuit bool `json:"disable_esc_quit"`
+
+	// Actions overrides the keys of host list actions, by action name (e.g.
+	// {"edit": "E"}). Actions not listed keep their default key.
+	Actions map[string]string `json:"actions,omitempty"`
+}
+
+// Host list actions that can be bound to another key in KeyBindings.Actions
+const (
+	ActionMoveUp         = "move_up"
+	ActionMoveDown       = "move_down"
+	ActionSearch         = "search"
+	ActionAdd            = "add"
+	ActionEdit           = "edit"
+	ActionMove           = "move"
+	ActionCopy           = "copy"
+	ActionDelete         = "delete"
+	ActionInfo           = "info"
+	ActionConnectWithKey = "connect_with_key"
+	ActionMark           = "mark"
+	ActionExport         = "export"
+	ActionTmux           = "tmux"
+	ActionConnectForward = "connect_forwards"
+	ActionWeb            = "web"
+	ActionWebURL         = "web_url"
+	ActionCopyID         = "copy_id"
+	ActionPing           = "ping"
+	ActionPortForward    = "port_forward"
+	ActionTunnels        = "tunnels"
+	ActionSOCKSProxy     = "socks_proxy"
+	ActionBanner         = "banner"
+	ActionTransfer       = "transfer"
+	ActionRetryTransfer  = "retry_transfer"
+	ActionHelp           = "help"
+	ActionSort           = "sort"
+	ActionSortName       = "sort_name"
+	ActionSortRecent     = "sort_recent"
+	ActionSortSwap       = "sort_swap"
+	ActionSavedViews     = "saved_views"
+	ActionSwitchConfig   = "switch_config"
+	ActionCommand        = "command"
+	ActionFavorite       = "favorite"
+	ActionFavoritesOnly  = "favorites_only"
+	ActionTagFilter      = "tag_filter"
+	ActionSourceFilter   = "source_filter"
+	ActionRename         = "rename"
+)
+
+// DefaultActionKeys are the keys of the host list actions unless overridden
+var DefaultActionKeys = map[string]string{
+	ActionMoveUp:         "k",
+	ActionMoveDown:       "j",
+	ActionSearch:         "/",
+	ActionAdd:            "a",
+	ActionEdit:           "e",
+	ActionMove:           "m",
+	ActionCopy:           "c",
+	ActionDelete:         "d",
+	ActionInfo:           "i",
+	ActionConnectWithKey: "I",
+	ActionMark:           " ",
+	ActionExport:         "X",
+	ActionTmux:           "T",
+	ActionConnectForward: "L",
+	ActionWeb:            "w",
+	ActionWebURL:         "W",
+	ActionCopyID:         "K",
+	ActionPing:           "p",
+	ActionPortForward:    "f",
+	ActionTunnels:        "F",
+	ActionSOCKSProxy:     "P",
+	ActionBanner:         "B",
+	ActionTransfer:       "t",
+	ActionRetryTransfer:  "R",
+	ActionHelp:           "h",
+	ActionSort:           "s",
+	ActionSortName:       "n",
+	ActionSortRecent:     "r",
+	ActionSortSwap:       "S",
+	ActionSavedViews:     "v",
+	ActionSwitchConfig:   "C",
+	ActionCommand:        ":",
+	ActionFavorite:       "*",
+	ActionFavoritesOnly:  "O",
+	ActionTagFilter:      "#",
+	ActionSourceFilter:   "g",
+	ActionRename:         "N",
+}
+
+// reservedKeys always keep their meaning in the host list
+var reservedKeys = map[string]bool{"enter": true, "tab": true, "esc": true, "ctrl+c": true, "ctrl+f": true, "up": true, "down": true}
+
+// normalizeKey accepts "space" for the space bar, which Bubble Tea names " "
+func normalizeKey(key string) string {
+	if key == "space" {
+		return " "
+	}
+	return key
+}
+
+// KeyFor returns the key bound to a host list action
+func (kb *KeyBindings) KeyFor(action string) string {
+	if key, ok := kb.Actions[action]; ok && key != "" {
+		return normalizeKey(key)
+	}
+	return DefaultActionKeys[action]
+}
+
+// Validate reports unknown actions and keys bound to several actions or
+// reserved for quitting, navigation or search
+func (kb *KeyBindings) Validate() error {
+	var problems []string
+	for action, key := range kb.Actions {
+		if _, ok := DefaultActionKeys[action]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown action %q", action))
+		} else if strings.TrimSpace(key) == "" && key != " " {
+			problems = append(problems, fmt.Sprintf("no key given for %q", action))
+		}
+	}
+
+	// Keys in action order, so the errors are the same on every run
+	actions := make([]string, 0, len(DefaultActionKeys))
+	for action := range DefaultActionKeys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	boundTo := make(map[string]string)
+	for _, action := range actions {
+		key := kb.KeyFor(action)
+		switch {
+		case boundTo[key] != "":
+			problems = append(problems, fmt.Sprintf("%q is bound to both %s and %s", key, boundTo[key], action))
+		case reservedKeys[key]:
+			problems = append(problems, fmt.Sprintf("%q of %s is reserved", key, action))
+		case kb.isQuitKey(key):
+			problems = append(problems, fmt.Sprintf("%q of %s is also a quit key", key, action))
+		default:
+			boundTo[key] = action
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid key bindings: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// isQuitKey reports whether key is one of the quit keys
+func (kb *KeyBindings) isQuitKey(key string) bool {
+	for _, quitKey := range kb.QuitKeys {
+		if quitKey == key {
+			return true
+		}
+	}
+	return false
 }
 
 // AppConfig represents the main application configuration
 type AppConfig struct {
 	KeyBindings KeyBindings `json:"key_bindings"`
+
+	// ScpExtraArgs are passed to every scp invocation (e.g. ["-O"] for legacy servers)
+	ScpExtraArgs []string `json:"scp_extra_args,omitempty"`
+
+	// PreferTUIPicker uses the in-terminal browser instead of native OS file dialogs
+	PreferTUIPicker bool `json:"prefer_tui_picker,omitempty"`
+
+	// History bounds the size of the active connection history file
+	History HistoryConfig `json:"history"`
+
+	// RecordTransferHistory records transfers and the remote directories
+	// they and the browser use (nil means true)
+	RecordTransferHistory *bool `json:"record_transfer_history,omitempty"`
+
+	// RecordConnectionHistory records when and how often hosts are
+	// connected to (nil means true)
+	RecordConnectionHistory *bool `json:"record_connection_history,omitempty"`
+
+	// CommandTimeoutSeconds limits how long a remote shell command run by the
+	// file browser may take (0 uses the built-in default)
+	CommandTimeoutSeconds int `json:"command_timeout_seconds,omitempty"`
+
+	// NotificationDuration is how many seconds notifications stay on screen
+	NotificationDuration int `json:"notification_duration"`
+
+	// RemoteBrowserSort picks the default remote browser order: "name",
+	// "modified" (newest first) or empty to sort log directories by
+	// modification time and everything else by name
+	RemoteBrowserSort string `json:"remote_browser_sort,omitempty"`
+
+	// RemoteUploadDir is the remote directory the browser opens in to pick
+	// the destination of an upload, unless the host sets its own
+	RemoteUploadDir string `json:"remote_upload_dir,omitempty"`
+
+	// NoAltScreen runs the TUI in the normal terminal buffer, keeping scrollback
+	NoAltScreen bool `json:"no_alt_screen,omitempty"`
+
+	// HideBanner collapses the ASCII title of the host list, toggled with B
+	HideBanner bool `json:"hide_banner,omitempty"`
+
+	// FavoritesOnly starts the host list showing only the favorite hosts,
+	// O reveals the others
+	FavoritesOnly bool `json:"favorites_only,omitempty"`
+
+	// SearchEnterAction is what Enter does while searching: "focus-table"
+	// (or empty) returns to the table, "connect-top" connects to the first match
+	SearchEnterAction string `json:"search_enter_action,omitempty"`
+
+	// ShowAuthMethod shows which key the remote browser logged in with, to
+	// debug authentication issues
+	ShowAuthMethod bool `json:"show_auth_method,omitempty"`
+
+	// DownloadOnExists is what a download does when its local destination
+	// exists: "ask" (or empty), "overwrite", "skip" or "rename"
+	DownloadOnExists string `json:"download_on_exists,omitempty"`
+
+	// PingConcurrency is how many hosts "ping all" checks at once (0 uses
+	// the built-in default)
+	PingConcurrency int `json:"ping_concurrency,omitempty"`
+
+	// TransferConcurrency splits recursive scp transfers into this many scp
+	// jobs run at once (0 or 1 runs a single scp)
+	TransferConcurrency int `json:"transfer_concurrency,omitempty"`
+
+	// PingCacheTTLSeconds is how long a ping result is reused before the
+	// host is checked again (0 uses the default, negative always re-pings)
+	PingCacheTTLSeconds int `json:"ping_cache_ttl_seconds,omitempty"`
+
+	// OpenMaxSizeMB is the largest remote file, in MiB, downloaded to a
+	// temporary directory to be opened (0 uses the built-in default)
+	OpenMaxSizeMB int `json:"open_max_size_mb,omitempty"`
+
+	// TransferConfirmSizeMB is the size, in MiB, above which the transfer
+	// forms ask for confirmation (0 uses the default, negative never asks)
+	TransferConfirmSizeMB int `json:"transfer_confirm_size_mb,omitempty"`
+}
+
+// Remote browser sort settings for AppConfig.RemoteBrowserSort
+const (
+	RemoteSortAuto     = ""
+	RemoteSortName     = "name"
+	RemoteSortModified = "modified"
+)
+
+// Search Enter settings for AppConfig.SearchEnterAction
+const (
+	SearchEnterFocusTable = "focus-table"
+	SearchEnterConnectTop = "connect-top"
+)
+
+// DefaultNotificationDuration is how many seconds notifications are shown by default
+const DefaultNotificationDuration = 3
+
+// NotificationTimeout returns how long notifications stay on screen
+func (c *AppConfig) NotificationTimeout() time.Duration {
+	if c.NotificationDuration <= 0 {
+		return DefaultNotificationDuration * time.Second
+	}
+	return time.Duration(c.NotificationDuration) * time.Second
+}
+
+// DefaultPingCacheTTLSeconds is how many seconds ping results are reused by default
+const DefaultPingCacheTTLSeconds = 30
+
+// PingCacheTTL returns how long ping results are reused, 0 when they are not
+func (c *AppConfig) PingCacheTTL() time.Duration {
+	switch {
+	case c.PingCacheTTLSeconds < 0:
+		return 0
+	case c.PingCacheTTLSeconds == 0:
+		return DefaultPingCacheTTLSeconds * time.Second
+	}
+	return time.Duration(c.PingCacheTTLSeconds) * time.Second
+}
+
+// DefaultTransferConfirmSizeMB is the transfer size, in MiB, confirmed by default
+const DefaultTransferConfirmSizeMB = 1024
+
+// TransferConfirmSize returns the size in bytes above which a transfer is
+// confirmed first, 0 when transfers are never confirmed
+func (c *AppConfig) TransferConfirmSize() int64 {
+	switch {
+	case c.TransferConfirmSizeMB < 0:
+		return 0
+	case c.TransferConfirmSizeMB == 0:
+		return DefaultTransferConfirmSizeMB << 20
+	}
+	return int64(c.TransferConfirmSizeMB) << 20
+}
+
+// HistoryConfig controls which old connection history sshm history compact
+// moves into the archive.
+// Zero means "use the default", a negative value disables that limit.
+type HistoryConfig struct {
+	MaxEntries int `json:"max_entries"`  // Hosts kept in the active history file
+	MaxAgeDays int `json:"max_age_days"` // Hosts unused for longer are archived
+}
+
+// NoHistoryEnv turns off every history recording when set to a value other
+// than "", "0" or "false", whatever the app config says
+const NoHistoryEnv = "SSHM_NO_HISTORY"
+
+// historyDisabledByEnv reports whether NoHistoryEnv turns recording off
+func historyDisabledByEnv() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(NoHistoryEnv))) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
+// TransferHistoryEnabled reports whether transfers and remote paths are
+// recorded. A nil config means the defaults.
+func (c *AppConfig) TransferHistoryEnabled() bool {
+	if historyDisabledByEnv() {
+		return false
+	}
+	return c == nil || c.RecordTransferHistory == nil || *c.RecordTransferHistory
+}
+
+// ConnectionHistoryEnabled reports whether connections are recorded. A nil
+// config means the defaults.
+func (c *AppConfig) ConnectionHistoryEnabled() bool {
+	if historyDisabledByEnv() {
+		return false
+	}
+	return c == nil || c.RecordConnectionHistory == nil || *c.RecordConnectionHistory
+}
+
+// GetDefaultHistoryConfig returns the default history rotation limits
+func GetDefaultHistoryConfig() HistoryConfig {
+	return HistoryConfig{
+		MaxEntries: 500,
+		MaxAgeDays: 365,
+	}
 }
 
 // GetDefaultKeyBindings returns the default key bindings configuration
@@ -32,7 +367,9 @@ func GetDefaultKeyBindings() KeyBindings {
 // GetDefaultAppConfig returns the default application configuration
 func GetDefaultAppConfig() AppConfig {
 	return AppConfig{
-		KeyBindings: GetDefaultKeyBindings(),
+		KeyBindings:          GetDefaultKeyBindings(),
+		History:              GetDefaultHistoryConfig(),
+		NotificationDuration: DefaultNotificationDuration,
 	}
 }
 
@@ -116,6 +453,18 @@ func SaveAppConfig(config *AppConfig) error {
 	return os.WriteFile(configPath, data, 0644)
 }
 
+// SetHideBanner saves whether the ASCII title is collapsed. The config is read
+// again from disk so only this setting changes, whatever the running app
+// fell back to: a config that cannot be read is left as it is.
+func SetHideBanner(hide bool) error {
+	appConfig, err := LoadAppConfig()
+	if err != nil {
+		return err
+	}
+	appConfig.HideBanner = hide
+	return SaveAppConfig(appConfig)
+}
+
 // mergeWithDefaults ensures all required fields are set with defaults if missing
 func mergeWithDefaults(config AppConfig) AppConfig {
 	defaults := GetDefaultAppConfig()
@@ -125,6 +474,18 @@ func mergeWithDefaults(config AppConfig) AppConfig {
 		config.KeyBindings.QuitKeys = defaults.KeyBindings.QuitKeys
 	}
 
+	// Unset history limits fall back to defaults
+	if config.History.MaxEntries == 0 {
+		config.History.MaxEntries = defaults.History.MaxEntries
+	}
+	if config.History.MaxAgeDays == 0 {
+		config.History.MaxAgeDays = defaults.History.MaxAgeDays
+	}
+
+	if config.NotificationDuration <= 0 {
+		config.NotificationDuration = defaults.NotificationDuration
+	}
+
 	return config
 }
 
@@ -136,11 +497,5 @@ func (kb *KeyBindings) ShouldQuitOnKey(key string) bool {
 	}
 
 	// Check if key is in the quit keys list
-	for _, quitKey := range kb.QuitKeys {
-		if quitKey == key {
-			return true
-		}
-	}
-
-	return false
+	return kb.isQuitKey(key)
 }
\ No newline at end of file
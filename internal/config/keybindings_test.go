@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultKeyBindings(t *testing.T) {
@@ -127,6 +129,99 @@ func TestMergeWithDefaults(t *testing.T) {
 	if len(mergedConfig.KeyBindings.QuitKeys) != len(expectedQuitKeys) {
 		t.Errorf("Expected %d quit keys, got %d", len(expectedQuitKeys), len(mergedConfig.KeyBindings.QuitKeys))
 	}
+
+	// Should fill in default history limits
+	if mergedConfig.History != GetDefaultHistoryConfig() {
+		t.Errorf("Expected default history limits, got %+v", mergedConfig.History)
+	}
+
+	// Should fill in the default notification duration
+	if mergedConfig.NotificationDuration != DefaultNotificationDuration {
+		t.Errorf("Expected notification duration %d, got %d", DefaultNotificationDuration, mergedConfig.NotificationDuration)
+	}
+}
+
+func TestNotificationTimeout(t *testing.T) {
+	tests := []struct {
+		seconds  int
+		expected time.Duration
+	}{
+		{0, 3 * time.Second},
+		{-1, 3 * time.Second},
+		{10, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		cfg := AppConfig{NotificationDuration: tt.seconds}
+		if got := cfg.NotificationTimeout(); got != tt.expected {
+			t.Errorf("NotificationTimeout() with %d = %v, want %v", tt.seconds, got, tt.expected)
+		}
+	}
+}
+
+func TestPingCacheTTL(t *testing.T) {
+	tests := []struct {
+		seconds  int
+		expected time.Duration
+	}{
+		{0, 30 * time.Second},
+		{-1, 0},
+		{90, 90 * time.Second},
+	}
+
+	for _, tt := range tests {
+		cfg := AppConfig{PingCacheTTLSeconds: tt.seconds}
+		if got := cfg.PingCacheTTL(); got != tt.expected {
+			t.Errorf("PingCacheTTL() with %d = %v, want %v", tt.seconds, got, tt.expected)
+		}
+	}
+}
+
+func TestTransferConfirmSize(t *testing.T) {
+	tests := []struct {
+		mb       int
+		expected int64
+	}{
+		{0, 1 << 30},
+		{-1, 0},
+		{200, 200 << 20},
+	}
+
+	for _, tt := range tests {
+		cfg := AppConfig{TransferConfirmSizeMB: tt.mb}
+		if got := cfg.TransferConfirmSize(); got != tt.expected {
+			t.Errorf("TransferConfirmSize() with %d = %d, want %d", tt.mb, got, tt.expected)
+		}
+	}
+}
+
+func TestHistoryRecordingSettings(t *testing.T) {
+	t.Setenv(NoHistoryEnv, "")
+	off := false
+
+	var unset *AppConfig
+	if !unset.TransferHistoryEnabled() || !unset.ConnectionHistoryEnabled() {
+		t.Error("Expected recording by default")
+	}
+	cfg := &AppConfig{RecordTransferHistory: &off}
+	if cfg.TransferHistoryEnabled() || !cfg.ConnectionHistoryEnabled() {
+		t.Error("Expected only transfer recording to be off")
+	}
+	cfg = &AppConfig{RecordConnectionHistory: &off}
+	if !cfg.TransferHistoryEnabled() || cfg.ConnectionHistoryEnabled() {
+		t.Error("Expected only connection recording to be off")
+	}
+
+	// The environment turns every recording off
+	for value, disabled := range map[string]bool{"1": true, "yes": true, "0": false, "false": false} {
+		t.Setenv(NoHistoryEnv, value)
+		if got := (&AppConfig{}).TransferHistoryEnabled(); got == disabled {
+			t.Errorf("%s=%s: TransferHistoryEnabled() = %v", NoHistoryEnv, value, got)
+		}
+		if got := unset.ConnectionHistoryEnabled(); got == disabled {
+			t.Errorf("%s=%s: ConnectionHistoryEnabled() = %v", NoHistoryEnv, value, got)
+		}
+	}
 }
 
 func TestSaveAndLoadAppConfigIntegration(t *testing.T) {
@@ -178,4 +273,61 @@ func TestSaveAndLoadAppConfigIntegration(t *testing.T) {
 	if len(loadedConfig.KeyBindings.QuitKeys) != 1 || loadedConfig.KeyBindings.QuitKeys[0] != "q" {
 		t.Errorf("Expected quit keys to be ['q'], got %v", loadedConfig.KeyBindings.QuitKeys)
 	}
-}
\ No newline at end of file
+}
+
+func TestKeyFor(t *testing.T) {
+	kb := GetDefaultKeyBindings()
+	if key := kb.KeyFor(ActionEdit); key != "e" {
+		t.Errorf("Expected the default edit key e, got %q", key)
+	}
+
+	kb.Actions = map[string]string{ActionEdit: "E", ActionMark: "space"}
+	if key := kb.KeyFor(ActionEdit); key != "E" {
+		t.Errorf("Expected the rebound edit key E, got %q", key)
+	}
+	if key := kb.KeyFor(ActionMark); key != " " {
+		t.Errorf("Expected space to be read as \" \", got %q", key)
+	}
+	if key := kb.KeyFor(ActionDelete); key != "d" {
+		t.Errorf("Expected actions not listed to keep their key, got %q", key)
+	}
+}
+
+func TestValidateKeyBindings(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions map[string]string
+		quit    []string
+		wantErr string
+	}{
+		{name: "defaults", actions: nil},
+		{name: "rebound key", actions: map[string]string{ActionEdit: "E", ActionTunnels: "ctrl+t"}},
+		{name: "swapped keys", actions: map[string]string{ActionEdit: "d", ActionDelete: "e"}},
+		{name: "unknown action", actions: map[string]string{"launch": "l"}, wantErr: `unknown action "launch"`},
+		{name: "duplicate key", actions: map[string]string{ActionEdit: "d"}, wantErr: `"d" is bound to both delete and edit`},
+		{name: "reserved key", actions: map[string]string{ActionSearch: "tab"}, wantErr: `"tab" of search is reserved`},
+		{name: "quit key", actions: map[string]string{ActionSort: "x"}, quit: []string{"x"}, wantErr: `"x" of sort is also a quit key`},
+		{name: "empty key", actions: map[string]string{ActionEdit: " \t"}, wantErr: `no key given for "edit"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := GetDefaultKeyBindings()
+			kb.Actions = tt.actions
+			if tt.quit != nil {
+				kb.QuitKeys = tt.quit
+			}
+
+			err := kb.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected valid bindings, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
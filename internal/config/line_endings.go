@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+// utf8BOM is the byte order mark some Windows editors write at the start of files
+const utf8BOM = "\ufeff"
+
+// LineEndingIssues describes what a config file edited on Windows may contain
+// that strict parsers reject. sshm reads such files transparently.
+type LineEndingIssues struct {
+	BOM  bool // The file starts with a UTF-8 byte order mark
+	CRLF bool // Lines end with CRLF instead of LF
+}
+
+// Any reports whether the file has an issue
+func (i LineEndingIssues) Any() bool {
+	return i.BOM || i.CRLF
+}
+
+func (i LineEndingIssues) String() string {
+	var issues []string
+	if i.BOM {
+		issues = append(issues, "UTF-8 BOM")
+	}
+	if i.CRLF {
+		issues = append(issues, "CRLF line endings")
+	}
+	return strings.Join(issues, ", ")
+}
+
+func detectLineEndingIssues(content []byte) LineEndingIssues {
+	return LineEndingIssues{
+		BOM:  bytes.HasPrefix(content, []byte(utf8BOM)),
+		CRLF: bytes.Contains(content, []byte("\r\n")),
+	}
+}
+
+// normalizeLineEndings strips the BOM and turns CRLF line endings into LF
+func normalizeLineEndings(content []byte) []byte {
+	content = bytes.TrimPrefix(content, []byte(utf8BOM))
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// configLines splits config content into lines without BOM or CR. Edits put
+// them back with joinConfigLines, which restores both.
+func configLines(content []byte) []string {
+	return strings.Split(string(normalizeLineEndings(content)), "\n")
+}
+
+// joinConfigLines joins lines split by configLines, with the line endings and
+// the BOM of the content they came from, so that editing a host leaves the
+// file as Windows editors saved it
+func joinConfigLines(lines []string, original []byte) []byte {
+	issues := detectLineEndingIssues(original)
+	eol := "\n"
+	if issues.CRLF {
+		eol = "\r\n"
+	}
+	content := strings.Join(lines, eol)
+	if issues.BOM {
+		content = utf8BOM + content
+	}
+	return []byte(content)
+}
+
+// newConfigScanner returns a line scanner over a config file that skips the
+// BOM. bufio.ScanLines already drops the CR of CRLF line endings.
+func newConfigScanner(r io.Reader) *bufio.Scanner {
+	reader := bufio.NewReader(r)
+	if prefix, err := reader.Peek(len(utf8BOM)); err == nil && string(prefix) == utf8BOM {
+		_, _ = reader.Discard(len(utf8BOM))
+	}
+	return bufio.NewScanner(reader)
+}
+
+// CheckLineEndings reports the BOM and CRLF line endings of a config file
+func CheckLineEndings(configPath string) (LineEndingIssues, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return LineEndingIssues{}, err
+	}
+	return detectLineEndingIssues(content), nil
+}
+
+// FixLineEndings rewrites a config file without BOM and with LF line endings,
+// after backing it up. It reports whether the file needed fixing.
+func FixLineEndings(configPath string) (bool, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return false, err
+	}
+	if !detectLineEndingIssues(content).Any() {
+		return false, nil
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return false, err
+	}
+	if err := backupConfig(configPath); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(configPath, normalizeLineEndings(content), info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// windowsConfig is an SSH config as saved by some Windows editors
+const windowsConfig = "\ufeffHost web\r\n    HostName web.example.com\r\n    User deploy\r\n\r\n# Tags: prod, eu\r\nHost db\r\n    HostName 10.0.0.5\r\n    Port 2222\r\n"
+
+func writeWindowsConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, []byte(windowsConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return configPath
+}
+
+func TestParseConfigWithBOMAndCRLF(t *testing.T) {
+	configPath := writeWindowsConfig(t)
+
+	hosts, err := ParseSSHConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("Expected 2 hosts, got %+v", hosts)
+	}
+	web, db := hosts[0], hosts[1]
+	if web.Name != "web" || web.Hostname != "web.example.com" || web.User != "deploy" {
+		t.Errorf("Unexpected first host %+v", web)
+	}
+	if db.Name != "db" || db.Port != "2222" || strings.Join(db.Tags, ",") != "prod,eu" {
+		t.Errorf("Unexpected second host %+v", db)
+	}
+
+	if exists, err := QuickHostExistsInFile("web", configPath); err != nil || !exists {
+		t.Errorf("Expected web to be found after the BOM, got %v, %v", exists, err)
+	}
+	if exists, err := HostExistsInSpecificFile("web", configPath); err != nil || !exists {
+		t.Errorf("Expected web to exist, got %v, %v", exists, err)
+	}
+}
+
+func TestEditConfigWithBOMAndCRLF(t *testing.T) {
+	configPath := writeWindowsConfig(t)
+
+	if err := DeleteSSHHostFromFile("web", configPath); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "web.example.com") {
+		t.Errorf("Expected web to be deleted, got %q", content)
+	}
+	if issues := detectLineEndingIssues(content); !issues.BOM || !issues.CRLF || strings.Contains(strings.ReplaceAll(string(content), "\r\n", ""), "\n") {
+		t.Errorf("Expected the rewritten file to keep its BOM and CRLF line endings, got %q", content)
+	}
+	hosts, err := ParseSSHConfigFile(configPath)
+	if err != nil || len(hosts) != 1 || hosts[0].Name != "db" {
+		t.Errorf("Expected only db to remain, got %+v (%v)", hosts, err)
+	}
+}
+
+func TestFixLineEndings(t *testing.T) {
+	configPath := writeWindowsConfig(t)
+
+	issues, err := CheckLineEndings(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !issues.BOM || !issues.CRLF || issues.String() != "UTF-8 BOM, CRLF line endings" {
+		t.Errorf("Unexpected issues %+v (%s)", issues, issues)
+	}
+
+	fixed, err := FixLineEndings(configPath)
+	if err != nil || !fixed {
+		t.Fatalf("FixLineEndings() = %v, %v", fixed, err)
+	}
+	content, _ := os.ReadFile(configPath)
+	expected := strings.ReplaceAll(strings.TrimPrefix(windowsConfig, "\ufeff"), "\r\n", "\n")
+	if string(content) != expected {
+		t.Errorf("Fixed content = %q, want %q", content, expected)
+	}
+
+	// The original is backed up before rewriting
+	backupDir, err := GetSSHMBackupDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := os.ReadFile(filepath.Join(backupDir, "config.backup"))
+	if err != nil || string(backup) != windowsConfig {
+		t.Errorf("Expected the original in the backup, got %q (%v)", backup, err)
+	}
+
+	if fixed, err := FixLineEndings(configPath); err != nil || fixed {
+		t.Errorf("Expected nothing left to fix, got %v, %v", fixed, err)
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxRecentConfigFiles caps how many SSH config files are remembered
+const maxRecentConfigFiles = 10
+
+// recentConfigsData is the on-disk format of the recent config files list
+type recentConfigsData struct {
+	Files []string `json:"files"`
+}
+
+// GetRecentConfigsPath returns the path to the recently used config files list
+func GetRecentConfigsPath() (string, error) {
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "sshm_recent_configs.json"), nil
+}
+
+// LoadRecentConfigFiles returns the SSH config files used with sshm, most recent first.
+// A missing file means none have been recorded yet.
+func LoadRecentConfigFiles() ([]string, error) {
+	path, err := GetRecentConfigsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var recent recentConfigsData
+	if err := json.Unmarshal(data, &recent); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return recent.Files, nil
+}
+
+// RecordConfigFile moves configFile to the front of the recently used list.
+// An empty path records the default SSH config file.
+func RecordConfigFile(configFile string) error {
+	if configFile == "" {
+		defaultPath, err := GetDefaultSSHConfigPath()
+		if err != nil {
+			return err
+		}
+		configFile = defaultPath
+	}
+	configFile, err := filepath.Abs(configFile)
+	if err != nil {
+		return err
+	}
+
+	recent, err := LoadRecentConfigFiles()
+	if err != nil {
+		return err
+	}
+
+	files := []string{configFile}
+	for _, f := range recent {
+		if f != configFile {
+			files = append(files, f)
+		}
+	}
+	if len(files) > maxRecentConfigFiles {
+		files = files[:maxRecentConfigFiles]
+	}
+
+	path, err := GetRecentConfigsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(recentConfigsData{Files: files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0600)
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	files, err := LoadRecentConfigFiles()
+	if err != nil || len(files) != 0 {
+		t.Fatalf("LoadRecentConfigFiles() = (%v, %v), want nothing recorded yet", files, err)
+	}
+
+	work := filepath.Join(dir, "work_config")
+	lab := filepath.Join(dir, "lab_config")
+	for _, f := range []string{work, lab, "", work} {
+		if err := RecordConfigFile(f); err != nil {
+			t.Fatalf("RecordConfigFile(%q) error = %v", f, err)
+		}
+	}
+
+	files, err = LoadRecentConfigFiles()
+	if err != nil {
+		t.Fatalf("LoadRecentConfigFiles() error = %v", err)
+	}
+	defaultPath, _ := GetDefaultSSHConfigPath()
+	expected := []string{work, defaultPath, lab}
+	if fmt.Sprint(files) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, most recent first, got %v", expected, files)
+	}
+}
+
+func TestRecordConfigFileLimit(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	for i := 0; i < maxRecentConfigFiles+3; i++ {
+		if err := RecordConfigFile(filepath.Join(dir, fmt.Sprintf("config%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := LoadRecentConfigFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != maxRecentConfigFiles {
+		t.Fatalf("Expected %d files, got %d", maxRecentConfigFiles, len(files))
+	}
+	if last := filepath.Join(dir, fmt.Sprintf("config%d", maxRecentConfigFiles+2)); files[0] != last {
+		t.Errorf("Expected %s first, got %s", last, files[0])
+	}
+}
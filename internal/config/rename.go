@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RenameSSHHostInFile renames a host on its Host line in configPath, leaving
+// the rest of the file as it is. On a Host line naming several hosts, only
+// oldName is replaced.
+func RenameSSHHostInFile(oldName, newName, configPath string) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	exists, err := HostExistsInSpecificFile(newName, configPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("host '%s' already exists", newName)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	lines := configLines(content)
+	renamed := false
+	for i, line := range lines {
+		if renamedLine, ok := renameHostLine(line, oldName, newName); ok {
+			lines[i] = renamedLine
+			renamed = true
+			break
+		}
+	}
+	if !renamed {
+		return fmt.Errorf("host '%s' not found in %s", oldName, configPath)
+	}
+
+	if err := backupConfig(configPath); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	return os.WriteFile(configPath, joinConfigLines(lines, content), 0600)
+}
+
+// renameHostLine replaces oldName with newName when line is a Host line
+// naming oldName, keeping the indentation, spacing and trailing comment. An
+// empty newName removes oldName from the line.
+func renameHostLine(line, oldName, newName string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "Host") {
+		return line, false
+	}
+
+	// Walk the words of the line, after the Host keyword
+	pos := 0
+	for i, field := range fields {
+		start := pos + strings.Index(line[pos:], field)
+		pos = start + len(field)
+		if strings.HasPrefix(field, "#") {
+			break
+		}
+		if i > 0 && field == oldName {
+			if newName == "" {
+				return strings.TrimRight(line[:start], " \t") + line[pos:], true
+			}
+			return line[:start] + newName + line[pos:], true
+		}
+	}
+	return line, false
+}
+
+// RenameHostSettings moves the favorite mark, the web URL and the transfer
+// defaults of a host to its new name
+func RenameHostSettings(oldName, newName string) error {
+	favorites, err := LoadFavorites()
+	if err != nil {
+		return err
+	}
+	if favorites[oldName] {
+		if err := SetFavorite(oldName, false); err != nil {
+			return err
+		}
+		if err := SetFavorite(newName, true); err != nil {
+			return err
+		}
+	}
+
+	if webURL, err := LoadHostWebURL(oldName); err != nil {
+		return err
+	} else if webURL != "" {
+		if err := SetHostWebURL(newName, webURL); err != nil {
+			return err
+		}
+		if err := SetHostWebURL(oldName, ""); err != nil {
+			return err
+		}
+	}
+
+	defaults, err := loadTransferDefaults()
+	if err != nil {
+		return err
+	}
+	if hostDefaults, ok := defaults.Hosts[oldName]; ok {
+		defaults.Hosts[newName] = hostDefaults
+		delete(defaults.Hosts, oldName)
+		return saveTransferDefaults(defaults)
+	}
+	return nil
+}
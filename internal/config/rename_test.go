@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameHostLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+		ok   bool
+	}{
+		{"Host web", "Host www", true},
+		{"  host  web   # front", "  host  www   # front", true},
+		{"Host web-server web", "Host web-server www", true},
+		{"Host web-server", "Host web-server", false},
+		{"Host api # web", "Host api # web", false},
+		{"    HostName web", "    HostName web", false},
+		{"Match host web", "Match host web", false},
+	}
+	for _, tt := range tests {
+		got, ok := renameHostLine(tt.line, "web", "www")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("renameHostLine(%q) = %q, %v, want %q, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRenameSSHHostInFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	configPath := filepath.Join(dir, "config")
+	content := `# Tags: prod
+Host web
+    # Front servers
+    HostName 10.0.0.1
+    LocalForward 8080 localhost:80
+
+Host db
+    HostName db.internal
+    ProxyJump web
+`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RenameSSHHostInFile("web", "db", configPath); err == nil {
+		t.Error("Expected an error when the new name is taken")
+	}
+	if err := RenameSSHHostInFile("nope", "other", configPath); err == nil {
+		t.Error("Expected an error for an unknown host")
+	}
+
+	if err := RenameSSHHostInFile("web", "front", configPath); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Tags: prod
+Host front
+    # Front servers
+    HostName 10.0.0.1
+    LocalForward 8080 localhost:80
+
+Host db
+    HostName db.internal
+    ProxyJump web
+`
+	if string(data) != want {
+		t.Errorf("Expected only the Host line to change, got:\n%s", data)
+	}
+}
+
+func TestRenameHostSettings(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if err := SetFavorite("web", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetHostWebURL("web", "https://web.example.com:8443"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetHostRecursiveDefault("web", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RenameHostSettings("web", "front"); err != nil {
+		t.Fatal(err)
+	}
+
+	favorites, _ := LoadFavorites()
+	if favorites["web"] || !favorites["front"] {
+		t.Errorf("Expected the favorite mark moved, got %v", favorites)
+	}
+	if old, _ := LoadHostWebURL("web"); old != "" {
+		t.Errorf("Expected no web URL left under the old name, got %q", old)
+	}
+	if url, _ := LoadHostWebURL("front"); url != "https://web.example.com:8443" {
+		t.Errorf("Expected the web URL moved, got %q", url)
+	}
+	if defaults, _ := LoadHostTransferDefaults("front"); !defaults.Recursive {
+		t.Error("Expected the transfer defaults moved")
+	}
+	if defaults, _ := LoadHostTransferDefaults("web"); defaults.Recursive {
+		t.Error("Expected no transfer defaults left under the old name")
+	}
+
+	// A host without settings has nothing to move
+	if err := RenameHostSettings("db", "database"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ResolvedOption is a single key/value pair from `ssh -G` output
+type ResolvedOption struct {
+	Key   string
+	Value string
+}
+
+// ParseResolvedConfig parses `ssh -G` output into key/value pairs, preserving order.
+// Keys that appear multiple times (e.g. identityfile) are kept as separate entries.
+func ParseResolvedConfig(output string) []ResolvedOption {
+	var options []ResolvedOption
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, " ", 2)
+		key := strings.ToLower(parts[0])
+		value := ""
+		if len(parts) == 2 {
+			value = parts[1]
+		}
+
+		options = append(options, ResolvedOption{Key: key, Value: value})
+	}
+
+	return options
+}
+
+// GetResolvedConfig runs `ssh -G` for a host and returns the fully resolved options
+func GetResolvedConfig(hostName, configFile string) ([]ResolvedOption, error) {
+	args := []string{"-G", hostName}
+	if configFile != "" {
+		args = []string{"-F", configFile, "-G", hostName}
+	}
+
+	output, err := exec.Command("ssh", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("ssh -G failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("ssh -G failed: %w", err)
+	}
+
+	return ParseResolvedConfig(string(output)), nil
+}
+
+// FormatResolvedConfig formats resolved options as `key value` lines, like ssh -G
+func FormatResolvedConfig(options []ResolvedOption) string {
+	var b strings.Builder
+	for _, opt := range options {
+		b.WriteString(opt.Key)
+		if opt.Value != "" {
+			b.WriteString(" ")
+			b.WriteString(opt.Value)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ResolvedConnectArgs returns ssh arguments connecting with the options of
+// `ssh -G`, so the command works without the SSH config. A non-empty identity
+// replaces the identity files, like BuildConnectArgs. The default keys ssh
+// lists for every host are left for ssh to try on its own.
+func ResolvedConnectArgs(options []ResolvedOption, identity string) []string {
+	var args []string
+	values := make(map[string]string)
+	for _, opt := range options {
+		switch opt.Key {
+		case "identityfile":
+			if identity == "" && opt.Value != "" && !strings.EqualFold(opt.Value, "none") && !defaultIdentityNames[filepath.Base(opt.Value)] {
+				args = append(args, "-i", opt.Value)
+			}
+		default:
+			if _, seen := values[opt.Key]; !seen {
+				values[opt.Key] = opt.Value
+			}
+		}
+	}
+
+	if identity != "" {
+		args = append(args, BuildIdentityArgs(identity)...)
+	} else if values["identitiesonly"] == "yes" {
+		args = append(args, "-o", "IdentitiesOnly=yes")
+	}
+	if port := values["port"]; port != "" && port != "22" {
+		args = append(args, "-p", port)
+	}
+	if user := values["user"]; user != "" {
+		args = append(args, "-l", user)
+	}
+	if jump := values["proxyjump"]; jump != "" && !strings.EqualFold(jump, "none") {
+		args = append(args, "-J", jump)
+	} else if proxy := values["proxycommand"]; proxy != "" && !strings.EqualFold(proxy, "none") {
+		args = append(args, "-o", "ProxyCommand="+proxy)
+	}
+	return append(args, values["hostname"])
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseResolvedConfig(t *testing.T) {
+	output := `host myserver
+user deploy
+hostname 192.168.1.10
+port 2222
+identityfile ~/.ssh/id_ed25519
+identityfile ~/.ssh/id_rsa
+proxyjump bastion
+sendenv LANG
+sendenv LC_*
+localforward 8080 [localhost]:80
+forwardagent no
+`
+
+	options := ParseResolvedConfig(output)
+
+	expected := []ResolvedOption{
+		{"host", "myserver"},
+		{"user", "deploy"},
+		{"hostname", "192.168.1.10"},
+		{"port", "2222"},
+		{"identityfile", "~/.ssh/id_ed25519"},
+		{"identityfile", "~/.ssh/id_rsa"},
+		{"proxyjump", "bastion"},
+		{"sendenv", "LANG"},
+		{"sendenv", "LC_*"},
+		{"localforward", "8080 [localhost]:80"},
+		{"forwardagent", "no"},
+	}
+
+	if len(options) != len(expected) {
+		t.Fatalf("Expected %d options, got %d: %v", len(expected), len(options), options)
+	}
+
+	for i, want := range expected {
+		if options[i] != want {
+			t.Errorf("Option %d = %+v, want %+v", i, options[i], want)
+		}
+	}
+}
+
+func TestParseResolvedConfigEdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []ResolvedOption
+	}{
+		{
+			name:     "Empty output",
+			output:   "",
+			expected: nil,
+		},
+		{
+			name:     "CRLF line endings and blank lines",
+			output:   "User root\r\n\r\nPort 22\r\n",
+			expected: []ResolvedOption{{"user", "root"}, {"port", "22"}},
+		},
+		{
+			name:     "Key without value",
+			output:   "remotecommand\n",
+			expected: []ResolvedOption{{"remotecommand", ""}},
+		},
+		{
+			name:     "Value with spaces",
+			output:   "proxycommand ssh -W %h:%p bastion\n",
+			expected: []ResolvedOption{{"proxycommand", "ssh -W %h:%p bastion"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := ParseResolvedConfig(tt.output)
+			if len(options) != len(tt.expected) {
+				t.Fatalf("Expected %d options, got %d: %v", len(tt.expected), len(options), options)
+			}
+			for i, want := range tt.expected {
+				if options[i] != want {
+					t.Errorf("Option %d = %+v, want %+v", i, options[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatResolvedConfig(t *testing.T) {
+	options := []ResolvedOption{
+		{"user", "root"},
+		{"port", "22"},
+		{"remotecommand", ""},
+	}
+
+	expected := "user root\nport 22\nremotecommand\n"
+	if got := FormatResolvedConfig(options); got != expected {
+		t.Errorf("FormatResolvedConfig() = %q, want %q", got, expected)
+	}
+}
+
+func TestResolvedConnectArgs(t *testing.T) {
+	options := ParseResolvedConfig(`host web
+hostname 10.0.0.5
+user deploy
+port 2222
+identitiesonly yes
+identityfile ~/.ssh/id_rsa
+identityfile ~/.ssh/web_key
+proxyjump bastion
+`)
+
+	got := strings.Join(ResolvedConnectArgs(options, ""), " ")
+	want := "-i ~/.ssh/web_key -o IdentitiesOnly=yes -p 2222 -l deploy -J bastion 10.0.0.5"
+	if got != want {
+		t.Errorf("ResolvedConnectArgs() = %q, want %q", got, want)
+	}
+
+	got = strings.Join(ResolvedConnectArgs(options, "/tmp/other"), " ")
+	want = "-i /tmp/other -o IdentitiesOnly=yes -p 2222 -l deploy -J bastion 10.0.0.5"
+	if got != want {
+		t.Errorf("ResolvedConnectArgs() with identity = %q, want %q", got, want)
+	}
+
+	// Defaults stay implicit, and a ProxyCommand is kept when there is no jump
+	options = ParseResolvedConfig("hostname db.internal\nuser root\nport 22\nidentityfile ~/.ssh/id_ed25519\nproxyjump none\nproxycommand nc -X 5 %h %p\n")
+	got = strings.Join(ResolvedConnectArgs(options, ""), " ")
+	want = "-l root -o ProxyCommand=nc -X 5 %h %p db.internal"
+	if got != want {
+		t.Errorf("ResolvedConnectArgs() = %q, want %q", got, want)
+	}
+}
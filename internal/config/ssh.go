@@ -1,12 +1,12 @@
 package config
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -210,7 +210,7 @@ func parseSSHConfigFileWithProcessedFiles(configPath string, processedFiles map[
 	var hosts []SSHHost
 	var currentHost *SSHHost
 	var pendingTags []string
-	scanner := bufio.NewScanner(file)
+	scanner := newConfigScanner(file)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -221,16 +221,10 @@ func parseSSHConfigFileWithProcessedFiles(configPath string, processedFiles map[
 		}
 
 		// Check for tags comment
-		if strings.HasPrefix(line, "# Tags:") {
-			tagsStr := strings.TrimPrefix(line, "# Tags:")
-			tagsStr = strings.TrimSpace(tagsStr)
-			if tagsStr != "" {
-				// Split tags by comma and trim whitespace
-				for _, tag := range strings.Split(tagsStr, ",") {
-					tag = strings.TrimSpace(tag)
-					if tag != "" {
-						pendingTags = append(pendingTags, tag)
-					}
+		if tags, ok := parseTagsComment(line); ok {
+			for _, tag := range tags {
+				if !slices.Contains(pendingTags, tag) {
+					pendingTags = append(pendingTags, tag)
 				}
 			}
 			continue
@@ -259,20 +253,19 @@ func parseSSHConfigFileWithProcessedFiles(configPath string, processedFiles map[
 				continue
 			}
 			hosts = append(hosts, includeHosts...)
+		case "match":
+			// A Match block ends the current host. Its criteria are not host
+			// aliases and its options apply conditionally, so they are left to
+			// ssh (and `ssh -G` for the resolved view) rather than attributed here.
+			if currentHost != nil {
+				hosts = appendWithAliases(hosts, currentHost)
+			}
+			currentHost = nil
+			pendingTags = nil
 		case "host":
 			// New host, save previous one if it exists
 			if currentHost != nil {
-				hosts = append(hosts, *currentHost)
-
-				// Handle aliases: create duplicate hosts for each alias
-				if len(currentHost.aliasNames) > 0 {
-					for _, aliasName := range currentHost.aliasNames {
-						aliasHost := *currentHost // Copy the host
-						aliasHost.Name = aliasName
-						aliasHost.aliasNames = nil // Clear temporary field
-						hosts = append(hosts, aliasHost)
-					}
-				}
+				hosts = appendWithAliases(hosts, currentHost)
 			}
 
 			// Parse multiple host names from the Host line
@@ -351,26 +344,41 @@ func parseSSHConfigFileWithProcessedFiles(configPath string, processedFiles map[
 
 	// Add the last host if it exists
 	if currentHost != nil {
-		hosts = append(hosts, *currentHost)
-
-		// Handle aliases: create duplicate hosts for each alias
-		if len(currentHost.aliasNames) > 0 {
-			for _, aliasName := range currentHost.aliasNames {
-				aliasHost := *currentHost // Copy the host
-				aliasHost.Name = aliasName
-				aliasHost.aliasNames = nil // Clear temporary field
-				hosts = append(hosts, aliasHost)
-			}
-		}
-		// Clear the temporary field from the original
-		currentHost.aliasNames = nil
+		hosts = appendWithAliases(hosts, currentHost)
 	}
 
 	return hosts, scanner.Err()
 }
 
-// processIncludeDirective processes an Include directive and returns hosts from included files
-func processIncludeDirective(pattern string, baseConfigPath string, processedFiles map[string]bool) ([]SSHHost, error) {
+// appendWithAliases appends a parsed host, plus a copy for each additional
+// name declared on the same Host line
+func appendWithAliases(hosts []SSHHost, host *SSHHost) []SSHHost {
+	aliases := host.aliasNames
+	host.aliasNames = nil
+	hosts = append(hosts, *host)
+
+	for _, aliasName := range aliases {
+		aliasHost := *host // Copy the host
+		aliasHost.Name = aliasName
+		hosts = append(hosts, aliasHost)
+	}
+	return hosts
+}
+
+// isBlockStart reports whether a config line opens a new Host or Match block,
+// which ends the block before it
+func isBlockStart(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	keyword := strings.ToLower(fields[0])
+	return keyword == "host" || keyword == "match"
+}
+
+// includeMatches returns the paths an Include pattern of the config file at
+// baseConfigPath matches
+func includeMatches(pattern string, baseConfigPath string) ([]string, error) {
 	// Expand tilde to home directory
 	if strings.HasPrefix(pattern, "~") {
 		homeDir, err := os.UserHomeDir()
@@ -391,6 +399,15 @@ func processIncludeDirective(pattern string, baseConfigPath string, processedFil
 	if err != nil {
 		return nil, fmt.Errorf("failed to glob pattern %s: %w", pattern, err)
 	}
+	return matches, nil
+}
+
+// processIncludeDirective processes an Include directive and returns hosts from included files
+func processIncludeDirective(pattern string, baseConfigPath string, processedFiles map[string]bool) ([]SSHHost, error) {
+	matches, err := includeMatches(pattern, baseConfigPath)
+	if err != nil {
+		return nil, err
+	}
 
 	var allHosts []SSHHost
 	for _, match := range matches {
@@ -731,7 +748,7 @@ func HostExistsInSpecificFile(hostName string, configPath string) (bool, error)
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	scanner := newConfigScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 
@@ -823,12 +840,12 @@ func quickHostSearchInFile(hostName string, configPath string, processedFiles ma
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	scanner := newConfigScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 
 		// Ignore empty lines and comments (except includes)
-		if line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "# Tags:")) {
+		if line == "" || (strings.HasPrefix(line, "#") && !isTagsComment(line)) {
 			continue
 		}
 
@@ -919,7 +936,7 @@ func IsPartOfMultiHostDeclaration(hostName string, configPath string) (bool, []s
 		return false, nil, err
 	}
 
-	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	scanner := newConfigScanner(strings.NewReader(string(content)))
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 
@@ -943,7 +960,9 @@ func IsPartOfMultiHostDeclaration(hostName string, configPath string) (bool, []s
 	return false, nil, scanner.Err()
 }
 
-// UpdateSSHHostInFile updates an existing SSH host configuration in a specific file
+// UpdateSSHHostInFile updates an existing SSH host configuration in a specific file.
+// Only the block of the host is edited, in place: comments and blank lines
+// within it stay, as does the rest of the file.
 func UpdateSSHHostInFile(oldName string, newHost SSHHost, configPath string) error {
 	configMutex.Lock()
 	defer configMutex.Unlock()
@@ -953,332 +972,37 @@ func UpdateSSHHostInFile(oldName string, newHost SSHHost, configPath string) err
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	// Check if this host is part of a multi-host declaration
-	isMultiHost, hostNames, err := IsPartOfMultiHostDeclaration(oldName, configPath)
-	if err != nil {
-		return fmt.Errorf("failed to check multi-host declaration: %w", err)
-	}
-
 	// Read the current config
 	content, err := os.ReadFile(configPath)
 	if err != nil {
 		return err
 	}
 
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	i := 0
-	hostFound := false
-
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-
-		// Check for tags comment followed by Host
-		if strings.HasPrefix(line, "# Tags:") && i+1 < len(lines) {
-			nextLine := strings.TrimSpace(lines[i+1])
-
-			// Check if this is a Host line that contains our target host
-			if strings.HasPrefix(nextLine, "Host ") {
-				hostPart := strings.TrimSpace(nextLine[5:]) // Remove "Host "
-				foundHostNames := strings.Fields(hostPart)
-
-				// Check if our target host is in this Host declaration
-				targetHostIndex := -1
-				for idx, hostName := range foundHostNames {
-					if hostName == oldName {
-						targetHostIndex = idx
-						break
-					}
-				}
-
-				if targetHostIndex != -1 {
-					hostFound = true
-
-					if isMultiHost && len(hostNames) > 1 {
-						// Strategy: Remove old host from the line, add new host as separate entry
-						// Remove the old host name from the Host line
-						var remainingHosts []string
-						for idx, hostName := range foundHostNames {
-							if idx != targetHostIndex {
-								remainingHosts = append(remainingHosts, hostName)
-							}
-						}
-
-						// Keep the tags comment
-						newLines = append(newLines, lines[i])
-
-						// Update the Host line with remaining hosts
-						if len(remainingHosts) > 0 {
-							newLines = append(newLines, "Host "+strings.Join(remainingHosts, " "))
-
-							// Copy the existing configuration for remaining hosts
-							i += 2 // Skip tags and original Host line
-							for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-								newLines = append(newLines, lines[i])
-								i++
-							}
-						} else {
-							// No remaining hosts, skip the entire block
-							i += 2 // Skip tags and Host line
-							for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-								i++
-							}
-						}
-
-						// Add the new host as a separate entry
-						newLines = append(newLines, "")
-						if len(newHost.Tags) > 0 {
-							newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-						}
-						newLines = append(newLines, "Host "+newHost.Name)
-						newLines = append(newLines, "    HostName "+newHost.Hostname)
-						if newHost.User != "" {
-							newLines = append(newLines, "    User "+newHost.User)
-						}
-						if newHost.Port != "" && newHost.Port != "22" {
-							newLines = append(newLines, "    Port "+newHost.Port)
-						}
-						if newHost.Identity != "" {
-							newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(newHost.Identity))
-						}
-						if newHost.ProxyJump != "" {
-							newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-						}
-						if newHost.RemoteCommand != "" {
-							newLines = append(newLines, "    RemoteCommand "+newHost.RemoteCommand)
-						}
-						if newHost.RequestTTY != "" {
-							newLines = append(newLines, "    RequestTTY "+newHost.RequestTTY)
-						}
-						// Write SSH options
-						if newHost.Options != "" {
-							options := strings.Split(newHost.Options, "\n")
-							for _, option := range options {
-								option = strings.TrimSpace(option)
-								if option != "" {
-									newLines = append(newLines, "    "+option)
-								}
-							}
-						}
-						newLines = append(newLines, "")
-
-						continue
-					} else {
-						// Simple case: only one host, replace entire block
-						// Skip until we find the end of this host block (empty line or next Host)
-						i += 2 // Skip tags and Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							i++
-						}
-
-						// Skip any trailing empty lines after the host block
-						for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-							i++
-						}
-
-						// Insert new configuration at this position
-						// Add empty line only if the previous line is not empty
-						if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
-							newLines = append(newLines, "")
-						}
-						if len(newHost.Tags) > 0 {
-							newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-						}
-						newLines = append(newLines, "Host "+newHost.Name)
-						newLines = append(newLines, "    HostName "+newHost.Hostname)
-						if newHost.User != "" {
-							newLines = append(newLines, "    User "+newHost.User)
-						}
-						if newHost.Port != "" && newHost.Port != "22" {
-							newLines = append(newLines, "    Port "+newHost.Port)
-						}
-						if newHost.Identity != "" {
-							newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(newHost.Identity))
-						}
-						if newHost.ProxyJump != "" {
-							newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-						}
-						if newHost.RemoteCommand != "" {
-							newLines = append(newLines, "    RemoteCommand "+newHost.RemoteCommand)
-						}
-						if newHost.RequestTTY != "" {
-							newLines = append(newLines, "    RequestTTY "+newHost.RequestTTY)
-						}
-						// Write SSH options
-						if newHost.Options != "" {
-							options := strings.Split(newHost.Options, "\n")
-							for _, option := range options {
-								option = strings.TrimSpace(option)
-								if option != "" {
-									newLines = append(newLines, "    "+option)
-								}
-							}
-						}
-
-						// Add empty line after the host configuration for separation
-						newLines = append(newLines, "")
-
-						continue
-					}
-				}
-			}
-		}
-
-		// Check for Host line without tags
-		if strings.HasPrefix(line, "Host ") {
-			hostPart := strings.TrimSpace(line[5:]) // Remove "Host "
-			foundHostNames := strings.Fields(hostPart)
-
-			// Check if our target host is in this Host declaration
-			targetHostIndex := -1
-			for idx, hostName := range foundHostNames {
-				if hostName == oldName {
-					targetHostIndex = idx
-					break
-				}
-			}
-
-			if targetHostIndex != -1 {
-				hostFound = true
-
-				if isMultiHost && len(hostNames) > 1 {
-					// Strategy: Remove old host from the line, add new host as separate entry
-					// Remove the old host name from the Host line
-					var remainingHosts []string
-					for idx, hostName := range foundHostNames {
-						if idx != targetHostIndex {
-							remainingHosts = append(remainingHosts, hostName)
-						}
-					}
-
-					// Update the Host line with remaining hosts
-					if len(remainingHosts) > 0 {
-						newLines = append(newLines, "Host "+strings.Join(remainingHosts, " "))
-
-						// Copy the existing configuration for remaining hosts
-						i++ // Skip original Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							newLines = append(newLines, lines[i])
-							i++
-						}
-					} else {
-						// No remaining hosts, skip the entire block
-						i++ // Skip Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							i++
-						}
-					}
-
-					// Add the new host as a separate entry
-					newLines = append(newLines, "")
-					if len(newHost.Tags) > 0 {
-						newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-					}
-					newLines = append(newLines, "Host "+newHost.Name)
-					newLines = append(newLines, "    HostName "+newHost.Hostname)
-					if newHost.User != "" {
-						newLines = append(newLines, "    User "+newHost.User)
-					}
-					if newHost.Port != "" && newHost.Port != "22" {
-						newLines = append(newLines, "    Port "+newHost.Port)
-					}
-					if newHost.Identity != "" {
-						newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(newHost.Identity))
-					}
-					if newHost.ProxyJump != "" {
-						newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-					}
-					if newHost.RemoteCommand != "" {
-						newLines = append(newLines, "    RemoteCommand "+newHost.RemoteCommand)
-					}
-					if newHost.RequestTTY != "" {
-						newLines = append(newLines, "    RequestTTY "+newHost.RequestTTY)
-					}
-					// Write SSH options
-					if newHost.Options != "" {
-						options := strings.Split(newHost.Options, "\n")
-						for _, option := range options {
-							option = strings.TrimSpace(option)
-							if option != "" {
-								newLines = append(newLines, "    "+option)
-							}
-						}
-					}
-					newLines = append(newLines, "")
-
-					continue
-				} else {
-					// Simple case: only one host, replace entire block
-					// Skip until we find the end of this host block
-					i++ // Skip Host line
-					for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-						i++
-					}
-
-					// Skip any trailing empty lines after the host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-						i++
-					}
-
-					// Insert new configuration
-					// Add empty line only if the previous line is not empty
-					if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
-						newLines = append(newLines, "")
-					}
-					if len(newHost.Tags) > 0 {
-						newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-					}
-					newLines = append(newLines, "Host "+newHost.Name)
-					newLines = append(newLines, "    HostName "+newHost.Hostname)
-					if newHost.User != "" {
-						newLines = append(newLines, "    User "+newHost.User)
-					}
-					if newHost.Port != "" && newHost.Port != "22" {
-						newLines = append(newLines, "    Port "+newHost.Port)
-					}
-					if newHost.Identity != "" {
-						newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(newHost.Identity))
-					}
-					if newHost.ProxyJump != "" {
-						newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-					}
-					if newHost.RemoteCommand != "" {
-						newLines = append(newLines, "    RemoteCommand "+newHost.RemoteCommand)
-					}
-					if newHost.RequestTTY != "" {
-						newLines = append(newLines, "    RequestTTY "+newHost.RequestTTY)
-					}
-					// Write SSH options
-					if newHost.Options != "" {
-						options := strings.Split(newHost.Options, "\n")
-						for _, option := range options {
-							option = strings.TrimSpace(option)
-							if option != "" {
-								newLines = append(newLines, "    "+option)
-							}
-						}
-					}
-
-					// Add empty line after the host configuration for separation
-					newLines = append(newLines, "")
-
-					continue
-				}
-			}
-		}
-
-		// Keep other lines as-is
-		newLines = append(newLines, lines[i])
-		i++
+	lines := configLines(content)
+	block, found := findHostBlock(lines, oldName)
+	if !found {
+		return fmt.Errorf("host '%s' not found", oldName)
 	}
 
-	if !hostFound {
-		return fmt.Errorf("host '%s' not found", oldName)
+	var newLines []string
+	if names, _ := hostLineNames(lines[block.hostLine]); len(names) > 1 {
+		// The other hosts of the line keep the block, the edited host gets
+		// its own block right after it
+		newLines = append(newLines, lines[:block.end]...)
+		newLines[block.hostLine], _ = renameHostLine(lines[block.hostLine], oldName, "")
+		newLines = append(newLines, "")
+		newLines = append(newLines, hostStanzaLines(newHost)...)
+		if block.end < len(lines) && strings.TrimSpace(lines[block.end]) != "" {
+			newLines = append(newLines, "")
+		}
+	} else {
+		newLines = append(newLines, lines[:block.start]...)
+		newLines = append(newLines, editHostBlock(lines, block, oldName, newHost)...)
 	}
+	newLines = append(newLines, lines[block.end:]...)
 
 	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	return os.WriteFile(configPath, []byte(newContent), 0600)
+	return os.WriteFile(configPath, joinConfigLines(newLines, content), 0600)
 }
 
 // DeleteSSHHost removes an SSH host configuration from the config file
@@ -1286,7 +1010,9 @@ func DeleteSSHHost(hostName string) error {
 	return DeleteSSHHostV2(hostName)
 }
 
-// DeleteSSHHostFromFile deletes an SSH host from a specific config file
+// DeleteSSHHostFromFile deletes an SSH host from a specific config file. Only
+// the block of the host is removed, or its name on a Host line naming several
+// hosts: the rest of the file is kept as it is.
 func DeleteSSHHostFromFile(hostName, configPath string) error {
 	configMutex.Lock()
 	defer configMutex.Unlock()
@@ -1296,186 +1022,42 @@ func DeleteSSHHostFromFile(hostName, configPath string) error {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	// Check if this host is part of a multi-host declaration
-	isMultiHost, hostNames, err := IsPartOfMultiHostDeclaration(hostName, configPath)
-	if err != nil {
-		return fmt.Errorf("failed to check multi-host declaration: %w", err)
-	}
-
 	// Read the current config
 	content, err := os.ReadFile(configPath)
 	if err != nil {
 		return err
 	}
 
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	i := 0
-	hostFound := false
-
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-
-		// Check for tags comment followed by Host
-		if strings.HasPrefix(line, "# Tags:") && i+1 < len(lines) {
-			nextLine := strings.TrimSpace(lines[i+1])
-
-			// Check if this is a Host line that contains our target host
-			if strings.HasPrefix(nextLine, "Host ") {
-				hostPart := strings.TrimSpace(nextLine[5:]) // Remove "Host "
-				foundHostNames := strings.Fields(hostPart)
-
-				// Check if our target host is in this Host declaration
-				targetHostIndex := -1
-				for idx, host := range foundHostNames {
-					if host == hostName {
-						targetHostIndex = idx
-						break
-					}
-				}
-
-				if targetHostIndex != -1 {
-					hostFound = true
-
-					if isMultiHost && len(hostNames) > 1 {
-						// Remove the target host from the multi-host line
-						var remainingHosts []string
-						for idx, host := range foundHostNames {
-							if idx != targetHostIndex {
-								remainingHosts = append(remainingHosts, host)
-							}
-						}
-
-						// Keep the tags comment
-						newLines = append(newLines, lines[i])
-
-						if len(remainingHosts) > 0 {
-							// Update the Host line with remaining hosts
-							newLines = append(newLines, "Host "+strings.Join(remainingHosts, " "))
-
-							// Copy the existing configuration for remaining hosts
-							i += 2 // Skip tags and original Host line
-							for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-								newLines = append(newLines, lines[i])
-								i++
-							}
-						} else {
-							// No remaining hosts, skip the entire block
-							i += 2 // Skip tags and Host line
-							for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-								i++
-							}
-						}
-
-						// Skip any trailing empty lines after the host block
-						for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-							i++
-						}
-
-						continue
-					} else {
-						// Single host or last host in multi-host block, delete entire block
-						// Skip tags comment and Host line
-						i += 2
-
-						// Skip until we find the end of this host block (empty line or next Host)
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							i++
-						}
-
-						// Skip any trailing empty lines after the host block
-						for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-							i++
-						}
+	lines := configLines(content)
+	block, found := findHostBlock(lines, hostName)
+	if !found {
+		return fmt.Errorf("host '%s' not found", hostName)
+	}
 
-						continue
-					}
-				}
+	var newLines []string
+	if names, _ := hostLineNames(lines[block.hostLine]); len(names) > 1 {
+		// The other hosts of the line keep the block
+		newLines = append(newLines, lines...)
+		newLines[block.hostLine], _ = renameHostLine(lines[block.hostLine], hostName, "")
+	} else {
+		newLines = append(newLines, lines[:block.start]...)
+		rest := lines[block.end:]
+		// Drop the blank lines that separated the block, unless they now
+		// separate a comment above it from what follows
+		if block.start == 0 || strings.TrimSpace(lines[block.start-1]) == "" {
+			for len(rest) > 0 && strings.TrimSpace(rest[0]) == "" {
+				rest = rest[1:]
 			}
 		}
-
-		// Check for Host line without tags
-		if strings.HasPrefix(line, "Host ") {
-			hostPart := strings.TrimSpace(line[5:]) // Remove "Host "
-			foundHostNames := strings.Fields(hostPart)
-
-			// Check if our target host is in this Host declaration
-			targetHostIndex := -1
-			for idx, host := range foundHostNames {
-				if host == hostName {
-					targetHostIndex = idx
-					break
-				}
-			}
-
-			if targetHostIndex != -1 {
-				hostFound = true
-
-				if isMultiHost && len(hostNames) > 1 {
-					// Remove the target host from the multi-host line
-					var remainingHosts []string
-					for idx, host := range foundHostNames {
-						if idx != targetHostIndex {
-							remainingHosts = append(remainingHosts, host)
-						}
-					}
-
-					if len(remainingHosts) > 0 {
-						// Update the Host line with remaining hosts
-						newLines = append(newLines, "Host "+strings.Join(remainingHosts, " "))
-
-						// Copy the existing configuration for remaining hosts
-						i++ // Skip original Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							newLines = append(newLines, lines[i])
-							i++
-						}
-					} else {
-						// No remaining hosts, skip the entire block
-						i++ // Skip Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							i++
-						}
-					}
-
-					// Skip any trailing empty lines after the host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-						i++
-					}
-
-					continue
-				} else {
-					// Single host, delete entire block
-					// Skip Host line
-					i++
-
-					// Skip until we find the end of this host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-						i++
-					}
-
-					// Skip any trailing empty lines after the host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-						i++
-					}
-
-					continue
-				}
-			}
+		newLines = append(newLines, rest...)
+		// Keep the final newline when the block was the last one
+		if len(rest) == 0 && len(newLines) > 0 && newLines[len(newLines)-1] != "" {
+			newLines = append(newLines, "")
 		}
-
-		// Keep other lines as-is
-		newLines = append(newLines, lines[i])
-		i++
-	}
-
-	if !hostFound {
-		return fmt.Errorf("host '%s' not found", hostName)
 	}
 
 	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	return os.WriteFile(configPath, []byte(newContent), 0600)
+	return os.WriteFile(configPath, joinConfigLines(newLines, content), 0600)
 }
 
 // FindHostInAllConfigs finds a host in all configuration files and returns the host with its source file
@@ -1621,6 +1203,50 @@ func MoveHostToFile(hostName string, targetConfigFile string) error {
 	return nil
 }
 
+// CopyHostToFile duplicates an SSH host block into another config file, keeping the original.
+// The host is looked up from baseConfigFile (or the default config when empty).
+func CopyHostToFile(hostName, baseConfigFile, targetConfigFile string) error {
+	var hosts []SSHHost
+	var err error
+	if baseConfigFile != "" {
+		hosts, err = ParseSSHConfigFile(baseConfigFile)
+	} else {
+		hosts, err = ParseSSHConfig()
+	}
+	if err != nil {
+		return err
+	}
+
+	var host *SSHHost
+	for i := range hosts {
+		if hosts[i].Name == hostName {
+			host = &hosts[i]
+			break
+		}
+	}
+	if host == nil {
+		return fmt.Errorf("host '%s' not found in any configuration file", hostName)
+	}
+
+	if host.SourceFile == targetConfigFile {
+		return fmt.Errorf("host '%s' is already in the target config file '%s'", hostName, targetConfigFile)
+	}
+
+	// Refuse to create a second block with the same name in the destination
+	exists, err := HostExistsInSpecificFile(hostName, targetConfigFile)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("host '%s' already exists in '%s', rename it before copying", hostName, targetConfigFile)
+	}
+
+	if err := AddSSHHostToFile(*host, targetConfigFile); err != nil {
+		return fmt.Errorf("failed to add host to target file: %v", err)
+	}
+	return nil
+}
+
 // GetConfigFilesExcludingCurrent returns all config files except the one containing the specified host
 func GetConfigFilesExcludingCurrent(hostName string, baseConfigFile string) ([]string, error) {
 	// Get all config files
@@ -1670,7 +1296,7 @@ func UpdateMultiHostBlock(originalHosts, newHosts []string, commonProperties SSH
 		return err
 	}
 
-	lines := strings.Split(string(content), "\n")
+	lines := configLines(content)
 	var newLines []string
 	i := 0
 	blockFound := false
@@ -1679,7 +1305,7 @@ func UpdateMultiHostBlock(originalHosts, newHosts []string, commonProperties SSH
 		line := strings.TrimSpace(lines[i])
 
 		// Check for tags comment followed by Host
-		if strings.HasPrefix(line, "# Tags:") && i+1 < len(lines) {
+		if isTagsComment(line) && i+1 < len(lines) {
 			nextLine := strings.TrimSpace(lines[i+1])
 
 			// Check if this is a Host line that contains any of our original hosts
@@ -1706,7 +1332,7 @@ func UpdateMultiHostBlock(originalHosts, newHosts []string, commonProperties SSH
 
 					// Skip the old block entirely
 					i += 2 // Skip tags and Host line
-					for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
+					for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !isBlockStart(lines[i]) {
 						i++
 					}
 
@@ -1792,7 +1418,7 @@ func UpdateMultiHostBlock(originalHosts, newHosts []string, commonProperties SSH
 
 				// Skip the old block entirely
 				i++ // Skip Host line
-				for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
+				for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !isBlockStart(lines[i]) {
 					i++
 				}
 
@@ -1863,6 +1489,5 @@ func UpdateMultiHostBlock(originalHosts, newHosts []string, commonProperties SSH
 	}
 
 	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	return os.WriteFile(configPath, []byte(newContent), 0600)
+	return os.WriteFile(configPath, joinConfigLines(newLines, content), 0600)
 }
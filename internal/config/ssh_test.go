@@ -378,6 +378,102 @@ Host another-real-server
 	}
 }
 
+func TestParseSSHConfigWithMatchBlocks(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, "config")
+	configContent := `Host web
+    HostName web.example.com
+    User deploy
+Match host web.example.com exec "test -f ~/.vpn"
+    ProxyJump bastion
+    User vpnuser
+
+# Tags: db
+Host db
+    HostName db.example.com
+    ServerAliveInterval 30
+
+Match all
+    ForwardAgent no
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+
+	if len(hosts) != 2 || hosts[0].Name != "web" || hosts[1].Name != "db" {
+		names := make([]string, len(hosts))
+		for i, h := range hosts {
+			names[i] = h.Name
+		}
+		t.Fatalf("Expected hosts [web db], got %v", names)
+	}
+
+	// Options inside a Match block must not be attributed to the host before it
+	web := hosts[0]
+	if web.User != "deploy" || web.ProxyJump != "" {
+		t.Errorf("web picked up Match options: user=%q, proxyjump=%q", web.User, web.ProxyJump)
+	}
+
+	db := hosts[1]
+	if db.Options != "ServerAliveInterval 30" {
+		t.Errorf("db options = %q, want only its own", db.Options)
+	}
+	if len(db.Tags) != 1 || db.Tags[0] != "db" {
+		t.Errorf("db tags = %v, want [db]", db.Tags)
+	}
+
+	// Match criteria are not host aliases
+	for _, name := range []string{"host", "all", "exec"} {
+		found, err := QuickHostExistsInFile(name, configFile)
+		if err != nil {
+			t.Fatalf("QuickHostExistsInFile() error = %v", err)
+		}
+		if found {
+			t.Errorf("Match criterion %q reported as a host", name)
+		}
+	}
+}
+
+func TestDeleteHostBeforeMatchBlock(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, "config")
+	configContent := `Host web
+    HostName web.example.com
+Match host web.example.com
+    ProxyJump bastion
+
+Host db
+    HostName db.example.com
+`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	if err := DeleteSSHHostFromFile("web", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Contains(string(content), "HostName web.example.com") {
+		t.Errorf("Host web was not deleted:\n%s", content)
+	}
+	if !strings.Contains(string(content), "Match host web.example.com\n    ProxyJump bastion") {
+		t.Errorf("Match block was removed with the host:\n%s", content)
+	}
+}
+
 func TestParseSSHConfigExcludesBackupFiles(t *testing.T) {
 	// Create temporary directory for test files
 	tempDir := t.TempDir()
@@ -1694,3 +1790,91 @@ Host production-server
 		}
 	}
 }
+
+func TestCopyHostToFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mainConfig := filepath.Join(tempDir, "config")
+	mainConfigContent := `Include work.conf
+
+# Tags: prod, web
+Host web1
+    HostName web1.example.com
+    User deploy
+    Port 2222
+    IdentityFile ~/.ssh/web_key
+    ProxyJump bastion
+    ServerAliveInterval 60
+`
+	if err := os.WriteFile(mainConfig, []byte(mainConfigContent), 0600); err != nil {
+		t.Fatalf("Failed to create main config: %v", err)
+	}
+
+	workConfig := filepath.Join(tempDir, "work.conf")
+	workConfigContent := `Host db1
+    HostName db1.example.com
+`
+	if err := os.WriteFile(workConfig, []byte(workConfigContent), 0600); err != nil {
+		t.Fatalf("Failed to create work config: %v", err)
+	}
+
+	if err := CopyHostToFile("web1", mainConfig, workConfig); err != nil {
+		t.Fatalf("CopyHostToFile() error = %v", err)
+	}
+
+	// The full block is written to the destination
+	copied, err := GetSSHHostFromFile("web1", workConfig)
+	if err != nil {
+		t.Fatalf("Copied host not found: %v", err)
+	}
+	if copied.Hostname != "web1.example.com" || copied.User != "deploy" || copied.Port != "2222" ||
+		copied.Identity != "~/.ssh/web_key" || copied.ProxyJump != "bastion" {
+		t.Errorf("Copied host is incomplete: %+v", copied)
+	}
+	if !strings.Contains(copied.Options, "ServerAliveInterval 60") {
+		t.Errorf("Expected options to be copied, got %q", copied.Options)
+	}
+	if len(copied.Tags) != 2 {
+		t.Errorf("Expected tags to be copied, got %v", copied.Tags)
+	}
+
+	// The original is kept
+	exists, err := HostExistsInSpecificFile("web1", mainConfig)
+	if err != nil || !exists {
+		t.Errorf("Expected original host to remain in source file (err = %v)", err)
+	}
+}
+
+func TestCopyHostToFileConflicts(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mainConfig := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(mainConfig, []byte("Host web1\n    HostName web1.example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to create main config: %v", err)
+	}
+	workConfig := filepath.Join(tempDir, "work.conf")
+	workConfigContent := "Host web1 web1-alt\n    HostName other.example.com\n"
+	if err := os.WriteFile(workConfig, []byte(workConfigContent), 0600); err != nil {
+		t.Fatalf("Failed to create work config: %v", err)
+	}
+
+	// Name already defined in the destination
+	err := CopyHostToFile("web1", mainConfig, workConfig)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected a name conflict error, got %v", err)
+	}
+	content, _ := os.ReadFile(workConfig)
+	if string(content) != workConfigContent {
+		t.Error("Destination file should not be modified on conflict")
+	}
+
+	// Copying into the file that already holds the host
+	if err := CopyHostToFile("web1", mainConfig, mainConfig); err == nil {
+		t.Error("Expected an error when copying a host into its own file")
+	}
+
+	// Unknown host
+	if err := CopyHostToFile("missing", mainConfig, workConfig); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// tagsCommentPrefixes start the comment giving the tags of the host that
+// follows it: sshm writes "# Tags: prod, web", "#sshm:tags prod,web" is read too
+var tagsCommentPrefixes = []string{"# Tags:", "#sshm:tags", "# sshm:tags"}
+
+// parseTagsComment returns the tags of a tags comment line
+func parseTagsComment(line string) ([]string, bool) {
+	for _, prefix := range tagsCommentPrefixes {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		var tags []string
+		for _, tag := range strings.Split(strings.TrimPrefix(line, prefix), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return tags, true
+	}
+	return nil, false
+}
+
+// isTagsComment reports whether line is a tags comment, which belongs to the
+// Host line below it
+func isTagsComment(line string) bool {
+	_, ok := parseTagsComment(line)
+	return ok
+}
+
+// HasTag reports whether host has tag, ignoring case
+func HasTag(host SSHHost, tag string) bool {
+	for _, t := range host.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterHostsByTags returns the hosts having every one of tags
+func FilterHostsByTags(hosts []SSHHost, tags []string) []SSHHost {
+	var filtered []SSHHost
+	for _, host := range hosts {
+		matched := true
+		for _, tag := range tags {
+			if !HasTag(host, tag) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, host)
+		}
+	}
+	return filtered
+}
+
+// HostTags returns the tags used by the hosts, sorted and without duplicates
+// (ignoring case)
+func HostTags(hosts []SSHHost) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, host := range hosts {
+		for _, tag := range host.Tags {
+			if key := strings.ToLower(tag); !seen[key] {
+				seen[key] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+	})
+	return tags
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSSHConfigTagsComments(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	content := `# Tags: prod, web
+Host web
+    HostName web.example.com
+
+#sshm:tags staging,db
+# sshm:tags db, backup
+Host db
+    HostName db.example.com
+
+Host plain
+    HostName plain.example.com
+`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := ParseSSHConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := make(map[string][]string)
+	for _, host := range hosts {
+		tags[host.Name] = host.Tags
+	}
+	want := map[string][]string{
+		"web":   {"prod", "web"},
+		"db":    {"staging", "db", "backup"},
+		"plain": nil,
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, tags)
+	}
+}
+
+func TestUpdateHostReplacesSSHMTagsComment(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	content := `#sshm:tags staging
+Host db
+    HostName db.example.com
+
+Host web
+    HostName web.example.com
+`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	host := SSHHost{Name: "db", Hostname: "db.example.com", Tags: []string{"prod"}}
+	if err := UpdateSSHHostInFile("db", host, configPath); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sshm:tags") || !strings.Contains(string(data), "# Tags: prod") {
+		t.Errorf("Expected the tags comment to be rewritten, got:\n%s", data)
+	}
+
+	if err := DeleteSSHHostFromFile("db", configPath); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := ParseSSHConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "web" || len(hosts[0].Tags) != 0 {
+		t.Errorf("Expected web alone without the tags of db, got %+v", hosts)
+	}
+}
+
+func TestHostTagsAndFilter(t *testing.T) {
+	hosts := []SSHHost{
+		{Name: "web", Tags: []string{"prod", "web"}},
+		{Name: "db", Tags: []string{"Prod", "db"}},
+		{Name: "dev"},
+	}
+
+	if tags := HostTags(hosts); !reflect.DeepEqual(tags, []string{"db", "prod", "web"}) {
+		t.Errorf("Expected the sorted tags without duplicates, got %v", tags)
+	}
+
+	filtered := FilterHostsByTags(hosts, []string{"prod"})
+	if len(filtered) != 2 {
+		t.Errorf("Expected both prod hosts, ignoring case, got %+v", filtered)
+	}
+	filtered = FilterHostsByTags(hosts, []string{"prod", "db"})
+	if len(filtered) != 1 || filtered[0].Name != "db" {
+		t.Errorf("Expected only the host with every tag, got %+v", filtered)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// HostTransferDefaults are the transfer settings a host starts with in the transfer forms
+type HostTransferDefaults struct {
+	Recursive bool   `json:"recursive,omitempty"`  // Transfer folders rather than single files
+	UploadDir string `json:"upload_dir,omitempty"` // Remote directory the browser opens in for uploads
+}
+
+// transferDefaultsData is the on-disk format of the per-host transfer defaults
+type transferDefaultsData struct {
+	Hosts map[string]HostTransferDefaults `json:"hosts"`
+}
+
+// GetTransferDefaultsPath returns the path to the per-host transfer defaults file
+func GetTransferDefaultsPath() (string, error) {
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "sshm_transfer_defaults.json"), nil
+}
+
+// loadTransferDefaults reads all per-host transfer defaults.
+// A missing file means no host has defaults yet.
+func loadTransferDefaults() (transferDefaultsData, error) {
+	defaults := transferDefaultsData{Hosts: make(map[string]HostTransferDefaults)}
+
+	path, err := GetTransferDefaultsPath()
+	if err != nil {
+		return defaults, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+
+	if err := json.Unmarshal(data, &defaults); err != nil {
+		return defaults, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if defaults.Hosts == nil {
+		defaults.Hosts = make(map[string]HostTransferDefaults)
+	}
+	return defaults, nil
+}
+
+// LoadHostTransferDefaults returns the transfer defaults of a host
+func LoadHostTransferDefaults(hostName string) (HostTransferDefaults, error) {
+	defaults, err := loadTransferDefaults()
+	if err != nil {
+		return HostTransferDefaults{}, err
+	}
+	return defaults.Hosts[hostName], nil
+}
+
+// SetHostRecursiveDefault makes transfers with a host default to folders, or back to files
+func SetHostRecursiveDefault(hostName string, recursive bool) error {
+	defaults, err := loadTransferDefaults()
+	if err != nil {
+		return err
+	}
+
+	host := defaults.Hosts[hostName]
+	host.Recursive = recursive
+	if host == (HostTransferDefaults{}) {
+		delete(defaults.Hosts, hostName)
+	} else {
+		defaults.Hosts[hostName] = host
+	}
+	return saveTransferDefaults(defaults)
+}
+
+// saveTransferDefaults writes all per-host transfer defaults
+func saveTransferDefaults(defaults transferDefaultsData) error {
+	path, err := GetTransferDefaultsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(defaults, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0600)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestHostRecursiveDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	defaults, err := LoadHostTransferDefaults("web")
+	if err != nil || defaults.Recursive {
+		t.Fatalf("LoadHostTransferDefaults() = (%+v, %v), want no defaults yet", defaults, err)
+	}
+
+	if err := SetHostRecursiveDefault("web", true); err != nil {
+		t.Fatalf("SetHostRecursiveDefault() error = %v", err)
+	}
+	if defaults, _ := LoadHostTransferDefaults("web"); !defaults.Recursive {
+		t.Error("Expected web to default to recursive transfers")
+	}
+	if defaults, _ := LoadHostTransferDefaults("db"); defaults.Recursive {
+		t.Error("Other hosts should keep the file default")
+	}
+
+	// Turning the default off forgets the host
+	if err := SetHostRecursiveDefault("web", false); err != nil {
+		t.Fatalf("SetHostRecursiveDefault() error = %v", err)
+	}
+	path, _ := GetTransferDefaultsPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{\n  \"hosts\": {}\n}" {
+		t.Errorf("Expected no hosts left, got %s", data)
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SavedView is a named host search query, recalled from the saved views menu
+type SavedView struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+	Sort  string `json:"sort,omitempty"` // Sort mode key, e.g. "name" or "last_used"
+}
+
+// savedViewsData is the on-disk format of the saved views file
+type savedViewsData struct {
+	Views []SavedView `json:"views"`
+}
+
+// GetSavedViewsPath returns the path to the saved views file
+func GetSavedViewsPath() (string, error) {
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "sshm_views.json"), nil
+}
+
+// LoadSavedViews returns the saved views in the order they were created.
+// A missing file means no views have been saved yet.
+func LoadSavedViews() ([]SavedView, error) {
+	path, err := GetSavedViewsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var views savedViewsData
+	if err := json.Unmarshal(data, &views); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return views.Views, nil
+}
+
+// SaveView stores a view, replacing any existing view with the same name
+func SaveView(view SavedView) error {
+	view.Name = strings.TrimSpace(view.Name)
+	view.Query = strings.TrimSpace(view.Query)
+	if view.Name == "" {
+		return fmt.Errorf("view name cannot be empty")
+	}
+	if view.Query == "" {
+		return fmt.Errorf("view query cannot be empty")
+	}
+
+	views, err := LoadSavedViews()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range views {
+		if strings.EqualFold(views[i].Name, view.Name) {
+			views[i] = view
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		views = append(views, view)
+	}
+
+	return writeSavedViews(views)
+}
+
+// DeleteSavedView removes the view with the given name
+func DeleteSavedView(name string) error {
+	views, err := LoadSavedViews()
+	if err != nil {
+		return err
+	}
+
+	kept := views[:0]
+	for _, v := range views {
+		if !strings.EqualFold(v.Name, name) {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == len(views) {
+		return fmt.Errorf("view '%s' not found", name)
+	}
+
+	return writeSavedViews(kept)
+}
+
+// writeSavedViews writes the saved views file
+func writeSavedViews(views []SavedView) error {
+	path, err := GetSavedViewsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(savedViewsData{Views: views}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0600)
+}
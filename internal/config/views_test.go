@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestSaveAndLoadViews(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	views, err := LoadSavedViews()
+	if err != nil {
+		t.Fatalf("LoadSavedViews() error = %v", err)
+	}
+	if len(views) != 0 {
+		t.Fatalf("Expected no views before saving, got %v", views)
+	}
+
+	if err := SaveView(SavedView{Name: "prod", Query: "tag:prod", Sort: "last_used"}); err != nil {
+		t.Fatalf("SaveView() error = %v", err)
+	}
+	if err := SaveView(SavedView{Name: "web", Query: "web"}); err != nil {
+		t.Fatalf("SaveView() error = %v", err)
+	}
+	// Saving under an existing name replaces the view in place
+	if err := SaveView(SavedView{Name: "Prod", Query: "prod db", Sort: "name"}); err != nil {
+		t.Fatalf("SaveView() error = %v", err)
+	}
+
+	views, err = LoadSavedViews()
+	if err != nil {
+		t.Fatalf("LoadSavedViews() error = %v", err)
+	}
+	expected := []SavedView{
+		{Name: "Prod", Query: "prod db", Sort: "name"},
+		{Name: "web", Query: "web"},
+	}
+	if len(views) != len(expected) {
+		t.Fatalf("Expected %d views, got %v", len(expected), views)
+	}
+	for i := range expected {
+		if views[i] != expected[i] {
+			t.Errorf("View %d = %+v, want %+v", i, views[i], expected[i])
+		}
+	}
+
+	if err := DeleteSavedView("prod"); err != nil {
+		t.Fatalf("DeleteSavedView() error = %v", err)
+	}
+	views, _ = LoadSavedViews()
+	if len(views) != 1 || views[0].Name != "web" {
+		t.Errorf("Expected only 'web' left, got %v", views)
+	}
+
+	if err := DeleteSavedView("missing"); err == nil {
+		t.Error("Expected an error deleting an unknown view")
+	}
+}
+
+func TestSaveViewValidation(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	if err := SaveView(SavedView{Name: "  ", Query: "web"}); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+	if err := SaveView(SavedView{Name: "empty", Query: ""}); err == nil {
+		t.Error("Expected an error for an empty query")
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// webURLsData is the on-disk format of the per-host web URLs
+type webURLsData struct {
+	Hosts map[string]string `json:"hosts"`
+}
+
+// GetWebURLsPath returns the path to the file of per-host web URLs
+func GetWebURLsPath() (string, error) {
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "sshm_web_urls.json"), nil
+}
+
+// loadWebURLs reads all per-host web URLs. A missing file means none is set.
+func loadWebURLs() (webURLsData, error) {
+	urls := webURLsData{Hosts: make(map[string]string)}
+
+	path, err := GetWebURLsPath()
+	if err != nil {
+		return urls, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return urls, nil
+		}
+		return urls, err
+	}
+
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return urls, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if urls.Hosts == nil {
+		urls.Hosts = make(map[string]string)
+	}
+	return urls, nil
+}
+
+// LoadHostWebURL returns the web URL set for a host, empty when none is
+func LoadHostWebURL(hostName string) (string, error) {
+	urls, err := loadWebURLs()
+	if err != nil {
+		return "", err
+	}
+	return urls.Hosts[hostName], nil
+}
+
+// NormalizeWebURL checks a web URL typed by the user, adding https:// when no
+// scheme is given
+func NormalizeWebURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid web URL %q", rawURL)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("web URL must use http or https, got %q", parsed.Scheme)
+	}
+	return parsed.String(), nil
+}
+
+// SetHostWebURL sets the web URL of a host; an empty URL removes it
+func SetHostWebURL(hostName, webURL string) error {
+	urls, err := loadWebURLs()
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(webURL) == "" {
+		delete(urls.Hosts, hostName)
+	} else {
+		normalized, err := NormalizeWebURL(webURL)
+		if err != nil {
+			return err
+		}
+		urls.Hosts[hostName] = normalized
+	}
+
+	path, err := GetWebURLsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(urls, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0600)
+}
+
+// DefaultWebURL returns the URL opened for a host without a web URL:
+// https:// on its HostName, or on its name when HostName is not set
+func DefaultWebURL(host SSHHost) string {
+	hostname := host.Hostname
+	if hostname == "" {
+		hostname = host.Name
+	}
+	if strings.Contains(hostname, ":") {
+		// IPv6 literals are bracketed in URLs
+		hostname = "[" + strings.Trim(hostname, "[]") + "]"
+	}
+	return (&url.URL{Scheme: "https", Host: hostname}).String()
+}
+
+// HostWebURL returns the web URL set for a host, or its default one
+func HostWebURL(host SSHHost) string {
+	if webURL, err := LoadHostWebURL(host.Name); err == nil && webURL != "" {
+		return webURL
+	}
+	return DefaultWebURL(host)
+}
+
+// OpenURLCommand returns the command opening a URL in the default browser on goos
+func OpenURLCommand(goos, webURL string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{webURL}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", webURL}
+	default:
+		return "xdg-open", []string{webURL}
+	}
+}
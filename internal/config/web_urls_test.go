@@ -0,0 +1,93 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaultWebURL(t *testing.T) {
+	tests := []struct {
+		host SSHHost
+		want string
+	}{
+		{SSHHost{Name: "web", Hostname: "web.example.com"}, "https://web.example.com"},
+		{SSHHost{Name: "nas.local"}, "https://nas.local"},
+		{SSHHost{Name: "router", Hostname: "192.168.1.1"}, "https://192.168.1.1"},
+		{SSHHost{Name: "v6", Hostname: "2001:db8::1"}, "https://[2001:db8::1]"},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultWebURL(tt.host); got != tt.want {
+			t.Errorf("DefaultWebURL(%+v) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeWebURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"grafana.example.com:3000", "https://grafana.example.com:3000", false},
+		{" http://10.0.0.5/admin ", "http://10.0.0.5/admin", false},
+		{"ftp://files.example.com", "", true},
+		{"https://", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeWebURL(tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeWebURL(%q) = %q, %v, want %q (error %v)", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHostWebURLStoredPerHost(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	web := SSHHost{Name: "web", Hostname: "web.example.com"}
+
+	if got := HostWebURL(web); got != "https://web.example.com" {
+		t.Errorf("Expected the default URL without a stored one, got %q", got)
+	}
+
+	if err := SetHostWebURL("web", "web.example.com:8443/ui"); err != nil {
+		t.Fatal(err)
+	}
+	if got := HostWebURL(web); got != "https://web.example.com:8443/ui" {
+		t.Errorf("HostWebURL() = %q", got)
+	}
+	if got := HostWebURL(SSHHost{Name: "db", Hostname: "10.0.0.5"}); got != "https://10.0.0.5" {
+		t.Errorf("Expected other hosts to keep their default, got %q", got)
+	}
+
+	if err := SetHostWebURL("web", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := LoadHostWebURL("web"); got != "" {
+		t.Errorf("Expected the URL to be removed, got %q", got)
+	}
+}
+
+func TestOpenURLCommand(t *testing.T) {
+	const webURL = "https://web.example.com"
+	tests := []struct {
+		goos     string
+		wantName string
+		wantArgs []string
+	}{
+		{"darwin", "open", []string{webURL}},
+		{"linux", "xdg-open", []string{webURL}},
+		{"freebsd", "xdg-open", []string{webURL}},
+		{"windows", "rundll32", []string{"url.dll,FileProtocolHandler", webURL}},
+	}
+
+	for _, tt := range tests {
+		name, args := OpenURLCommand(tt.goos, webURL)
+		if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("OpenURLCommand(%q) = %s %v, want %s %v", tt.goos, name, args, tt.wantName, tt.wantArgs)
+		}
+	}
+}
@@ -35,29 +35,50 @@ func (s PingStatus) String() string {
 	return "unknown"
 }
 
+// DefaultPingCacheTTL is how long a ping result is reused before the host is checked again
+const DefaultPingCacheTTL = config.DefaultPingCacheTTLSeconds * time.Second
+
 // HostPingResult represents the result of pinging a host
 type HostPingResult struct {
-	HostName string
-	Status   PingStatus
-	Error    error
-	Duration time.Duration
+	HostName  string
+	Status    PingStatus
+	Error     error
+	Duration  time.Duration
+	CheckedAt time.Time // When the check completed, zero while connecting
 }
 
 // PingManager manages SSH connectivity checks for multiple hosts
 type PingManager struct {
-	results map[string]*HostPingResult
-	mutex   sync.RWMutex
-	timeout time.Duration
+	results  map[string]*HostPingResult
+	mutex    sync.RWMutex
+	timeout  time.Duration
+	cacheTTL time.Duration
 }
 
-// NewPingManager creates a new ping manager with the specified timeout
+// NewPingManager creates a new ping manager with the specified timeout,
+// caching results for DefaultPingCacheTTL
 func NewPingManager(timeout time.Duration) *PingManager {
 	return &PingManager{
-		results: make(map[string]*HostPingResult),
-		timeout: timeout,
+		results:  make(map[string]*HostPingResult),
+		timeout:  timeout,
+		cacheTTL: DefaultPingCacheTTL,
 	}
 }
 
+// SetCacheTTL sets how long ping results are reused; zero or less disables the cache
+func (pm *PingManager) SetCacheTTL(ttl time.Duration) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.cacheTTL = ttl
+}
+
+// CacheTTL returns how long ping results are reused
+func (pm *PingManager) CacheTTL() time.Duration {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	return pm.cacheTTL
+}
+
 // GetStatus returns the current status for a host
 func (pm *PingManager) GetStatus(hostName string) PingStatus {
 	pm.mutex.RLock()
@@ -78,17 +99,46 @@ func (pm *PingManager) GetResult(hostName string) (*HostPingResult, bool) {
 	return result, exists
 }
 
-// updateStatus updates the status for a host
-func (pm *PingManager) updateStatus(hostName string, status PingStatus, err error, duration time.Duration) {
+// GetCached returns the result of the last completed check of a host while
+// it is younger than the cache TTL
+func (pm *PingManager) GetCached(hostName string) (*HostPingResult, bool) {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	result, exists := pm.results[hostName]
+	if !exists || result.CheckedAt.IsZero() || time.Since(result.CheckedAt) >= pm.cacheTTL {
+		return nil, false
+	}
+	return result, true
+}
+
+// StaleHosts returns the hosts without a cached result, which need to be pinged again
+func (pm *PingManager) StaleHosts(hosts []config.SSHHost) []config.SSHHost {
+	var stale []config.SSHHost
+	for _, host := range hosts {
+		if _, cached := pm.GetCached(host.Name); !cached {
+			stale = append(stale, host)
+		}
+	}
+	return stale
+}
+
+// updateStatus updates the status for a host and returns the stored result
+func (pm *PingManager) updateStatus(hostName string, status PingStatus, err error, duration time.Duration) *HostPingResult {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
-	pm.results[hostName] = &HostPingResult{
+	result := &HostPingResult{
 		HostName: hostName,
 		Status:   status,
 		Error:    err,
 		Duration: duration,
 	}
+	if status != StatusConnecting {
+		result.CheckedAt = time.Now()
+	}
+	pm.results[hostName] = result
+	return result
 }
 
 // PingHost performs an SSH connectivity check for a single host
@@ -117,14 +167,7 @@ func (pm *PingManager) PingHost(ctx context.Context, host config.SSHHost) *HostP
 	dialer := &net.Dialer{}
 	conn, err := dialer.DialContext(pingCtx, "tcp", net.JoinHostPort(hostname, port))
 	if err != nil {
-		duration := time.Since(start)
-		pm.updateStatus(host.Name, StatusOffline, err, duration)
-		return &HostPingResult{
-			HostName: host.Name,
-			Status:   StatusOffline,
-			Error:    err,
-			Duration: duration,
-		}
+		return pm.updateStatus(host.Name, StatusOffline, err, time.Since(start))
 	}
 	defer conn.Close()
 
@@ -150,35 +193,56 @@ func (pm *PingManager) PingHost(ctx context.Context, host config.SSHHost) *HostP
 		status = StatusOffline
 	}
 
-	pm.updateStatus(host.Name, status, err, duration)
-	return &HostPingResult{
-		HostName: host.Name,
-		Status:   status,
-		Error:    err,
-		Duration: duration,
-	}
+	return pm.updateStatus(host.Name, status, err, duration)
 }
 
+// DefaultPingConcurrency is how many hosts are pinged at once when no limit is configured
+const DefaultPingConcurrency = 20
+
 // PingAllHosts pings all hosts concurrently and returns a channel of results
 func (pm *PingManager) PingAllHosts(ctx context.Context, hosts []config.SSHHost) <-chan *HostPingResult {
+	return pm.PingHosts(ctx, hosts, DefaultPingConcurrency)
+}
+
+// PingHosts pings hosts with at most concurrency checks running at once
+// (DefaultPingConcurrency when not positive) and returns a channel receiving
+// each result as it completes. The channel is closed once every host has been
+// checked or ctx is cancelled.
+func (pm *PingManager) PingHosts(ctx context.Context, hosts []config.SSHHost, concurrency int) <-chan *HostPingResult {
+	if concurrency <= 0 {
+		concurrency = DefaultPingConcurrency
+	}
+	if concurrency > len(hosts) {
+		concurrency = len(hosts)
+	}
+
 	resultChan := make(chan *HostPingResult, len(hosts))
+	jobs := make(chan config.SSHHost)
 
 	var wg sync.WaitGroup
-
-	for _, host := range hosts {
+	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
-		go func(h config.SSHHost) {
+		go func() {
 			defer wg.Done()
-			result := pm.PingHost(ctx, h)
+			for host := range jobs {
+				resultChan <- pm.PingHost(ctx, host)
+			}
+		}()
+	}
+
+	// Feed the workers until every host is queued or ctx is cancelled
+	go func() {
+		defer close(jobs)
+		for _, host := range hosts {
 			select {
-			case resultChan <- result:
+			case jobs <- host:
 			case <-ctx.Done():
 				return
 			}
-		}(host)
-	}
+		}
+	}()
 
-	// Close the channel when all goroutines are done
+	// Close the channel when all workers are done
 	go func() {
 		wg.Wait()
 		close(resultChan)
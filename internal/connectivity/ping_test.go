@@ -2,6 +2,9 @@ package connectivity
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -141,4 +144,116 @@ func TestPingHost_Basic(t *testing.T) {
 	if status == StatusUnknown {
 		t.Error("Expected status to be set after ping attempt")
 	}
-}
\ No newline at end of file
+}
+func TestPingHosts_BoundedConcurrency(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// Hold every connection open briefly, tracking how many are open at once
+	var active, maxActive int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				n := atomic.AddInt32(&active, 1)
+				for {
+					max := atomic.LoadInt32(&maxActive)
+					if n <= max || atomic.CompareAndSwapInt32(&maxActive, max, n) {
+						break
+					}
+				}
+				time.Sleep(50 * time.Millisecond)
+				atomic.AddInt32(&active, -1)
+				conn.Close()
+			}()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	var hosts []config.SSHHost
+	for i := 0; i < 6; i++ {
+		hosts = append(hosts, config.SSHHost{Name: fmt.Sprintf("host%d", i), Hostname: "127.0.0.1", Port: port})
+	}
+
+	pm := NewPingManager(2 * time.Second)
+	seen := make(map[string]bool)
+	for result := range pm.PingHosts(context.Background(), hosts, 2) {
+		seen[result.HostName] = true
+	}
+
+	if len(seen) != len(hosts) {
+		t.Errorf("Expected a result for each of the %d hosts, got %d", len(hosts), len(seen))
+	}
+	if max := atomic.LoadInt32(&maxActive); max > 2 {
+		t.Errorf("Expected at most 2 hosts checked at once, got %d", max)
+	}
+}
+
+func TestPingHosts_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pm := NewPingManager(time.Second)
+	hosts := []config.SSHHost{{Name: "a", Hostname: "127.0.0.1", Port: "1"}, {Name: "b", Hostname: "127.0.0.1", Port: "1"}}
+
+	done := make(chan struct{})
+	go func() {
+		for range pm.PingHosts(ctx, hosts, 1) {
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the results channel to be closed after cancellation")
+	}
+}
+
+func TestPingManager_GetCached(t *testing.T) {
+	pm := NewPingManager(time.Second)
+
+	if _, ok := pm.GetCached("web"); ok {
+		t.Error("Expected no cached result for a host never pinged")
+	}
+
+	pm.updateStatus("web", StatusConnecting, nil, 0)
+	if _, ok := pm.GetCached("web"); ok {
+		t.Error("Expected a check in progress not to be cached")
+	}
+
+	pm.updateStatus("web", StatusOnline, nil, time.Millisecond)
+	result, ok := pm.GetCached("web")
+	if !ok || result.Status != StatusOnline || result.CheckedAt.IsZero() {
+		t.Errorf("Expected the fresh online result, got %+v, %v", result, ok)
+	}
+
+	// Results older than the TTL are stale
+	pm.results["web"].CheckedAt = time.Now().Add(-DefaultPingCacheTTL)
+	if _, ok := pm.GetCached("web"); ok {
+		t.Error("Expected a result older than the TTL to be stale")
+	}
+
+	pm.updateStatus("web", StatusOnline, nil, time.Millisecond)
+	pm.SetCacheTTL(0)
+	if _, ok := pm.GetCached("web"); ok {
+		t.Error("Expected nothing to be cached with the cache disabled")
+	}
+}
+
+func TestPingManager_StaleHosts(t *testing.T) {
+	pm := NewPingManager(time.Second)
+	pm.updateStatus("fresh", StatusOffline, nil, time.Millisecond)
+
+	hosts := []config.SSHHost{{Name: "fresh"}, {Name: "new"}}
+	stale := pm.StaleHosts(hosts)
+	if len(stale) != 1 || stale[0].Name != "new" {
+		t.Errorf("Expected only the host without a cached result, got %v", stale)
+	}
+}
@@ -0,0 +1,52 @@
+package connectivity
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var (
+	// ErrPortInUse is returned by CheckLocalPort when another program listens on the port
+	ErrPortInUse = errors.New("already in use")
+	// ErrPortDenied is returned by CheckLocalPort when listening on the port needs privileges
+	ErrPortDenied = errors.New("permission denied (ports below 1024 need root)")
+)
+
+// CheckLocalPort checks that a local or dynamic forward can listen on port
+// at bindAddress, given as in the forward spec: empty for ssh's default of
+// the loopback interface, * for every interface, or an address, bracketed or
+// not for IPv6. A busy port is reported with ErrPortInUse and a privileged
+// one with ErrPortDenied.
+func CheckLocalPort(bindAddress string, port int) error {
+	host := strings.TrimSuffix(strings.TrimPrefix(bindAddress, "["), "]")
+	switch host {
+	case "", "localhost":
+		host = "127.0.0.1"
+	case "*":
+		host = ""
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	listener, err := net.Listen("tcp", addr)
+	if err == nil {
+		listener.Close()
+		return nil
+	}
+
+	// Errors name the bind address only when it was given
+	name := strconv.Itoa(port)
+	if bindAddress != "" {
+		name = net.JoinHostPort(strings.TrimSuffix(strings.TrimPrefix(bindAddress, "["), "]"), name)
+	}
+	switch {
+	case errors.Is(err, syscall.EADDRINUSE):
+		return fmt.Errorf("local port %s %w", name, ErrPortInUse)
+	case errors.Is(err, syscall.EACCES):
+		return fmt.Errorf("local port %s: %w", name, ErrPortDenied)
+	}
+	return fmt.Errorf("cannot listen on local port %s: %w", name, err)
+}
@@ -0,0 +1,40 @@
+package connectivity
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestCheckLocalPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("Cannot listen on the loopback interface")
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	for _, bind := range []string{"", "localhost", "127.0.0.1"} {
+		if err := CheckLocalPort(bind, port); !errors.Is(err, ErrPortInUse) {
+			t.Errorf("CheckLocalPort(%q, %d) = %v, want ErrPortInUse", bind, port, err)
+		}
+	}
+	// Another address of the loopback network is a different socket
+	if err := CheckLocalPort("127.0.0.2", port); err != nil {
+		t.Errorf("CheckLocalPort(127.0.0.2, %d) = %v, want nil", port, err)
+	}
+
+	listener.Close()
+	if err := CheckLocalPort("", port); err != nil {
+		t.Errorf("Expected port %d to be free once closed, got %v", port, err)
+	}
+}
+
+func TestCheckLocalPortPrivileged(t *testing.T) {
+	if os.Geteuid() <= 0 {
+		t.Skip("Privileged ports are open to root")
+	}
+	if err := CheckLocalPort("", 1); !errors.Is(err, ErrPortDenied) {
+		t.Errorf("CheckLocalPort(1) = %v, want ErrPortDenied", err)
+	}
+}
@@ -0,0 +1,27 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// withFileLock runs fn while holding an exclusive lock on path+".lock", so that
+// several sshm instances never read-modify-write the same file at once
+func withFileLock(path string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return err
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
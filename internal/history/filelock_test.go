@@ -0,0 +1,25 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "data.json")
+
+	called := false
+	if err := withFileLock(path, func() error {
+		called = true
+		return nil
+	}); err != nil {
+		t.Fatalf("withFileLock() error = %v", err)
+	}
+	if !called {
+		t.Error("Expected fn to be called")
+	}
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Errorf("Expected lock file to exist, got %v", err)
+	}
+}
@@ -2,17 +2,24 @@ package history
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
 )
 
 // ConnectionHistory represents the history of SSH connections
 type ConnectionHistory struct {
-	Connections map[string]ConnectionInfo `json:"connections"`
+	Connections  map[string]ConnectionInfo `json:"connections"`
+	LastTransfer *TransferAttempt          `json:"last_transfer,omitempty"`
 }
 
 // PortForwardConfig stores port forwarding configuration
@@ -30,6 +37,7 @@ type TransferHistoryEntry struct {
 	LocalPath  string    `json:"local_path"`
 	RemotePath string    `json:"remote_path"`
 	Timestamp  time.Time `json:"timestamp"`
+	Count      int       `json:"count,omitempty"` // Times this transfer was run, 0 for older records
 }
 
 // ConnectionInfo stores information about a specific connection
@@ -39,12 +47,29 @@ type ConnectionInfo struct {
 	ConnectCount    int                    `json:"connect_count"`
 	PortForwarding  *PortForwardConfig     `json:"port_forwarding,omitempty"`
 	TransferHistory []TransferHistoryEntry `json:"transfer_history,omitempty"`
+	TransferBackend string                 `json:"transfer_backend,omitempty"` // Last backend used by quick transfers
+	QuickTransfer   *QuickTransferChoice   `json:"quick_transfer,omitempty"`   // Last choices made in quick transfers
+	RemoteBookmarks []string               `json:"remote_bookmarks,omitempty"` // Remote directories bookmarked in the browser, oldest first
+}
+
+// QuickTransferChoice stores the direction and type last picked in the quick
+// transfer of a host, preselected the next time
+type QuickTransferChoice struct {
+	Direction string `json:"direction"`        // "upload" or "download"
+	Folder    bool   `json:"folder,omitempty"` // A folder rather than a file was transferred
 }
 
 // HistoryManager manages the connection history
 type HistoryManager struct {
 	historyPath string
 	history     *ConnectionHistory
+	mu          sync.Mutex     // Serializes updates within this process
+	recovered   bool           // History was restored from the backup file
+	policy      RotationPolicy // Limits past which updates archive hosts
+
+	// Recording turned off in the app config or with SSHM_NO_HISTORY
+	skipConnections bool
+	skipTransfers   bool
 }
 
 // NewHistoryManager creates a new history manager
@@ -71,6 +96,17 @@ func NewHistoryManager() (*HistoryManager, error) {
 		historyPath: historyPath,
 		history:     &ConnectionHistory{Connections: make(map[string]ConnectionInfo)},
 	}
+	appConfig, err := config.LoadAppConfig()
+	if err != nil {
+		appConfig = nil // Defaults, still honoring SSHM_NO_HISTORY
+	}
+	hm.skipConnections = !appConfig.ConnectionHistoryEnabled()
+	hm.skipTransfers = !appConfig.TransferHistoryEnabled()
+	historyConfig := config.GetDefaultHistoryConfig()
+	if appConfig != nil {
+		historyConfig = appConfig.History
+	}
+	hm.policy = RotationPolicyFromConfig(historyConfig)
 
 	// Load existing history if it exists
 	err = hm.loadHistory()
@@ -124,14 +160,60 @@ func migrateOldHistoryFile(newHistoryPath string) error {
 	return nil
 }
 
-// loadHistory loads the connection history from the JSON file
+// backupPath returns the path of the last known good copy of the history file
+func (hm *HistoryManager) backupPath() string {
+	return hm.historyPath + ".bak"
+}
+
+// loadHistory loads the connection history from the JSON file, falling back
+// to the backup when the file is corrupted (e.g. sshm was killed mid-write)
 func (hm *HistoryManager) loadHistory() error {
 	data, err := os.ReadFile(hm.historyPath)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(data, hm.history)
+	parseErr := json.Unmarshal(data, hm.history)
+	if parseErr == nil {
+		return nil
+	}
+
+	backup, err := os.ReadFile(hm.backupPath())
+	if err != nil {
+		return parseErr
+	}
+	hm.history = &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}
+	if err := json.Unmarshal(backup, hm.history); err != nil {
+		return parseErr
+	}
+
+	hm.recovered = true
+	return nil
+}
+
+// RecoveryWarning returns a message when the history file was corrupted and
+// restored from its backup, or "" if it loaded normally
+func (hm *HistoryManager) RecoveryWarning() string {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	if !hm.recovered {
+		return ""
+	}
+	return fmt.Sprintf("connection history was corrupted and has been restored from %s", filepath.Base(hm.backupPath()))
+}
+
+// reloadHistory replaces the in-memory history with the current file contents,
+// picking up records written by other sshm instances
+func (hm *HistoryManager) reloadHistory() error {
+	hm.history = &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}
+	err := hm.loadHistory()
+	if hm.history.Connections == nil {
+		hm.history.Connections = make(map[string]ConnectionInfo)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 // saveHistory saves the connection history to the JSON file
@@ -147,33 +229,88 @@ func (hm *HistoryManager) saveHistory() error {
 		return err
 	}
 
-	return os.WriteFile(hm.historyPath, data, 0600)
+	// Keep the previous version as a backup, as long as it is still valid
+	if current, err := os.ReadFile(hm.historyPath); err == nil && json.Valid(current) {
+		if err := config.WriteFileAtomic(hm.backupPath(), current, 0600); err != nil {
+			return err
+		}
+	}
+
+	return config.WriteFileAtomic(hm.historyPath, data, 0600)
+}
+
+// update applies mutate to the latest history on disk, archives the hosts it
+// leaves outside the rotation policy and saves it, holding the history file
+// lock so concurrent instances don't lose each other's records
+func (hm *HistoryManager) update(mutate func(h *ConnectionHistory)) error {
+	_, err := hm.updateRotating(mutate, hm.policy)
+	return err
+}
+
+// updateRotating is update archiving with policy. It returns the number of
+// hosts archived; when the history can't be saved, the archive is put back
+// as it was so no host ends up in both files.
+func (hm *HistoryManager) updateRotating(mutate func(h *ConnectionHistory), policy RotationPolicy) (int, error) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	archived := 0
+	err := withFileLock(hm.historyPath, func() error {
+		if err := hm.reloadHistory(); err != nil {
+			return err
+		}
+		mutate(hm.history)
+
+		n, undo, err := hm.rotate(hm.history, policy)
+		if err != nil {
+			return err
+		}
+		if err := hm.saveHistory(); err != nil {
+			undo()
+			return err
+		}
+		archived = n
+		return nil
+	})
+	return archived, err
+}
+
+// RecordsTransfers reports whether transfers are recorded, so that views can
+// leave out transfer history that will stay empty
+func (hm *HistoryManager) RecordsTransfers() bool {
+	return !hm.skipTransfers
 }
 
 // RecordConnection records a new connection for the specified host
 func (hm *HistoryManager) RecordConnection(hostName string) error {
+	if hm.skipConnections {
+		return nil
+	}
 	now := time.Now()
 
-	if conn, exists := hm.history.Connections[hostName]; exists {
-		// Update existing connection
-		conn.LastConnect = now
-		conn.ConnectCount++
-		hm.history.Connections[hostName] = conn
-	} else {
-		// Create new connection record
-		hm.history.Connections[hostName] = ConnectionInfo{
-			HostName:     hostName,
-			LastConnect:  now,
-			ConnectCount: 1,
+	return hm.update(func(h *ConnectionHistory) {
+		if conn, exists := h.Connections[hostName]; exists {
+			// Update existing connection
+			conn.LastConnect = now
+			conn.ConnectCount++
+			h.Connections[hostName] = conn
+		} else {
+			// Create new connection record
+			h.Connections[hostName] = ConnectionInfo{
+				HostName:     hostName,
+				LastConnect:  now,
+				ConnectCount: 1,
+			}
 		}
-	}
-
-	return hm.saveHistory()
+	})
 }
 
 // GetLastConnectionTime returns the last connection time for a host
 func (hm *HistoryManager) GetLastConnectionTime(hostName string) (time.Time, bool) {
-	if conn, exists := hm.history.Connections[hostName]; exists {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	// Hosts only holding bookmarks were never connected to
+	if conn, exists := hm.history.Connections[hostName]; exists && !conn.LastConnect.IsZero() {
 		return conn.LastConnect, true
 	}
 	return time.Time{}, false
@@ -181,6 +318,8 @@ func (hm *HistoryManager) GetLastConnectionTime(hostName string) (time.Time, boo
 
 // GetConnectionCount returns the total number of connections for a host
 func (hm *HistoryManager) GetConnectionCount(hostName string) int {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
 	if conn, exists := hm.history.Connections[hostName]; exists {
 		return conn.ConnectCount
 	}
@@ -254,17 +393,19 @@ func (hm *HistoryManager) CleanupOldEntries(currentHosts []config.SSHHost) error
 	}
 
 	// Remove entries for hosts that no longer exist
-	for hostName := range hm.history.Connections {
-		if !currentHostNames[hostName] {
-			delete(hm.history.Connections, hostName)
+	return hm.update(func(h *ConnectionHistory) {
+		for hostName := range h.Connections {
+			if !currentHostNames[hostName] {
+				delete(h.Connections, hostName)
+			}
 		}
-	}
-
-	return hm.saveHistory()
+	})
 }
 
 // GetAllConnectionsInfo returns all connection information sorted by last connection time
 func (hm *HistoryManager) GetAllConnectionsInfo() []ConnectionInfo {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
 	var connections []ConnectionInfo
 	for _, conn := range hm.history.Connections {
 		connections = append(connections, conn)
@@ -279,6 +420,10 @@ func (hm *HistoryManager) GetAllConnectionsInfo() []ConnectionInfo {
 
 // RecordPortForwarding saves port forwarding configuration for a host
 func (hm *HistoryManager) RecordPortForwarding(hostName, forwardType, localPort, remoteHost, remotePort, bindAddress string) error {
+	// A forward is recorded as a connection of the host
+	if hm.skipConnections {
+		return nil
+	}
 	now := time.Now()
 
 	portForwardConfig := &PortForwardConfig{
@@ -289,35 +434,44 @@ func (hm *HistoryManager) RecordPortForwarding(hostName, forwardType, localPort,
 		BindAddress: bindAddress,
 	}
 
-	if conn, exists := hm.history.Connections[hostName]; exists {
-		// Update existing connection
-		conn.LastConnect = now
-		conn.ConnectCount++
-		conn.PortForwarding = portForwardConfig
-		hm.history.Connections[hostName] = conn
-	} else {
-		// Create new connection record
-		hm.history.Connections[hostName] = ConnectionInfo{
-			HostName:       hostName,
-			LastConnect:    now,
-			ConnectCount:   1,
-			PortForwarding: portForwardConfig,
+	return hm.update(func(h *ConnectionHistory) {
+		if conn, exists := h.Connections[hostName]; exists {
+			// Update existing connection
+			conn.LastConnect = now
+			conn.ConnectCount++
+			conn.PortForwarding = portForwardConfig
+			h.Connections[hostName] = conn
+		} else {
+			// Create new connection record
+			h.Connections[hostName] = ConnectionInfo{
+				HostName:       hostName,
+				LastConnect:    now,
+				ConnectCount:   1,
+				PortForwarding: portForwardConfig,
+			}
 		}
-	}
-
-	return hm.saveHistory()
+	})
 }
 
 // GetPortForwardingConfig retrieves the last used port forwarding configuration for a host
 func (hm *HistoryManager) GetPortForwardingConfig(hostName string) *PortForwardConfig {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
 	if conn, exists := hm.history.Connections[hostName]; exists {
 		return conn.PortForwarding
 	}
 	return nil
 }
 
-// RecordTransfer saves a file transfer record for a host
+// maxTransferHistory is how many transfers are kept per host
+const maxTransferHistory = 10
+
+// RecordTransfer saves a file transfer record for a host. Running the same
+// transfer again moves it to the front and increments its count.
 func (hm *HistoryManager) RecordTransfer(hostName, direction, localPath, remotePath string) error {
+	if hm.skipTransfers {
+		return nil
+	}
 	now := time.Now()
 
 	entry := TransferHistoryEntry{
@@ -325,42 +479,225 @@ func (hm *HistoryManager) RecordTransfer(hostName, direction, localPath, remoteP
 		LocalPath:  localPath,
 		RemotePath: remotePath,
 		Timestamp:  now,
+		Count:      1,
 	}
 
-	if conn, exists := hm.history.Connections[hostName]; exists {
-		// Add to existing history, keep last 10 entries
-		conn.TransferHistory = append([]TransferHistoryEntry{entry}, conn.TransferHistory...)
-		if len(conn.TransferHistory) > 10 {
-			conn.TransferHistory = conn.TransferHistory[:10]
+	return hm.update(func(h *ConnectionHistory) {
+		if conn, exists := h.Connections[hostName]; exists {
+			// Fold earlier runs of the same transfer into the new entry
+			var others []TransferHistoryEntry
+			for _, previous := range conn.TransferHistory {
+				if previous.sameTransfer(entry) {
+					entry.Count += previous.Occurrences()
+				} else {
+					others = append(others, previous)
+				}
+			}
+
+			// Add to existing history, keep the last entries
+			conn.TransferHistory = append([]TransferHistoryEntry{entry}, others...)
+			if len(conn.TransferHistory) > maxTransferHistory {
+				conn.TransferHistory = conn.TransferHistory[:maxTransferHistory]
+			}
+			conn.LastConnect = now
+			h.Connections[hostName] = conn
+		} else {
+			// Create new connection record
+			h.Connections[hostName] = ConnectionInfo{
+				HostName:        hostName,
+				LastConnect:     now,
+				ConnectCount:    0,
+				TransferHistory: []TransferHistoryEntry{entry},
+			}
 		}
-		conn.LastConnect = now
-		hm.history.Connections[hostName] = conn
-	} else {
-		// Create new connection record
-		hm.history.Connections[hostName] = ConnectionInfo{
-			HostName:        hostName,
-			LastConnect:     now,
-			ConnectCount:    0,
-			TransferHistory: []TransferHistoryEntry{entry},
+	})
+}
+
+// RecordTransferBackend remembers the backend last used to transfer files with a host
+func (hm *HistoryManager) RecordTransferBackend(hostName string, backend transfer.TransferBackend) error {
+	if hm.skipTransfers {
+		return nil
+	}
+	return hm.update(func(h *ConnectionHistory) {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			// Dated like a transfer record so rotation keeps it
+			conn = ConnectionInfo{HostName: hostName, LastConnect: time.Now()}
+		}
+		conn.TransferBackend = backend.String()
+		h.Connections[hostName] = conn
+	})
+}
+
+// GetTransferBackend returns the backend last used with a host, scp if none was recorded
+func (hm *HistoryManager) GetTransferBackend(hostName string) transfer.TransferBackend {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	backend, _ := transfer.ParseBackend(hm.history.Connections[hostName].TransferBackend)
+	return backend
+}
+
+// RecordQuickTransferChoice remembers the direction and type last picked in
+// the quick transfer of a host
+func (hm *HistoryManager) RecordQuickTransferChoice(hostName string, choice QuickTransferChoice) error {
+	if hm.skipTransfers {
+		return nil
+	}
+	return hm.update(func(h *ConnectionHistory) {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			// Dated like a transfer record so rotation keeps it
+			conn = ConnectionInfo{HostName: hostName, LastConnect: time.Now()}
 		}
+		conn.QuickTransfer = &choice
+		h.Connections[hostName] = conn
+	})
+}
+
+// GetQuickTransferChoice returns the choices last made in the quick transfer
+// of a host, false if none was recorded
+func (hm *HistoryManager) GetQuickTransferChoice(hostName string) (QuickTransferChoice, bool) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	choice := hm.history.Connections[hostName].QuickTransfer
+	if choice == nil {
+		return QuickTransferChoice{}, false
 	}
+	return *choice, true
+}
 
-	return hm.saveHistory()
+// RecordRemoteBookmark bookmarks a remote directory of a host, after the
+// directories bookmarked before it. Bookmarking a directory twice keeps one.
+func (hm *HistoryManager) RecordRemoteBookmark(hostName, remotePath string) error {
+	remotePath = cleanBookmarkPath(remotePath)
+	if remotePath == "" || hm.skipTransfers {
+		return nil
+	}
+
+	return hm.update(func(h *ConnectionHistory) {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			// Not a connection: the host stays undated, rotation keeps it for its bookmarks
+			conn = ConnectionInfo{HostName: hostName}
+		}
+		for _, p := range conn.RemoteBookmarks {
+			if p == remotePath {
+				return
+			}
+		}
+		conn.RemoteBookmarks = append(conn.RemoteBookmarks, remotePath)
+		h.Connections[hostName] = conn
+	})
 }
 
-// GetTransferHistory retrieves the transfer history for a host
+// RemoveRemoteBookmark removes a remote directory from the bookmarks of a host
+func (hm *HistoryManager) RemoveRemoteBookmark(hostName, remotePath string) error {
+	remotePath = cleanBookmarkPath(remotePath)
+
+	return hm.update(func(h *ConnectionHistory) {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			return
+		}
+		kept := conn.RemoteBookmarks[:0]
+		for _, p := range conn.RemoteBookmarks {
+			if p != remotePath {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			kept = nil
+		}
+		conn.RemoteBookmarks = kept
+		h.Connections[hostName] = conn
+	})
+}
+
+// GetRemoteBookmarks returns a copy of the remote directories bookmarked on
+// a host, in the order they were bookmarked
+func (hm *HistoryManager) GetRemoteBookmarks(hostName string) []string {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	return slices.Clone(hm.history.Connections[hostName].RemoteBookmarks)
+}
+
+// cleanBookmarkPath cleans a remote directory for storage; empty means "don't record"
+func cleanBookmarkPath(p string) string {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return ""
+	}
+	return path.Clean(p)
+}
+
+// GetTransferHistory retrieves a copy of the transfer history for a host
 func (hm *HistoryManager) GetTransferHistory(hostName string) []TransferHistoryEntry {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
 	if conn, exists := hm.history.Connections[hostName]; exists {
-		return conn.TransferHistory
+		return slices.Clone(conn.TransferHistory)
 	}
 	return nil
 }
 
+// GetTransferHistoryByFrequency retrieves the transfer history for a host, most
+// repeated transfers first
+func (hm *HistoryManager) GetTransferHistoryByFrequency(hostName string) []TransferHistoryEntry {
+	return SortTransfersByFrequency(hm.GetTransferHistory(hostName))
+}
+
+// Occurrences returns how many times the transfer was run
+func (e TransferHistoryEntry) Occurrences() int {
+	if e.Count < 1 {
+		return 1
+	}
+	return e.Count
+}
+
+// sameTransfer reports whether two entries have the same direction and paths
+func (e TransferHistoryEntry) sameTransfer(other TransferHistoryEntry) bool {
+	return e.Direction == other.Direction && e.LocalPath == other.LocalPath && e.RemotePath == other.RemotePath
+}
+
+// SortTransfersByFrequency merges identical transfers, adding up their counts,
+// and orders them by count with the most recent first among equal counts
+func SortTransfersByFrequency(entries []TransferHistoryEntry) []TransferHistoryEntry {
+	var merged []TransferHistoryEntry
+	for _, entry := range entries {
+		found := false
+		for i := range merged {
+			if merged[i].sameTransfer(entry) {
+				merged[i].Count = merged[i].Occurrences() + entry.Occurrences()
+				if entry.Timestamp.After(merged[i].Timestamp) {
+					merged[i].Timestamp = entry.Timestamp
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			entry.Count = entry.Occurrences()
+			merged = append(merged, entry)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Count != merged[j].Count {
+			return merged[i].Count > merged[j].Count
+		}
+		return merged[i].Timestamp.After(merged[j].Timestamp)
+	})
+	return merged
+}
+
 // GetLastTransfer retrieves the most recent transfer for a host
 func (hm *HistoryManager) GetLastTransfer(hostName string) *TransferHistoryEntry {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
 	if conn, exists := hm.history.Connections[hostName]; exists {
 		if len(conn.TransferHistory) > 0 {
-			return &conn.TransferHistory[0]
+			last := conn.TransferHistory[0]
+			return &last
 		}
 	}
 	return nil
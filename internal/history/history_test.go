@@ -1,10 +1,16 @@
 package history
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
 )
 
 // createTestHistoryManager creates a history manager with a temporary file for testing
@@ -162,3 +168,368 @@ func TestMigrateOldHistoryFile_NewFileExists(t *testing.T) {
 		t.Error("New file was modified when it shouldn't have been")
 	}
 }
+
+func TestHistoryManager_ConcurrentRecordConnection(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	// Two managers on the same file simulate two running sshm instances
+	other := &HistoryManager{
+		historyPath: hm.historyPath,
+		history:     &ConnectionHistory{Connections: make(map[string]ConnectionInfo)},
+	}
+
+	const perManager = 25
+	var wg sync.WaitGroup
+	for _, m := range []*HistoryManager{hm, other} {
+		for i := 0; i < perManager; i++ {
+			wg.Add(1)
+			go func(m *HistoryManager) {
+				defer wg.Done()
+				if err := m.RecordConnection("shared-host"); err != nil {
+					t.Errorf("RecordConnection() error = %v", err)
+				}
+			}(m)
+		}
+	}
+	wg.Wait()
+
+	reloaded := &HistoryManager{
+		historyPath: hm.historyPath,
+		history:     &ConnectionHistory{Connections: make(map[string]ConnectionInfo)},
+	}
+	if err := reloaded.loadHistory(); err != nil {
+		t.Fatalf("loadHistory() error = %v", err)
+	}
+	if got := reloaded.GetConnectionCount("shared-host"); got != 2*perManager {
+		t.Errorf("Expected %d connections after concurrent writes, got %d", 2*perManager, got)
+	}
+}
+
+func TestHistoryManager_ConcurrentInstancesKeepOtherHosts(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	other := &HistoryManager{
+		historyPath: hm.historyPath,
+		history:     &ConnectionHistory{Connections: make(map[string]ConnectionInfo)},
+	}
+
+	// Each instance records a host the other has never loaded
+	for i := 0; i < 5; i++ {
+		if err := hm.RecordConnection(fmt.Sprintf("host-a%d", i)); err != nil {
+			t.Fatalf("RecordConnection() error = %v", err)
+		}
+		if err := other.RecordTransfer(fmt.Sprintf("host-b%d", i), "upload", "/tmp/f", "/srv/f"); err != nil {
+			t.Fatalf("RecordTransfer() error = %v", err)
+		}
+	}
+
+	if got := len(other.GetAllConnectionsInfo()); got != 10 {
+		t.Errorf("Expected 10 hosts in history, got %d", got)
+	}
+	if hm.GetConnectionCount("host-a0") != 1 || other.GetConnectionCount("host-a0") != 1 {
+		t.Error("Expected host-a0 to survive writes from the other instance")
+	}
+}
+
+func TestHistoryManager_SaveKeepsBackup(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	if err := hm.RecordConnection("host1"); err != nil {
+		t.Fatalf("RecordConnection() error = %v", err)
+	}
+	// First save has no previous version to back up
+	if _, err := os.Stat(hm.backupPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup after first save, got %v", err)
+	}
+
+	if err := hm.RecordConnection("host2"); err != nil {
+		t.Fatalf("RecordConnection() error = %v", err)
+	}
+
+	backup := &HistoryManager{
+		historyPath: hm.backupPath(),
+		history:     &ConnectionHistory{Connections: make(map[string]ConnectionInfo)},
+	}
+	if err := backup.loadHistory(); err != nil {
+		t.Fatalf("Failed to load backup: %v", err)
+	}
+	if backup.GetConnectionCount("host1") != 1 || backup.GetConnectionCount("host2") != 0 {
+		t.Error("Expected backup to hold the previous version of the history")
+	}
+
+	// No temporary files are left next to the history
+	matches, _ := filepath.Glob(hm.historyPath + ".tmp-*")
+	if len(matches) != 0 {
+		t.Errorf("Expected no temporary files, found %v", matches)
+	}
+}
+
+func TestHistoryManager_RecoverFromCorruptFile(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	_ = hm.RecordConnection("host1")
+	_ = hm.RecordConnection("host1")
+
+	// Simulate a write interrupted halfway through
+	if err := os.WriteFile(hm.historyPath, []byte(`{"connections":{"host1":{"host_na`), 0600); err != nil {
+		t.Fatalf("Failed to corrupt history: %v", err)
+	}
+
+	reloaded := &HistoryManager{
+		historyPath: hm.historyPath,
+		history:     &ConnectionHistory{Connections: make(map[string]ConnectionInfo)},
+	}
+	if err := reloaded.loadHistory(); err != nil {
+		t.Fatalf("Expected recovery from backup, got error %v", err)
+	}
+	if got := reloaded.GetConnectionCount("host1"); got != 1 {
+		t.Errorf("Expected backup count 1, got %d", got)
+	}
+	if reloaded.RecoveryWarning() == "" {
+		t.Error("Expected a recovery warning")
+	}
+
+	// The next save replaces the corrupt file but not the good backup
+	if err := reloaded.RecordConnection("host1"); err != nil {
+		t.Fatalf("RecordConnection() error = %v", err)
+	}
+	data, _ := os.ReadFile(reloaded.backupPath())
+	if !strings.Contains(string(data), "host1") {
+		t.Error("Expected the valid backup to be kept over the corrupt file")
+	}
+	if got := reloaded.GetConnectionCount("host1"); got != 2 {
+		t.Errorf("Expected count 2 after recovery, got %d", got)
+	}
+}
+
+func TestHistoryManager_CorruptFileWithoutBackup(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	if err := os.WriteFile(hm.historyPath, []byte("not json"), 0600); err != nil {
+		t.Fatalf("Failed to write history: %v", err)
+	}
+
+	if err := hm.loadHistory(); err == nil {
+		t.Error("Expected an error for a corrupt file with no backup")
+	}
+	if hm.RecoveryWarning() != "" {
+		t.Error("Expected no recovery warning without a backup")
+	}
+}
+
+func TestRecordTransferCountsRepeats(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	_ = hm.RecordTransfer("web", "upload", "/tmp/app.tar", "/srv/")
+	_ = hm.RecordTransfer("web", "download", "/tmp/", "/var/log/app.log")
+	_ = hm.RecordTransfer("web", "upload", "/tmp/app.tar", "/srv/")
+
+	entries := hm.GetTransferHistory("web")
+	if len(entries) != 2 {
+		t.Fatalf("Expected repeated transfers to be merged, got %d entries", len(entries))
+	}
+	if entries[0].LocalPath != "/tmp/app.tar" || entries[0].Count != 2 {
+		t.Errorf("Expected the repeated upload first with a count of 2, got %+v", entries[0])
+	}
+	if entries[1].Count != 1 {
+		t.Errorf("Expected the download once, got %+v", entries[1])
+	}
+}
+
+func TestSortTransfersByFrequency(t *testing.T) {
+	now := time.Now()
+	entry := func(direction, local string, age time.Duration, count int) TransferHistoryEntry {
+		return TransferHistoryEntry{Direction: direction, LocalPath: local, RemotePath: "/srv/", Timestamp: now.Add(-age), Count: count}
+	}
+
+	entries := []TransferHistoryEntry{
+		entry("upload", "/tmp/recent", time.Minute, 1),
+		entry("upload", "/tmp/common", time.Hour, 3),
+		entry("download", "/tmp/common", 2*time.Hour, 1),
+		// Older history files kept repeats as separate entries without a count
+		entry("upload", "/tmp/legacy", 3*time.Hour, 0),
+		entry("upload", "/tmp/legacy", 4*time.Hour, 0),
+		entry("upload", "/tmp/once", 5*time.Hour, 0),
+	}
+
+	sorted := SortTransfersByFrequency(entries)
+
+	expected := []struct {
+		direction string
+		local     string
+		count     int
+	}{
+		{"upload", "/tmp/common", 3},
+		{"upload", "/tmp/legacy", 2},
+		{"upload", "/tmp/recent", 1},
+		{"download", "/tmp/common", 1},
+		{"upload", "/tmp/once", 1},
+	}
+	if len(sorted) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d: %+v", len(expected), len(sorted), sorted)
+	}
+	for i, want := range expected {
+		got := sorted[i]
+		if got.Direction != want.direction || got.LocalPath != want.local || got.Count != want.count {
+			t.Errorf("Entry %d = %s %s x%d, want %s %s x%d", i, got.Direction, got.LocalPath, got.Count, want.direction, want.local, want.count)
+		}
+	}
+	if !sorted[1].Timestamp.Equal(now.Add(-3 * time.Hour)) {
+		t.Errorf("Expected merged entries to keep the latest timestamp, got %v", sorted[1].Timestamp)
+	}
+	if entries[3].Count != 0 {
+		t.Error("SortTransfersByFrequency should not modify its input")
+	}
+}
+
+func TestRemoteBookmarks(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	_ = hm.RecordRemoteBookmark("web", "/var/www/site/")
+	_ = hm.RecordRemoteBookmark("web", "/etc/nginx")
+	_ = hm.RecordRemoteBookmark("web", "/var/www/site")
+	_ = hm.RecordRemoteBookmark("db", "/var/lib/postgresql")
+
+	if got := hm.GetRemoteBookmarks("web"); strings.Join(got, ",") != "/var/www/site,/etc/nginx" {
+		t.Errorf("Expected bookmarks in order without duplicates, got %v", got)
+	}
+
+	// Bookmarks survive a restart
+	reloaded := &HistoryManager{historyPath: hm.historyPath, history: &ConnectionHistory{}}
+	if err := reloaded.loadHistory(); err != nil {
+		t.Fatalf("Failed to reload history: %v", err)
+	}
+	if got := reloaded.GetRemoteBookmarks("db"); len(got) != 1 || got[0] != "/var/lib/postgresql" {
+		t.Errorf("Expected the db bookmark after reloading, got %v", got)
+	}
+
+	_ = hm.RemoveRemoteBookmark("web", "/var/www/site")
+	if got := hm.GetRemoteBookmarks("web"); len(got) != 1 || got[0] != "/etc/nginx" {
+		t.Errorf("Expected /etc/nginx left after removing, got %v", got)
+	}
+	if got := hm.GetRemoteBookmarks("unknown"); len(got) != 0 {
+		t.Errorf("Expected no bookmarks for an unknown host, got %v", got)
+	}
+
+	// The bookmarks returned are a copy
+	hm.GetRemoteBookmarks("web")[0] = "/tmp"
+	if got := hm.GetRemoteBookmarks("web"); got[0] != "/etc/nginx" {
+		t.Errorf("Expected the stored bookmarks to be unchanged, got %v", got)
+	}
+
+	// Bookmarking is not connecting, and bookmarked hosts are never archived
+	if _, ok := hm.GetLastConnectionTime("db"); ok {
+		t.Error("Expected a host only bookmarked to have no last connection")
+	}
+	if _, _, err := hm.Compact(RotationPolicy{MaxEntries: 1, MaxAge: time.Hour}); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if got := hm.GetRemoteBookmarks("db"); len(got) != 1 {
+		t.Errorf("Expected the db bookmark to survive compaction, got %v", got)
+	}
+}
+
+func TestHistoryRecordingDisabled(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv(config.NoHistoryEnv, "1")
+
+	hm, err := NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hm.RecordsTransfers() {
+		t.Error("Expected transfers not to be recorded")
+	}
+
+	_ = hm.RecordConnection("web")
+	_ = hm.RecordTransfer("web", "upload", "/tmp/secret.tar", "/srv/")
+	_ = hm.RecordTransferAttempt(TransferAttempt{Host: "web", LocalPath: "/tmp/secret.tar"})
+	_ = hm.RecordPortForwarding("web", "local", "8080", "localhost", "80", "")
+	_ = hm.RecordTransferBackend("web", transfer.BackendRsync)
+	_ = hm.RecordQuickTransferChoice("web", QuickTransferChoice{})
+	_ = hm.RecordRemoteBookmark("web", "/srv/secret")
+
+	if hm.GetConnectionCount("web") != 0 || len(hm.GetTransferHistory("web")) != 0 || hm.GetLastTransferAttempt() != nil {
+		t.Error("Expected nothing to be recorded")
+	}
+	if hm.GetPortForwardingConfig("web") != nil || len(hm.GetRemoteBookmarks("web")) != 0 {
+		t.Error("Expected no forward or bookmark to be recorded")
+	}
+	if _, ok := hm.GetQuickTransferChoice("web"); ok || hm.GetTransferBackend("web") != transfer.BackendSCP {
+		t.Error("Expected no transfer choice to be recorded")
+	}
+	if _, err := os.Stat(hm.historyPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no history file to be written, got %v", err)
+	}
+
+	store, err := NewRemotePathStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Record("web", "/var/log")
+	if got := store.Suggestions("web"); len(got) != 0 {
+		t.Errorf("Expected no remote path to be recorded, got %v", got)
+	}
+}
+
+func TestHistoryRecordingConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	off := false
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.RecordConnectionHistory = &off
+	if err := config.SaveAppConfig(&appConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	hm, err := NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = hm.RecordConnection("web")
+	_ = hm.RecordPortForwarding("web", "dynamic", "1080", "", "", "")
+	_ = hm.RecordTransfer("web", "upload", "/tmp/app.tar", "/srv/")
+
+	if hm.GetConnectionCount("web") != 0 || hm.GetPortForwardingConfig("web") != nil {
+		t.Error("Expected the connection and forward not to be recorded")
+	}
+	if len(hm.GetTransferHistory("web")) != 1 {
+		t.Error("Expected transfers to be recorded still")
+	}
+}
+
+func TestHistoryManager_ReadsWhileRecording(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			_ = hm.RecordTransfer("web1", "upload", fmt.Sprintf("/tmp/file%d", i), "/srv/")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			hm.GetTransferHistory("web1")
+			hm.GetLastTransfer("web1")
+			hm.GetLastConnectionTime("web1")
+			hm.HostData("web1", true)
+		}
+	}()
+	wg.Wait()
+
+	last := hm.GetLastTransfer("web1")
+	if last == nil || last.LocalPath != "/tmp/file19" {
+		t.Fatalf("Expected the last recorded transfer, got %+v", last)
+	}
+
+	// Entries handed out are copies the next update can't change
+	entries := hm.GetTransferHistory("web1")
+	entries[0].LocalPath = "changed"
+	if got := hm.GetLastTransfer("web1"); got.LocalPath != "/tmp/file19" {
+		t.Errorf("Expected the history to be unaffected, got %q", got.LocalPath)
+	}
+}
@@ -0,0 +1,127 @@
+package history
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// HostData returns what sshm recorded for a host: its bookmarks and, with
+// withHistory, its connections, transfers and last port forwarding
+func (hm *HistoryManager) HostData(hostName string, withHistory bool) (ConnectionInfo, bool) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	conn, exists := hm.history.Connections[hostName]
+	if !exists {
+		return ConnectionInfo{}, false
+	}
+	if withHistory {
+		return conn, true
+	}
+	if len(conn.RemoteBookmarks) == 0 {
+		return ConnectionInfo{}, false
+	}
+	return ConnectionInfo{HostName: hostName, RemoteBookmarks: conn.RemoteBookmarks}, true
+}
+
+// MergeHostData adds data recorded for a host on another machine to the
+// history of hostName. Bookmarks and transfers are added when missing, keeping
+// the 10 most recent transfers, and the connection count and the settings are
+// kept unless the imported connection is more recent. What the history
+// settings turn off here is left out, as it would be when recording.
+func (hm *HistoryManager) MergeHostData(hostName string, data ConnectionInfo) error {
+	if hm.skipConnections && hm.skipTransfers {
+		return nil
+	}
+
+	return hm.update(func(h *ConnectionHistory) {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			conn = ConnectionInfo{HostName: hostName}
+		}
+
+		if !hm.skipTransfers {
+			for _, p := range data.RemoteBookmarks {
+				if p = cleanBookmarkPath(p); p != "" && !slices.Contains(conn.RemoteBookmarks, p) {
+					conn.RemoteBookmarks = append(conn.RemoteBookmarks, p)
+				}
+			}
+
+			for _, entry := range data.TransferHistory {
+				if !containsTransfer(conn.TransferHistory, entry) {
+					conn.TransferHistory = append(conn.TransferHistory, entry)
+				}
+			}
+			sort.SliceStable(conn.TransferHistory, func(i, j int) bool {
+				return conn.TransferHistory[i].Timestamp.After(conn.TransferHistory[j].Timestamp)
+			})
+			if len(conn.TransferHistory) > maxTransferHistory {
+				conn.TransferHistory = conn.TransferHistory[:maxTransferHistory]
+			}
+		}
+
+		newer := data.LastConnect.After(conn.LastConnect)
+		if newer && !hm.skipConnections {
+			conn.LastConnect = data.LastConnect
+			if data.ConnectCount > conn.ConnectCount {
+				conn.ConnectCount = data.ConnectCount
+			}
+			if data.PortForwarding != nil {
+				conn.PortForwarding = data.PortForwarding
+			}
+		}
+		if newer && !hm.skipTransfers {
+			if data.TransferBackend != "" {
+				conn.TransferBackend = data.TransferBackend
+			}
+			if data.QuickTransfer != nil {
+				conn.QuickTransfer = data.QuickTransfer
+			}
+		}
+
+		// Transfers date the host as recording them does; bookmarks alone
+		// leave it undated, rotation keeps it for them
+		if conn.LastConnect.IsZero() && len(conn.TransferHistory) > 0 {
+			conn.LastConnect = conn.TransferHistory[0].Timestamp
+		}
+		if !exists && reflect.DeepEqual(conn, ConnectionInfo{HostName: hostName}) {
+			return // Nothing imported
+		}
+		h.Connections[hostName] = conn
+	})
+}
+
+// containsTransfer reports whether entries hold the same transfer run at the same time
+func containsTransfer(entries []TransferHistoryEntry, entry TransferHistoryEntry) bool {
+	for _, e := range entries {
+		if e.sameTransfer(entry) && e.Timestamp.Equal(entry.Timestamp) {
+			return true
+		}
+	}
+	return false
+}
+
+// RenameHost moves the history of a host, bookmarks included, to its new name
+func (hm *HistoryManager) RenameHost(oldName, newName string) error {
+	return hm.update(func(h *ConnectionHistory) {
+		if conn, exists := h.Connections[oldName]; exists {
+			conn.HostName = newName
+			h.Connections[newName] = conn
+			delete(h.Connections, oldName)
+		}
+		if h.LastTransfer != nil && h.LastTransfer.Host == oldName {
+			h.LastTransfer.Host = newName
+		}
+	})
+}
+
+// RenameHost moves the remote paths remembered for a host to its new name
+func (s *RemotePathStore) RenameHost(oldName, newName string) error {
+	return s.update(func(d *remotePathData) {
+		if entries, exists := d.Hosts[oldName]; exists {
+			d.Hosts[newName] = entries
+			delete(d.Hosts, oldName)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHostDataWithoutHistory(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	if err := hm.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordRemoteBookmark("web", "/var/www/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordConnection("db"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, ok := hm.HostData("web", false)
+	if !ok || !reflect.DeepEqual(data, ConnectionInfo{HostName: "web", RemoteBookmarks: []string{"/var/www"}}) {
+		t.Errorf("Expected only the bookmarks of web, got %+v", data)
+	}
+	if _, ok := hm.HostData("db", false); ok {
+		t.Error("Expected nothing for db without history, it has no bookmarks")
+	}
+	if data, ok := hm.HostData("db", true); !ok || data.ConnectCount != 1 {
+		t.Errorf("Expected the connections of db with history, got %+v", data)
+	}
+}
+
+func TestMergeHostData(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	older := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	newer := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	if err := hm.update(func(h *ConnectionHistory) {
+		h.Connections["web"] = ConnectionInfo{
+			HostName:        "web",
+			LastConnect:     older,
+			ConnectCount:    2,
+			RemoteBookmarks: []string{"/srv"},
+			TransferHistory: []TransferHistoryEntry{{Direction: "upload", LocalPath: "a", RemotePath: "/srv", Timestamp: older}},
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	imported := ConnectionInfo{
+		HostName:        "web",
+		LastConnect:     newer,
+		ConnectCount:    7,
+		TransferBackend: "rsync",
+		RemoteBookmarks: []string{"/srv", "/var/log"},
+		TransferHistory: []TransferHistoryEntry{
+			{Direction: "download", LocalPath: "b", RemotePath: "/var/log", Timestamp: newer},
+			{Direction: "upload", LocalPath: "a", RemotePath: "/srv", Timestamp: older},
+		},
+	}
+	// Importing the same bundle twice changes nothing the second time
+	for i := 0; i < 2; i++ {
+		if err := hm.MergeHostData("web", imported); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn := hm.history.Connections["web"]
+	if !reflect.DeepEqual(conn.RemoteBookmarks, []string{"/srv", "/var/log"}) {
+		t.Errorf("Expected the bookmarks merged, got %v", conn.RemoteBookmarks)
+	}
+	if len(conn.TransferHistory) != 2 || conn.TransferHistory[0].LocalPath != "b" {
+		t.Errorf("Expected the new transfer added first, got %+v", conn.TransferHistory)
+	}
+	if !conn.LastConnect.Equal(newer) || conn.ConnectCount != 7 || conn.TransferBackend != "rsync" {
+		t.Errorf("Expected the more recent connection to win, got %+v", conn)
+	}
+
+	// A host unknown here gets the imported data
+	if err := hm.MergeHostData("db", ConnectionInfo{RemoteBookmarks: []string{"/data"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := hm.GetRemoteBookmarks("db"); !reflect.DeepEqual(got, []string{"/data"}) {
+		t.Errorf("Expected the bookmarks of db, got %v", got)
+	}
+	// Bookmarks are not a connection
+	if _, ok := hm.GetLastConnectionTime("db"); ok {
+		t.Error("Expected a host imported for its bookmarks to stay undated")
+	}
+}
+
+func TestMergeHostDataKeepsRecentTransfers(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	var imported ConnectionInfo
+	for i := 0; i < 15; i++ {
+		imported.TransferHistory = append(imported.TransferHistory, TransferHistoryEntry{
+			Direction: "upload", LocalPath: fmt.Sprintf("file%d", i), RemotePath: "/srv", Timestamp: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	if err := hm.MergeHostData("web", imported); err != nil {
+		t.Fatal(err)
+	}
+
+	transfers := hm.GetTransferHistory("web")
+	if len(transfers) != maxTransferHistory || transfers[0].LocalPath != "file14" || transfers[len(transfers)-1].LocalPath != "file5" {
+		t.Errorf("Expected the 10 most recent transfers, got %+v", transfers)
+	}
+	if last, ok := hm.GetLastConnectionTime("web"); !ok || !last.Equal(transfers[0].Timestamp) {
+		t.Errorf("Expected the host dated by its last transfer, got %v", last)
+	}
+}
+
+func TestMergeHostDataHonorsHistorySettings(t *testing.T) {
+	imported := ConnectionInfo{
+		LastConnect:     time.Now().Add(-time.Hour),
+		ConnectCount:    3,
+		RemoteBookmarks: []string{"/srv"},
+		TransferHistory: []TransferHistoryEntry{{Direction: "upload", LocalPath: "a", RemotePath: "/srv", Timestamp: time.Now()}},
+		TransferBackend: "rsync",
+	}
+
+	hm := createTestHistoryManager(t)
+	hm.skipTransfers = true
+	if err := hm.MergeHostData("web", imported); err != nil {
+		t.Fatal(err)
+	}
+	if hm.GetConnectionCount("web") != 3 {
+		t.Error("Expected the connections imported")
+	}
+	if len(hm.GetRemoteBookmarks("web")) != 0 || len(hm.GetTransferHistory("web")) != 0 || hm.history.Connections["web"].TransferBackend != "" {
+		t.Errorf("Expected no transfer data imported, got %+v", hm.history.Connections["web"])
+	}
+
+	hm = createTestHistoryManager(t)
+	hm.skipConnections = true
+	if err := hm.MergeHostData("web", imported); err != nil {
+		t.Fatal(err)
+	}
+	if hm.GetConnectionCount("web") != 0 {
+		t.Error("Expected no connection imported")
+	}
+	if len(hm.GetRemoteBookmarks("web")) != 1 || len(hm.GetTransferHistory("web")) != 1 {
+		t.Errorf("Expected the transfer data imported, got %+v", hm.history.Connections["web"])
+	}
+
+	hm = createTestHistoryManager(t)
+	hm.skipConnections, hm.skipTransfers = true, true
+	if err := hm.MergeHostData("web", imported); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := hm.history.Connections["web"]; exists {
+		t.Error("Expected nothing imported")
+	}
+}
+
+func TestRenameHost(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	if err := hm.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordRemoteBookmark("web", "/srv"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordTransferAttempt(TransferAttempt{Host: "web", RemotePath: "/srv"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hm.RenameHost("web", "front"); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := hm.GetLastConnectionTime("web"); exists {
+		t.Error("Expected no history left under the old name")
+	}
+	if hm.GetConnectionCount("front") != 1 || len(hm.GetRemoteBookmarks("front")) != 1 {
+		t.Errorf("Expected the history moved, got %+v", hm.history.Connections["front"])
+	}
+	if hm.history.Connections["front"].HostName != "front" || hm.GetLastTransferAttempt().Host != "front" {
+		t.Error("Expected the host name updated in the records")
+	}
+
+	s := &RemotePathStore{storePath: filepath.Join(t.TempDir(), "paths.json"), data: &remotePathData{Hosts: make(map[string][]RemotePathEntry)}}
+	if err := s.Record("web", "/var/log"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RenameHost("web", "front"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Last("web") != "" || s.Last("front") != "/var/log" {
+		t.Errorf("Expected the remote paths moved, got %v", s.data.Hosts)
+	}
+}
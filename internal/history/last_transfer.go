@@ -0,0 +1,153 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+// TransferAttempt is the last transfer run, kept whether it succeeded or
+// failed so the exact same transfer can be retried
+type TransferAttempt struct {
+	Host       string    `json:"host"`
+	Direction  string    `json:"direction"` // "upload" or "download"
+	LocalPath  string    `json:"local_path"`
+	RemotePath string    `json:"remote_path"`
+	Recursive  bool      `json:"recursive,omitempty"`
+	ConfigFile string    `json:"config_file,omitempty"`
+	ExtraArgs  []string  `json:"extra_args,omitempty"`
+	User       string    `json:"user,omitempty"`
+	Port       string    `json:"port,omitempty"`
+	JumpHost   string    `json:"jump_host,omitempty"`
+	Backend    string    `json:"backend,omitempty"` // "rsync", empty for scp
+	Resumable  bool      `json:"resumable,omitempty"`
+	LimitKBps  int       `json:"limit_kbps,omitempty"` // Bandwidth limit, zero when unlimited
+	Timestamp  time.Time `json:"timestamp"`
+	Error      string    `json:"error,omitempty"` // Empty when the transfer succeeded
+}
+
+// NewTransferAttempt captures a transfer request and the error it ended with, if any
+func NewTransferAttempt(req *transfer.TransferRequest, err error) TransferAttempt {
+	direction := "upload"
+	if req.Direction == transfer.Download {
+		direction = "download"
+	}
+
+	attempt := TransferAttempt{
+		Host:       req.Host,
+		Direction:  direction,
+		LocalPath:  req.LocalPath,
+		RemotePath: req.RemotePath,
+		Recursive:  req.Recursive,
+		ConfigFile: req.ConfigFile,
+		ExtraArgs:  append([]string(nil), req.ExtraArgs...),
+		User:       req.User,
+		Port:       req.Port,
+		JumpHost:   req.JumpHost,
+		Resumable:  req.Resumable,
+		LimitKBps:  req.BandwidthLimitKBps,
+		Timestamp:  time.Now(),
+	}
+	if req.Backend == transfer.BackendRsync {
+		attempt.Backend = req.Backend.String()
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	return attempt
+}
+
+// Failed reports whether the attempt ended with an error
+func (a *TransferAttempt) Failed() bool {
+	return a.Error != ""
+}
+
+// Request rebuilds the transfer request of the attempt
+func (a *TransferAttempt) Request() *transfer.TransferRequest {
+	direction := transfer.Upload
+	if a.Direction == "download" {
+		direction = transfer.Download
+	}
+
+	backend, _ := transfer.ParseBackend(a.Backend)
+
+	return &transfer.TransferRequest{
+		Host:       a.Host,
+		Direction:  direction,
+		LocalPath:  a.LocalPath,
+		RemotePath: a.RemotePath,
+		Recursive:  a.Recursive,
+		ConfigFile: a.ConfigFile,
+		ExtraArgs:  append([]string(nil), a.ExtraArgs...),
+		User:       a.User,
+		Port:       a.Port,
+		JumpHost:   a.JumpHost,
+		Backend:    backend,
+		Resumable:  a.Resumable,
+
+		BandwidthLimitKBps: a.LimitKBps,
+	}
+}
+
+// Parameters describes the attempt one line per setting, for showing it before a retry
+func (a *TransferAttempt) Parameters() []string {
+	lines := []string{
+		"Host:      " + a.Host,
+		"Direction: " + a.Direction,
+		"Local:     " + a.LocalPath,
+		"Remote:    " + a.RemotePath,
+	}
+	if a.Recursive {
+		lines = append(lines, "Recursive: yes")
+	}
+	if a.User != "" {
+		lines = append(lines, "User:      "+a.User)
+	}
+	if a.Port != "" {
+		lines = append(lines, "Port:      "+a.Port)
+	}
+	if a.JumpHost != "" {
+		lines = append(lines, "Jump host: "+a.JumpHost)
+	}
+	if a.ConfigFile != "" {
+		lines = append(lines, "Config:    "+a.ConfigFile)
+	}
+	if len(a.ExtraArgs) > 0 {
+		lines = append(lines, "scp args:  "+strings.Join(a.ExtraArgs, " "))
+	}
+	if a.Resumable {
+		lines = append(lines, "Resume:    yes")
+	}
+	if a.LimitKBps > 0 {
+		lines = append(lines, fmt.Sprintf("Limit:     %d KB/s", a.LimitKBps))
+	}
+	if a.Backend != "" {
+		lines = append(lines, "Backend:   "+a.Backend)
+	}
+
+	status := "succeeded"
+	if a.Failed() {
+		status = "failed: " + a.Error
+	}
+	lines = append(lines, fmt.Sprintf("Last run:  %s, %s", a.Timestamp.Format("2006-01-02 15:04"), status))
+	return lines
+}
+
+// RecordTransferAttempt saves a transfer as the last one attempted, replacing the previous one
+func (hm *HistoryManager) RecordTransferAttempt(attempt TransferAttempt) error {
+	if hm.skipTransfers {
+		return nil
+	}
+	return hm.update(func(h *ConnectionHistory) {
+		h.LastTransfer = &attempt
+	})
+}
+
+// GetLastTransferAttempt returns the last transfer attempted on any host, or nil
+func (hm *HistoryManager) GetLastTransferAttempt() *TransferAttempt {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	return hm.history.LastTransfer
+}
@@ -0,0 +1,124 @@
+package history
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+func TestRecordTransferAttemptPersistsFailure(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	req := &transfer.TransferRequest{
+		Host:       "web",
+		Direction:  transfer.Download,
+		LocalPath:  "/tmp/logs",
+		RemotePath: "/var/log/app",
+		Recursive:  true,
+		ConfigFile: "/home/me/.ssh/work_config",
+		ExtraArgs:  []string{"-O", "-l", "8192"},
+		User:       "deploy",
+		Port:       "2222",
+		JumpHost:   "admin@bastion.example.com",
+		Backend:    transfer.BackendRsync,
+		Resumable:  true,
+
+		BandwidthLimitKBps: 500,
+	}
+	if err := hm.RecordTransferAttempt(NewTransferAttempt(req, errors.New("scp: connection refused"))); err != nil {
+		t.Fatalf("RecordTransferAttempt() error = %v", err)
+	}
+
+	// A fresh manager reads the attempt back from disk
+	reloaded := &HistoryManager{historyPath: hm.historyPath, history: &ConnectionHistory{}}
+	if err := reloaded.reloadHistory(); err != nil {
+		t.Fatalf("reloadHistory() error = %v", err)
+	}
+
+	attempt := reloaded.GetLastTransferAttempt()
+	if attempt == nil {
+		t.Fatal("Expected the failed attempt to be saved")
+	}
+	if !attempt.Failed() || attempt.Error != "scp: connection refused" {
+		t.Errorf("Expected the failure to be kept, got %q", attempt.Error)
+	}
+	if !reflect.DeepEqual(attempt.Request(), req) {
+		t.Errorf("Request() = %+v, want %+v", attempt.Request(), req)
+	}
+	if attempt.Request().CommandString() != req.CommandString() {
+		t.Errorf("Expected the same scp command, got %q", attempt.Request().CommandString())
+	}
+}
+
+func TestRecordTransferAttemptReplacesPrevious(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	if hm.GetLastTransferAttempt() != nil {
+		t.Fatal("Expected no attempt in an empty history")
+	}
+
+	first := &transfer.TransferRequest{Host: "web", Direction: transfer.Upload, LocalPath: "/tmp/a", RemotePath: "/srv/"}
+	second := &transfer.TransferRequest{Host: "db", Direction: transfer.Upload, LocalPath: "/tmp/b", RemotePath: "/backup/"}
+	_ = hm.RecordTransferAttempt(NewTransferAttempt(first, errors.New("failed")))
+	_ = hm.RecordTransferAttempt(NewTransferAttempt(second, nil))
+
+	attempt := hm.GetLastTransferAttempt()
+	if attempt == nil || attempt.Host != "db" || attempt.Failed() {
+		t.Fatalf("Expected the successful db upload, got %+v", attempt)
+	}
+	if got := hm.GetTransferHistory("db"); len(got) != 0 {
+		t.Errorf("Attempts should not add to the per-host transfer history, got %v", got)
+	}
+}
+
+func TestTransferAttemptParameters(t *testing.T) {
+	attempt := NewTransferAttempt(&transfer.TransferRequest{
+		Host:       "web",
+		Direction:  transfer.Upload,
+		LocalPath:  "/tmp/site",
+		RemotePath: "/srv/www",
+		Recursive:  true,
+		Port:       "2222",
+	}, errors.New("lost connection"))
+
+	text := strings.Join(attempt.Parameters(), "\n")
+	for _, want := range []string{"web", "upload", "/tmp/site", "/srv/www", "Recursive: yes", "2222", "failed: lost connection"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in parameters:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "User:") {
+		t.Errorf("Unset settings should be left out:\n%s", text)
+	}
+}
+
+func TestTransferBackendPerHost(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	if hm.GetTransferBackend("web") != transfer.BackendSCP {
+		t.Error("Expected scp for a host without a recorded backend")
+	}
+	if err := hm.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordTransferBackend("web", transfer.BackendRsync); err != nil {
+		t.Fatalf("RecordTransferBackend() error = %v", err)
+	}
+
+	reloaded := &HistoryManager{historyPath: hm.historyPath, history: &ConnectionHistory{}}
+	if err := reloaded.reloadHistory(); err != nil {
+		t.Fatalf("reloadHistory() error = %v", err)
+	}
+	if reloaded.GetTransferBackend("web") != transfer.BackendRsync {
+		t.Error("Expected rsync to be remembered for web")
+	}
+	if reloaded.GetConnectionCount("web") != 1 {
+		t.Error("Recording the backend should not count as a connection")
+	}
+	if reloaded.GetTransferBackend("db") != transfer.BackendSCP {
+		t.Error("Expected other hosts to keep scp")
+	}
+}
@@ -0,0 +1,186 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// maxRemotePathsPerHost caps how many remote paths are remembered per host
+const maxRemotePathsPerHost = 20
+
+// RemotePathEntry is a remote directory used on a host
+type RemotePathEntry struct {
+	Path     string    `json:"path"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// remotePathData is the on-disk format of the remote path store
+type remotePathData struct {
+	Hosts map[string][]RemotePathEntry `json:"hosts"`
+}
+
+// RemotePathStore remembers remote directories visited or selected per host,
+// shared by the remote browser, the transfer forms and the send/get commands
+type RemotePathStore struct {
+	storePath string
+	data      *remotePathData
+	skip      bool       // Transfer history is turned off: nothing new is recorded
+	mu        sync.Mutex // Serializes updates within this process
+}
+
+// NewRemotePathStore creates a remote path store backed by ~/.config/sshm/sshm_remote_paths.json
+func NewRemotePathStore() (*RemotePathStore, error) {
+	configDir, err := config.GetSSHMConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure config dir exists
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := &RemotePathStore{
+		storePath: filepath.Join(configDir, "sshm_remote_paths.json"),
+		data:      &remotePathData{Hosts: make(map[string][]RemotePathEntry)},
+	}
+	appConfig, err := config.LoadAppConfig()
+	if err != nil {
+		appConfig = nil // Defaults, still honoring SSHM_NO_HISTORY
+	}
+	s.skip = !appConfig.TransferHistoryEnabled()
+
+	// Load existing paths if any
+	if err := s.load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load reads the store from disk
+func (s *RemotePathStore) load() error {
+	data, err := os.ReadFile(s.storePath)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, s.data); err != nil {
+		return err
+	}
+	if s.data.Hosts == nil {
+		s.data.Hosts = make(map[string][]RemotePathEntry)
+	}
+	return nil
+}
+
+// save writes the store to disk
+func (s *RemotePathStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.storePath), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return config.WriteFileAtomic(s.storePath, data, 0600)
+}
+
+// update applies mutate to the latest store on disk and saves it, holding
+// the store's file lock so concurrent instances don't lose each other's paths
+func (s *RemotePathStore) update(mutate func(d *remotePathData)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withFileLock(s.storePath, func() error {
+		s.data = &remotePathData{Hosts: make(map[string][]RemotePathEntry)}
+		if err := s.load(); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		mutate(s.data)
+		return s.save()
+	})
+}
+
+// normalizeRemotePath cleans a remote path for storage; empty means "don't record"
+func normalizeRemotePath(p string) string {
+	p = strings.TrimSpace(p)
+	if p == "" || p == "~" || p == "~/" {
+		// The home directory is always available, no need to remember it
+		return ""
+	}
+	return path.Clean(p)
+}
+
+// Record remembers a remote directory for a host, moving it to the front if already known
+func (s *RemotePathStore) Record(hostName, remotePath string) error {
+	remotePath = normalizeRemotePath(remotePath)
+	if remotePath == "" || s.skip {
+		return nil
+	}
+
+	now := time.Now()
+	return s.update(func(d *remotePathData) {
+		entries := []RemotePathEntry{{Path: remotePath, LastUsed: now}}
+		for _, e := range d.Hosts[hostName] {
+			if e.Path != remotePath {
+				entries = append(entries, e)
+			}
+		}
+		if len(entries) > maxRemotePathsPerHost {
+			entries = entries[:maxRemotePathsPerHost]
+		}
+		d.Hosts[hostName] = entries
+	})
+}
+
+// RecordParent remembers the directory containing a remote file
+func (s *RemotePathStore) RecordParent(hostName, remoteFile string) error {
+	remoteFile = normalizeRemotePath(remoteFile)
+	if remoteFile == "" {
+		return nil
+	}
+	return s.Record(hostName, path.Dir(remoteFile))
+}
+
+// RecordTransfer remembers the remote directory used by a transfer: the destination
+// of an upload, or the directory containing a downloaded file
+func (s *RemotePathStore) RecordTransfer(hostName, direction, remotePath string, recursive bool) error {
+	if direction == "download" && !recursive {
+		return s.RecordParent(hostName, remotePath)
+	}
+	return s.Record(hostName, remotePath)
+}
+
+// Suggestions returns the remembered remote directories for a host, most recent first
+func (s *RemotePathStore) Suggestions(hostName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.data.Hosts[hostName]
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+// Last returns the most recently used remote directory for a host, or "" if none
+func (s *RemotePathStore) Last(hostName string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entries := s.data.Hosts[hostName]; len(entries) > 0 {
+		return entries[0].Path
+	}
+	return ""
+}
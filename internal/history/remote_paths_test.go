@@ -0,0 +1,180 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// createTestRemotePathStore creates a remote path store with a temporary file for testing
+func createTestRemotePathStore(t *testing.T) *RemotePathStore {
+	tempDir := t.TempDir()
+	return &RemotePathStore{
+		storePath: filepath.Join(tempDir, "test_sshm_remote_paths.json"),
+		data:      &remotePathData{Hosts: make(map[string][]RemotePathEntry)},
+	}
+}
+
+func TestRemotePathStore_RecordAndSuggestions(t *testing.T) {
+	s := createTestRemotePathStore(t)
+
+	for _, p := range []string{"/var/log", "/etc/nginx", "/srv/app"} {
+		if err := s.Record("web1", p); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	expected := []string{"/srv/app", "/etc/nginx", "/var/log"}
+	if got := s.Suggestions("web1"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Suggestions() = %v, want %v", got, expected)
+	}
+	if got := s.Last("web1"); got != "/srv/app" {
+		t.Errorf("Last() = %q, want /srv/app", got)
+	}
+}
+
+func TestRemotePathStore_PerHost(t *testing.T) {
+	s := createTestRemotePathStore(t)
+
+	_ = s.Record("web1", "/var/www")
+	_ = s.Record("db1", "/var/lib/postgresql")
+
+	if got := s.Suggestions("web1"); !reflect.DeepEqual(got, []string{"/var/www"}) {
+		t.Errorf("web1 suggestions = %v", got)
+	}
+	if got := s.Suggestions("db1"); !reflect.DeepEqual(got, []string{"/var/lib/postgresql"}) {
+		t.Errorf("db1 suggestions = %v", got)
+	}
+	if got := s.Suggestions("unknown"); len(got) != 0 {
+		t.Errorf("Expected no suggestions for unknown host, got %v", got)
+	}
+	if got := s.Last("unknown"); got != "" {
+		t.Errorf("Expected no last path for unknown host, got %q", got)
+	}
+}
+
+func TestRemotePathStore_Dedupe(t *testing.T) {
+	s := createTestRemotePathStore(t)
+
+	_ = s.Record("web1", "/var/log")
+	_ = s.Record("web1", "/etc")
+	_ = s.Record("web1", "/var/log/") // Same directory, trailing slash
+
+	expected := []string{"/var/log", "/etc"}
+	if got := s.Suggestions("web1"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Suggestions() = %v, want %v", got, expected)
+	}
+}
+
+func TestRemotePathStore_Cap(t *testing.T) {
+	s := createTestRemotePathStore(t)
+
+	for i := 0; i < maxRemotePathsPerHost+5; i++ {
+		_ = s.Record("web1", fmt.Sprintf("/data/dir%d", i))
+	}
+
+	got := s.Suggestions("web1")
+	if len(got) != maxRemotePathsPerHost {
+		t.Fatalf("Expected %d suggestions, got %d", maxRemotePathsPerHost, len(got))
+	}
+	if got[0] != fmt.Sprintf("/data/dir%d", maxRemotePathsPerHost+4) {
+		t.Errorf("Expected most recent path first, got %s", got[0])
+	}
+}
+
+func TestRemotePathStore_SkipsHomeAndEmpty(t *testing.T) {
+	s := createTestRemotePathStore(t)
+
+	for _, p := range []string{"", "  ", "~", "~/"} {
+		_ = s.Record("web1", p)
+	}
+
+	if got := s.Suggestions("web1"); len(got) != 0 {
+		t.Errorf("Expected no suggestions, got %v", got)
+	}
+}
+
+func TestRemotePathStore_RecordParent(t *testing.T) {
+	s := createTestRemotePathStore(t)
+
+	_ = s.RecordParent("web1", "/var/log/nginx/access.log")
+
+	if got := s.Last("web1"); got != "/var/log/nginx" {
+		t.Errorf("Last() = %q, want /var/log/nginx", got)
+	}
+}
+
+func TestRemotePathStore_RecordTransfer(t *testing.T) {
+	s := createTestRemotePathStore(t)
+
+	_ = s.RecordTransfer("web1", "upload", "/etc/nginx/", false)
+	if got := s.Last("web1"); got != "/etc/nginx" {
+		t.Errorf("After upload, Last() = %q, want /etc/nginx", got)
+	}
+
+	_ = s.RecordTransfer("web1", "download", "/var/log/syslog", false)
+	if got := s.Last("web1"); got != "/var/log" {
+		t.Errorf("After file download, Last() = %q, want /var/log", got)
+	}
+
+	_ = s.RecordTransfer("web1", "download", "/srv/backups", true)
+	if got := s.Last("web1"); got != "/srv/backups" {
+		t.Errorf("After folder download, Last() = %q, want /srv/backups", got)
+	}
+}
+
+func TestRemotePathStore_Persistence(t *testing.T) {
+	s := createTestRemotePathStore(t)
+	_ = s.Record("web1", "/var/log")
+	_ = s.Record("web1", "/etc")
+
+	// Reload from the same file
+	s2 := &RemotePathStore{
+		storePath: s.storePath,
+		data:      &remotePathData{Hosts: make(map[string][]RemotePathEntry)},
+	}
+	if err := s2.load(); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	if got := s2.Suggestions("web1"); !reflect.DeepEqual(got, []string{"/etc", "/var/log"}) {
+		t.Errorf("Reloaded suggestions = %v", got)
+	}
+}
+
+func TestRemotePathStore_ConcurrentInstancesKeepEachOthersPaths(t *testing.T) {
+	s1 := createTestRemotePathStore(t)
+	s2 := &RemotePathStore{
+		storePath: s1.storePath,
+		data:      &remotePathData{Hosts: make(map[string][]RemotePathEntry)},
+	}
+
+	// Both instances were loaded before either recorded anything
+	if err := s1.Record("web1", "/var/log"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := s2.Record("db1", "/srv/backups"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := s1.RenameHost("web1", "web2"); err != nil {
+		t.Fatalf("RenameHost() error = %v", err)
+	}
+
+	reloaded := &RemotePathStore{
+		storePath: s1.storePath,
+		data:      &remotePathData{Hosts: make(map[string][]RemotePathEntry)},
+	}
+	if err := reloaded.load(); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if got := reloaded.Last("db1"); got != "/srv/backups" {
+		t.Errorf("Last(db1) = %q, want /srv/backups", got)
+	}
+	if got := reloaded.Last("web2"); got != "/var/log" {
+		t.Errorf("Last(web2) = %q, want /var/log", got)
+	}
+	if got := reloaded.Suggestions("web1"); len(got) != 0 {
+		t.Errorf("Suggestions(web1) = %v, want none after rename", got)
+	}
+}
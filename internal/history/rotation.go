@@ -0,0 +1,203 @@
+package history
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// RotationPolicy bounds how much connection history the active file keeps.
+// A zero MaxEntries or MaxAge disables that limit.
+type RotationPolicy struct {
+	MaxEntries int
+	MaxAge     time.Duration
+}
+
+// RotationPolicyFromConfig converts the application history settings to a policy
+func RotationPolicyFromConfig(cfg config.HistoryConfig) RotationPolicy {
+	var policy RotationPolicy
+	if cfg.MaxEntries > 0 {
+		policy.MaxEntries = cfg.MaxEntries
+	}
+	if cfg.MaxAgeDays > 0 {
+		policy.MaxAge = time.Duration(cfg.MaxAgeDays) * 24 * time.Hour
+	}
+	return policy
+}
+
+// archivePath returns the path of the compressed archive of rotated history
+func (hm *HistoryManager) archivePath() string {
+	ext := filepath.Ext(hm.historyPath)
+	return hm.historyPath[:len(hm.historyPath)-len(ext)] + "_archive.jsonl.gz"
+}
+
+// selectExpired returns the hosts that fall outside the policy: those unused
+// for longer than MaxAge, then the least recently used beyond MaxEntries.
+// Hosts with remote bookmarks are kept whatever their age.
+func selectExpired(h *ConnectionHistory, policy RotationPolicy, now time.Time) []string {
+	connections := make([]ConnectionInfo, 0, len(h.Connections))
+	for name, conn := range h.Connections {
+		if len(conn.RemoteBookmarks) > 0 {
+			continue
+		}
+		conn.HostName = name
+		connections = append(connections, conn)
+	}
+
+	// Most recent first, name as tie-breaker so rotation is deterministic
+	sort.Slice(connections, func(i, j int) bool {
+		if !connections[i].LastConnect.Equal(connections[j].LastConnect) {
+			return connections[i].LastConnect.After(connections[j].LastConnect)
+		}
+		return connections[i].HostName < connections[j].HostName
+	})
+
+	var expired []string
+	kept := 0
+	for _, conn := range connections {
+		tooOld := policy.MaxAge > 0 && now.Sub(conn.LastConnect) > policy.MaxAge
+		tooMany := policy.MaxEntries > 0 && kept >= policy.MaxEntries
+		if tooOld || tooMany {
+			expired = append(expired, conn.HostName)
+			continue
+		}
+		kept++
+	}
+	return expired
+}
+
+// rotate moves expired hosts from h into the archive and returns how many were
+// moved, with a function taking them back out of the archive for when h can't
+// be saved
+func (hm *HistoryManager) rotate(h *ConnectionHistory, policy RotationPolicy) (int, func(), error) {
+	expired := selectExpired(h, policy, time.Now())
+	if len(expired) == 0 {
+		return 0, func() {}, nil
+	}
+
+	archived := make([]ConnectionInfo, 0, len(expired))
+	for _, name := range expired {
+		archived = append(archived, h.Connections[name])
+	}
+
+	path := hm.archivePath()
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	undo := func() { _ = truncateArchive(path, size) }
+	if err := appendArchive(path, archived); err != nil {
+		undo()
+		return 0, nil, err
+	}
+
+	for _, name := range expired {
+		delete(h.Connections, name)
+	}
+	return len(expired), undo, nil
+}
+
+// truncateArchive cuts the archive back to size, removing it when it was empty
+func truncateArchive(path string, size int64) error {
+	if size == 0 {
+		return os.Remove(path)
+	}
+	return os.Truncate(path, size)
+}
+
+// appendArchive appends entries to a gzip archive as one JSON object per line.
+// Each call adds a new gzip member, which readers see as one continuous stream.
+func appendArchive(path string, entries []ConnectionInfo) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			zw.Close()
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// readArchive reads every entry from a history archive
+func readArchive(path string) ([]ConnectionInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var entries []ConnectionInfo
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry ConnectionInfo
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Compact archives hosts outside the given policy and rewrites the active
+// history file. It returns the number of hosts archived and the archive path.
+// Every update also archives the hosts outside the configured policy;
+// Restore brings them back.
+func (hm *HistoryManager) Compact(policy RotationPolicy) (int, string, error) {
+	archived, err := hm.updateRotating(func(h *ConnectionHistory) {}, policy)
+	if err != nil {
+		return 0, "", err
+	}
+	return archived, hm.archivePath(), nil
+}
+
+// Restore moves every host of the archive back into the active history and
+// removes the archive. Hosts recorded again since they were archived keep
+// their active entry. It returns the number of hosts restored.
+func (hm *HistoryManager) Restore() (int, error) {
+	entries, err := readArchive(hm.archivePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	// Restored hosts are not archived again by this update
+	restored := 0
+	_, err = hm.updateRotating(func(h *ConnectionHistory) {
+		for _, entry := range entries {
+			if _, exists := h.Connections[entry.HostName]; exists {
+				continue
+			}
+			h.Connections[entry.HostName] = entry
+			restored++
+		}
+	}, RotationPolicy{})
+	if err != nil {
+		return 0, err
+	}
+	return restored, os.Remove(hm.archivePath())
+}
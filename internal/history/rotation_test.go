@@ -0,0 +1,244 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// seedHistory writes connections last used i days ago for host0..hostN-1
+func seedHistory(t *testing.T, hm *HistoryManager, n int) {
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("host%d", i)
+		hm.history.Connections[name] = ConnectionInfo{
+			HostName:     name,
+			LastConnect:  now.Add(-time.Duration(i) * 24 * time.Hour),
+			ConnectCount: i + 1,
+		}
+	}
+	if err := hm.saveHistory(); err != nil {
+		t.Fatalf("saveHistory() error = %v", err)
+	}
+}
+
+func TestRotationPolicyFromConfig(t *testing.T) {
+	policy := RotationPolicyFromConfig(config.HistoryConfig{MaxEntries: 10, MaxAgeDays: 2})
+	if policy.MaxEntries != 10 || policy.MaxAge != 48*time.Hour {
+		t.Errorf("Unexpected policy %+v", policy)
+	}
+
+	disabled := RotationPolicyFromConfig(config.HistoryConfig{MaxEntries: -1, MaxAgeDays: -1})
+	if disabled.MaxEntries != 0 || disabled.MaxAge != 0 {
+		t.Errorf("Expected negative limits to disable rotation, got %+v", disabled)
+	}
+}
+
+func TestSelectExpired(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	seedHistory(t, hm, 10)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		policy   RotationPolicy
+		expected int
+	}{
+		{"No limits", RotationPolicy{}, 0},
+		{"Under count threshold", RotationPolicy{MaxEntries: 10}, 0},
+		{"Over count threshold", RotationPolicy{MaxEntries: 7}, 3},
+		{"Age threshold", RotationPolicy{MaxAge: 5*24*time.Hour - time.Hour}, 5},
+		{"Both thresholds", RotationPolicy{MaxEntries: 2, MaxAge: 5*24*time.Hour - time.Hour}, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectExpired(hm.history, tt.policy, now); len(got) != tt.expected {
+				t.Errorf("selectExpired() returned %d hosts (%v), want %d", len(got), got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompactPreservesActiveData(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	seedHistory(t, hm, 10)
+
+	archived, archivePath, err := hm.Compact(RotationPolicy{MaxEntries: 4})
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if archived != 6 {
+		t.Errorf("Expected 6 hosts archived, got %d", archived)
+	}
+
+	// The most recent hosts remain in the active file untouched
+	reloaded := &HistoryManager{
+		historyPath: hm.historyPath,
+		history:     &ConnectionHistory{Connections: make(map[string]ConnectionInfo)},
+	}
+	if err := reloaded.loadHistory(); err != nil {
+		t.Fatalf("loadHistory() error = %v", err)
+	}
+	if got := len(reloaded.history.Connections); got != 4 {
+		t.Errorf("Expected 4 active hosts, got %d", got)
+	}
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("host%d", i)
+		if got := reloaded.GetConnectionCount(name); got != i+1 {
+			t.Errorf("Expected %s count %d, got %d", name, i+1, got)
+		}
+	}
+
+	// The older hosts are in the archive
+	entries, err := readArchive(archivePath)
+	if err != nil {
+		t.Fatalf("readArchive() error = %v", err)
+	}
+	if len(entries) != 6 {
+		t.Fatalf("Expected 6 archived entries, got %d", len(entries))
+	}
+
+	// A second compaction appends to the archive
+	if _, _, err := hm.Compact(RotationPolicy{MaxEntries: 2}); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	entries, err = readArchive(archivePath)
+	if err != nil {
+		t.Fatalf("readArchive() error = %v", err)
+	}
+	if len(entries) != 8 {
+		t.Errorf("Expected 8 archived entries after second compaction, got %d", len(entries))
+	}
+}
+
+func TestCompactWithinLimits(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	seedHistory(t, hm, 3)
+
+	archived, archivePath, err := hm.Compact(RotationPolicy{MaxEntries: 5})
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if archived != 0 {
+		t.Errorf("Expected nothing archived, got %d", archived)
+	}
+	if _, err := os.Stat(archivePath); !os.IsNotExist(err) {
+		t.Error("Expected no archive to be created")
+	}
+}
+
+func TestRecordingArchivesOutsidePolicy(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	seedHistory(t, hm, 5)
+	hm.policy = RotationPolicy{MaxEntries: 3}
+
+	if err := hm.RecordConnection("host0"); err != nil {
+		t.Fatalf("RecordConnection() error = %v", err)
+	}
+	if got := len(hm.history.Connections); got != 3 {
+		t.Errorf("Expected 3 active hosts, got %d", got)
+	}
+	entries, err := readArchive(hm.archivePath())
+	if err != nil {
+		t.Fatalf("readArchive() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].HostName != "host3" || entries[1].HostName != "host4" {
+		t.Errorf("Expected the 2 oldest hosts archived, got %+v", entries)
+	}
+
+	// Within the limits, nothing more is archived
+	if err := hm.RecordConnection("host1"); err != nil {
+		t.Fatalf("RecordConnection() error = %v", err)
+	}
+	if entries, _ := readArchive(hm.archivePath()); len(entries) != 2 {
+		t.Errorf("Expected the archive to be left alone, got %d entries", len(entries))
+	}
+}
+
+func TestFailedSaveLeavesArchive(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	seedHistory(t, hm, 5)
+	if _, _, err := hm.Compact(RotationPolicy{MaxEntries: 4}); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	before, err := os.ReadFile(hm.archivePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The backup can't replace a directory, so saving the history fails
+	if err := os.Remove(hm.backupPath()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(hm.backupPath(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	hm.policy = RotationPolicy{MaxEntries: 2}
+	if err := hm.RecordConnection("host0"); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+
+	after, err := os.ReadFile(hm.archivePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("Expected the archive to be truncated back, grew from %d to %d bytes", len(before), len(after))
+	}
+	if err := hm.reloadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(hm.history.Connections); got != 4 {
+		t.Errorf("Expected the 4 hosts to stay active, got %d", got)
+	}
+
+	// Without an archive before, a failed save leaves none behind
+	os.Remove(hm.archivePath())
+	if err := hm.RecordConnection("host0"); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+	if _, err := os.Stat(hm.archivePath()); !os.IsNotExist(err) {
+		t.Errorf("Expected no archive, got %v", err)
+	}
+}
+
+func TestRestoreArchive(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	seedHistory(t, hm, 5)
+
+	if restored, err := hm.Restore(); err != nil || restored != 0 {
+		t.Errorf("Restore() without an archive = %d, %v", restored, err)
+	}
+
+	if _, _, err := hm.Compact(RotationPolicy{MaxEntries: 2}); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	// Recorded again after it was archived: the active entry wins
+	if err := hm.RecordConnection("host4"); err != nil {
+		t.Fatalf("RecordConnection() error = %v", err)
+	}
+
+	restored, err := hm.Restore()
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if restored != 2 {
+		t.Errorf("Expected 2 hosts restored, got %d", restored)
+	}
+	if got := len(hm.history.Connections); got != 5 {
+		t.Errorf("Expected 5 active hosts, got %d", got)
+	}
+	if got := hm.GetConnectionCount("host2"); got != 3 {
+		t.Errorf("Expected host2 to be restored with its count, got %d", got)
+	}
+	if got := hm.GetConnectionCount("host4"); got != 1 {
+		t.Errorf("Expected the active host4 to be kept, got count %d", got)
+	}
+	if _, err := os.Stat(hm.archivePath()); !os.IsNotExist(err) {
+		t.Error("Expected the archive to be removed once restored")
+	}
+}
@@ -0,0 +1,115 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// AuthMethod describes the key an SFTP session logged in with
+type AuthMethod struct {
+	Agent   bool   // The key is held by the SSH agent
+	Key     string // Identity file path, or the agent's comment for the key
+	KeyType string // e.g. ssh-ed25519
+}
+
+func (a AuthMethod) String() string {
+	source := "identity file " + a.Key
+	if a.Agent {
+		source = "SSH agent key"
+		if a.Key != "" {
+			source += " " + a.Key
+		}
+	}
+	if a.KeyType != "" {
+		source += fmt.Sprintf(" (%s)", a.KeyType)
+	}
+	return source
+}
+
+// authRecorder remembers which key got a session in. x/crypto/ssh first asks
+// the server whether it would accept each key, and only signs with a key it
+// accepts, so the last key asked to sign is the one that logged in.
+type authRecorder struct {
+	mu     sync.Mutex
+	method *AuthMethod
+}
+
+func (r *authRecorder) record(method AuthMethod) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.method = &method
+}
+
+// succeeded returns the method of the last key used to sign, if any
+func (r *authRecorder) succeeded() (AuthMethod, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.method == nil {
+		return AuthMethod{}, false
+	}
+	return *r.method, true
+}
+
+// wrap returns a signer reporting to r when it signs. The algorithm
+// interfaces of signer are kept so RSA keys still negotiate SHA-2 signatures.
+func (r *authRecorder) wrap(signer ssh.Signer, method AuthMethod) ssh.Signer {
+	method.KeyType = signer.PublicKey().Type()
+	base := recordingSigner{Signer: signer, method: method, recorder: r}
+
+	switch s := signer.(type) {
+	case ssh.MultiAlgorithmSigner:
+		return &recordingMultiAlgorithmSigner{recordingAlgorithmSigner{base, s}, s}
+	case ssh.AlgorithmSigner:
+		return &recordingAlgorithmSigner{base, s}
+	default:
+		return &base
+	}
+}
+
+// recordingSigner is a signer that reports its AuthMethod when used
+type recordingSigner struct {
+	ssh.Signer
+	method   AuthMethod
+	recorder *authRecorder
+}
+
+func (s *recordingSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	s.recorder.record(s.method)
+	return s.Signer.Sign(rand, data)
+}
+
+// recordingAlgorithmSigner is a recordingSigner for ssh.AlgorithmSigner keys
+type recordingAlgorithmSigner struct {
+	recordingSigner
+	algorithmSigner ssh.AlgorithmSigner
+}
+
+func (s *recordingAlgorithmSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	s.recorder.record(s.method)
+	return s.algorithmSigner.SignWithAlgorithm(rand, data, algorithm)
+}
+
+// recordingMultiAlgorithmSigner is a recordingSigner for ssh.MultiAlgorithmSigner keys
+type recordingMultiAlgorithmSigner struct {
+	recordingAlgorithmSigner
+	multiSigner ssh.MultiAlgorithmSigner
+}
+
+func (s *recordingMultiAlgorithmSigner) Algorithms() []string {
+	return s.multiSigner.Algorithms()
+}
+
+// agentKeyComment returns the comment the agent holds for key
+func agentKeyComment(agentKeys []*agent.Key, key ssh.PublicKey) string {
+	for _, k := range agentKeys {
+		if bytes.Equal(k.Marshal(), key.Marshal()) {
+			return k.Comment
+		}
+	}
+	return ""
+}
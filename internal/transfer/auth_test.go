@@ -0,0 +1,145 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// newTestSigner generates a throwaway ed25519 signer
+func newTestSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// authenticate logs in to a loopback SSH server that only accepts accepted,
+// offering signers in order, and returns the method recorded by the client
+func authenticate(t *testing.T, accepted ssh.PublicKey, recorder *authRecorder, signers ...ssh.Signer) (AuthMethod, bool) {
+	t.Helper()
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), accepted.Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	serverConfig.AddHostKey(newTestSigner(t))
+
+	// net.Pipe is unbuffered and both sides send their version first, so use loopback TCP
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on loopback: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		serverConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer serverConn.Close()
+		conn, chans, reqs, err := ssh.NewServerConn(serverConn, serverConfig)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		go ssh.DiscardRequests(reqs)
+		for ch := range chans {
+			_ = ch.Reject(ssh.Prohibited, "test server")
+		}
+	}()
+
+	clientConfig := &ssh.ClientConfig{
+		User:            "deploy",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	client, err := ssh.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err != nil {
+		t.Fatalf("Expected to log in, got %v", err)
+	}
+	client.Close()
+
+	return recorder.succeeded()
+}
+
+func TestAuthRecorderRecordsAcceptedKey(t *testing.T) {
+	agentKey, fileKey, otherKey := newTestSigner(t), newTestSigner(t), newTestSigner(t)
+
+	recorder := &authRecorder{}
+	signers := []ssh.Signer{
+		recorder.wrap(agentKey, AuthMethod{Agent: true, Key: "me@laptop"}),
+		recorder.wrap(fileKey, AuthMethod{Key: "/home/me/.ssh/id_work"}),
+		recorder.wrap(otherKey, AuthMethod{Key: "/home/me/.ssh/id_other"}),
+	}
+
+	// Refused keys are offered but never sign, so only the accepted one is recorded
+	method, ok := authenticate(t, fileKey.PublicKey(), recorder, signers...)
+	want := AuthMethod{Key: "/home/me/.ssh/id_work", KeyType: ssh.KeyAlgoED25519}
+	if !ok || method != want {
+		t.Fatalf("Recorded method = %+v (%v), want %+v", method, ok, want)
+	}
+	if method.String() != "identity file /home/me/.ssh/id_work (ssh-ed25519)" {
+		t.Errorf("Unexpected description %q", method.String())
+	}
+
+	recorder = &authRecorder{}
+	signers = []ssh.Signer{
+		recorder.wrap(agentKey, AuthMethod{Agent: true, Key: "me@laptop"}),
+		recorder.wrap(fileKey, AuthMethod{Key: "/home/me/.ssh/id_work"}),
+	}
+	method, ok = authenticate(t, agentKey.PublicKey(), recorder, signers...)
+	if !ok || !method.Agent || method.String() != "SSH agent key me@laptop (ssh-ed25519)" {
+		t.Errorf("Expected the agent key, got %+v (%v)", method, ok)
+	}
+}
+
+func TestAuthRecorderKeepsRSASignatureAlgorithms(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: priv, Comment: "rsa@laptop"}); err != nil {
+		t.Fatal(err)
+	}
+	agentSigners, err := keyring.Signers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentKeys, _ := keyring.List()
+
+	recorder := &authRecorder{}
+	signer := agentSigners[0]
+	wrapped := recorder.wrap(signer, AuthMethod{Agent: true, Key: agentKeyComment(agentKeys, signer.PublicKey())})
+	if _, ok := wrapped.(ssh.AlgorithmSigner); !ok {
+		t.Fatal("Expected the wrapped agent signer to keep SignWithAlgorithm")
+	}
+
+	method, ok := authenticate(t, signer.PublicKey(), recorder, wrapped)
+	if !ok || method.Key != "rsa@laptop" || method.KeyType != ssh.KeyAlgoRSA {
+		t.Errorf("Expected the RSA agent key, got %+v (%v)", method, ok)
+	}
+}
+
+func TestSessionAuthMethodUnknown(t *testing.T) {
+	s := &SFTPSession{}
+	if _, ok := s.AuthMethod(); ok {
+		t.Error("Expected no method for a session without a recorded login")
+	}
+}
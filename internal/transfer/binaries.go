@@ -0,0 +1,54 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// MissingBinaryError reports a program sshm runs that is not installed
+type MissingBinaryError struct {
+	Name string
+}
+
+func (e *MissingBinaryError) Error() string {
+	return fmt.Sprintf("%s not found in PATH. %s", e.Name, GetInstallInstructions(e.Name, runtime.GOOS))
+}
+
+// RequireBinary returns a MissingBinaryError when name is not on PATH
+func RequireBinary(name string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return &MissingBinaryError{Name: name}
+	}
+	return nil
+}
+
+// CheckCommand returns a MissingBinaryError when the program cmd runs was not
+// found, instead of the raw error Start would return
+func CheckCommand(cmd *exec.Cmd) error {
+	if cmd.Err != nil && errors.Is(cmd.Err, exec.ErrNotFound) {
+		return &MissingBinaryError{Name: filepath.Base(cmd.Args[0])}
+	}
+	return nil
+}
+
+// GetInstallInstructions returns how to install the program name on the
+// platform goos, as reported by runtime.GOOS
+func GetInstallInstructions(name, goos string) string {
+	switch name {
+	case "ssh", "scp", "sftp", "ssh-copy-id", "ssh-keygen":
+		switch goos {
+		case "darwin":
+			return "OpenSSH ships with macOS; check your PATH or install it with: brew install openssh"
+		case "linux":
+			return "Install with: sudo apt install openssh-client (Debian/Ubuntu) or sudo dnf install openssh-clients (Fedora)"
+		case "windows":
+			return "Install the OpenSSH Client in Settings > System > Optional features, or run: Add-WindowsCapability -Online -Name OpenSSH.Client~~~~0.0.1.0"
+		default:
+			return "Install the OpenSSH client with your system's package manager"
+		}
+	}
+	return fmt.Sprintf("Install %s and make sure it is on your PATH", name)
+}
@@ -0,0 +1,70 @@
+package transfer
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRequireBinary(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	err := RequireBinary("scp")
+	var missing *MissingBinaryError
+	if !errors.As(err, &missing) || missing.Name != "scp" {
+		t.Fatalf("RequireBinary(scp) = %v, want a MissingBinaryError", err)
+	}
+	if !strings.HasPrefix(err.Error(), "scp not found in PATH. ") {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if err := CheckCommand(exec.Command("sh", "-c", "true")); err != nil {
+		t.Errorf("CheckCommand(sh) = %v, want nil", err)
+	}
+
+	err := CheckCommand(exec.Command("sshm-missing-binary"))
+	var missing *MissingBinaryError
+	if !errors.As(err, &missing) || missing.Name != "sshm-missing-binary" {
+		t.Errorf("CheckCommand(missing) = %v, want a MissingBinaryError", err)
+	}
+}
+
+func TestExecuteReportsMissingSCP(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	req := &TransferRequest{Host: "myserver", Direction: Upload, LocalPath: "file.txt", RemotePath: "/tmp/"}
+	result := req.ExecuteTo(nil, nil, nil)
+	var missing *MissingBinaryError
+	if result.Success || !errors.As(result.Error, &missing) || missing.Name != "scp" {
+		t.Errorf("Expected a missing scp error, got %+v", result)
+	}
+
+	rt := req.StartTransfer(nil)
+	if result := <-rt.Done(); !errors.As(result.Error, &missing) {
+		t.Errorf("Expected StartTransfer to report the missing scp, got %+v", result)
+	}
+}
+
+func TestGetInstallInstructions(t *testing.T) {
+	tests := []struct {
+		name, goos, want string
+	}{
+		{"ssh", "linux", "sudo apt install openssh-client"},
+		{"scp", "linux", "sudo dnf install openssh-clients"},
+		{"scp", "darwin", "brew install openssh"},
+		{"ssh", "windows", "OpenSSH.Client"},
+		{"ssh-copy-id", "freebsd", "package manager"},
+		{"mosh", "linux", "Install mosh"},
+	}
+	for _, tt := range tests {
+		if got := GetInstallInstructions(tt.name, tt.goos); !strings.Contains(got, tt.want) {
+			t.Errorf("GetInstallInstructions(%q, %q) = %q, want it to mention %q", tt.name, tt.goos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrChecksumUnavailable is returned when the remote host has no SHA-256 tool
+var ErrChecksumUnavailable = errors.New("sha256sum and shasum are not available on the remote host")
+
+// noChecksumToolMarker is printed by the checksum command when no tool is found
+const noChecksumToolMarker = "sshm:no-checksum-tool"
+
+// checksumCommand returns the shell command printing the SHA-256 of a remote
+// file with sha256sum (GNU, BusyBox) or shasum (macOS, BSD), whichever exists
+func checksumCommand(path string) string {
+	quoted := shellQuote(path)
+	return fmt.Sprintf("if command -v sha256sum >/dev/null 2>&1; then sha256sum -- %s; "+
+		"elif command -v shasum >/dev/null 2>&1; then shasum -a 256 -- %s; "+
+		"else echo %s; fi", quoted, quoted, noChecksumToolMarker)
+}
+
+// parseChecksumOutput extracts the digest from sha256sum or shasum output
+func parseChecksumOutput(output string) (string, error) {
+	output = strings.TrimSpace(output)
+	if strings.Contains(output, noChecksumToolMarker) {
+		return "", ErrChecksumUnavailable
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum output")
+	}
+	sum := strings.ToLower(strings.TrimPrefix(fields[0], "\\"))
+	if len(sum) != sha256.Size*2 {
+		return "", fmt.Errorf("unexpected checksum output: %s", output)
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return "", fmt.Errorf("unexpected checksum output: %s", output)
+	}
+	return sum, nil
+}
+
+// Checksum returns the hex encoded SHA-256 of a remote file
+func (s *SFTPSession) Checksum(path string) (string, error) {
+	output, err := s.output(checksumCommand(path))
+	if err != nil {
+		if IsTimeout(err) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+	return parseChecksumOutput(string(output))
+}
+
+// LocalChecksum returns the hex encoded SHA-256 of a local file
+func LocalChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ChecksumsMatch reports whether two hex encoded checksums are the same digest
+func ChecksumsMatch(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	return a != "" && strings.EqualFold(a, b)
+}
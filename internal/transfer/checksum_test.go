@@ -0,0 +1,118 @@
+package transfer
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestChecksumCommand(t *testing.T) {
+	cmd := checksumCommand("/srv/it's here.txt")
+
+	quoted := `'/srv/it'\''s here.txt'`
+	for _, part := range []string{
+		"command -v sha256sum",
+		"sha256sum -- " + quoted,
+		"command -v shasum",
+		"shasum -a 256 -- " + quoted,
+		"echo " + noChecksumToolMarker,
+	} {
+		if !strings.Contains(cmd, part) {
+			t.Errorf("Expected %q in checksum command %q", part, cmd)
+		}
+	}
+	if strings.Index(cmd, "sha256sum --") > strings.Index(cmd, "shasum -a") {
+		t.Error("Expected sha256sum to be preferred over shasum")
+	}
+}
+
+func TestParseChecksumOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+		wantErr  bool
+	}{
+		{"sha256sum", helloSHA256 + "  /srv/hello.txt\n", helloSHA256, false},
+		{"shasum", helloSHA256 + "  /srv/hello.txt\n", helloSHA256, false},
+		{"escaped name", `\` + helloSHA256 + `  /srv/a\nb`, helloSHA256, false},
+		{"uppercase", strings.ToUpper(helloSHA256) + "  x", helloSHA256, false},
+		{"empty", "", "", true},
+		{"not a digest", "sha256sum: /srv/x: Permission denied", "", true},
+	}
+
+	for _, tt := range tests {
+		sum, err := parseChecksumOutput(tt.output)
+		if (err != nil) != tt.wantErr || sum != tt.expected {
+			t.Errorf("%s: parseChecksumOutput() = (%q, %v), want %q (error %v)", tt.name, sum, err, tt.expected, tt.wantErr)
+		}
+	}
+
+	if _, err := parseChecksumOutput(noChecksumToolMarker + "\n"); !errors.Is(err, ErrChecksumUnavailable) {
+		t.Errorf("Expected ErrChecksumUnavailable without a checksum tool, got %v", err)
+	}
+}
+
+func TestSessionChecksum(t *testing.T) {
+	var ran string
+	s := &SFTPSession{runner: func(cmd string, w io.Writer) error {
+		ran = cmd
+		_, err := io.WriteString(w, helloSHA256+"  /srv/hello.txt\n")
+		return err
+	}}
+
+	sum, err := s.Checksum("/srv/hello.txt")
+	if err != nil || sum != helloSHA256 {
+		t.Fatalf("Checksum() = (%q, %v), want %q", sum, err, helloSHA256)
+	}
+	if ran != checksumCommand("/srv/hello.txt") {
+		t.Errorf("Unexpected command %q", ran)
+	}
+
+	missing := &SFTPSession{runner: func(cmd string, w io.Writer) error {
+		_, err := io.WriteString(w, noChecksumToolMarker+"\n")
+		return err
+	}}
+	if _, err := missing.Checksum("/srv/hello.txt"); !errors.Is(err, ErrChecksumUnavailable) {
+		t.Errorf("Expected ErrChecksumUnavailable, got %v", err)
+	}
+}
+
+func TestLocalChecksum(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := LocalChecksum(path)
+	if err != nil || sum != helloSHA256 {
+		t.Errorf("LocalChecksum() = (%q, %v), want %q", sum, err, helloSHA256)
+	}
+	if _, err := LocalChecksum(dir); err == nil {
+		t.Error("Expected an error for a directory")
+	}
+}
+
+func TestChecksumsMatch(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{helloSHA256, helloSHA256, true},
+		{helloSHA256, strings.ToUpper(helloSHA256) + "\n", true},
+		{helloSHA256, strings.Repeat("0", 64), false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := ChecksumsMatch(tt.a, tt.b); got != tt.expected {
+			t.Errorf("ChecksumsMatch(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
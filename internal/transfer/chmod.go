@@ -0,0 +1,59 @@
+package transfer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// octalModeRegex matches octal modes such as 755 or 0644
+var octalModeRegex = regexp.MustCompile(`^[0-7]?[0-7]{3}$`)
+
+// symbolicModeRegex matches symbolic modes such as +x, u+x or go-w,a+r
+var symbolicModeRegex = regexp.MustCompile(`^[ugoa]*[-+=][rwxXst]*(,[ugoa]*[-+=][rwxXst]*)*$`)
+
+// ValidateChmodMode checks a mode given to chmod: octal such as 755 or 0644,
+// or symbolic such as +x, u+x or go-w
+func ValidateChmodMode(mode string) error {
+	if octalModeRegex.MatchString(mode) || symbolicModeRegex.MatchString(mode) {
+		return nil
+	}
+	return fmt.Errorf("invalid mode %q: use octal such as 755 or symbolic such as +x or go-w", mode)
+}
+
+// chmodCommand returns the shell command changing the mode of a remote path.
+// "--" keeps a mode such as -x from being read as an option.
+func chmodCommand(path, mode string, recursive bool) string {
+	flags := ""
+	if recursive {
+		flags = "-R "
+	}
+	return fmt.Sprintf("chmod %s-- %s %s 2>&1", flags, mode, shellQuote(path))
+}
+
+// Chmod changes the mode of a remote path with chmod, through every file
+// below it when recursive is set
+func (s *SFTPSession) Chmod(path, mode string, recursive bool) error {
+	if err := ValidateChmodMode(mode); err != nil {
+		return err
+	}
+	if strings.HasPrefix(path, "~") {
+		home, err := s.GetHomeDirectory()
+		if err != nil {
+			return err
+		}
+		path = strings.Replace(path, "~", home, 1)
+	}
+
+	output, err := s.output(chmodCommand(path, mode, recursive))
+	if err != nil {
+		if IsTimeout(err) {
+			return err
+		}
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("chmod failed: %s", msg)
+		}
+		return fmt.Errorf("chmod failed: %w", err)
+	}
+	return nil
+}
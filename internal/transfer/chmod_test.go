@@ -0,0 +1,64 @@
+package transfer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestValidateChmodMode(t *testing.T) {
+	for _, mode := range []string{"755", "0644", "1777", "+x", "-x", "u+x", "go-w", "a=r", "u+rwx,g-w,o=", "+X"} {
+		if err := ValidateChmodMode(mode); err != nil {
+			t.Errorf("ValidateChmodMode(%q) = %v, want nil", mode, err)
+		}
+	}
+	for _, mode := range []string{"", "75", "888", "07555", "x", "+y", "u+x;rm -rf /", "755 /etc", "--reference=/etc/passwd"} {
+		if err := ValidateChmodMode(mode); err == nil {
+			t.Errorf("ValidateChmodMode(%q) = nil, want an error", mode)
+		}
+	}
+}
+
+func TestChmodCommand(t *testing.T) {
+	tests := []struct {
+		path      string
+		mode      string
+		recursive bool
+		want      string
+	}{
+		{"/srv/deploy.sh", "+x", false, "chmod -- +x '/srv/deploy.sh' 2>&1"},
+		{"/srv/app", "755", true, "chmod -R -- 755 '/srv/app' 2>&1"},
+		{"/srv/it's.sh", "-x", false, `chmod -- -x '/srv/it'\''s.sh' 2>&1`},
+	}
+	for _, tt := range tests {
+		if got := chmodCommand(tt.path, tt.mode, tt.recursive); got != tt.want {
+			t.Errorf("chmodCommand(%q, %q, %v) = %q, want %q", tt.path, tt.mode, tt.recursive, got, tt.want)
+		}
+	}
+}
+
+func TestSessionChmod(t *testing.T) {
+	var ran string
+	s := &SFTPSession{runner: func(cmd string, w io.Writer) error {
+		ran = cmd
+		return nil
+	}}
+	if err := s.Chmod("/srv/deploy.sh", "+x", false); err != nil || ran != chmodCommand("/srv/deploy.sh", "+x", false) {
+		t.Errorf("Chmod() = %v, ran %q", err, ran)
+	}
+
+	ran = ""
+	if err := s.Chmod("/srv/deploy.sh", "+x; reboot", false); err == nil || ran != "" {
+		t.Errorf("Expected an invalid mode to be rejected before running anything, ran %q", ran)
+	}
+
+	denied := &SFTPSession{runner: func(cmd string, w io.Writer) error {
+		io.WriteString(w, "chmod: changing permissions of '/etc/passwd': Operation not permitted\n")
+		return errors.New("Process exited with status 1")
+	}}
+	err := denied.Chmod("/etc/passwd", "644", false)
+	if err == nil || !strings.Contains(err.Error(), "Operation not permitted") {
+		t.Errorf("Expected chmod's message in the error, got %v", err)
+	}
+}
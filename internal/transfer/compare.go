@@ -0,0 +1,107 @@
+package transfer
+
+import (
+	"sort"
+	"time"
+)
+
+// ChangedEntry is a name found in both directories of a comparison whose
+// type, size or modification time differ
+type ChangedEntry struct {
+	Remote RemoteFile
+	Local  FileInfo
+}
+
+// DirComparison is what differs between a remote and a local directory,
+// each list sorted by name
+type DirComparison struct {
+	RemotePath string
+	LocalPath  string
+	RemoteOnly []RemoteFile   // Missing from the local directory
+	LocalOnly  []FileInfo     // Missing from the remote directory
+	Changed    []ChangedEntry // On both sides, but different
+	Same       int            // Entries that look identical on both sides
+}
+
+// Identical reports whether both directories hold the same entries
+func (c *DirComparison) Identical() bool {
+	return len(c.RemoteOnly) == 0 && len(c.LocalOnly) == 0 && len(c.Changed) == 0
+}
+
+// Differences returns how many entries differ
+func (c *DirComparison) Differences() int {
+	return len(c.RemoteOnly) + len(c.LocalOnly) + len(c.Changed)
+}
+
+// CompareListings compares the entries of a remote and a local directory by
+// name. Files differ in size or in modification time, to the second as SFTP
+// reports it; directories only when the other side is a file.
+func CompareListings(remote []RemoteFile, local []FileInfo) *DirComparison {
+	c := &DirComparison{}
+
+	locals := make(map[string]FileInfo, len(local))
+	for _, f := range local {
+		locals[f.Name] = f
+	}
+
+	seen := make(map[string]bool, len(remote))
+	for _, r := range remote {
+		if r.Name == ".." || r.Name == "." {
+			continue
+		}
+		seen[r.Name] = true
+
+		l, ok := locals[r.Name]
+		switch {
+		case !ok:
+			c.RemoteOnly = append(c.RemoteOnly, r)
+		case entriesDiffer(r, l):
+			c.Changed = append(c.Changed, ChangedEntry{Remote: r, Local: l})
+		default:
+			c.Same++
+		}
+	}
+	for _, l := range local {
+		if !seen[l.Name] {
+			c.LocalOnly = append(c.LocalOnly, l)
+		}
+	}
+
+	sort.Slice(c.RemoteOnly, func(i, j int) bool { return c.RemoteOnly[i].Name < c.RemoteOnly[j].Name })
+	sort.Slice(c.LocalOnly, func(i, j int) bool { return c.LocalOnly[i].Name < c.LocalOnly[j].Name })
+	sort.Slice(c.Changed, func(i, j int) bool { return c.Changed[i].Remote.Name < c.Changed[j].Remote.Name })
+	return c
+}
+
+// entriesDiffer reports whether the remote and local entries of a name differ
+func entriesDiffer(r RemoteFile, l FileInfo) bool {
+	if r.IsDir != l.IsDir {
+		return true
+	}
+	if r.IsDir {
+		return false
+	}
+	return r.Size != l.Size || !r.ModTime.Truncate(time.Second).Equal(l.ModTime.Truncate(time.Second))
+}
+
+// CompareDirectories lists remotePath over SFTP and the local directory
+// localPath, and compares their entries. Subdirectories are not descended.
+func (s *SFTPSession) CompareDirectories(remotePath, localPath string) (*DirComparison, error) {
+	expanded, err := ExpandPath(localPath)
+	if err != nil {
+		return nil, err
+	}
+	local, err := GetLocalFiles(expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := s.ListDirectory(remotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	c := CompareListings(remote, local)
+	c.RemotePath, c.LocalPath = remotePath, localPath
+	return c, nil
+}
@@ -0,0 +1,93 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompareListings(t *testing.T) {
+	modified := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	remote := []RemoteFile{
+		{Name: "..", IsDir: true},
+		{Name: "same.txt", Size: 10, ModTime: modified},
+		{Name: "bigger.log", Size: 200, ModTime: modified},
+		{Name: "touched.conf", Size: 5, ModTime: modified.Add(time.Hour)},
+		{Name: "only-remote.tar.gz", Size: 1000, ModTime: modified},
+		{Name: "logs", IsDir: true, ModTime: modified},
+		{Name: "was-dir", IsDir: true},
+	}
+	local := []FileInfo{
+		{Name: "same.txt", Size: 10, ModTime: modified.Add(300 * time.Millisecond)},
+		{Name: "bigger.log", Size: 150, ModTime: modified},
+		{Name: "touched.conf", Size: 5, ModTime: modified},
+		{Name: "logs", IsDir: true, ModTime: modified.Add(time.Hour)},
+		{Name: "was-dir", Size: 3},
+		{Name: "only-local.md", Size: 42},
+		{Name: ".env", Size: 7},
+	}
+
+	c := CompareListings(remote, local)
+
+	names := func(files []RemoteFile) []string {
+		var out []string
+		for _, f := range files {
+			out = append(out, f.Name)
+		}
+		return out
+	}
+	if got := names(c.RemoteOnly); !reflect.DeepEqual(got, []string{"only-remote.tar.gz"}) {
+		t.Errorf("RemoteOnly = %v", got)
+	}
+
+	var localOnly []string
+	for _, f := range c.LocalOnly {
+		localOnly = append(localOnly, f.Name)
+	}
+	if !reflect.DeepEqual(localOnly, []string{".env", "only-local.md"}) {
+		t.Errorf("LocalOnly = %v", localOnly)
+	}
+
+	var changed []string
+	for _, e := range c.Changed {
+		changed = append(changed, e.Remote.Name)
+	}
+	if !reflect.DeepEqual(changed, []string{"bigger.log", "touched.conf", "was-dir"}) {
+		t.Errorf("Changed = %v", changed)
+	}
+
+	// same.txt differs below a second, logs only in its directory mtime
+	if c.Same != 2 {
+		t.Errorf("Same = %d, want 2", c.Same)
+	}
+	if c.Identical() || c.Differences() != 6 {
+		t.Errorf("Expected 6 differences, got %d", c.Differences())
+	}
+
+	if empty := CompareListings(nil, nil); !empty.Identical() || empty.Same != 0 {
+		t.Errorf("Expected two empty directories to be identical, got %+v", empty)
+	}
+}
+
+func TestGetLocalFilesModTime(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(file, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := GetLocalFiles(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("GetLocalFiles() = %v, %v", files, err)
+	}
+	if !files[0].ModTime.Equal(modified) || files[0].Size != 4 {
+		t.Errorf("Expected the size and modification time, got %+v", files[0])
+	}
+}
@@ -0,0 +1,51 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// MaxTextContentSize is the largest remote file read into memory as text
+const MaxTextContentSize = 1 << 20 // 1 MiB
+
+// binarySniffLen is how many leading bytes are inspected for binary detection
+const binarySniffLen = 8000
+
+// IsBinaryContent reports whether data looks like a binary file.
+// Like git, a NUL byte in the first few KB means binary; otherwise the sniffed
+// prefix must be valid UTF-8.
+func IsBinaryContent(data []byte) bool {
+	sniff := data
+	if len(sniff) > binarySniffLen {
+		sniff = sniff[:binarySniffLen]
+		// Ignore a multi-byte character cut in half by the sniff window
+		for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(sniff); i++ {
+			sniff = sniff[:len(sniff)-1]
+		}
+	}
+
+	if bytes.IndexByte(sniff, 0) >= 0 {
+		return true
+	}
+	return !utf8.Valid(sniff)
+}
+
+// CheckTextSize rejects files too large to load as text, before reading them
+func CheckTextSize(size int64) error {
+	if size > MaxTextContentSize {
+		return fmt.Errorf("file is too large (%d bytes, limit is %d)", size, MaxTextContentSize)
+	}
+	return nil
+}
+
+// CheckTextContent validates data read from a remote file before it is used as text
+func CheckTextContent(data []byte) error {
+	if err := CheckTextSize(int64(len(data))); err != nil {
+		return err
+	}
+	if IsBinaryContent(data) {
+		return fmt.Errorf("file appears to be binary")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package transfer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIsBinaryContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected bool
+	}{
+		{"Empty", []byte{}, false},
+		{"Plain text", []byte("server {\n  listen 80;\n}\n"), false},
+		{"UTF-8 text", []byte("héllo wörld ✓\n"), false},
+		{"NUL byte", []byte("ELF\x00\x01\x02"), true},
+		{"Invalid UTF-8", []byte{0xff, 0xfe, 'a', 'b'}, true},
+		{"NUL after sniff window", append(bytes.Repeat([]byte("a"), binarySniffLen+10), 0), false},
+		{
+			// A 3-byte character straddling the sniff boundary is still text
+			"Rune cut at sniff window",
+			append(bytes.Repeat([]byte("a"), binarySniffLen-1), []byte("✓✓")...),
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinaryContent(tt.data); got != tt.expected {
+				t.Errorf("IsBinaryContent() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckTextSize(t *testing.T) {
+	if err := CheckTextSize(0); err != nil {
+		t.Errorf("Expected empty file to be allowed, got %v", err)
+	}
+	if err := CheckTextSize(MaxTextContentSize); err != nil {
+		t.Errorf("Expected file at the limit to be allowed, got %v", err)
+	}
+	if err := CheckTextSize(MaxTextContentSize + 1); err == nil {
+		t.Error("Expected file over the limit to be rejected")
+	}
+}
+
+func TestCheckTextContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"Small text", []byte("key=value\n"), ""},
+		{"Binary", []byte{0x7f, 'E', 'L', 'F', 0, 0}, "binary"},
+		{"Too large", []byte(strings.Repeat("a", MaxTextContentSize+1)), "too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTextContent(tt.data)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package transfer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EstimateLocalSize returns the total size and the number of regular files
+// of a local file or directory tree. Unreadable subdirectories are skipped.
+func EstimateLocalSize(path string) (size int64, fileCount int, err error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, 0, err
+	}
+
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == path {
+				return err
+			}
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size += info.Size()
+		fileCount++
+		return nil
+	})
+	return size, fileCount, err
+}
+
+// EstimateRemoteSize returns the total size and the number of regular files
+// of a remote file or directory tree, from a WalkDir of it
+func (s *SFTPSession) EstimateRemoteSize(path string) (int64, int, error) {
+	walk, err := s.WalkDir(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return walk.TotalSize(), walk.FileCount(), nil
+}
+
+// FormatSizeEstimate describes the size of a transfer, such as
+// "About 1.3GB in 4,210 files"
+func FormatSizeEstimate(size int64, fileCount int) string {
+	files := "files"
+	if fileCount == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("About %s in %s %s", FormatByteSize(size), formatCount(fileCount), files)
+}
+
+// formatCount writes n with thousands separators, such as "4,210"
+func formatCount(n int) string {
+	digits := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
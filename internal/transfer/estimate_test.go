@@ -0,0 +1,64 @@
+package transfer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEstimateLocalSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub", "deeper"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{"a.txt": 100, "sub/b.bin": 2048, "sub/deeper/c": 0} {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	size, files, err := EstimateLocalSize(dir)
+	if err != nil || size != 2148 || files != 3 {
+		t.Errorf("EstimateLocalSize(dir) = (%d, %d, %v), want (2148, 3, nil)", size, files, err)
+	}
+
+	size, files, err = EstimateLocalSize(filepath.Join(dir, "a.txt"))
+	if err != nil || size != 100 || files != 1 {
+		t.Errorf("EstimateLocalSize(file) = (%d, %d, %v), want (100, 1, nil)", size, files, err)
+	}
+
+	if _, _, err := EstimateLocalSize(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
+
+func TestSessionEstimateRemoteSize(t *testing.T) {
+	s := &SFTPSession{runner: func(cmd string, w io.Writer) error {
+		_, err := io.WriteString(w, sampleWalk)
+		return err
+	}}
+
+	size, files, err := s.EstimateRemoteSize("/srv/app")
+	if err != nil || size != 2900 || files != 4 {
+		t.Fatalf("EstimateRemoteSize() = (%d, %d, %v), want (2900, 4, nil)", size, files, err)
+	}
+}
+
+func TestFormatSizeEstimate(t *testing.T) {
+	tests := []struct {
+		size  int64
+		files int
+		want  string
+	}{
+		{1395864371, 4210, "About 1.3GB in 4,210 files"},
+		{512, 1, "About 512B in 1 file"},
+		{1536, 0, "About 1.5KB in 0 files"},
+		{5 << 40, 1234567, "About 5.0TB in 1,234,567 files"},
+	}
+	for _, tt := range tests {
+		if got := FormatSizeEstimate(tt.size, tt.files); got != tt.want {
+			t.Errorf("FormatSizeEstimate(%d, %d) = %q, want %q", tt.size, tt.files, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// RunRemoteCommand runs a command on a host of the SSH config over a new SSH
+// connection, dialed as NewSFTPSession does. The output of the command is
+// streamed to stdout and stderr while it runs, and stdin, when not nil, is
+// fed to it. A command running longer than timeout, when positive, is killed
+// with ErrCommandTimeout. The exit status of the remote command is returned;
+// err is only set when the command could not be run or ended without a status.
+func RunRemoteCommand(host, configFile, command string, timeout time.Duration, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	client, _, err := dialHost(host, configFile)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open a session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+	if stdin != nil {
+		// Copied outside the session so that a terminal left open on stdin
+		// does not keep Wait from returning once the command exits
+		pipe, err := session.StdinPipe()
+		if err != nil {
+			return 0, fmt.Errorf("failed to open the input of the command: %w", err)
+		}
+		go func() {
+			_, _ = io.Copy(pipe, stdin)
+			pipe.Close()
+		}()
+	}
+
+	if err := session.Start(command); err != nil {
+		return 0, fmt.Errorf("failed to run the command: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case err := <-done:
+		return exitStatus(err)
+	case <-expired:
+		// Ask the remote side to stop, then tear the connection down
+		_ = session.Signal(ssh.SIGKILL)
+		return 0, fmt.Errorf("%w after %s", ErrCommandTimeout, timeout)
+	}
+}
+
+// exitStatus turns the result of a remote command into its exit status
+func exitStatus(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+	var missing *ssh.ExitMissingError
+	if errors.As(err, &missing) {
+		return 0, errors.New("the command ended without an exit status, the connection may have been lost")
+	}
+	return 0, fmt.Errorf("failed to run the command: %w", err)
+}
+
+// DefaultExecConcurrency is how many hosts run a command at once by default
+const DefaultExecConcurrency = 10
+
+// ExecResult is the outcome of a command run on one host of several
+type ExecResult struct {
+	Host       string
+	ExitStatus int
+	Err        error // The command could not run, or was killed by the timeout
+}
+
+// Succeeded reports whether the command ran and exited with status 0
+func (r ExecResult) Succeeded() bool {
+	return r.Err == nil && r.ExitStatus == 0
+}
+
+// RunExecFanOut runs a command on every host with at most concurrency hosts
+// in flight. Every host is attempted; results keep the order of hosts.
+func RunExecFanOut(hosts []string, concurrency int, run func(host string) ExecResult) []ExecResult {
+	if concurrency <= 0 {
+		concurrency = DefaultExecConcurrency
+	}
+
+	results := make([]ExecResult, len(hosts))
+	runConcurrently(len(hosts), concurrency, func(i int) {
+		results[i] = run(hosts[i])
+		results[i].Host = hosts[i]
+	})
+	return results
+}
+
+// FormatExecSummary renders one line per host with the outcome of its
+// command, host names padded so statuses line up
+func FormatExecSummary(results []ExecResult) []string {
+	width := len("HOST")
+	for _, r := range results {
+		if len(r.Host) > width {
+			width = len(r.Host)
+		}
+	}
+
+	lines := []string{fmt.Sprintf("%-*s  %s", width, "HOST", "RESULT")}
+	for _, r := range results {
+		status := "ok"
+		switch {
+		case r.Err != nil:
+			status = "failed: " + r.Err.Error()
+		case r.ExitStatus != 0:
+			status = fmt.Sprintf("exit %d", r.ExitStatus)
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  %s", width, r.Host, status))
+	}
+	return lines
+}
+
+// PrefixWriter writes every line written to it to w behind a prefix, such
+// as the name of the host it came from. Writers sharing a mutex never
+// interleave within a line. Call Flush to write a last line without newline.
+type PrefixWriter struct {
+	w       io.Writer
+	mu      *sync.Mutex
+	prefix  string
+	partial []byte
+}
+
+// NewPrefixWriter creates a PrefixWriter writing to w while holding mu
+func NewPrefixWriter(w io.Writer, mu *sync.Mutex, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, mu: mu, prefix: prefix}
+}
+
+func (p *PrefixWriter) Write(b []byte) (int, error) {
+	p.partial = append(p.partial, b...)
+	var out bytes.Buffer
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+		out.WriteString(p.prefix)
+		out.Write(p.partial[:i+1])
+		p.partial = p.partial[i+1:]
+	}
+	if out.Len() > 0 {
+		if err := p.emit(out.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush writes the last line when it did not end with a newline
+func (p *PrefixWriter) Flush() error {
+	if len(p.partial) == 0 {
+		return nil
+	}
+	line := append([]byte(p.prefix), p.partial...)
+	p.partial = nil
+	return p.emit(append(line, '\n'))
+}
+
+func (p *PrefixWriter) emit(b []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.w.Write(b)
+	return err
+}
@@ -0,0 +1,98 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestExitStatus(t *testing.T) {
+	if status, err := exitStatus(nil); status != 0 || err != nil {
+		t.Errorf("exitStatus(nil) = %d, %v; want 0, nil", status, err)
+	}
+
+	_, err := exitStatus(&ssh.ExitMissingError{})
+	if err == nil || !strings.Contains(err.Error(), "without an exit status") {
+		t.Errorf("Expected a missing exit status to be an error, got %v", err)
+	}
+
+	_, err = exitStatus(errors.New("EOF"))
+	if err == nil || !strings.Contains(err.Error(), "failed to run the command") {
+		t.Errorf("Expected other failures to be wrapped, got %v", err)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	w := NewPrefixWriter(&out, &mu, "web1 | ")
+
+	io.WriteString(w, "load: 0.")
+	io.WriteString(w, "42\nusers: 3\nlast")
+	if out.String() != "web1 | load: 0.42\nweb1 | users: 3\n" {
+		t.Errorf("Expected complete lines only, got %q", out.String())
+	}
+
+	_ = w.Flush()
+	if !strings.HasSuffix(out.String(), "web1 | last\n") {
+		t.Errorf("Expected Flush to end the last line, got %q", out.String())
+	}
+	_ = w.Flush()
+	if strings.Count(out.String(), "last") != 1 {
+		t.Error("Expected a second Flush to write nothing")
+	}
+}
+
+func TestRunExecFanOut(t *testing.T) {
+	hosts := []string{"web1", "web2", "web3", "db1"}
+	var mu sync.Mutex
+	running, peak := 0, 0
+
+	results := RunExecFanOut(hosts, 2, func(host string) ExecResult {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if host == "web2" {
+			return ExecResult{ExitStatus: 2}
+		}
+		return ExecResult{}
+	})
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 hosts at once, got %d", peak)
+	}
+	for i, r := range results {
+		if r.Host != hosts[i] {
+			t.Errorf("Expected results in host order, got %s at %d", r.Host, i)
+		}
+	}
+	if results[1].Succeeded() || !results[0].Succeeded() {
+		t.Error("Expected only web2 to fail")
+	}
+
+	results[3].Err = errors.New("failed to connect: connection refused")
+	expected := []string{
+		"HOST  RESULT",
+		"web1  ok",
+		"web2  exit 2",
+		"web3  ok",
+		"db1   failed: failed to connect: connection refused",
+	}
+	if got := FormatExecSummary(results); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("FormatExecSummary() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
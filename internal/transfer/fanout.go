@@ -0,0 +1,131 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultFanOutConcurrency is how many hosts are uploaded to at once by default
+const DefaultFanOutConcurrency = 4
+
+// FanOutResult is the outcome of one host's transfer in a fan-out upload
+type FanOutResult struct {
+	Host   string
+	Result *TransferResult
+}
+
+// BuildFanOutRequests builds one upload request per host for the same local file
+func BuildFanOutRequests(hosts []string, localPath, remotePath, configFile string, recursive bool, extraArgs []string) []*TransferRequest {
+	requests := make([]*TransferRequest, 0, len(hosts))
+	for _, host := range hosts {
+		requests = append(requests, &TransferRequest{
+			Host:       host,
+			Direction:  Upload,
+			LocalPath:  localPath,
+			RemotePath: remotePath,
+			Recursive:  recursive,
+			ConfigFile: configFile,
+			ExtraArgs:  extraArgs,
+		})
+	}
+	return requests
+}
+
+// RunFanOut runs the requests with at most concurrency transfers in flight.
+// Every request is attempted regardless of failures; results keep the request order.
+func RunFanOut(requests []*TransferRequest, concurrency int, run func(*TransferRequest) *TransferResult) []FanOutResult {
+	if concurrency <= 0 {
+		concurrency = DefaultFanOutConcurrency
+	}
+
+	results := make([]FanOutResult, len(requests))
+	runConcurrently(len(requests), concurrency, func(i int) {
+		results[i] = FanOutResult{Host: requests[i].Host, Result: run(requests[i])}
+	})
+	return results
+}
+
+// runConcurrently calls job for 0 to n-1 with at most concurrency calls in
+// flight, returning once every call has
+func runConcurrently(n, concurrency int, job func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				job(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// SummarizeFanOut counts successful and failed transfers
+func SummarizeFanOut(results []FanOutResult) (succeeded, failed int) {
+	for _, r := range results {
+		if r.Result != nil && r.Result.Success {
+			succeeded++
+		} else {
+			failed++
+		}
+	}
+	return succeeded, failed
+}
+
+// ExecuteBatch runs the transfer without a terminal: no prompts, no progress
+// output, and scp's error output captured into the returned error.
+// Used when several transfers run concurrently.
+func (r *TransferRequest) ExecuteBatch() *TransferResult {
+	if err := ValidateSCPExtraArgs(r.ExtraArgs); err != nil {
+		return &TransferResult{Success: false, Error: err}
+	}
+
+	args := append([]string{"-q", "-o", "BatchMode=yes"}, r.scpArgs()...)
+	cmd := scpCommand(args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return &TransferResult{Success: false, Error: err}
+	}
+
+	return &TransferResult{Success: true}
+}
+
+// FormatFanOutSummary renders one line per host with the outcome of its
+// transfer, host names padded so statuses line up
+func FormatFanOutSummary(results []FanOutResult) []string {
+	width := len("HOST")
+	for _, r := range results {
+		if len(r.Host) > width {
+			width = len(r.Host)
+		}
+	}
+
+	lines := []string{fmt.Sprintf("%-*s  %s", width, "HOST", "RESULT")}
+	for _, r := range results {
+		status := "ok"
+		if r.Result == nil || !r.Result.Success {
+			status = "failed"
+			if r.Result != nil && r.Result.Error != nil {
+				status += ": " + r.Result.Error.Error()
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  %s", width, r.Host, status))
+	}
+	return lines
+}
@@ -0,0 +1,143 @@
+package transfer
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestBuildFanOutRequests(t *testing.T) {
+	hosts := []string{"web1", "web2", "db1"}
+	extra := []string{"-O"}
+
+	requests := BuildFanOutRequests(hosts, "./nginx.conf", "/etc/nginx/", "/tmp/ssh_config", false, extra)
+
+	if len(requests) != len(hosts) {
+		t.Fatalf("Expected %d requests, got %d", len(hosts), len(requests))
+	}
+
+	for i, req := range requests {
+		if req.Host != hosts[i] {
+			t.Errorf("Request %d: expected host %s, got %s", i, hosts[i], req.Host)
+		}
+		if req.Direction != Upload {
+			t.Errorf("Request %d: expected Upload direction", i)
+		}
+		if req.LocalPath != "./nginx.conf" || req.RemotePath != "/etc/nginx/" {
+			t.Errorf("Request %d: unexpected paths %s -> %s", i, req.LocalPath, req.RemotePath)
+		}
+		if req.ConfigFile != "/tmp/ssh_config" {
+			t.Errorf("Request %d: expected config file to be set", i)
+		}
+		if !reflect.DeepEqual(req.ExtraArgs, extra) {
+			t.Errorf("Request %d: expected extra args %v, got %v", i, extra, req.ExtraArgs)
+		}
+	}
+
+	// Each request targets its own host
+	args := requests[1].BuildSCPCommand().Args
+	if args[len(args)-1] != "web2:/etc/nginx/" {
+		t.Errorf("Expected destination web2:/etc/nginx/, got %s", args[len(args)-1])
+	}
+}
+
+func TestRunFanOutAggregatesResults(t *testing.T) {
+	hosts := []string{"ok1", "bad1", "ok2", "bad2", "ok3"}
+	requests := BuildFanOutRequests(hosts, "./file", "/tmp/", "", false, nil)
+
+	run := func(req *TransferRequest) *TransferResult {
+		if req.Host[:3] == "bad" {
+			return &TransferResult{Success: false, Error: fmt.Errorf("connection refused")}
+		}
+		return &TransferResult{Success: true}
+	}
+
+	results := RunFanOut(requests, 2, run)
+
+	if len(results) != len(hosts) {
+		t.Fatalf("Expected %d results, got %d", len(hosts), len(results))
+	}
+
+	// Results keep request order and every host is attempted
+	for i, r := range results {
+		if r.Host != hosts[i] {
+			t.Errorf("Result %d: expected host %s, got %s", i, hosts[i], r.Host)
+		}
+	}
+
+	succeeded, failed := SummarizeFanOut(results)
+	if succeeded != 3 || failed != 2 {
+		t.Errorf("Expected 3 succeeded / 2 failed, got %d / %d", succeeded, failed)
+	}
+}
+
+func TestRunFanOutRespectsConcurrency(t *testing.T) {
+	hosts := make([]string, 20)
+	for i := range hosts {
+		hosts[i] = fmt.Sprintf("host%d", i)
+	}
+	requests := BuildFanOutRequests(hosts, "./file", "/tmp/", "", false, nil)
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	release := make(chan struct{})
+
+	run := func(req *TransferRequest) *TransferResult {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return &TransferResult{Success: true}
+	}
+
+	done := make(chan []FanOutResult)
+	go func() { done <- RunFanOut(requests, 3, run) }()
+
+	// Let transfers complete one at a time
+	for range hosts {
+		release <- struct{}{}
+	}
+	results := <-done
+
+	if maxInFlight > 3 {
+		t.Errorf("Expected at most 3 concurrent transfers, got %d", maxInFlight)
+	}
+	if succeeded, _ := SummarizeFanOut(results); succeeded != len(hosts) {
+		t.Errorf("Expected all %d transfers to succeed, got %d", len(hosts), succeeded)
+	}
+}
+
+func TestSummarizeFanOutNilResult(t *testing.T) {
+	results := []FanOutResult{{Host: "a", Result: nil}, {Host: "b", Result: &TransferResult{Success: true}}}
+	succeeded, failed := SummarizeFanOut(results)
+	if succeeded != 1 || failed != 1 {
+		t.Errorf("Expected a nil result to count as failed, got %d / %d", succeeded, failed)
+	}
+}
+
+func TestFormatFanOutSummary(t *testing.T) {
+	results := []FanOutResult{
+		{Host: "web1", Result: &TransferResult{Success: true}},
+		{Host: "database", Result: &TransferResult{Success: false, Error: fmt.Errorf("connection refused")}},
+		{Host: "web2", Result: nil},
+	}
+
+	want := []string{
+		"HOST      RESULT",
+		"web1      ok",
+		"database  failed: connection refused",
+		"web2      failed",
+	}
+	if got := FormatFanOutSummary(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatFanOutSummary() = %q, want %q", got, want)
+	}
+}
@@ -69,6 +69,12 @@ func IsPickerAvailable() bool {
 	}
 }
 
+// UseNativePicker reports whether local paths should be picked with the native
+// OS dialog rather than the TUI browser, honoring the PreferTUIPicker setting
+func UseNativePicker(preferTUI bool) bool {
+	return !preferTUI && IsPickerAvailable()
+}
+
 // macOS implementation using osascript
 func openMacOSPicker(mode PickerMode, title string, startDir string) (*PickerResult, error) {
 	var script string
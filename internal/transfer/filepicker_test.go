@@ -0,0 +1,15 @@
+package transfer
+
+import "testing"
+
+func TestUseNativePicker(t *testing.T) {
+	// Preferring the TUI browser always disables the native dialog
+	if UseNativePicker(true) {
+		t.Error("Expected native picker to be disabled when the TUI browser is preferred")
+	}
+
+	// Otherwise the native dialog is used whenever it is available
+	if got := UseNativePicker(false); got != IsPickerAvailable() {
+		t.Errorf("UseNativePicker(false) = %v, want %v", got, IsPickerAvailable())
+	}
+}
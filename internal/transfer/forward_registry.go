@@ -0,0 +1,241 @@
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// BackgroundForward is an ssh tunnel left running in the background
+type BackgroundForward struct {
+	ID          int       `json:"id"`
+	Host        string    `json:"host"`
+	Forward     string    `json:"forward"` // Forwarding option, such as "-L 8080:localhost:80"
+	PID         int       `json:"pid"`
+	ControlPath string    `json:"control_path"`
+	ConfigFile  string    `json:"config_file,omitempty"` // SSH config the tunnel was started with
+	Started     time.Time `json:"started"`
+}
+
+// forwardRegistryData is the on-disk format of the forward registry
+type forwardRegistryData struct {
+	NextID   int                  `json:"next_id"`
+	Forwards []*BackgroundForward `json:"forwards"`
+}
+
+// ForwardRegistry tracks the tunnels started in the background, backed by
+// ~/.config/sshm/sshm_forwards.json
+type ForwardRegistry struct {
+	path string
+	dir  string // Holds the control sockets the tunnels are checked and stopped through
+}
+
+// NewForwardRegistry creates a registry in the sshm config directory
+func NewForwardRegistry() (*ForwardRegistry, error) {
+	configDir, err := config.GetSSHMConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ForwardRegistry{
+		path: filepath.Join(configDir, "sshm_forwards.json"),
+		dir:  filepath.Join(configDir, "fwd"),
+	}, nil
+}
+
+// load reads the registry. A missing file means no tunnel is tracked.
+func (r *ForwardRegistry) load() (*forwardRegistryData, error) {
+	data := &forwardRegistryData{NextID: 1}
+
+	raw, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", r.path, err)
+	}
+	if data.NextID < 1 {
+		data.NextID = 1
+	}
+	return data, nil
+}
+
+// save replaces the registry on disk, through a temporary file renamed over
+// it so that an interrupted write never loses the tunnels tracked
+func (r *ForwardRegistry) save(data *forwardRegistryData) error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return config.WriteFileAtomic(r.path, raw, 0600)
+}
+
+// NewControlPath returns the control socket path of the next tunnel
+func (r *ForwardRegistry) NewControlPath() (string, error) {
+	data, err := r.load()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return "", err
+	}
+	// Kept short: unix socket paths are limited to about 100 bytes
+	return filepath.Join(r.dir, strconv.Itoa(data.NextID)+".sock"), nil
+}
+
+// Add tracks a tunnel, giving it the next ID
+func (r *ForwardRegistry) Add(forward *BackgroundForward) error {
+	data, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	forward.ID = data.NextID
+	data.NextID++
+	data.Forwards = append(data.Forwards, forward)
+	return r.save(data)
+}
+
+// Active returns the tracked tunnels still running, forgetting those that
+// have ended since they were started
+func (r *ForwardRegistry) Active() ([]*BackgroundForward, error) {
+	data, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*BackgroundForward
+	for _, f := range data.Forwards {
+		if _, err := forwardControl(f.ControlPath, f.Host, f.ConfigFile, "check"); err == nil {
+			active = append(active, f)
+		} else {
+			os.Remove(f.ControlPath)
+		}
+	}
+	if len(active) != len(data.Forwards) {
+		data.Forwards = active
+		if err := r.save(data); err != nil {
+			return nil, err
+		}
+	}
+	return active, nil
+}
+
+// Stop ends the tunnel with the given ID and stops tracking it
+func (r *ForwardRegistry) Stop(id int) (*BackgroundForward, error) {
+	data, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, f := range data.Forwards {
+		if f.ID != id {
+			continue
+		}
+		// A tunnel that has ended already only needs to be forgotten
+		if _, err := forwardControl(f.ControlPath, f.Host, f.ConfigFile, "check"); err == nil {
+			if out, err := forwardControl(f.ControlPath, f.Host, f.ConfigFile, "exit"); err != nil {
+				return nil, fmt.Errorf("failed to stop the tunnel to %s: %s", f.Host, controlError(out, err))
+			}
+		}
+		os.Remove(f.ControlPath)
+		data.Forwards = append(data.Forwards[:i], data.Forwards[i+1:]...)
+		return f, r.save(data)
+	}
+	return nil, fmt.Errorf("no background tunnel with ID %d", id)
+}
+
+// RenameHost moves the tracked tunnels of a renamed host to its new name.
+// The tunnels are checked and stopped through their control socket, which
+// the rename does not affect.
+func (r *ForwardRegistry) RenameHost(oldName, newName string) error {
+	data, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	renamed := false
+	for _, f := range data.Forwards {
+		if f.Host == oldName {
+			f.Host = newName
+			renamed = true
+		}
+	}
+	if !renamed {
+		return nil
+	}
+	return r.save(data)
+}
+
+// BackgroundForwardCommand returns the ssh command starting a tunnel that
+// goes to the background once connected (-f -N). sshArgs are the forwarding
+// options and the host, as for a foreground tunnel. The control socket lets
+// ForwardPID find the process ssh forked into, and the registry check and
+// stop the tunnel.
+func BackgroundForwardCommand(controlPath string, sshArgs []string) *exec.Cmd {
+	args := []string{"-f", "-N",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ControlMaster=yes",
+		"-o", "ControlPath=" + controlPath,
+	}
+	return exec.Command("ssh", append(args, sshArgs...)...)
+}
+
+// masterPIDPattern matches the reply of ssh -O check
+var masterPIDPattern = regexp.MustCompile(`pid=(\d+)`)
+
+// forwardControl sends a control command, such as check or exit, to the
+// tunnel listening on controlPath and returns its reply (replaced in tests)
+var forwardControl = func(controlPath, host, configFile, command string) ([]byte, error) {
+	var args []string
+	if configFile != "" {
+		args = append(args, "-F", configFile)
+	}
+	args = append(args, "-S", controlPath, "-O", command, host)
+	return exec.Command("ssh", args...).CombinedOutput()
+}
+
+// controlError describes a failed control command by what ssh printed
+func controlError(out []byte, err error) string {
+	if msg := strings.TrimSpace(string(out)); msg != "" {
+		return msg
+	}
+	return err.Error()
+}
+
+// ForwardPID asks the tunnel listening on controlPath for its process ID
+func ForwardPID(controlPath, host, configFile string) (int, error) {
+	out, err := forwardControl(controlPath, host, configFile, "check")
+	if err != nil {
+		return 0, fmt.Errorf("the tunnel is not running: %w", err)
+	}
+	return parseMasterPID(string(out))
+}
+
+// parseMasterPID extracts the process ID from the reply of ssh -O check,
+// such as "Master running (pid=1234)"
+func parseMasterPID(out string) (int, error) {
+	match := masterPIDPattern.FindStringSubmatch(out)
+	if match == nil {
+		return 0, errors.New("ssh did not report the tunnel process")
+	}
+	return strconv.Atoi(match[1])
+}
@@ -0,0 +1,165 @@
+package transfer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeTunnels stands in for ssh -O: the tunnels running are those whose
+// control path is set
+func fakeTunnels(t *testing.T, running map[string]bool) *[]string {
+	t.Helper()
+	var commands []string
+	orig := forwardControl
+	forwardControl = func(controlPath, host, configFile, command string) ([]byte, error) {
+		commands = append(commands, command+" "+controlPath)
+		if !running[controlPath] {
+			return []byte("Control socket connect(" + controlPath + "): No such file or directory\n"), errors.New("exit status 255")
+		}
+		if command == "exit" {
+			delete(running, controlPath)
+			return []byte("Exit request sent.\n"), nil
+		}
+		return []byte("Master running (pid=4242)\n"), nil
+	}
+	t.Cleanup(func() { forwardControl = orig })
+	return &commands
+}
+
+func TestForwardRegistry(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	registry, err := NewForwardRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	controlPath, err := registry.NewControlPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if controlPath != filepath.Join(dir, "sshm", "fwd", "1.sock") {
+		t.Errorf("NewControlPath() = %q", controlPath)
+	}
+
+	running := map[string]bool{controlPath: true}
+	commands := fakeTunnels(t, running)
+
+	// The second tunnel has ended since it was tracked
+	web := &BackgroundForward{Host: "web", Forward: "-L 8080:localhost:80", PID: 4242, ControlPath: controlPath}
+	old := &BackgroundForward{Host: "db", Forward: "-D 1080", PID: 4343, ControlPath: filepath.Join(dir, "sshm", "fwd", "2.sock")}
+	for _, f := range []*BackgroundForward{web, old} {
+		if err := registry.Add(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if web.ID != 1 || old.ID != 2 {
+		t.Errorf("Expected IDs 1 and 2, got %d and %d", web.ID, old.ID)
+	}
+
+	active, err := registry.Active()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 || active[0].Host != "web" {
+		t.Fatalf("Expected only the running tunnel, got %+v", active)
+	}
+
+	if _, err := registry.Stop(2); err == nil {
+		t.Error("Expected the ended tunnel to be forgotten")
+	}
+	stopped, err := registry.Stop(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped.Host != "web" {
+		t.Errorf("Stop() returned %+v", stopped)
+	}
+	if running[controlPath] {
+		t.Error("Expected the tunnel to be sent an exit request")
+	}
+	if last := (*commands)[len(*commands)-1]; last != "exit "+controlPath {
+		t.Errorf("Expected the tunnel to be stopped with ssh -O exit, got %q", last)
+	}
+	if active, _ := registry.Active(); len(active) != 0 {
+		t.Errorf("Expected no tunnel left, got %+v", active)
+	}
+
+	// IDs are not reused once a tunnel is gone
+	next := &BackgroundForward{Host: "web"}
+	if err := registry.Add(next); err != nil {
+		t.Fatal(err)
+	}
+	if next.ID != 3 {
+		t.Errorf("Expected ID 3, got %d", next.ID)
+	}
+
+	// Saving leaves no temporary file behind
+	entries, err := os.ReadDir(filepath.Join(dir, "sshm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("Unexpected leftover %s", e.Name())
+		}
+	}
+}
+
+func TestForwardRegistryRenameHost(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	fakeTunnels(t, map[string]bool{"1.sock": true, "2.sock": true})
+
+	registry, err := NewForwardRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*BackgroundForward{
+		{Host: "web", Forward: "-L 8080:localhost:80", ControlPath: "1.sock"},
+		{Host: "db", Forward: "-D 1080", ControlPath: "2.sock"},
+	} {
+		if err := registry.Add(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := registry.RenameHost("web", "front"); err != nil {
+		t.Fatal(err)
+	}
+	active, err := registry.Active()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 2 || active[0].Host != "front" || active[0].ControlPath != "1.sock" || active[1].Host != "db" {
+		t.Errorf("Expected the tunnel of web under its new name, got %+v", active)
+	}
+}
+
+func TestBackgroundForwardCommand(t *testing.T) {
+	cmd := BackgroundForwardCommand("/tmp/1.sock", []string{"-F", "/tmp/cfg", "-L", "8080:localhost:80", "web"})
+	want := []string{"ssh", "-f", "-N",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ControlMaster=yes",
+		"-o", "ControlPath=/tmp/1.sock",
+		"-F", "/tmp/cfg", "-L", "8080:localhost:80", "web"}
+	if !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("BackgroundForwardCommand() = %q, want %q", cmd.Args, want)
+	}
+}
+
+func TestParseMasterPID(t *testing.T) {
+	if pid, err := parseMasterPID("Master running (pid=4242)\r\n"); err != nil || pid != 4242 {
+		t.Errorf("parseMasterPID() = %d, %v", pid, err)
+	}
+	if _, err := parseMasterPID("Control socket connect(/tmp/1.sock): No such file or directory"); err == nil {
+		t.Error("Expected an error without a PID")
+	}
+}
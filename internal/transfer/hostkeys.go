@@ -0,0 +1,236 @@
+package transfer
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// UnknownHostKeyError is returned when an SFTP session connects to a host
+// missing from known_hosts while StrictHostKeyChecking is ask. AcceptHostKey
+// records the key so the next session trusts it.
+type UnknownHostKeyError struct {
+	Host           string // Host as written to known_hosts, e.g. [host]:2222
+	Key            ssh.PublicKey
+	KnownHostsFile string // File the key is appended to
+}
+
+func (e *UnknownHostKeyError) Error() string {
+	return fmt.Sprintf("the authenticity of host %s can't be established (%s key fingerprint is %s)",
+		e.Host, e.Key.Type(), ssh.FingerprintSHA256(e.Key))
+}
+
+// HostKeyMismatchError is returned when a host presents another key than the
+// one in known_hosts. It is never accepted from sshm: the stale entry has to
+// be removed by hand, as with ssh.
+type HostKeyMismatchError struct {
+	Host string
+	Key  ssh.PublicKey
+	File string // known_hosts file holding the other key
+	Line int
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("host key for %s has changed (%s key fingerprint is %s, %s:%d has another one); someone could be eavesdropping, remove the old key with ssh-keygen -R if the change is expected",
+		e.Host, e.Key.Type(), ssh.FingerprintSHA256(e.Key), e.File, e.Line)
+}
+
+// hostKeySettings are the host key related options resolved for a host
+type hostKeySettings struct {
+	userFiles   []string // UserKnownHostsFile entries, ~ expanded
+	globalFiles []string // GlobalKnownHostsFile entries
+	strict      string   // StrictHostKeyChecking as printed by ssh -G
+	alias       string   // HostKeyAlias, looked up instead of the hostname
+}
+
+// defaultHostKeySettings returns the settings ssh uses without configuration
+func defaultHostKeySettings() hostKeySettings {
+	return hostKeySettings{
+		userFiles:   knownHostsFiles("~/.ssh/known_hosts ~/.ssh/known_hosts2"),
+		globalFiles: knownHostsFiles("/etc/ssh/ssh_known_hosts /etc/ssh/ssh_known_hosts2"),
+		strict:      "ask",
+	}
+}
+
+// knownHostsFiles splits a space separated list of known_hosts files
+func knownHostsFiles(value string) []string {
+	var files []string
+	for _, file := range strings.Fields(value) {
+		if file == "none" || file == "/dev/null" {
+			continue
+		}
+		if strings.HasPrefix(file, "~") {
+			if home, err := os.UserHomeDir(); err == nil {
+				file = filepath.Join(home, file[1:])
+			}
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// userKnownHostsFile returns the file new host keys are appended to
+func (s hostKeySettings) userKnownHostsFile() string {
+	if len(s.userFiles) == 0 {
+		return ""
+	}
+	return s.userFiles[0]
+}
+
+// existingKnownHostsFiles returns the known_hosts files that can be read
+func (s hostKeySettings) existingKnownHostsFiles() []string {
+	var files []string
+	for _, file := range append(append([]string{}, s.userFiles...), s.globalFiles...) {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// knownHostsLookup checks a host and key against the known_hosts files
+func (s hostKeySettings) knownHostsLookup() (ssh.HostKeyCallback, error) {
+	files := s.existingKnownHostsFiles()
+	if len(files) == 0 {
+		// Nothing is known yet, every host is unknown
+		return func(string, net.Addr, ssh.PublicKey) error {
+			return &knownhosts.KeyError{}
+		}, nil
+	}
+	return knownhosts.New(files...)
+}
+
+// hostKeyCallback verifies the key of the host at addr against known_hosts,
+// following StrictHostKeyChecking for hosts that are not in it yet
+func (s hostKeySettings) hostKeyCallback(addr string) (ssh.HostKeyCallback, error) {
+	lookup, err := s.knownHostsLookup()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+	lookupAddr := s.lookupAddress(addr)
+
+	return func(_ string, remote net.Addr, key ssh.PublicKey) error {
+		err := lookup(lookupAddr, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			// Known host, or a revoked key
+			return err
+		}
+		host := knownhosts.Normalize(lookupAddr)
+		if len(keyErr.Want) > 0 {
+			want := keyErr.Want[0]
+			return &HostKeyMismatchError{Host: host, Key: key, File: want.Filename, Line: want.Line}
+		}
+
+		unknown := &UnknownHostKeyError{Host: host, Key: key, KnownHostsFile: s.userKnownHostsFile()}
+		switch strings.ToLower(s.strict) {
+		case "false", "no", "off", "accept-new":
+			if err := AcceptHostKey(unknown); err != nil {
+				return err
+			}
+			return nil
+		case "true", "yes":
+			return fmt.Errorf("no %s host key is known for %s and StrictHostKeyChecking is enabled", key.Type(), host)
+		default:
+			return unknown
+		}
+	}, nil
+}
+
+// lookupAddress returns the address looked up in known_hosts for addr
+func (s hostKeySettings) lookupAddress(addr string) string {
+	if s.alias == "" {
+		return addr
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return s.alias
+	}
+	return net.JoinHostPort(s.alias, port)
+}
+
+// hostKeyAlgorithms returns the key algorithms to negotiate with the host at
+// addr, so a host with several keys presents the one known_hosts has.
+// It is empty for unknown hosts, leaving the defaults.
+func (s hostKeySettings) hostKeyAlgorithms(addr string) []string {
+	lookup, err := s.knownHostsLookup()
+	if err != nil {
+		return nil
+	}
+
+	// Look the host up with a key it cannot have to learn the known ones
+	probe, err := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err != nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	remote := &net.TCPAddr{IP: net.IPv4zero, Port: 22}
+	if !errors.As(lookup(s.lookupAddress(addr), remote, probe), &keyErr) {
+		return nil
+	}
+
+	var algorithms []string
+	seen := make(map[string]bool)
+	for _, known := range keyErr.Want {
+		keyType := known.Key.Type()
+		names := []string{keyType}
+		if keyType == ssh.KeyAlgoRSA {
+			names = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				algorithms = append(algorithms, name)
+			}
+		}
+	}
+	return algorithms
+}
+
+// AcceptHostKey appends the key of an unknown host to the user known_hosts
+// file, creating it if needed
+func AcceptHostKey(unknown *UnknownHostKeyError) error {
+	if unknown.KnownHostsFile == "" {
+		return fmt.Errorf("no known_hosts file to record the key of %s in", unknown.Host)
+	}
+	if err := os.MkdirAll(filepath.Dir(unknown.KnownHostsFile), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(unknown.KnownHostsFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, knownhosts.Line([]string{unknown.Host}, unknown.Key)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// PromptHostKey asks whether to trust the key of an unknown host, as ssh does
+func PromptHostKey(in io.Reader, out io.Writer, unknown *UnknownHostKeyError) (bool, error) {
+	fmt.Fprintf(out, "\nThe authenticity of host '%s' can't be established.\n", unknown.Host)
+	fmt.Fprintf(out, "%s key fingerprint is %s.\n", unknown.Key.Type(), ssh.FingerprintSHA256(unknown.Key))
+	fmt.Fprint(out, "Are you sure you want to continue connecting (yes/no)? ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "yes", "y":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
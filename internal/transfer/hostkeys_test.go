@@ -0,0 +1,190 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// testHostKeySettings uses a known_hosts file in a temp dir holding lines
+func testHostKeySettings(t *testing.T, strict string, lines ...string) hostKeySettings {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), ".ssh", "known_hosts")
+	if len(lines) > 0 {
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return hostKeySettings{userFiles: []string{file}, strict: strict}
+}
+
+func checkHostKey(t *testing.T, settings hostKeySettings, addr string, key ssh.PublicKey) error {
+	t.Helper()
+	callback, err := settings.hostKeyCallback(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return callback(addr, &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22}, key)
+}
+
+func TestHostKeyCallbackKnownHost(t *testing.T) {
+	key := newHostKey(t)
+	settings := testHostKeySettings(t, "ask", knownhosts.Line([]string{"server1.example.com"}, key))
+
+	if err := checkHostKey(t, settings, "server1.example.com:22", key); err != nil {
+		t.Errorf("Expected a known host to be accepted, got %v", err)
+	}
+}
+
+func TestHostKeyCallbackUnknownHostAsks(t *testing.T) {
+	key := newHostKey(t)
+	settings := testHostKeySettings(t, "ask")
+
+	err := checkHostKey(t, settings, "server1.example.com:2222", key)
+	var unknown *UnknownHostKeyError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected an UnknownHostKeyError, got %v", err)
+	}
+	if unknown.Host != "[server1.example.com]:2222" || unknown.KnownHostsFile != settings.userFiles[0] {
+		t.Errorf("Unexpected error fields: %+v", unknown)
+	}
+	if !strings.Contains(err.Error(), ssh.FingerprintSHA256(key)) {
+		t.Errorf("Expected the fingerprint in %q", err.Error())
+	}
+
+	if err := AcceptHostKey(unknown); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(unknown.KnownHostsFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("Expected known_hosts to be created with mode 0600, got %v", err)
+	}
+	if err := checkHostKey(t, settings, "server1.example.com:2222", key); err != nil {
+		t.Errorf("Expected the accepted key to be trusted, got %v", err)
+	}
+}
+
+func TestHostKeyCallbackAcceptNew(t *testing.T) {
+	key := newHostKey(t)
+	settings := testHostKeySettings(t, "accept-new")
+
+	if err := checkHostKey(t, settings, "server1:22", key); err != nil {
+		t.Fatalf("Expected accept-new to trust a new host, got %v", err)
+	}
+	data, _ := os.ReadFile(settings.userFiles[0])
+	if string(data) != knownhosts.Line([]string{"server1"}, key)+"\n" {
+		t.Errorf("Expected the key to be recorded, got %q", data)
+	}
+}
+
+func TestHostKeyCallbackStrictRefusesUnknownHost(t *testing.T) {
+	settings := testHostKeySettings(t, "true")
+
+	err := checkHostKey(t, settings, "server1:22", newHostKey(t))
+	var unknown *UnknownHostKeyError
+	if err == nil || errors.As(err, &unknown) {
+		t.Fatalf("Expected StrictHostKeyChecking to refuse the host, got %v", err)
+	}
+	if _, statErr := os.Stat(settings.userFiles[0]); !os.IsNotExist(statErr) {
+		t.Error("Expected known_hosts to be left untouched")
+	}
+}
+
+func TestHostKeyCallbackMismatch(t *testing.T) {
+	known := newHostKey(t)
+	// Even accept-new never replaces a changed key
+	settings := testHostKeySettings(t, "accept-new", knownhosts.Line([]string{"server1"}, known))
+
+	err := checkHostKey(t, settings, "server1:22", newHostKey(t))
+	var mismatch *HostKeyMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Expected a HostKeyMismatchError, got %v", err)
+	}
+	if mismatch.File != settings.userFiles[0] || mismatch.Line != 1 {
+		t.Errorf("Expected the known key location, got %s:%d", mismatch.File, mismatch.Line)
+	}
+}
+
+func TestHostKeyCallbackAlias(t *testing.T) {
+	key := newHostKey(t)
+	settings := testHostKeySettings(t, "ask", knownhosts.Line([]string{"[web]:2222"}, key))
+	settings.alias = "web"
+
+	if err := checkHostKey(t, settings, "10.0.0.5:2222", key); err != nil {
+		t.Errorf("Expected the key to be looked up under HostKeyAlias, got %v", err)
+	}
+}
+
+func TestHostKeyAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPub, err := ssh.NewPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := testHostKeySettings(t, "ask",
+		knownhosts.Line([]string{"server1"}, newHostKey(t)),
+		knownhosts.Line([]string{"server1"}, rsaPub))
+
+	want := []string{ssh.KeyAlgoED25519, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	if got := settings.hostKeyAlgorithms("server1:22"); !reflect.DeepEqual(got, want) {
+		t.Errorf("hostKeyAlgorithms() = %v, want %v", got, want)
+	}
+	if got := settings.hostKeyAlgorithms("server2:22"); got != nil {
+		t.Errorf("Expected no preference for an unknown host, got %v", got)
+	}
+}
+
+func TestKnownHostsFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	got := knownHostsFiles("~/.ssh/known_hosts /etc/ssh/ssh_known_hosts none")
+	want := []string{filepath.Join(home, ".ssh", "known_hosts"), "/etc/ssh/ssh_known_hosts"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("knownHostsFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestPromptHostKey(t *testing.T) {
+	unknown := &UnknownHostKeyError{Host: "server1", Key: newHostKey(t)}
+
+	for answer, want := range map[string]bool{"yes\n": true, "y\n": true, "no\n": false, "\n": false} {
+		var out bytes.Buffer
+		accepted, err := PromptHostKey(strings.NewReader(answer), &out, unknown)
+		if err != nil || accepted != want {
+			t.Errorf("PromptHostKey(%q) = %v, %v, want %v", answer, accepted, err, want)
+		}
+		if !strings.Contains(out.String(), ssh.FingerprintSHA256(unknown.Key)) {
+			t.Errorf("Expected the fingerprint in the prompt, got %q", out.String())
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// KeyLockedError is returned when an SFTP session needs an IdentityFile that
+// is protected by a passphrase. UnlockIdentityFile makes it usable.
+type KeyLockedError struct {
+	Path           string // Private key file, ~ expanded
+	AddKeysToAgent string // AddKeysToAgent setting of the host
+}
+
+func (e *KeyLockedError) Error() string {
+	return fmt.Sprintf("private key %s needs a passphrase", e.Path)
+}
+
+// identitySettings are the key related options resolved for a host
+type identitySettings struct {
+	files          []string // IdentityFile entries, in the order ssh tries them
+	addKeysToAgent string
+}
+
+// signerCache keeps keys decrypted for SFTP sessions when they cannot be
+// handed to an ssh-agent, for the lifetime of the process only
+type signerCache struct {
+	mu      sync.Mutex
+	signers map[string]ssh.Signer
+}
+
+var decryptedKeys = &signerCache{signers: make(map[string]ssh.Signer)}
+
+func (c *signerCache) get(path string) (ssh.Signer, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	signer, ok := c.signers[path]
+	return signer, ok
+}
+
+func (c *signerCache) put(path string, signer ssh.Signer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.signers[path] = signer
+}
+
+// dialAgent connects to the ssh-agent at SSH_AUTH_SOCK (replaced in tests)
+var dialAgent = func() (agent.ExtendedAgent, func(), error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, nil, fmt.Errorf("SSH agent not available (SSH_AUTH_SOCK not set)")
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to SSH agent: %w", err)
+	}
+	return agent.NewClient(conn), func() { conn.Close() }, nil
+}
+
+// identityKey is a signer loaded from an identity file
+type identityKey struct {
+	ssh.Signer
+	path string
+}
+
+// identitySigners returns signers for the identity files that can be used
+// without asking for a passphrase: unencrypted keys and keys decrypted earlier
+// in this process. Encrypted keys already held by the agent are skipped; the
+// first other encrypted key is returned as locked.
+func identitySigners(settings identitySettings, agentKeys []*agent.Key) ([]identityKey, *KeyLockedError) {
+	var signers []identityKey
+	var locked *KeyLockedError
+
+	for _, file := range settings.files {
+		path, err := ExpandPath(file)
+		if err != nil {
+			continue
+		}
+		if signer, ok := decryptedKeys.get(path); ok {
+			signers = append(signers, identityKey{signer, path})
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// ssh -G lists the default identity files whether they exist or not
+			continue
+		}
+
+		signer, err := ssh.ParsePrivateKey(data)
+		if err == nil {
+			signers = append(signers, identityKey{signer, path})
+			continue
+		}
+
+		var missing *ssh.PassphraseMissingError
+		if !errors.As(err, &missing) {
+			continue
+		}
+		if missing.PublicKey != nil && agentHasKey(agentKeys, missing.PublicKey) {
+			continue
+		}
+		if locked == nil {
+			locked = &KeyLockedError{Path: path, AddKeysToAgent: settings.addKeysToAgent}
+		}
+	}
+
+	return signers, locked
+}
+
+// agentHasKey reports whether key is among the keys held by the agent
+func agentHasKey(agentKeys []*agent.Key, key ssh.PublicKey) bool {
+	for _, k := range agentKeys {
+		if bytes.Equal(k.Marshal(), key.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnlockIdentityFile decrypts a passphrase protected private key so that the
+// next SFTP sessions can use it without asking again. As with ssh, the key is
+// added to the agent when AddKeysToAgent allows it; otherwise, or without an
+// agent, it is kept in memory until sshm exits.
+func UnlockIdentityFile(path string, passphrase []byte, addKeysToAgent string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	key, err := ssh.ParseRawPrivateKeyWithPassphrase(data, passphrase)
+	if err != nil {
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return fmt.Errorf("incorrect passphrase for %s", path)
+		}
+		return fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return fmt.Errorf("unsupported private key %s: %w", path, err)
+	}
+
+	if add, confirm, lifetime := parseAddKeysToAgent(addKeysToAgent); add {
+		if agentClient, closeAgent, err := dialAgent(); err == nil {
+			defer closeAgent()
+			err := agentClient.Add(agent.AddedKey{
+				PrivateKey:       key,
+				Comment:          path,
+				LifetimeSecs:     lifetime,
+				ConfirmBeforeUse: confirm,
+			})
+			if err == nil {
+				return nil
+			}
+		}
+	}
+
+	decryptedKeys.put(path, signer)
+	return nil
+}
+
+// parseAddKeysToAgent interprets the AddKeysToAgent option as printed by ssh -G:
+// yes, no, ask, confirm, a key lifetime, or confirm followed by a lifetime.
+// "ask" needs ssh-askpass to confirm the addition, so the key is not added.
+func parseAddKeysToAgent(value string) (add, confirm bool, lifetimeSecs uint32) {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) == 0 {
+		return false, false, 0
+	}
+
+	switch fields[0] {
+	case "no", "false", "ask":
+		return false, false, 0
+	case "yes", "true":
+		return true, false, 0
+	case "confirm":
+		confirm = true
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 {
+		lifetime, ok := parseSSHTime(fields[0])
+		if !ok {
+			return false, false, 0
+		}
+		lifetimeSecs = lifetime
+	}
+	return true, confirm, lifetimeSecs
+}
+
+// parseSSHTime parses an sshd_config style time such as "3600", "90m" or "1h30m"
+func parseSSHTime(value string) (uint32, bool) {
+	if secs, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return uint32(secs), true
+	}
+
+	units := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+	var total time.Duration
+	start := 0
+	for i := 0; i < len(value); i++ {
+		unit, ok := units[value[i]]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value[start:i])
+		if err != nil {
+			return 0, false
+		}
+		total += time.Duration(n) * unit
+		start = i + 1
+	}
+	if start != len(value) || total <= 0 {
+		return 0, false
+	}
+	return uint32(total / time.Second), true
+}
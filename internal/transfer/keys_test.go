@@ -0,0 +1,156 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// writeEncryptedKey writes a passphrase protected ed25519 key and returns its path
+func writeEncryptedKey(t *testing.T, passphrase string) string {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "test", []byte(passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// useTestAgent replaces the SSH agent for the duration of the test; a nil
+// keyring means no agent is running. The decrypted key cache is reset too.
+func useTestAgent(t *testing.T, keyring agent.Agent) {
+	t.Helper()
+
+	origDial, origKeys := dialAgent, decryptedKeys
+	t.Cleanup(func() { dialAgent, decryptedKeys = origDial, origKeys })
+
+	decryptedKeys = &signerCache{signers: make(map[string]ssh.Signer)}
+	dialAgent = func() (agent.ExtendedAgent, func(), error) {
+		if keyring == nil {
+			return nil, nil, errors.New("SSH agent not available (SSH_AUTH_SOCK not set)")
+		}
+		return keyring.(agent.ExtendedAgent), func() {}, nil
+	}
+}
+
+func TestIdentitySignersCachesUnlockedKey(t *testing.T) {
+	useTestAgent(t, nil)
+	path := writeEncryptedKey(t, "s3cret")
+	settings := identitySettings{files: []string{filepath.Join(t.TempDir(), "missing"), path}, addKeysToAgent: "yes"}
+
+	signers, locked := identitySigners(settings, nil)
+	if len(signers) != 0 || locked == nil || locked.Path != path || locked.AddKeysToAgent != "yes" {
+		t.Fatalf("Expected %s to be locked, got signers %v, locked %+v", path, signers, locked)
+	}
+
+	if err := UnlockIdentityFile(path, []byte("wrong"), "yes"); err == nil || !strings.Contains(err.Error(), "incorrect passphrase") {
+		t.Errorf("Expected an incorrect passphrase error, got %v", err)
+	}
+	if err := UnlockIdentityFile(path, []byte("s3cret"), "yes"); err != nil {
+		t.Fatalf("UnlockIdentityFile() error = %v", err)
+	}
+
+	// Every later browse opens with the cached signer, without asking again
+	var first ssh.PublicKey
+	for i := 0; i < 3; i++ {
+		signers, locked := identitySigners(settings, nil)
+		if locked != nil || len(signers) != 1 {
+			t.Fatalf("Open %d: expected the cached signer, got signers %v, locked %+v", i+1, signers, locked)
+		}
+		if first == nil {
+			first = signers[0].PublicKey()
+		} else if !bytes.Equal(first.Marshal(), signers[0].PublicKey().Marshal()) {
+			t.Errorf("Open %d: expected the same key", i+1)
+		}
+	}
+}
+
+func TestUnlockIdentityFileAddsToAgent(t *testing.T) {
+	keyring := agent.NewKeyring()
+	useTestAgent(t, keyring)
+	path := writeEncryptedKey(t, "s3cret")
+	settings := identitySettings{files: []string{path}, addKeysToAgent: "yes"}
+
+	if err := UnlockIdentityFile(path, []byte("s3cret"), "yes"); err != nil {
+		t.Fatalf("UnlockIdentityFile() error = %v", err)
+	}
+
+	keys, err := keyring.List()
+	if err != nil || len(keys) != 1 || keys[0].Comment != path {
+		t.Fatalf("Expected the key in the agent, got %v (%v)", keys, err)
+	}
+	if _, ok := decryptedKeys.get(path); ok {
+		t.Error("A key handed to the agent should not be cached in memory too")
+	}
+
+	// The agent now holds the key, so it is neither locked nor loaded again
+	signers, locked := identitySigners(settings, keys)
+	if len(signers) != 0 || locked != nil {
+		t.Errorf("Expected the agent to cover the key, got signers %v, locked %+v", signers, locked)
+	}
+}
+
+func TestUnlockIdentityFileRespectsAddKeysToAgent(t *testing.T) {
+	keyring := agent.NewKeyring()
+	useTestAgent(t, keyring)
+	path := writeEncryptedKey(t, "s3cret")
+
+	if err := UnlockIdentityFile(path, []byte("s3cret"), "no"); err != nil {
+		t.Fatalf("UnlockIdentityFile() error = %v", err)
+	}
+
+	if keys, _ := keyring.List(); len(keys) != 0 {
+		t.Errorf("AddKeysToAgent no should keep the key out of the agent, got %v", keys)
+	}
+	if _, ok := decryptedKeys.get(path); !ok {
+		t.Error("Expected the key to be cached for this process")
+	}
+}
+
+func TestParseAddKeysToAgent(t *testing.T) {
+	tests := []struct {
+		value    string
+		add      bool
+		confirm  bool
+		lifetime uint32
+	}{
+		{"", false, false, 0},
+		{"no", false, false, 0},
+		{"false", false, false, 0},
+		{"ask", false, false, 0},
+		{"yes", true, false, 0},
+		{"true", true, false, 0},
+		{"confirm", true, true, 0},
+		{"1h30m", true, false, 5400},
+		{"3600", true, false, 3600},
+		{"confirm 2d", true, true, 172800},
+		{"later", false, false, 0},
+	}
+
+	for _, tt := range tests {
+		add, confirm, lifetime := parseAddKeysToAgent(tt.value)
+		if add != tt.add || confirm != tt.confirm || lifetime != tt.lifetime {
+			t.Errorf("parseAddKeysToAgent(%q) = (%v, %v, %d), want (%v, %v, %d)",
+				tt.value, add, confirm, lifetime, tt.add, tt.confirm, tt.lifetime)
+		}
+	}
+}
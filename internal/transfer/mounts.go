@@ -0,0 +1,133 @@
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// mountsData is the on-disk format of the SSHFS mounts made by 'sshm mount'
+type mountsData struct {
+	Mounts []*SSHFSMount `json:"mounts"`
+}
+
+// GetMountsPath returns the path to the file tracking active SSHFS mounts
+func GetMountsPath() (string, error) {
+	configDir, err := config.GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "sshm_mounts.json"), nil
+}
+
+// LoadMounts returns the active SSHFS mounts. A missing file means none is.
+func LoadMounts() ([]*SSHFSMount, error) {
+	path, err := GetMountsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var mounts mountsData
+	if err := json.Unmarshal(data, &mounts); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return mounts.Mounts, nil
+}
+
+// saveMounts replaces the tracked mounts
+func saveMounts(mounts []*SSHFSMount) error {
+	path, err := GetMountsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(mountsData{Mounts: mounts}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(path, data, 0600)
+}
+
+// RecordMount tracks a mount so 'sshm unmount' can find it later
+func RecordMount(mount *SSHFSMount) error {
+	mounts, err := LoadMounts()
+	if err != nil {
+		return err
+	}
+
+	// A mount point holds one mount, replace a stale entry
+	kept := mounts[:0]
+	for _, m := range mounts {
+		if m.MountPoint != mount.MountPoint {
+			kept = append(kept, m)
+		}
+	}
+	return saveMounts(append(kept, mount))
+}
+
+// ForgetMount stops tracking the mount on mountPoint
+func ForgetMount(mountPoint string) error {
+	mounts, err := LoadMounts()
+	if err != nil {
+		return err
+	}
+
+	kept := mounts[:0]
+	for _, m := range mounts {
+		if m.MountPoint != mountPoint {
+			kept = append(kept, m)
+		}
+	}
+	return saveMounts(kept)
+}
+
+// HostMounts returns the tracked mounts of a host
+func HostMounts(host string) ([]*SSHFSMount, error) {
+	mounts, err := LoadMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var hostMounts []*SSHFSMount
+	for _, m := range mounts {
+		if m.Host == host {
+			hostMounts = append(hostMounts, m)
+		}
+	}
+	return hostMounts, nil
+}
+
+// RenameMountsHost moves the tracked mounts of a renamed host to its new name
+func RenameMountsHost(oldName, newName string) error {
+	mounts, err := LoadMounts()
+	if err != nil {
+		return err
+	}
+
+	renamed := false
+	for _, m := range mounts {
+		if m.Host == oldName {
+			m.Host = newName
+			renamed = true
+		}
+	}
+	if !renamed {
+		return nil
+	}
+	return saveMounts(mounts)
+}
@@ -0,0 +1,57 @@
+package transfer
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndForgetMounts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	if mounts, err := LoadMounts(); err != nil || len(mounts) != 0 {
+		t.Fatalf("Expected no mounts without a state file, got %v, %v", mounts, err)
+	}
+
+	web := &SSHFSMount{Host: "web", RemotePath: "/var/www", MountPoint: "/mnt/www"}
+	db := &SSHFSMount{Host: "db", MountPoint: "/tmp/sshm-db-1"}
+	for _, m := range []*SSHFSMount{web, db} {
+		if err := RecordMount(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new mount on the same mount point replaces the stale entry
+	if err := RecordMount(&SSHFSMount{Host: "web", RemotePath: "/srv", MountPoint: "/mnt/www", KeepMountPoint: true}); err != nil {
+		t.Fatal(err)
+	}
+	mounts, err := HostMounts("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 1 || mounts[0].RemotePath != "/srv" || !mounts[0].KeepMountPoint {
+		t.Errorf("Expected the latest mount of web, got %+v", mounts)
+	}
+
+	if err := ForgetMount("/mnt/www"); err != nil {
+		t.Fatal(err)
+	}
+	if mounts, _ := HostMounts("web"); len(mounts) != 0 {
+		t.Errorf("Expected the mount of web to be forgotten, got %+v", mounts)
+	}
+	if mounts, _ := HostMounts("db"); len(mounts) != 1 {
+		t.Errorf("Expected the mount of db to remain, got %+v", mounts)
+	}
+
+	// A renamed host keeps its mounts, for 'sshm unmount' to find them
+	if err := RenameMountsHost("db", "database"); err != nil {
+		t.Fatal(err)
+	}
+	if mounts, _ := HostMounts("database"); len(mounts) != 1 || mounts[0].MountPoint != "/tmp/sshm-db-1" {
+		t.Errorf("Expected the mount of db under its new name, got %+v", mounts)
+	}
+	if mounts, _ := HostMounts("db"); len(mounts) != 0 {
+		t.Errorf("Expected no mount left under the old name, got %+v", mounts)
+	}
+}
@@ -0,0 +1,116 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// OnExists is what a download does when its local destination already exists
+type OnExists string
+
+const (
+	OnExistsAsk       OnExists = "ask"       // Ask each time
+	OnExistsOverwrite OnExists = "overwrite" // Replace the local file, as scp does
+	OnExistsSkip      OnExists = "skip"      // Leave the local file and skip the download
+	OnExistsRename    OnExists = "rename"    // Download next to it under a numbered name
+)
+
+// ParseOnExists parses an on-exists setting; empty means ask
+func ParseOnExists(value string) (OnExists, error) {
+	switch action := OnExists(strings.ToLower(strings.TrimSpace(value))); action {
+	case "":
+		return OnExistsAsk, nil
+	case OnExistsAsk, OnExistsOverwrite, OnExistsSkip, OnExistsRename:
+		return action, nil
+	default:
+		return "", fmt.Errorf("invalid on-exists value %q (use ask, overwrite, skip or rename)", value)
+	}
+}
+
+// DefaultOnExists returns the download_on_exists preference of the app
+// config, ask when it is not set or invalid
+func DefaultOnExists() OnExists {
+	appConfig, err := config.LoadAppConfig()
+	if err != nil {
+		return OnExistsAsk
+	}
+	action, err := ParseOnExists(appConfig.DownloadOnExists)
+	if err != nil {
+		return OnExistsAsk
+	}
+	return action
+}
+
+// ExistingDestination returns the local file or directory a download would
+// replace, empty when there is none or the request is an upload
+func (r *TransferRequest) ExistingDestination() string {
+	if r.Direction != Download {
+		return ""
+	}
+	target := r.localDownloadPath()
+	if _, err := os.Lstat(target); err != nil {
+		return ""
+	}
+	return target
+}
+
+// ApplyOnExists prepares a download whose local destination may exist:
+// rename points LocalPath at a free numbered name and skip returns false.
+// Ask must be answered first, it overwrites like scp otherwise.
+func (r *TransferRequest) ApplyOnExists(action OnExists) bool {
+	existing := r.ExistingDestination()
+	if existing == "" {
+		return true
+	}
+
+	switch action {
+	case OnExistsSkip:
+		return false
+	case OnExistsRename:
+		r.LocalPath = UniqueLocalPath(existing)
+	}
+	return true
+}
+
+// UniqueLocalPath returns path, or when it exists the first free name with a
+// number before its extension: report.pdf, report (1).pdf, report (2).pdf...
+func UniqueLocalPath(path string) string {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		ext = ""
+	}
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// PromptOnExists asks what to do with an existing local destination. Anything
+// but overwrite or rename skips, so nothing is replaced by accident.
+func PromptOnExists(in io.Reader, out io.Writer, existing string) OnExists {
+	fmt.Fprintf(out, "⚠️  %s already exists.\n", existing)
+	fmt.Fprint(out, "[o]verwrite, [r]ename or [s]kip? [s]: ")
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "o", "overwrite":
+		return OnExistsOverwrite
+	case "r", "rename":
+		return OnExistsRename
+	default:
+		return OnExistsSkip
+	}
+}
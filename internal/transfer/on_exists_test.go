@@ -0,0 +1,88 @@
+package transfer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOnExists(t *testing.T) {
+	for value, want := range map[string]OnExists{"": OnExistsAsk, "ask": OnExistsAsk, "Overwrite": OnExistsOverwrite, "skip": OnExistsSkip, " rename ": OnExistsRename} {
+		if got, err := ParseOnExists(value); err != nil || got != want {
+			t.Errorf("ParseOnExists(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseOnExists("merge"); err == nil {
+		t.Error("Expected an unknown value to be refused")
+	}
+}
+
+func TestUniqueLocalPath(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "report.pdf", "report (1).pdf", "notes")
+	if err := os.Mkdir(filepath.Join(dir, "site.v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"report.pdf": "report (2).pdf",
+		"notes":      "notes (1)",
+		"site.v2":    "site.v2 (1)", // Directories have no extension
+		"new.txt":    "new.txt",
+	}
+	for name, want := range tests {
+		if got := UniqueLocalPath(filepath.Join(dir, name)); got != filepath.Join(dir, want) {
+			t.Errorf("UniqueLocalPath(%q) = %q, want %q", name, filepath.Base(got), want)
+		}
+	}
+}
+
+func TestApplyOnExists(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "app.log")
+	existing := filepath.Join(dir, "app.log")
+
+	tests := []struct {
+		name      string
+		localPath string
+		action    OnExists
+		proceed   bool
+		wantLocal string
+	}{
+		{"overwrite", dir, OnExistsOverwrite, true, dir},
+		{"skip", dir, OnExistsSkip, false, dir},
+		{"rename into directory", dir, OnExistsRename, true, filepath.Join(dir, "app (1).log")},
+		{"rename file", existing, OnExistsRename, true, filepath.Join(dir, "app (1).log")},
+		{"nothing there", filepath.Join(dir, "other.log"), OnExistsSkip, true, filepath.Join(dir, "other.log")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &TransferRequest{Host: "web", Direction: Download, LocalPath: tt.localPath, RemotePath: "/var/log/app.log"}
+			if got := req.ApplyOnExists(tt.action); got != tt.proceed {
+				t.Errorf("ApplyOnExists() = %v, want %v", got, tt.proceed)
+			}
+			if req.LocalPath != tt.wantLocal {
+				t.Errorf("LocalPath = %q, want %q", req.LocalPath, tt.wantLocal)
+			}
+		})
+	}
+
+	upload := &TransferRequest{Direction: Upload, LocalPath: existing, RemotePath: "/tmp/"}
+	if upload.ExistingDestination() != "" {
+		t.Error("Expected uploads to never have an existing local destination")
+	}
+}
+
+func TestPromptOnExists(t *testing.T) {
+	for answer, want := range map[string]OnExists{"o\n": OnExistsOverwrite, "rename\n": OnExistsRename, "s\n": OnExistsSkip, "\n": OnExistsSkip, "": OnExistsSkip} {
+		var out bytes.Buffer
+		if got := PromptOnExists(strings.NewReader(answer), &out, "/tmp/app.log"); got != want {
+			t.Errorf("PromptOnExists(%q) = %q, want %q", answer, got, want)
+		}
+		if !strings.Contains(out.String(), "/tmp/app.log already exists") {
+			t.Errorf("Expected the existing file in the prompt, got %q", out.String())
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	pathpkg "path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// DefaultOpenMaxSize is the largest remote file downloaded to be opened
+// locally when open_max_size_mb is not set
+const DefaultOpenMaxSize = 100 << 20 // 100 MiB
+
+// OpenMaxSize returns the largest remote file downloaded to be opened, from
+// the open_max_size_mb preference of the app config
+func OpenMaxSize() int64 {
+	appConfig, err := config.LoadAppConfig()
+	if err != nil || appConfig == nil || appConfig.OpenMaxSizeMB <= 0 {
+		return DefaultOpenMaxSize
+	}
+	return int64(appConfig.OpenMaxSizeMB) << 20
+}
+
+// CheckOpenSize rejects files too large to download for a quick look
+func CheckOpenSize(size, limit int64) error {
+	if size > limit {
+		return fmt.Errorf("file is too large to open (%d bytes, limit is %d), download it instead", size, limit)
+	}
+	return nil
+}
+
+// NewOpenDir creates the temporary directory remote files are downloaded
+// to before being opened. The caller removes it once done.
+func NewOpenDir() (string, error) {
+	return os.MkdirTemp("", "sshm-open-")
+}
+
+// TempOpenPath returns where a remote file is downloaded in dir to be opened.
+// It keeps the file name, whose extension picks the application, and numbers
+// it when a file of the same name was already opened.
+func TempOpenPath(dir, remotePath string) string {
+	name := pathpkg.Base(strings.TrimRight(remotePath, "/"))
+	if name == "." || name == "/" || name == "" || name == ".." {
+		name = "file"
+	}
+	// Backslashes are separators on Windows, keep the file inside dir
+	name = strings.ReplaceAll(name, `\`, "_")
+	return UniqueLocalPath(filepath.Join(dir, name))
+}
+
+// DownloadForOpen downloads a remote file into dir, refusing directories and
+// files larger than limit, and returns the local copy
+func (s *SFTPSession) DownloadForOpen(remotePath, dir string, limit int64) (string, error) {
+	file, err := s.Stat(remotePath)
+	if err != nil {
+		return "", err
+	}
+	if file.IsDir {
+		return "", fmt.Errorf("%s is a directory", remotePath)
+	}
+	if err := CheckOpenSize(file.Size, limit); err != nil {
+		return "", err
+	}
+
+	localPath := TempOpenPath(dir, remotePath)
+	local, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	if err := s.ReadFileFrom(remotePath, 0, local); err != nil {
+		local.Close()
+		os.Remove(localPath)
+		return "", fmt.Errorf("failed to download %s: %w", remotePath, err)
+	}
+	if err := local.Close(); err != nil {
+		os.Remove(localPath)
+		return "", err
+	}
+	return localPath, nil
+}
+
+// OpenFileCommand returns the command opening a local file with its default
+// application on goos
+func OpenFileCommand(goos, path string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{path}
+	case "windows":
+		// The empty argument is the window title start expects first
+		return "cmd", []string{"/c", "start", "", path}
+	default:
+		return "xdg-open", []string{path}
+	}
+}
+
+// OpenLocalFile opens a local file with its default application, without
+// waiting for it to be closed
+func OpenLocalFile(path string) error {
+	name, args := OpenFileCommand(runtime.GOOS, path)
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("cannot open files: %s not found", name)
+	}
+	return exec.Command(name, args...).Start()
+}
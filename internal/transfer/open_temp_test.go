@@ -0,0 +1,103 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTempOpenPath(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"/srv/reports/summary.pdf", "summary.pdf"},
+		{"~/photo.jpg", "photo.jpg"},
+		{"/var/log/", "log"},
+		{"/", "file"},
+		{`/data/a\b.txt`, "a_b.txt"},
+	}
+	for _, tt := range tests {
+		if got := TempOpenPath(dir, tt.remote); got != filepath.Join(dir, tt.want) {
+			t.Errorf("TempOpenPath(%q) = %q, want %q", tt.remote, got, filepath.Join(dir, tt.want))
+		}
+	}
+
+	// A second file of the same name does not replace the first one
+	writeFiles(t, dir, "summary.pdf")
+	if got, want := TempOpenPath(dir, "/other/summary.pdf"), filepath.Join(dir, "summary (1).pdf"); got != want {
+		t.Errorf("Expected %q for a name already opened, got %q", want, got)
+	}
+}
+
+func TestOpenFileCommand(t *testing.T) {
+	path := "/tmp/sshm-open-1/summary.pdf"
+
+	tests := []struct {
+		goos string
+		name string
+		args []string
+	}{
+		{"darwin", "open", []string{path}},
+		{"linux", "xdg-open", []string{path}},
+		{"freebsd", "xdg-open", []string{path}},
+		{"windows", "cmd", []string{"/c", "start", "", path}},
+	}
+	for _, tt := range tests {
+		name, args := OpenFileCommand(tt.goos, path)
+		if name != tt.name || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("OpenFileCommand(%q) = %s %v, want %s %v", tt.goos, name, args, tt.name, tt.args)
+		}
+	}
+}
+
+func TestOpenMaxSize(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	if got := OpenMaxSize(); got != DefaultOpenMaxSize {
+		t.Errorf("Expected the default limit without a preference, got %d", got)
+	}
+	if err := CheckOpenSize(DefaultOpenMaxSize+1, DefaultOpenMaxSize); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Expected a file over the limit to be refused, got %v", err)
+	}
+	if err := CheckOpenSize(DefaultOpenMaxSize, DefaultOpenMaxSize); err != nil {
+		t.Errorf("Expected a file at the limit to be accepted, got %v", err)
+	}
+}
+
+func TestDownloadForOpen(t *testing.T) {
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, "report.pdf"), []byte("%PDF-1.7"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(home, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := newTestSFTPSession(t, home)
+	dir := t.TempDir()
+
+	local, err := s.DownloadForOpen(filepath.Join(home, "report.pdf"), dir, DefaultOpenMaxSize)
+	if err != nil {
+		t.Fatalf("DownloadForOpen() error = %v", err)
+	}
+	if content, _ := os.ReadFile(local); string(content) != "%PDF-1.7" || filepath.Dir(local) != dir {
+		t.Errorf("Expected the file in %s, got %q with %q", dir, local, content)
+	}
+
+	if _, err := s.DownloadForOpen(filepath.Join(home, "report.pdf"), dir, 4); err == nil {
+		t.Error("Expected a file over the limit to be refused")
+	}
+	if _, err := s.DownloadForOpen(filepath.Join(home, "docs"), dir, DefaultOpenMaxSize); err == nil {
+		t.Error("Expected a directory to be refused")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected refused files to leave nothing behind, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,351 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// TreeEntry is an entry directly below the root of a recursive transfer,
+// with the size of everything below it
+type TreeEntry struct {
+	Name string
+	Size int64
+}
+
+// DefaultConcurrency returns how many scp jobs a recursive transfer is split
+// into according to the app config, 1 when it is not set
+func DefaultConcurrency() int {
+	appConfig, err := config.LoadAppConfig()
+	if err != nil || appConfig.TransferConcurrency < 1 {
+		return 1
+	}
+	return appConfig.TransferConcurrency
+}
+
+// PartitionTree spreads entries over at most jobs groups holding about the
+// same number of bytes: the largest entries are placed first, each in the
+// group with the fewest bytes so far. Empty groups are dropped.
+func PartitionTree(entries []TreeEntry, jobs int) [][]TreeEntry {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(entries) {
+		jobs = len(entries)
+	}
+
+	sorted := append([]TreeEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Size > sorted[j].Size })
+
+	groups := make([][]TreeEntry, jobs)
+	sizes := make([]int64, jobs)
+	for _, entry := range sorted {
+		smallest := 0
+		for i := range sizes {
+			if sizes[i] < sizes[smallest] || (sizes[i] == sizes[smallest] && len(groups[i]) < len(groups[smallest])) {
+				smallest = i
+			}
+		}
+		groups[smallest] = append(groups[smallest], entry)
+		sizes[smallest] += entry.Size
+	}
+
+	var partitions [][]TreeEntry
+	for _, group := range groups {
+		if len(group) > 0 {
+			partitions = append(partitions, group)
+		}
+	}
+	return partitions
+}
+
+// LocalTreeEntries lists the entries directly below a local directory with
+// the size of each
+func LocalTreeEntries(dir string) ([]TreeEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]TreeEntry, 0, len(dirEntries))
+	for _, d := range dirEntries {
+		size, _, err := EstimateLocalSize(filepath.Join(dir, d.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, TreeEntry{Name: d.Name(), Size: size})
+	}
+	return entries, nil
+}
+
+// expandRemoteHome replaces a leading ~ with the home directory, which the
+// quoted paths of remote commands would not expand
+func (s *SFTPSession) expandRemoteHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	home, err := s.GetHomeDirectory()
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(path, "~", home, 1), nil
+}
+
+// TreeEntries lists the entries directly below a remote directory with the
+// size of each, from a WalkDir of it
+func (s *SFTPSession) TreeEntries(dir string) ([]TreeEntry, error) {
+	walk, err := s.WalkDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sizes := walk.ChildSizes()
+	entries := make([]TreeEntry, 0, len(sizes))
+	for name, size := range sizes {
+		entries = append(entries, TreeEntry{Name: name, Size: size})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// uploadTargetCommand creates the directory a recursive upload of a
+// directory named name to dest fills, following scp: inside dest when it is
+// a directory, dest itself otherwise. It prints the directory.
+func uploadTargetCommand(dest, name string) string {
+	return fmt.Sprintf(`if [ -d %s ]; then t=%s; else t=%s; fi; mkdir -p -- "$t" && printf '%%s\n' "$t"`,
+		shellQuote(dest), shellQuote(pathpkg.Join(dest, name)), shellQuote(dest))
+}
+
+// UploadTarget creates the remote directory a recursive upload of the
+// directory named name to dest fills, and returns it
+func (s *SFTPSession) UploadTarget(dest, name string) (string, error) {
+	dest, err := s.expandRemoteHome(dest)
+	if err != nil {
+		return "", err
+	}
+	output, err := s.output(uploadTargetCommand(dest, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// remoteTree is what splitting a transfer needs from an SFTPSession
+type remoteTree interface {
+	TreeEntries(dir string) ([]TreeEntry, error)
+	UploadTarget(dest, name string) (string, error)
+	Close() error
+}
+
+// openRemoteTree connects to a host to split a transfer (replaced in tests)
+var openRemoteTree = func(host, configFile string) (remoteTree, error) {
+	session, err := NewSFTPSession(host, configFile)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// parallelPlan is a recursive transfer split into scp jobs copying groups
+// of the entries below its root into the same target directory
+type parallelPlan struct {
+	root   string // Source directory, local or remote
+	target string // Directory the entries are copied into
+	groups [][]TreeEntry
+	total  int64
+}
+
+// splits reports whether the transfer asks to run as several scp jobs. The
+// session listing the tree logs in with the host's config and keys, so one-off
+// overrides and password logins keep the single scp. So do bandwidth limits,
+// which every job would apply on its own.
+func (r *TransferRequest) splits() bool {
+	return r.Concurrency > 1 && r.Recursive && r.EffectiveBackend() == BackendSCP &&
+		r.User == "" && r.Port == "" && r.JumpHost == "" && r.Password == "" && r.BandwidthLimitKBps == 0
+}
+
+// planParallel lists the entries below the root of the transfer and creates
+// the directory they are copied into. A nil plan means there is nothing to
+// split and a single scp should run.
+func (r *TransferRequest) planParallel() (*parallelPlan, error) {
+	remote, err := openRemoteTree(r.Host, r.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	defer remote.Close()
+
+	plan := &parallelPlan{}
+	var entries []TreeEntry
+	if r.Direction == Upload {
+		if info, err := os.Stat(r.LocalPath); err != nil || !info.IsDir() {
+			return nil, nil
+		}
+		plan.root = r.LocalPath
+		if entries, err = LocalTreeEntries(r.LocalPath); err != nil {
+			return nil, err
+		}
+		if len(entries) < 2 {
+			return nil, nil
+		}
+		if plan.target, err = remote.UploadTarget(r.RemotePath, filepath.Base(filepath.Clean(r.LocalPath))); err != nil {
+			return nil, err
+		}
+	} else {
+		plan.root = r.RemotePath
+		if entries, err = remote.TreeEntries(r.RemotePath); err != nil {
+			return nil, err
+		}
+		if len(entries) < 2 {
+			return nil, nil
+		}
+		plan.target = r.localDownloadPath()
+		if err := os.MkdirAll(plan.target, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	plan.groups = PartitionTree(entries, r.Concurrency)
+	for _, entry := range entries {
+		plan.total += entry.Size
+	}
+	return plan, nil
+}
+
+// jobArgs returns the scp arguments copying a group of entries of the plan
+func (r *TransferRequest) jobArgs(plan *parallelPlan, group []TreeEntry) []string {
+	// The jobs run at once without a terminal, so scp cannot prompt
+	args := append([]string{"-o", "BatchMode=yes"}, r.scpOptions()...)
+	for _, entry := range group {
+		if r.Direction == Upload {
+			args = append(args, filepath.Join(plan.root, entry.Name))
+		} else {
+			args = append(args, FormatRemoteSpec(r.remoteHost(), pathpkg.Join(plan.root, entry.Name)))
+		}
+	}
+	if r.Direction == Upload {
+		return append(args, FormatRemoteSpec(r.remoteHost(), plan.target))
+	}
+	return append(args, plan.target)
+}
+
+// parallelRun tracks the scp processes of a split transfer so they can all
+// be cancelled
+type parallelRun struct {
+	mu     sync.Mutex
+	cmds   []*exec.Cmd
+	killed bool
+}
+
+// run starts cmd unless the transfer was cancelled, and waits for it
+func (p *parallelRun) run(cmd *exec.Cmd) error {
+	p.mu.Lock()
+	if p.killed {
+		p.mu.Unlock()
+		return fmt.Errorf("transfer cancelled")
+	}
+	if err := cmd.Start(); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	p.cmds = append(p.cmds, cmd)
+	p.mu.Unlock()
+	return cmd.Wait()
+}
+
+// kill stops every scp process started so far and those not started yet
+func (p *parallelRun) kill() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.killed = true
+	for _, cmd := range p.cmds {
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+	}
+}
+
+// cancelled reports whether kill was called
+func (p *parallelRun) cancelled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.killed
+}
+
+// lockedWriter serializes the writes of the concurrent scp jobs
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(b)
+}
+
+// parallel runs a recursive transfer as Concurrency scp jobs at once, each
+// copying a group of the entries below its root, and draws one progress
+// meter for all of them as jobs complete. handled is false when the transfer
+// should run as a single scp: splitting is not asked for, the tree cannot be
+// listed or has fewer than two entries.
+func (r *TransferRequest) parallel(stdout, stderr io.Writer, run *parallelRun) (result *TransferResult, handled bool) {
+	if !r.splits() {
+		return nil, false
+	}
+	plan, err := r.planParallel()
+	if err != nil {
+		fmt.Fprintf(stdout, "Cannot split the transfer (%v), using a single scp\n", err)
+		return nil, false
+	}
+	if plan == nil {
+		return nil, false
+	}
+	if run == nil {
+		run = &parallelRun{}
+	}
+
+	out := &lockedWriter{w: stdout}
+	errOut := &lockedWriter{w: stderr}
+	fmt.Fprintf(out, "Copying %s with %d scp jobs\n", plan.root, len(plan.groups))
+	progress := newResumeProgress(out, pathpkg.Base(filepath.ToSlash(plan.root)), 0, plan.total)
+
+	errs := make([]error, len(plan.groups))
+	runConcurrently(len(plan.groups), len(plan.groups), func(i int) {
+		cmd := scpCommand(r.jobArgs(plan, plan.groups[i])...)
+		cmd.Stdout = out
+		cmd.Stderr = errOut
+		errs[i] = run.run(cmd)
+		if errs[i] == nil {
+			var size int64
+			for _, entry := range plan.groups[i] {
+				size += entry.Size
+			}
+			progress.advance(size)
+		}
+	})
+	progress.finish()
+
+	if run.cancelled() {
+		return &TransferResult{Success: false, Error: fmt.Errorf("transfer cancelled")}, true
+	}
+	failed := 0
+	var first error
+	for _, err := range errs {
+		if err != nil {
+			failed++
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	if failed > 0 {
+		return &TransferResult{Success: false, Error: fmt.Errorf("%d of %d scp jobs failed: %w", failed, len(errs), first)}, true
+	}
+	return &TransferResult{Success: true, BytesSent: plan.total}, true
+}
@@ -0,0 +1,214 @@
+package transfer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPartitionTreeBalancesBytes(t *testing.T) {
+	entries := []TreeEntry{
+		{Name: "videos", Size: 900},
+		{Name: "photos", Size: 500},
+		{Name: "docs", Size: 300},
+		{Name: "music", Size: 300},
+		{Name: "notes.txt", Size: 100},
+	}
+
+	groups := PartitionTree(entries, 3)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %d: %v", len(groups), groups)
+	}
+
+	var sizes []int64
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		var size int64
+		for _, entry := range group {
+			if seen[entry.Name] {
+				t.Errorf("Expected %s in a single group", entry.Name)
+			}
+			seen[entry.Name] = true
+			size += entry.Size
+		}
+		sizes = append(sizes, size)
+	}
+	if len(seen) != len(entries) {
+		t.Errorf("Expected every entry to be placed, got %v", seen)
+	}
+
+	// 900 alone, 500+100 and 300+300
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+	if want := []int64{600, 600, 900}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("Expected group sizes %v, got %v", want, sizes)
+	}
+}
+
+func TestPartitionTreeSpreadsEmptyFiles(t *testing.T) {
+	entries := []TreeEntry{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+
+	groups := PartitionTree(entries, 2)
+	if len(groups) != 2 || len(groups[0]) != 2 || len(groups[1]) != 2 {
+		t.Errorf("Expected empty files spread two by two, got %v", groups)
+	}
+}
+
+func TestPartitionTreeDropsEmptyGroups(t *testing.T) {
+	entries := []TreeEntry{{Name: "a", Size: 1}, {Name: "b", Size: 2}}
+
+	if groups := PartitionTree(entries, 8); len(groups) != 2 {
+		t.Errorf("Expected no more groups than entries, got %v", groups)
+	}
+	if groups := PartitionTree(entries, 0); len(groups) != 1 || len(groups[0]) != 2 {
+		t.Errorf("Expected a single group without concurrency, got %v", groups)
+	}
+}
+
+func TestSessionTreeEntries(t *testing.T) {
+	s := &SFTPSession{runner: func(cmd string, w io.Writer) error {
+		_, err := io.WriteString(w, sampleWalk)
+		return err
+	}}
+
+	entries, err := s.TreeEntries("/srv/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TreeEntry{{Name: "README.md", Size: 100}, {Name: "current"}, {Name: "logs", Size: 300}, {Name: "secret"}, {Name: "src", Size: 2500}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Expected %v, got %v", want, entries)
+	}
+}
+
+// fakeRemoteTree lists a fixed remote tree and records the upload target created
+type fakeRemoteTree struct {
+	entries []TreeEntry
+	target  string
+}
+
+func (f *fakeRemoteTree) TreeEntries(dir string) ([]TreeEntry, error) { return f.entries, nil }
+func (f *fakeRemoteTree) UploadTarget(dest, name string) (string, error) {
+	f.target = dest + "/" + name
+	return f.target, nil
+}
+func (f *fakeRemoteTree) Close() error { return nil }
+
+// recordSCPJobs replaces scp with a command that succeeds and records the
+// arguments of every job
+func recordSCPJobs(t *testing.T, remote *fakeRemoteTree) *[][]string {
+	t.Helper()
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("true not available")
+	}
+	var mu sync.Mutex
+	var jobs [][]string
+	origSCP, origTree := scpCommand, openRemoteTree
+	scpCommand = func(args ...string) *exec.Cmd {
+		mu.Lock()
+		jobs = append(jobs, args)
+		mu.Unlock()
+		return exec.Command("true")
+	}
+	openRemoteTree = func(host, configFile string) (remoteTree, error) { return remote, nil }
+	t.Cleanup(func() { scpCommand, openRemoteTree = origSCP, origTree })
+	return &jobs
+}
+
+func TestParallelUploadSplitsTopLevelEntries(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "site")
+	for name, size := range map[string]int{"assets/app.js": 500, "images/logo.png": 400, "index.html": 100} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	remote := &fakeRemoteTree{}
+	jobs := recordSCPJobs(t, remote)
+
+	var out bytes.Buffer
+	req := &TransferRequest{Host: "web", Direction: Upload, LocalPath: root, RemotePath: "/var/www", Recursive: true, Concurrency: 2}
+	if result := req.ExecuteTo(nil, &out, io.Discard); !result.Success {
+		t.Fatalf("Expected the split upload to succeed, got %v", result.Error)
+	}
+
+	if len(*jobs) != 2 {
+		t.Fatalf("Expected 2 scp jobs, got %v", *jobs)
+	}
+	copied := make(map[string]bool)
+	for _, args := range *jobs {
+		if args[len(args)-1] != "web:/var/www/site" {
+			t.Errorf("Expected every job to copy into web:/var/www/site, got %v", args)
+		}
+		if args[0] != "-o" || args[1] != "BatchMode=yes" || args[2] != "-r" {
+			t.Errorf("Expected batch mode and -r first, got %v", args)
+		}
+		for _, arg := range args[3 : len(args)-1] {
+			copied[filepath.Base(arg)] = true
+		}
+	}
+	if len(copied) != 3 || !copied["assets"] || !copied["images"] || !copied["index.html"] {
+		t.Errorf("Expected each top-level entry copied once, got %v", copied)
+	}
+	if !strings.Contains(out.String(), "100%") {
+		t.Errorf("Expected a progress meter reaching 100%%, got %q", out.String())
+	}
+}
+
+func TestParallelDownloadCreatesLocalTarget(t *testing.T) {
+	remote := &fakeRemoteTree{entries: []TreeEntry{{Name: "a", Size: 10}, {Name: "b", Size: 20}}}
+	jobs := recordSCPJobs(t, remote)
+
+	dir := t.TempDir()
+	req := &TransferRequest{Host: "db", Direction: Download, LocalPath: dir, RemotePath: "/srv/backups", Recursive: true, Concurrency: 4}
+	if result := req.ExecuteTo(nil, io.Discard, io.Discard); !result.Success {
+		t.Fatalf("Expected the split download to succeed, got %v", result.Error)
+	}
+
+	target := filepath.Join(dir, "backups")
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		t.Fatalf("Expected %s to be created, got %v", target, err)
+	}
+	if len(*jobs) != 2 {
+		t.Fatalf("Expected one job per entry, got %v", *jobs)
+	}
+	for _, args := range *jobs {
+		if args[len(args)-1] != target || !strings.HasPrefix(args[len(args)-2], "db:/srv/backups/") {
+			t.Errorf("Expected a remote entry copied into %s, got %v", target, args)
+		}
+	}
+}
+
+func TestParallelFallsBackToSingleSCP(t *testing.T) {
+	remote := &fakeRemoteTree{entries: []TreeEntry{{Name: "only", Size: 10}}}
+	jobs := recordSCPJobs(t, remote)
+
+	req := &TransferRequest{Host: "db", Direction: Download, LocalPath: t.TempDir(), RemotePath: "/srv/one", Recursive: true, Concurrency: 4}
+	if result := req.ExecuteTo(nil, io.Discard, io.Discard); !result.Success {
+		t.Fatalf("Expected the transfer to succeed, got %v", result.Error)
+	}
+	if len(*jobs) != 1 || (*jobs)[0][0] != "-r" {
+		t.Errorf("Expected the usual single scp for one entry, got %v", *jobs)
+	}
+
+	// One-off overrides are not known to the session listing the tree
+	req = &TransferRequest{Host: "db", Direction: Download, LocalPath: t.TempDir(), RemotePath: "/srv", Recursive: true, Concurrency: 4, Port: "2222"}
+	if req.splits() {
+		t.Error("Expected a port override to keep the single scp")
+	}
+
+	// Each job would get the whole bandwidth limit
+	req = &TransferRequest{Host: "db", Direction: Download, LocalPath: t.TempDir(), RemotePath: "/srv", Recursive: true, Concurrency: 4, BandwidthLimitKBps: 500}
+	if req.splits() {
+		t.Error("Expected a bandwidth limit to keep the single scp")
+	}
+}
@@ -0,0 +1,155 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// AskpassEnv passes the password of a transfer to sshm when it runs as the
+// SSH_ASKPASS helper of scp or rsync
+const AskpassEnv = "SSHM_ASKPASS_PASSWORD"
+
+// errPasswordProbe ends the login of NeedsPassword once the server asked
+// for a password, so that none is ever sent
+var errPasswordProbe = errors.New("password authentication offered")
+
+// NeedsPassword reports whether scp would prompt for a password to log in to
+// host: none of the keys of the SSH agent and of the host's IdentityFile
+// entries is accepted, and the server offers password or keyboard-interactive
+// authentication. Hosts reached through a ProxyJump or a ProxyCommand, and
+// hosts with a passphrase protected key, are reported as not needing one.
+func NeedsPassword(host, configFile string) (bool, error) {
+	if proxiedHost(host, configFile) {
+		return false, nil
+	}
+
+	hostname, port, user, identity, hostKeys := resolveSSHHost(host, configFile)
+	addr := sshAddress(hostname, port)
+	hostKeyCallback, err := hostKeys.hostKeyCallback(addr)
+	if err != nil {
+		return false, err
+	}
+
+	signers, locked, _, err := hostSigners(identity, &authRecorder{})
+	if err != nil {
+		return false, err
+	}
+	if locked != nil {
+		return false, nil
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:              user,
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: hostKeys.hostKeyAlgorithms(addr),
+		Timeout:           DialTimeout,
+	}
+	return probePassword(addr, sshConfig, signers)
+}
+
+// probePassword logs in to addr with the signers and reports whether the
+// server asked for a password once they were all refused
+func probePassword(addr string, sshConfig *ssh.ClientConfig, signers []ssh.Signer) (bool, error) {
+	asked := false
+	if len(signers) > 0 {
+		sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeys(signers...))
+	}
+	sshConfig.Auth = append(sshConfig.Auth,
+		ssh.PasswordCallback(func() (string, error) {
+			asked = true
+			return "", errPasswordProbe
+		}),
+		ssh.KeyboardInteractive(func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+			// Some servers only send a banner this way
+			if len(questions) == 0 {
+				return nil, nil
+			}
+			asked = true
+			return nil, errPasswordProbe
+		}),
+	)
+
+	client, err := ssh.Dial("tcp", addr, sshConfig)
+	if err == nil {
+		client.Close()
+		return false, nil
+	}
+	if asked {
+		return true, nil
+	}
+	return false, fmt.Errorf("failed to connect: %w", err)
+}
+
+// proxiedHost reports whether host is reached through a ProxyJump or a
+// ProxyCommand, which NeedsPassword cannot follow
+func proxiedHost(host, configFile string) bool {
+	options, err := config.GetResolvedConfig(host, configFile)
+	if err != nil {
+		return false
+	}
+	for _, opt := range options {
+		if (opt.Key == "proxyjump" || opt.Key == "proxycommand") && opt.Value != "none" {
+			return true
+		}
+	}
+	return false
+}
+
+// withPassword makes cmd log in with the password of the request instead of
+// prompting on the terminal: through sshpass when it is installed, otherwise
+// through sshm itself as the SSH_ASKPASS helper, which takes OpenSSH 8.4 or
+// later. Either way the password is in the environment of the processes of
+// the transfer, never on their command line.
+func (r *TransferRequest) withPassword(cmd *exec.Cmd) *exec.Cmd {
+	if r.Password == "" || cmd.Err != nil {
+		return cmd
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	if sshpass, err := exec.LookPath("sshpass"); err == nil {
+		wrapped := exec.Command(sshpass, append([]string{"-e"}, cmd.Args...)...)
+		wrapped.Env = append(env, "SSHPASS="+r.Password)
+		return wrapped
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return cmd
+	}
+	cmd.Env = append(env,
+		"SSH_ASKPASS="+self,
+		"SSH_ASKPASS_REQUIRE=force",
+		AskpassEnv+"="+r.Password,
+	)
+	return cmd
+}
+
+// RunAskpass answers ssh when sshm runs as the SSH_ASKPASS helper of a
+// transfer, args being the prompt. Password prompts get the password of the
+// transfer; any other question, such as accepting an unknown host key, is
+// declined. It reports whether sshm runs as the helper, and its exit code.
+func RunAskpass(args []string, stdout io.Writer) (int, bool) {
+	password, ok := os.LookupEnv(AskpassEnv)
+	if !ok {
+		return 0, false
+	}
+
+	prompt := strings.ToLower(strings.Join(args, " "))
+	if !strings.Contains(prompt, "password") {
+		return 1, true
+	}
+	fmt.Fprintln(stdout, password)
+	return 0, true
+}
@@ -0,0 +1,196 @@
+package transfer
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startAuthServer runs a loopback SSH server that only authenticates, and
+// returns its address
+func startAuthServer(t *testing.T, serverConfig *ssh.ServerConfig) string {
+	t.Helper()
+	serverConfig.AddHostKey(newTestSigner(t))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on loopback: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			serverConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer serverConn.Close()
+				conn, chans, reqs, err := ssh.NewServerConn(serverConn, serverConfig)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					_ = ch.Reject(ssh.Prohibited, "test server")
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func probeConfig() *ssh.ClientConfig {
+	return &ssh.ClientConfig{User: "deploy", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+}
+
+func TestProbePasswordDetectsPasswordOnlyHost(t *testing.T) {
+	var sentPassword []byte
+	addr := startAuthServer(t, &ssh.ServerConfig{
+		PublicKeyCallback: func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, ssh.ErrNoAuth
+		},
+		PasswordCallback: func(_ ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			sentPassword = password
+			return nil, ssh.ErrNoAuth
+		},
+	})
+
+	needed, err := probePassword(addr, probeConfig(), []ssh.Signer{newTestSigner(t)})
+	if err != nil || !needed {
+		t.Fatalf("Expected a password to be needed once the key is refused, got %v, %v", needed, err)
+	}
+	if sentPassword != nil {
+		t.Errorf("Expected the probe never to send a password, server got %q", sentPassword)
+	}
+
+	// Without any key at all
+	if needed, err := probePassword(addr, probeConfig(), nil); err != nil || !needed {
+		t.Errorf("Expected a password to be needed without keys, got %v, %v", needed, err)
+	}
+}
+
+func TestProbePasswordKeyboardInteractive(t *testing.T) {
+	addr := startAuthServer(t, &ssh.ServerConfig{
+		KeyboardInteractiveCallback: func(_ ssh.ConnMetadata, client ssh.KeyboardInteractiveChallenge) (*ssh.Permissions, error) {
+			if _, err := client("", "", []string{"Password: "}, []bool{false}); err != nil {
+				return nil, err
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	})
+
+	if needed, err := probePassword(addr, probeConfig(), nil); err != nil || !needed {
+		t.Errorf("Expected a keyboard-interactive password prompt to count, got %v, %v", needed, err)
+	}
+}
+
+func TestProbePasswordAcceptedKey(t *testing.T) {
+	key := newTestSigner(t)
+	addr := startAuthServer(t, &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, offered ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(offered.Marshal(), key.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+		PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) {
+			return nil, ssh.ErrNoAuth
+		},
+	})
+
+	if needed, err := probePassword(addr, probeConfig(), []ssh.Signer{key}); err != nil || needed {
+		t.Errorf("Expected no password with an accepted key, got %v, %v", needed, err)
+	}
+
+	// A server taking neither the key nor a password is an error, not a password host
+	keyOnly := startAuthServer(t, &ssh.ServerConfig{
+		PublicKeyCallback: func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, ssh.ErrNoAuth
+		},
+	})
+	if needed, err := probePassword(keyOnly, probeConfig(), []ssh.Signer{newTestSigner(t)}); err == nil || needed {
+		t.Errorf("Expected a login error, got %v, %v", needed, err)
+	}
+}
+
+// fakeBinaries puts empty executables with the given names in a directory
+// that becomes the only entry of PATH
+func fakeBinaries(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	return dir
+}
+
+func TestPasswordGoesThroughSSHPass(t *testing.T) {
+	dir := fakeBinaries(t, "scp", "sshpass")
+
+	req := &TransferRequest{Host: "nas", Direction: Upload, LocalPath: "a.txt", RemotePath: "/tmp", Password: "s3cret"}
+	cmd := req.BuildCommand()
+	if cmd.Path != filepath.Join(dir, "sshpass") || cmd.Args[1] != "-e" || cmd.Args[2] != "scp" {
+		t.Fatalf("Expected scp run by sshpass -e, got %s %v", cmd.Path, cmd.Args)
+	}
+	if !slices.Contains(cmd.Env, "SSHPASS=s3cret") {
+		t.Error("Expected the password in SSHPASS")
+	}
+	for _, arg := range cmd.Args {
+		if strings.Contains(arg, "s3cret") {
+			t.Errorf("Expected the password off the command line, got %v", cmd.Args)
+		}
+	}
+	if strings.Contains(req.CommandString(), "s3cret") || strings.Contains(req.CommandString(), "sshpass") {
+		t.Errorf("Expected the shown command to stay plain scp, got %q", req.CommandString())
+	}
+}
+
+func TestPasswordFallsBackToAskpass(t *testing.T) {
+	fakeBinaries(t, "scp")
+
+	req := &TransferRequest{Host: "nas", Direction: Download, LocalPath: t.TempDir(), RemotePath: "/srv", Recursive: true, Concurrency: 4, Password: "s3cret"}
+	cmd := req.BuildCommand()
+	if cmd.Args[0] != "scp" {
+		t.Fatalf("Expected scp itself without sshpass, got %v", cmd.Args)
+	}
+	self, _ := os.Executable()
+	for _, want := range []string{"SSH_ASKPASS=" + self, "SSH_ASKPASS_REQUIRE=force", AskpassEnv + "=s3cret"} {
+		if !slices.Contains(cmd.Env, want) {
+			t.Errorf("Expected %s in the environment of scp", want)
+		}
+	}
+
+	// The jobs of a split transfer run in batch mode, which rules out passwords
+	if req.splits() {
+		t.Error("Expected a password login to keep the single scp")
+	}
+}
+
+func TestRunAskpass(t *testing.T) {
+	var out bytes.Buffer
+	if _, ok := RunAskpass([]string{"deploy@nas's password: "}, &out); ok {
+		t.Fatal("Expected sshm not to act as askpass helper without a password")
+	}
+
+	t.Setenv(AskpassEnv, "s3cret")
+	code, ok := RunAskpass([]string{"deploy@nas's password: "}, &out)
+	if !ok || code != 0 || out.String() != "s3cret\n" {
+		t.Errorf("Expected the password printed for a password prompt, got %d %q", code, out.String())
+	}
+
+	out.Reset()
+	code, ok = RunAskpass([]string{"Are you sure you want to continue connecting (yes/no/[fingerprint])?"}, &out)
+	if !ok || code == 0 || out.Len() != 0 {
+		t.Errorf("Expected any other question to be declined, got %d %q", code, out.String())
+	}
+}
@@ -0,0 +1,353 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progress is one update of scp's progress meter for the file being transferred
+type Progress struct {
+	File    string // File name as shown by scp
+	Percent int    // 0-100
+	Bytes   int64  // Bytes transferred so far (approximate, scp rounds to its display unit)
+	Rate    string // Transfer rate, e.g. "1.2MB/s"
+	ETA     string // Remaining or elapsed time, e.g. "00:12"
+}
+
+// ProgressReporter receives the events of a transfer so callers (TUI, CLI,
+// tests) decide where its output goes instead of scp writing to the terminal
+type ProgressReporter interface {
+	// Started is called before scp is launched
+	Started(req *TransferRequest)
+	// Progress is called for every progress meter update
+	Progress(p Progress)
+	// Output is called for any other line scp prints, such as warnings and errors
+	Output(line string)
+	// Finished is called once with the outcome of the transfer
+	Finished(result *TransferResult)
+}
+
+// discardReporter ignores every event
+type discardReporter struct{}
+
+func (discardReporter) Started(*TransferRequest) {}
+func (discardReporter) Progress(Progress)        {}
+func (discardReporter) Output(string)            {}
+func (discardReporter) Finished(*TransferResult) {}
+
+// writerReporter prints events as plain text lines
+type writerReporter struct {
+	w io.Writer
+}
+
+// NewWriterReporter returns a reporter that prints transfer events to w
+func NewWriterReporter(w io.Writer) ProgressReporter {
+	return writerReporter{w: w}
+}
+
+func (r writerReporter) Started(req *TransferRequest) {
+	source, dest := req.LocalPath, FormatRemoteSpec(req.remoteHost(), req.RemotePath)
+	if req.Direction == Download {
+		source, dest = dest, source
+	}
+	fmt.Fprintf(r.w, "%s %s -> %s\n", req.Direction, source, dest)
+}
+
+func (r writerReporter) Progress(p Progress) {
+	fmt.Fprintf(r.w, "%s %3d%% %s %s %s\n", p.File, p.Percent, FormatByteSize(p.Bytes), p.Rate, p.ETA)
+}
+
+func (r writerReporter) Output(line string) {
+	fmt.Fprintln(r.w, line)
+}
+
+func (r writerReporter) Finished(result *TransferResult) {
+	if result.Success {
+		fmt.Fprintln(r.w, "Transfer complete")
+		return
+	}
+	fmt.Fprintf(r.w, "Transfer failed: %v\n", result.Error)
+}
+
+// reporterWriter turns scp's output into reporter events. scp redraws its
+// progress meter with carriage returns, so both \r and \n end a line.
+type reporterWriter struct {
+	mu       sync.Mutex
+	reporter ProgressReporter
+	buf      []byte
+	last     string           // Last non-progress line, used to explain failures
+	sent     map[string]int64 // Bytes transferred per file
+}
+
+func newReporterWriter(reporter ProgressReporter) *reporterWriter {
+	return &reporterWriter{reporter: reporter, sent: make(map[string]int64)}
+}
+
+func (w *reporterWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		w.handleLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush reports any output left without a trailing newline
+func (w *reporterWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handleLine(string(w.buf))
+	w.buf = nil
+}
+
+func (w *reporterWriter) handleLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	if p, ok := parseProgressLine(line); ok {
+		w.sent[p.File] = p.Bytes
+		w.reporter.Progress(p)
+		return
+	}
+	// rsync names the file on its own line before the progress of that file
+	if p, ok := parseRsyncProgressLine(line); ok {
+		p.File = w.last
+		w.sent[p.File] = p.Bytes
+		w.reporter.Progress(p)
+		return
+	}
+	w.last = line
+	w.reporter.Output(line)
+}
+
+// lastOutput returns the last non-progress line written
+func (w *reporterWriter) lastOutput() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.last
+}
+
+// bytesSent returns the total bytes reported across all files
+func (w *reporterWriter) bytesSent() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var total int64
+	for _, n := range w.sent {
+		total += n
+	}
+	return total
+}
+
+// metered reports whether a progress meter line was written
+func (w *reporterWriter) metered() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.sent) > 0
+}
+
+// measuredProgressInterval is how often reportMeasured reports, a variable so
+// tests can shorten it
+var measuredProgressInterval = MeasureInterval
+
+// reportMeasured reports the progress of r from the size its destination
+// reaches, every measuredProgressInterval for as long as meter has seen no
+// progress meter: scp only draws one when its output is a terminal. The
+// destination is measured as MeasureSource sets up, from the first interval
+// on, so short transfers open no session for it. The returned func stops the
+// reports and is called before Finished.
+func (r *TransferRequest) reportMeasured(reporter ProgressReporter, meter *reporterWriter) (stop func()) {
+	var mu sync.Mutex
+	stopped := false
+	done := make(chan struct{})
+	tracker := NewProgressTracker()
+	tracker.measureInterval = 0 // Every report measures again
+	ticker := time.NewTicker(measuredProgressInterval)
+
+	go func() {
+		defer ticker.Stop()
+		started := time.Now()
+		measuring := false
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if meter.metered() {
+				continue
+			}
+			if !measuring {
+				measuring = true
+				r.MeasureSource(tracker, nil, 0)
+			}
+
+			bytes, total := tracker.Snapshot()
+			mu.Lock()
+			if !stopped {
+				reporter.Progress(measuredProgress(r.sourceName(), bytes, total, time.Since(started)))
+			}
+			mu.Unlock()
+		}
+	}()
+
+	return func() {
+		mu.Lock()
+		stopped = true
+		mu.Unlock()
+		close(done)
+		tracker.Close()
+	}
+}
+
+// sourceName is the name of what r copies, as scp's progress meter shows it
+func (r *TransferRequest) sourceName() string {
+	if r.Direction == Download {
+		return path.Base(r.RemotePath)
+	}
+	return filepath.Base(r.LocalPath)
+}
+
+// measuredProgress builds the Progress of a measured transfer, as scp's meter
+// would show it after elapsed; total is 0 when the size is not known
+func measuredProgress(file string, done, total int64, elapsed time.Duration) Progress {
+	p := Progress{File: file, Bytes: done, ETA: "--:--"}
+	rate := int64(0)
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		rate = int64(float64(done) / seconds)
+	}
+	p.Rate = FormatByteSize(rate) + "/s"
+	if total > 0 {
+		p.Percent = int(done * 100 / total)
+		if rate > 0 {
+			remaining := (total - done) / rate
+			p.ETA = fmt.Sprintf("%02d:%02d", remaining/60, remaining%60)
+		}
+	}
+	return p
+}
+
+// parseProgressLine parses a line of scp's progress meter:
+//
+//	backup.tar.gz   45%  450MB  45.0MB/s   00:12 ETA
+func parseProgressLine(line string) (Progress, bool) {
+	fields := strings.Fields(line)
+	if n := len(fields); n > 0 && fields[n-1] == "ETA" {
+		fields = fields[:n-1]
+	}
+	// file, percent, size, rate, time
+	if len(fields) < 5 {
+		return Progress{}, false
+	}
+
+	k := len(fields) - 4
+	percentField := fields[k]
+	if !strings.HasSuffix(percentField, "%") {
+		return Progress{}, false
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(percentField, "%"))
+	if err != nil || percent < 0 || percent > 100 {
+		return Progress{}, false
+	}
+	size, ok := parseSize(fields[k+1])
+	if !ok || !strings.HasSuffix(fields[k+2], "/s") {
+		return Progress{}, false
+	}
+
+	return Progress{
+		File:    strings.Join(fields[:k], " "),
+		Percent: percent,
+		Bytes:   size,
+		Rate:    fields[k+2],
+		ETA:     fields[k+3],
+	}, true
+}
+
+// parseRsyncProgressLine parses a progress line of rsync --progress, which
+// does not include the file name:
+//
+//	1,048,576  45%   10.00MB/s    0:00:01 (xfr#1, to-chk=3/5)
+func parseRsyncProgressLine(line string) (Progress, bool) {
+	fields := strings.Fields(line)
+	// bytes, percent, rate, time
+	if len(fields) < 4 {
+		return Progress{}, false
+	}
+
+	bytes, err := strconv.ParseInt(strings.ReplaceAll(fields[0], ",", ""), 10, 64)
+	if err != nil || bytes < 0 {
+		return Progress{}, false
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(fields[1], "%"))
+	if err != nil || !strings.HasSuffix(fields[1], "%") || percent < 0 || percent > 100 {
+		return Progress{}, false
+	}
+	if !strings.HasSuffix(fields[2], "/s") {
+		return Progress{}, false
+	}
+
+	return Progress{
+		Percent: percent,
+		Bytes:   bytes,
+		Rate:    fields[2],
+		ETA:     fields[3],
+	}, true
+}
+
+// sizeUnits are the suffixes scp's progress meter uses, in increasing powers of 1024
+var sizeUnits = []string{"KB", "MB", "GB", "TB", "PB"}
+
+// parseSize parses a size such as "1024", "12KB" or "1.5GB"
+func parseSize(s string) (int64, bool) {
+	multiplier := 1.0
+	for i, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit) {
+			s = strings.TrimSuffix(s, unit)
+			for j := 0; j <= i; j++ {
+				multiplier *= 1024
+			}
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "B")
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return int64(value * multiplier), true
+}
+
+// FormatByteSize formats a byte count the way scp's progress meter does, such
+// as "1.3GB"
+func FormatByteSize(n int64) string {
+	if n < 1024 {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+	value := float64(n)
+	unit := ""
+	for _, u := range sizeUnits {
+		if value < 1024 {
+			break
+		}
+		value /= 1024
+		unit = u
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + unit
+}
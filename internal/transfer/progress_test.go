@@ -0,0 +1,244 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeReporter records the events it receives
+type fakeReporter struct {
+	mu       sync.Mutex
+	started  int
+	progress []Progress
+	output   []string
+	result   *TransferResult
+}
+
+func (f *fakeReporter) Started(*TransferRequest) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started++
+}
+
+func (f *fakeReporter) Progress(p Progress) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.progress = append(f.progress, p)
+}
+
+func (f *fakeReporter) Output(line string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.output = append(f.output, line)
+}
+
+func (f *fakeReporter) Finished(result *TransferResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.result = result
+}
+
+// fakeSCP replaces the scp binary with a shell script for the duration of the test
+func fakeSCP(t *testing.T, script string) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	orig := scpCommand
+	scpCommand = func(args ...string) *exec.Cmd {
+		return exec.Command("sh", "-c", script)
+	}
+	t.Cleanup(func() { scpCommand = orig })
+}
+
+func TestParseProgressLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Progress
+		ok       bool
+	}{
+		{
+			line:     "backup.tar.gz   45%  450MB  45.0MB/s   00:12 ETA",
+			expected: Progress{File: "backup.tar.gz", Percent: 45, Bytes: 450 * 1024 * 1024, Rate: "45.0MB/s", ETA: "00:12"},
+			ok:       true,
+		},
+		{
+			line:     "my notes.txt  100% 1024     1.0MB/s   00:00",
+			expected: Progress{File: "my notes.txt", Percent: 100, Bytes: 1024, Rate: "1.0MB/s", ETA: "00:00"},
+			ok:       true,
+		},
+		{line: "scp: /nope: No such file or directory", ok: false},
+		{line: "Warning: Permanently added 'host' to the list of known hosts.", ok: false},
+		{line: "file 150% 1KB 1KB/s 00:00", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseProgressLine(tt.line)
+		if ok != tt.ok {
+			t.Errorf("parseProgressLine(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if ok && got != tt.expected {
+			t.Errorf("parseProgressLine(%q) = %+v, want %+v", tt.line, got, tt.expected)
+		}
+	}
+}
+
+func TestExecuteWithProgressReportsEvents(t *testing.T) {
+	fakeSCP(t, `printf 'data.bin   50%%  512KB 512.0KB/s   00:01 ETA\rdata.bin  100%% 1024KB   1.0MB/s   00:01    \n'`)
+
+	reporter := &fakeReporter{}
+	req := &TransferRequest{Host: "server1", Direction: Upload, LocalPath: "data.bin", RemotePath: "/tmp/"}
+	result := req.ExecuteWithProgress(reporter)
+
+	if !result.Success {
+		t.Fatalf("ExecuteWithProgress() failed: %v", result.Error)
+	}
+	if reporter.started != 1 {
+		t.Errorf("Started called %d times, want 1", reporter.started)
+	}
+	if len(reporter.progress) != 2 {
+		t.Fatalf("Expected 2 progress events, got %+v", reporter.progress)
+	}
+	if reporter.progress[0].Percent != 50 || reporter.progress[1].Percent != 100 {
+		t.Errorf("Unexpected progress percentages: %+v", reporter.progress)
+	}
+	if reporter.result != result {
+		t.Error("Expected Finished to receive the returned result")
+	}
+	if result.BytesSent != 1024*1024 {
+		t.Errorf("BytesSent = %d, want %d", result.BytesSent, 1024*1024)
+	}
+	if len(reporter.output) != 0 {
+		t.Errorf("Expected no output lines, got %v", reporter.output)
+	}
+}
+
+func TestExecuteWithProgressReportsFailure(t *testing.T) {
+	fakeSCP(t, `echo 'scp: /nope: No such file or directory' >&2; exit 1`)
+
+	reporter := &fakeReporter{}
+	req := &TransferRequest{Host: "server1", Direction: Download, LocalPath: ".", RemotePath: "/nope"}
+	result := req.ExecuteWithProgress(reporter)
+
+	if result.Success {
+		t.Fatal("Expected the transfer to fail")
+	}
+	if len(reporter.output) != 1 || !strings.Contains(reporter.output[0], "No such file") {
+		t.Errorf("Expected scp's error as output, got %v", reporter.output)
+	}
+	if !strings.Contains(result.Error.Error(), "No such file") {
+		t.Errorf("Expected error to include scp's message, got %v", result.Error)
+	}
+	if reporter.result == nil || reporter.result.Success {
+		t.Errorf("Expected Finished with a failed result, got %+v", reporter.result)
+	}
+}
+
+func TestExecuteWithProgressInvalidArgs(t *testing.T) {
+	reporter := &fakeReporter{}
+	req := &TransferRequest{Host: "server1", LocalPath: ".", RemotePath: "/tmp", ExtraArgs: []string{"-r"}}
+	result := req.ExecuteWithProgress(reporter)
+
+	if result.Success {
+		t.Fatal("Expected invalid extra args to fail")
+	}
+	if reporter.started != 1 || reporter.result != result {
+		t.Errorf("Expected Started and Finished even when validation fails, got %+v", reporter)
+	}
+}
+
+func TestExecuteWithProgressMeasuresWithoutMeter(t *testing.T) {
+	orig := measuredProgressInterval
+	measuredProgressInterval = 50 * time.Millisecond
+	t.Cleanup(func() { measuredProgressInterval = orig })
+
+	// scp draws no meter off a terminal: the download grows with no output
+	local := filepath.Join(t.TempDir(), "app.log")
+	fakeSCP(t, fmt.Sprintf(`printf 1234 > %[1]s; sleep 0.3; printf 5678 >> %[1]s; sleep 0.3`, local))
+
+	reporter := &fakeReporter{}
+	// The jump host keeps the test from opening an SFTP session for the size
+	req := &TransferRequest{Host: "server1", Direction: Download, LocalPath: local, RemotePath: "/var/log/app.log", JumpHost: "bastion"}
+	result := req.ExecuteWithProgress(reporter)
+	if !result.Success {
+		t.Fatalf("ExecuteWithProgress() failed: %v", result.Error)
+	}
+
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	if len(reporter.progress) == 0 {
+		t.Fatal("Expected progress measured from the destination")
+	}
+	last := reporter.progress[len(reporter.progress)-1]
+	if last.File != "app.log" || last.Bytes != 8 {
+		t.Errorf("Expected the last progress to measure 8 bytes of app.log, got %+v", last)
+	}
+}
+
+func TestExecuteWithProgressSkipsMeasureWithMeter(t *testing.T) {
+	orig := measuredProgressInterval
+	measuredProgressInterval = 50 * time.Millisecond
+	t.Cleanup(func() { measuredProgressInterval = orig })
+
+	fakeSCP(t, `printf 'data.bin   50%%  512KB 512.0KB/s   00:01 ETA\n'; sleep 0.3`)
+
+	reporter := &fakeReporter{}
+	req := &TransferRequest{Host: "server1", Direction: Upload, LocalPath: "data.bin", RemotePath: "/tmp/", JumpHost: "bastion"}
+	req.ExecuteWithProgress(reporter)
+
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	if len(reporter.progress) != 1 {
+		t.Errorf("Expected only scp's meter to be reported, got %+v", reporter.progress)
+	}
+}
+
+func TestMeasuredProgress(t *testing.T) {
+	p := measuredProgress("backup.tar", 512*1024, 1024*1024, 2*time.Second)
+	want := Progress{File: "backup.tar", Percent: 50, Bytes: 512 * 1024, Rate: "256.0KB/s", ETA: "00:02"}
+	if p != want {
+		t.Errorf("measuredProgress() = %+v, want %+v", p, want)
+	}
+	if p := measuredProgress("backup.tar", 1024, 0, time.Second); p.Percent != 0 || p.ETA != "--:--" {
+		t.Errorf("Expected no percentage or ETA without a size, got %+v", p)
+	}
+}
+
+func TestStartTransferReportsEvents(t *testing.T) {
+	fakeSCP(t, `printf 'a.txt  100%%  10KB  10.0KB/s   00:00\n'`)
+
+	reporter := &fakeReporter{}
+	req := &TransferRequest{Host: "server1", Direction: Upload, LocalPath: "a.txt", RemotePath: "/tmp/"}
+	result := <-req.StartTransfer(reporter).Done()
+
+	if !result.Success {
+		t.Fatalf("StartTransfer() failed: %v", result.Error)
+	}
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	if reporter.started != 1 || len(reporter.progress) != 1 || reporter.result != result {
+		t.Errorf("Unexpected events: %+v", reporter)
+	}
+}
+
+func TestWriterReporter(t *testing.T) {
+	var out bytes.Buffer
+	reporter := NewWriterReporter(&out)
+
+	req := &TransferRequest{Host: "server1", Direction: Download, LocalPath: "./app.log", RemotePath: "/var/log/app.log"}
+	reporter.Started(req)
+	reporter.Progress(Progress{File: "app.log", Percent: 100, Bytes: 2048, Rate: "2.0KB/s", ETA: "00:00"})
+	reporter.Finished(&TransferResult{Success: true})
+
+	expected := "Download server1:/var/log/app.log -> ./app.log\napp.log 100% 2.0KB 2.0KB/s 00:00\nTransfer complete\n"
+	if out.String() != expected {
+		t.Errorf("Output = %q, want %q", out.String(), expected)
+	}
+}
@@ -0,0 +1,169 @@
+package transfer
+
+import (
+	"io"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MeasureInterval is how often the destination of a transfer drawing no
+// progress meter is measured again. Every measure walks the whole tree.
+const MeasureInterval = 2 * time.Second
+
+// ProgressTracker sums the progress of a transfer over all its files. It is
+// fed the progress meter of scp or rsync as a ProgressReporter; when no meter
+// is drawn (scp only draws it on terminals) the size reached by the
+// destination is measured instead.
+type ProgressTracker struct {
+	mu       sync.Mutex
+	files    map[string]Progress // Last meter update per file
+	total    int64               // Size of the source, 0 when unknown
+	measure  func() (int64, error)
+	closer   io.Closer
+	finished bool
+
+	measureInterval time.Duration
+	measured        int64     // Last size measured
+	measuredAt      time.Time // When it was measured, zero before the first time
+}
+
+// NewProgressTracker returns a tracker with no size known yet
+func NewProgressTracker() *ProgressTracker {
+	return &ProgressTracker{files: make(map[string]Progress), measureInterval: MeasureInterval}
+}
+
+func (t *ProgressTracker) Started(*TransferRequest) {}
+func (t *ProgressTracker) Output(string)            {}
+
+func (t *ProgressTracker) Progress(p Progress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.files[p.File] = p
+}
+
+// Finished releases what measuring the destination needed
+func (t *ProgressTracker) Finished(*TransferResult) {
+	t.Close()
+}
+
+// SetSource sets the size of the source and how to measure the destination.
+// closer, if any, is closed when the transfer finishes.
+func (t *ProgressTracker) SetSource(total int64, measure func() (int64, error), closer io.Closer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.finished {
+		if closer != nil {
+			closer.Close()
+		}
+		return
+	}
+	t.total, t.measure, t.closer = total, measure, closer
+}
+
+// MeasureSource sets the size of the source of r on tracker, unless the size
+// estimated before the transfer is given as total, and how to measure its
+// destination, which the tracker falls back to when the transfer draws no
+// progress meter. Uploads go into the picked remote directory, or to the
+// picked file when r.RemoteFile is set; the remote side is measured over
+// session, or over a new SFTP session when it is nil, skipped for one-off
+// jump hosts it cannot use. The tracker closes the session once the transfer
+// is over.
+func (r *TransferRequest) MeasureSource(tracker *ProgressTracker, session *SFTPSession, total int64) {
+	if session == nil && r.JumpHost == "" {
+		session, _ = NewSFTPSession(r.Host, r.ConfigFile)
+	}
+	var closer io.Closer
+	if session != nil {
+		closer = session
+	}
+
+	var measure func() (int64, error)
+	switch r.Direction {
+	case Upload:
+		if total == 0 {
+			total, _, _ = EstimateLocalSize(r.LocalPath)
+		}
+		if session != nil {
+			dest := r.RemotePath
+			if !r.RemoteFile {
+				dest = path.Join(r.RemotePath, filepath.Base(r.LocalPath))
+			}
+			measure = func() (int64, error) {
+				walk, err := session.Rewalk(dest)
+				if err != nil {
+					return 0, err
+				}
+				return walk.TotalSize(), nil
+			}
+		}
+	case Download:
+		if session != nil && total == 0 {
+			total, _, _ = session.EstimateRemoteSize(r.RemotePath)
+		}
+		measure = func() (int64, error) {
+			size, _, err := EstimateLocalSize(r.LocalPath)
+			return size, err
+		}
+	}
+
+	tracker.SetSource(total, measure, closer)
+}
+
+// Close stops measuring the destination
+func (t *ProgressTracker) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.finished = true
+	t.measure = nil
+	if t.closer != nil {
+		t.closer.Close()
+		t.closer = nil
+	}
+}
+
+// Snapshot returns the bytes transferred so far and the size of the whole
+// transfer, 0 when it is not known
+func (t *ProgressTracker) Snapshot() (done, total int64) {
+	t.mu.Lock()
+	total = t.total
+	measure := t.measure
+	var estimated int64
+	for _, p := range t.files {
+		done += p.Bytes
+		if p.Percent > 0 {
+			estimated += p.Bytes * 100 / int64(p.Percent)
+		}
+	}
+	metered := len(t.files) > 0
+	measured := t.measured
+	stale := time.Since(t.measuredAt) >= t.measureInterval
+	t.mu.Unlock()
+
+	switch {
+	case metered:
+		// Without the source size, what the meters show is the best guess
+		if total == 0 {
+			total = estimated
+		}
+	case measure != nil && stale:
+		// Measured outside the lock, it may walk a whole directory
+		done = measured
+		if n, err := measure(); err == nil {
+			done = n
+		}
+		t.mu.Lock()
+		t.measured, t.measuredAt = done, time.Now()
+		t.mu.Unlock()
+	case measure != nil:
+		done = measured
+	}
+
+	if total > 0 && done > total {
+		done = total
+	}
+	return done, total
+}
@@ -0,0 +1,73 @@
+package transfer
+
+import "testing"
+
+type fakeCloser struct{ closed bool }
+
+func (c *fakeCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestProgressTrackerSumsFiles(t *testing.T) {
+	tracker := NewProgressTracker()
+	tracker.Progress(Progress{File: "a.txt", Percent: 100, Bytes: 1000})
+	tracker.Progress(Progress{File: "b.txt", Percent: 25, Bytes: 500})
+
+	// Without the source size, the meters give an estimate
+	if done, total := tracker.Snapshot(); done != 1500 || total != 3000 {
+		t.Errorf("Snapshot() = %d, %d, want 1500, 3000", done, total)
+	}
+
+	tracker.SetSource(5000, func() (int64, error) { return 42, nil }, nil)
+	tracker.Progress(Progress{File: "b.txt", Percent: 50, Bytes: 1000})
+	if done, total := tracker.Snapshot(); done != 2000 || total != 5000 {
+		t.Errorf("Snapshot() = %d, %d, want 2000, 5000 with the meters preferred", done, total)
+	}
+}
+
+func TestProgressTrackerMeasuresDestination(t *testing.T) {
+	tracker := NewProgressTracker()
+	tracker.measureInterval = 0
+	size := int64(300)
+	closer := &fakeCloser{}
+	tracker.SetSource(1000, func() (int64, error) { return size, nil }, closer)
+
+	if done, total := tracker.Snapshot(); done != 300 || total != 1000 {
+		t.Errorf("Snapshot() = %d, %d, want 300, 1000", done, total)
+	}
+	// An overwritten destination may start larger than the source
+	size = 4000
+	if done, _ := tracker.Snapshot(); done != 1000 {
+		t.Errorf("Expected the progress to be capped at the total, got %d", done)
+	}
+
+	tracker.Finished(&TransferResult{Success: true})
+	if !closer.closed {
+		t.Error("Expected the measuring session to be closed once finished")
+	}
+
+	late := &fakeCloser{}
+	tracker.SetSource(1000, nil, late)
+	if !late.closed {
+		t.Error("Expected a session opened after the transfer finished to be closed")
+	}
+}
+
+func TestProgressTrackerThrottlesMeasures(t *testing.T) {
+	tracker := NewProgressTracker()
+	walks := 0
+	tracker.SetSource(1000, func() (int64, error) {
+		walks++
+		return int64(walks * 100), nil
+	}, nil)
+
+	for i := 0; i < 3; i++ {
+		if done, _ := tracker.Snapshot(); done != 100 {
+			t.Errorf("Snapshot() = %d, want the first measure 100", done)
+		}
+	}
+	if walks != 1 {
+		t.Errorf("Expected 1 measure within MeasureInterval, got %d", walks)
+	}
+}
@@ -0,0 +1,339 @@
+package transfer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTP version 3 packet types, status codes and attribute flags used by dirReader
+const (
+	fxpInit    = 1
+	fxpVersion = 2
+	fxpClose   = 4
+	fxpOpendir = 11
+	fxpReaddir = 12
+	fxpStatus  = 101
+	fxpHandle  = 102
+	fxpName    = 104
+
+	fxEOF              = 1
+	fxNoSuchFile       = 2
+	fxPermissionDenied = 3
+
+	attrSize        = 0x00000001
+	attrUIDGID      = 0x00000002
+	attrPermissions = 0x00000004
+	attrACModTime   = 0x00000008
+	attrExtended    = 0x80000000
+)
+
+// maxDirPacket bounds the packets dirReader accepts, as OpenSSH's sftp does
+const maxDirPacket = 256 * 1024
+
+var errShortPacket = errors.New("short SFTP packet")
+
+// dirReader lists remote directories over an SFTP channel of its own, a
+// READDIR reply at a time. pkg/sftp's ReadDir reads the whole directory
+// before returning, which a listing capped at a few thousand entries cannot
+// afford on directories holding millions.
+type dirReader struct {
+	ch     io.ReadWriteCloser
+	nextID uint32
+}
+
+// sessionChannel is an SSH session running the sftp subsystem
+type sessionChannel struct {
+	io.Reader
+	io.WriteCloser
+	session *ssh.Session
+}
+
+func (c *sessionChannel) Close() error {
+	return c.session.Close()
+}
+
+// openSFTPChannel starts the sftp subsystem in a new session of client
+func openSFTPChannel(client *ssh.Client) (io.ReadWriteCloser, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		session.Close()
+		return nil, err
+	}
+	return &sessionChannel{Reader: stdout, WriteCloser: stdin, session: session}, nil
+}
+
+// newDirReader negotiates SFTP version 3 over ch
+func newDirReader(ch io.ReadWriteCloser) (*dirReader, error) {
+	d := &dirReader{ch: ch}
+	if err := d.send(fxpInit, binary.BigEndian.AppendUint32(nil, 3)); err != nil {
+		return nil, err
+	}
+	typ, _, err := d.recv()
+	if err != nil {
+		return nil, err
+	}
+	if typ != fxpVersion {
+		return nil, fmt.Errorf("unexpected SFTP packet type %d", typ)
+	}
+	return d, nil
+}
+
+// Close closes the channel, ending any request in flight
+func (d *dirReader) Close() error {
+	return d.ch.Close()
+}
+
+// readDir returns up to limit entries of a directory, in the order the server
+// sends them, without "." and "..". Reading stops at the first READDIR reply
+// that goes past limit, and truncated reports whether it did. After an error
+// the channel is left in an unknown state and should be closed.
+func (d *dirReader) readDir(path string, limit int) (infos []os.FileInfo, truncated bool, err error) {
+	handle, err := d.handleRequest(fxpOpendir, path)
+	if err != nil {
+		return nil, false, err
+	}
+	infos, truncated, err = d.readEntries(handle, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, _, err := d.request(fxpClose, handle); err != nil {
+		return nil, false, err
+	}
+	return infos, truncated, nil
+}
+
+// readEntries sends READDIR requests for an open directory, as readDir
+func (d *dirReader) readEntries(handle string, limit int) (infos []os.FileInfo, truncated bool, err error) {
+	for {
+		typ, data, err := d.request(fxpReaddir, handle)
+		if err != nil {
+			return nil, false, err
+		}
+		if typ != fxpName {
+			if err := statusError(typ, data); err != io.EOF {
+				return nil, false, err
+			}
+			return infos, false, nil
+		}
+
+		entries, err := parseNames(data)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, entry := range entries {
+			if entry.name == "." || entry.name == ".." {
+				continue
+			}
+			if len(infos) == limit {
+				return infos, true, nil
+			}
+			infos = append(infos, entry)
+		}
+	}
+}
+
+// handleRequest sends a request answered with a handle
+func (d *dirReader) handleRequest(typ byte, arg string) (string, error) {
+	replyType, data, err := d.request(typ, arg)
+	if err != nil {
+		return "", err
+	}
+	if replyType != fxpHandle {
+		return "", statusError(replyType, data)
+	}
+	p := packet{data: data}
+	handle := p.string()
+	return handle, p.err
+}
+
+// request sends a request with a single string argument and returns the
+// type and body of its reply, past the request id
+func (d *dirReader) request(typ byte, arg string) (byte, []byte, error) {
+	d.nextID++
+	id := d.nextID
+
+	payload := binary.BigEndian.AppendUint32(nil, id)
+	payload = appendString(payload, arg)
+	if err := d.send(typ, payload); err != nil {
+		return 0, nil, err
+	}
+
+	replyType, data, err := d.recv()
+	if err != nil {
+		return 0, nil, err
+	}
+	p := packet{data: data}
+	if replyID := p.uint32(); p.err != nil || replyID != id {
+		return 0, nil, fmt.Errorf("unexpected SFTP reply to request %d", id)
+	}
+	return replyType, p.data, nil
+}
+
+// send writes a packet in a single write
+func (d *dirReader) send(typ byte, payload []byte) error {
+	buf := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1))
+	buf = append(buf, typ)
+	buf = append(buf, payload...)
+	_, err := d.ch.Write(buf)
+	return err
+}
+
+// recv reads a packet
+func (d *dirReader) recv() (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(d.ch, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length == 0 || length > maxDirPacket {
+		return 0, nil, fmt.Errorf("SFTP packet of %d bytes", length)
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(d.ch, buf); err != nil {
+		return 0, nil, err
+	}
+	return buf[0], buf[1:], nil
+}
+
+// statusError converts a reply that is not the one expected into an error,
+// with the os errors pkg/sftp uses for missing files and denied access
+func statusError(typ byte, data []byte) error {
+	if typ != fxpStatus {
+		return fmt.Errorf("unexpected SFTP packet type %d", typ)
+	}
+	p := packet{data: data}
+	code := p.uint32()
+	msg := p.string()
+	if p.err != nil {
+		return p.err
+	}
+
+	switch code {
+	case fxEOF:
+		return io.EOF
+	case fxNoSuchFile:
+		return os.ErrNotExist
+	case fxPermissionDenied:
+		return os.ErrPermission
+	}
+	if msg == "" {
+		msg = fmt.Sprintf("status %d", code)
+	}
+	return fmt.Errorf("sftp: %s", msg)
+}
+
+// parseNames parses the entries of a NAME reply
+func parseNames(data []byte) ([]*dirEntry, error) {
+	p := packet{data: data}
+	count := p.uint32()
+	var entries []*dirEntry
+	for i := uint32(0); i < count && p.err == nil; i++ {
+		name := p.string()
+		p.string() // long name, as ls -l prints it
+		entries = append(entries, &dirEntry{name: name, stat: p.attrs()})
+	}
+	return entries, p.err
+}
+
+// packet reads the fields of an SFTP packet body; the first error sticks
+type packet struct {
+	data []byte
+	err  error
+}
+
+func (p *packet) uint32() uint32 {
+	if p.err != nil || len(p.data) < 4 {
+		p.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint32(p.data)
+	p.data = p.data[4:]
+	return v
+}
+
+func (p *packet) uint64() uint64 {
+	if p.err != nil || len(p.data) < 8 {
+		p.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint64(p.data)
+	p.data = p.data[8:]
+	return v
+}
+
+func (p *packet) string() string {
+	n := p.uint32()
+	if p.err != nil || uint32(len(p.data)) < n {
+		p.err = errShortPacket
+		return ""
+	}
+	s := string(p.data[:n])
+	p.data = p.data[n:]
+	return s
+}
+
+// attrs reads an ATTRS structure
+func (p *packet) attrs() *sftp.FileStat {
+	stat := &sftp.FileStat{}
+	flags := p.uint32()
+	if flags&attrSize != 0 {
+		stat.Size = p.uint64()
+	}
+	if flags&attrUIDGID != 0 {
+		stat.UID = p.uint32()
+		stat.GID = p.uint32()
+	}
+	if flags&attrPermissions != 0 {
+		stat.Mode = p.uint32()
+	}
+	if flags&attrACModTime != 0 {
+		stat.Atime = p.uint32()
+		stat.Mtime = p.uint32()
+	}
+	if flags&attrExtended != 0 {
+		count := p.uint32()
+		for i := uint32(0); i < count && p.err == nil; i++ {
+			stat.Extended = append(stat.Extended, sftp.StatExtended{ExtType: p.string(), ExtData: p.string()})
+		}
+	}
+	return stat
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// dirEntry is the os.FileInfo of a READDIR entry. Sys returns its
+// *sftp.FileStat, as it does for the entries of pkg/sftp.
+type dirEntry struct {
+	name string
+	stat *sftp.FileStat
+}
+
+func (e *dirEntry) Name() string       { return e.name }
+func (e *dirEntry) Size() int64        { return int64(e.stat.Size) }
+func (e *dirEntry) Mode() os.FileMode  { return e.stat.FileMode() }
+func (e *dirEntry) ModTime() time.Time { return e.stat.ModTime() }
+func (e *dirEntry) IsDir() bool        { return e.Mode().IsDir() }
+func (e *dirEntry) Sys() any           { return e.stat }
@@ -0,0 +1,59 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EditorCommand returns the command editing a local file with the user's
+// editor: $VISUAL, then $EDITOR, then vi (notepad on Windows). The variables
+// may hold arguments, such as "code --wait".
+func EditorCommand(path string) *exec.Cmd {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	fields := strings.Fields(editor)
+	return exec.Command(fields[0], append(fields[1:], path)...)
+}
+
+// NeedsReupload reports whether a file downloaded to be edited was changed,
+// comparing its checksum with the one taken before the editor ran. An editor
+// closed without saving leaves the file as it was and nothing is uploaded.
+func NeedsReupload(sumBefore, localPath string) (bool, error) {
+	sumAfter, err := LocalChecksum(localPath)
+	if err != nil {
+		return false, err
+	}
+	return !ChecksumsMatch(sumBefore, sumAfter), nil
+}
+
+// UploadEdited replaces the content of a remote file with a local copy,
+// keeping the remote file itself so its mode and owner stay the same
+func (s *SFTPSession) UploadEdited(localPath, remotePath string) error {
+	local, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer local.Close()
+
+	remote, err := s.sftp.OpenFile(remotePath, os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for writing: %w", remotePath, err)
+	}
+	if _, err := remote.ReadFrom(local); err != nil {
+		remote.Close()
+		return fmt.Errorf("failed to upload %s: %w", remotePath, err)
+	}
+	return remote.Close()
+}
@@ -0,0 +1,57 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNeedsReupload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nginx.conf")
+	if err := os.WriteFile(path, []byte("worker_processes 1;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	before, err := LocalChecksum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The editor exited without saving
+	if changed, err := NeedsReupload(before, path); err != nil || changed {
+		t.Errorf("Expected an untouched file not to be uploaded, got %v, %v", changed, err)
+	}
+
+	if err := os.WriteFile(path, []byte("worker_processes 4;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := NeedsReupload(before, path); err != nil || !changed {
+		t.Errorf("Expected an edited file to be uploaded, got %v, %v", changed, err)
+	}
+
+	// Saved again with the original content: nothing to upload
+	if err := os.WriteFile(path, []byte("worker_processes 1;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := NeedsReupload(before, path); err != nil || changed {
+		t.Errorf("Expected a file saved unchanged not to be uploaded, got %v, %v", changed, err)
+	}
+
+	if _, err := NeedsReupload(before, filepath.Join(t.TempDir(), "gone")); err == nil {
+		t.Error("Expected an error for a copy removed by the editor")
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "nano")
+	if got := EditorCommand("/tmp/a.conf").Args; !reflect.DeepEqual(got, []string{"nano", "/tmp/a.conf"}) {
+		t.Errorf("EditorCommand() with $EDITOR = %q", got)
+	}
+
+	// $VISUAL wins, and may carry arguments
+	t.Setenv("VISUAL", "code --wait")
+	if got := EditorCommand("/tmp/a.conf").Args; !reflect.DeepEqual(got, []string{"code", "--wait", "/tmp/a.conf"}) {
+		t.Errorf("EditorCommand() with $VISUAL = %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrPartialLarger is returned when resuming a download whose local file is
+// larger than the remote one, so it is not a partial copy of it
+var ErrPartialLarger = errors.New("local file is larger than the remote file")
+
+// remoteFileReader is what resuming a download needs from an SFTPSession
+type remoteFileReader interface {
+	Stat(path string) (*RemoteFile, error)
+	ReadFileFrom(path string, offset int64, w io.Writer) error
+	Close() error
+}
+
+// openRemoteFiles connects to a host to resume a download (replaced in tests)
+var openRemoteFiles = func(host, configFile string) (remoteFileReader, error) {
+	session, err := NewSFTPSession(host, configFile)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// localDownloadPath returns the file a download writes, inside LocalPath when
+// it is a directory as with scp
+func (r *TransferRequest) localDownloadPath() string {
+	if info, err := os.Stat(r.LocalPath); err == nil && info.IsDir() {
+		return filepath.Join(r.LocalPath, pathpkg.Base(r.RemotePath))
+	}
+	return r.LocalPath
+}
+
+// resume continues a resumable download from the size of the local partial
+// file. scp cannot resume, so the missing bytes are streamed over an SSH
+// session instead. handled is false when there is nothing to resume and the
+// transfer should run as usual. The key of an unknown host is confirmed on
+// stdin, unless it is nil.
+func (r *TransferRequest) resume(stdin io.Reader, stdout io.Writer) (result *TransferResult, handled bool) {
+	// The session logs in with the host's config and keys, without one-off
+	// overrides or a password
+	if !r.Resumable || r.Direction != Download || r.Recursive || r.User != "" || r.Port != "" || r.JumpHost != "" || r.Password != "" {
+		return nil, false
+	}
+
+	localPath := r.localDownloadPath()
+	info, err := os.Stat(localPath)
+	if err != nil || info.IsDir() || info.Size() == 0 {
+		return nil, false
+	}
+
+	remote, err := openRemoteFiles(r.Host, r.ConfigFile)
+	var unknown *UnknownHostKeyError
+	if errors.As(err, &unknown) && stdin != nil {
+		if accepted, _ := PromptHostKey(stdin, stdout, unknown); accepted {
+			if err = AcceptHostKey(unknown); err == nil {
+				remote, err = openRemoteFiles(r.Host, r.ConfigFile)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stdout, "Cannot resume (%v), downloading %s again\n", err, localPath)
+		return nil, false
+	}
+	defer remote.Close()
+
+	remoteFile, err := remote.Stat(r.RemotePath)
+	if err != nil || remoteFile.IsDir {
+		// Let the transfer report the problem with the remote path
+		return nil, false
+	}
+
+	partial, total := info.Size(), remoteFile.Size
+	switch {
+	case partial == total:
+		fmt.Fprintf(stdout, "%s is already complete (%s)\n", localPath, FormatByteSize(total))
+		return &TransferResult{Success: true}, true
+	case partial > total:
+		return &TransferResult{
+			Success: false,
+			Error: fmt.Errorf("%w: %s is %s, %s is %s", ErrPartialLarger,
+				localPath, FormatByteSize(partial), FormatRemoteSpec(r.Host, r.RemotePath), FormatByteSize(total)),
+		}, true
+	}
+
+	file, err := os.OpenFile(localPath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return &TransferResult{Success: false, Error: err}, true
+	}
+	defer file.Close()
+
+	fmt.Fprintf(stdout, "Resuming %s at %s of %s\n", localPath, FormatByteSize(partial), FormatByteSize(total))
+	progress := newResumeProgress(stdout, filepath.Base(localPath), partial, total)
+	err = remote.ReadFileFrom(r.RemotePath, partial, io.MultiWriter(file, progress))
+	progress.finish()
+	if err != nil {
+		return &TransferResult{Success: false, Error: err}, true
+	}
+	return &TransferResult{Success: true}, true
+}
+
+// resumeProgressInterval is how often a resumed download redraws its progress
+const resumeProgressInterval = 500 * time.Millisecond
+
+// resumeProgress draws an scp style progress meter for a resumed download or
+// a split transfer, so reporters parse it like scp's own
+type resumeProgress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	name    string
+	start   int64 // Bytes already there before resuming
+	done    int64
+	total   int64
+	started time.Time
+	drawn   time.Time
+}
+
+func newResumeProgress(w io.Writer, name string, start, total int64) *resumeProgress {
+	now := time.Now()
+	return &resumeProgress{w: w, name: name, start: start, done: start, total: total, started: now, drawn: now}
+}
+
+func (p *resumeProgress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done += int64(len(b))
+	if now := time.Now(); now.Sub(p.drawn) >= resumeProgressInterval {
+		p.drawn = now
+		p.draw("\r")
+	}
+	return len(b), nil
+}
+
+// advance counts n more bytes done and redraws the meter
+func (p *resumeProgress) advance(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	p.drawn = time.Now()
+	p.draw("\r")
+}
+
+// finish draws the final state of the meter
+func (p *resumeProgress) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.draw("\n")
+}
+
+func (p *resumeProgress) draw(end string) {
+	elapsed := time.Since(p.started)
+	rate := int64(0)
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		rate = int64(float64(p.done-p.start) / seconds)
+	}
+	percent := 100
+	if p.total > 0 {
+		percent = int(p.done * 100 / p.total)
+	}
+	fmt.Fprintf(p.w, "%s %3d%% %s %s/s %02d:%02d%s", p.name, percent, FormatByteSize(p.done),
+		FormatByteSize(rate), int(elapsed.Minutes()), int(elapsed.Seconds())%60, end)
+}
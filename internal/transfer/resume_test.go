@@ -0,0 +1,182 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRemoteFiles serves a single remote file for resume tests
+type fakeRemoteFiles struct {
+	path    string
+	content []byte
+	offsets []int64 // Offsets passed to ReadFileFrom
+	closed  bool
+}
+
+func (f *fakeRemoteFiles) Stat(path string) (*RemoteFile, error) {
+	if path != f.path {
+		return nil, fmt.Errorf("path does not exist: %s", path)
+	}
+	return &RemoteFile{Name: filepath.Base(path), Path: path, Size: int64(len(f.content))}, nil
+}
+
+func (f *fakeRemoteFiles) ReadFileFrom(path string, offset int64, w io.Writer) error {
+	f.offsets = append(f.offsets, offset)
+	_, err := w.Write(f.content[offset:])
+	return err
+}
+
+func (f *fakeRemoteFiles) Close() error {
+	f.closed = true
+	return nil
+}
+
+func useFakeRemoteFiles(t *testing.T, remote *fakeRemoteFiles) {
+	t.Helper()
+	orig := openRemoteFiles
+	openRemoteFiles = func(host, configFile string) (remoteFileReader, error) {
+		return remote, nil
+	}
+	t.Cleanup(func() { openRemoteFiles = orig })
+}
+
+func TestResumeDownloadAppendsMissingBytes(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	remote := &fakeRemoteFiles{path: "/backups/db.tar.gz", content: content}
+	useFakeRemoteFiles(t, remote)
+	fakeSCP(t, `echo 'scp should not run' >&2; exit 1`)
+
+	// The destination is a directory, so the partial file is inside it
+	dir := t.TempDir()
+	local := filepath.Join(dir, "db.tar.gz")
+	if err := os.WriteFile(local, content[:300], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reporter := &fakeReporter{}
+	req := &TransferRequest{Host: "server1", Direction: Download, LocalPath: dir, RemotePath: "/backups/db.tar.gz", Resumable: true}
+	result := req.ExecuteWithProgress(reporter)
+
+	if !result.Success {
+		t.Fatalf("ExecuteWithProgress() failed: %v", result.Error)
+	}
+	got, _ := os.ReadFile(local)
+	if !bytes.Equal(got, content) {
+		t.Errorf("Expected the file to be completed, got %d bytes", len(got))
+	}
+	if len(remote.offsets) != 1 || remote.offsets[0] != 300 || !remote.closed {
+		t.Errorf("Expected one read from offset 300 and a closed session, got %v (closed %v)", remote.offsets, remote.closed)
+	}
+	if n := len(reporter.progress); n == 0 || reporter.progress[n-1].Percent != 100 || reporter.progress[n-1].File != "db.tar.gz" {
+		t.Errorf("Expected a final progress event at 100%%, got %+v", reporter.progress)
+	}
+	if len(reporter.output) == 0 || reporter.output[0] != "Resuming "+local+" at 300B of 1000B" {
+		t.Errorf("Expected a resume notice, got %v", reporter.output)
+	}
+}
+
+func TestResumeDownloadAlreadyComplete(t *testing.T) {
+	remote := &fakeRemoteFiles{path: "/data/a.txt", content: []byte("hello")}
+	useFakeRemoteFiles(t, remote)
+	fakeSCP(t, `echo 'scp should not run' >&2; exit 1`)
+
+	local := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(local, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &TransferRequest{Host: "server1", Direction: Download, LocalPath: local, RemotePath: "/data/a.txt", Resumable: true}
+	if result := req.ExecuteWithProgress(nil); !result.Success {
+		t.Fatalf("Expected success for a complete file, got %v", result.Error)
+	}
+	if len(remote.offsets) != 0 {
+		t.Error("Expected nothing to be read")
+	}
+}
+
+func TestResumeDownloadLocalLarger(t *testing.T) {
+	useFakeRemoteFiles(t, &fakeRemoteFiles{path: "/data/a.txt", content: []byte("hi")})
+
+	local := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(local, []byte("a different file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &TransferRequest{Host: "server1", Direction: Download, LocalPath: local, RemotePath: "/data/a.txt", Resumable: true}
+	result := req.ExecuteWithProgress(nil)
+	if result.Success || !errors.Is(result.Error, ErrPartialLarger) {
+		t.Fatalf("Expected ErrPartialLarger, got %v", result.Error)
+	}
+	if got, _ := os.ReadFile(local); string(got) != "a different file" {
+		t.Error("Expected the local file to be left untouched")
+	}
+}
+
+func TestResumeDownloadWithoutPartialRunsSCP(t *testing.T) {
+	remote := &fakeRemoteFiles{path: "/data/a.txt", content: []byte("hello")}
+	useFakeRemoteFiles(t, remote)
+	fakeSCP(t, `printf 'a.txt  100%%  5     1.0KB/s   00:00\n'`)
+
+	req := &TransferRequest{Host: "server1", Direction: Download, LocalPath: filepath.Join(t.TempDir(), "a.txt"), RemotePath: "/data/a.txt", Resumable: true}
+	if result := req.ExecuteWithProgress(nil); !result.Success {
+		t.Fatalf("Expected scp to run the download, got %v", result.Error)
+	}
+	if len(remote.offsets) != 0 {
+		t.Error("Expected no resume without a partial file")
+	}
+}
+
+func TestReadFileFrom(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "my file.bin")
+	if err := os.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := newTestSFTPSession(t, dir)
+	var out bytes.Buffer
+	if err := s.ReadFileFrom(path, 4, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "456789" {
+		t.Errorf("ReadFileFrom() = %q, want %q", out.String(), "456789")
+	}
+}
+
+func TestResumeDownloadConfirmsUnknownHostKey(t *testing.T) {
+	content := []byte("0123456789")
+	remote := &fakeRemoteFiles{path: "/data/a.txt", content: content}
+	unknown := &UnknownHostKeyError{Host: "server1", Key: newHostKey(t), KnownHostsFile: filepath.Join(t.TempDir(), "known_hosts")}
+	orig := openRemoteFiles
+	openRemoteFiles = func(host, configFile string) (remoteFileReader, error) {
+		if _, err := os.Stat(unknown.KnownHostsFile); err != nil {
+			return nil, unknown
+		}
+		return remote, nil
+	}
+	t.Cleanup(func() { openRemoteFiles = orig })
+	fakeSCP(t, `echo 'scp should not run' >&2; exit 1`)
+
+	local := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(local, content[:4], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	req := &TransferRequest{Host: "server1", Direction: Download, LocalPath: local, RemotePath: "/data/a.txt", Resumable: true}
+	if result := req.ExecuteTo(strings.NewReader("yes\n"), &out, io.Discard); !result.Success {
+		t.Fatalf("Expected the download to resume once the key is accepted, got %v", result.Error)
+	}
+	if !strings.Contains(out.String(), "Are you sure you want to continue connecting") {
+		t.Errorf("Expected a host key prompt, got %q", out.String())
+	}
+	if got, _ := os.ReadFile(local); !bytes.Equal(got, content) {
+		t.Errorf("Expected the file to be completed, got %q", got)
+	}
+}
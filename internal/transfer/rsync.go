@@ -0,0 +1,116 @@
+package transfer
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// TransferBackend is the program copying the files of a transfer
+type TransferBackend int
+
+const (
+	BackendSCP TransferBackend = iota
+	BackendRsync
+)
+
+func (b TransferBackend) String() string {
+	if b == BackendRsync {
+		return "rsync"
+	}
+	return "scp"
+}
+
+// ParseBackend returns the backend named name, as returned by String
+func ParseBackend(name string) (TransferBackend, bool) {
+	switch strings.ToLower(name) {
+	case "scp":
+		return BackendSCP, true
+	case "rsync":
+		return BackendRsync, true
+	}
+	return BackendSCP, false
+}
+
+// rsyncCommand creates the rsync process (replaced in tests)
+var rsyncCommand = func(args ...string) *exec.Cmd {
+	return exec.Command("rsync", args...)
+}
+
+// rsyncInstalled reports whether rsync is on PATH (replaced in tests)
+var rsyncInstalled = func() bool {
+	_, err := exec.LookPath("rsync")
+	return err == nil
+}
+
+// EffectiveBackend returns the backend the transfer runs with: scp unless
+// rsync is chosen and installed
+func (r *TransferRequest) EffectiveBackend() TransferBackend {
+	if r.Backend == BackendRsync && rsyncInstalled() {
+		return BackendRsync
+	}
+	return BackendSCP
+}
+
+// rsyncArgs assembles the rsync arguments for the transfer. rsync copies
+// directories with -a, so Recursive needs no flag of its own.
+func (r *TransferRequest) rsyncArgs() []string {
+	var sshArgs []string
+	if r.ConfigFile != "" {
+		sshArgs = append(sshArgs, "-F", r.ConfigFile)
+	}
+	// ssh takes the port with a lowercase -p, unlike scp
+	if r.Port != "" {
+		sshArgs = append(sshArgs, "-p", r.Port)
+	}
+	if r.JumpHost != "" {
+		sshArgs = append(sshArgs, "-J", r.JumpHost)
+	}
+	sshArgs = append(sshArgs, sshOptionsFromSCPArgs(r.ExtraArgs)...)
+
+	args := []string{"-avz", "--progress", "-e", ShellCommand("ssh", sshArgs...)}
+	// rsync takes the limit in KB/s, like BandwidthLimitKBps
+	if r.BandwidthLimitKBps > 0 {
+		args = append(args, "--bwlimit="+strconv.Itoa(r.BandwidthLimitKBps))
+	}
+	source, dest := r.endpoints()
+	return append(args, source, dest)
+}
+
+// BuildRsyncCommand builds the rsync command for the transfer, connecting
+// through ssh with the same config file and port as scp would
+func (r *TransferRequest) BuildRsyncCommand() *exec.Cmd {
+	return rsyncCommand(r.rsyncArgs()...)
+}
+
+// sshFlags lists the scp flags ssh accepts with the same meaning, and whether
+// they take a value
+var sshFlags = map[string]bool{
+	"-4": false, "-6": false, "-C": false, "-q": false, "-v": false,
+	"-c": true, "-i": true, "-J": true, "-o": true,
+}
+
+// sshOptionsFromSCPArgs keeps the extra scp arguments that are ssh options, so
+// they still apply when rsync runs ssh. scp's -P becomes ssh's -p and
+// scp-only flags such as -O are dropped.
+func sshOptionsFromSCPArgs(args []string) []string {
+	var options []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		takesValue, isSSHFlag := sshFlags[arg]
+		hasValue := i+1 < len(args)
+		switch {
+		case arg == "-P" && hasValue:
+			options = append(options, "-p", args[i+1])
+			i++
+		case isSSHFlag && takesValue && hasValue:
+			options = append(options, arg, args[i+1])
+			i++
+		case isSSHFlag && !takesValue:
+			options = append(options, arg)
+		case scpValueFlags[arg]:
+			i++ // Skip the value of an scp-only flag
+		}
+	}
+	return options
+}
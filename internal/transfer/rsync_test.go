@@ -0,0 +1,153 @@
+package transfer
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// fakeRsync replaces the rsync binary with a shell script for the duration of
+// the test, and makes rsync look installed or not
+func fakeRsync(t *testing.T, installed bool, script string) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	origCommand, origInstalled := rsyncCommand, rsyncInstalled
+	rsyncCommand = func(args ...string) *exec.Cmd {
+		return exec.Command("sh", "-c", script)
+	}
+	rsyncInstalled = func() bool { return installed }
+	t.Cleanup(func() { rsyncCommand, rsyncInstalled = origCommand, origInstalled })
+}
+
+func TestBuildRsyncCommandArgs(t *testing.T) {
+	fakeRsync(t, true, "")
+
+	tests := []struct {
+		name     string
+		req      TransferRequest
+		expected []string
+	}{
+		{
+			name:     "Upload without config",
+			req:      TransferRequest{Host: "myserver", Direction: Upload, LocalPath: "./site", RemotePath: "/var/www/", Recursive: true},
+			expected: []string{"-avz", "--progress", "-e", "ssh", "./site", "myserver:/var/www/"},
+		},
+		{
+			name: "Download with config file, user and port",
+			req: TransferRequest{
+				Host:       "myserver",
+				Direction:  Download,
+				LocalPath:  "./",
+				RemotePath: "/var/log/app.log",
+				ConfigFile: "/home/me/.ssh/my config",
+				User:       "deploy",
+				Port:       "2222",
+			},
+			expected: []string{"-avz", "--progress", "-e", "ssh -F '/home/me/.ssh/my config' -p 2222", "deploy@myserver:/var/log/app.log", "./"},
+		},
+		{
+			name:     "Through a jump host",
+			req:      TransferRequest{Host: "myserver", Direction: Upload, LocalPath: "a.txt", RemotePath: "/tmp/", JumpHost: "bastion"},
+			expected: []string{"-avz", "--progress", "-e", "ssh -J bastion", "a.txt", "myserver:/tmp/"},
+		},
+		{
+			name:     "With a bandwidth limit",
+			req:      TransferRequest{Host: "myserver", Direction: Upload, LocalPath: "a.txt", RemotePath: "/tmp/", BandwidthLimitKBps: 500},
+			expected: []string{"-avz", "--progress", "-e", "ssh", "--bwlimit=500", "a.txt", "myserver:/tmp/"},
+		},
+		{
+			name: "Extra scp args kept as ssh options",
+			req: TransferRequest{
+				Host:       "myserver",
+				Direction:  Upload,
+				LocalPath:  "a.txt",
+				RemotePath: "/tmp/",
+				ExtraArgs:  []string{"-O", "-o", "Compression=yes", "-l", "800", "-i", "/keys/id"},
+			},
+			expected: []string{"-avz", "--progress", "-e", "ssh -o Compression=yes -i /keys/id", "a.txt", "myserver:/tmp/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.rsyncArgs(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("rsyncArgs() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSSHOptionsFromSCPArgs(t *testing.T) {
+	got := sshOptionsFromSCPArgs([]string{"-T", "-P", "2200", "-C", "-S", "/bin/myssh", "-J", "bastion", "-o"})
+	expected := []string{"-p", "2200", "-C", "-J", "bastion"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("sshOptionsFromSCPArgs() = %q, want %q", got, expected)
+	}
+}
+
+func TestCommandStringBackend(t *testing.T) {
+	req := &TransferRequest{Host: "myserver", Direction: Upload, LocalPath: "a.txt", RemotePath: "/tmp/", Backend: BackendRsync}
+
+	fakeRsync(t, true, "")
+	if got := req.CommandString(); got != "rsync -avz --progress -e ssh a.txt myserver:/tmp/" {
+		t.Errorf("CommandString() = %q", got)
+	}
+	if req.BuildCommand().Args[0] != "sh" {
+		t.Error("Expected BuildCommand to run rsync when it is installed")
+	}
+
+	rsyncInstalled = func() bool { return false }
+	if req.EffectiveBackend() != BackendSCP {
+		t.Error("Expected scp when rsync is not installed")
+	}
+	if got := req.CommandString(); got != "scp a.txt myserver:/tmp/" {
+		t.Errorf("CommandString() without rsync = %q", got)
+	}
+}
+
+func TestExecuteWithProgressRsync(t *testing.T) {
+	fakeRsync(t, true, `printf 'sending incremental file list\nsite/index.html\n      8,192  50%%    1.00MB/s    0:00:01\r     16,384 100%%    2.00MB/s    0:00:00 (xfr#1, to-chk=0/2)\n'`)
+	fakeSCP(t, `echo 'scp should not run' >&2; exit 1`)
+
+	reporter := &fakeReporter{}
+	req := &TransferRequest{Host: "server1", Direction: Upload, LocalPath: "site", RemotePath: "/srv/", Backend: BackendRsync}
+	result := req.ExecuteWithProgress(reporter)
+
+	if !result.Success {
+		t.Fatalf("ExecuteWithProgress() failed: %v", result.Error)
+	}
+	if len(reporter.progress) != 2 {
+		t.Fatalf("Expected 2 progress events, got %+v", reporter.progress)
+	}
+	last := reporter.progress[1]
+	if last.File != "site/index.html" || last.Percent != 100 || last.Bytes != 16384 || last.Rate != "2.00MB/s" {
+		t.Errorf("Unexpected progress %+v", last)
+	}
+	if result.BytesSent != 16384 {
+		t.Errorf("BytesSent = %d, want 16384", result.BytesSent)
+	}
+}
+
+func TestExecuteWithProgressFallsBackToSCP(t *testing.T) {
+	fakeRsync(t, false, `echo 'rsync should not run' >&2; exit 1`)
+	fakeSCP(t, `printf 'a.txt  100%%  10KB  10.0KB/s   00:00\n'`)
+
+	req := &TransferRequest{Host: "server1", Direction: Upload, LocalPath: "a.txt", RemotePath: "/tmp/", Backend: BackendRsync}
+	result := req.ExecuteWithProgress(nil)
+	if !result.Success {
+		t.Fatalf("Expected the scp fallback to succeed, got %v", result.Error)
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	for _, backend := range []TransferBackend{BackendSCP, BackendRsync} {
+		if got, ok := ParseBackend(backend.String()); !ok || got != backend {
+			t.Errorf("ParseBackend(%q) = %v, %v", backend, got, ok)
+		}
+	}
+	if got, ok := ParseBackend(""); ok || got != BackendSCP {
+		t.Errorf("Expected scp and false for an empty name, got %v, %v", got, ok)
+	}
+}
@@ -3,10 +3,17 @@ package transfer
 
 import (
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
 )
 
 // Direction represents the transfer direction
@@ -30,12 +37,30 @@ func (d Direction) String() string {
 
 // TransferRequest represents a file transfer request
 type TransferRequest struct {
-	Host       string    // SSH host name from config
-	Direction  Direction // Upload or Download
-	LocalPath  string    // Local file/directory path
-	RemotePath string    // Remote file/directory path
-	Recursive  bool      // Transfer directories recursively
-	ConfigFile string    // Optional SSH config file path
+	Host       string          // SSH host name from config
+	Direction  Direction       // Upload or Download
+	LocalPath  string          // Local file/directory path
+	RemotePath string          // Remote file/directory path
+	Recursive  bool            // Transfer directories recursively
+	ConfigFile string          // Optional SSH config file path
+	ExtraArgs  []string        // Additional scp arguments (e.g. -O, -T, -o Option=value)
+	User       string          // Optional user overriding the config for this transfer
+	Port       string          // Optional port overriding the config for this transfer
+	JumpHost   string          // Optional jump host for this transfer, passed as -J
+	Backend    TransferBackend // Program copying the files, scp by default
+	Resumable  bool            // Continue a download from an existing partial local file
+	RemoteFile bool            // RemotePath of an upload names the file written, not its directory
+
+	// BandwidthLimitKBps throttles the transfer to this many KB/s, unlimited when zero
+	BandwidthLimitKBps int
+
+	// Concurrency splits a recursive scp transfer into this many scp jobs run
+	// at once, one scp runs when it is 1 or less
+	Concurrency int
+
+	// Password logs in to a host that takes no key, for transfers that cannot
+	// prompt on the terminal. It is never shown with the command.
+	Password string
 }
 
 // TransferResult represents the result of a transfer operation
@@ -50,8 +75,8 @@ type TransferResult struct {
 //   - "./local.txt", "host:/remote/path" -> Upload
 //   - "host:/remote/file.txt", "./local/" -> Download
 func ParseTransferArgs(source, dest string) (*TransferRequest, error) {
-	sourceHasHost := strings.Contains(source, ":")
-	destHasHost := strings.Contains(dest, ":")
+	sourceHost, sourcePath, sourceHasHost := SplitRemoteSpec(source)
+	destHost, destPath, destHasHost := SplitRemoteSpec(dest)
 
 	if sourceHasHost && destHasHost {
 		return nil, fmt.Errorf("cannot transfer between two remote hosts")
@@ -66,16 +91,14 @@ func ParseTransferArgs(source, dest string) (*TransferRequest, error) {
 	if sourceHasHost {
 		// Download: host:/path -> local
 		req.Direction = Download
-		parts := strings.SplitN(source, ":", 2)
-		req.Host = parts[0]
-		req.RemotePath = parts[1]
+		req.Host = sourceHost
+		req.RemotePath = sourcePath
 		req.LocalPath = dest
 	} else {
 		// Upload: local -> host:/path
 		req.Direction = Upload
-		parts := strings.SplitN(dest, ":", 2)
-		req.Host = parts[0]
-		req.RemotePath = parts[1]
+		req.Host = destHost
+		req.RemotePath = destPath
 		req.LocalPath = source
 	}
 
@@ -93,8 +116,72 @@ func ParseTransferArgs(source, dest string) (*TransferRequest, error) {
 	return req, nil
 }
 
-// BuildSCPCommand builds the scp command for the transfer
-func (r *TransferRequest) BuildSCPCommand() *exec.Cmd {
+// SplitRemoteSpec splits an scp-style "host:path" argument. IPv6 literals may be
+// written bracketed ("[::1]:/path", "user@[::1]:/path") or bare ("fe80::1:/path").
+// As with scp, an argument with a slash before the first colon is a local path.
+func SplitRemoteSpec(arg string) (host, remotePath string, ok bool) {
+	user := ""
+	rest := arg
+	if at := strings.Index(arg, "@["); at >= 0 && !strings.Contains(arg[:at], "/") {
+		user, rest = arg[:at+1], arg[at+1:]
+	}
+
+	// Bracketed literal: [addr]:path
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]:")
+		if end < 0 {
+			return "", "", false
+		}
+		return user + rest[1:end], rest[end+2:], true
+	}
+
+	colon := strings.Index(arg, ":")
+	if colon <= 0 {
+		return "", "", false
+	}
+	if slash := strings.Index(arg, "/"); slash >= 0 && slash < colon {
+		return "", "", false
+	}
+
+	// Bare IPv6 literal: everything up to the last colon before the path
+	hostPart := arg
+	if slash := strings.Index(arg, "/"); slash >= 0 {
+		hostPart = arg[:slash]
+	}
+	if last := strings.LastIndex(hostPart, ":"); last > colon {
+		candidate := hostPart[:last]
+		addr := candidate[strings.LastIndex(candidate, "@")+1:]
+		if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+			return candidate, arg[last+1:], true
+		}
+	}
+
+	// Host aliases and names never contain colons, so split on the first one
+	return arg[:colon], arg[colon+1:], true
+}
+
+// FormatRemoteSpec joins a host and remote path into an scp argument,
+// bracketing IPv6 literals so the path separator stays unambiguous
+func FormatRemoteSpec(host, remotePath string) string {
+	user := ""
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		user, host = host[:at+1], host[at+1:]
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return user + host + ":" + remotePath
+}
+
+// scpArgs assembles the scp arguments for the transfer.
+// Managed flags come first, then user-supplied extra args, then source and destination.
+func (r *TransferRequest) scpArgs() []string {
+	source, dest := r.endpoints()
+	return append(r.scpOptions(), source, dest)
+}
+
+// scpOptions returns the managed flags followed by the user-supplied extra args
+func (r *TransferRequest) scpOptions() []string {
 	args := []string{}
 
 	// Add recursive flag if needed
@@ -107,74 +194,127 @@ func (r *TransferRequest) BuildSCPCommand() *exec.Cmd {
 		args = append(args, "-F", r.ConfigFile)
 	}
 
-	// Build source and destination based on direction
-	var source, dest string
+	// scp takes the port with a capital -P, unlike ssh
+	if r.Port != "" {
+		args = append(args, "-P", r.Port)
+	}
+
+	if r.JumpHost != "" {
+		args = append(args, "-J", r.JumpHost)
+	}
+
+	// scp takes the limit in Kbit/s
+	if r.BandwidthLimitKBps > 0 {
+		args = append(args, "-l", strconv.Itoa(r.BandwidthLimitKBps*8))
+	}
+
+	// Add user-supplied extra args (e.g. -O for legacy servers)
+	return append(args, r.ExtraArgs...)
+}
+
+// endpoints returns the source and destination of the transfer based on its direction
+func (r *TransferRequest) endpoints() (source, dest string) {
+	remote := FormatRemoteSpec(r.remoteHost(), r.RemotePath)
 	if r.Direction == Upload {
-		source = r.LocalPath
-		dest = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-	} else {
-		source = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-		dest = r.LocalPath
+		return r.LocalPath, remote
 	}
+	return remote, r.LocalPath
+}
 
-	args = append(args, source, dest)
+// remoteHost returns the host as given to scp, with the user override applied
+func (r *TransferRequest) remoteHost() string {
+	if r.User == "" {
+		return r.Host
+	}
+	host := r.Host
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	return r.User + "@" + host
+}
 
+// scpCommand creates the scp process (replaced in tests)
+var scpCommand = func(args ...string) *exec.Cmd {
 	return exec.Command("scp", args...)
 }
 
-// Execute runs the transfer and returns the result
-func (r *TransferRequest) Execute() *TransferResult {
-	cmd := r.BuildSCPCommand()
-
-	// Connect stdin/stdout/stderr for interactive use (password prompts, etc.)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// BuildSCPCommand builds the scp command for the transfer
+func (r *TransferRequest) BuildSCPCommand() *exec.Cmd {
+	return scpCommand(r.scpArgs()...)
+}
 
-	err := cmd.Run()
-	if err != nil {
-		return &TransferResult{
-			Success: false,
-			Error:   err,
-		}
+// BuildCommand builds the command for the backend of the transfer, falling
+// back to scp when rsync is chosen but not installed. A request with a
+// Password logs in with it.
+func (r *TransferRequest) BuildCommand() *exec.Cmd {
+	if r.EffectiveBackend() == BackendRsync {
+		return r.withPassword(r.BuildRsyncCommand())
 	}
+	return r.withPassword(r.BuildSCPCommand())
+}
 
-	return &TransferResult{
-		Success: true,
+// CommandString returns the command for the transfer as a single line that
+// can be pasted into a POSIX shell
+func (r *TransferRequest) CommandString() string {
+	if r.EffectiveBackend() == BackendRsync {
+		return ShellCommand("rsync", r.rsyncArgs()...)
 	}
+	return ShellCommand("scp", r.scpArgs()...)
 }
 
-// ExecuteWithProgress runs the transfer with progress callback
-// This uses scp's built-in progress indicator
-func (r *TransferRequest) ExecuteWithProgress() *TransferResult {
-	args := []string{}
-
-	// Add recursive flag if needed
-	if r.Recursive {
-		args = append(args, "-r")
+// ShellCommand joins a command and its arguments into a line that can be
+// pasted into a POSIX shell
+func ShellCommand(name string, args ...string) string {
+	words := []string{name}
+	for _, arg := range args {
+		words = append(words, quoteShellWord(arg))
 	}
+	return strings.Join(words, " ")
+}
 
-	// Add config file if specified
-	if r.ConfigFile != "" {
-		args = append(args, "-F", r.ConfigFile)
+// quoteShellWord returns s unchanged when the shell would read it literally,
+// and single-quoted otherwise
+func quoteShellWord(s string) string {
+	if s == "" {
+		return "''"
 	}
+	for _, c := range s {
+		safe := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			strings.ContainsRune("@%+=:,./_-", c)
+		if !safe {
+			return shellQuote(s)
+		}
+	}
+	return s
+}
 
-	// Build source and destination based on direction
-	var source, dest string
-	if r.Direction == Upload {
-		source = r.LocalPath
-		dest = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-	} else {
-		source = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-		dest = r.LocalPath
+// Execute runs the transfer attached to the terminal, so scp can prompt for
+// passwords and draw its own progress meter
+func (r *TransferRequest) Execute() *TransferResult {
+	return r.ExecuteTo(os.Stdin, os.Stdout, os.Stderr)
+}
+
+// ExecuteTo runs the transfer with scp's input and output connected to the
+// given streams. A nil stdin means scp cannot prompt.
+func (r *TransferRequest) ExecuteTo(stdin io.Reader, stdout, stderr io.Writer) *TransferResult {
+	if err := ValidateSCPExtraArgs(r.ExtraArgs); err != nil {
+		return &TransferResult{Success: false, Error: err}
 	}
 
-	args = append(args, source, dest)
+	if result, handled := r.resume(stdin, stdout); handled {
+		return result
+	}
+	if result, handled := r.parallel(stdout, stderr, nil); handled {
+		return result
+	}
 
-	cmd := exec.Command("scp", args...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd := r.BuildCommand()
+	if err := CheckCommand(cmd); err != nil {
+		return &TransferResult{Success: false, Error: err}
+	}
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	err := cmd.Run()
 	if err != nil {
@@ -189,52 +329,114 @@ func (r *TransferRequest) ExecuteWithProgress() *TransferResult {
 	}
 }
 
-// RunningTransfer represents a transfer that can be cancelled
-type RunningTransfer struct {
-	cmd    *exec.Cmd
-	done   chan *TransferResult
-	killed bool
-}
+// ExecuteWithProgress runs the transfer and sends its events to reporter
+// instead of the terminal. scp only draws its progress meter when its output
+// is a terminal, which it is not here: while no meter is drawn, Progress
+// events come from measuring the destination every MeasureInterval.
+func (r *TransferRequest) ExecuteWithProgress(reporter ProgressReporter) *TransferResult {
+	if reporter == nil {
+		reporter = discardReporter{}
+	}
 
-// StartTransfer starts a transfer and returns a RunningTransfer that can be cancelled
-func (r *TransferRequest) StartTransfer() *RunningTransfer {
-	args := []string{}
+	stdout := newReporterWriter(reporter)
+	stderr := newReporterWriter(reporter)
 
-	// Add recursive flag if needed
-	if r.Recursive {
-		args = append(args, "-r")
+	reporter.Started(r)
+	// Nothing is measured for events that are discarded
+	stopMeasuring := func() {}
+	if _, discarded := reporter.(discardReporter); !discarded {
+		stopMeasuring = r.reportMeasured(reporter, stdout)
 	}
+	result := r.ExecuteTo(nil, stdout, stderr)
+	stopMeasuring()
+	result = finishReported(result, stdout, stderr)
+	reporter.Finished(result)
 
-	// Add config file if specified
-	if r.ConfigFile != "" {
-		args = append(args, "-F", r.ConfigFile)
+	return result
+}
+
+// finishReported flushes the reporter writers and completes the result with
+// what they saw: bytes sent on success, scp's last error line on failure
+func finishReported(result *TransferResult, stdout, stderr *reporterWriter) *TransferResult {
+	stdout.Flush()
+	stderr.Flush()
+
+	if result.Success {
+		result.BytesSent = stdout.bytesSent()
+	} else if msg := stderr.lastOutput(); msg != "" && result.Error != nil {
+		result.Error = fmt.Errorf("%w: %s", result.Error, msg)
 	}
+	return result
+}
 
-	// Build source and destination based on direction
-	var source, dest string
-	if r.Direction == Upload {
-		source = r.LocalPath
-		dest = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-	} else {
-		source = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-		dest = r.LocalPath
+// RunningTransfer represents a transfer that can be cancelled
+type RunningTransfer struct {
+	cmd      *exec.Cmd
+	parallel *parallelRun // Processes of a transfer split into several scp jobs
+	done     chan *TransferResult
+	killed   bool
+}
+
+// StartTransfer starts a transfer and returns a RunningTransfer that can be cancelled.
+// Events go to reporter; a nil reporter discards them.
+func (r *TransferRequest) StartTransfer(reporter ProgressReporter) *RunningTransfer {
+	if reporter == nil {
+		reporter = discardReporter{}
 	}
 
-	args = append(args, source, dest)
+	stdout := newReporterWriter(reporter)
+	stderr := newReporterWriter(reporter)
 
-	cmd := exec.Command("scp", args...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd := r.BuildCommand()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	rt := &RunningTransfer{
 		cmd:  cmd,
 		done: make(chan *TransferResult, 1),
 	}
 
+	finish := func(result *TransferResult) {
+		result = finishReported(result, stdout, stderr)
+		reporter.Finished(result)
+		rt.done <- result
+	}
+
+	reporter.Started(r)
+
+	if err := ValidateSCPExtraArgs(r.ExtraArgs); err != nil {
+		finish(&TransferResult{Success: false, Error: err})
+		return rt
+	}
+	if err := CheckCommand(cmd); err != nil {
+		finish(&TransferResult{Success: false, Error: err})
+		return rt
+	}
+
+	// Splitting lists the tree first, so it all happens in the background
+	if r.splits() {
+		rt.parallel = &parallelRun{}
+		go func() {
+			result, handled := r.parallel(stdout, stderr, rt.parallel)
+			if !handled {
+				err := rt.parallel.run(cmd)
+				switch {
+				case rt.parallel.cancelled():
+					result = &TransferResult{Success: false, Error: fmt.Errorf("transfer cancelled")}
+				case err != nil:
+					result = &TransferResult{Success: false, Error: err}
+				default:
+					result = &TransferResult{Success: true}
+				}
+			}
+			finish(result)
+		}()
+		return rt
+	}
+
 	// Start the command
 	if err := cmd.Start(); err != nil {
-		rt.done <- &TransferResult{Success: false, Error: err}
+		finish(&TransferResult{Success: false, Error: err})
 		return rt
 	}
 
@@ -242,11 +444,11 @@ func (r *TransferRequest) StartTransfer() *RunningTransfer {
 	go func() {
 		err := cmd.Wait()
 		if rt.killed {
-			rt.done <- &TransferResult{Success: false, Error: fmt.Errorf("transfer cancelled")}
+			finish(&TransferResult{Success: false, Error: fmt.Errorf("transfer cancelled")})
 		} else if err != nil {
-			rt.done <- &TransferResult{Success: false, Error: err}
+			finish(&TransferResult{Success: false, Error: err})
 		} else {
-			rt.done <- &TransferResult{Success: true}
+			finish(&TransferResult{Success: true})
 		}
 	}()
 
@@ -255,6 +457,10 @@ func (r *TransferRequest) StartTransfer() *RunningTransfer {
 
 // Cancel kills the running transfer
 func (rt *RunningTransfer) Cancel() {
+	if rt.parallel != nil {
+		rt.parallel.kill()
+		return
+	}
 	if rt.cmd != nil && rt.cmd.Process != nil {
 		rt.killed = true
 		rt.cmd.Process.Kill()
@@ -266,6 +472,107 @@ func (rt *RunningTransfer) Done() <-chan *TransferResult {
 	return rt.done
 }
 
+// scpValueFlags lists scp flags that consume the following argument as their value
+var scpValueFlags = map[string]bool{
+	"-c": true, "-D": true, "-i": true, "-J": true, "-l": true,
+	"-o": true, "-P": true, "-S": true, "-X": true,
+}
+
+// ValidateSCPExtraArgs checks that user-supplied scp arguments don't conflict
+// with the arguments sshm manages itself (-r, -F, source and destination)
+func ValidateSCPExtraArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-r":
+			return fmt.Errorf("scp argument %q is managed by sshm", arg)
+		case strings.HasPrefix(arg, "-F"):
+			return fmt.Errorf("scp argument %q conflicts with the config file option; use --config instead", arg)
+		case !strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unexpected scp argument %q: source and destination are managed by sshm", arg)
+		case scpValueFlags[arg]:
+			if i+1 >= len(args) {
+				return fmt.Errorf("scp argument %q requires a value", arg)
+			}
+			i++ // Skip the flag value
+		}
+	}
+	return nil
+}
+
+// ValidateOverrides checks the optional user and port overriding the SSH config
+func ValidateOverrides(user, port string) error {
+	if strings.ContainsAny(user, "@:/ \t") {
+		return fmt.Errorf("invalid user %q", user)
+	}
+	if !validation.ValidatePort(port) {
+		return fmt.Errorf("invalid port %q: must be between 1 and 65535", port)
+	}
+	return nil
+}
+
+// ValidateBandwidthLimit checks a bandwidth limit in KB/s, zero meaning unlimited
+func ValidateBandwidthLimit(kbps int) error {
+	if kbps < 0 {
+		return fmt.Errorf("invalid bandwidth limit %d: must be a positive number of KB/s", kbps)
+	}
+	return nil
+}
+
+// ParseBandwidthLimit reads a bandwidth limit in KB/s as typed in a form,
+// empty meaning unlimited
+func ParseBandwidthLimit(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	kbps, err := strconv.Atoi(s)
+	if err != nil || kbps <= 0 {
+		return 0, fmt.Errorf("invalid bandwidth limit %q: must be a positive number of KB/s", s)
+	}
+	return kbps, nil
+}
+
+// ValidateJumpHost checks a one-off jump host for -J. Each hop of the comma
+// separated list is a host of the SSH config or written as user@host[:port].
+func ValidateJumpHost(jumpHost, configFile string) error {
+	for _, hop := range strings.Split(jumpHost, ",") {
+		hop = strings.TrimSpace(hop)
+		if hop == "" {
+			return fmt.Errorf("invalid jump host %q", jumpHost)
+		}
+
+		if user, address, ok := strings.Cut(hop, "@"); ok {
+			host, port := address, ""
+			if h, p, err := net.SplitHostPort(address); err == nil {
+				host, port = h, p
+			}
+			host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+			if user == "" || strings.ContainsAny(user, ":/ \t") ||
+				!(validation.ValidateHostname(host) || validation.ValidateIP(host)) ||
+				(port != "" && !validation.ValidatePort(port)) {
+				return fmt.Errorf("invalid jump host %q: expected user@host[:port]", hop)
+			}
+			continue
+		}
+
+		var exists bool
+		var err error
+		if configFile != "" {
+			exists, err = config.QuickHostExistsInFile(hop, configFile)
+		} else {
+			exists, err = config.QuickHostExists(hop)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up jump host %q: %w", hop, err)
+		}
+		if !exists {
+			return fmt.Errorf("jump host %q is not in the SSH config; use user@host for other hosts", hop)
+		}
+	}
+	return nil
+}
+
 // ValidateLocalPath checks if a local path is valid for the given direction
 func ValidateLocalPath(path string, direction Direction) error {
 	if direction == Upload {
@@ -321,9 +628,10 @@ func GetLocalFiles(path string) ([]FileInfo, error) {
 			continue
 		}
 		files = append(files, FileInfo{
-			Name:  entry.Name(),
-			IsDir: entry.IsDir(),
-			Size:  info.Size(),
+			Name:    entry.Name(),
+			IsDir:   entry.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
 		})
 	}
 
@@ -332,7 +640,8 @@ func GetLocalFiles(path string) ([]FileInfo, error) {
 
 // FileInfo represents basic file information
 type FileInfo struct {
-	Name  string
-	IsDir bool
-	Size  int64
+	Name    string
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
 }
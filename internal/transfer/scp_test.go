@@ -0,0 +1,383 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildSCPCommandArgOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      TransferRequest
+		expected []string
+	}{
+		{
+			name: "Upload without extra args",
+			req: TransferRequest{
+				Host:       "myserver",
+				Direction:  Upload,
+				LocalPath:  "./file.txt",
+				RemotePath: "/tmp/",
+			},
+			expected: []string{"scp", "./file.txt", "myserver:/tmp/"},
+		},
+		{
+			name: "Upload with all managed and extra args",
+			req: TransferRequest{
+				Host:       "myserver",
+				Direction:  Upload,
+				LocalPath:  "./dir",
+				RemotePath: "/tmp/",
+				Recursive:  true,
+				ConfigFile: "/home/user/.ssh/custom",
+				ExtraArgs:  []string{"-O", "-o", "Compression=yes"},
+			},
+			expected: []string{"scp", "-r", "-F", "/home/user/.ssh/custom", "-O", "-o", "Compression=yes", "./dir", "myserver:/tmp/"},
+		},
+		{
+			name: "Upload through a jump host",
+			req: TransferRequest{
+				Host:       "myserver",
+				Direction:  Upload,
+				LocalPath:  "./file.txt",
+				RemotePath: "/tmp/",
+				Port:       "2222",
+				JumpHost:   "admin@bastion.example.com",
+				ExtraArgs:  []string{"-O"},
+			},
+			expected: []string{"scp", "-P", "2222", "-J", "admin@bastion.example.com", "-O", "./file.txt", "myserver:/tmp/"},
+		},
+		{
+			name: "Upload with a bandwidth limit",
+			req: TransferRequest{
+				Host:               "myserver",
+				Direction:          Upload,
+				LocalPath:          "./file.txt",
+				RemotePath:         "/tmp/",
+				ExtraArgs:          []string{"-O"},
+				BandwidthLimitKBps: 500,
+			},
+			expected: []string{"scp", "-l", "4000", "-O", "./file.txt", "myserver:/tmp/"},
+		},
+		{
+			name: "Download with extra args",
+			req: TransferRequest{
+				Host:       "myserver",
+				Direction:  Download,
+				LocalPath:  "./",
+				RemotePath: "/var/log/app.log",
+				ExtraArgs:  []string{"-T"},
+			},
+			expected: []string{"scp", "-T", "myserver:/var/log/app.log", "./"},
+		},
+		{
+			name: "Download from IPv6 host",
+			req: TransferRequest{
+				Host:       "admin@2001:db8::1",
+				Direction:  Download,
+				LocalPath:  "./",
+				RemotePath: "/etc/hosts",
+			},
+			expected: []string{"scp", "admin@[2001:db8::1]:/etc/hosts", "./"},
+		},
+		{
+			name: "Upload with port override",
+			req: TransferRequest{
+				Host:       "myserver",
+				Direction:  Upload,
+				LocalPath:  "./file.txt",
+				RemotePath: "/tmp/",
+				ConfigFile: "/home/user/.ssh/custom",
+				Port:       "2222",
+				ExtraArgs:  []string{"-O"},
+			},
+			expected: []string{"scp", "-F", "/home/user/.ssh/custom", "-P", "2222", "-O", "./file.txt", "myserver:/tmp/"},
+		},
+		{
+			name: "Download with user override",
+			req: TransferRequest{
+				Host:       "myserver",
+				Direction:  Download,
+				LocalPath:  "./",
+				RemotePath: "/var/log/app.log",
+				User:       "deploy",
+			},
+			expected: []string{"scp", "deploy@myserver:/var/log/app.log", "./"},
+		},
+		{
+			name: "User override replaces user in host",
+			req: TransferRequest{
+				Host:       "admin@2001:db8::1",
+				Direction:  Upload,
+				LocalPath:  "./file.txt",
+				RemotePath: "/srv/",
+				User:       "deploy",
+				Port:       "2200",
+			},
+			expected: []string{"scp", "-P", "2200", "./file.txt", "deploy@[2001:db8::1]:/srv/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tt.req.BuildSCPCommand()
+			if !reflect.DeepEqual(cmd.Args, tt.expected) {
+				t.Errorf("BuildSCPCommand() args = %v, want %v", cmd.Args, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitRemoteSpec(t *testing.T) {
+	tests := []struct {
+		arg      string
+		host     string
+		path     string
+		isRemote bool
+	}{
+		{"myserver:/tmp/file", "myserver", "/tmp/file", true},
+		{"user@myserver:file.txt", "user@myserver", "file.txt", true},
+		{"myserver:", "myserver", "", true},
+		{"[::1]:/tmp/file", "::1", "/tmp/file", true},
+		{"user@[2001:db8::1]:/var/log", "user@2001:db8::1", "/var/log", true},
+		{"[fe80::1%eth0]:~/notes", "fe80::1%eth0", "~/notes", true},
+		{"2001:db8::1:/srv/data", "2001:db8::1", "/srv/data", true},
+		{"192.168.1.10:/srv", "192.168.1.10", "/srv", true},
+		{"./local:file", "", "", false},
+		{"local.txt", "", "", false},
+		{"[::1]", "", "", false},
+		{":/tmp", "", "", false},
+	}
+
+	for _, tt := range tests {
+		host, path, ok := SplitRemoteSpec(tt.arg)
+		if host != tt.host || path != tt.path || ok != tt.isRemote {
+			t.Errorf("SplitRemoteSpec(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.arg, host, path, ok, tt.host, tt.path, tt.isRemote)
+		}
+	}
+}
+
+func TestFormatRemoteSpec(t *testing.T) {
+	tests := []struct {
+		host     string
+		path     string
+		expected string
+	}{
+		{"myserver", "/tmp", "myserver:/tmp"},
+		{"::1", "/tmp", "[::1]:/tmp"},
+		{"user@2001:db8::1", "~/", "user@[2001:db8::1]:~/"},
+		{"user@10.0.0.1", "/srv", "user@10.0.0.1:/srv"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatRemoteSpec(tt.host, tt.path); got != tt.expected {
+			t.Errorf("FormatRemoteSpec(%q, %q) = %q, want %q", tt.host, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestParseTransferArgsIPv6(t *testing.T) {
+	req, err := ParseTransferArgs("[::1]:/var/log/app.log", "./")
+	if err != nil {
+		t.Fatalf("ParseTransferArgs() error = %v", err)
+	}
+	if req.Direction != Download || req.Host != "::1" || req.RemotePath != "/var/log/app.log" {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+
+	// Round trip back to the scp argument
+	args := req.scpArgs()
+	if args[len(args)-2] != "[::1]:/var/log/app.log" {
+		t.Errorf("Expected bracketed remote argument, got %v", args)
+	}
+
+	local := t.TempDir()
+	req, err = ParseTransferArgs(local, "deploy@[2001:db8::5]:/srv/")
+	if err != nil {
+		t.Fatalf("ParseTransferArgs() error = %v", err)
+	}
+	if req.Direction != Upload || req.Host != "deploy@2001:db8::5" || req.RemotePath != "/srv/" || !req.Recursive {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+
+	if _, err := ParseTransferArgs("[::1]:/a", "[::2]:/b"); err == nil {
+		t.Error("Expected an error for two remote hosts")
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	tests := []struct {
+		user    string
+		port    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"deploy", "2222", false},
+		{"deploy", "0", true},
+		{"deploy", "70000", true},
+		{"deploy", "ssh", true},
+		{"me@host", "", true},
+		{"two words", "", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateOverrides(tt.user, tt.port)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateOverrides(%q, %q) error = %v, wantErr %v", tt.user, tt.port, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseBandwidthLimit(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{" 250 ", 250, false},
+		{"0", 0, true},
+		{"-10", 0, true},
+		{"1MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBandwidthLimit(tt.input)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseBandwidthLimit(%q) = (%d, %v), want %d, wantErr %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if err := ValidateBandwidthLimit(-1); err == nil {
+		t.Error("Expected a negative limit to be rejected")
+	}
+	if err := ValidateBandwidthLimit(0); err != nil {
+		t.Errorf("Expected no limit to be valid, got %v", err)
+	}
+}
+
+func TestValidateJumpHost(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	content := "Host bastion\n    HostName 203.0.113.10\n\nHost edge\n    HostName 203.0.113.11\n"
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		jumpHost string
+		wantErr  bool
+	}{
+		{"bastion", false},
+		{"bastion,edge", false},
+		{"admin@bastion.example.com", false},
+		{"admin@203.0.113.10:2222", false},
+		{"admin@[2001:db8::1]:22", false},
+		{"admin@bastion,edge", false},
+		{"unknown", true},
+		{"bastion,", true},
+		{"@bastion.example.com", true},
+		{"admin@", true},
+		{"admin@bastion.example.com:99999", true},
+		{"two words@bastion", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateJumpHost(tt.jumpHost, configFile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateJumpHost(%q) error = %v, wantErr %v", tt.jumpHost, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateSCPExtraArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"Empty", nil, false},
+		{"Legacy protocol flag", []string{"-O"}, false},
+		{"Option with value", []string{"-o", "StrictHostKeyChecking=no"}, false},
+		{"Joined option value", []string{"-oCompression=yes"}, false},
+		{"Port and limit", []string{"-P", "2222", "-l", "1000"}, false},
+		{"Recursive flag is managed", []string{"-r"}, true},
+		{"Config file conflicts", []string{"-F", "/tmp/config"}, true},
+		{"Joined config file conflicts", []string{"-F/tmp/config"}, true},
+		{"Positional argument", []string{"host:/tmp/file"}, true},
+		{"Missing option value", []string{"-o"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSCPExtraArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSCPExtraArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      TransferRequest
+		expected string
+	}{
+		{
+			name:     "Upload",
+			req:      TransferRequest{Host: "myserver", Direction: Upload, LocalPath: "./file.txt", RemotePath: "/tmp/"},
+			expected: "scp ./file.txt myserver:/tmp/",
+		},
+		{
+			name:     "Download",
+			req:      TransferRequest{Host: "myserver", Direction: Download, LocalPath: "/home/me/logs", RemotePath: "/var/log/app.log"},
+			expected: "scp myserver:/var/log/app.log /home/me/logs",
+		},
+		{
+			name:     "Recursive upload with config and extra args",
+			req:      TransferRequest{Host: "myserver", Direction: Upload, LocalPath: "./site", RemotePath: "/srv/www", Recursive: true, ConfigFile: "/etc/ssh/my config", ExtraArgs: []string{"-o", "Compression=yes"}},
+			expected: "scp -r -F '/etc/ssh/my config' -o Compression=yes ./site myserver:/srv/www",
+		},
+		{
+			name:     "Paths needing quotes",
+			req:      TransferRequest{Host: "myserver", Direction: Download, LocalPath: "./it's here", RemotePath: "~/My Documents/$HOME;rm"},
+			expected: `scp 'myserver:~/My Documents/$HOME;rm' './it'\''s here'`,
+		},
+		{
+			name:     "User and port overrides",
+			req:      TransferRequest{Host: "myserver", Direction: Upload, LocalPath: "a.txt", RemotePath: "", User: "deploy", Port: "2222"},
+			expected: "scp -P 2222 a.txt deploy@myserver:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.CommandString(); got != tt.expected {
+				t.Errorf("CommandString() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuoteShellWord(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain-file_1.txt", "plain-file_1.txt"},
+		{"", "''"},
+		{"~/file", "'~/file'"},
+		{"a b", "'a b'"},
+		{"*.log", "'*.log'"},
+		{"it's", `'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if got := quoteShellWord(tt.input); got != tt.expected {
+			t.Errorf("quoteShellWord(%q) = %s, want %s", tt.input, got, tt.expected)
+		}
+	}
+}
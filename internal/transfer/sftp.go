@@ -1,15 +1,21 @@
 package transfer
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
-	"os/exec"
-	"path/filepath"
+	pathpkg "path"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
 )
@@ -20,203 +26,517 @@ type RemoteFile struct {
 	Path    string
 	IsDir   bool
 	Size    int64
-	ModTime string
+	ModTime time.Time   // Zero when the entry came without attributes, as QuickSearch results do
+	Info    os.FileInfo // SFTP attributes of the entry itself, nil for ".." and QuickSearch results
 }
 
 // SFTPSession manages an SFTP connection for browsing
 type SFTPSession struct {
 	client     *ssh.Client
+	sftp       *sftp.Client
 	host       string
 	configFile string
+
+	// runner overrides how remote commands are executed (used in tests)
+	runner func(cmd string, w io.Writer) error
+
+	// commandTimeout bounds each remote command; zero means DefaultCommandTimeout
+	commandTimeout time.Duration
+
+	// walks caches recent WalkDir results
+	walks walkCache
+
+	// dirChannel opens the SFTP channel directories are listed over (overridden in tests)
+	dirChannel func() (io.ReadWriteCloser, error)
+	dirsMu     sync.Mutex
+	dirs       *dirReader
+
+	// authMethod is the key the session logged in with, when known
+	authMethod *AuthMethod
 }
 
-// NewSFTPSession creates a new SFTP session using SSH agent
+// NewSFTPSession creates a new SFTP session, authenticating with the keys of
+// the SSH agent and the host's IdentityFile entries. A *KeyLockedError is
+// returned when only a passphrase protected key could log in, and an
+// *UnknownHostKeyError when the host is not in known_hosts yet.
 func NewSFTPSession(host, configFile string) (*SFTPSession, error) {
-	// Get SSH agent connection
-	socket := os.Getenv("SSH_AUTH_SOCK")
-	if socket == "" {
-		return nil, fmt.Errorf("SSH agent not available (SSH_AUTH_SOCK not set)")
+	client, method, err := dialHost(host, configFile)
+	if err != nil {
+		return nil, err
 	}
 
-	conn, err := net.Dial("unix", socket)
+	sftpClient, err := sftp.NewClient(client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to SSH agent: %w", err)
+		client.Close()
+		return nil, fmt.Errorf("failed to start the SFTP subsystem: %w", err)
 	}
 
-	agentClient := agent.NewClient(conn)
+	session := &SFTPSession{
+		client:     client,
+		sftp:       sftpClient,
+		host:       host,
+		configFile: configFile,
+		authMethod: method,
+	}
+	if appConfig, err := config.LoadAppConfig(); err == nil && appConfig.CommandTimeoutSeconds > 0 {
+		session.SetCommandTimeout(time.Duration(appConfig.CommandTimeoutSeconds) * time.Second)
+	}
+	return session, nil
+}
 
-	// Get signers from agent
-	signers, err := agentClient.Signers()
+// dialHost opens an SSH connection to a host of the SSH config, as described
+// for NewSFTPSession, returning the key it logged in with when known
+func dialHost(host, configFile string) (*ssh.Client, *AuthMethod, error) {
+	// Parse host to get actual hostname and port
+	// The host is an SSH config alias, so we need to resolve it
+	hostname, port, user, identity, hostKeys := resolveSSHHost(host, configFile)
+	addr := sshAddress(hostname, port)
+
+	// Check the host key against known_hosts, as ssh does
+	hostKeyCallback, err := hostKeys.hostKeyCallback(addr)
 	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to get signers from SSH agent: %w", err)
+		return nil, nil, err
+	}
+
+	recorder := &authRecorder{}
+	signers, locked, agentErr, err := hostSigners(identity, recorder)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if len(signers) == 0 {
-		conn.Close()
-		return nil, fmt.Errorf("no keys available in SSH agent")
+		if locked != nil {
+			return nil, nil, locked
+		}
+		if agentErr != nil {
+			return nil, nil, agentErr
+		}
+		return nil, nil, fmt.Errorf("no keys available in SSH agent")
 	}
 
 	// Create SSH config
-	config := &ssh.ClientConfig{
+	sshConfig := &ssh.ClientConfig{
 		Auth: []ssh.AuthMethod{
 			ssh.PublicKeys(signers...),
 		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // TODO: proper host key verification
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: hostKeys.hostKeyAlgorithms(addr),
+		Timeout:           DialTimeout,
 	}
-
-	// Parse host to get actual hostname and port
-	// The host is an SSH config alias, so we need to resolve it
-	hostname, port, user := resolveSSHHost(host, configFile)
 	if user != "" {
-		config.User = user
+		sshConfig.User = user
 	}
 
-	addr := fmt.Sprintf("%s:%s", hostname, port)
-
 	// Connect
-	client, err := ssh.Dial("tcp", addr, config)
+	client, err := ssh.Dial("tcp", addr, sshConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect: %w", err)
+		// The other keys were refused, the locked one may be the right one
+		if locked != nil && strings.Contains(err.Error(), "unable to authenticate") {
+			return nil, nil, locked
+		}
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
-	return &SFTPSession{
-		client:     client,
-		host:       host,
-		configFile: configFile,
-	}, nil
+	if method, ok := recorder.succeeded(); ok {
+		return client, &method, nil
+	}
+	return client, nil, nil
+}
+
+// hostSigners returns the keys offered to a host, reporting to recorder the
+// one that logs in. Keys held by the SSH agent come first, as with ssh, then
+// the identity files usable without a passphrase. locked is the first
+// passphrase protected identity file, and agentErr why the agent could not
+// be reached.
+func hostSigners(identity identitySettings, recorder *authRecorder) (signers []ssh.Signer, locked *KeyLockedError, agentErr, err error) {
+	var agentKeys []*agent.Key
+	agentClient, closeAgent, agentErr := dialAgent()
+	if agentErr == nil {
+		defer closeAgent()
+		agentSigners, err := agentClient.Signers()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get signers from SSH agent: %w", err)
+		}
+		agentKeys, _ = agentClient.List()
+		for _, signer := range agentSigners {
+			comment := agentKeyComment(agentKeys, signer.PublicKey())
+			signers = append(signers, recorder.wrap(signer, AuthMethod{Agent: true, Key: comment}))
+		}
+	}
+
+	keySigners, locked := identitySigners(identity, agentKeys)
+	for _, key := range keySigners {
+		signers = append(signers, recorder.wrap(key.Signer, AuthMethod{Key: key.path}))
+	}
+	return signers, locked, agentErr, nil
 }
 
-// resolveSSHHost resolves an SSH config alias to hostname, port, and user
-func resolveSSHHost(host, configFile string) (hostname, port, user string) {
+// sshAddress builds the dial address for a host and port, bracketing IPv6 literals
+func sshAddress(hostname, port string) string {
+	hostname = strings.TrimSuffix(strings.TrimPrefix(hostname, "["), "]")
+	return net.JoinHostPort(hostname, port)
+}
+
+// resolveSSHHost resolves an SSH config alias to hostname, port, user, identity
+// and host key settings
+func resolveSSHHost(host, configFile string) (hostname, port, user string, identity identitySettings, hostKeys hostKeySettings) {
 	// Default values
 	hostname = host
 	port = "22"
 	user = os.Getenv("USER")
+	hostKeys = defaultHostKeySettings()
 
 	// Try to resolve using ssh -G
-	args := []string{"-G", host}
-	if configFile != "" {
-		args = []string{"-F", configFile, "-G", host}
-	}
-
-	cmd := exec.Command("ssh", args...)
-	output, err := cmd.Output()
+	options, err := config.GetResolvedConfig(host, configFile)
 	if err != nil {
 		return
 	}
 
-	// Parse the output
-	for _, line := range strings.Split(string(output), "\n") {
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.ToLower(parts[0])
-		value := parts[1]
-
-		switch key {
+	for _, opt := range options {
+		switch opt.Key {
 		case "hostname":
-			hostname = value
+			hostname = opt.Value
 		case "port":
-			port = value
+			port = opt.Value
 		case "user":
-			user = value
+			user = opt.Value
+		case "identityfile":
+			identity.files = append(identity.files, opt.Value)
+		case "addkeystoagent":
+			identity.addKeysToAgent = opt.Value
+		case "userknownhostsfile":
+			hostKeys.userFiles = knownHostsFiles(opt.Value)
+		case "globalknownhostsfile":
+			hostKeys.globalFiles = knownHostsFiles(opt.Value)
+		case "stricthostkeychecking":
+			hostKeys.strict = opt.Value
+		case "hostkeyalias":
+			hostKeys.alias = opt.Value
 		}
 	}
 
 	return
 }
 
-// ListDirectory lists files in a remote directory
+// DefaultListLimit caps how many entries of a single directory are kept in memory
+const DefaultListLimit = 5000
+
+// ListBatchSize is how many entries are delivered at once while streaming a listing
+const ListBatchSize = 500
+
+// ListDirectory lists files in a remote directory, keeping at most DefaultListLimit entries
 func (s *SFTPSession) ListDirectory(path string) ([]RemoteFile, error) {
-	// Use SSH to list directory since we're not using full SFTP library
-	session, err := s.client.NewSession()
+	var files []RemoteFile
+	_, err := s.ListDirectoryStream(path, DefaultListLimit, func(batch []RemoteFile) {
+		files = append(files, batch...)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+		return nil, err
+	}
+
+	SortRemoteFiles(files)
+	return files, nil
+}
+
+// ListDirectoryStream lists a remote directory, handing entries to onBatch in name order.
+// Only the first limit entries the server sends are read and delivered; truncated reports
+// whether the directory had more.
+// Symlinks are followed over the same SFTP connection to tell which lead to directories.
+func (s *SFTPSession) ListDirectoryStream(path string, limit int, onBatch func([]RemoteFile)) (truncated bool, err error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
 	}
-	defer session.Close()
 
 	// Expand ~ to home directory
 	if strings.HasPrefix(path, "~") {
-		// Get home directory
-		homeSession, err := s.client.NewSession()
+		home, err := s.GetHomeDirectory()
+		if IsTimeout(err) {
+			return false, err
+		}
 		if err == nil {
-			homeOutput, err := homeSession.Output("echo $HOME")
-			homeSession.Close()
-			if err == nil {
-				home := strings.TrimSpace(string(homeOutput))
-				path = strings.Replace(path, "~", home, 1)
-			}
+			path = strings.Replace(path, "~", home, 1)
 		}
 	}
 
-	// List directory with details
-	cmd := fmt.Sprintf("ls -la %q 2>/dev/null | tail -n +2", path)
-	output, err := session.Output(cmd)
+	infos, truncated, err := s.readDir(path, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list directory: %w", err)
+		return false, fmt.Errorf("failed to list directory: %w", err)
 	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
 
-	var files []RemoteFile
+	var batch []RemoteFile
 
 	// Add parent directory entry
 	if path != "/" {
-		files = append(files, RemoteFile{
+		batch = append(batch, RemoteFile{
 			Name:  "..",
-			Path:  filepath.Dir(path),
+			Path:  pathpkg.Dir(pathpkg.Clean(path)),
 			IsDir: true,
 		})
 	}
 
-	for _, line := range strings.Split(string(output), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	for _, info := range infos {
+		file := remoteFileFromInfo(path, info)
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := s.stat(file.Path)
+			if IsTimeout(err) {
+				return false, err
+			}
+			// Broken links are listed as files
+			file.IsDir = err == nil && target.IsDir()
 		}
 
-		// Parse ls -la output
-		// drwxr-xr-x  2 user group  4096 Jan  1 12:00 dirname
-		fields := strings.Fields(line)
-		if len(fields) < 9 {
-			continue
+		batch = append(batch, file)
+		if len(batch) >= ListBatchSize {
+			onBatch(batch)
+			batch = nil
 		}
+	}
 
-		permissions := fields[0]
-		size := int64(0)
-		fmt.Sscanf(fields[4], "%d", &size)
-		name := strings.Join(fields[8:], " ")
+	if len(batch) > 0 {
+		onBatch(batch)
+	}
+	return truncated, nil
+}
 
-		// Skip . and .. entries from ls output
-		if name == "." || name == ".." {
-			continue
+// dirListing is what a readDir request hands back, with the reader to keep
+type dirListing struct {
+	reader    *dirReader
+	infos     []os.FileInfo
+	truncated bool
+}
+
+// readDir reads up to limit entries of a directory, stopping there rather
+// than reading the rest of it. The channel it reads over is kept for the next
+// listing, and replaced after an error.
+func (s *SFTPSession) readDir(path string, limit int) ([]os.FileInfo, bool, error) {
+	s.dirsMu.Lock()
+	defer s.dirsMu.Unlock()
+
+	reader := s.dirs
+	s.dirs = nil
+	done := make(chan dirListing, 1)
+	err := s.sftpCall("readdir "+path, func() error {
+		listing := dirListing{reader: reader}
+		var err error
+		if listing.reader == nil {
+			if listing.reader, err = s.openDirReader(); err != nil {
+				done <- listing
+				return err
+			}
+		}
+		listing.infos, listing.truncated, err = listing.reader.readDir(path, limit)
+		if err != nil {
+			listing.reader.Close()
+			listing.reader = nil
+		}
+		done <- listing
+		return err
+	})
+	if IsTimeout(err) {
+		// Closing the channel ends the request in flight; one still being
+		// opened is closed once it is
+		if reader != nil {
+			reader.Close()
 		}
+		go func() {
+			if listing := <-done; listing.reader != nil {
+				listing.reader.Close()
+			}
+		}()
+	}
+	if err != nil {
+		return nil, false, err
+	}
 
-		isDir := strings.HasPrefix(permissions, "d")
-		isLink := strings.HasPrefix(permissions, "l")
+	listing := <-done
+	s.dirs = listing.reader
+	return listing.infos, listing.truncated, nil
+}
 
-		// Handle symlinks
-		if isLink {
-			// Check if link points to directory
-			checkSession, err := s.client.NewSession()
-			if err == nil {
-				linkPath := filepath.Join(path, name)
-				checkCmd := fmt.Sprintf("test -d %q && echo dir", linkPath)
-				checkOutput, _ := checkSession.Output(checkCmd)
-				checkSession.Close()
-				isDir = strings.TrimSpace(string(checkOutput)) == "dir"
-			}
+// openDirReader opens a channel to the SFTP server for readDir
+func (s *SFTPSession) openDirReader() (*dirReader, error) {
+	open := s.dirChannel
+	if open == nil {
+		open = func() (io.ReadWriteCloser, error) { return openSFTPChannel(s.client) }
+	}
+	ch, err := open()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newDirReader(ch)
+	if err != nil {
+		ch.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// remoteFileFromInfo converts the SFTP attributes of an entry of dir
+func remoteFileFromInfo(dir string, info os.FileInfo) RemoteFile {
+	return RemoteFile{
+		Name:    info.Name(),
+		Path:    pathpkg.Join(dir, info.Name()),
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Info:    info,
+	}
+}
+
+// DefaultCommandTimeout is how long a remote command may run before it is abandoned
+const DefaultCommandTimeout = 30 * time.Second
+
+// DialTimeout bounds how long connecting to the remote host may take
+const DialTimeout = 15 * time.Second
+
+// ErrCommandTimeout is returned when a remote command does not finish in time
+var ErrCommandTimeout = errors.New("remote command timed out")
+
+// IsTimeout reports whether err was caused by a remote command timing out
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrCommandTimeout)
+}
+
+// SetCommandTimeout changes how long remote commands may run; zero restores the default
+func (s *SFTPSession) SetCommandTimeout(d time.Duration) {
+	s.commandTimeout = d
+}
+
+// timeout returns the effective per-command deadline
+func (s *SFTPSession) timeout() time.Duration {
+	if s.commandTimeout > 0 {
+		return s.commandTimeout
+	}
+	return DefaultCommandTimeout
+}
+
+// runCommand runs a command on the remote host, writing its stdout to w.
+// A command that outlives the session timeout is abandoned with ErrCommandTimeout.
+func (s *SFTPSession) runCommand(cmd string, w io.Writer) error {
+	// Output written after the deadline is dropped so callers can reuse w
+	out := &cutoffWriter{w: w}
+
+	var session *ssh.Session
+	run := s.runner
+	if run == nil {
+		var err error
+		session, err = s.client.NewSession()
+		if err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
 		}
+		defer session.Close()
 
-		files = append(files, RemoteFile{
-			Name:  name,
-			Path:  filepath.Join(path, name),
-			IsDir: isDir,
-			Size:  size,
-		})
+		run = func(cmd string, w io.Writer) error {
+			session.Stdout = w
+			return session.Run(cmd)
+		}
 	}
 
-	// Sort: directories first, then by name
+	done := make(chan error, 1)
+	go func() {
+		done <- run(cmd, out)
+	}()
+
+	timer := time.NewTimer(s.timeout())
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		out.cut()
+		if session != nil {
+			// Ask the remote side to stop, then tear the channel down
+			_ = session.Signal(ssh.SIGKILL)
+			session.Close()
+		}
+		return fmt.Errorf("%w after %s: %s", ErrCommandTimeout, s.timeout(), commandName(cmd))
+	}
+}
+
+// sftpCall runs an SFTP request, abandoning it with ErrCommandTimeout when it
+// outlives the session timeout like a remote command would
+func (s *SFTPSession) sftpCall(op string, call func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- call()
+	}()
+
+	timer := time.NewTimer(s.timeout())
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w after %s: %s", ErrCommandTimeout, s.timeout(), op)
+	}
+}
+
+// stat returns the attributes of a remote path, following symlinks
+func (s *SFTPSession) stat(path string) (os.FileInfo, error) {
+	var info os.FileInfo
+	err := s.sftpCall("stat "+path, func() error {
+		var err error
+		info, err = s.sftp.Stat(path)
+		return err
+	})
+	return info, err
+}
+
+// output runs a command on the remote host and returns its stdout
+func (s *SFTPSession) output(cmd string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := s.runCommand(cmd, &buf)
+	return buf.Bytes(), err
+}
+
+// commandName returns the program name of a shell command, for error messages
+func commandName(cmd string) string {
+	fields := strings.Fields(cmd)
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case strings.Contains(fields[i], "="):
+			// Environment assignment such as LC_ALL=C
+		case fields[i] == "timeout":
+			i++ // Skip the duration argument
+		default:
+			return fields[i]
+		}
+	}
+	return cmd
+}
+
+// cutoffWriter forwards writes until cut, then silently discards them
+type cutoffWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	off bool
+}
+
+func (c *cutoffWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.off {
+		return len(p), nil
+	}
+	return c.w.Write(p)
+}
+
+func (c *cutoffWriter) cut() {
+	c.mu.Lock()
+	c.off = true
+	c.mu.Unlock()
+}
+
+// shellQuote quotes s for safe use as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// SortRemoteFiles sorts files with ".." first, then directories, then by name
+func SortRemoteFiles(files []RemoteFile) {
 	sort.Slice(files, func(i, j int) bool {
 		if files[i].Name == ".." {
 			return true
@@ -229,28 +549,83 @@ func (s *SFTPSession) ListDirectory(path string) ([]RemoteFile, error) {
 		}
 		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
 	})
+}
 
-	return files, nil
+// SortRemoteFilesByModified sorts files with ".." first, then newest first.
+// Directories are not grouped so fresh log files and rotated folders mix by age.
+func SortRemoteFilesByModified(files []RemoteFile) {
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Name == ".." {
+			return files[j].Name != ".."
+		}
+		if files[j].Name == ".." {
+			return false
+		}
+		if !files[i].ModTime.Equal(files[j].ModTime) {
+			return files[i].ModTime.After(files[j].ModTime)
+		}
+		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
+	})
 }
 
-// GetHomeDirectory returns the remote home directory
-func (s *SFTPSession) GetHomeDirectory() (string, error) {
-	session, err := s.client.NewSession()
-	if err != nil {
-		return "", err
+// logDirNames are directory names that hold logs
+var logDirNames = map[string]bool{
+	"log":     true,
+	"logs":    true,
+	"journal": true,
+}
+
+// IsLogDirectory reports whether path looks like a log directory, such as
+// /var/log, /var/log/nginx or ~/app/logs, where the newest files matter most
+func IsLogDirectory(path string) bool {
+	for _, part := range strings.Split(strings.ToLower(path), "/") {
+		if logDirNames[part] {
+			return true
+		}
+		for _, suffix := range []string{"-log", "_log", "-logs", "_logs", ".log", ".logs"} {
+			if strings.HasSuffix(part, suffix) {
+				return true
+			}
+		}
 	}
-	defer session.Close()
+	return false
+}
 
-	output, err := session.Output("echo $HOME")
+// GetHomeDirectory returns the remote home directory, where SFTP sessions start
+func (s *SFTPSession) GetHomeDirectory() (string, error) {
+	var home string
+	err := s.sftpCall("getwd", func() error {
+		var err error
+		home, err = s.sftp.Getwd()
+		return err
+	})
+	return home, err
+}
+
+// AuthorizedKeys returns the content of the remote ~/.ssh/authorized_keys,
+// empty when the file doesn't exist yet
+func (s *SFTPSession) AuthorizedKeys() (string, error) {
+	output, err := s.output("cat ~/.ssh/authorized_keys 2>/dev/null || true")
 	if err != nil {
 		return "", err
 	}
+	return string(output), nil
+}
 
-	return strings.TrimSpace(string(output)), nil
+// AuthMethod returns the key the session logged in with. It is unknown when
+// the server let the session in without checking a key.
+func (s *SFTPSession) AuthMethod() (AuthMethod, bool) {
+	if s.authMethod == nil {
+		return AuthMethod{}, false
+	}
+	return *s.authMethod, true
 }
 
 // Close closes the SFTP session
 func (s *SFTPSession) Close() error {
+	if s.sftp != nil {
+		s.sftp.Close()
+	}
 	if s.client != nil {
 		return s.client.Close()
 	}
@@ -259,58 +634,84 @@ func (s *SFTPSession) Close() error {
 
 // ReadFile reads a remote file (for small files only)
 func (s *SFTPSession) ReadFile(path string, w io.Writer) error {
-	session, err := s.client.NewSession()
-	if err != nil {
+	// Output written after the deadline is dropped so callers can reuse w
+	out := &cutoffWriter{w: w}
+	err := s.sftpCall("read "+path, func() error {
+		file, err := s.sftp.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = file.WriteTo(out)
 		return err
+	})
+	if IsTimeout(err) {
+		out.cut()
 	}
-	defer session.Close()
-
-	session.Stdout = w
-	return session.Run(fmt.Sprintf("cat %q", path))
+	return err
 }
 
-// Stat returns file info for a remote path
-func (s *SFTPSession) Stat(path string) (*RemoteFile, error) {
-	session, err := s.client.NewSession()
+// ReadFileFrom streams a remote file from offset to its end, to resume a download.
+// Unlike ReadFile it has no deadline.
+func (s *SFTPSession) ReadFileFrom(path string, offset int64, w io.Writer) error {
+	file, err := s.sftp.Open(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer session.Close()
+	defer file.Close()
 
-	cmd := fmt.Sprintf("ls -ld %q 2>/dev/null", path)
-	output, err := session.Output(cmd)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = file.WriteTo(w)
+	return err
+}
+
+// ReadTextFile reads a small remote text file, refusing large or binary content
+func (s *SFTPSession) ReadTextFile(path string) ([]byte, error) {
+	// Read one byte past the limit so oversized files are detected without reading them fully
+	var data []byte
+	err := s.sftpCall("read "+path, func() error {
+		file, err := s.sftp.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		data, err = io.ReadAll(io.LimitReader(file, MaxTextContentSize+1))
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("path does not exist: %s", path)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	line := strings.TrimSpace(string(output))
-	fields := strings.Fields(line)
-	if len(fields) < 9 {
-		return nil, fmt.Errorf("unexpected ls output")
+	if err := CheckTextContent(data); err != nil {
+		return nil, err
 	}
+	return data, nil
+}
 
-	permissions := fields[0]
-	size := int64(0)
-	fmt.Sscanf(fields[4], "%d", &size)
-	name := filepath.Base(path)
+// Stat returns file info for a remote path, following symlinks
+func (s *SFTPSession) Stat(path string) (*RemoteFile, error) {
+	info, err := s.stat(path)
+	if IsTimeout(err) {
+		return nil, err
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("path does not exist: %s", path)
+		}
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
 
-	return &RemoteFile{
-		Name:  name,
-		Path:  path,
-		IsDir: strings.HasPrefix(permissions, "d"),
-		Size:  size,
-	}, nil
+	file := remoteFileFromInfo(pathpkg.Dir(path), info)
+	file.Name = pathpkg.Base(path)
+	file.Path = path
+	return &file, nil
 }
 
 // HasLocate checks if locate/mlocate is available on the remote system
 func (s *SFTPSession) HasLocate() bool {
-	session, err := s.client.NewSession()
-	if err != nil {
-		return false
-	}
-	defer session.Close()
-
-	err = session.Run("which locate >/dev/null 2>&1 || which mlocate >/dev/null 2>&1")
+	err := s.runCommand("which locate >/dev/null 2>&1 || which mlocate >/dev/null 2>&1", io.Discard)
 	return err == nil
 }
 
@@ -321,22 +722,14 @@ func (s *SFTPSession) Search(pattern, startDir string, limit int) ([]RemoteFile,
 		limit = 100
 	}
 
-	session, err := s.client.NewSession()
-	if err != nil {
-		return nil, err
-	}
-	defer session.Close()
-
 	// Expand ~ in startDir
 	if strings.HasPrefix(startDir, "~") {
-		homeSession, err := s.client.NewSession()
+		home, err := s.GetHomeDirectory()
+		if IsTimeout(err) {
+			return nil, err
+		}
 		if err == nil {
-			homeOutput, err := homeSession.Output("echo $HOME")
-			homeSession.Close()
-			if err == nil {
-				home := strings.TrimSpace(string(homeOutput))
-				startDir = strings.Replace(startDir, "~", home, 1)
-			}
+			startDir = strings.Replace(startDir, "~", home, 1)
 		}
 	}
 
@@ -346,9 +739,7 @@ func (s *SFTPSession) Search(pattern, startDir string, limit int) ([]RemoteFile,
 	var cmd string
 
 	// First check if fd is available (much faster than find)
-	fdCheck, _ := s.client.NewSession()
-	hasFd := fdCheck.Run("which fd >/dev/null 2>&1") == nil
-	fdCheck.Close()
+	hasFd := s.runCommand("which fd >/dev/null 2>&1", io.Discard) == nil
 
 	if hasFd {
 		// fd is super fast and has nice defaults
@@ -358,7 +749,10 @@ func (s *SFTPSession) Search(pattern, startDir string, limit int) ([]RemoteFile,
 		cmd = fmt.Sprintf("find %q -iname '*%s*' 2>/dev/null | head -n %d", startDir, pattern, limit)
 	}
 
-	output, err := session.Output(cmd)
+	output, err := s.output(cmd)
+	if IsTimeout(err) {
+		return nil, err
+	}
 	if err != nil {
 		// Search might return no results, which is not an error
 		return []RemoteFile{}, nil
@@ -372,36 +766,15 @@ func (s *SFTPSession) Search(pattern, startDir string, limit int) ([]RemoteFile,
 		}
 
 		// Get file info
-		infoSession, err := s.client.NewSession()
-		if err != nil {
-			continue
+		file, err := s.Stat(line)
+		if IsTimeout(err) {
+			return nil, err
 		}
-
-		infoCmd := fmt.Sprintf("ls -ld %q 2>/dev/null", line)
-		infoOutput, err := infoSession.Output(infoCmd)
-		infoSession.Close()
-
 		if err != nil {
 			// File might not exist anymore
 			continue
 		}
-
-		infoLine := strings.TrimSpace(string(infoOutput))
-		fields := strings.Fields(infoLine)
-		if len(fields) < 9 {
-			continue
-		}
-
-		permissions := fields[0]
-		size := int64(0)
-		fmt.Sscanf(fields[4], "%d", &size)
-
-		files = append(files, RemoteFile{
-			Name:  filepath.Base(line),
-			Path:  line,
-			IsDir: strings.HasPrefix(permissions, "d"),
-			Size:  size,
-		})
+		files = append(files, *file)
 	}
 
 	return files, nil
@@ -414,22 +787,14 @@ func (s *SFTPSession) QuickSearch(pattern, startDir string, limit int) ([]Remote
 		limit = 30
 	}
 
-	session, err := s.client.NewSession()
-	if err != nil {
-		return nil, err
-	}
-	defer session.Close()
-
 	// Expand ~ in startDir
 	if strings.HasPrefix(startDir, "~") {
-		homeSession, err := s.client.NewSession()
+		home, err := s.GetHomeDirectory()
+		if IsTimeout(err) {
+			return nil, err
+		}
 		if err == nil {
-			homeOutput, err := homeSession.Output("echo $HOME")
-			homeSession.Close()
-			if err == nil {
-				home := strings.TrimSpace(string(homeOutput))
-				startDir = strings.Replace(startDir, "~", home, 1)
-			}
+			startDir = strings.Replace(startDir, "~", home, 1)
 		}
 	}
 
@@ -447,14 +812,18 @@ func (s *SFTPSession) QuickSearch(pattern, startDir string, limit int) ([]Remote
 	// timeout 3s kills the search after 3 seconds
 	cmd := fmt.Sprintf("timeout 3s find %q -maxdepth 5 -iname '*%s*' -printf '%%y %%p\\n' 2>/dev/null | head -n %d", startDir, pattern, limit)
 
-	output, err := session.Output(cmd)
+	output, err := s.output(cmd)
+	if IsTimeout(err) {
+		return nil, err
+	}
 	if err != nil {
 		// Try simpler find without -printf and timeout (BSD/macOS compatibility)
-		session2, _ := s.client.NewSession()
 		// macOS uses gtimeout (from coreutils) or we skip timeout
 		cmd = fmt.Sprintf("find %q -maxdepth 5 -iname '*%s*' 2>/dev/null | head -n %d | while read f; do if [ -d \"$f\" ]; then echo \"d $f\"; else echo \"f $f\"; fi; done", startDir, pattern, limit)
-		output, err = session2.Output(cmd)
-		session2.Close()
+		output, err = s.output(cmd)
+		if IsTimeout(err) {
+			return nil, err
+		}
 		if err != nil {
 			return []RemoteFile{}, nil
 		}
@@ -471,7 +840,7 @@ func (s *SFTPSession) QuickSearch(pattern, startDir string, limit int) ([]Remote
 		path := strings.TrimSpace(line[2:])
 
 		files = append(files, RemoteFile{
-			Name:  filepath.Base(path),
+			Name:  pathpkg.Base(path),
 			Path:  path,
 			IsDir: typeChar == 'd',
 		})
@@ -0,0 +1,603 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// fakeRunner simulates remote command execution for SFTPSession tests
+type fakeRunner struct {
+	listing string
+	calls   []string
+}
+
+func (f *fakeRunner) run(cmd string, w io.Writer) error {
+	f.calls = append(f.calls, cmd)
+	if strings.Contains(cmd, "ls -la") {
+		_, err := io.WriteString(w, f.listing)
+		return err
+	}
+	return fmt.Errorf("unexpected command: %s", cmd)
+}
+
+func lsLine(perms, name string) string {
+	return fmt.Sprintf("%s  1 user group  4096 Jan  1 12:00 %s\n", perms, name)
+}
+
+// stallWriter carries the requests of a test SFTP client, and blocks them
+// once stalled to simulate an unresponsive server
+type stallWriter struct {
+	w       io.WriteCloser
+	stalled *atomic.Bool
+	release chan struct{}
+}
+
+func (s *stallWriter) Write(p []byte) (int, error) {
+	if s.stalled.Load() {
+		<-s.release
+	}
+	return s.w.Write(p)
+}
+
+func (s *stallWriter) Close() error {
+	return s.w.Close()
+}
+
+// newTestSFTPSession connects a session to an in-process SFTP server serving
+// the local filesystem, with home as its working directory. The channels
+// directories are listed over go to servers of their own, and stall with
+// the session's.
+func newTestSFTPSession(t *testing.T, home string) (*SFTPSession, *stallWriter) {
+	t.Helper()
+	writer := &stallWriter{stalled: &atomic.Bool{}, release: make(chan struct{})}
+	clientRead, server := serveTestSFTP(t, home, writer)
+
+	client, err := sftp.NewClientPipe(clientRead, writer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		// Closing the server ends the client's reads so it can close too
+		close(writer.release)
+		server.Close()
+		client.Close()
+	})
+
+	session := &SFTPSession{sftp: client}
+	session.dirChannel = func() (io.ReadWriteCloser, error) {
+		channelWriter := &stallWriter{stalled: writer.stalled, release: writer.release}
+		channelRead, server := serveTestSFTP(t, home, channelWriter)
+		t.Cleanup(func() { server.Close() })
+		return struct {
+			io.Reader
+			io.WriteCloser
+		}{channelRead, channelWriter}, nil
+	}
+	return session, writer
+}
+
+// serveTestSFTP starts an SFTP server reading the requests writer carries,
+// and returns the reader of its replies
+func serveTestSFTP(t *testing.T, home string, writer *stallWriter) (io.Reader, *sftp.Server) {
+	t.Helper()
+	serverRead, clientWrite := io.Pipe()
+	clientRead, serverWrite := io.Pipe()
+	writer.w = clientWrite
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverRead, serverWrite}, sftp.WithServerWorkingDirectory(home))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	return clientRead, server
+}
+
+// writeFiles creates empty files in dir
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestListDirectoryStreamCap(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		writeFiles(t, dir, fmt.Sprintf("file%02d", i))
+	}
+
+	tests := []struct {
+		name          string
+		limit         int
+		wantEntries   int
+		wantTruncated bool
+	}{
+		{"Below limit", 50, 20, false},
+		{"Exactly at limit", 20, 20, false},
+		{"Above limit", 10, 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestSFTPSession(t, dir)
+
+			var files []RemoteFile
+			truncated, err := s.ListDirectoryStream(dir, tt.limit, func(batch []RemoteFile) {
+				files = append(files, batch...)
+			})
+			if err != nil {
+				t.Fatalf("ListDirectoryStream() error = %v", err)
+			}
+
+			// The ".." parent entry does not count towards the limit
+			if len(files) != tt.wantEntries+1 {
+				t.Errorf("Expected %d entries, got %d", tt.wantEntries+1, len(files))
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated = %v, got %v", tt.wantTruncated, truncated)
+			}
+			// The entries read are delivered in name order
+			names := fileNames(files)[1:]
+			if !sort.StringsAreSorted(names) || !strings.HasPrefix(names[0], "file") {
+				t.Errorf("Unexpected entries %v", fileNames(files))
+			}
+		})
+	}
+}
+
+func TestListDirectoryStreamBatches(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < ListBatchSize*2+10; i++ {
+		writeFiles(t, dir, fmt.Sprintf("file%04d", i))
+	}
+
+	s, _ := newTestSFTPSession(t, dir)
+
+	var batches int
+	_, err := s.ListDirectoryStream(dir, DefaultListLimit, func(batch []RemoteFile) {
+		batches++
+		if len(batch) > ListBatchSize {
+			t.Errorf("Batch of %d entries exceeds batch size %d", len(batch), ListBatchSize)
+		}
+	})
+	if err != nil {
+		t.Fatalf("ListDirectoryStream() error = %v", err)
+	}
+
+	if batches != 3 {
+		t.Errorf("Expected 3 batches, got %d", batches)
+	}
+}
+
+// readdirCounter counts the READDIR requests sent over a listing channel
+type readdirCounter struct {
+	io.ReadWriteCloser
+	count *int
+}
+
+func (c readdirCounter) Write(p []byte) (int, error) {
+	if len(p) > 4 && p[4] == fxpReaddir {
+		*c.count++
+	}
+	return c.ReadWriteCloser.Write(p)
+}
+
+func TestListDirectoryStreamStopsReading(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 1000; i++ {
+		writeFiles(t, dir, fmt.Sprintf("file%04d", i))
+	}
+
+	s, _ := newTestSFTPSession(t, dir)
+	open := s.dirChannel
+	var readdirs int
+	s.dirChannel = func() (io.ReadWriteCloser, error) {
+		ch, err := open()
+		return readdirCounter{ReadWriteCloser: ch, count: &readdirs}, err
+	}
+
+	var files []RemoteFile
+	truncated, err := s.ListDirectoryStream(dir, 10, func(batch []RemoteFile) {
+		files = append(files, batch...)
+	})
+	if err != nil {
+		t.Fatalf("ListDirectoryStream() error = %v", err)
+	}
+	if !truncated || len(files) != 11 {
+		t.Errorf("Expected 10 entries and truncated, got %d, %v", len(files)-1, truncated)
+	}
+	if readdirs != 1 {
+		t.Errorf("Expected a single READDIR request, got %d", readdirs)
+	}
+
+	// The channel is kept for the next listing, which reads all entries
+	readdirs = 0
+	files, err = s.ListDirectory(dir)
+	if err != nil || len(files) != 1001 {
+		t.Fatalf("ListDirectory() = %d entries, %v", len(files), err)
+	}
+	if readdirs < 2 {
+		t.Errorf("Expected READDIR requests up to the end of the directory, got %d", readdirs)
+	}
+}
+
+func TestListDirectoryEntries(t *testing.T) {
+	dir := t.TempDir()
+	// Names that used to break parsing `ls -la` output
+	writeFiles(t, dir, "file.txt", "with  two spaces.txt", "line\nbreak", "-> arrow", "café.log")
+	if err := os.Mkdir(filepath.Join(dir, "realdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		"link-to-dir":      "realdir",
+		"another dir link": filepath.Join(dir, "realdir"),
+		"link-to-file":     "file.txt",
+		"broken":           "/nowhere",
+	} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(dir, "file.txt"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := newTestSFTPSession(t, dir)
+	files, err := s.ListDirectory(dir)
+	if err != nil {
+		t.Fatalf("ListDirectory() error = %v", err)
+	}
+
+	isDir := make(map[string]bool)
+	byName := make(map[string]RemoteFile)
+	for _, f := range files {
+		isDir[f.Name] = f.IsDir
+		byName[f.Name] = f
+	}
+
+	expected := map[string]bool{
+		"..":                   true,
+		"realdir":              true,
+		"file.txt":             false,
+		"with  two spaces.txt": false,
+		"line\nbreak":          false,
+		"-> arrow":             false,
+		"café.log":             false,
+		"link-to-dir":          true,
+		"another dir link":     true,
+		"link-to-file":         false,
+		"broken":               false,
+	}
+	if len(files) != len(expected) {
+		t.Errorf("Expected %d entries, got %v", len(expected), fileNames(files))
+	}
+	for name, want := range expected {
+		got, ok := isDir[name]
+		if !ok {
+			t.Errorf("Missing entry %q", name)
+			continue
+		}
+		if got != want {
+			t.Errorf("Entry %q: IsDir = %v, want %v", name, got, want)
+		}
+	}
+
+	file := byName["file.txt"]
+	if file.Size != 5 || file.ModTime.IsZero() || file.Info == nil || file.Info.Mode().Perm() != 0640 {
+		t.Errorf("Expected the attributes of file.txt, got %+v", file)
+	}
+	if link := byName["link-to-dir"]; link.Info == nil || link.Info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected the link's own attributes, got %+v", link)
+	}
+}
+
+func TestListDirectoryErrors(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := newTestSFTPSession(t, dir)
+
+	if _, err := s.ListDirectory(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestListDirectorySlashPaths(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "media")
+	if err := os.MkdirAll(filepath.Join(dir, "films"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, "notes.txt")
+
+	s, _ := newTestSFTPSession(t, root)
+	for _, listed := range []string{dir, dir + "/"} {
+		files, err := s.ListDirectory(listed)
+		if err != nil {
+			t.Fatalf("ListDirectory(%q) error = %v", listed, err)
+		}
+
+		var parent string
+		var paths []string
+		for _, file := range files {
+			if file.Name == ".." {
+				parent = file.Path
+				continue
+			}
+			paths = append(paths, file.Path)
+		}
+		if parent != root {
+			t.Errorf("ListDirectory(%q) parent = %q, want %q", listed, parent, root)
+		}
+		want := dir + "/films," + dir + "/notes.txt"
+		if strings.Join(paths, ",") != want {
+			t.Errorf("ListDirectory(%q) paths = %v, want %v", listed, paths, want)
+		}
+	}
+
+	// The root has no parent entry and its children have a single slash
+	files, err := s.ListDirectory("/")
+	if err != nil {
+		t.Fatalf("ListDirectory(/) error = %v", err)
+	}
+	for _, file := range files {
+		if file.Name == ".." || file.Path != "/"+file.Name {
+			t.Errorf("Unexpected entry %+v in /", file)
+		}
+	}
+}
+
+func TestListDirectoryExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	if err := os.Mkdir(filepath.Join(home, "projects"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, filepath.Join(home, "projects"), "main.go")
+
+	s, _ := newTestSFTPSession(t, home)
+	if got, err := s.GetHomeDirectory(); err != nil || got != home {
+		t.Fatalf("GetHomeDirectory() = %q, %v, want %q", got, err, home)
+	}
+
+	files, err := s.ListDirectory("~/projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[1].Path != filepath.Join(home, "projects", "main.go") {
+		t.Errorf("Unexpected listing %+v", files)
+	}
+}
+
+func TestStatAndReadFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "my notes.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dir, filepath.Join(dir, "self")); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := newTestSFTPSession(t, dir)
+	path := filepath.Join(dir, "my notes.txt")
+
+	file, err := s.Stat(path)
+	if err != nil || file.Name != "my notes.txt" || file.Path != path || file.IsDir || file.Size != 6 {
+		t.Errorf("Stat() = %+v, %v", file, err)
+	}
+	if link, err := s.Stat(filepath.Join(dir, "self")); err != nil || !link.IsDir {
+		t.Errorf("Expected Stat to follow links to directories, got %+v, %v", link, err)
+	}
+	if _, err := s.Stat(filepath.Join(dir, "missing")); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected a missing path error, got %v", err)
+	}
+
+	var out strings.Builder
+	if err := s.ReadFile(path, &out); err != nil || out.String() != "hello\n" {
+		t.Errorf("ReadFile() = %q, %v", out.String(), err)
+	}
+	if data, err := s.ReadTextFile(path); err != nil || string(data) != "hello\n" {
+		t.Errorf("ReadTextFile() = %q, %v", data, err)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"simple", "'simple'"},
+		{"with space", "'with space'"},
+		{"it's", `'it'\''s'`},
+		{"$HOME", "'$HOME'"},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.input); got != tt.expected {
+			t.Errorf("shellQuote(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// slowRunner writes partial output, then blocks until released
+func slowRunner(release <-chan struct{}) func(cmd string, w io.Writer) error {
+	return func(cmd string, w io.Writer) error {
+		io.WriteString(w, "partial\n")
+		<-release
+		io.WriteString(w, "late\n")
+		return nil
+	}
+}
+
+func TestRunCommandTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	s := &SFTPSession{runner: slowRunner(release)}
+	s.SetCommandTimeout(50 * time.Millisecond)
+
+	var out strings.Builder
+	start := time.Now()
+	err := s.runCommand("ls -la /data", &out)
+	elapsed := time.Since(start)
+
+	if !IsTimeout(err) {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "ls") {
+		t.Errorf("Expected the command name in the error, got %q", err.Error())
+	}
+	if elapsed > time.Second {
+		t.Errorf("Command was not cut off at the deadline, took %s", elapsed)
+	}
+	if out.String() != "partial\n" {
+		t.Errorf("Expected only output written before the deadline, got %q", out.String())
+	}
+}
+
+func TestRunCommandWithinTimeout(t *testing.T) {
+	fake := &fakeRunner{listing: lsLine("-rw-r--r--", "file.txt")}
+	s := &SFTPSession{runner: fake.run}
+	s.SetCommandTimeout(time.Second)
+
+	var out strings.Builder
+	if err := s.runCommand("ls -la /data", &out); err != nil {
+		t.Fatalf("runCommand() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "file.txt") {
+		t.Errorf("Expected command output, got %q", out.String())
+	}
+
+	// Ordinary failures are not reported as timeouts
+	if err := s.runCommand("unknown", io.Discard); err == nil || IsTimeout(err) {
+		t.Errorf("Expected a non-timeout error, got %v", err)
+	}
+}
+
+func TestRemoteOperationsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	s, writer := newTestSFTPSession(t, t.TempDir())
+	writer.stalled.Store(true)
+	s.runner = slowRunner(release)
+	s.SetCommandTimeout(20 * time.Millisecond)
+
+	if _, err := s.ListDirectory("/data"); !IsTimeout(err) {
+		t.Errorf("ListDirectory() error = %v, want timeout", err)
+	}
+	if _, err := s.GetHomeDirectory(); !IsTimeout(err) {
+		t.Errorf("GetHomeDirectory() error = %v, want timeout", err)
+	}
+	if _, err := s.Stat("/data/file"); !IsTimeout(err) {
+		t.Errorf("Stat() error = %v, want timeout", err)
+	}
+	if _, err := s.QuickSearch("file", "/data", 10); !IsTimeout(err) {
+		t.Errorf("QuickSearch() error = %v, want timeout", err)
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		expected string
+	}{
+		{"ls -la /data", "ls"},
+		{"LC_ALL=C find / -printf x", "find"},
+		{"timeout 3s find /", "find"},
+	}
+
+	for _, tt := range tests {
+		if got := commandName(tt.cmd); got != tt.expected {
+			t.Errorf("commandName(%q) = %q, want %q", tt.cmd, got, tt.expected)
+		}
+	}
+}
+
+func TestSSHAddress(t *testing.T) {
+	tests := []struct {
+		hostname string
+		port     string
+		expected string
+	}{
+		{"example.com", "22", "example.com:22"},
+		{"10.0.0.1", "2222", "10.0.0.1:2222"},
+		{"::1", "22", "[::1]:22"},
+		{"2001:db8::1", "2200", "[2001:db8::1]:2200"},
+		{"[2001:db8::1]", "22", "[2001:db8::1]:22"},
+	}
+
+	for _, tt := range tests {
+		if got := sshAddress(tt.hostname, tt.port); got != tt.expected {
+			t.Errorf("sshAddress(%q, %q) = %q, want %q", tt.hostname, tt.port, got, tt.expected)
+		}
+	}
+}
+
+func TestIsLogDirectory(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/var/log", true},
+		{"/var/log/nginx", true},
+		{"/srv/app/logs", true},
+		{"/srv/app/Logs/", true},
+		{"/var/log/journal", true},
+		{"/opt/app/access_logs", true},
+		{"/opt/app/app-log", true},
+		{"/home/user", false},
+		{"/var/www/blog", false},
+		{"/srv/catalog", false},
+		{"~", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsLogDirectory(tt.path); got != tt.expected {
+			t.Errorf("IsLogDirectory(%q) = %v, want %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestSortRemoteFilesByModified(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	files := []RemoteFile{
+		{Name: "syslog.1", ModTime: day(9)},
+		{Name: "nginx", IsDir: true, ModTime: day(1)},
+		{Name: "syslog", ModTime: day(10)},
+		{Name: "..", IsDir: true},
+		{Name: "auth.log", ModTime: day(9)},
+		{Name: "archive", IsDir: true, ModTime: day(10)},
+	}
+
+	SortRemoteFilesByModified(files)
+
+	expected := []string{"..", "archive", "syslog", "auth.log", "syslog.1", "nginx"}
+	for i, name := range expected {
+		if files[i].Name != name {
+			t.Fatalf("Sorted order = %v, want %v", fileNames(files), expected)
+		}
+	}
+}
+
+func fileNames(files []RemoteFile) []string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name
+	}
+	return names
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -12,10 +13,14 @@ import (
 
 // SSHFSMount represents a mounted SSHFS filesystem
 type SSHFSMount struct {
-	Host       string
-	RemotePath string
-	MountPoint string
-	ConfigFile string
+	Host       string `json:"host"`
+	RemotePath string `json:"remote_path"`
+	MountPoint string `json:"mount_point"`
+	ConfigFile string `json:"config_file,omitempty"`
+
+	// KeepMountPoint leaves the mount point directory in place on unmount,
+	// for directories that existed before mounting
+	KeepMountPoint bool `json:"keep_mount_point,omitempty"`
 }
 
 // IsSSHFSAvailable checks if SSHFS is installed
@@ -56,11 +61,42 @@ func NewSSHFSMount(host, remotePath, configFile string) (*SSHFSMount, error) {
 	}, nil
 }
 
+// NewSSHFSMountAt creates an SSHFS mount on mountPoint, creating the
+// directory when it does not exist
+func NewSSHFSMountAt(host, remotePath, mountPoint, configFile string) (*SSHFSMount, error) {
+	if !IsSSHFSAvailable() {
+		return nil, fmt.Errorf("sshfs not installed. %s", GetSSHFSInstallInstructions())
+	}
+
+	mountPoint, err := ExpandPath(mountPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := false
+	if info, err := os.Stat(mountPoint); err == nil {
+		if !info.IsDir() {
+			return nil, fmt.Errorf("mount point %s is not a directory", mountPoint)
+		}
+		keep = true
+	} else if err := os.MkdirAll(mountPoint, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mount point: %w", err)
+	}
+
+	return &SSHFSMount{
+		Host:           host,
+		RemotePath:     remotePath,
+		MountPoint:     mountPoint,
+		ConfigFile:     configFile,
+		KeepMountPoint: keep,
+	}, nil
+}
+
 // Mount mounts the remote filesystem
 func (m *SSHFSMount) Mount() error {
 	// Build sshfs command
 	// sshfs user@host:/path /mount/point -o options
-	remote := fmt.Sprintf("%s:%s", m.Host, m.RemotePath)
+	remote := FormatRemoteSpec(m.Host, m.RemotePath)
 
 	args := []string{remote, m.MountPoint}
 
@@ -90,7 +126,9 @@ func (m *SSHFSMount) Mount() error {
 
 	if err := cmd.Run(); err != nil {
 		// Clean up mount point on failure
-		os.Remove(m.MountPoint)
+		if !m.KeepMountPoint {
+			os.Remove(m.MountPoint)
+		}
 		return fmt.Errorf("failed to mount: %w", err)
 	}
 
@@ -117,7 +155,9 @@ func (m *SSHFSMount) Unmount() error {
 	err := cmd.Run()
 
 	// Try to remove the mount point directory
-	os.Remove(m.MountPoint)
+	if !m.KeepMountPoint {
+		os.Remove(m.MountPoint)
+	}
 
 	return err
 }
@@ -150,7 +190,7 @@ func (m *SSHFSMount) ToRemotePath(localPath string) (string, error) {
 		return m.RemotePath, nil
 	}
 
-	return filepath.Join(m.RemotePath, relPath), nil
+	return path.Join(m.RemotePath, filepath.ToSlash(relPath)), nil
 }
 
 // OpenRemoteFilePicker mounts remote filesystem and opens native file picker
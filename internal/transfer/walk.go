@@ -0,0 +1,262 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WalkCacheTTL is how long a WalkDir result is reused for the same path
+const WalkCacheTTL = 30 * time.Second
+
+// WalkEntry is a file or directory found while walking a remote tree
+type WalkEntry struct {
+	Path  string
+	Size  int64
+	IsDir bool
+	Other bool // Symlink or special file, not counted in sizes
+}
+
+// WalkResult is the content of a remote tree, with the subtrees that could not be read
+type WalkResult struct {
+	Root       string
+	Entries    []WalkEntry
+	Unreadable []string // Paths skipped because of permission or I/O errors
+}
+
+// walkCacheEntry is a cached WalkDir result
+type walkCacheEntry struct {
+	result  *WalkResult
+	expires time.Time
+}
+
+// walkCache holds recent WalkDir results per session
+type walkCache struct {
+	mu      sync.Mutex
+	entries map[string]walkCacheEntry
+}
+
+// errFindFailed is returned when the remote find cannot run the walk, as
+// BusyBox's find which has no -printf
+var errFindFailed = errors.New("remote find failed")
+
+// WalkDir lists every file below root with its size. Unreadable subdirectories
+// are reported in the result instead of aborting the walk. Results are cached
+// for WalkCacheTTL.
+func (s *SFTPSession) WalkDir(root string) (*WalkResult, error) {
+	root, err := s.expandRemoteHome(root)
+	if err != nil {
+		return nil, err
+	}
+	root = path.Clean(root)
+
+	s.walks.mu.Lock()
+	if cached, ok := s.walks.entries[root]; ok && time.Now().Before(cached.expires) {
+		s.walks.mu.Unlock()
+		return cached.result, nil
+	}
+	s.walks.mu.Unlock()
+
+	return s.Rewalk(root)
+}
+
+// Rewalk walks root again, ignoring and then replacing any cached result. It is
+// used to measure a tree that is still being written.
+func (s *SFTPSession) Rewalk(root string) (*WalkResult, error) {
+	root, err := s.expandRemoteHome(root)
+	if err != nil {
+		return nil, err
+	}
+	root = path.Clean(root)
+
+	result, err := s.walk(root)
+	if err != nil {
+		return nil, err
+	}
+
+	s.walks.mu.Lock()
+	if s.walks.entries == nil {
+		s.walks.entries = make(map[string]walkCacheEntry)
+	}
+	s.walks.entries[root] = walkCacheEntry{result: result, expires: time.Now().Add(WalkCacheTTL)}
+	s.walks.mu.Unlock()
+
+	return result, nil
+}
+
+// walk runs find on the host, and falls back to walking over SFTP when that
+// find cannot print sizes
+func (s *SFTPSession) walk(root string) (*WalkResult, error) {
+	// %y is the file type, %s the size in bytes; errors go to stdout so they can be
+	// attributed to subtrees, and the exit status is ignored because find keeps going
+	var out bytes.Buffer
+	cmd := fmt.Sprintf("LC_ALL=C find %s -printf '%%y %%s %%p\\n' 2>&1; true", shellQuote(root))
+	if err := s.runCommand(cmd, &out); err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	result, err := ParseWalkOutput(root, &out)
+	if errors.Is(err, errFindFailed) && s.sftp != nil {
+		return s.sftpWalk(root)
+	}
+	return result, err
+}
+
+// sftpWalk lists root like find does, one SFTP request per directory
+func (s *SFTPSession) sftpWalk(root string) (*WalkResult, error) {
+	result := &WalkResult{Root: root}
+	err := s.sftpCall("walk "+root, func() error {
+		walker := s.sftp.Walk(root)
+		for walker.Step() {
+			if err := walker.Err(); err != nil {
+				if walker.Path() == root {
+					return err
+				}
+				result.Unreadable = append(result.Unreadable, walker.Path())
+				continue
+			}
+			info := walker.Stat()
+			switch {
+			case info.IsDir():
+				result.Entries = append(result.Entries, WalkEntry{Path: walker.Path(), IsDir: true})
+			case info.Mode().IsRegular():
+				result.Entries = append(result.Entries, WalkEntry{Path: walker.Path(), Size: info.Size()})
+			default:
+				result.Entries = append(result.Entries, WalkEntry{Path: walker.Path(), Other: true})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", root, err)
+	}
+	return result, nil
+}
+
+// ParseWalkOutput parses the output of the find command run by WalkDir
+func ParseWalkOutput(root string, r io.Reader) (*WalkResult, error) {
+	result := &WalkResult{Root: root}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "find: ") {
+			failed, ok := parseFindError(line)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", errFindFailed, strings.TrimPrefix(line, "find: "))
+			}
+			if failed == root {
+				return nil, fmt.Errorf("cannot read %s: %s", root, findErrorReason(line))
+			}
+			result.Unreadable = append(result.Unreadable, failed)
+			continue
+		}
+
+		// "<type> <size> <path>"; the path may itself contain spaces
+		parts := strings.SplitN(line, " ", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch parts[0] {
+		case "f":
+			result.Entries = append(result.Entries, WalkEntry{Path: parts[2], Size: size})
+		case "d":
+			result.Entries = append(result.Entries, WalkEntry{Path: parts[2], IsDir: true})
+		default:
+			// Symlinks and special files are listed without a size
+			result.Entries = append(result.Entries, WalkEntry{Path: parts[2], Other: true})
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// parseFindError extracts the path from "find: '<path>': <reason>"
+func parseFindError(line string) (string, bool) {
+	rest := strings.TrimPrefix(line, "find: ")
+	if !strings.HasPrefix(rest, "'") {
+		return "", false
+	}
+	end := strings.LastIndex(rest, "': ")
+	if end <= 0 {
+		return "", false
+	}
+	return rest[1:end], true
+}
+
+// findErrorReason returns the reason part of a find error line
+func findErrorReason(line string) string {
+	if i := strings.LastIndex(line, "': "); i >= 0 {
+		return line[i+3:]
+	}
+	return line
+}
+
+// TotalSize returns the sum of all file sizes in the walk
+func (r *WalkResult) TotalSize() int64 {
+	var total int64
+	for _, e := range r.Entries {
+		if !e.IsDir && !e.Other {
+			total += e.Size
+		}
+	}
+	return total
+}
+
+// FileCount returns the number of regular files in the walk
+func (r *WalkResult) FileCount() int {
+	count := 0
+	for _, e := range r.Entries {
+		if !e.IsDir && !e.Other {
+			count++
+		}
+	}
+	return count
+}
+
+// ChildSizes returns the total size below each direct child of the root,
+// keyed by child name
+func (r *WalkResult) ChildSizes() map[string]int64 {
+	sizes := make(map[string]int64)
+	prefix := strings.TrimSuffix(r.Root, "/") + "/"
+
+	for _, e := range r.Entries {
+		rel := strings.TrimPrefix(e.Path, prefix)
+		if rel == e.Path || rel == "" {
+			continue // The root itself
+		}
+		child := rel
+		if i := strings.Index(rel, "/"); i >= 0 {
+			child = rel[:i]
+		}
+		if e.IsDir || e.Other {
+			if _, ok := sizes[child]; !ok {
+				sizes[child] = 0
+			}
+			continue
+		}
+		sizes[child] += e.Size
+	}
+	return sizes
+}
+
+// Complete reports whether every subtree could be read
+func (r *WalkResult) Complete() bool {
+	return len(r.Unreadable) == 0
+}
@@ -0,0 +1,156 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleWalk = `d 4096 /srv/app
+f 100 /srv/app/README.md
+d 4096 /srv/app/src
+f 2000 /srv/app/src/main.go
+f 500 /srv/app/src/util.go
+d 4096 /srv/app/secret
+find: '/srv/app/secret': Permission denied
+d 4096 /srv/app/logs
+f 300 /srv/app/logs/my app.log
+l 12 /srv/app/current
+`
+
+func TestParseWalkOutput(t *testing.T) {
+	result, err := ParseWalkOutput("/srv/app", strings.NewReader(sampleWalk))
+	if err != nil {
+		t.Fatalf("ParseWalkOutput() error = %v", err)
+	}
+
+	if got := result.TotalSize(); got != 2900 {
+		t.Errorf("TotalSize() = %d, want 2900", got)
+	}
+	if got := result.FileCount(); got != 4 {
+		t.Errorf("FileCount() = %d, want 4", got)
+	}
+	if !reflect.DeepEqual(result.Unreadable, []string{"/srv/app/secret"}) {
+		t.Errorf("Unreadable = %v", result.Unreadable)
+	}
+	if result.Complete() {
+		t.Error("Expected walk with unreadable subtree to be incomplete")
+	}
+
+	expected := map[string]int64{
+		"README.md": 100,
+		"src":       2500,
+		"secret":    0,
+		"logs":      300,
+		"current":   0,
+	}
+	if got := result.ChildSizes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ChildSizes() = %v, want %v", got, expected)
+	}
+}
+
+func TestParseWalkOutputErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    string
+		output  string
+		wantErr string
+	}{
+		{"Unreadable root", "/root", "find: '/root': Permission denied\n", "Permission denied"},
+		{"Missing root", "/nope", "find: '/nope': No such file or directory\n", "No such file"},
+		{"Unsupported find", "/srv", "find: unrecognized: -printf\n", "remote find failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWalkOutput(tt.root, strings.NewReader(tt.output))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWalkDirCachesResults(t *testing.T) {
+	calls := 0
+	s := &SFTPSession{runner: func(cmd string, w io.Writer) error {
+		calls++
+		if !strings.Contains(cmd, "find '/srv/app'") {
+			return fmt.Errorf("unexpected command: %s", cmd)
+		}
+		_, err := io.WriteString(w, sampleWalk)
+		return err
+	}}
+
+	first, err := s.WalkDir("/srv/app/")
+	if err != nil {
+		t.Fatalf("WalkDir() error = %v", err)
+	}
+	second, err := s.WalkDir("/srv/app")
+	if err != nil {
+		t.Fatalf("WalkDir() error = %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 remote walk, got %d", calls)
+	}
+	if first != second {
+		t.Error("Expected cached result to be reused")
+	}
+}
+
+func TestWalkDirFallsBackToSFTP(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "app", "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{"app/README.md": 100, "app/src/main.go": 2000} {
+		if err := os.WriteFile(filepath.Join(home, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, _ := newTestSFTPSession(t, home)
+	s.runner = func(cmd string, w io.Writer) error {
+		if !strings.Contains(cmd, "find '"+filepath.Join(home, "app")+"'") {
+			return fmt.Errorf("unexpected command: %s", cmd)
+		}
+		_, err := io.WriteString(w, "find: unrecognized: -printf\n")
+		return err
+	}
+
+	result, err := s.WalkDir("~/app")
+	if err != nil {
+		t.Fatalf("WalkDir() error = %v", err)
+	}
+	if got := result.TotalSize(); got != 2100 {
+		t.Errorf("TotalSize() = %d, want 2100", got)
+	}
+	expected := map[string]int64{"README.md": 100, "src": 2000}
+	if got := result.ChildSizes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ChildSizes() = %v, want %v", got, expected)
+	}
+}
+
+func TestRewalkSkipsCache(t *testing.T) {
+	calls := 0
+	s := &SFTPSession{runner: func(cmd string, w io.Writer) error {
+		calls++
+		_, err := io.WriteString(w, sampleWalk)
+		return err
+	}}
+
+	if _, err := s.WalkDir("/srv/app"); err != nil {
+		t.Fatalf("WalkDir() error = %v", err)
+	}
+	if _, err := s.Rewalk("/srv/app"); err != nil {
+		t.Fatalf("Rewalk() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 remote walks, got %d", calls)
+	}
+}
@@ -475,7 +475,7 @@ func RunAddForm(hostname string, configFile string) error {
 	addForm := NewAddForm(hostname, styles, 80, 24, configFile)
 	m := standaloneAddForm{addForm}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := newProgram(m)
 	_, err := p.Run()
 	return err
 }
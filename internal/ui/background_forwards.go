@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/connectivity"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// forwardsListModel lists the tunnels running in the background and stops them
+type forwardsListModel struct {
+	registry *transfer.ForwardRegistry
+	forwards []*transfer.BackgroundForward
+	cursor   int
+	err      string
+	styles   Styles
+	width    int
+	height   int
+}
+
+// backgroundForwardsMsg carries the tunnels found running in the background
+type backgroundForwardsMsg struct {
+	registry *transfer.ForwardRegistry
+	forwards []*transfer.BackgroundForward
+	err      error
+}
+
+// backgroundForwardStoppedMsg is sent once a tunnel has been stopped
+type backgroundForwardStoppedMsg struct {
+	forward *transfer.BackgroundForward
+	err     error
+}
+
+// forwardsListCloseMsg is sent when the tunnel list is closed
+type forwardsListCloseMsg struct{}
+
+// NewForwardsList creates a list over the running background tunnels
+func NewForwardsList(registry *transfer.ForwardRegistry, forwards []*transfer.BackgroundForward, styles Styles, width, height int) *forwardsListModel {
+	return &forwardsListModel{
+		registry: registry,
+		forwards: forwards,
+		styles:   styles,
+		width:    width,
+		height:   height,
+	}
+}
+
+// loadBackgroundForwards returns a command listing the running background tunnels
+func loadBackgroundForwards() tea.Cmd {
+	return func() tea.Msg {
+		registry, err := transfer.NewForwardRegistry()
+		if err != nil {
+			return backgroundForwardsMsg{err: err}
+		}
+		forwards, err := registry.Active()
+		return backgroundForwardsMsg{registry: registry, forwards: forwards, err: err}
+	}
+}
+
+// startBackgroundForward starts a tunnel that leaves the terminal once
+// connected, so passwords and host keys can still be prompted for, and
+// tracks it in the forward registry. forward is the forwarding option
+// shown to the user, sshArgs the ssh arguments ending with the host.
+func (m Model) startBackgroundForward(hostName, forward string, sshArgs []string) tea.Cmd {
+	registry, err := transfer.NewForwardRegistry()
+	if err != nil {
+		return notify(NotifyError, err.Error())
+	}
+	controlPath, err := registry.NewControlPath()
+	if err != nil {
+		return notify(NotifyError, err.Error())
+	}
+
+	sshCmd := transfer.BackgroundForwardCommand(controlPath, sshArgs)
+	if err := transfer.CheckCommand(sshCmd); err != nil {
+		return notify(NotifyError, err.Error())
+	}
+
+	// Record the connection in history
+	var warn tea.Cmd
+	if m.historyManager != nil {
+		if err := m.historyManager.RecordConnection(hostName); err != nil {
+			warn = notify(NotifyWarn, fmt.Sprintf("Could not record connection history: %v", err))
+		}
+	}
+
+	configFile := m.configFile
+	return tea.Batch(warn, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
+		if err != nil {
+			return notifyMsg{level: NotifyError, text: fmt.Sprintf("Could not start the tunnel to %s: %v", hostName, err)}
+		}
+		pid, err := transfer.ForwardPID(controlPath, hostName, configFile)
+		if err != nil {
+			return notifyMsg{level: NotifyError, text: fmt.Sprintf("Could not track the tunnel to %s: %v", hostName, err)}
+		}
+		tracked := &transfer.BackgroundForward{
+			Host:        hostName,
+			Forward:     forward,
+			PID:         pid,
+			ControlPath: controlPath,
+			ConfigFile:  configFile,
+			Started:     time.Now(),
+		}
+		if err := registry.Add(tracked); err != nil {
+			return notifyMsg{level: NotifyWarn, text: fmt.Sprintf("Tunnel running as PID %d but not tracked: %v", pid, err)}
+		}
+		return notifyMsg{level: NotifySuccess, text: fmt.Sprintf("Tunnel %s to %s running in the background (F to list)", forward, hostName)}
+	}))
+}
+
+// startSOCKSProxy starts a SOCKS proxy through a host in the background on
+// the default port, saving it in the host's port forwarding history
+func (m Model) startSOCKSProxy(hostName string) tea.Cmd {
+	port := config.DefaultSOCKSPort
+	if err := connectivity.CheckLocalPort("", port); err != nil {
+		return notify(NotifyError, err.Error())
+	}
+
+	var warn tea.Cmd
+	if m.historyManager != nil {
+		if err := m.historyManager.RecordPortForwarding(hostName, "dynamic", strconv.Itoa(port), "", "", ""); err != nil {
+			warn = notify(NotifyWarn, fmt.Sprintf("Could not record port forwarding history: %v", err))
+		}
+	}
+
+	forward := fmt.Sprintf("-D %d", port)
+	return tea.Batch(warn, m.startBackgroundForward(hostName, forward, config.BuildSOCKSProxyArgs(hostName, m.configFile, port)))
+}
+
+func (m *forwardsListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *forwardsListModel) Update(msg tea.Msg) (*forwardsListModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case backgroundForwardStoppedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		m.err = ""
+		for i, f := range m.forwards {
+			if f.ID == msg.forward.ID {
+				m.forwards = append(m.forwards[:i], m.forwards[i+1:]...)
+				break
+			}
+		}
+		if m.cursor >= len(m.forwards) && m.cursor > 0 {
+			m.cursor--
+		}
+		return m, notify(NotifySuccess, fmt.Sprintf("Stopped the tunnel %s to %s", msg.forward.Forward, msg.forward.Host))
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return forwardsListCloseMsg{} }
+
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case "down", "j":
+			if m.cursor < len(m.forwards)-1 {
+				m.cursor++
+			}
+
+		case "x", "d", "delete":
+			if len(m.forwards) == 0 {
+				return m, nil
+			}
+			registry, id := m.registry, m.forwards[m.cursor].ID
+			return m, func() tea.Msg {
+				forward, err := registry.Stop(id)
+				return backgroundForwardStoppedMsg{forward: forward, err: err}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m *forwardsListModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render("Background tunnels"))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render("Error: " + m.err))
+		b.WriteString("\n\n")
+	}
+
+	if len(m.forwards) == 0 {
+		b.WriteString(m.styles.HelpText.Render("No tunnel running. Start one with f, then Ctrl+B."))
+		b.WriteString("\n")
+	}
+	for i, f := range m.forwards {
+		line := fmt.Sprintf("%s  %s  (pid %d, since %s)", f.Host, f.Forward, f.PID, f.Started.Format("Jan 2 15:04"))
+		if i == m.cursor {
+			b.WriteString(m.styles.Selected.Render("▶ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • x: stop tunnel • Esc: close"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestToggleBanner(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+
+	m := createTestModel()
+	defaults := config.GetDefaultAppConfig()
+	m.appConfig = &defaults
+	// Many hosts in a short window, so the table height is bound by the room left
+	for i := 0; i < 30; i++ {
+		m.hosts = append(m.hosts, config.SSHHost{Name: fmt.Sprintf("extra%d", i)})
+	}
+	m.filteredHosts = m.hosts
+	m.updateTableRows()
+	m.updateTableHeight()
+	expanded := m.table.Height()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	m = updated.(Model)
+	if !m.hideBanner || strings.Contains(m.View(), "|_____|_____|") {
+		t.Fatal("Expected B to collapse the banner")
+	}
+	if got := m.table.Height(); got != expanded+bannerHeight {
+		t.Errorf("Expected the table to gain the banner's %d rows: height %d, was %d", bannerHeight, got, expanded)
+	}
+
+	// The choice is remembered for the next start
+	saved, err := config.LoadAppConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.HideBanner {
+		t.Error("Expected the collapsed banner to be saved in the app config")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	m = updated.(Model)
+	if m.hideBanner || m.table.Height() != expanded || !strings.Contains(m.View(), "|_____|_____|") {
+		t.Errorf("Expected B to bring the banner back, height %d want %d", m.table.Height(), expanded)
+	}
+	if saved, _ := config.LoadAppConfig(); saved.HideBanner {
+		t.Error("Expected the expanded banner to be saved")
+	}
+}
+
+func TestToggleBannerKeepsSavedConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+
+	saved := config.GetDefaultAppConfig()
+	saved.KeyBindings.Actions = map[string]string{config.ActionEdit: "E"}
+	if err := config.SaveAppConfig(&saved); err != nil {
+		t.Fatal(err)
+	}
+
+	// The running app dropped the custom keys, as it does when they conflict
+	m := createTestModel()
+	running := config.GetDefaultAppConfig()
+	m.appConfig = &running
+
+	if err := m.toggleBanner(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := config.LoadAppConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.HideBanner || reloaded.KeyBindings.Actions[config.ActionEdit] != "E" {
+		t.Errorf("Expected only hide_banner to change, got %+v", reloaded.KeyBindings.Actions)
+	}
+}
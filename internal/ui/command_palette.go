@@ -0,0 +1,281 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteCommand is a command typed after : in the host list. Commands run
+// the host list action bound to action, on the host given as argument or on
+// the selected one when onHost is set.
+type paletteCommand struct {
+	action string
+	onHost bool
+}
+
+// paletteCommands are the commands of the : palette by name. connect, sort,
+// tag, tmux and quit are handled by runCommand itself.
+var paletteCommands = map[string]paletteCommand{
+	"connect":   {onHost: true},
+	"edit":      {action: config.ActionEdit, onHost: true},
+	"delete":    {action: config.ActionDelete, onHost: true},
+	"info":      {action: config.ActionInfo, onHost: true},
+	"move":      {action: config.ActionMove, onHost: true},
+	"copy":      {action: config.ActionCopy, onHost: true},
+	"transfer":  {action: config.ActionTransfer, onHost: true},
+	"forward":   {action: config.ActionPortForward, onHost: true},
+	"proxy":     {action: config.ActionSOCKSProxy, onHost: true},
+	"web":       {action: config.ActionWeb, onHost: true},
+	"copy-id":   {action: config.ActionCopyID, onHost: true},
+	"key":       {action: config.ActionConnectWithKey, onHost: true},
+	"favorite":  {action: config.ActionFavorite, onHost: true},
+	"rename":    {action: config.ActionRename, onHost: true},
+	"tmux":      {action: config.ActionTmux},
+	"ping":      {action: config.ActionPing},
+	"add":       {action: config.ActionAdd},
+	"tunnels":   {action: config.ActionTunnels},
+	"retry":     {action: config.ActionRetryTransfer},
+	"views":     {action: config.ActionSavedViews},
+	"config":    {action: config.ActionSwitchConfig},
+	"banner":    {action: config.ActionBanner},
+	"favorites": {action: config.ActionFavoritesOnly},
+	"tag":       {action: config.ActionTagFilter},
+	"files":     {action: config.ActionSourceFilter},
+	"help":      {action: config.ActionHelp},
+	"sort":      {action: config.ActionSort},
+	"quit":      {},
+}
+
+// sortArguments are the orders accepted by :sort
+var sortArguments = map[string]SortMode{"name": SortByName, "recent": SortByLastUsed}
+
+// openCommandLine shows the : prompt in place of the footer
+func (m *Model) openCommandLine() tea.Cmd {
+	ti := textinput.New()
+	ti.Prompt = ":"
+	ti.Placeholder = "connect, edit, delete, ping, transfer <host>... (Tab completes)"
+	ti.CharLimit = 200
+	ti.Focus()
+	m.commandInput = ti
+	m.commandHint = ""
+	m.commandMode = true
+	m.table.Blur()
+	return textinput.Blink
+}
+
+// closeCommandLine hides the : prompt and gives the focus back to the table
+func (m *Model) closeCommandLine() {
+	m.commandMode = false
+	m.commandHint = ""
+	m.commandInput.Blur()
+	m.table.Focus()
+}
+
+// handleCommandKeys handles the keys typed in the : prompt
+func (m Model) handleCommandKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.closeCommandLine()
+		return m, nil
+	case "enter":
+		line := m.commandInput.Value()
+		m.closeCommandLine()
+		return m.runCommand(line)
+	case "tab":
+		value, candidates := m.completeCommand(m.commandInput.Value())
+		m.commandInput.SetValue(value)
+		m.commandInput.CursorEnd()
+		m.commandHint = ""
+		if len(candidates) > 1 {
+			if len(candidates) > maxCompletionHint {
+				candidates = append(candidates[:maxCompletionHint], "...")
+			}
+			m.commandHint = strings.Join(candidates, "  ")
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.commandInput, cmd = m.commandInput.Update(msg)
+	return m, cmd
+}
+
+// runCommand runs a command line typed after :
+func (m Model) runCommand(line string) (tea.Model, tea.Cmd) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return m, nil
+	}
+	name, args := fields[0], fields[1:]
+	command, ok := paletteCommands[name]
+	if !ok {
+		return m, m.showError(fmt.Sprintf("Unknown command %q (Tab lists the commands)", name))
+	}
+	keys := m.keyBindings()
+
+	switch name {
+	case "quit":
+		return m, tea.Quit
+	case "sort":
+		if len(args) > 0 {
+			mode, ok := sortArguments[args[0]]
+			if !ok {
+				return m, m.showError(fmt.Sprintf("Unknown sort order %q, use name or recent", args[0]))
+			}
+			m.setSortMode(mode)
+			return m, nil
+		}
+	case "tag":
+		if len(args) > 0 {
+			if args[0] == "none" {
+				m.setTagFilter("")
+				return m, nil
+			}
+			tag, ok := m.findTag(strings.TrimPrefix(args[0], "#"))
+			if !ok {
+				return m, m.showError(fmt.Sprintf("No host is tagged %q", args[0]))
+			}
+			m.setTagFilter(tag)
+			return m, nil
+		}
+	case "tmux":
+		if len(args) > 0 {
+			for _, hostName := range args {
+				if !m.hasHost(hostName) {
+					return m, m.showError(fmt.Sprintf("No host named %q", hostName))
+				}
+			}
+			return m, m.connectInTmux(args)
+		}
+	}
+
+	if command.onHost && len(args) > 0 {
+		if !m.selectHost(args[0]) {
+			return m, m.showError(fmt.Sprintf("No host named %q", args[0]))
+		}
+	}
+	if name == "connect" {
+		return m.handleListViewKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	return m.handleListViewKeys(keyMsgFor(keys.KeyFor(command.action)))
+}
+
+// hasHost reports whether hostName is one of the loaded hosts
+func (m Model) hasHost(hostName string) bool {
+	for _, host := range m.hosts {
+		if host.Name == hostName {
+			return true
+		}
+	}
+	return false
+}
+
+// selectHost moves the table cursor to hostName, clearing the search and the
+// tag and file filters and leaving favorites-only mode when they hide the host
+func (m *Model) selectHost(hostName string) bool {
+	if !m.hasHost(hostName) {
+		return false
+	}
+	for pass := 0; pass < 2; pass++ {
+		for i, host := range m.filteredHosts {
+			if host.Name == hostName {
+				m.table.SetCursor(i)
+				return true
+			}
+		}
+		m.searchInput.SetValue("")
+		m.favoritesOnly = false
+		m.tagFilter = ""
+		m.sourceFilter = ""
+		m.filteredHosts = m.sortHosts(m.visibleHosts())
+		m.updateTableRows()
+	}
+	return false
+}
+
+// completeCommand completes the last word of a command line: the command
+// name first, then host names, the order of :sort or the tags of :tag. A single match is
+// completed fully; several are completed to their common prefix.
+func (m Model) completeCommand(value string) (string, []string) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || (len(fields) == 1 && !strings.HasSuffix(value, " ")) {
+		prefix := strings.TrimSpace(value)
+		var names []string
+		for name := range paletteCommands {
+			names = append(names, name)
+		}
+		completed, candidates := completeWord(prefix, names)
+		if len(candidates) == 1 {
+			completed += " "
+		}
+		return completed, candidates
+	}
+
+	command, ok := paletteCommands[fields[0]]
+	var words []string
+	switch {
+	case !ok:
+		return value, nil
+	case fields[0] == "sort":
+		for order := range sortArguments {
+			words = append(words, order)
+		}
+	case fields[0] == "tag":
+		words = append(config.HostTags(m.hosts), "none")
+	case command.onHost || fields[0] == "tmux":
+		for _, host := range m.hosts {
+			words = append(words, host.Name)
+		}
+	default:
+		return value, nil
+	}
+
+	prefix := ""
+	if !strings.HasSuffix(value, " ") {
+		prefix = fields[len(fields)-1]
+	}
+	completed, candidates := completeWord(prefix, words)
+	if len(candidates) == 1 {
+		completed += " "
+	}
+	return strings.TrimSuffix(value, prefix) + completed, candidates
+}
+
+// completeWord completes prefix against words, returning the sorted matches
+func completeWord(prefix string, words []string) (string, []string) {
+	var candidates []string
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			candidates = append(candidates, word)
+		}
+	}
+	if len(candidates) == 0 {
+		return prefix, nil
+	}
+	sort.Strings(candidates)
+
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		common = commonPrefix(common, c)
+	}
+	return common, candidates
+}
+
+// keyMsgFor builds the key message Bubble Tea sends for a key name such as
+// "e", " ", "enter" or "ctrl+t"
+func keyMsgFor(key string) tea.KeyMsg {
+	alt := strings.HasPrefix(key, "alt+")
+	name := strings.TrimPrefix(key, "alt+")
+	for t := tea.KeyF20; t <= tea.KeyBackspace; t++ {
+		if t != tea.KeyRunes && t.String() == name {
+			return tea.KeyMsg{Type: t, Alt: alt}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name), Alt: alt}
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeCommand opens the command line and types line without running it
+func typeCommand(t *testing.T, m Model, line string) Model {
+	t.Helper()
+	m = pressKey(t, m, ":")
+	if !m.commandMode {
+		t.Fatal("Expected : to open the command line")
+	}
+	for _, r := range line {
+		m = pressKey(t, m, string(r))
+	}
+	return m
+}
+
+// runCommandLine types line after : and presses Enter
+func runCommandLine(t *testing.T, m Model, line string) Model {
+	t.Helper()
+	m = typeCommand(t, m, line)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return updated.(Model)
+}
+
+func TestCommandRunsOnNamedHost(t *testing.T) {
+	m := createTestModel()
+	m.searchInput.SetValue("web")
+	m.filteredHosts = m.filterHosts("web")
+	m.updateTableRows()
+
+	// The host hidden by the search is selected after clearing it
+	m = runCommandLine(t, m, "delete server2")
+	if m.commandMode {
+		t.Error("Expected Enter to close the command line")
+	}
+	if !m.deleteMode || m.deleteHost != "server2" {
+		t.Fatalf("Expected to confirm the deletion of server2, got deleteMode=%v host=%q", m.deleteMode, m.deleteHost)
+	}
+	if m.searchInput.Value() != "" {
+		t.Errorf("Expected the search hiding server2 to be cleared, got %q", m.searchInput.Value())
+	}
+}
+
+func TestCommandsWithoutHost(t *testing.T) {
+	m := createTestModel()
+
+	m = runCommandLine(t, m, "sort recent")
+	if m.sortMode != SortByLastUsed {
+		t.Errorf("Expected :sort recent to sort by last login, got %v", m.sortMode)
+	}
+
+	m = runCommandLine(t, m, "help")
+	if m.viewMode != ViewHelp {
+		t.Errorf("Expected :help to open the help, got view %v", m.viewMode)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	m := createTestModel()
+
+	m = runCommandLine(t, m, "launch")
+	if len(m.notifications.items) == 0 || m.notifications.items[0].level != NotifyError {
+		t.Error("Expected an unknown command to show an error")
+	}
+
+	m = runCommandLine(t, m, "edit nowhere")
+	if m.viewMode != ViewList || m.editForm != nil {
+		t.Error("Expected an unknown host not to open the edit form")
+	}
+}
+
+func TestCommandEscLeavesSearchAlone(t *testing.T) {
+	m := createTestModel()
+	m = typeCommand(t, m, "d")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+
+	// Letters typed in the command line are not host list keys
+	if m.commandMode || m.deleteMode || m.searchMode {
+		t.Errorf("Expected Esc to only close the command line, got command=%v delete=%v search=%v", m.commandMode, m.deleteMode, m.searchMode)
+	}
+}
+
+func TestCompleteCommand(t *testing.T) {
+	m := createTestModel()
+
+	tests := []struct {
+		value      string
+		want       string
+		candidates int
+	}{
+		{value: "ed", want: "edit "},
+		{value: "co", want: "co", candidates: 4},
+		{value: "edit db", want: "edit db-server "},
+		{value: "edit ser", want: "edit server", candidates: 3},
+		{value: "sort r", want: "sort recent "},
+		{value: "ping s", want: "ping s"},
+	}
+	for _, tt := range tests {
+		got, candidates := m.completeCommand(tt.value)
+		if got != tt.want {
+			t.Errorf("completeCommand(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if tt.candidates > 0 && len(candidates) != tt.candidates {
+			t.Errorf("completeCommand(%q) listed %v, want %d candidates", tt.value, candidates, tt.candidates)
+		}
+	}
+}
+
+func TestKeyMsgFor(t *testing.T) {
+	for _, key := range []string{"e", " ", "enter", "ctrl+t", "alt+x", ":"} {
+		if got := keyMsgFor(key).String(); got != key {
+			t.Errorf("keyMsgFor(%q).String() = %q", key, got)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// configFileEntry is a config file offered by the config switcher
+type configFileEntry struct {
+	path   string
+	recent bool // Used with sshm before, otherwise discovered through Include
+}
+
+// configSwitcherModel lets the user switch the active SSH config file
+type configSwitcherModel struct {
+	entries []configFileEntry
+	active  string
+	cursor  int
+	err     string
+	styles  Styles
+	width   int
+	height  int
+}
+
+// configSwitchMsg is sent when a config file is selected
+type configSwitchMsg struct {
+	path string
+}
+
+// configSwitcherCancelMsg is sent when the switcher is closed without switching
+type configSwitcherCancelMsg struct{}
+
+// NewConfigSwitcher lists the recently used config files followed by the files
+// included from the active one
+func NewConfigSwitcher(activeConfig string, styles Styles, width, height int) *configSwitcherModel {
+	m := &configSwitcherModel{
+		active: activeConfig,
+		styles: styles,
+		width:  width,
+		height: height,
+	}
+
+	recent, err := config.LoadRecentConfigFiles()
+	if err != nil {
+		m.err = err.Error()
+	}
+	discovered, _ := config.GetAllConfigFilesFromBase(activeConfig)
+	m.entries = configSwitcherEntries(activeConfig, recent, discovered)
+
+	for i, e := range m.entries {
+		if e.path == activeConfig {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// configSwitcherEntries merges the active, recent and discovered config files
+// without duplicates. Recent files that no longer exist are skipped.
+func configSwitcherEntries(active string, recent, discovered []string) []configFileEntry {
+	seen := make(map[string]bool)
+	var entries []configFileEntry
+	add := func(path string, isRecent bool) {
+		if path == "" || seen[path] {
+			return
+		}
+		seen[path] = true
+		entries = append(entries, configFileEntry{path: path, recent: isRecent})
+	}
+
+	add(active, true)
+	for _, path := range recent {
+		if _, err := os.Stat(path); err == nil {
+			add(path, true)
+		}
+	}
+
+	sorted := append([]string(nil), discovered...)
+	sort.Strings(sorted)
+	for _, path := range sorted {
+		add(path, false)
+	}
+	return entries
+}
+
+func (m *configSwitcherModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *configSwitcherModel) Update(msg tea.Msg) (*configSwitcherModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "esc", "q":
+		return m, func() tea.Msg { return configSwitcherCancelMsg{} }
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.entries)-1 {
+			m.cursor++
+		}
+
+	case "enter":
+		if len(m.entries) == 0 {
+			return m, nil
+		}
+		path := m.entries[m.cursor].path
+		return m, func() tea.Msg { return configSwitchMsg{path: path} }
+	}
+
+	return m, nil
+}
+
+func (m *configSwitcherModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render("SSH Config Files"))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render(m.err))
+		b.WriteString("\n\n")
+	}
+
+	if len(m.entries) == 0 {
+		b.WriteString(m.styles.HelpText.Render("No config files found"))
+		b.WriteString("\n")
+	}
+
+	visibleHeight := m.height - 14
+	if visibleHeight < 5 {
+		visibleHeight = 5
+	}
+	start := 0
+	if m.cursor >= visibleHeight {
+		start = m.cursor - visibleHeight + 1
+	}
+	end := start + visibleHeight
+	if end > len(m.entries) {
+		end = len(m.entries)
+	}
+
+	for i := start; i < end; i++ {
+		entry := m.entries[i]
+		label := "included"
+		if entry.path == m.active {
+			label = "active"
+		} else if entry.recent {
+			label = "recent"
+		}
+		name := displayConfigPath(entry.path)
+		if i == m.cursor {
+			b.WriteString(m.styles.Selected.Render("▶ " + name))
+		} else {
+			b.WriteString("  " + name)
+		}
+		b.WriteString("  " + m.styles.HelpText.Render("("+label+")"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Enter: switch • Esc: close"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
+
+// displayConfigPath shortens a config file path under the home directory to ~/...
+func displayConfigPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// activeConfigFile returns the absolute path of the SSH config file the host list was read from
+func (m Model) activeConfigFile() string {
+	path := m.configFile
+	if path == "" {
+		path, _ = config.GetDefaultSSHConfigPath()
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// switchConfigFile re-reads the host list from path and makes it the active config
+func (m *Model) switchConfigFile(path string) error {
+	hosts, err := config.ParseSSHConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	m.configFile = path
+	m.hosts = m.sortHosts(hosts)
+	m.sourceFilter = ""
+	m.refreshFilteredHosts()
+	m.table.SetCursor(0)
+
+	_ = config.RecordConfigFile(path)
+	return nil
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConfigSwitcherEntries(t *testing.T) {
+	dir := t.TempDir()
+	active := filepath.Join(dir, "config")
+	work := filepath.Join(dir, "work")
+	included := filepath.Join(dir, "config.d", "lab")
+	for _, f := range []string{active, work} {
+		if err := os.WriteFile(f, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := configSwitcherEntries(active,
+		[]string{work, filepath.Join(dir, "deleted"), active},
+		[]string{included, active})
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.path)
+	}
+	expected := []string{active, work, included}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	if !entries[1].recent || entries[2].recent {
+		t.Errorf("Expected only used files to be marked recent, got %+v", entries)
+	}
+}
+
+func TestSwitchConfigFileRefreshesHosts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	other := filepath.Join(dir, "other_config")
+	content := "Host alpha\n    HostName alpha.example.com\n\nHost beta\n    HostName beta.example.com\n"
+	if err := os.WriteFile(other, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := createTestModel()
+	m.viewMode = ViewConfigSwitcher
+	m.configSwitcher = &configSwitcherModel{entries: []configFileEntry{{path: other}}}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected Enter to select the config file")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+
+	if m.viewMode != ViewList || m.configSwitcher != nil {
+		t.Error("Expected the switcher to close")
+	}
+	if m.configFile != other {
+		t.Errorf("Expected the active config to be %s, got %s", other, m.configFile)
+	}
+	if len(m.hosts) != 2 || m.hosts[0].Name != "alpha" || m.hosts[1].Name != "beta" {
+		t.Errorf("Expected the hosts of the new config, got %+v", m.hosts)
+	}
+	if rows := m.table.Rows(); len(rows) != 2 || !strings.Contains(rows[0][0], "alpha") {
+		t.Errorf("Expected the table to show the new hosts, got %v", rows)
+	}
+	if !strings.Contains(m.View(), "other_config") {
+		t.Error("Expected the footer to show the active config file")
+	}
+
+	recent, err := config.LoadRecentConfigFiles()
+	if err != nil || len(recent) == 0 || recent[0] != other {
+		t.Errorf("Expected %s to be recorded as recently used, got %v (%v)", other, recent, err)
+	}
+}
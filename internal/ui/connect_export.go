@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/atotto/clipboard"
+)
+
+// connectCommandsFile is where connect commands are written without a clipboard
+const connectCommandsFile = "connect_commands.sh"
+
+// toggleMark marks or unmarks a host for actions on several hosts
+func (m *Model) toggleMark(hostName string) {
+	if m.markedHosts == nil {
+		m.markedHosts = make(map[string]bool)
+	}
+	if m.markedHosts[hostName] {
+		delete(m.markedHosts, hostName)
+	} else {
+		m.markedHosts[hostName] = true
+	}
+	m.updateTableRows()
+}
+
+// markedHostNames returns the marked hosts in config order, including those
+// hidden by the current search
+func (m Model) markedHostNames() []string {
+	var names []string
+	for _, host := range m.hosts {
+		if m.markedHosts[host.Name] {
+			names = append(names, host.Name)
+		}
+	}
+	return names
+}
+
+// connectCommands returns the ssh command connecting to each host, one per line
+func connectCommands(hostNames []string, configFile string) string {
+	var b strings.Builder
+	for _, hostName := range hostNames {
+		b.WriteString(transfer.ShellCommand("ssh", config.BuildConnectArgs(hostName, configFile, "")...))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// exportConnectCommands copies commands to the clipboard, or writes them to a
+// file in the sshm config directory when there is no clipboard. The file path
+// is returned in that case.
+func exportConnectCommands(commands string) (string, error) {
+	if err := clipboard.WriteAll(commands); err == nil {
+		return "", nil
+	}
+
+	configDir, err := config.GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(configDir, connectCommandsFile)
+	if err := os.WriteFile(path, []byte(commands), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConnectCommands(t *testing.T) {
+	hosts := []string{"server1", "db-server"}
+
+	expected := "ssh server1\nssh db-server\n"
+	if got := connectCommands(hosts, ""); got != expected {
+		t.Errorf("connectCommands() = %q, want %q", got, expected)
+	}
+
+	expected = "ssh -F '/home/me/my configs/ssh' server1\nssh -F '/home/me/my configs/ssh' db-server\n"
+	if got := connectCommands(hosts, "/home/me/my configs/ssh"); got != expected {
+		t.Errorf("connectCommands() with -F = %q, want %q", got, expected)
+	}
+
+	if got := connectCommands(nil, ""); got != "" {
+		t.Errorf("Expected no commands for no hosts, got %q", got)
+	}
+}
+
+func TestMarkHostsForExport(t *testing.T) {
+	m := createTestModel()
+
+	// Mark db-server, then server1: the export follows the config order
+	m.table.SetCursor(4)
+	m = pressKey(t, m, " ")
+	m.table.SetCursor(0)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	m = updated.(Model)
+
+	if got := m.markedHostNames(); !reflect.DeepEqual(got, []string{"server1", "db-server"}) {
+		t.Fatalf("markedHostNames() = %v", got)
+	}
+	row := m.table.Rows()[0][0]
+	if row[:len("✓")] != "✓" || extractHostNameFromTableRow(row) != "server1" {
+		t.Errorf("Expected a marked row that still resolves to its host, got %q", row)
+	}
+
+	// Marks survive a search hiding the host
+	m.filteredHosts = m.hosts[:1]
+	m.updateTableRows()
+	if got := m.markedHostNames(); len(got) != 2 {
+		t.Errorf("Expected hidden marked hosts to be kept, got %v", got)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	m = updated.(Model)
+	if got := m.markedHostNames(); !reflect.DeepEqual(got, []string{"db-server"}) {
+		t.Errorf("Expected Space to unmark server1, got %v", got)
+	}
+}
+
+func TestExportConnectCommandsWithoutClipboard(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("The clipboard can only be made unavailable on Linux")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	// No display and no clipboard tool on the PATH
+	t.Setenv("PATH", "")
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	path, err := exportConnectCommands("ssh server1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "sshm", connectCommandsFile) {
+		t.Fatalf("Expected the commands in the config dir, got %q", path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "ssh server1\n" {
+		t.Errorf("File content = %q", data)
+	}
+}
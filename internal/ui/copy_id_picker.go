@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// copyIDPickerModel lets the user pick the public key ssh-copy-id installs on a host
+type copyIDPickerModel struct {
+	hostName   string
+	configFile string
+	keys       []string
+	installed  map[string]bool // Keys found in the remote authorized_keys
+	checking   bool
+	checkErr   string
+	confirm    string // Installed key waiting for a second Enter
+	cursor     int
+	err        string
+	styles     Styles
+	width      int
+	height     int
+}
+
+// copyIDCheckMsg carries the remote authorized_keys read before installing a key
+type copyIDCheckMsg struct {
+	hostName   string
+	authorized string
+	err        error
+}
+
+// copyIDPickedMsg is sent when a public key has been chosen
+type copyIDPickedMsg struct {
+	hostName  string
+	publicKey string
+}
+
+// copyIDPickerCancelMsg is sent when the picker is closed without choosing
+type copyIDPickerCancelMsg struct{}
+
+// copyIDDoneMsg is sent when ssh-copy-id exits
+type copyIDDoneMsg struct {
+	hostName  string
+	publicKey string
+	err       error
+}
+
+// NewCopyIDPicker creates a picker listing the public keys from ~/.ssh
+func NewCopyIDPicker(hostName, configFile string, styles Styles, width, height int) *copyIDPickerModel {
+	m := &copyIDPickerModel{
+		hostName:   hostName,
+		configFile: configFile,
+		checking:   true,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+
+	keys, err := config.ListPublicKeys()
+	if err != nil {
+		m.err = err.Error()
+	} else if len(keys) == 0 {
+		m.err = "No public keys found in ~/.ssh (create one with ssh-keygen)"
+	}
+	m.keys = keys
+
+	return m
+}
+
+// checkAuthorizedKeys returns a command reading the authorized_keys of a host.
+// It fails on hosts that don't accept any of our keys yet, which is fine:
+// the keys are then simply shown as not checked.
+func checkAuthorizedKeys(hostName, configFile string) tea.Cmd {
+	return func() tea.Msg {
+		session, err := transfer.NewSFTPSession(hostName, configFile)
+		if err != nil {
+			return copyIDCheckMsg{hostName: hostName, err: err}
+		}
+		defer session.Close()
+
+		authorized, err := session.AuthorizedKeys()
+		return copyIDCheckMsg{hostName: hostName, authorized: authorized, err: err}
+	}
+}
+
+// copyIDCommand returns the ssh-copy-id command installing publicKey on a host
+func copyIDCommand(hostName, configFile, publicKey string) *exec.Cmd {
+	return exec.Command("ssh-copy-id", config.BuildCopyIDArgs(hostName, configFile, publicKey)...)
+}
+
+// setAuthorizedKeys marks the keys already present in the remote authorized_keys
+func (m *copyIDPickerModel) setAuthorizedKeys(authorized string, err error) {
+	m.checking = false
+	if err != nil {
+		m.checkErr = err.Error()
+		return
+	}
+
+	m.installed = make(map[string]bool)
+	for _, key := range m.keys {
+		data, readErr := os.ReadFile(key)
+		if readErr == nil && config.PublicKeyInstalled(string(data), authorized) {
+			m.installed[key] = true
+		}
+	}
+}
+
+func (m *copyIDPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *copyIDPickerModel) Update(msg tea.Msg) (*copyIDPickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case copyIDCheckMsg:
+		if msg.hostName == m.hostName {
+			m.setAuthorizedKeys(msg.authorized, msg.err)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return copyIDPickerCancelMsg{} }
+
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			m.confirm = ""
+
+		case "down", "j":
+			if m.cursor < len(m.keys)-1 {
+				m.cursor++
+			}
+			m.confirm = ""
+
+		case "enter":
+			if len(m.keys) == 0 {
+				return m, nil
+			}
+			key := m.keys[m.cursor]
+			// Installing a key twice only adds a duplicate line, so ask first
+			if m.installed[key] && m.confirm != key {
+				m.confirm = key
+				return m, nil
+			}
+			picked := copyIDPickedMsg{hostName: m.hostName, publicKey: key}
+			return m, func() tea.Msg { return picked }
+		}
+	}
+
+	return m, nil
+}
+
+func (m *copyIDPickerModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Install a public key on %s", m.hostName)))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render(m.err))
+		b.WriteString("\n")
+	}
+
+	visibleHeight := m.height - 14
+	if visibleHeight < 5 {
+		visibleHeight = 5
+	}
+	start := 0
+	if m.cursor >= visibleHeight {
+		start = m.cursor - visibleHeight + 1
+	}
+	end := start + visibleHeight
+	if end > len(m.keys) {
+		end = len(m.keys)
+	}
+
+	for i := start; i < end; i++ {
+		name := "~/.ssh/" + filepath.Base(m.keys[i])
+		if m.installed[m.keys[i]] {
+			name += " (already installed)"
+		}
+		line := "  " + name
+		if i == m.cursor {
+			line = m.styles.Selected.Render("▶ " + name)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.confirm != "":
+		b.WriteString(m.styles.Error.Render(filepath.Base(m.confirm) + " is already installed, press Enter again to install it anyway"))
+	case m.checking:
+		b.WriteString(m.styles.HelpText.Render("Checking which keys are already installed..."))
+	case m.checkErr != "":
+		b.WriteString(m.styles.HelpText.Render("Could not check the installed keys: " + m.checkErr))
+	default:
+		b.WriteString(m.styles.HelpText.Render("Runs ssh-copy-id -i <key>, which may ask for the password"))
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Enter: install • Esc: cancel"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createCopyIDTestPicker returns a picker over two public keys in a temp ~/.ssh
+func createCopyIDTestPicker(t *testing.T) (*copyIDPickerModel, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.Mkdir(sshDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]string{
+		"id_ed25519.pub": "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKey1 me@laptop\n",
+		"work.pub":       "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKey2 me@work\n",
+	}
+	for name, content := range keys {
+		if err := os.WriteFile(filepath.Join(sshDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return NewCopyIDPicker("server1", "", NewStyles(120), 120, 40), sshDir
+}
+
+func TestCopyIDPickerListsPublicKeys(t *testing.T) {
+	picker, sshDir := createCopyIDTestPicker(t)
+
+	expected := []string{filepath.Join(sshDir, "id_ed25519.pub"), filepath.Join(sshDir, "work.pub")}
+	if !reflect.DeepEqual(picker.keys, expected) {
+		t.Fatalf("keys = %v, want %v", picker.keys, expected)
+	}
+	if !strings.Contains(picker.View(), "Checking which keys") {
+		t.Error("Expected the pre-check to be shown as running")
+	}
+
+	picker, _ = picker.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected Enter to pick the key")
+	}
+	msg, ok := cmd().(copyIDPickedMsg)
+	if !ok || msg.hostName != "server1" || msg.publicKey != expected[1] {
+		t.Fatalf("Expected work.pub to be picked, got %+v", cmd())
+	}
+
+	want := []string{"ssh-copy-id", "-i", expected[1], "server1"}
+	if args := copyIDCommand(msg.hostName, "", msg.publicKey).Args; !reflect.DeepEqual(args, want) {
+		t.Errorf("copyIDCommand() args = %v, want %v", args, want)
+	}
+}
+
+func TestCopyIDPickerMarksInstalledKeys(t *testing.T) {
+	picker, _ := createCopyIDTestPicker(t)
+
+	authorized := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKey1 old comment\n"
+	picker, _ = picker.Update(copyIDCheckMsg{hostName: "server1", authorized: authorized})
+	if !picker.installed[picker.keys[0]] || picker.installed[picker.keys[1]] {
+		t.Fatalf("Expected only id_ed25519.pub to be installed, got %v", picker.installed)
+	}
+	if !strings.Contains(picker.View(), "id_ed25519.pub (already installed)") {
+		t.Error("Expected the installed key to be marked")
+	}
+
+	// Re-installing asks for a second Enter
+	picker, cmd := picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Fatal("Expected a confirmation before installing the key again")
+	}
+	if !strings.Contains(picker.View(), "press Enter again") {
+		t.Error("Expected the confirmation to be shown")
+	}
+	_, cmd = picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected the second Enter to pick the key")
+	}
+	if _, ok := cmd().(copyIDPickedMsg); !ok {
+		t.Errorf("Expected copyIDPickedMsg, got %T", cmd())
+	}
+}
+
+func TestCopyIDPickerCheckFailure(t *testing.T) {
+	picker, _ := createCopyIDTestPicker(t)
+
+	picker, _ = picker.Update(copyIDCheckMsg{hostName: "server1", err: errors.New("unable to authenticate")})
+	if len(picker.installed) != 0 {
+		t.Errorf("Expected no key to be marked, got %v", picker.installed)
+	}
+	if !strings.Contains(picker.View(), "Could not check the installed keys") {
+		t.Error("Expected the failed check to be reported")
+	}
+
+	_, cmd := picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected keys to stay installable without the pre-check")
+	}
+}
+
+func TestCopyIDOpensFromList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := createTestModel()
+
+	m = pressKey(t, m, "K")
+	if m.viewMode != ViewCopyID || m.copyIDPicker == nil {
+		t.Fatalf("Expected the key picker, got view %v", m.viewMode)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected Esc to close the picker")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.viewMode != ViewList || m.copyIDPicker != nil {
+		t.Errorf("Expected to return to the list, got view %v", m.viewMode)
+	}
+}
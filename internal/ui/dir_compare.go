@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// remoteBrowserDirCompareMsg carries the comparison of a remote directory
+// with a local one
+type remoteBrowserDirCompareMsg struct {
+	dir        transfer.RemoteFile
+	comparison *transfer.DirComparison
+	err        error
+}
+
+// dirCompareView shows side by side what differs between a remote and a
+// local directory. It is read-only: syncing is left to a transfer.
+type dirCompareView struct {
+	comparison *transfer.DirComparison
+	viewport   viewport.Model
+}
+
+// compareDirectory compares the remote directory dir with a local directory
+func (m *remoteBrowserModel) compareDirectory(dir transfer.RemoteFile, localPath string) tea.Cmd {
+	session := m.session
+	return func() tea.Msg {
+		comparison, err := session.CompareDirectories(dir.Path, localPath)
+		return remoteBrowserDirCompareMsg{dir: dir, comparison: comparison, err: err}
+	}
+}
+
+// newDirCompareView shows a comparison in a window of the given size
+func newDirCompareView(comparison *transfer.DirComparison, width, height int) *dirCompareView {
+	v := &dirCompareView{comparison: comparison, viewport: newScrollViewport()}
+	v.fit(width, height)
+	return v
+}
+
+// fit sizes the scrollable rows to the window, leaving room for the title,
+// the summary and the help of the browser
+func (v *dirCompareView) fit(width, height int) {
+	fitScrollViewport(&v.viewport, dirCompareRows(v.comparison, width), height, 10)
+}
+
+// summary counts the differences of the comparison
+func (v *dirCompareView) summary() string {
+	c := v.comparison
+	if c.Identical() {
+		return fmt.Sprintf("✓ Same %d entries on both sides", c.Same)
+	}
+	return fmt.Sprintf("%d changed, %d only remote, %d only local, %d identical",
+		len(c.Changed), len(c.RemoteOnly), len(c.LocalOnly), c.Same)
+}
+
+// dirCompareRows renders the differences as two columns, remote on the
+// left and local on the right: changed entries first, then the entries
+// missing from one side
+func dirCompareRows(c *transfer.DirComparison, width int) string {
+	column := (width - 9) / 2
+	if column < 24 {
+		column = 24
+	}
+
+	var b strings.Builder
+	row := func(marker, remote, local string) {
+		b.WriteString(fmt.Sprintf("  %s %s │ %s\n", marker, padCell(remote, column), padCell(local, column)))
+	}
+
+	row(" ", "Remote: "+c.RemotePath, "Local: "+c.LocalPath)
+	b.WriteString("    " + strings.Repeat("─", column) + "─┼─" + strings.Repeat("─", column) + "\n")
+
+	if c.Identical() {
+		row(" ", "(no differences)", "")
+	}
+	for _, e := range c.Changed {
+		row("~", compareCell(e.Remote.Name, e.Remote.IsDir, e.Remote.Size, e.Remote.ModTime),
+			compareCell(e.Local.Name, e.Local.IsDir, e.Local.Size, e.Local.ModTime))
+	}
+	for _, f := range c.RemoteOnly {
+		row("<", compareCell(f.Name, f.IsDir, f.Size, f.ModTime), "—")
+	}
+	for _, f := range c.LocalOnly {
+		row(">", "—", compareCell(f.Name, f.IsDir, f.Size, f.ModTime))
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// compareCell describes an entry of a compared directory: directories by
+// name, files with their size and modification time
+func compareCell(name string, isDir bool, size int64, modTime time.Time) string {
+	if isDir {
+		return name + "/"
+	}
+	cell := name + "  " + transfer.FormatByteSize(size)
+	if !modTime.IsZero() {
+		cell += "  " + modTime.Local().Format("2006-01-02 15:04")
+	}
+	return cell
+}
+
+// padCell fits s to exactly width runes, ending truncated text with "…"
+func padCell(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// View renders the comparison with its summary
+func (v *dirCompareView) View(styles Styles) string {
+	var b strings.Builder
+	b.WriteString(v.viewport.View() + "\n\n")
+	b.WriteString(styles.HelpText.Render("  "+v.summary()) + "\n")
+	if indicator := scrollIndicator(v.viewport); indicator != "" {
+		b.WriteString(styles.HelpText.Render("  "+indicator) + "\n")
+	}
+	return b.String()
+}
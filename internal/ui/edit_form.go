@@ -657,7 +657,7 @@ func RunEditForm(hostName string, configFile string) error {
 	}
 
 	m := standaloneEditForm{editForm}
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := newProgram(m)
 	_, err = p.Run()
 	return err
 }
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+func TestListEmptyStateNoHosts(t *testing.T) {
+	m := createTestModel()
+	m.configFile = "/tmp/empty_config"
+	m.hosts = nil
+	m.filteredHosts = nil
+	m.updateTableRows()
+
+	view := m.View()
+	if !strings.Contains(view, "No hosts found in /tmp/empty_config") || !strings.Contains(view, "press 'a' to add one") {
+		t.Errorf("Expected the add host guidance, got:\n%s", view)
+	}
+}
+
+func TestListEmptyStateNoMatches(t *testing.T) {
+	m := createTestModel()
+	m.searchInput.SetValue("xyz")
+	m.filteredHosts = m.filterHosts("xyz")
+	m.updateTableRows()
+
+	if view := m.View(); !strings.Contains(view, "No matches for 'xyz'") || !strings.Contains(view, "press / to change the search") {
+		t.Errorf("Expected the no matches guidance, got:\n%s", view)
+	}
+
+	m.searchMode = true
+	if view := m.View(); !strings.Contains(view, "clear it to see all 5 hosts") {
+		t.Errorf("Expected the search mode guidance, got:\n%s", view)
+	}
+}
+
+func TestListEmptyStateHiddenWithHosts(t *testing.T) {
+	m := createTestModel()
+	if state := m.listEmptyState(); state != "" {
+		t.Errorf("Expected no empty state with hosts listed, got %q", state)
+	}
+}
+
+func TestRemoteBrowserEmptyStates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	newBrowser := func(dir string, files []transfer.RemoteFile) *remoteBrowserModel {
+		m := NewRemoteBrowser("server1", dir, "", BrowseFiles, NewStyles(80), 80, 24)
+		m.currentDir = dir
+		m.loading = false
+		m.files = files
+		m.filterFiles()
+		return m
+	}
+	parent := transfer.RemoteFile{Name: "..", Path: "/srv", IsDir: true}
+
+	tests := []struct {
+		name     string
+		browser  *remoteBrowserModel
+		expected string
+	}{
+		{"empty directory", newBrowser("/srv/app", []transfer.RemoteFile{parent}), "This directory is empty — press Backspace to go up"},
+		{"empty root", newBrowser("/", nil), "This directory is empty"},
+		{"only hidden files", newBrowser("/srv/app", []transfer.RemoteFile{parent, {Name: ".env"}, {Name: ".git", IsDir: true}}), "press '.' to show 2 hidden"},
+	}
+
+	for _, tt := range tests {
+		if view := tt.browser.View(); !strings.Contains(view, tt.expected) {
+			t.Errorf("%s: expected %q in:\n%s", tt.name, tt.expected, view)
+		}
+	}
+
+	full := newBrowser("/srv/app", []transfer.RemoteFile{parent, {Name: "main.go"}})
+	if state := full.emptyState(); state != "" {
+		t.Errorf("Expected no empty state with files listed, got %q", state)
+	}
+}
+
+func TestRemoteBrowserSearchNoMatches(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := NewRemoteBrowser("server1", "/srv", "", BrowseFiles, NewStyles(80), 80, 24)
+	m.loading = false
+	m.searchMode = true
+	m.searchQuery = "zzz"
+	m.searchTriggered = true
+
+	if view := m.View(); !strings.Contains(view, "No matches for 'zzz'") {
+		t.Errorf("Expected the no matches guidance, got:\n%s", view)
+	}
+}
+
+func TestTransferFormEmptyHistory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	form := NewTransferForm("server1", NewStyles(100), 100, 60, "", transfer.Upload)
+	form.historyItems = nil
+	form.showHistory = true
+
+	if view := form.View(); !strings.Contains(view, "No transfers yet") {
+		t.Errorf("Expected the empty history guidance, got:\n%s", view)
+	}
+}
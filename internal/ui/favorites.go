@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// FavoritesOnlyKey returns the key switching between the favorite hosts and
+// all hosts, as the TUI binds it from the app config
+func FavoritesOnlyKey() string {
+	keys := config.GetDefaultKeyBindings()
+	// Only read the app config, loading a missing one would create it
+	if path, err := config.GetAppConfigPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			if appConfig, err := config.LoadAppConfig(); err == nil && appConfig.KeyBindings.Validate() == nil {
+				keys = appConfig.KeyBindings
+			}
+		}
+	}
+	return keyName(keys.KeyFor(config.ActionFavoritesOnly))
+}
+
+// visibleHosts returns the hosts the list and the search draw from: only the
+// favorites in favorites-only mode, only the hosts of the tag filter and of
+// the source file filter if set
+func (m Model) visibleHosts() []config.SSHHost {
+	if !m.favoritesOnly && m.tagFilter == "" && m.sourceFilter == "" {
+		return m.hosts
+	}
+	var visible []config.SSHHost
+	for _, host := range m.hosts {
+		if m.favoritesOnly && !m.favorites[host.Name] {
+			continue
+		}
+		if m.tagFilter != "" && !config.HasTag(host, m.tagFilter) {
+			continue
+		}
+		if m.sourceFilter != "" && host.SourceFile != m.sourceFilter {
+			continue
+		}
+		visible = append(visible, host)
+	}
+	return visible
+}
+
+// refreshFilteredHosts re-applies the search, the favorites-only mode and the
+// tag and source file filters
+func (m *Model) refreshFilteredHosts() {
+	if m.searchInput.Value() != "" {
+		m.filteredHosts = m.filterHosts(m.searchInput.Value())
+	} else {
+		m.filteredHosts = m.sortHosts(m.visibleHosts())
+	}
+	m.updateTableRows()
+}
+
+// toggleFavorite marks or unmarks a host as favorite and saves the favorites
+func (m *Model) toggleFavorite(hostName string) error {
+	favorite := !m.favorites[hostName]
+	if err := config.SetFavorite(hostName, favorite); err != nil {
+		return err
+	}
+	if m.favorites == nil {
+		m.favorites = make(map[string]bool)
+	}
+	if favorite {
+		m.favorites[hostName] = true
+	} else {
+		delete(m.favorites, hostName)
+	}
+	m.refreshFilteredHosts()
+	return nil
+}
+
+// toggleFavoritesOnly switches between the favorite hosts and all hosts
+func (m *Model) toggleFavoritesOnly() {
+	m.favoritesOnly = !m.favoritesOnly
+	m.refreshFilteredHosts()
+	m.table.SetCursor(0)
+}
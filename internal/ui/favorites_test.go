@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// useFavoritesConfig points the app config to a temporary directory, with
+// favorites-only mode set as given and the named hosts starred
+func useFavoritesConfig(t *testing.T, favoritesOnly bool, favorites ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.FavoritesOnly = favoritesOnly
+	if err := config.SaveAppConfig(&appConfig); err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range favorites {
+		if err := config.SetFavorite(host, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func hostNames(hosts []config.SSHHost) []string {
+	var names []string
+	for _, host := range hosts {
+		names = append(names, host.Name)
+	}
+	return names
+}
+
+func TestFavoritesOnlyStartup(t *testing.T) {
+	useFavoritesConfig(t, true, "web-server", "db-server")
+
+	m := NewModel(createTestModel().hosts, "", "test")
+	names := hostNames(m.filteredHosts)
+	if len(names) != 2 || names[0] != "db-server" || names[1] != "web-server" {
+		t.Fatalf("Expected only the favorites at startup, got %v", names)
+	}
+	if rows := m.table.Rows(); len(rows) != 2 {
+		t.Errorf("Expected the table to list the 2 favorites, got %d rows", len(rows))
+	}
+
+	// The search only looks among the favorites
+	if found := hostNames(m.filterHosts("server")); len(found) != 2 {
+		t.Errorf("Expected the search to stay within the favorites, got %v", found)
+	}
+
+	m.toggleFavoritesOnly()
+	if len(m.filteredHosts) != 5 {
+		t.Errorf("Expected O to reveal all 5 hosts, got %v", hostNames(m.filteredHosts))
+	}
+}
+
+func TestFavoritesFlagOverridesConfig(t *testing.T) {
+	useFavoritesConfig(t, false, "server2")
+
+	m := newModel(createTestModel().hosts, "", "test", true)
+	if names := hostNames(m.filteredHosts); len(names) != 1 || names[0] != "server2" {
+		t.Errorf("Expected --favorites to list only server2, got %v", names)
+	}
+}
+
+func TestFavoritesOnlyKey(t *testing.T) {
+	useFavoritesConfig(t, false)
+	if key := FavoritesOnlyKey(); key != "O" {
+		t.Errorf("FavoritesOnlyKey() = %q, want the default O", key)
+	}
+
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.KeyBindings.Actions = map[string]string{config.ActionFavoritesOnly: "ctrl+o"}
+	if err := config.SaveAppConfig(&appConfig); err != nil {
+		t.Fatal(err)
+	}
+	if key := FavoritesOnlyKey(); key != "ctrl+o" {
+		t.Errorf("FavoritesOnlyKey() = %q, want the configured ctrl+o", key)
+	}
+}
+
+func TestFavoritesOnlyWithoutFavoritesShowsAll(t *testing.T) {
+	useFavoritesConfig(t, true)
+
+	m := NewModel(createTestModel().hosts, "", "test")
+	if m.favoritesOnly || len(m.filteredHosts) != 5 {
+		t.Errorf("Expected all hosts when none is a favorite, got %v", hostNames(m.filteredHosts))
+	}
+	if len(m.notifications.items) == 0 {
+		t.Error("Expected a notification explaining why all hosts are shown")
+	}
+}
+
+func TestStarSelectedHost(t *testing.T) {
+	useFavoritesConfig(t, false)
+	m := createTestModel()
+	selected, _ := m.selectedSSHHost()
+
+	m = pressKey(t, m, "*")
+	if !m.favorites[selected.Name] {
+		t.Fatalf("Expected * to star %s", selected.Name)
+	}
+	saved, err := config.LoadFavorites()
+	if err != nil || !saved[selected.Name] {
+		t.Errorf("Expected the favorite to be saved, got %v, %v", saved, err)
+	}
+
+	m = pressKey(t, m, "O")
+	if names := hostNames(m.filteredHosts); len(names) != 1 || names[0] != selected.Name {
+		t.Errorf("Expected O to list only %s, got %v", selected.Name, names)
+	}
+
+	m = pressKey(t, m, "*")
+	if m.favorites[selected.Name] || len(m.filteredHosts) != 0 {
+		t.Errorf("Expected * to unstar %s and empty the favorites, got %v", selected.Name, hostNames(m.filteredHosts))
+	}
+}
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// forwardPickerModel lets the user choose which of the port forwardings
+// configured for a host are opened when connecting
+type forwardPickerModel struct {
+	hostName string
+	forwards []config.ConfiguredForward
+	enabled  []bool
+	cursor   int
+	styles   Styles
+	width    int
+	height   int
+}
+
+// configuredForwardsMsg carries the resolved config of a host about to be
+// connected to with a choice of forwards
+type configuredForwardsMsg struct {
+	hostName string
+	options  []config.ResolvedOption
+	err      error
+}
+
+// forwardPickerConnectMsg is sent to connect with the enabled forwards
+type forwardPickerConnectMsg struct {
+	hostName string
+	selected []config.ConfiguredForward
+	all      bool // Every forward is enabled, a plain ssh already opens them
+}
+
+// forwardPickerCancelMsg is sent when the picker is closed without connecting
+type forwardPickerCancelMsg struct{}
+
+// NewForwardPicker creates a picker over the forwards found in the resolved
+// options of a host, all enabled as with a plain ssh
+func NewForwardPicker(hostName string, options []config.ResolvedOption, styles Styles, width, height int) *forwardPickerModel {
+	forwards := config.ConfiguredForwards(options)
+	enabled := make([]bool, len(forwards))
+	for i := range enabled {
+		enabled[i] = true
+	}
+
+	return &forwardPickerModel{
+		hostName: hostName,
+		forwards: forwards,
+		enabled:  enabled,
+		styles:   styles,
+		width:    width,
+		height:   height,
+	}
+}
+
+// loadConfiguredForwards returns a command resolving the config of a host with ssh -G
+func loadConfiguredForwards(hostName, configFile string) tea.Cmd {
+	return func() tea.Msg {
+		if err := transfer.RequireBinary("ssh"); err != nil {
+			return configuredForwardsMsg{hostName: hostName, err: err}
+		}
+		options, err := config.GetResolvedConfig(hostName, configFile)
+		return configuredForwardsMsg{hostName: hostName, options: options, err: err}
+	}
+}
+
+func (m *forwardPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *forwardPickerModel) Update(msg tea.Msg) (*forwardPickerModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "esc", "q":
+		return m, func() tea.Msg { return forwardPickerCancelMsg{} }
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.forwards)-1 {
+			m.cursor++
+		}
+
+	case " ", "x":
+		if len(m.enabled) > 0 {
+			m.enabled[m.cursor] = !m.enabled[m.cursor]
+		}
+
+	case "a":
+		// Enable all, or disable all when they already are
+		all := m.allEnabled()
+		for i := range m.enabled {
+			m.enabled[i] = !all
+		}
+
+	case "enter":
+		connect := forwardPickerConnectMsg{
+			hostName: m.hostName,
+			selected: m.selected(),
+			all:      m.allEnabled(),
+		}
+		return m, func() tea.Msg { return connect }
+	}
+
+	return m, nil
+}
+
+// selected returns the enabled forwards
+func (m *forwardPickerModel) selected() []config.ConfiguredForward {
+	var selected []config.ConfiguredForward
+	for i, forward := range m.forwards {
+		if m.enabled[i] {
+			selected = append(selected, forward)
+		}
+	}
+	return selected
+}
+
+func (m *forwardPickerModel) allEnabled() bool {
+	for _, enabled := range m.enabled {
+		if !enabled {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *forwardPickerModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Connect to %s with forwards", m.hostName)))
+	b.WriteString("\n\n")
+
+	for i, forward := range m.forwards {
+		check := "[ ]"
+		if m.enabled[i] {
+			check = "[x]"
+		}
+		line := "  " + check + " " + forward.String()
+		if i == m.cursor {
+			line = m.styles.Selected.Render("▶ " + check + " " + forward.String())
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Space: toggle • a: all/none • Enter: connect • Esc: cancel"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
+
+// selectiveForwardCommand returns the ssh command connecting to a host of
+// configFile with only the selected forwards, and the temporary config to
+// remove with RemoveForwardlessConfig after
+func selectiveForwardCommand(msg forwardPickerConnectMsg, configFile string) (*exec.Cmd, string, error) {
+	forwardless, err := config.WriteForwardlessConfig(configFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not write the connection config: %w", err)
+	}
+	return exec.Command("ssh", config.BuildSelectiveForwardArgs(msg.hostName, forwardless, msg.selected)...), forwardless, nil
+}
+
+// connectWithForwards connects to a host opening only the selected forwards
+func (m Model) connectWithForwards(msg forwardPickerConnectMsg) tea.Cmd {
+	if msg.all {
+		return m.connectToHost(msg.hostName, "")
+	}
+
+	sshCmd, forwardless, err := selectiveForwardCommand(msg, m.configFile)
+	if err != nil {
+		return notify(NotifyError, err.Error())
+	}
+	if err := transfer.CheckCommand(sshCmd); err != nil {
+		config.RemoveForwardlessConfig(forwardless)
+		return notify(NotifyError, err.Error())
+	}
+
+	var warn tea.Cmd
+	if m.historyManager != nil {
+		if err := m.historyManager.RecordConnection(msg.hostName); err != nil {
+			warn = notify(NotifyWarn, fmt.Sprintf("Could not record connection history: %v", err))
+		}
+	}
+
+	return tea.Batch(warn, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
+		config.RemoveForwardlessConfig(forwardless)
+		return tea.Quit()
+	}))
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// forwardOptions is the resolved config of a host with two forwards
+var forwardOptions = config.ParseResolvedConfig(`hostname db.internal
+localforward 5432 [localhost]:5432
+dynamicforward 1080
+`)
+
+func TestForwardPickerSelectsForwards(t *testing.T) {
+	picker := NewForwardPicker("db", forwardOptions, NewStyles(120), 120, 40)
+	if len(picker.forwards) != 2 || !picker.allEnabled() {
+		t.Fatalf("Expected both forwards enabled, got %+v", picker.forwards)
+	}
+	view := picker.View()
+	for _, want := range []string{"[x] LocalForward 5432 → [localhost]:5432", "[x] DynamicForward 1080"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the picker", want)
+		}
+	}
+
+	// Enter with everything enabled connects as a plain ssh would
+	_, cmd := picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg := cmd().(forwardPickerConnectMsg); !msg.all {
+		t.Error("Expected all forwards to be reported as enabled")
+	}
+
+	picker, _ = picker.Update(tea.KeyMsg{Type: tea.KeyDown})
+	picker, _ = picker.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	_, cmd = picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg := cmd().(forwardPickerConnectMsg)
+	if msg.all || len(msg.selected) != 1 || msg.selected[0].Flag != "-L" {
+		t.Fatalf("Expected only the LocalForward, got %+v", msg)
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configFile, []byte("Host db\n    LocalForward 5432 localhost:5432\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sshCmd, forwardless, err := selectiveForwardCommand(msg, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer config.RemoveForwardlessConfig(forwardless)
+
+	expected := []string{"ssh", "-F", forwardless, "-L", "5432:[localhost]:5432", "db"}
+	if !reflect.DeepEqual(sshCmd.Args, expected) {
+		t.Errorf("ssh args = %v, want %v", sshCmd.Args, expected)
+	}
+}
+
+func TestForwardPickerWithoutForwards(t *testing.T) {
+	m := createTestModel()
+
+	updated, _ := m.Update(configuredForwardsMsg{hostName: "web", options: config.ParseResolvedConfig("hostname web\n")})
+	m = updated.(Model)
+	if m.viewMode != ViewList || m.forwardPicker != nil {
+		t.Fatalf("Expected to stay on the list, got view %v", m.viewMode)
+	}
+	if len(m.notifications.items) != 1 || !strings.Contains(m.notifications.items[0].text, "No LocalForward") {
+		t.Errorf("Expected a notification, got %+v", m.notifications.items)
+	}
+
+	updated, _ = m.Update(configuredForwardsMsg{hostName: "db", options: forwardOptions})
+	m = updated.(Model)
+	if m.viewMode != ViewForwardPicker || m.forwardPicker == nil {
+		t.Errorf("Expected the forward picker, got view %v", m.viewMode)
+	}
+}
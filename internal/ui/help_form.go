@@ -1,26 +1,48 @@
 package ui
 
 import (
+	"fmt"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type helpModel struct {
-	styles Styles
-	width  int
-	height int
+	styles   Styles
+	width    int
+	height   int
+	keys     *config.KeyBindings
+	viewport viewport.Model
 }
 
 // helpCloseMsg is sent when the help window is closed
 type helpCloseMsg struct{}
 
-// NewHelpForm creates a new help form model
-func NewHelpForm(styles Styles, width, height int) *helpModel {
+// NewHelpForm creates a new help form model listing the keys of keys
+func NewHelpForm(styles Styles, width, height int, keys *config.KeyBindings) *helpModel {
 	return &helpModel{
-		styles: styles,
-		width:  width,
-		height: height,
+		styles:   styles,
+		width:    width,
+		height:   height,
+		keys:     keys,
+		viewport: newScrollViewport(),
+	}
+}
+
+// keyLabel renders a key in the commands column
+func (m *helpModel) keyLabel(key string) string {
+	if key == " " {
+		key = "␣"
 	}
+	return m.styles.FocusedLabel.Render(fmt.Sprintf("%-2s ", key))
+}
+
+// actionLabel renders the key bound to an action
+func (m *helpModel) actionLabel(action string) string {
+	return m.keyLabel(m.keys.KeyFor(action))
 }
 
 func (m *helpModel) Init() tea.Cmd {
@@ -31,17 +53,26 @@ func (m *helpModel) Update(msg tea.Msg) (*helpModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "esc", "q", "h", "enter", "ctrl+c":
+		case "esc", "q", "enter", "ctrl+c", m.keys.KeyFor(config.ActionHelp):
 			return m, func() tea.Msg { return helpCloseMsg{} }
 		}
+		// Arrows and page keys scroll the commands when they do not fit
+		m.fitViewport()
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
 	}
 	return m, nil
 }
 
-func (m *helpModel) View() string {
-	// Title
-	title := m.styles.Header.Render("📖 SSHM - Commands")
+// fitViewport sizes the scrollable commands to the window, leaving room for
+// the title, the close hint and the borders
+func (m *helpModel) fitViewport() {
+	fitScrollViewport(&m.viewport, m.commands(), m.height, 12)
+}
 
+// commands returns the two columns of commands shown by the help
+func (m *helpModel) commands() string {
 	// Create two columns of commands for better visual organization
 	leftColumn := lipgloss.JoinVertical(lipgloss.Left,
 		m.styles.FocusedLabel.Render("Navigation & Connection"),
@@ -50,11 +81,50 @@ func (m *helpModel) View() string {
 			m.styles.FocusedLabel.Render("⏎  "),
 			m.styles.HelpText.Render("connect to selected host")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("i  "),
+			m.actionLabel(config.ActionConnectWithKey),
+			m.styles.HelpText.Render("connect with a chosen key")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionConnectForward),
+			m.styles.HelpText.Render("connect choosing configured forwards")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionMark),
+			m.styles.HelpText.Render("mark host")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionRename),
+			m.styles.HelpText.Render("rename host, keeping its history")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionFavorite),
+			m.styles.HelpText.Render("star host as favorite")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionFavoritesOnly),
+			m.styles.HelpText.Render("show favorites only / all hosts")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionTagFilter),
+			m.styles.HelpText.Render("list the hosts of the next tag")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionSourceFilter),
+			m.styles.HelpText.Render("list the hosts of one config file")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionExport),
+			m.styles.HelpText.Render("export connect commands")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionTmux),
+			m.styles.HelpText.Render("connect to marked hosts in tmux panes")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionCopyID),
+			m.styles.HelpText.Render("install a public key (ssh-copy-id)")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionWeb),
+			m.styles.HelpText.Render("open web UI ("+keyName(m.keys.KeyFor(config.ActionWebURL))+": set its URL)")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionInfo),
 			m.styles.HelpText.Render("show host information")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("/  "),
+			m.actionLabel(config.ActionSearch),
 			m.styles.HelpText.Render("search hosts")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionCommand),
+			m.styles.HelpText.Render("type a command (:edit web1, Tab completes)")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("Tab "),
 			m.styles.HelpText.Render("switch focus")),
@@ -62,16 +132,19 @@ func (m *helpModel) View() string {
 		m.styles.FocusedLabel.Render("Host Management"),
 		"",
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("a  "),
+			m.actionLabel(config.ActionAdd),
 			m.styles.HelpText.Render("add new host")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("e  "),
+			m.actionLabel(config.ActionEdit),
 			m.styles.HelpText.Render("edit selected host")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("m  "),
+			m.actionLabel(config.ActionMove),
 			m.styles.HelpText.Render("move host to another config")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("d  "),
+			m.actionLabel(config.ActionCopy),
+			m.styles.HelpText.Render("copy host to another config")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionDelete),
 			m.styles.HelpText.Render("delete selected host")),
 	)
 
@@ -79,31 +152,52 @@ func (m *helpModel) View() string {
 		m.styles.FocusedLabel.Render("Advanced Features"),
 		"",
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("p  "),
+			m.actionLabel(config.ActionPing),
 			m.styles.HelpText.Render("ping all hosts")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("f  "),
+			m.actionLabel(config.ActionPortForward),
 			m.styles.HelpText.Render("setup port forwarding")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("t  "),
+			m.actionLabel(config.ActionTunnels),
+			m.styles.HelpText.Render("background tunnels (x: stop)")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionSOCKSProxy),
+			m.styles.HelpText.Render("SOCKS proxy on port 1080 in background")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionTransfer),
 			m.styles.HelpText.Render("quick file transfer (upload/download)")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("s  "),
+			m.actionLabel(config.ActionRetryTransfer),
+			m.styles.HelpText.Render("retry the last transfer")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionSort),
 			m.styles.HelpText.Render("cycle sort modes")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("n  "),
+			m.actionLabel(config.ActionSortName),
 			m.styles.HelpText.Render("sort by name")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("r  "),
+			m.actionLabel(config.ActionSortRecent),
 			m.styles.HelpText.Render("sort by recent connection")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionSortSwap),
+			m.styles.HelpText.Render("swap back to the previous sort mode")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionSavedViews),
+			m.styles.HelpText.Render("saved views (save/recall searches)")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionSwitchConfig),
+			m.styles.HelpText.Render("switch SSH config file")),
 		"",
 		m.styles.FocusedLabel.Render("System"),
 		"",
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("h  "),
+			m.actionLabel(config.ActionBanner),
+			m.styles.HelpText.Render("collapse/expand the banner")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.actionLabel(config.ActionHelp),
 			m.styles.HelpText.Render("show this help")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("q  "),
+			m.keyLabel(quitKey(m.keys)),
 			m.styles.HelpText.Render("quit application")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("ESC "),
@@ -111,19 +205,30 @@ func (m *helpModel) View() string {
 	)
 
 	// Join the two columns side by side
-	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+	return lipgloss.JoinHorizontal(lipgloss.Top,
 		leftColumn,
 		"    ", // spacing between columns
 		rightColumn,
 	)
+}
+
+func (m *helpModel) View() string {
+	// Title
+	title := m.styles.Header.Render("📖 SSHM - Commands")
+
+	m.fitViewport()
+	footer := m.styles.HelpText.Render(fmt.Sprintf("Press ESC, %s, q or Enter to close", keyName(m.keys.KeyFor(config.ActionHelp))))
+	if indicator := scrollIndicator(m.viewport); indicator != "" {
+		footer = m.styles.HelpText.Render(indicator) + "\n" + footer
+	}
 
 	// Create the main content
 	content := lipgloss.JoinVertical(lipgloss.Center,
 		title,
 		"",
-		columns,
+		m.viewport.View(),
 		"",
-		m.styles.HelpText.Render("Press ESC, h, q or Enter to close"),
+		footer,
 	)
 
 	// Center the help window
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"errors"
+	"io"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errHostKeyRejected is returned when the key of an unknown host is not trusted
+var errHostKeyRejected = errors.New("host key not accepted")
+
+// hostKeyAcceptedMsg is sent once the prompt about an unknown host key is done
+type hostKeyAcceptedMsg struct {
+	err error
+}
+
+// hostKeyPromptExec shows the fingerprint of an unknown host key on the
+// terminal handed over by tea.Exec and records the key once confirmed
+type hostKeyPromptExec struct {
+	unknown *transfer.UnknownHostKeyError
+	in      io.Reader
+	out     io.Writer
+}
+
+// acceptHostKey returns a command asking whether to trust an unknown host key
+func acceptHostKey(unknown *transfer.UnknownHostKeyError) tea.Cmd {
+	return tea.Exec(&hostKeyPromptExec{unknown: unknown, out: io.Discard}, func(err error) tea.Msg {
+		return hostKeyAcceptedMsg{err: err}
+	})
+}
+
+func (e *hostKeyPromptExec) SetStdin(r io.Reader)  { e.in = r }
+func (e *hostKeyPromptExec) SetStdout(w io.Writer) { e.out = w }
+func (e *hostKeyPromptExec) SetStderr(io.Writer)   {}
+
+func (e *hostKeyPromptExec) Run() error {
+	accepted, err := transfer.PromptHostKey(e.in, e.out, e.unknown)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return errHostKeyRejected
+	}
+	return transfer.AcceptHostKey(e.unknown)
+}
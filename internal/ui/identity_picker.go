@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// identityPickerModel lets the user pick an identity file for a single connection
+type identityPickerModel struct {
+	hostName string
+	keys     []string
+	cursor   int
+	err      string
+	styles   Styles
+	width    int
+	height   int
+}
+
+// identityPickedMsg is sent when an identity has been chosen
+type identityPickedMsg struct {
+	hostName string
+	identity string
+	save     bool // Persist as the host's IdentityFile
+}
+
+// identityPickerCancelMsg is sent when the picker is closed without choosing
+type identityPickerCancelMsg struct{}
+
+// NewIdentityPicker creates an identity picker listing keys from ~/.ssh
+func NewIdentityPicker(hostName string, styles Styles, width, height int) *identityPickerModel {
+	m := &identityPickerModel{
+		hostName: hostName,
+		styles:   styles,
+		width:    width,
+		height:   height,
+	}
+
+	keys, err := config.ListIdentityFiles()
+	if err != nil {
+		m.err = err.Error()
+	} else if len(keys) == 0 {
+		m.err = "No keys found in ~/.ssh"
+	}
+	m.keys = keys
+
+	return m
+}
+
+func (m *identityPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *identityPickerModel) Update(msg tea.Msg) (*identityPickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = NewStyles(m.width)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return identityPickerCancelMsg{} }
+
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case "down", "j":
+			if m.cursor < len(m.keys)-1 {
+				m.cursor++
+			}
+
+		case "enter", "ctrl+s":
+			if len(m.keys) == 0 {
+				return m, nil
+			}
+			picked := identityPickedMsg{
+				hostName: m.hostName,
+				identity: m.keys[m.cursor],
+				save:     msg.String() == "ctrl+s",
+			}
+			return m, func() tea.Msg { return picked }
+		}
+	}
+
+	return m, nil
+}
+
+func (m *identityPickerModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Connect to %s with key", m.hostName)))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render(m.err))
+		b.WriteString("\n")
+	}
+
+	visibleHeight := m.height - 12
+	if visibleHeight < 5 {
+		visibleHeight = 5
+	}
+	start := 0
+	if m.cursor >= visibleHeight {
+		start = m.cursor - visibleHeight + 1
+	}
+	end := start + visibleHeight
+	if end > len(m.keys) {
+		end = len(m.keys)
+	}
+
+	for i := start; i < end; i++ {
+		name := "~/.ssh/" + filepath.Base(m.keys[i])
+		line := "  " + name
+		if i == m.cursor {
+			line = m.styles.Selected.Render("▶ " + name)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("Adds -i <key> -o IdentitiesOnly=yes for this connection only"))
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Enter: connect • Ctrl+S: connect and save as IdentityFile • Esc: cancel"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
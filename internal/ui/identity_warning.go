@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// identityWarningModel warns that the IdentityFile of a host is missing before
+// connecting, since the agent may still hold a usable key
+type identityWarningModel struct {
+	hostName string
+	missing  []string
+	styles   Styles
+	width    int
+	height   int
+}
+
+// identityCheckMsg carries the missing identity files of a host about to be connected to
+type identityCheckMsg struct {
+	hostName string
+	missing  []string
+}
+
+// identityWarningProceedMsg is sent when the user connects despite the warning
+type identityWarningProceedMsg struct {
+	hostName string
+}
+
+// identityWarningCancelMsg is sent when the user gives up on the connection
+type identityWarningCancelMsg struct{}
+
+// NewIdentityWarning creates the warning for the missing identity files of a host
+func NewIdentityWarning(hostName string, missing []string, styles Styles, width, height int) *identityWarningModel {
+	return &identityWarningModel{
+		hostName: hostName,
+		missing:  missing,
+		styles:   styles,
+		width:    width,
+		height:   height,
+	}
+}
+
+// checkIdentityFiles returns a command looking for identity files of the host
+// that don't exist. A failing `ssh -G` is not reported here: ssh itself will.
+func checkIdentityFiles(host config.SSHHost, configFile string) tea.Cmd {
+	return func() tea.Msg {
+		missing, err := config.MissingIdentityFiles(host, configFile)
+		if err != nil {
+			missing = nil
+		}
+		return identityCheckMsg{hostName: host.Name, missing: missing}
+	}
+}
+
+func (m *identityWarningModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *identityWarningModel) Update(msg tea.Msg) (*identityWarningModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "esc", "q", "n":
+		return m, func() tea.Msg { return identityWarningCancelMsg{} }
+
+	case "enter", "y":
+		hostName := m.hostName
+		return m, func() tea.Msg { return identityWarningProceedMsg{hostName: hostName} }
+	}
+
+	return m, nil
+}
+
+func (m *identityWarningModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render("Missing Identity File"))
+	b.WriteString("\n\n")
+
+	if len(m.missing) == 1 {
+		b.WriteString("The key configured for " + m.hostName + " does not exist:\n")
+	} else {
+		b.WriteString("The keys configured for " + m.hostName + " do not exist:\n")
+	}
+	for _, path := range m.missing {
+		b.WriteString(m.styles.Error.Render("  " + path))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("The connection may still work with a key from the SSH agent or a password."))
+	b.WriteString("\n\n")
+	b.WriteString(m.styles.HelpText.Render("Enter: connect anyway • Esc: cancel"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
+
+// connectWithIdentityCheck connects to a host, first warning about missing identity files
+func (m Model) connectWithIdentityCheck(hostName string) tea.Cmd {
+	for _, host := range m.hosts {
+		if host.Name == hostName {
+			return checkIdentityFiles(host, m.configFile)
+		}
+	}
+	return m.connectToHost(hostName, "")
+}
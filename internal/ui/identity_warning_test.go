@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/history"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createIdentityTestModel returns a test model with a temporary history and a
+// pending warning for server1
+func createIdentityTestModel(t *testing.T, missing []string) Model {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := createTestModel()
+	m.historyManager = hm
+
+	updated, _ := m.Update(identityCheckMsg{hostName: "server1", missing: missing})
+	return updated.(Model)
+}
+
+// answerIdentityWarning presses key in the warning and applies the resulting message
+func answerIdentityWarning(t *testing.T, m Model, key tea.KeyMsg) (Model, tea.Cmd) {
+	t.Helper()
+	updated, cmd := m.Update(key)
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatalf("Expected %q to answer the warning", key.String())
+	}
+	updated, cmd = m.Update(cmd())
+	return updated.(Model), cmd
+}
+
+func TestIdentityCheckWithoutMissingFilesConnects(t *testing.T) {
+	m := createIdentityTestModel(t, nil)
+
+	if m.viewMode != ViewList || m.identityWarning != nil {
+		t.Errorf("Expected no warning, got view %v", m.viewMode)
+	}
+	if m.historyManager.GetConnectionCount("server1") != 1 {
+		t.Error("Expected to connect straight away")
+	}
+}
+
+func TestIdentityWarningShowsMissingFiles(t *testing.T) {
+	m := createIdentityTestModel(t, []string{"/home/user/.ssh/id_gone"})
+
+	if m.viewMode != ViewIdentityWarning || m.identityWarning == nil {
+		t.Fatalf("Expected the identity warning, got view %v", m.viewMode)
+	}
+	if m.historyManager.GetConnectionCount("server1") != 0 {
+		t.Error("Should not connect before the warning is answered")
+	}
+
+	view := m.View()
+	for _, want := range []string{"server1", "/home/user/.ssh/id_gone", "SSH agent", "connect anyway"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the warning", want)
+		}
+	}
+}
+
+func TestIdentityWarningProceed(t *testing.T) {
+	m := createIdentityTestModel(t, []string{"/home/user/.ssh/id_gone"})
+
+	m, cmd := answerIdentityWarning(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewMode != ViewList || m.identityWarning != nil {
+		t.Errorf("Expected to leave the warning, got view %v", m.viewMode)
+	}
+	if cmd == nil || m.historyManager.GetConnectionCount("server1") != 1 {
+		t.Error("Expected to connect after proceeding")
+	}
+}
+
+func TestIdentityWarningCancel(t *testing.T) {
+	m := createIdentityTestModel(t, []string{"/home/user/.ssh/id_gone"})
+
+	m, cmd := answerIdentityWarning(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.viewMode != ViewList || m.identityWarning != nil || !m.table.Focused() {
+		t.Errorf("Expected to return to the list, got view %v", m.viewMode)
+	}
+	if cmd != nil || m.historyManager.GetConnectionCount("server1") != 0 {
+		t.Error("Cancelling should not connect")
+	}
+}
@@ -3,8 +3,11 @@ package ui
 import (
 	"fmt"
 	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/connectivity"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -16,6 +19,8 @@ type infoFormModel struct {
 	height     int
 	configFile string
 	hostName   string
+	ping       *connectivity.HostPingResult // Last ping of the host, nil if never pinged
+	viewport   viewport.Model               // Scrolls the details when they do not fit
 }
 
 // Messages for communication with parent model
@@ -25,6 +30,10 @@ type infoFormEditMsg struct {
 
 type infoFormCancelMsg struct{}
 
+type infoFormResolvedMsg struct {
+	hostName string
+}
+
 // NewInfoForm creates a new info form model for displaying host details in read-only mode
 func NewInfoForm(hostName string, styles Styles, width, height int, configFile string) (*infoFormModel, error) {
 	// Get the existing host configuration
@@ -48,6 +57,7 @@ func NewInfoForm(hostName string, styles Styles, width, height int, configFile s
 		styles:     styles,
 		width:      width,
 		height:     height,
+		viewport:   newScrollViewport(),
 	}, nil
 }
 
@@ -71,13 +81,29 @@ func (m *infoFormModel) Update(msg tea.Msg) (*infoFormModel, tea.Cmd) {
 		case "e", "enter":
 			// Switch to edit mode
 			return m, func() tea.Msg { return infoFormEditMsg{hostName: m.hostName} }
+
+		case "r":
+			// Show the resolved configuration (ssh -G)
+			return m, func() tea.Msg { return infoFormResolvedMsg{hostName: m.hostName} }
 		}
+
+		m.fitViewport()
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
 	}
 
 	return m, nil
 }
 
-func (m *infoFormModel) View() string {
+// fitViewport sizes the scrollable details to the window, leaving room for
+// the actions and the borders
+func (m *infoFormModel) fitViewport() {
+	fitScrollViewport(&m.viewport, m.details(), m.height, 12)
+}
+
+// details returns the title and the fields of the host
+func (m *infoFormModel) details() string {
 	var b strings.Builder
 
 	// Title
@@ -99,6 +125,7 @@ func (m *infoFormModel) View() string {
 		{"ProxyJump", formatOptionalValue(m.host.ProxyJump)},
 		{"SSH Options", formatSSHOptions(m.host.Options)},
 		{"Tags", formatTags(m.host.Tags)},
+		{"Status", formatPingResult(m.ping, time.Now())},
 	}
 
 	// Render each section
@@ -115,7 +142,7 @@ func (m *infoFormModel) View() string {
 			Foreground(lipgloss.Color("255")) // White
 
 		// If value is empty or default, use a muted style
-		if section.value == "Not set" || section.value == "22" && section.label == "Port" {
+		if section.value == "Not set" || section.value == "Not checked" || section.value == "22" && section.label == "Port" {
 			valueStyle = valueStyle.Foreground(lipgloss.Color("243")) // Gray
 		}
 
@@ -129,6 +156,19 @@ func (m *infoFormModel) View() string {
 		b.WriteString("\n")
 	}
 
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (m *infoFormModel) View() string {
+	var b strings.Builder
+
+	m.fitViewport()
+	b.WriteString(m.viewport.View())
+	b.WriteString("\n")
+	if indicator := scrollIndicator(m.viewport); indicator != "" {
+		b.WriteString(m.styles.HelpText.Render(indicator))
+		b.WriteString("\n")
+	}
 	b.WriteString("\n")
 
 	// Action instructions
@@ -148,6 +188,11 @@ func (m *infoFormModel) View() string {
 	b.WriteString(helpStyle.Render(" - Switch to edit mode"))
 	b.WriteString("\n")
 
+	b.WriteString("  ")
+	b.WriteString(actionStyle.Render("r"))
+	b.WriteString(helpStyle.Render(" - Show resolved config (ssh -G)"))
+	b.WriteString("\n")
+
 	b.WriteString("  ")
 	b.WriteString(actionStyle.Render("q/Esc"))
 	b.WriteString(helpStyle.Render(" - Return to host list"))
@@ -173,6 +218,18 @@ func (m *infoFormModel) View() string {
 
 // Helper functions for formatting values
 
+// formatPingResult shows the last known status of a host and how long ago it was checked
+func formatPingResult(result *connectivity.HostPingResult, now time.Time) string {
+	if result == nil {
+		return "Not checked"
+	}
+	if result.CheckedAt.IsZero() {
+		return result.Status.String() + "..."
+	}
+	age := now.Sub(result.CheckedAt).Truncate(time.Second)
+	return fmt.Sprintf("%s (checked %s ago)", result.Status, age)
+}
+
 func formatOptionalValue(value string) string {
 	if value == "" {
 		return "Not set"
@@ -203,6 +260,8 @@ func (m standaloneInfoForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg.(type) {
 	case infoFormCancelMsg:
 		return m, tea.Quit
+	case infoFormResolvedMsg:
+		return m, nil
 	case infoFormEditMsg:
 		// For standalone mode, just quit - parent should handle edit transition
 		return m, tea.Quit
@@ -222,7 +281,7 @@ func RunInfoForm(hostName string, configFile string) error {
 	}
 	m := standaloneInfoForm{infoForm}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := newProgram(m)
 	_, err = p.Run()
 	return err
 }
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// errUnlockCancelled is returned when no passphrase was entered
+var errUnlockCancelled = errors.New("passphrase entry cancelled")
+
+// keyUnlockedMsg is sent once the passphrase prompt of a locked key is done
+type keyUnlockedMsg struct {
+	err error
+}
+
+// keyUnlockExec asks for the passphrase of a private key on the terminal
+// handed over by tea.Exec, then unlocks the key for the next SFTP sessions
+type keyUnlockExec struct {
+	locked *transfer.KeyLockedError
+	in     io.Reader
+	out    io.Writer
+}
+
+// unlockKey returns a command prompting for the passphrase of a locked key
+func unlockKey(locked *transfer.KeyLockedError) tea.Cmd {
+	return tea.Exec(&keyUnlockExec{locked: locked, out: io.Discard}, func(err error) tea.Msg {
+		return keyUnlockedMsg{err: err}
+	})
+}
+
+func (e *keyUnlockExec) SetStdin(r io.Reader)  { e.in = r }
+func (e *keyUnlockExec) SetStdout(w io.Writer) { e.out = w }
+func (e *keyUnlockExec) SetStderr(io.Writer)   {}
+
+func (e *keyUnlockExec) Run() error {
+	fmt.Fprintf(e.out, "\nEnter passphrase for %s (empty to cancel): ", e.locked.Path)
+	passphrase, err := readPassphrase(e.in)
+	fmt.Fprintln(e.out)
+	if err != nil {
+		return err
+	}
+	if len(passphrase) == 0 {
+		return errUnlockCancelled
+	}
+	return transfer.UnlockIdentityFile(e.locked.Path, passphrase, e.locked.AddKeysToAgent)
+}
+
+// readPassphrase reads a line without echoing it when in is a terminal
+func readPassphrase(in io.Reader) ([]byte, error) {
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return term.ReadPassword(int(f.Fd()))
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// keyBindings returns the configured key bindings, the defaults without an
+// app config
+func (m Model) keyBindings() *config.KeyBindings {
+	if m.appConfig == nil {
+		defaults := config.GetDefaultKeyBindings()
+		return &defaults
+	}
+	return &m.appConfig.KeyBindings
+}
+
+// applyNavigationKeys binds the configured up and down keys of the host
+// table, next to the arrow keys
+func applyNavigationKeys(t *table.Model, keys *config.KeyBindings) {
+	t.KeyMap.LineUp.SetKeys("up", keys.KeyFor(config.ActionMoveUp))
+	t.KeyMap.LineDown.SetKeys("down", keys.KeyFor(config.ActionMoveDown))
+}
+
+// quitKey is the first configured quit key, q by default
+func quitKey(keys *config.KeyBindings) string {
+	if len(keys.QuitKeys) > 0 {
+		return keys.QuitKeys[0]
+	}
+	return "q"
+}
+
+// keyName shows a key the way it is typed
+func keyName(key string) string {
+	if key == " " {
+		return "space"
+	}
+	return key
+}
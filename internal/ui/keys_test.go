@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReboundActionKeys(t *testing.T) {
+	m := createTestModel()
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.KeyBindings.Actions = map[string]string{config.ActionHelp: "?", config.ActionSortSwap: "o"}
+	appConfig.KeyBindings.QuitKeys = []string{"Q", "ctrl+c"}
+	m.appConfig = &appConfig
+
+	// The default keys no longer trigger their actions
+	m = pressKey(t, m, "h")
+	if m.viewMode == ViewHelp {
+		t.Fatal("Expected h not to open the help once help is bound to ?")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd != nil {
+		if _, quit := cmd().(tea.QuitMsg); quit {
+			t.Fatal("Expected q not to quit once the quit keys are Q and ctrl+c")
+		}
+	}
+
+	m = pressKey(t, m, "o")
+	if m.sortMode != SortByLastUsed {
+		t.Errorf("Expected o to swap the sort mode, got %v", m.sortMode)
+	}
+
+	if footer := m.View(); !strings.Contains(footer, "?: help") || !strings.Contains(footer, "Q: quit") {
+		t.Errorf("Expected the footer to show the bound keys, got %q", footer)
+	}
+
+	m = pressKey(t, m, "?")
+	if m.viewMode != ViewHelp {
+		t.Fatal("Expected ? to open the help")
+	}
+	if !strings.Contains(m.View(), "Press ESC, ?, q or Enter to close") {
+		t.Error("Expected the help to show the bound help key")
+	}
+
+	m.viewMode = ViewList
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Q")}); cmd == nil {
+		t.Fatal("Expected Q to quit")
+	} else if _, quit := cmd().(tea.QuitMsg); !quit {
+		t.Error("Expected Q to quit")
+	}
+}
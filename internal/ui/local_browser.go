@@ -0,0 +1,348 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// localBrowserModel is an in-terminal browser for local files, used instead of
+// the native OS picker when it is unavailable or disabled by PreferTUIPicker
+type localBrowserModel struct {
+	currentDir   string
+	files        []transfer.RemoteFile // All entries of currentDir
+	visibleFiles []transfer.RemoteFile // Filtered entries (respects showHidden)
+	cursor       int
+	selected     string
+	err          string
+	mode         BrowserMode
+	showHidden   bool
+	styles       Styles
+	width        int
+	height       int
+}
+
+// localBrowserResultMsg is sent when local browsing is complete
+type localBrowserResultMsg struct {
+	path     string
+	selected bool
+}
+
+// openLocalBrowserMsg requests the main app to open the local browser
+type openLocalBrowserMsg struct {
+	startDir string
+	mode     BrowserMode
+}
+
+// NewLocalBrowser creates a new local file browser
+func NewLocalBrowser(startDir string, mode BrowserMode, styles Styles, width, height int) *localBrowserModel {
+	if startDir == "" {
+		startDir, _ = os.Getwd()
+	}
+	if expanded, err := transfer.ExpandPath(startDir); err == nil {
+		startDir = expanded
+	}
+	// Start from the containing directory when given a file
+	if info, err := os.Stat(startDir); err == nil && !info.IsDir() {
+		startDir = filepath.Dir(startDir)
+	}
+
+	m := &localBrowserModel{
+		mode:   mode,
+		styles: styles,
+		width:  width,
+		height: height,
+	}
+	m.loadDirectory(startDir)
+	if m.currentDir == "" {
+		// Start path is unreadable, fall back to the working directory
+		cwd, _ := os.Getwd()
+		m.loadDirectory(cwd)
+	}
+	return m
+}
+
+func (m *localBrowserModel) Init() tea.Cmd {
+	return nil
+}
+
+// loadDirectory reads a local directory, keeping the current one on error
+func (m *localBrowserModel) loadDirectory(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		m.err = err.Error()
+		return
+	}
+
+	files := make([]transfer.RemoteFile, 0, len(entries)+1)
+	if parent := filepath.Dir(dir); parent != dir {
+		files = append(files, transfer.RemoteFile{Name: "..", Path: parent, IsDir: true})
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		file := transfer.RemoteFile{Name: entry.Name(), Path: path, IsDir: entry.IsDir()}
+		// Follow symlinks so linked directories can be entered
+		if info, err := os.Stat(path); err == nil {
+			file.IsDir = info.IsDir()
+			file.Size = info.Size()
+		}
+		files = append(files, file)
+	}
+	transfer.SortRemoteFiles(files)
+
+	m.currentDir = dir
+	m.files = files
+	m.cursor = 0
+	m.err = ""
+	m.filterFiles()
+}
+
+// filterFiles updates visibleFiles based on showHidden setting
+func (m *localBrowserModel) filterFiles() {
+	m.visibleFiles = m.visibleFiles[:0]
+	for _, f := range m.files {
+		if !m.showHidden && strings.HasPrefix(f.Name, ".") && f.Name != ".." {
+			continue
+		}
+		m.visibleFiles = append(m.visibleFiles, f)
+	}
+	if m.cursor >= len(m.visibleFiles) {
+		m.cursor = len(m.visibleFiles) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// result returns a command reporting the browser outcome
+func (m *localBrowserModel) result(path string, selected bool) tea.Cmd {
+	return func() tea.Msg {
+		return localBrowserResultMsg{path: path, selected: selected}
+	}
+}
+
+func (m *localBrowserModel) Update(msg tea.Msg) (*localBrowserModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "ctrl+c":
+		return m, m.result("", false)
+
+	case "enter":
+		if len(m.visibleFiles) == 0 {
+			return m, nil
+		}
+		file := m.visibleFiles[m.cursor]
+		if file.IsDir {
+			m.loadDirectory(file.Path)
+			return m, nil
+		}
+		if m.mode == BrowseFiles {
+			return m, m.result(file.Path, true)
+		}
+		return m, nil
+
+	case "s", " ":
+		// Select current directory (for BrowseDirectories mode)
+		if m.mode == BrowseDirectories {
+			return m, m.result(m.currentDir, true)
+		}
+		return m, nil
+
+	case ".":
+		// Toggle hidden files
+		m.showHidden = !m.showHidden
+		m.filterFiles()
+		return m, nil
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.cursor < len(m.visibleFiles)-1 {
+			m.cursor++
+		}
+		return m, nil
+
+	case "home", "g":
+		m.cursor = 0
+		return m, nil
+
+	case "end", "G":
+		if len(m.visibleFiles) > 0 {
+			m.cursor = len(m.visibleFiles) - 1
+		}
+		return m, nil
+
+	case "backspace", "h", "left":
+		// Go to parent directory
+		if parent := filepath.Dir(m.currentDir); parent != m.currentDir {
+			m.loadDirectory(parent)
+		}
+		return m, nil
+
+	case "~":
+		// Go to home directory
+		if home, err := os.UserHomeDir(); err == nil {
+			m.loadDirectory(home)
+		}
+		return m, nil
+
+	case "right", "l":
+		// Enter directory if on one
+		if len(m.visibleFiles) > 0 && m.visibleFiles[m.cursor].IsDir {
+			m.loadDirectory(m.visibleFiles[m.cursor].Path)
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m *localBrowserModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.Header.Render("📂 Local Browser"))
+	b.WriteString("\n")
+	b.WriteString(m.styles.DirStyle.Render("  "+m.currentDir) + "\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render("Error: "+m.err) + "\n\n")
+	}
+
+	visibleHeight := m.height - 10
+	if visibleHeight < 5 {
+		visibleHeight = 5
+	}
+	start := 0
+	if m.cursor >= visibleHeight {
+		start = m.cursor - visibleHeight + 1
+	}
+	end := start + visibleHeight
+	if end > len(m.visibleFiles) {
+		end = len(m.visibleFiles)
+	}
+
+	for i := start; i < end; i++ {
+		b.WriteString(m.renderFileLine(m.visibleFiles[i], i == m.cursor) + "\n")
+	}
+	if len(m.visibleFiles) > visibleHeight {
+		b.WriteString(fmt.Sprintf("  [%d/%d]\n", m.cursor+1, len(m.visibleFiles)))
+	}
+
+	b.WriteString("\n")
+	if m.showHidden {
+		b.WriteString("  [hidden: on]\n")
+	} else {
+		b.WriteString("  [hidden: off]\n")
+	}
+
+	if m.mode == BrowseDirectories {
+		b.WriteString(" ↑/↓: navigate | Enter: open | s: select this folder | .: hidden | Esc: cancel\n")
+	} else {
+		b.WriteString(" ↑/↓: navigate | Enter: select | .: hidden | ~: home | Esc: cancel\n")
+	}
+
+	return b.String()
+}
+
+func (m *localBrowserModel) renderFileLine(file transfer.RemoteFile, selected bool) string {
+	var icon, name string
+
+	if file.Name == ".." {
+		icon = "⬆"
+		name = ".."
+	} else if file.IsDir {
+		icon = "📁"
+		name = file.Name + "/"
+	} else {
+		icon = "  "
+		name = file.Name
+	}
+
+	if len(name) > 40 {
+		name = name[:37] + "..."
+	}
+
+	if selected {
+		return ansiSelected + "  " + icon + " " + name + ansiReset
+	}
+	if file.IsDir {
+		return ansiDir + "  " + icon + " " + name + ansiReset
+	}
+	return "  " + icon + " " + name
+}
+
+// browserModeFor maps a native picker mode to the equivalent browser mode
+func browserModeFor(mode transfer.PickerMode) BrowserMode {
+	if mode == transfer.PickDirectory {
+		return BrowseDirectories
+	}
+	return BrowseFiles
+}
+
+// Standalone browser for CLI use
+
+type standaloneLocalBrowser struct {
+	*localBrowserModel
+}
+
+func (m standaloneLocalBrowser) Init() tea.Cmd {
+	return m.localBrowserModel.Init()
+}
+
+func (m standaloneLocalBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.localBrowserModel.width = msg.Width
+		m.localBrowserModel.height = msg.Height
+		m.localBrowserModel.styles = NewStyles(msg.Width)
+		return m, nil
+
+	case localBrowserResultMsg:
+		// Store result for retrieval
+		if msg.selected {
+			m.localBrowserModel.selected = msg.path
+		}
+		return m, tea.Quit
+	}
+
+	newModel, cmd := m.localBrowserModel.Update(msg)
+	m.localBrowserModel = newModel
+	return m, cmd
+}
+
+func (m standaloneLocalBrowser) View() string {
+	return m.localBrowserModel.View()
+}
+
+// RunLocalBrowser runs the local browser as a standalone TUI and returns the selected path
+func RunLocalBrowser(startDir string, mode BrowserMode) (string, bool, error) {
+	styles := NewStyles(80)
+	browser := NewLocalBrowser(startDir, mode, styles, 80, 24)
+	m := standaloneLocalBrowser{browser}
+
+	p := newProgram(m)
+	finalModel, err := p.Run()
+	if err != nil {
+		return "", false, err
+	}
+
+	if result, ok := finalModel.(standaloneLocalBrowser); ok {
+		if result.localBrowserModel.selected != "" {
+			return result.localBrowserModel.selected, true, nil
+		}
+	}
+
+	return "", false, nil
+}
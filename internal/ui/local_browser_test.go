@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createTestLocalTree creates dir/{docs/,notes.txt,.hidden}
+func createTestLocalTree(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	for _, name := range []string{"notes.txt", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLocalBrowserListing(t *testing.T) {
+	dir := createTestLocalTree(t)
+	m := NewLocalBrowser(dir, BrowseFiles, NewStyles(80), 80, 24)
+
+	var names []string
+	for _, f := range m.visibleFiles {
+		names = append(names, f.Name)
+	}
+	// Parent first, then directories, then files; dotfiles hidden by default
+	expected := []string{"..", "docs", "notes.txt"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, names)
+			break
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")})
+	if len(m.visibleFiles) != 4 {
+		t.Errorf("Expected hidden file to be shown, got %d entries", len(m.visibleFiles))
+	}
+}
+
+func TestLocalBrowserSelectFile(t *testing.T) {
+	dir := createTestLocalTree(t)
+	m := NewLocalBrowser(dir, BrowseFiles, NewStyles(80), 80, 24)
+
+	// Enter on a directory opens it
+	m.cursor = 1
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.currentDir != filepath.Join(dir, "docs") {
+		t.Fatalf("Expected to enter docs, currentDir = %s", m.currentDir)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m.cursor = 2
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command when selecting a file")
+	}
+	result := cmd().(localBrowserResultMsg)
+	if !result.selected || result.path != filepath.Join(dir, "notes.txt") {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestLocalBrowserSelectDirectory(t *testing.T) {
+	dir := createTestLocalTree(t)
+	m := NewLocalBrowser(filepath.Join(dir, "notes.txt"), BrowseDirectories, NewStyles(80), 80, 24)
+
+	// Starting from a file opens its directory
+	if m.currentDir != dir {
+		t.Fatalf("Expected to start in %s, got %s", dir, m.currentDir)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	result := cmd().(localBrowserResultMsg)
+	if !result.selected || result.path != dir {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if result := cmd().(localBrowserResultMsg); result.selected {
+		t.Error("Expected esc to cancel")
+	}
+}
+
+func TestQuickTransferPickerHonorsPreferTUI(t *testing.T) {
+	tests := []struct {
+		name       string
+		direction  transfer.Direction
+		uploadType UploadType
+		expected   BrowserMode
+	}{
+		{"Upload file", transfer.Upload, UploadFile, BrowseFiles},
+		{"Upload folder", transfer.Upload, UploadFolder, BrowseDirectories},
+		{"Download destination", transfer.Download, UploadFile, BrowseDirectories},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &quickTransferModel{
+				direction:       tt.direction,
+				uploadType:      tt.uploadType,
+				preferTUIPicker: true,
+			}
+			msg, ok := m.openLocalPicker()().(openLocalBrowserMsg)
+			if !ok {
+				t.Fatal("Expected the TUI browser to be requested")
+			}
+			if msg.mode != tt.expected {
+				t.Errorf("Expected mode %v, got %v", tt.expected, msg.mode)
+			}
+		})
+	}
+}
+
+func TestModelRoutesLocalBrowserToQuickTransfer(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := createTestModel()
+	m.viewMode = ViewQuickTransfer
+	m.quickTransferForm = &quickTransferModel{direction: transfer.Download, state: QTStateSelectingLocal}
+
+	newModel, _ := m.Update(openLocalBrowserMsg{startDir: t.TempDir(), mode: BrowseDirectories})
+	m = newModel.(Model)
+	if m.viewMode != ViewLocalBrowser || m.localBrowserForm == nil {
+		t.Fatal("Expected the local browser view to open")
+	}
+
+	newModel, _ = m.Update(localBrowserResultMsg{path: "/tmp/dest", selected: true})
+	m = newModel.(Model)
+	if m.viewMode != ViewQuickTransfer || m.localBrowserForm != nil {
+		t.Error("Expected to return to quick transfer")
+	}
+	if m.quickTransferForm.localPath != "/tmp/dest" {
+		t.Errorf("Expected local path to be set, got %q", m.quickTransferForm.localPath)
+	}
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"context"
+
 	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/connectivity"
 	"github.com/Gu1llaum-3/sshm/internal/history"
@@ -30,6 +32,22 @@ func (s SortMode) String() string {
 	}
 }
 
+// Key returns the identifier a sort mode is stored under in saved views
+func (s SortMode) Key() string {
+	if s == SortByLastUsed {
+		return "last_used"
+	}
+	return "name"
+}
+
+// sortModeFromKey parses a stored sort mode key, defaulting to name
+func sortModeFromKey(key string) SortMode {
+	if key == "last_used" {
+		return SortByLastUsed
+	}
+	return SortByName
+}
+
 // ViewMode defines the current view state
 type ViewMode int
 
@@ -43,8 +61,20 @@ const (
 	ViewTransfer
 	ViewQuickTransfer
 	ViewRemoteBrowser
+	ViewLocalBrowser
 	ViewHelp
 	ViewFileSelector
+	ViewResolvedConfig
+	ViewIdentityPicker
+	ViewSavedViews
+	ViewConfigSwitcher
+	ViewRetryTransfer
+	ViewIdentityWarning
+	ViewCopyID
+	ViewForwardPicker
+	ViewWebURL
+	ViewForwardsList
+	ViewSourceFilter
 )
 
 // PortForwardType defines the type of port forwarding
@@ -83,25 +113,78 @@ type Model struct {
 	sortMode       SortMode
 	configFile     string // Path to the SSH config file
 
+	// Sort mode used before the current one, swapped back to with S
+	previousSortMode SortMode
+	hasPreviousSort  bool
+
+	// Command line opened with :, in place of the footer
+	commandMode  bool
+	commandInput textinput.Model
+	commandHint  string // Completions listed by the last Tab
+
+	// Inline rename of a host, in place of the footer; renameHost is empty
+	// when no host is being renamed
+	renameHost  string
+	renameInput textinput.Model
+
+	// Hosts marked with Space, for actions on several hosts
+	markedHosts map[string]bool
+
+	// Favorite hosts, starred with *, and whether only they are listed
+	favorites     map[string]bool
+	favoritesOnly bool
+
+	// Tag whose hosts only are listed, cycled with #
+	tagFilter string
+
+	// Config file whose hosts only are listed, chosen with g
+	sourceFilter string
+
+	// Host names with the characters matched by the search highlighted
+	nameHighlights map[string]string
+
+	// Ping all run in progress: its results, how to stop it, how many
+	// hosts were checked so far and how they answered. pingRun numbers runs
+	// so stale results of a replaced run are ignored.
+	pingResults <-chan *connectivity.HostPingResult
+	pingCancel  context.CancelFunc
+	pingRun     int
+	pingTotal   int
+	pingDone    int
+	pingTally   pingTally
+
 	// Application configuration
-	appConfig      *config.AppConfig
+	appConfig  *config.AppConfig
+	hideBanner bool // The ASCII title is collapsed to give the table its rows
 
 	// Version update information
 	updateInfo     *version.UpdateInfo
 	currentVersion string
 
 	// View management
-	viewMode          ViewMode
-	addForm           *addFormModel
-	editForm          *editFormModel
-	moveForm          *moveFormModel
-	infoForm          *infoFormModel
-	portForwardForm   *portForwardModel
-	transferForm      *transferFormModel
-	quickTransferForm *quickTransferModel
-	remoteBrowserForm *remoteBrowserModel
-	helpForm          *helpModel
-	fileSelectorForm  *fileSelectorModel
+	viewMode           ViewMode
+	addForm            *addFormModel
+	editForm           *editFormModel
+	moveForm           *moveFormModel
+	infoForm           *infoFormModel
+	portForwardForm    *portForwardModel
+	transferForm       *transferFormModel
+	quickTransferForm  *quickTransferModel
+	remoteBrowserForm  *remoteBrowserModel
+	localBrowserForm   *localBrowserModel
+	helpForm           *helpModel
+	fileSelectorForm   *fileSelectorModel
+	resolvedConfigForm *resolvedConfigModel
+	identityPicker     *identityPickerModel
+	savedViewsForm     *savedViewsModel
+	configSwitcher     *configSwitcherModel
+	retryTransferForm  *retryTransferModel
+	identityWarning    *identityWarningModel
+	copyIDPicker       *copyIDPickerModel
+	forwardPicker      *forwardPickerModel
+	webURLForm         *webURLFormModel
+	forwardsList       *forwardsListModel
+	sourceFilterForm   *sourceFilterModel
 
 	// Terminal size and styles
 	width  int
@@ -109,9 +192,8 @@ type Model struct {
 	styles Styles
 	ready  bool
 
-	// Error handling
-	errorMessage string
-	showingError bool
+	// Toast notifications shown over the list view
+	notifications notificationQueue
 }
 
 // updateTableStyles updates the table header border color based on focus state
@@ -16,6 +16,7 @@ type moveFormModel struct {
 	height       int
 	styles       Styles
 	state        moveFormState
+	duplicate    bool // Copy the host instead of moving it
 }
 
 type moveFormState int
@@ -35,6 +36,22 @@ type moveFormCancelMsg struct{}
 
 // NewMoveForm creates a new move form for moving a host to another config file
 func NewMoveForm(hostName string, styles Styles, width, height int, configFile string) (*moveFormModel, error) {
+	return newMoveForm(hostName, styles, width, height, configFile, false)
+}
+
+// NewCopyForm creates a form for duplicating a host into another config file
+func NewCopyForm(hostName string, styles Styles, width, height int, configFile string) (*moveFormModel, error) {
+	return newMoveForm(hostName, styles, width, height, configFile, true)
+}
+
+func newMoveForm(hostName string, styles Styles, width, height int, configFile string, duplicate bool) (*moveFormModel, error) {
+	operation := "move"
+	title := fmt.Sprintf("Select destination config file for host '%s':", hostName)
+	if duplicate {
+		operation = "copy"
+		title = fmt.Sprintf("Select config file to copy host '%s' into:", hostName)
+	}
+
 	// Get all config files except the one containing the current host
 	files, err := config.GetConfigFilesExcludingCurrent(hostName, configFile)
 	if err != nil {
@@ -42,12 +59,12 @@ func NewMoveForm(hostName string, styles Styles, width, height int, configFile s
 	}
 
 	if len(files) == 0 {
-		return nil, fmt.Errorf("no includes found in SSH config file - move operation requires multiple config files")
+		return nil, fmt.Errorf("no includes found in SSH config file - %s operation requires multiple config files", operation)
 	}
 
-	// Create a custom file selector for move operation
+	// Create a custom file selector for the operation
 	fileSelector, err := newFileSelectorFromFiles(
-		fmt.Sprintf("Select destination config file for host '%s':", hostName),
+		title,
 		styles,
 		width,
 		height,
@@ -65,6 +82,7 @@ func NewMoveForm(hostName string, styles Styles, width, height int, configFile s
 		height:       height,
 		styles:       styles,
 		state:        moveFormSelectingFile,
+		duplicate:    duplicate,
 	}, nil
 }
 
@@ -127,6 +145,10 @@ func (m *moveFormModel) View() string {
 		return "Loading..."
 
 	case moveFormProcessing:
+		if m.duplicate {
+			return m.styles.FormTitle.Render("Copying host...") + "\n\n" +
+				m.styles.HelpText.Render(fmt.Sprintf("Copying host '%s' to selected config file...", m.hostName))
+		}
 		return m.styles.FormTitle.Render("Moving host...") + "\n\n" +
 			m.styles.HelpText.Render(fmt.Sprintf("Moving host '%s' to selected config file...", m.hostName))
 
@@ -137,7 +159,12 @@ func (m *moveFormModel) View() string {
 
 func (m *moveFormModel) submitMove(targetFile string) tea.Cmd {
 	return func() tea.Msg {
-		err := config.MoveHostToFile(m.hostName, targetFile)
+		var err error
+		if m.duplicate {
+			err = config.CopyHostToFile(m.hostName, m.configFile, targetFile)
+		} else {
+			err = config.MoveHostToFile(m.hostName, targetFile)
+		}
 		return moveFormSubmitMsg{
 			hostName:   m.hostName,
 			targetFile: targetFile,
@@ -182,7 +209,7 @@ func RunMoveForm(hostName string, configFile string) error {
 	}
 	m := standaloneMoveForm{moveForm}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := newProgram(m)
 	_, err = p.Run()
 	return err
 }
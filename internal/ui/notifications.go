@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// NotificationLevel is the severity of a toast notification
+type NotificationLevel int
+
+const (
+	NotifyInfo NotificationLevel = iota
+	NotifySuccess
+	NotifyWarn
+	NotifyError
+)
+
+// maxNotifications caps how many toasts are stacked at once; older ones are dropped
+const maxNotifications = 4
+
+// notification is a single toast shown in the list view
+type notification struct {
+	id    int
+	level NotificationLevel
+	text  string
+}
+
+// notificationQueue holds the toasts currently on screen, oldest first
+type notificationQueue struct {
+	items  []notification
+	nextID int
+}
+
+// notifyMsg asks the main model to show a toast. It lets commands and value
+// receivers, which cannot change the model, raise notifications.
+type notifyMsg struct {
+	level NotificationLevel
+	text  string
+}
+
+// expireNotificationMsg removes the toast with the given id once its time is up
+type expireNotificationMsg struct {
+	id int
+}
+
+// push adds a toast and returns it, dropping the oldest when the stack is full
+func (q *notificationQueue) push(level NotificationLevel, text string) notification {
+	q.nextID++
+	n := notification{id: q.nextID, level: level, text: text}
+	q.items = append(q.items, n)
+	if len(q.items) > maxNotifications {
+		q.items = q.items[len(q.items)-maxNotifications:]
+	}
+	return n
+}
+
+// expire removes the toast with the given id, if it is still shown
+func (q *notificationQueue) expire(id int) {
+	for i, n := range q.items {
+		if n.id == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// clear removes every toast
+func (q *notificationQueue) clear() {
+	q.items = nil
+}
+
+// notify returns a command that raises a toast from outside Update
+func notify(level NotificationLevel, text string) tea.Cmd {
+	return func() tea.Msg {
+		return notifyMsg{level: level, text: text}
+	}
+}
+
+// pushNotification shows a toast and schedules it to expire after the
+// configured notification duration
+func (m *Model) pushNotification(level NotificationLevel, text string) tea.Cmd {
+	n := m.notifications.push(level, text)
+	return expireNotificationAfter(n.id, m.notificationDuration())
+}
+
+// showError shows an error toast
+func (m *Model) showError(text string) tea.Cmd {
+	return m.pushNotification(NotifyError, text)
+}
+
+// notificationDuration returns how long toasts stay on screen
+func (m *Model) notificationDuration() time.Duration {
+	if m.appConfig != nil {
+		return m.appConfig.NotificationTimeout()
+	}
+	return config.DefaultNotificationDuration * time.Second
+}
+
+// expireNotificationAfter returns a command that expires the toast with the given id after d.
+// A tick is used so no goroutine sits blocked while the toast is shown.
+func expireNotificationAfter(id int, d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return expireNotificationMsg{id: id}
+	})
+}
+
+// notificationStyle returns the icon and style for a toast level
+func notificationStyle(level NotificationLevel) (string, lipgloss.Style) {
+	style := lipgloss.NewStyle().
+		Bold(true).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder())
+
+	switch level {
+	case NotifySuccess:
+		return "✅", style.Foreground(lipgloss.Color("10")).BorderForeground(lipgloss.Color("10"))
+	case NotifyWarn:
+		return "⚠️ ", style.Foreground(lipgloss.Color("11")).BorderForeground(lipgloss.Color("11"))
+	case NotifyError:
+		return "❌", style.Foreground(lipgloss.Color("9")).Background(lipgloss.Color("1")).BorderForeground(lipgloss.Color("9"))
+	default:
+		return "ℹ️ ", style.Foreground(lipgloss.Color("12")).BorderForeground(lipgloss.Color("12"))
+	}
+}
+
+// renderNotifications renders the toasts stacked against the right edge, newest last
+func (m Model) renderNotifications() string {
+	if len(m.notifications.items) == 0 {
+		return ""
+	}
+
+	toasts := make([]string, 0, len(m.notifications.items))
+	for _, n := range m.notifications.items {
+		icon, style := notificationStyle(n.level)
+		toasts = append(toasts, style.Render(icon+" "+n.text))
+	}
+	stack := lipgloss.JoinVertical(lipgloss.Right, toasts...)
+
+	width := m.width - 2 // Account for the app padding
+	if width < lipgloss.Width(stack) {
+		return stack
+	}
+	return lipgloss.PlaceHorizontal(width, lipgloss.Right, stack)
+}
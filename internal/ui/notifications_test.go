@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNotificationQueue(t *testing.T) {
+	var q notificationQueue
+	first := q.push(NotifyInfo, "first")
+	second := q.push(NotifyError, "second")
+
+	if len(q.items) != 2 || q.items[0].text != "first" || q.items[1].level != NotifyError {
+		t.Fatalf("Unexpected queue: %+v", q.items)
+	}
+	if first.id == second.id {
+		t.Error("Expected distinct notification ids")
+	}
+
+	q.expire(first.id)
+	if len(q.items) != 1 || q.items[0].id != second.id {
+		t.Errorf("Expected only the second notification left, got %+v", q.items)
+	}
+
+	// Expiring an unknown id is a no-op
+	q.expire(first.id)
+	if len(q.items) != 1 {
+		t.Errorf("Expected queue to be unchanged, got %+v", q.items)
+	}
+}
+
+func TestNotificationQueueCap(t *testing.T) {
+	var q notificationQueue
+	for i := 0; i < maxNotifications+2; i++ {
+		q.push(NotifyInfo, strings.Repeat("x", i+1))
+	}
+	if len(q.items) != maxNotifications {
+		t.Fatalf("Expected %d notifications, got %d", maxNotifications, len(q.items))
+	}
+	if q.items[0].text != "xxx" {
+		t.Errorf("Expected the oldest notifications to be dropped, first is %q", q.items[0].text)
+	}
+}
+
+func TestNotificationDuration(t *testing.T) {
+	m := Model{appConfig: &config.AppConfig{NotificationDuration: 7}}
+	if got := m.notificationDuration(); got != 7*time.Second {
+		t.Errorf("notificationDuration() = %v, want 7s", got)
+	}
+
+	m = Model{}
+	if got := m.notificationDuration(); got != config.DefaultNotificationDuration*time.Second {
+		t.Errorf("notificationDuration() without config = %v, want default", got)
+	}
+}
+
+func TestNotificationExpiry(t *testing.T) {
+	start := time.Now()
+	msg := expireNotificationAfter(4, 20*time.Millisecond)()
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Notification expired after %v, before the configured duration", elapsed)
+	}
+	expire, ok := msg.(expireNotificationMsg)
+	if !ok || expire.id != 4 {
+		t.Fatalf("Expected expireNotificationMsg for notification 4, got %#v", msg)
+	}
+
+	// Each toast expires on its own
+	m := Model{}
+	m.pushNotification(NotifyInfo, "first")
+	m.pushNotification(NotifySuccess, "second")
+
+	updated, _ := m.Update(expireNotificationMsg{id: 1})
+	m = updated.(Model)
+	if len(m.notifications.items) != 1 || m.notifications.items[0].text != "second" {
+		t.Errorf("Expected only the second notification left, got %+v", m.notifications.items)
+	}
+}
+
+func TestNotifyMsgQueuesNotification(t *testing.T) {
+	m := Model{}
+	updated, cmd := m.Update(notify(NotifyWarn, "disk almost full")())
+	m = updated.(Model)
+
+	if len(m.notifications.items) != 1 || m.notifications.items[0].level != NotifyWarn {
+		t.Fatalf("Expected a queued warning, got %+v", m.notifications.items)
+	}
+	if cmd == nil {
+		t.Error("Expected an expiry command for the new notification")
+	}
+}
+
+func TestKeypressDismissesNotifications(t *testing.T) {
+	m := Model{viewMode: ViewList, appConfig: &config.AppConfig{KeyBindings: config.GetDefaultKeyBindings()}}
+	m.showError("something failed")
+	m.pushNotification(NotifyInfo, "note")
+
+	updated, cmd := m.handleListViewKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if len(m.notifications.items) != 0 {
+		t.Error("Expected Esc to dismiss the notifications")
+	}
+	if cmd != nil {
+		t.Error("Expected Esc to only dismiss the notifications, not quit")
+	}
+}
+
+func TestNotificationsRenderWithoutStdout(t *testing.T) {
+	m := Model{width: 100, styles: NewStyles(100)}
+
+	output := captureStdout(t, func() {
+		m.showError("copy failed")
+		m.pushNotification(NotifySuccess, "copied web1")
+		_ = m.renderNotifications()
+	})
+	if output != "" {
+		t.Errorf("Expected nothing written to stdout, got %q", output)
+	}
+
+	rendered := m.renderNotifications()
+	errorAt := strings.Index(rendered, "copy failed")
+	successAt := strings.Index(rendered, "copied web1")
+	if errorAt < 0 || successAt < 0 || errorAt > successAt {
+		t.Errorf("Expected both notifications stacked oldest first, got:\n%s", rendered)
+	}
+}
+
+// captureStdout returns everything written to os.Stdout while fn runs
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	fn()
+
+	w.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/connectivity"
+)
+
+func TestPingAllStreamsResults(t *testing.T) {
+	// A port nothing listens on, so every ping fails fast
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	m := createTestModel()
+	m.hosts = []config.SSHHost{
+		{Name: "server1", Hostname: "127.0.0.1", Port: port},
+		{Name: "server2", Hostname: "127.0.0.1", Port: port},
+	}
+	m.filteredHosts = m.hosts
+	m.appConfig = &config.AppConfig{PingConcurrency: 1}
+	m.pingManager = connectivity.NewPingManager(time.Second)
+
+	cmd := m.startPingAllCmd()
+	if !strings.Contains(m.View(), "checked 0/2") {
+		t.Fatalf("Expected the ping counter, got:\n%s", m.View())
+	}
+
+	for i := 1; i <= 2; i++ {
+		updated, next := m.Update(cmd())
+		m = updated.(Model)
+		cmd = next
+		if want := fmt.Sprintf("checked %d/2", i); !strings.Contains(m.View(), want) {
+			t.Fatalf("Expected %q after %d result(s), got:\n%s", want, i, m.View())
+		}
+	}
+
+	if !strings.Contains(m.View(), "(0 up, 2 down)") {
+		t.Errorf("Expected the running tally, got:\n%s", m.View())
+	}
+
+	updated, notifyCmd := m.Update(cmd())
+	m = updated.(Model)
+	if m.pingInProgress() || strings.Contains(m.View(), "Pinging hosts") {
+		t.Error("Expected the ping counter to be gone once every host was checked")
+	}
+	if notifyCmd == nil || !strings.Contains(m.View(), "Ping done: 0 up, 2 down") {
+		t.Errorf("Expected a summary toast, got:\n%s", m.View())
+	}
+	if status := m.pingManager.GetStatus("server1"); status != connectivity.StatusOffline {
+		t.Errorf("Expected server1 to be offline, got %v", status)
+	}
+}
+
+func TestPingTally(t *testing.T) {
+	var tally pingTally
+	stream := []*connectivity.HostPingResult{
+		{HostName: "web1", Status: connectivity.StatusOnline},
+		{HostName: "web2", Status: connectivity.StatusOffline},
+		{HostName: "db1", Status: connectivity.StatusOnline},
+		{HostName: "db2", Status: connectivity.StatusUnknown},
+		nil,
+	}
+	want := []string{
+		"1 up, 0 down",
+		"1 up, 1 down",
+		"2 up, 1 down",
+		"2 up, 1 down, 1 unknown",
+		"2 up, 1 down, 2 unknown",
+	}
+	for i, result := range stream {
+		tally.add(result)
+		if got := tally.String(); got != want[i] {
+			t.Errorf("After %d result(s): %q, want %q", i+1, got, want[i])
+		}
+	}
+
+	// A new run starts from zero
+	m := createTestModel()
+	m.pingManager = connectivity.NewPingManager(time.Second)
+	m.pingTally = tally
+	m.startPingAllCmd()
+	defer m.pingCancel()
+	if m.pingTally != (pingTally{}) {
+		t.Errorf("Expected the tally to be reset, got %+v", m.pingTally)
+	}
+}
+
+func TestPingAllIgnoresReplacedRun(t *testing.T) {
+	m := createTestModel()
+	m.pingRun = 2
+	m.pingTotal = 5
+
+	updated, cmd := m.Update(pingResultMsg{run: 1, result: &connectivity.HostPingResult{HostName: "server1"}})
+	m = updated.(Model)
+	if m.pingDone != 0 || cmd != nil {
+		t.Error("Expected a result of a replaced run to be ignored")
+	}
+}
+
+func TestPingAllSkipsCachedHosts(t *testing.T) {
+	m := createTestModel()
+	m.pingManager = connectivity.NewPingManager(time.Second)
+	for _, host := range m.hosts {
+		m.pingManager.PingHost(context.Background(), config.SSHHost{Name: host.Name, Hostname: "127.0.0.1", Port: "1"})
+	}
+
+	cmd := m.startPingAllCmd()
+	if m.pingInProgress() {
+		t.Error("Expected no ping run while every result is cached")
+	}
+	if msg, ok := cmd().(notifyMsg); !ok || !strings.Contains(msg.text, "checked less than 30s ago") {
+		t.Errorf("Expected a notification that nothing is stale, got %#v", msg)
+	}
+}
+
+func TestFormatPingResult(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		result *connectivity.HostPingResult
+		want   string
+	}{
+		{nil, "Not checked"},
+		{&connectivity.HostPingResult{Status: connectivity.StatusConnecting}, "connecting..."},
+		{&connectivity.HostPingResult{Status: connectivity.StatusOnline, CheckedAt: now.Add(-12500 * time.Millisecond)}, "online (checked 12s ago)"},
+		{&connectivity.HostPingResult{Status: connectivity.StatusOffline, CheckedAt: now.Add(-2 * time.Minute)}, "offline (checked 2m0s ago)"},
+	}
+	for _, tt := range tests {
+		if got := formatPingResult(tt.result, now); got != tt.want {
+			t.Errorf("formatPingResult() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/Gu1llaum-3/sshm/internal/connectivity"
 	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -35,8 +37,10 @@ type portForwardModel struct {
 
 // portForwardSubmitMsg is sent when the port forward form is submitted
 type portForwardSubmitMsg struct {
-	err     error
-	sshArgs []string
+	err        error
+	sshArgs    []string
+	background bool   // Start the tunnel detached and return to the list
+	forward    string // Forwarding option, such as "-L 8080:localhost:80"
 }
 
 // portForwardCancelMsg is sent when the port forward form is cancelled
@@ -121,9 +125,12 @@ func (m *portForwardModel) Update(msg tea.Msg) (*portForwardModel, tea.Cmd) {
 				return m, textinput.Blink
 			} else {
 				// Submit form
-				return m, m.submitForm()
+				return m, m.submitForm(false)
 			}
 
+		case "ctrl+b":
+			return m, m.submitForm(true)
+
 		case "shift+tab", "up":
 			prevField := m.getPrevValidField(m.focused)
 			if prevField != -1 {
@@ -340,13 +347,22 @@ func (m *portForwardModel) View() string {
 	}
 	fields = append(fields, bindLabel)
 	fields = append(fields, m.inputs[pfBindAddressInput].View())
+	if bind := strings.TrimSpace(m.inputs[pfBindAddressInput].Value()); bindsAllInterfaces(bind) {
+		warning := "⚠ Listens on every interface: other machines can connect"
+		if m.forwardType == RemoteForward {
+			warning += " (needs GatewayPorts on the server)"
+		}
+		fields = append(fields, m.styles.HelpText.Render(warning))
+	} else if err := validateBindAddress(bind); err != nil {
+		fields = append(fields, m.styles.Error.Render("Use an IP address, localhost or *"))
+	}
 
 	// Join form fields
 	formContent := lipgloss.JoinVertical(lipgloss.Left, fields...)
 	sections = append(sections, formContent)
 
 	// Help text
-	helpText := " Tab/↓: next field • Shift+Tab/↑: previous field • Enter: connect • Esc: cancel"
+	helpText := " Tab/↓: next field • Shift+Tab/↑: previous field • Enter: connect • Ctrl+B: run in background • Esc: cancel"
 	sections = append(sections, m.styles.HelpText.Render(helpText))
 
 	// Join all sections
@@ -362,7 +378,7 @@ func (m *portForwardModel) View() string {
 	)
 }
 
-func (m *portForwardModel) submitForm() tea.Cmd {
+func (m *portForwardModel) submitForm(background bool) tea.Cmd {
 	return func() tea.Msg {
 		// Validate inputs
 		localPort := strings.TrimSpace(m.inputs[pfLocalPortInput].Value())
@@ -371,7 +387,8 @@ func (m *portForwardModel) submitForm() tea.Cmd {
 		}
 
 		// Validate port number
-		if _, err := strconv.Atoi(localPort); err != nil {
+		port, err := strconv.Atoi(localPort)
+		if err != nil {
 			return portForwardSubmitMsg{err: fmt.Errorf("invalid port number"), sshArgs: nil}
 		}
 
@@ -379,6 +396,17 @@ func (m *portForwardModel) submitForm() tea.Cmd {
 		remoteHost := strings.TrimSpace(m.inputs[pfRemoteHostInput].Value())
 		remotePort := strings.TrimSpace(m.inputs[pfRemotePortInput].Value())
 		bindAddress := strings.TrimSpace(m.inputs[pfBindAddressInput].Value())
+		if err := validateBindAddress(bindAddress); err != nil {
+			return portForwardSubmitMsg{err: err, sshArgs: nil}
+		}
+
+		// Local and dynamic forwards listen here: a busy port would leave a
+		// dead tunnel without a word
+		if m.forwardType != RemoteForward {
+			if err := connectivity.CheckLocalPort(bindAddress, port); err != nil {
+				return portForwardSubmitMsg{err: err, sshArgs: nil}
+			}
+		}
 
 		// Build SSH command with port forwarding
 		var sshArgs []string
@@ -405,14 +433,7 @@ func (m *portForwardModel) submitForm() tea.Cmd {
 				return portForwardSubmitMsg{err: fmt.Errorf("invalid remote port number"), sshArgs: nil}
 			}
 
-			// Build -L argument
-			var forwardArg string
-			if bindAddress != "" {
-				forwardArg = fmt.Sprintf("%s:%s:%s:%s", bindAddress, localPort, remoteHost, remotePort)
-			} else {
-				forwardArg = fmt.Sprintf("%s:%s:%s", localPort, remoteHost, remotePort)
-			}
-			sshArgs = append(sshArgs, "-L", forwardArg)
+			sshArgs = append(sshArgs, "-L", buildForwardArg(bindAddress, localPort, remoteHost, remotePort))
 
 		case RemoteForward:
 			forwardTypeStr = "remote"
@@ -428,25 +449,12 @@ func (m *portForwardModel) submitForm() tea.Cmd {
 				return portForwardSubmitMsg{err: fmt.Errorf("invalid local port number"), sshArgs: nil}
 			}
 
-			// Build -R argument (note: localPort is actually the remote port in this context)
-			var forwardArg string
-			if bindAddress != "" {
-				forwardArg = fmt.Sprintf("%s:%s:%s:%s", bindAddress, localPort, remoteHost, remotePort)
-			} else {
-				forwardArg = fmt.Sprintf("%s:%s:%s", localPort, remoteHost, remotePort)
-			}
-			sshArgs = append(sshArgs, "-R", forwardArg)
+			// Note: localPort is actually the remote port in this context
+			sshArgs = append(sshArgs, "-R", buildForwardArg(bindAddress, localPort, remoteHost, remotePort))
 
 		case DynamicForward:
 			forwardTypeStr = "dynamic"
-			// Build -D argument
-			var forwardArg string
-			if bindAddress != "" {
-				forwardArg = fmt.Sprintf("%s:%s", bindAddress, localPort)
-			} else {
-				forwardArg = localPort
-			}
-			sshArgs = append(sshArgs, "-D", forwardArg)
+			sshArgs = append(sshArgs, "-D", buildForwardArg(bindAddress, localPort, "", ""))
 		}
 
 		// Save port forwarding configuration to history
@@ -464,12 +472,60 @@ func (m *portForwardModel) submitForm() tea.Cmd {
 			}
 		}
 
+		// The forwarding option, before the hostname is added
+		forward := strings.Join(sshArgs[len(sshArgs)-2:], " ")
+
 		// Add hostname
 		sshArgs = append(sshArgs, m.hostName)
 
 		// Return success with the SSH command to execute
-		return portForwardSubmitMsg{err: nil, sshArgs: sshArgs}
+		return portForwardSubmitMsg{err: nil, sshArgs: sshArgs, background: background, forward: forward}
+	}
+}
+
+// validateBindAddress checks the address a forward listens on: an IP
+// address, bracketed or not for IPv6, localhost, or * for every interface.
+// Empty keeps ssh's default.
+func validateBindAddress(addr string) error {
+	if addr == "" || addr == "*" || addr == "localhost" {
+		return nil
+	}
+	if validation.ValidateIP(strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")) {
+		return nil
+	}
+	return fmt.Errorf("invalid bind address %q: use an IP address, localhost or * for all interfaces", addr)
+}
+
+// bindsAllInterfaces reports whether a bind address exposes the forward to other machines
+func bindsAllInterfaces(addr string) bool {
+	switch strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]") {
+	case "*", "0.0.0.0", "::":
+		return true
+	}
+	return false
+}
+
+// forwardAddress writes a host of a forward spec, bracketing IPv6 addresses
+// so their colons are not taken for separators
+func forwardAddress(host string) string {
+	if strings.Contains(host, ":") && !strings.HasPrefix(host, "[") {
+		return "[" + host + "]"
+	}
+	return host
+}
+
+// buildForwardArg assembles the value of -L or -R,
+// [bind_address:]port:host:hostport, or of -D, [bind_address:]port, when
+// host is empty
+func buildForwardArg(bindAddress, port, host, hostPort string) string {
+	arg := port
+	if host != "" {
+		arg += ":" + forwardAddress(host) + ":" + hostPort
+	}
+	if bindAddress != "" {
+		arg = forwardAddress(bindAddress) + ":" + arg
 	}
+	return arg
 }
 
 // getValidFields returns the list of valid field indices for the current forward type
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBuildForwardArg(t *testing.T) {
+	tests := []struct {
+		name                             string
+		bind, port, host, hostPort, want string
+	}{
+		{"local without bind address", "", "8080", "localhost", "80", "8080:localhost:80"},
+		{"loopback bind address", "127.0.0.1", "8080", "db.internal", "5432", "127.0.0.1:8080:db.internal:5432"},
+		{"all interfaces", "*", "8080", "localhost", "80", "*:8080:localhost:80"},
+		{"IPv4 any", "0.0.0.0", "8080", "localhost", "80", "0.0.0.0:8080:localhost:80"},
+		{"IPv6 bind address", "::1", "8080", "localhost", "80", "[::1]:8080:localhost:80"},
+		{"bracketed IPv6 bind address", "[::1]", "8080", "localhost", "80", "[::1]:8080:localhost:80"},
+		{"IPv6 destination", "", "8080", "fd00::10", "80", "8080:[fd00::10]:80"},
+		{"dynamic", "", "1080", "", "", "1080"},
+		{"dynamic with bind address", "192.168.1.5", "1080", "", "", "192.168.1.5:1080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildForwardArg(tt.bind, tt.port, tt.host, tt.hostPort); got != tt.want {
+				t.Errorf("buildForwardArg() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBindAddress(t *testing.T) {
+	for _, addr := range []string{"", "*", "localhost", "127.0.0.1", "0.0.0.0", "::1", "[::1]", "fe80::1"} {
+		if err := validateBindAddress(addr); err != nil {
+			t.Errorf("validateBindAddress(%q) = %v, want nil", addr, err)
+		}
+	}
+	for _, addr := range []string{"example.com", "127.0.0.1:22", "300.1.1.1", "**"} {
+		if err := validateBindAddress(addr); err == nil {
+			t.Errorf("validateBindAddress(%q) = nil, want an error", addr)
+		}
+	}
+}
+
+func TestPortForwardFormBindAddress(t *testing.T) {
+	m := NewPortForwardForm("web", NewStyles(100), 100, 40, "", nil)
+	m.inputs[pfLocalPortInput].SetValue("8080")
+	m.inputs[pfRemotePortInput].SetValue("80")
+
+	m.inputs[pfBindAddressInput].SetValue(" * ")
+	msg := m.submitForm(false)().(portForwardSubmitMsg)
+	if want := []string{"-L", "*:8080:localhost:80", "web"}; msg.err != nil || !reflect.DeepEqual(msg.sshArgs, want) {
+		t.Errorf("sshArgs = %q (err %v), want %q", msg.sshArgs, msg.err, want)
+	}
+	if !strings.Contains(m.View(), "Listens on every interface") {
+		t.Error("Expected a warning when binding every interface")
+	}
+
+	m.forwardType = RemoteForward
+	m.inputs[pfBindAddressInput].SetValue("::1")
+	msg = m.submitForm(false)().(portForwardSubmitMsg)
+	if want := []string{"-R", "[::1]:8080:localhost:80", "web"}; msg.err != nil || !reflect.DeepEqual(msg.sshArgs, want) {
+		t.Errorf("sshArgs = %q (err %v), want %q", msg.sshArgs, msg.err, want)
+	}
+
+	m.inputs[pfBindAddressInput].SetValue("my-laptop")
+	if msg := m.submitForm(false)().(portForwardSubmitMsg); msg.err == nil || msg.sshArgs != nil {
+		t.Errorf("Expected an invalid bind address to be rejected, got %q", msg.sshArgs)
+	}
+}
+
+func TestPortForwardFormLocalPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("Cannot listen on the loopback interface")
+	}
+	defer listener.Close()
+	busy := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	m := NewPortForwardForm("web", NewStyles(100), 100, 40, "", nil)
+	m.inputs[pfLocalPortInput].SetValue(busy)
+	m.inputs[pfRemotePortInput].SetValue("80")
+
+	for _, forwardType := range []PortForwardType{LocalForward, DynamicForward} {
+		m.forwardType = forwardType
+		for _, background := range []bool{false, true} {
+			msg := m.submitForm(background)().(portForwardSubmitMsg)
+			if msg.err == nil || msg.err.Error() != "local port "+busy+" already in use" || msg.sshArgs != nil {
+				t.Errorf("%s (background %v): expected the busy port to be reported, got %v", forwardType, background, msg.err)
+			}
+		}
+	}
+
+	// The port is checked on the address the forward binds
+	m.forwardType = LocalForward
+	m.inputs[pfBindAddressInput].SetValue("127.0.0.2")
+	if msg := m.submitForm(false)().(portForwardSubmitMsg); msg.err != nil {
+		t.Errorf("Expected the port to be free on 127.0.0.2, got %v", msg.err)
+	}
+	m.inputs[pfBindAddressInput].SetValue("127.0.0.1")
+	if msg := m.submitForm(false)().(portForwardSubmitMsg); msg.err == nil || msg.err.Error() != "local port 127.0.0.1:"+busy+" already in use" {
+		t.Errorf("Expected the busy port to be reported with its address, got %v", msg.err)
+	}
+	m.inputs[pfBindAddressInput].SetValue("")
+
+	// A remote forward listens on the server, the local port doesn't matter
+	m.forwardType = RemoteForward
+	msg := m.submitForm(true)().(portForwardSubmitMsg)
+	if msg.err != nil || !msg.background || msg.forward != "-R "+busy+":localhost:80" {
+		t.Errorf("Expected a background remote forward, got %+v", msg)
+	}
+}
+
+func TestSOCKSProxyKeyPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:1080")
+	if err != nil {
+		t.Skip("Port 1080 is not available to the test")
+	}
+	defer listener.Close()
+
+	m := createTestModel()
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	if cmd == nil {
+		t.Fatal("Expected P to start a proxy")
+	}
+	if msg, ok := cmd().(notifyMsg); !ok || msg.level != NotifyError || msg.text != "local port 1080 already in use" {
+		t.Errorf("Expected the busy port to be reported, got %#v", msg)
+	}
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// altScreenDisabled is set by the --no-altscreen flag and overrides the app config
+var altScreenDisabled bool
+
+// DisableAltScreen makes every TUI run in the normal terminal buffer instead of
+// the alternate screen, so the terminal's scrollback is kept
+func DisableAltScreen() {
+	altScreenDisabled = true
+}
+
+// useAltScreen reports whether TUI programs should take over the alternate screen
+func useAltScreen() bool {
+	if altScreenDisabled {
+		return false
+	}
+	appConfig, err := config.LoadAppConfig()
+	return err != nil || appConfig == nil || !appConfig.NoAltScreen
+}
+
+// programOptions returns the options every TUI program runs with, followed by extra
+func programOptions(altScreen bool, extra ...tea.ProgramOption) []tea.ProgramOption {
+	var opts []tea.ProgramOption
+	if altScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	return append(opts, extra...)
+}
+
+// newProgram creates a TUI program, in the alternate screen unless disabled
+func newProgram(model tea.Model, extra ...tea.ProgramOption) *tea.Program {
+	return tea.NewProgram(model, programOptions(useAltScreen(), extra...)...)
+}
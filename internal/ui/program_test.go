@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+func TestUseAltScreen(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	defer func() { altScreenDisabled = false }()
+
+	if !useAltScreen() {
+		t.Error("Expected the alternate screen by default")
+	}
+
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.NoAltScreen = true
+	if err := config.SaveAppConfig(&appConfig); err != nil {
+		t.Fatalf("SaveAppConfig() error = %v", err)
+	}
+	if useAltScreen() {
+		t.Error("Expected no_alt_screen in the config to disable the alternate screen")
+	}
+
+	appConfig.NoAltScreen = false
+	if err := config.SaveAppConfig(&appConfig); err != nil {
+		t.Fatalf("SaveAppConfig() error = %v", err)
+	}
+	DisableAltScreen()
+	if useAltScreen() {
+		t.Error("Expected --no-altscreen to disable the alternate screen regardless of the config")
+	}
+}
+
+func TestProgramOptions(t *testing.T) {
+	if got := len(programOptions(true)); got != 1 {
+		t.Errorf("Expected only the alt screen option, got %d options", got)
+	}
+	if got := len(programOptions(false)); got != 0 {
+		t.Errorf("Expected no options without the alt screen, got %d", got)
+	}
+	if got := len(programOptions(false, nil)); got != 1 {
+		t.Errorf("Expected extra options to be kept, got %d options", got)
+	}
+}
@@ -3,11 +3,17 @@ package ui
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/history"
 	"github.com/Gu1llaum-3/sshm/internal/transfer"
 
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -16,13 +22,17 @@ import (
 type QuickTransferState int
 
 const (
-	QTStateChooseDirection QuickTransferState = iota
-	QTStateChooseUploadType   // File or Folder selection (only for uploads)
-	QTStateChooseDownloadType // File or Folder selection (for downloads)
+	QTStateChooseDirection    QuickTransferState = iota
+	QTStateChooseUploadType                      // File or Folder selection (only for uploads)
+	QTStateChooseDownloadType                    // File or Folder selection (for downloads)
 	QTStateSelectingLocal
 	QTStateSelectingRemote
 	QTStateTransferring
 	QTStateDone
+	QTStateEditJumpHost    // Typing a one-off jump host, opened from the direction choice
+	QTStateConfirmExisting // The local destination of a download exists: overwrite, rename or skip
+	QTStateConfirmSize     // The transfer is being measured or is larger than the confirm size
+	QTStateCheckingLogin   // Finding out whether the host only takes a password
 )
 
 // quickTransferModel is a streamlined transfer UI
@@ -41,7 +51,27 @@ type quickTransferModel struct {
 	height           int
 	err              string
 	historyManager   *history.HistoryManager
+	scpExtraArgs     []string
+	preferTUIPicker  bool
+	recursiveDefault bool                     // The host defaults to folder transfers
+	startDirs        remoteStartDirs          // Where the remote browser opens
+	backend          transfer.TransferBackend // scp or rsync, the last one used with the host
+	jumpHost         string                   // One-off jump host passed as -J, empty to use the SSH config
+	jumpInput        textinput.Model
+	jumpErr          string
 	runningTransfer  *transfer.RunningTransfer // For cancellation
+	tracker          *transfer.ProgressTracker // Progress of the running transfer
+	progressBar      progress.Model
+	bytesDone        int64
+	bytesTotal       int64 // 0 while the size of the transfer is unknown
+	transferStarted  time.Time
+	pendingRequest   *transfer.TransferRequest    // Download waiting for the overwrite/rename/skip choice
+	existingPath     string                       // Local file the pending download would replace
+	lastChoice       *history.QuickTransferChoice // Direction and type picked last time with the host
+	estimate         sizeEstimate                 // Size of the local upload or remote download
+	confirmSize      int64                        // Transfers larger than this are confirmed first, 0 never
+	sizeConfirmed    bool                         // The transfer goes ahead whatever its size
+	session          *transfer.SFTPSession        // The remote browser's session, reused to measure the transfer
 }
 
 // quickTransferDoneMsg signals transfer complete
@@ -63,28 +93,78 @@ type quickLocalPickedMsg struct {
 type quickRemotePickedMsg struct {
 	path     string
 	selected bool
+	session  *transfer.SFTPSession // The browser's session, handed over when it was kept
 }
 
 // openRemoteBrowserMsg requests the main app to open the remote browser
 type openRemoteBrowserMsg struct {
-	host       string
-	startPath  string
-	configFile string
-	mode       BrowserMode
+	host        string
+	startPath   string
+	configFile  string
+	mode        BrowserMode
+	saveAsName  string // Name offered for the new file, in BrowseSaveAs mode
+	keepSession bool   // Hand the browser's session over with its result
 }
 
 // NewQuickTransfer creates a new quick transfer model
 func NewQuickTransfer(hostName string, styles Styles, width, height int, configFile string) *quickTransferModel {
 	historyManager, _ := history.NewHistoryManager()
-	return &quickTransferModel{
-		state:          QTStateChooseDirection,
-		hostName:       hostName,
-		configFile:     configFile,
-		styles:         styles,
-		width:          width,
-		height:         height,
-		historyManager: historyManager,
+
+	// Load extra scp arguments and picker preference from the app config
+	var scpExtraArgs []string
+	var preferTUIPicker bool
+	if appConfig, err := config.LoadAppConfig(); err == nil {
+		scpExtraArgs = appConfig.ScpExtraArgs
+		preferTUIPicker = appConfig.PreferTUIPicker
+	}
+	m := &quickTransferModel{
+		state:           QTStateChooseDirection,
+		hostName:        hostName,
+		configFile:      configFile,
+		styles:          styles,
+		width:           width,
+		height:          height,
+		historyManager:  historyManager,
+		scpExtraArgs:    scpExtraArgs,
+		preferTUIPicker: preferTUIPicker,
+		confirmSize:     transferConfirmSize(),
+		progressBar:     progress.New(progress.WithDefaultGradient(), progress.WithWidth(40)),
+	}
+
+	if historyManager != nil {
+		m.backend = historyManager.GetTransferBackend(hostName)
+
+		// Preselect the direction picked last time, still changeable
+		if choice, ok := historyManager.GetQuickTransferChoice(hostName); ok {
+			m.lastChoice = &choice
+			if choice.Direction == "download" {
+				m.selectedIdx = 1
+			}
+		}
+	}
+
+	// Hosts can default to folder transfers
+	if hostDefaults, err := config.LoadHostTransferDefaults(hostName); err == nil {
+		m.recursiveDefault = hostDefaults.Recursive
 	}
+	pathStore, _ := history.NewRemotePathStore()
+	m.startDirs = loadRemoteStartDirs(hostName, pathStore)
+	return m
+}
+
+// defaultTypeIdx returns the preselected File/Folder choice for the host:
+// the one picked last time in this direction, else the host default
+func (m *quickTransferModel) defaultTypeIdx() int {
+	if m.lastChoice != nil && m.lastChoice.Direction == strings.ToLower(m.direction.String()) {
+		if m.lastChoice.Folder {
+			return 1 // Folder
+		}
+		return 0 // File
+	}
+	if m.recursiveDefault {
+		return 1 // Folder
+	}
+	return 0 // File
 }
 
 func (m *quickTransferModel) Init() tea.Cmd {
@@ -105,11 +185,15 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 			m.state = QTStateTransferring
 			return m, m.executeTransfer()
 		}
-		// For uploads: local picked, now ask for remote destination
+		// For uploads: local picked, measure it while asking for the remote destination
 		m.state = QTStateSelectingRemote
-		return m, m.openRemotePicker()
+		return m, tea.Batch(m.openRemotePicker(), m.estimateSize())
 
 	case quickRemotePickedMsg:
+		if msg.session != nil {
+			m.close()
+			m.session = msg.session
+		}
 		if !msg.selected {
 			// Cancelled - go back or exit
 			return m, func() tea.Msg { return quickTransferCancelMsg{} }
@@ -117,14 +201,53 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 		m.remotePath = msg.path
 
 		if m.direction == transfer.Download {
-			// For downloads: remote picked, now ask for local destination
+			// For downloads: remote picked, measure it while asking for the local destination
 			m.state = QTStateSelectingLocal
-			return m, m.openLocalPicker()
+			return m, tea.Batch(m.openLocalPicker(), m.estimateSize())
 		}
 		// For uploads: both paths set, execute transfer
 		m.state = QTStateTransferring
 		return m, m.executeTransfer()
 
+	case sizeEstimateMsg:
+		if !m.estimate.apply(msg) || m.state != QTStateConfirmSize {
+			return m, nil
+		}
+		// The transfer was waiting for its size: go ahead unless it is too large
+		if !m.estimate.exceeds(msg.path, m.confirmSize) {
+			m.state = QTStateTransferring
+			return m, m.executeTransfer()
+		}
+		return m, nil
+
+	case transferLoginCheckedMsg:
+		if m.state != QTStateCheckingLogin {
+			return m, nil
+		}
+		if msg.needed {
+			return m, promptTransferPassword(msg.request)
+		}
+		return m, m.runTransfer(msg.request)
+
+	case transferPasswordMsg:
+		if m.state != QTStateCheckingLogin {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			m.state = QTStateDone
+			return m, nil
+		}
+		msg.request.Password = msg.password
+		return m, m.runTransfer(msg.request)
+
+	case transferProgressMsg:
+		if m.state != QTStateTransferring || m.tracker == nil {
+			return m, nil
+		}
+		m.bytesDone, m.bytesTotal = msg.bytesDone, msg.bytesTotal
+		return m, tickTransferProgress(m.tracker)
+
 	case quickTransferDoneMsg:
 		if msg.err != nil {
 			m.err = msg.err.Error()
@@ -153,12 +276,12 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 			switch msg.String() {
 			case "u", "U", "1":
 				m.direction = transfer.Upload
-				m.selectedIdx = 0 // Reset for upload type selection
+				m.selectedIdx = m.defaultTypeIdx() // Reset for upload type selection
 				m.state = QTStateChooseUploadType
 				return m, nil
 			case "d", "D", "2":
 				m.direction = transfer.Download
-				m.selectedIdx = 0 // Reset for download type selection
+				m.selectedIdx = m.defaultTypeIdx() // Reset for download type selection
 				m.state = QTStateChooseDownloadType
 				return m, nil
 			case "left", "h", "up", "k":
@@ -170,15 +293,20 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 			case "tab":
 				m.selectedIdx = (m.selectedIdx + 1) % 2
 				return m, nil
+			case "b":
+				m.toggleBackend()
+				return m, nil
+			case "J":
+				return m, m.editJumpHost()
 			case "enter", " ":
 				if m.selectedIdx == 0 {
 					m.direction = transfer.Upload
-					m.selectedIdx = 0 // Reset for upload type selection
+					m.selectedIdx = m.defaultTypeIdx() // Reset for upload type selection
 					m.state = QTStateChooseUploadType
 					return m, nil
 				} else {
 					m.direction = transfer.Download
-					m.selectedIdx = 0 // Reset for download type selection
+					m.selectedIdx = m.defaultTypeIdx() // Reset for download type selection
 					m.state = QTStateChooseDownloadType
 					return m, nil
 				}
@@ -186,6 +314,27 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 				return m, func() tea.Msg { return quickTransferCancelMsg{} }
 			}
 
+		case QTStateEditJumpHost:
+			switch msg.Type {
+			case tea.KeyEsc:
+				m.state = QTStateChooseDirection
+				return m, nil
+			case tea.KeyEnter:
+				jumpHost := strings.TrimSpace(m.jumpInput.Value())
+				if jumpHost != "" {
+					if err := transfer.ValidateJumpHost(jumpHost, m.configFile); err != nil {
+						m.jumpErr = err.Error()
+						return m, nil
+					}
+				}
+				m.jumpHost = jumpHost
+				m.state = QTStateChooseDirection
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.jumpInput, cmd = m.jumpInput.Update(msg)
+			return m, cmd
+
 		case QTStateChooseUploadType:
 			// Handle escape to go back
 			if msg.Type == tea.KeyEsc {
@@ -275,6 +424,26 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 				return m, func() tea.Msg { return quickTransferCancelMsg{} }
 			}
 
+		case QTStateConfirmExisting:
+			switch msg.String() {
+			case "o", "O":
+				return m, m.resolveExisting(m.pendingRequest, m.existingPath, transfer.OnExistsOverwrite)
+			case "r", "R":
+				return m, m.resolveExisting(m.pendingRequest, m.existingPath, transfer.OnExistsRename)
+			case "s", "S", "esc":
+				return m, m.resolveExisting(m.pendingRequest, m.existingPath, transfer.OnExistsSkip)
+			}
+
+		case QTStateConfirmSize:
+			switch msg.String() {
+			case "enter", "y", "Y":
+				m.sizeConfirmed = true
+				m.state = QTStateTransferring
+				return m, m.executeTransfer()
+			case "esc", "n", "N", "q":
+				return m, func() tea.Msg { return quickTransferCancelMsg{} }
+			}
+
 		case QTStateTransferring:
 			// Transfer in progress - handled at top with ctrl+c
 			break
@@ -288,7 +457,40 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 	return m, nil
 }
 
+// toggleBackend switches between scp and rsync
+func (m *quickTransferModel) toggleBackend() {
+	if m.backend == transfer.BackendRsync {
+		m.backend = transfer.BackendSCP
+	} else {
+		m.backend = transfer.BackendRsync
+	}
+}
+
+// editJumpHost opens the jump host input, starting from the current one
+func (m *quickTransferModel) editJumpHost() tea.Cmd {
+	m.jumpInput = textinput.New()
+	m.jumpInput.Placeholder = "config host or user@host"
+	m.jumpInput.CharLimit = 200
+	m.jumpInput.Width = 40
+	m.jumpInput.SetValue(m.jumpHost)
+	m.jumpInput.Focus()
+	m.jumpErr = ""
+	m.state = QTStateEditJumpHost
+	return textinput.Blink
+}
+
 func (m *quickTransferModel) openLocalPicker() tea.Cmd {
+	// Without a native dialog, ask the main app to open the TUI browser
+	if !transfer.UseNativePicker(m.preferTUIPicker) {
+		mode := BrowseFiles
+		if m.direction == transfer.Download || m.uploadType == UploadFolder {
+			mode = BrowseDirectories
+		}
+		return func() tea.Msg {
+			return openLocalBrowserMsg{mode: mode}
+		}
+	}
+
 	return func() tea.Msg {
 		var mode transfer.PickerMode
 		var title string
@@ -320,8 +522,15 @@ func (m *quickTransferModel) openRemotePicker() tea.Cmd {
 	// Send a message to the main app to open the remote browser
 	// This avoids nested tea.Program issues
 	var mode BrowserMode
+	var saveAsName string
 	if m.direction == transfer.Upload {
-		mode = BrowseDirectories
+		// A file is saved under the name picked, a folder goes into a directory
+		if m.uploadType == UploadFolder {
+			mode = BrowseDirectories
+		} else {
+			mode = BrowseSaveAs
+			saveAsName = filepath.Base(m.localPath)
+		}
 	} else {
 		// Download: use directories mode for folder downloads, files mode for file downloads
 		if m.downloadType == UploadFolder {
@@ -334,14 +543,39 @@ func (m *quickTransferModel) openRemotePicker() tea.Cmd {
 	return func() tea.Msg {
 		return openRemoteBrowserMsg{
 			host:       m.hostName,
-			startPath:  "~",
+			startPath:  m.startDirs.startPath(m.direction),
 			configFile: m.configFile,
 			mode:       mode,
+			saveAsName: saveAsName,
+
+			keepSession: true,
 		}
 	}
 }
 
+// estimateSource returns the path the transfer copies from
+func (m *quickTransferModel) estimateSource() string {
+	if m.direction == transfer.Upload {
+		return m.localPath
+	}
+	return m.remotePath
+}
+
+// estimateSize measures the path the transfer copies from in the background
+func (m *quickTransferModel) estimateSize() tea.Cmd {
+	return startSizeEstimate(&m.estimate, m.session, m.hostName, m.configFile, m.direction, m.estimateSource())
+}
+
 func (m *quickTransferModel) executeTransfer() tea.Cmd {
+	// Transfers larger than the confirm size wait for a confirmation, and
+	// for their size while it is measured
+	if source := m.estimateSource(); !m.sizeConfirmed && m.confirmSize > 0 && m.estimate.path == source {
+		if m.estimate.loading || m.estimate.exceeds(source, m.confirmSize) {
+			m.state = QTStateConfirmSize
+			return nil
+		}
+	}
+
 	localPath := m.localPath
 	recursive := false
 
@@ -355,7 +589,7 @@ func (m *quickTransferModel) executeTransfer() tea.Cmd {
 		// Download: if local path is a directory, append the remote filename/foldername
 		info, err := os.Stat(localPath)
 		if err == nil && info.IsDir() {
-			remoteFilename := filepath.Base(m.remotePath)
+			remoteFilename := path.Base(m.remotePath)
 			localPath = filepath.Join(localPath, remoteFilename)
 		}
 		// Set recursive for folder downloads
@@ -369,16 +603,85 @@ func (m *quickTransferModel) executeTransfer() tea.Cmd {
 		Direction:  m.direction,
 		LocalPath:  localPath,
 		RemotePath: m.remotePath,
+		RemoteFile: m.direction == transfer.Upload && m.uploadType != UploadFolder && !recursive,
 		Recursive:  recursive,
 		ConfigFile: m.configFile,
+		ExtraArgs:  m.scpExtraArgs,
+		JumpHost:   m.jumpHost,
+		Backend:    m.backend,
+
+		Concurrency: transfer.DefaultConcurrency(),
 	}
 
-	// Start the transfer (non-blocking)
-	m.runningTransfer = req.StartTransfer()
+	// Don't clobber an existing local file without the user's say
+	if existing := req.ExistingDestination(); existing != "" {
+		action := transfer.DefaultOnExists()
+		if action == transfer.OnExistsAsk {
+			m.pendingRequest = req
+			m.existingPath = existing
+			m.state = QTStateConfirmExisting
+			return nil
+		}
+		return m.resolveExisting(req, existing, action)
+	}
+	return m.startTransfer(req)
+}
 
-	// Return a command that waits for the transfer to complete
-	return func() tea.Msg {
+// recordChoice remembers the direction and type of this transfer for the
+// next quick transfer with the host
+func (m *quickTransferModel) recordChoice() {
+	if m.historyManager == nil {
+		return
+	}
+	folder := m.uploadType == UploadFolder
+	if m.direction == transfer.Download {
+		folder = m.downloadType == UploadFolder
+	}
+	_ = m.historyManager.RecordQuickTransferChoice(m.hostName, history.QuickTransferChoice{
+		Direction: strings.ToLower(m.direction.String()),
+		Folder:    folder,
+	})
+}
+
+// resolveExisting applies the overwrite/rename/skip choice to a download
+// whose local destination exists
+func (m *quickTransferModel) resolveExisting(req *transfer.TransferRequest, existing string, action transfer.OnExists) tea.Cmd {
+	m.pendingRequest = nil
+	if !req.ApplyOnExists(action) {
+		m.err = fmt.Sprintf("%s already exists, download skipped", existing)
+		m.state = QTStateDone
+		return nil
+	}
+	m.state = QTStateTransferring
+	return m.startTransfer(req)
+}
+
+// startTransfer runs req in the background once it is known whether the
+// host takes a password, which scp could not prompt for behind the TUI
+func (m *quickTransferModel) startTransfer(req *transfer.TransferRequest) tea.Cmd {
+	if m.session != nil && req.JumpHost == "" {
+		// The browser logged in with a key, so scp needs no password either
+		return m.runTransfer(req)
+	}
+	m.state = QTStateCheckingLogin
+	return checkTransferLogin(req)
+}
+
+// runTransfer runs req in the background, following its progress
+func (m *quickTransferModel) runTransfer(req *transfer.TransferRequest) tea.Cmd {
+	m.state = QTStateTransferring
+
+	// Start the transfer (non-blocking), following its progress meter
+	m.tracker = transfer.NewProgressTracker()
+	m.bytesDone, m.bytesTotal = 0, 0
+	m.transferStarted = time.Now()
+	m.runningTransfer = req.StartTransfer(m.tracker)
+
+	// Wait for the transfer to complete while measuring and ticking its progress
+	wait := func() tea.Msg {
 		result := <-m.runningTransfer.Done()
+		recordTransferAttempt(m.historyManager, req, result.Error)
+		m.recordChoice()
 		if !result.Success {
 			return quickTransferDoneMsg{success: false, err: result.Error}
 		}
@@ -390,10 +693,25 @@ func (m *quickTransferModel) executeTransfer() tea.Cmd {
 				direction = "download"
 			}
 			_ = m.historyManager.RecordTransfer(m.hostName, direction, m.localPath, m.remotePath)
+			_ = m.historyManager.RecordTransferBackend(m.hostName, m.backend)
 		}
+		pathStore, _ := history.NewRemotePathStore()
+		recordRemotePath(pathStore, req)
 
 		return quickTransferDoneMsg{success: true}
 	}
+	// The tracker closes the session once the transfer is over
+	session := m.session
+	m.session = nil
+	return tea.Batch(wait, measureTransfer(req, m.tracker, session, m.estimate.measured(m.estimateSource())), tickTransferProgress(m.tracker))
+}
+
+// close closes the remote browser's session when no transfer took it over
+func (m *quickTransferModel) close() {
+	if m.session != nil {
+		m.session.Close()
+		m.session = nil
+	}
 }
 
 func (m *quickTransferModel) View() string {
@@ -402,7 +720,11 @@ func (m *quickTransferModel) View() string {
 	// Title
 	title := m.styles.Header.Render("📁 Quick Transfer")
 	sections = append(sections, title)
-	sections = append(sections, m.styles.HelpText.Render(fmt.Sprintf("Host: %s", m.hostName)))
+	header := fmt.Sprintf("Host: %s • Backend: %s", m.hostName, m.backend)
+	if m.jumpHost != "" {
+		header += " • Jump: " + m.jumpHost
+	}
+	sections = append(sections, m.styles.HelpText.Render(header))
 	sections = append(sections, "")
 
 	if m.err != "" {
@@ -426,7 +748,18 @@ func (m *quickTransferModel) View() string {
 			buttons := lipgloss.JoinHorizontal(lipgloss.Center, uploadBtn, "    ", downloadBtn)
 			sections = append(sections, buttons)
 			sections = append(sections, "")
-			sections = append(sections, m.styles.HelpText.Render("←/→ or Tab: switch • Enter: confirm • Esc: cancel"))
+			sections = append(sections, m.styles.HelpText.Render("←/→ or Tab: switch • b: scp/rsync • J: jump host • Enter: confirm • Esc: cancel"))
+
+		case QTStateEditJumpHost:
+			sections = append(sections, m.styles.Label.Render("Jump host for this transfer:"))
+			sections = append(sections, m.jumpInput.View())
+			sections = append(sections, "")
+			if m.jumpErr != "" {
+				sections = append(sections, m.styles.Error.Render(m.jumpErr))
+				sections = append(sections, "")
+			}
+			sections = append(sections, m.styles.HelpText.Render("Leave empty to use ProxyJump from the SSH config"))
+			sections = append(sections, m.styles.HelpText.Render("Enter: confirm • Esc: back"))
 
 		case QTStateChooseUploadType:
 			sections = append(sections, m.styles.Label.Render("What do you want to upload?"))
@@ -473,6 +806,13 @@ func (m *quickTransferModel) View() string {
 				sections = append(sections, m.styles.Label.Render("Select download destination..."))
 			}
 			sections = append(sections, "")
+			if m.direction == transfer.Download && m.remotePath != "" {
+				sections = append(sections, m.styles.HelpText.Render("Remote: "+m.remotePath))
+				if summary := m.estimate.summary(m.remotePath); summary != "" {
+					sections = append(sections, m.styles.HelpText.Render(summary))
+				}
+				sections = append(sections, "")
+			}
 			loadingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
 			sections = append(sections, loadingStyle.Render("Opening file picker..."))
 
@@ -489,11 +829,18 @@ func (m *quickTransferModel) View() string {
 			sections = append(sections, "")
 			if m.localPath != "" {
 				sections = append(sections, m.styles.HelpText.Render("Local: "+m.localPath))
+				if summary := m.estimate.summary(m.localPath); summary != "" {
+					sections = append(sections, m.styles.HelpText.Render(summary))
+				}
 				sections = append(sections, "")
 			}
 			loadingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
 			sections = append(sections, loadingStyle.Render("Opening remote browser..."))
 
+		case QTStateCheckingLogin:
+			loadingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+			sections = append(sections, loadingStyle.Render("Checking how to log in to "+m.hostName+"..."))
+
 		case QTStateTransferring:
 			direction := "Uploading"
 			if m.direction == transfer.Download {
@@ -508,8 +855,35 @@ func (m *quickTransferModel) View() string {
 			sections = append(sections, m.styles.HelpText.Render("Local: "+m.localPath))
 			sections = append(sections, m.styles.HelpText.Render("Remote: "+m.remotePath))
 			sections = append(sections, "")
-			loadingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
-			sections = append(sections, loadingStyle.Render("Transfer in progress..."))
+			if m.bytesDone > 0 || m.bytesTotal > 0 {
+				sections = append(sections, transferProgressView(m.progressBar, m.styles, m.bytesDone, m.bytesTotal, m.transferStarted))
+			} else {
+				loadingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+				sections = append(sections, loadingStyle.Render("Transfer in progress..."))
+			}
+
+		case QTStateConfirmExisting:
+			sections = append(sections, m.styles.Label.Render("The download destination already exists"))
+			sections = append(sections, "")
+			sections = append(sections, m.styles.HelpText.Render("Local: "+m.existingPath))
+			sections = append(sections, "")
+			sections = append(sections, m.styles.HelpText.Render("o: overwrite • r: rename (keep both) • s/Esc: skip"))
+
+		case QTStateConfirmSize:
+			source := m.estimateSource()
+			if m.estimate.loading {
+				sections = append(sections, m.styles.Label.Render("Measuring the transfer..."))
+			} else {
+				sections = append(sections, m.styles.Label.Render("This transfer is larger than "+transfer.FormatByteSize(m.confirmSize)))
+			}
+			sections = append(sections, "")
+			sections = append(sections, m.styles.HelpText.Render("Local: "+m.localPath))
+			sections = append(sections, m.styles.HelpText.Render("Remote: "+m.remotePath))
+			if summary := m.estimate.summary(source); summary != "" {
+				sections = append(sections, m.styles.HelpText.Render(summary))
+			}
+			sections = append(sections, "")
+			sections = append(sections, m.styles.HelpText.Render("Enter/y: transfer • Esc/n: cancel"))
 
 		case QTStateDone:
 			sections = append(sections, m.styles.Label.Render("✓ Transfer complete!"))
@@ -550,10 +924,27 @@ func (m standaloneQuickTransfer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case quickTransferCancelMsg:
 		return m, tea.Quit
 
+	case openLocalBrowserMsg:
+		// Standalone mode: launch local browser as external program
+		return m, func() tea.Msg {
+			path, selected, err := RunLocalBrowser(msg.startDir, msg.mode)
+			if err != nil || !selected {
+				return quickLocalPickedMsg{selected: false}
+			}
+			return quickLocalPickedMsg{path: path, selected: true}
+		}
+
 	case openRemoteBrowserMsg:
 		// Standalone mode: launch remote browser as external program
 		return m, func() tea.Msg {
-			path, selected, err := RunRemoteBrowser(msg.host, msg.startPath, msg.configFile, msg.mode)
+			var path string
+			var selected bool
+			var err error
+			if msg.mode == BrowseSaveAs {
+				path, selected, err = RunRemoteSaveAs(msg.host, msg.startPath, msg.configFile, msg.saveAsName)
+			} else {
+				path, selected, err = RunRemoteBrowser(msg.host, msg.startPath, msg.configFile, msg.mode)
+			}
 			if err != nil || !selected {
 				return quickRemotePickedMsg{selected: false}
 			}
@@ -576,7 +967,7 @@ func RunQuickTransfer(hostName, configFile string) error {
 	qt := NewQuickTransfer(hostName, styles, 80, 24, configFile)
 	m := standaloneQuickTransfer{qt}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := newProgram(m)
 	_, err := p.Run()
 	return err
 }
@@ -0,0 +1,331 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestQuickTransferRemembersBackend(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordTransferBackend("web", transfer.BackendRsync); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	if m.backend != transfer.BackendRsync {
+		t.Fatalf("Expected the last backend used with web, got %v", m.backend)
+	}
+	if !strings.Contains(m.View(), "Backend: rsync") {
+		t.Error("Expected the backend to be shown")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if m.backend != transfer.BackendSCP || m.state != QTStateChooseDirection {
+		t.Errorf("Expected b to switch to scp, got %v in state %v", m.backend, m.state)
+	}
+
+	if other := NewQuickTransfer("db", NewStyles(80), 80, 24, ""); other.backend != transfer.BackendSCP {
+		t.Errorf("Expected scp for a host without history, got %v", other.backend)
+	}
+}
+
+func TestQuickTransferRestoresLastChoices(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordQuickTransferChoice("web", history.QuickTransferChoice{Direction: "download", Folder: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	if m.selectedIdx != 1 {
+		t.Fatalf("Expected download to be preselected, got %d", m.selectedIdx)
+	}
+
+	// Enter twice repeats the last download of a folder
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != QTStateChooseDownloadType || m.selectedIdx != 1 {
+		t.Fatalf("Expected folder to be preselected for downloads, got state %v and %d", m.state, m.selectedIdx)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.downloadType != UploadFolder {
+		t.Error("Expected the folder download to be chosen")
+	}
+
+	// The choices can still be changed; uploads fall back to file
+	m = NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if m.state != QTStateChooseUploadType || m.selectedIdx != 0 {
+		t.Errorf("Expected file to be preselected for uploads, got state %v and %d", m.state, m.selectedIdx)
+	}
+
+	// Hosts without recorded choices start on upload
+	if other := NewQuickTransfer("db", NewStyles(80), 80, 24, ""); other.selectedIdx != 0 || other.lastChoice != nil {
+		t.Errorf("Expected no preselection for a host without history, got %d", other.selectedIdx)
+	}
+}
+
+func TestQuickTransferRecordsChoices(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	m := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	m.direction = transfer.Upload
+	m.uploadType = UploadFolder
+	m.recordChoice()
+
+	restored := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	if restored.lastChoice == nil || restored.lastChoice.Direction != "upload" || !restored.lastChoice.Folder {
+		t.Fatalf("Expected the folder upload to be remembered, got %+v", restored.lastChoice)
+	}
+	restored.direction = transfer.Upload
+	if restored.defaultTypeIdx() != 1 {
+		t.Error("Expected folder to be preselected for the next upload")
+	}
+}
+
+func TestQuickTransferRemoteStartPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	startPath := func(host string, direction transfer.Direction) string {
+		m := NewQuickTransfer(host, NewStyles(80), 80, 24, "")
+		m.direction = direction
+		msg, ok := m.openRemotePicker()().(openRemoteBrowserMsg)
+		if !ok {
+			t.Fatal("Expected the remote browser to be opened")
+		}
+		return msg.startPath
+	}
+
+	// Nothing known yet: the home directory
+	if got := startPath("web", transfer.Upload); got != "~" {
+		t.Errorf("Expected uploads to start in ~, got %q", got)
+	}
+	if got := startPath("web", transfer.Download); got != "~" {
+		t.Errorf("Expected downloads to start in ~, got %q", got)
+	}
+
+	// Downloads start where the host was browsed last
+	pathStore, err := history.NewRemotePathStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = pathStore.Record("web", "/var/log/nginx")
+	if got := startPath("web", transfer.Download); got != "/var/log/nginx" {
+		t.Errorf("Expected downloads to start in the last directory, got %q", got)
+	}
+	if got := startPath("web", transfer.Upload); got != "~" {
+		t.Errorf("Expected uploads to ignore the last directory, got %q", got)
+	}
+
+	// Uploads start in the configured directory, the host's own first
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.RemoteUploadDir = "/srv/incoming"
+	if err := config.SaveAppConfig(&appConfig); err != nil {
+		t.Fatal(err)
+	}
+	defaultsPath, _ := config.GetTransferDefaultsPath()
+	if err := os.WriteFile(defaultsPath, []byte(`{"hosts": {"web": {"upload_dir": "/var/www"}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := startPath("web", transfer.Upload); got != "/var/www" {
+		t.Errorf("Expected web uploads to start in its upload directory, got %q", got)
+	}
+	if got := startPath("db", transfer.Upload); got != "/srv/incoming" {
+		t.Errorf("Expected db uploads to start in the global upload directory, got %q", got)
+	}
+	if got := startPath("db", transfer.Download); got != "~" {
+		t.Errorf("Expected db downloads to start in ~, got %q", got)
+	}
+
+	// The transfer form opens the browser in the same places
+	form := NewTransferForm("web", NewStyles(80), 80, 24, "", transfer.Download)
+	if got := form.startDirs.startPath(form.direction); got != "/var/log/nginx" {
+		t.Errorf("Expected the transfer form to start downloads in the last directory, got %q", got)
+	}
+}
+
+func TestQuickTransferUploadDestinationMode(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	m := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	m.direction = transfer.Upload
+	m.localPath = filepath.Join(dir, "report.pdf")
+	msg := m.openRemotePicker()().(openRemoteBrowserMsg)
+	if msg.mode != BrowseSaveAs || msg.saveAsName != "report.pdf" {
+		t.Errorf("Expected a file upload to be saved as report.pdf, got mode %v name %q", msg.mode, msg.saveAsName)
+	}
+
+	m.uploadType = UploadFolder
+	m.localPath = dir
+	msg = m.openRemotePicker()().(openRemoteBrowserMsg)
+	if msg.mode != BrowseDirectories || msg.saveAsName != "" {
+		t.Errorf("Expected a folder upload to pick a directory, got mode %v name %q", msg.mode, msg.saveAsName)
+	}
+}
+
+func TestQuickTransferJumpHost(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	m := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")})
+	if m.state != QTStateEditJumpHost {
+		t.Fatalf("Expected J to open the jump host input, got state %v", m.state)
+	}
+
+	m.jumpInput.SetValue("@bastion")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != QTStateEditJumpHost || m.jumpErr == "" {
+		t.Fatal("Expected an invalid jump host to be rejected")
+	}
+
+	m.jumpInput.SetValue("admin@bastion.example.com")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != QTStateChooseDirection || m.jumpHost != "admin@bastion.example.com" {
+		t.Fatalf("Expected the jump host to be kept, got %q in state %v", m.jumpHost, m.state)
+	}
+	if !strings.Contains(m.View(), "Jump: admin@bastion.example.com") {
+		t.Error("Expected the jump host to be shown")
+	}
+}
+
+func TestQuickTransferProgress(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	m := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	m.state = QTStateTransferring
+	m.tracker = transfer.NewProgressTracker()
+	m.transferStarted = time.Now().Add(-10 * time.Second)
+	if !strings.Contains(m.View(), "Transfer in progress...") {
+		t.Error("Expected no progress before the first tick")
+	}
+
+	m, cmd := m.Update(transferProgressMsg{bytesDone: 2 << 20, bytesTotal: 8 << 20})
+	if cmd == nil {
+		t.Error("Expected the next tick to be scheduled")
+	}
+	if view := m.View(); !strings.Contains(view, "2.0MB of 8.0MB") || !strings.Contains(view, "ETA 00:30") {
+		t.Errorf("Expected the progress with an ETA, got %q", view)
+	}
+
+	// Directory transfers of unknown size show what went through
+	m, _ = m.Update(transferProgressMsg{bytesDone: 4 << 20})
+	if view := m.View(); !strings.Contains(view, "4.0MB transferred") {
+		t.Errorf("Expected the bytes transferred, got %q", view)
+	}
+
+	m.state = QTStateDone
+	if _, cmd := m.Update(transferProgressMsg{bytesDone: 8 << 20}); cmd != nil {
+		t.Error("Expected ticks to stop once the transfer is done")
+	}
+}
+
+func TestFormatTransferDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		42 * time.Second:             "00:42",
+		12*time.Minute + time.Second: "12:01",
+		2*time.Hour + 3*time.Minute:  "2:03:00",
+	} {
+		if got := formatTransferDuration(d); got != want {
+			t.Errorf("formatTransferDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestQuickTransferConfirmsExistingDownload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	if err := os.WriteFile(filepath.Join(dir, "app.log"), []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	m.direction = transfer.Download
+	m.remotePath = "/var/log/app.log"
+	m, cmd := m.Update(quickLocalPickedMsg{path: dir, selected: true})
+	if m.state != QTStateConfirmExisting || cmd != nil {
+		t.Fatalf("Expected to ask before overwriting, got state %v", m.state)
+	}
+	if !strings.Contains(m.View(), filepath.Join(dir, "app.log")) {
+		t.Error("Expected the existing file to be shown")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if m.state != QTStateDone || !strings.Contains(m.err, "download skipped") || m.runningTransfer != nil {
+		t.Errorf("Expected the download to be skipped, got state %v, err %q", m.state, m.err)
+	}
+}
+
+func TestQuickTransferConfirmsLargeDownload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	m := NewQuickTransfer("web", NewStyles(80), 80, 24, "")
+	m.direction = transfer.Download
+	m.state = QTStateSelectingRemote
+
+	// The remote folder is measured while the destination is picked
+	m, _ = m.Update(quickRemotePickedMsg{path: "/srv/backups", selected: true})
+	if m.state != QTStateSelectingLocal || !m.estimate.loading {
+		t.Fatalf("Expected the download to be measured, got state %v", m.state)
+	}
+	if m.estimateSize() != nil {
+		t.Error("Expected the path being measured not to be measured again")
+	}
+
+	// The destination is picked before the size is known: wait for it
+	m.localPath = dir
+	m.state = QTStateTransferring
+	if cmd := m.executeTransfer(); cmd != nil || m.state != QTStateConfirmSize {
+		t.Fatalf("Expected the transfer to wait for its size, got state %v", m.state)
+	}
+	if !strings.Contains(m.View(), "Measuring") {
+		t.Error("Expected the view to show the size being measured")
+	}
+
+	m, cmd := m.Update(sizeEstimateMsg{path: "/srv/backups", size: 3 << 30, files: 4210})
+	if cmd != nil || m.state != QTStateConfirmSize {
+		t.Fatalf("Expected a download over the confirm size to wait for a confirmation, got state %v", m.state)
+	}
+	if view := m.View(); !strings.Contains(view, "About 3.0GB in 4,210 files") || !strings.Contains(view, "larger than 1.0GB") {
+		t.Errorf("Expected the size in the confirmation, got:\n%s", view)
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}); cmd == nil {
+		t.Fatal("Expected n to cancel")
+	} else if _, ok := cmd().(quickTransferCancelMsg); !ok {
+		t.Error("Expected n to cancel the transfer")
+	}
+}
@@ -1,22 +1,31 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
-	"path/filepath"
+	"os"
+	"path"
 	"sort"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
 	"github.com/Gu1llaum-3/sshm/internal/transfer"
+	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// BrowserMode defines whether we're selecting files or directories
+// BrowserMode defines whether we're selecting files or directories, or only looking around
 type BrowserMode int
 
 const (
 	BrowseFiles BrowserMode = iota
 	BrowseDirectories
+	BrowseNavigate // Nothing can be selected, Enter only opens directories
+	BrowseSaveAs   // A directory is selected, then the name of a new file in it is typed
 )
 
 // searchDebounceTime is how long to wait after typing before searching
@@ -24,29 +33,92 @@ const searchDebounceTime = 400 * time.Millisecond
 
 // remoteBrowserModel is the TUI file browser for remote files
 type remoteBrowserModel struct {
-	host        string
-	configFile  string
-	currentDir  string
-	files       []transfer.RemoteFile // All files from directory
-	visibleFiles []transfer.RemoteFile // Filtered files (respects showHidden)
-	cursor      int
-	selected    string
-	err         string
-	loading     bool
-	mode        BrowserMode
-	styles      Styles
-	width       int
-	height      int
-	session     *transfer.SFTPSession
-	searchMode  bool
-	searchQuery string
-	searchFiles []transfer.RemoteFile // Search results
-	hasLocate   bool                  // Whether locate is available on remote
-	showHidden  bool                  // Whether to show dotfiles
+	host          string
+	configFile    string
+	currentDir    string
+	files         []transfer.RemoteFile // All files from directory
+	visibleFiles  []transfer.RemoteFile // Filtered files (respects showHidden)
+	cursor        int
+	selected      string
+	err           string
+	status        string // Transient confirmation (e.g. after copying)
+	loading       bool
+	mode          BrowserMode
+	styles        Styles
+	width         int
+	height        int
+	session       *transfer.SFTPSession
+	searchMode    bool
+	searchQuery   string
+	searchFiles   []transfer.RemoteFile // Search results
+	hasLocate     bool                  // Whether locate is available on remote
+	showHidden    bool                  // Whether to show dotfiles
+	relativePaths bool                  // Show search results relative to currentDir
+	sortSetting   string                // AppConfig.RemoteBrowserSort
+	sortMode      remoteSortMode        // Order of the current listing
+	sortChosen    bool                  // Whether sortMode was picked with O rather than derived from the directory
 
 	// Debounce state
 	pendingSearch   string // Query waiting to be searched
 	searchTriggered bool   // Whether a search has been triggered for current query
+
+	// Recent remote directories (quick jumps)
+	pathStore  *history.RemotePathStore
+	jumpMode   bool
+	jumpCursor int
+	jumpPaths  []string
+
+	// Bookmarked remote directories, kept in the connection history
+	historyManager *history.HistoryManager
+	bookmarkMode   bool
+	bookmarkCursor int
+	bookmarks      []string
+
+	// Streaming listing state
+	listingID int  // Identifies the current listing so stale batches are ignored
+	streaming bool // More batches of the current listing are still arriving
+	truncated bool // The directory has more entries than DefaultListLimit
+
+	// Checksums
+	checksums   map[string]string   // SHA-256 of remote files, keyed by checksumKey
+	compareMode bool                // Whether the local path to compare with is being typed
+	comparePath string              // Local file to compare the selected file with
+	compareFile transfer.RemoteFile // Remote file being compared
+	dirCompare  *dirCompareView     // Differences with a local directory, shown instead of the listing
+
+	// Mode change of the selected entry
+	chmodMode      bool                // Whether the mode is being typed
+	chmodInput     string              // Mode being typed, octal or symbolic
+	chmodFile      transfer.RemoteFile // Entry whose mode changes
+	chmodRecursive bool                // Whether to ask if the mode applies below a directory
+
+	// Kept through a reload of the current directory
+	reloadStatus string // Status shown once the directory is listed again
+	reloadCursor string // Entry the cursor returns to
+
+	// Name of the new file, in BrowseSaveAs mode
+	nameMode    bool   // Whether the file name is being typed
+	nameDir     string // Directory the file goes to
+	nameInput   string // File name being typed
+	nameDefault string // Name s starts from, such as that of the file uploaded
+
+	// Extension filter, hiding the files that match none of its patterns
+	filter      extensionFilter
+	filterMode  bool   // Whether the patterns are being typed
+	filterInput string // Patterns being typed
+
+	// Type-ahead
+	initialPending bool // ' was pressed: the next character is jumped to, even a bound key
+
+	// Authentication
+	showAuthMethod bool   // AppConfig.ShowAuthMethod
+	authMethod     string // Key the session logged in with, once connected
+
+	// Temporary directory of the files downloaded to be opened, removed when the browser closes
+	openDir string
+
+	// The session outlives the browser, handed over with its result
+	keepSession bool
 }
 
 // remoteBrowserResultMsg is sent when browsing is complete
@@ -54,13 +126,18 @@ type remoteBrowserResultMsg struct {
 	path     string
 	selected bool
 	err      error
+	session  *transfer.SFTPSession // Handed over by a browser keeping its session
 }
 
-// remoteBrowserLoadedMsg is sent when directory listing completes
+// remoteBrowserLoadedMsg carries a batch of a directory listing
 type remoteBrowserLoadedMsg struct {
-	files []transfer.RemoteFile
-	dir   string
-	err   error
+	files     []transfer.RemoteFile
+	dir       string
+	err       error
+	id        int
+	more      bool // More batches follow on next
+	truncated bool
+	next      <-chan remoteBrowserLoadedMsg
 }
 
 // remoteBrowserSearchMsg is sent when search completes
@@ -70,6 +147,30 @@ type remoteBrowserSearchMsg struct {
 	err   error
 }
 
+// remoteBrowserCopiedMsg is sent when a file's contents were copied to the clipboard
+type remoteBrowserCopiedMsg struct {
+	name  string
+	bytes int
+	err   error
+}
+
+// remoteBrowserChecksumMsg carries the SHA-256 of a remote file, and of the
+// local file it was compared with when localPath is set
+type remoteBrowserChecksumMsg struct {
+	file      transfer.RemoteFile
+	sum       string
+	localPath string
+	localSum  string
+	err       error
+}
+
+// remoteBrowserOpenedMsg is sent when a remote file was downloaded to a
+// temporary directory and opened with its default application
+type remoteBrowserOpenedMsg struct {
+	name string
+	err  error
+}
+
 // searchDebounceMsg is sent after debounce delay to trigger actual search
 type searchDebounceMsg struct {
 	query string
@@ -81,17 +182,32 @@ func NewRemoteBrowser(host, startPath, configFile string, mode BrowserMode, styl
 		startPath = "~"
 	}
 
-	return &remoteBrowserModel{
-		host:       host,
-		configFile: configFile,
-		currentDir: startPath,
-		mode:       mode,
-		styles:     styles,
-		width:      width,
-		height:     height,
-		loading:    true,
-		cursor:     0,
+	pathStore, _ := history.NewRemotePathStore()
+	historyManager, _ := history.NewHistoryManager()
+
+	m := &remoteBrowserModel{
+		pathStore:      pathStore,
+		historyManager: historyManager,
+		host:           host,
+		configFile:     configFile,
+		currentDir:     startPath,
+		mode:           mode,
+		styles:         styles,
+		width:          width,
+		height:         height,
+		loading:        true,
+		cursor:         0,
+		filter:         lastRemoteFilter,
+		sortMode:       lastRemoteSort,
+		sortChosen:     remoteSortChosen,
+	}
+
+	if appConfig, err := config.LoadAppConfig(); err == nil && appConfig != nil {
+		m.sortSetting = appConfig.RemoteBrowserSort
+		m.showAuthMethod = appConfig.ShowAuthMethod
 	}
+
+	return m
 }
 
 func (m *remoteBrowserModel) Init() tea.Cmd {
@@ -99,12 +215,21 @@ func (m *remoteBrowserModel) Init() tea.Cmd {
 }
 
 func (m *remoteBrowserModel) loadDirectory(path string) tea.Cmd {
-	return func() tea.Msg {
+	m.listingID++
+	id := m.listingID
+
+	// Buffered for every possible batch so the lister never blocks on an abandoned listing
+	ch := make(chan remoteBrowserLoadedMsg, transfer.DefaultListLimit/transfer.ListBatchSize+2)
+
+	go func() {
+		defer close(ch)
+
 		// Create SFTP session if needed
 		if m.session == nil {
 			session, err := transfer.NewSFTPSession(m.host, m.configFile)
 			if err != nil {
-				return remoteBrowserLoadedMsg{err: err}
+				ch <- remoteBrowserLoadedMsg{err: err, id: id}
+				return
 			}
 			m.session = session
 		}
@@ -117,12 +242,180 @@ func (m *remoteBrowserModel) loadDirectory(path string) tea.Cmd {
 			}
 		}
 
-		files, err := m.session.ListDirectory(path)
+		truncated, err := m.session.ListDirectoryStream(path, transfer.DefaultListLimit, func(batch []transfer.RemoteFile) {
+			ch <- remoteBrowserLoadedMsg{files: batch, dir: path, id: id, more: true}
+		})
+		ch <- remoteBrowserLoadedMsg{dir: path, err: err, id: id, truncated: truncated}
+	}()
+
+	return waitForListing(ch)
+}
+
+// copyFileContents reads a remote text file and copies it to the clipboard
+func (m *remoteBrowserModel) copyFileContents(file transfer.RemoteFile) tea.Cmd {
+	session := m.session
+	return func() tea.Msg {
+		data, err := session.ReadTextFile(file.Path)
 		if err != nil {
-			return remoteBrowserLoadedMsg{err: err}
+			return remoteBrowserCopiedMsg{name: file.Name, err: err}
 		}
+		if err := clipboard.WriteAll(string(data)); err != nil {
+			return remoteBrowserCopiedMsg{name: file.Name, err: err}
+		}
+		return remoteBrowserCopiedMsg{name: file.Name, bytes: len(data)}
+	}
+}
+
+// openFile downloads a remote file to the temporary directory of the browser
+// and opens it with its default application
+func (m *remoteBrowserModel) openFile(file transfer.RemoteFile) tea.Cmd {
+	session := m.session
+	dir := m.openDir
+	return func() tea.Msg {
+		localPath, err := session.DownloadForOpen(file.Path, dir, transfer.OpenMaxSize())
+		if err == nil {
+			err = transfer.OpenLocalFile(localPath)
+		}
+		return remoteBrowserOpenedMsg{name: file.Name, err: err}
+	}
+}
+
+// close ends the SFTP session and removes the files downloaded to be opened
+// finish closes the browser and returns the command reporting its result. A
+// browser keeping its session hands it over with the result.
+func (m *remoteBrowserModel) finish(path string, selected bool) tea.Cmd {
+	result := remoteBrowserResultMsg{path: path, selected: selected}
+	if m.keepSession {
+		result.session, m.session = m.session, nil
+	}
+	m.close()
+	return func() tea.Msg { return result }
+}
+
+func (m *remoteBrowserModel) close() {
+	if m.session != nil {
+		m.session.Close()
+	}
+	if m.openDir != "" {
+		os.RemoveAll(m.openDir)
+		m.openDir = ""
+	}
+}
+
+// openNamePrompt asks for the name of the new file to put in dir, starting from name
+func (m *remoteBrowserModel) openNamePrompt(dir, name string) {
+	m.nameMode = true
+	m.nameDir = dir
+	m.nameInput = name
+	m.err = ""
+	m.status = ""
+}
+
+// saveAsPath returns the path of the file named name in dir. The name is a
+// single path element: subdirectories are chosen in the listing.
+func saveAsPath(dir, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", errors.New("type the name of the file")
+	case name == "." || name == ".." || strings.Contains(name, "/"):
+		return "", fmt.Errorf("%q is not a file name", name)
+	}
+	return path.Join(dir, name), nil
+}
+
+// scpGrabCommand returns the scp command downloading file into the current
+// directory, for running it from another terminal
+func scpGrabCommand(host, configFile string, file transfer.RemoteFile) string {
+	req := &transfer.TransferRequest{
+		Host:       host,
+		Direction:  transfer.Download,
+		LocalPath:  "./",
+		RemotePath: file.Path,
+		Recursive:  file.IsDir,
+		ConfigFile: configFile,
+	}
+	return req.CommandString()
+}
 
-		return remoteBrowserLoadedMsg{files: files, dir: path}
+// copySCPCommand copies the scp command for file to the clipboard. Without a
+// clipboard the command is shown instead so it can be copied by hand.
+func (m *remoteBrowserModel) copySCPCommand(file transfer.RemoteFile) {
+	command := scpGrabCommand(m.host, m.configFile, file)
+	if err := clipboard.WriteAll(command); err != nil {
+		m.err = ""
+		m.status = "Clipboard unavailable, copy it yourself: " + command
+		return
+	}
+	m.err = ""
+	m.status = "Copied " + command
+}
+
+// checksumFile computes the SHA-256 of a remote file, reusing a cached result,
+// and of localPath when it is set
+func (m *remoteBrowserModel) checksumFile(file transfer.RemoteFile, localPath string) tea.Cmd {
+	session := m.session
+	cached := m.checksums[checksumKey(file)]
+	return func() tea.Msg {
+		msg := remoteBrowserChecksumMsg{file: file, sum: cached, localPath: localPath}
+		if msg.sum == "" {
+			msg.sum, msg.err = session.Checksum(file.Path)
+			if msg.err != nil {
+				return msg
+			}
+		}
+		if localPath != "" {
+			expanded, err := transfer.ExpandPath(localPath)
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+			msg.localSum, msg.err = transfer.LocalChecksum(expanded)
+		}
+		return msg
+	}
+}
+
+// checksumKey identifies a version of a remote file in the checksum cache, so
+// a file changed since its checksum was computed is hashed again
+func checksumKey(file transfer.RemoteFile) string {
+	return fmt.Sprintf("%s|%d|%d", file.Path, file.Size, file.ModTime.Unix())
+}
+
+// checksumStatus formats the checksum of a remote file
+func checksumStatus(file transfer.RemoteFile, sum string) string {
+	return "SHA-256 " + file.Name + ": " + sum
+}
+
+// checksumResultText returns the error and status to show for a checksum
+// result: a plain checksum, or whether it matches the local file compared
+func checksumResultText(msg remoteBrowserChecksumMsg) (errText, status string) {
+	if msg.localPath == "" {
+		return "", checksumStatus(msg.file, msg.sum)
+	}
+	if transfer.ChecksumsMatch(msg.sum, msg.localSum) {
+		return "", fmt.Sprintf("✓ %s matches %s (SHA-256 %s)", msg.file.Name, msg.localPath, msg.sum)
+	}
+	return fmt.Sprintf("%s differs from %s (remote %s, local %s)", msg.file.Name, msg.localPath, shortChecksum(msg.sum), shortChecksum(msg.localSum)), ""
+}
+
+// shortChecksum abbreviates a checksum for side by side display
+func shortChecksum(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12] + "…"
+	}
+	return sum
+}
+
+// waitForListing returns a command that delivers the next batch of a listing
+func waitForListing(ch <-chan remoteBrowserLoadedMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		msg.next = ch
+		return msg
 	}
 }
 
@@ -148,9 +441,75 @@ func (m *remoteBrowserModel) scheduleSearch(query string) tea.Cmd {
 	})
 }
 
-// filterFiles updates visibleFiles based on showHidden setting
+// appendFiles adds a batch of listed files, keeping the cursor on the same entry
+func (m *remoteBrowserModel) appendFiles(files []transfer.RemoteFile) {
+	if len(files) == 0 {
+		return
+	}
+
+	var current string
+	if m.cursor < len(m.visibleFiles) {
+		current = m.visibleFiles[m.cursor].Name
+	}
+
+	m.files = append(m.files, files...)
+	sortRemoteFiles(m.files, m.sortMode)
+	m.filterFiles()
+
+	if current == "" {
+		return
+	}
+	for i, f := range m.visibleFiles {
+		if f.Name == current {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// defaultSortMode returns the order of dir until one is chosen with O: newest
+// first when the config asks for it or dir looks like a log directory
+func (m *remoteBrowserModel) defaultSortMode(dir string) remoteSortMode {
+	switch m.sortSetting {
+	case config.RemoteSortModified:
+		return remoteSortModifiedDesc
+	case config.RemoteSortName:
+		return remoteSortName
+	}
+	if transfer.IsLogDirectory(dir) {
+		return remoteSortModifiedDesc
+	}
+	return remoteSortName
+}
+
+// cycleSort switches to the next order and sorts the listing again, keeping
+// the cursor on the same entry. The order is kept for the rest of the session.
+func (m *remoteBrowserModel) cycleSort() {
+	m.sortMode = m.sortMode.next()
+	m.sortChosen = true
+	lastRemoteSort, remoteSortChosen = m.sortMode, true
+
+	var current string
+	if m.cursor < len(m.visibleFiles) {
+		current = m.visibleFiles[m.cursor].Name
+	}
+	sortRemoteFiles(m.files, m.sortMode)
+	m.filterFiles()
+	m.cursor = 0
+	for i, f := range m.visibleFiles {
+		if f.Name == current {
+			m.cursor = i
+			break
+		}
+	}
+
+	m.err = ""
+	m.status = "Sorted by " + m.sortMode.String()
+}
+
+// filterFiles updates visibleFiles based on showHidden setting and the extension filter
 func (m *remoteBrowserModel) filterFiles() {
-	if m.showHidden {
+	if m.showHidden && !m.filter.active() {
 		m.visibleFiles = m.files
 		return
 	}
@@ -158,12 +517,48 @@ func (m *remoteBrowserModel) filterFiles() {
 	m.visibleFiles = nil
 	for _, f := range m.files {
 		// Always show ".." for navigation
-		if f.Name == ".." || !strings.HasPrefix(f.Name, ".") {
+		if f.Name != ".." && !m.showHidden && strings.HasPrefix(f.Name, ".") {
+			continue
+		}
+		if m.filter.matches(f) {
 			m.visibleFiles = append(m.visibleFiles, f)
 		}
 	}
 }
 
+// clampCursor keeps the cursor on a visible entry after the list shrank
+func (m *remoteBrowserModel) clampCursor() {
+	if m.cursor >= len(m.visibleFiles) {
+		m.cursor = len(m.visibleFiles) - 1
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+	}
+}
+
+// emptyState returns the guidance shown when the directory has nothing to list
+func (m *remoteBrowserModel) emptyState() string {
+	for _, f := range m.visibleFiles {
+		if f.Name != ".." {
+			return ""
+		}
+	}
+	if m.filter.active() {
+		for _, f := range m.files {
+			if !f.IsDir && f.Name != ".." && !m.filter.matches(f) {
+				return fmt.Sprintf("No files match %s — press * to change the filter", m.filter)
+			}
+		}
+	}
+	if hidden := len(m.files) - len(m.visibleFiles); hidden > 0 {
+		return fmt.Sprintf("No visible files — press '.' to show %d hidden", hidden)
+	}
+	if m.currentDir == "/" {
+		return "This directory is empty"
+	}
+	return "This directory is empty — press Backspace to go up"
+}
+
 // filterSearchResults filters existing search results by current query (for backspace)
 func (m *remoteBrowserModel) filterSearchResults() {
 	if len(m.searchQuery) < 3 {
@@ -225,21 +620,97 @@ func (m *remoteBrowserModel) sortSearchResults() {
 
 func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd) {
 	switch msg := msg.(type) {
-	case remoteBrowserLoadedMsg:
-		m.loading = false
+	case keyUnlockedMsg:
 		if msg.err != nil {
-			m.err = msg.err.Error()
+			m.err = msg.err.Error() + " (press r to retry)"
 			return m, nil
 		}
-		m.files = msg.files
-		m.currentDir = msg.dir
-		m.cursor = 0
 		m.err = ""
-		m.searchMode = false
-		m.searchQuery = ""
-		m.searchFiles = nil
-		m.filterFiles()
-		return m, nil
+		m.loading = true
+		return m, m.loadDirectory(m.currentDir)
+
+	case hostKeyAcceptedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error() + " (press r to retry)"
+			return m, nil
+		}
+		m.err = ""
+		m.loading = true
+		return m, m.loadDirectory(m.currentDir)
+
+	case remoteBrowserLoadedMsg:
+		// Keep draining listings we've navigated away from, but ignore their contents
+		if msg.id != m.listingID {
+			if msg.more {
+				return m, waitForListing(msg.next)
+			}
+			return m, nil
+		}
+
+		if msg.err != nil {
+			m.loading = false
+			m.streaming = false
+			m.err = remoteErrorText(msg.err, "press r to retry")
+			// Ask for the passphrase of a protected key, then list again
+			var locked *transfer.KeyLockedError
+			if errors.As(msg.err, &locked) {
+				return m, unlockKey(locked)
+			}
+			// Ask whether to trust the key of an unknown host, then list again
+			var unknown *transfer.UnknownHostKeyError
+			if errors.As(msg.err, &unknown) {
+				return m, acceptHostKey(unknown)
+			}
+			return m, nil
+		}
+
+		// First batch of a new listing replaces the previous directory
+		if m.loading {
+			m.loading = false
+			m.files = nil
+			m.currentDir = msg.dir
+			m.cursor = 0
+			m.err = ""
+			m.status = m.reloadStatus
+			m.reloadStatus = ""
+			m.truncated = false
+			m.searchMode = false
+			m.searchQuery = ""
+			m.searchFiles = nil
+			if !m.sortChosen {
+				m.sortMode = m.defaultSortMode(msg.dir)
+			}
+			if m.session != nil {
+				if method, ok := m.session.AuthMethod(); ok {
+					m.authMethod = method.String()
+				}
+			}
+
+			// Remember visited directories for quick jumps
+			if m.pathStore != nil {
+				_ = m.pathStore.Record(m.host, msg.dir)
+			}
+		}
+
+		m.appendFiles(msg.files)
+		if m.reloadCursor != "" {
+			for i, f := range m.visibleFiles {
+				if f.Name == m.reloadCursor {
+					m.cursor = i
+					m.reloadCursor = ""
+					break
+				}
+			}
+			if !msg.more {
+				m.reloadCursor = ""
+			}
+		}
+		m.streaming = msg.more
+		if !msg.more {
+			m.truncated = msg.truncated
+			return m, nil
+		}
+		return m, waitForListing(msg.next)
 
 	case remoteBrowserSearchMsg:
 		// Only process if this is for the current query (ignore stale results)
@@ -249,7 +720,7 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 		m.loading = false
 		m.searchTriggered = true
 		if msg.err != nil {
-			m.err = msg.err.Error()
+			m.err = remoteErrorText(msg.err, "edit the query to search again")
 			return m, nil
 		}
 		m.searchFiles = msg.files
@@ -258,6 +729,81 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 		m.err = ""
 		return m, nil
 
+	case remoteBrowserCopiedMsg:
+		if msg.err != nil {
+			m.err = fmt.Sprintf("cannot copy %s: %v", msg.name, msg.err)
+			m.status = ""
+			return m, nil
+		}
+		m.err = ""
+		m.status = fmt.Sprintf("Copied %d bytes of %s to clipboard", msg.bytes, msg.name)
+		return m, nil
+
+	case remoteBrowserOpenedMsg:
+		if msg.err != nil {
+			m.err = remoteErrorText(fmt.Errorf("cannot open %s: %w", msg.name, msg.err), "press o to retry")
+			m.status = ""
+			return m, nil
+		}
+		m.err = ""
+		m.status = "Opened " + msg.name + " (temporary copy, removed when the browser closes)"
+		return m, nil
+
+	case remoteBrowserEditedMsg:
+		if msg.err != nil {
+			m.err = remoteErrorText(fmt.Errorf("cannot edit %s: %w", msg.file.Name, msg.err), "press E to retry")
+			m.status = ""
+			return m, nil
+		}
+		m.err = ""
+		if !msg.sshfs && !msg.uploaded {
+			m.status = editStatus(msg)
+			return m, nil
+		}
+		// List the directory again to show the new size, staying on the file
+		m.loading = true
+		m.reloadStatus = editStatus(msg)
+		m.reloadCursor = msg.file.Name
+		return m, m.loadDirectory(m.currentDir)
+
+	case remoteBrowserChecksumMsg:
+		if msg.err != nil {
+			m.err = remoteErrorText(fmt.Errorf("cannot checksum %s: %w", msg.file.Name, msg.err), "press c to retry")
+			m.status = ""
+			return m, nil
+		}
+		if msg.sum != "" {
+			if m.checksums == nil {
+				m.checksums = make(map[string]string)
+			}
+			m.checksums[checksumKey(msg.file)] = msg.sum
+		}
+		m.err, m.status = checksumResultText(msg)
+		return m, nil
+
+	case remoteBrowserChmodMsg:
+		if msg.err != nil {
+			m.status = ""
+			m.err = remoteErrorText(fmt.Errorf("cannot change the mode of %s: %w", msg.file.Name, msg.err), "press X to retry")
+			return m, nil
+		}
+		// List the directory again to show the new mode, staying on the entry
+		m.err = ""
+		m.loading = true
+		m.reloadStatus = chmodStatus(msg)
+		m.reloadCursor = msg.file.Name
+		return m, m.loadDirectory(m.currentDir)
+
+	case remoteBrowserDirCompareMsg:
+		m.status = ""
+		if msg.err != nil {
+			m.err = remoteErrorText(fmt.Errorf("cannot compare %s: %w", msg.dir.Name, msg.err), "press C to retry")
+			return m, nil
+		}
+		m.err = ""
+		m.dirCompare = newDirCompareView(msg.comparison, m.width, m.height)
+		return m, nil
+
 	case searchDebounceMsg:
 		// Only search if query hasn't changed since debounce was scheduled
 		if msg.query == m.searchQuery && len(m.searchQuery) >= 3 && !m.searchTriggered {
@@ -280,6 +826,9 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 				m.loading = false
 				m.cursor = 0
 				return m, nil
+			case "ctrl+t":
+				m.relativePaths = !m.relativePaths
+				return m, nil
 			case "up", "ctrl+p":
 				if m.cursor > 0 {
 					m.cursor--
@@ -298,6 +847,214 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 			return m, nil
 		}
 
+		// Handle the differences with a local directory
+		if m.dirCompare != nil {
+			switch msg.String() {
+			case "esc", "q", "C", "ctrl+c":
+				m.dirCompare = nil
+			default:
+				var cmd tea.Cmd
+				m.dirCompare.viewport, cmd = m.dirCompare.viewport.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		// Handle recent directory list
+		if m.jumpMode {
+			switch msg.String() {
+			case "esc", "q", "J", "ctrl+c":
+				m.jumpMode = false
+			case "up", "k":
+				if m.jumpCursor > 0 {
+					m.jumpCursor--
+				}
+			case "down", "j":
+				if m.jumpCursor < len(m.jumpPaths)-1 {
+					m.jumpCursor++
+				}
+			case "enter":
+				m.jumpMode = false
+				m.loading = true
+				return m, m.loadDirectory(m.jumpPaths[m.jumpCursor])
+			}
+			return m, nil
+		}
+
+		// Handle the bookmark list
+		if m.bookmarkMode {
+			switch msg.String() {
+			case "esc", "q", "B", "ctrl+c":
+				m.bookmarkMode = false
+			case "up", "k":
+				if m.bookmarkCursor > 0 {
+					m.bookmarkCursor--
+				}
+			case "down", "j":
+				if m.bookmarkCursor < len(m.bookmarks)-1 {
+					m.bookmarkCursor++
+				}
+			case "d", "delete":
+				bookmark := m.bookmarks[m.bookmarkCursor]
+				if err := m.historyManager.RemoveRemoteBookmark(m.host, bookmark); err != nil {
+					m.err = fmt.Sprintf("Cannot remove the bookmark: %v", err)
+					return m, nil
+				}
+				m.bookmarks = m.historyManager.GetRemoteBookmarks(m.host)
+				m.status = "Removed the bookmark of " + bookmark
+				if len(m.bookmarks) == 0 {
+					m.bookmarkMode = false
+				} else if m.bookmarkCursor >= len(m.bookmarks) {
+					m.bookmarkCursor = len(m.bookmarks) - 1
+				}
+			case "enter":
+				m.bookmarkMode = false
+				m.loading = true
+				return m, m.loadDirectory(m.bookmarks[m.bookmarkCursor])
+			}
+			return m, nil
+		}
+
+		// Handle the file name prompt of BrowseSaveAs
+		if m.nameMode {
+			switch msg.String() {
+			case "esc", "ctrl+c":
+				m.nameMode = false
+				m.err = ""
+			case "enter":
+				target, err := saveAsPath(m.nameDir, m.nameInput)
+				if err != nil {
+					m.err = err.Error()
+					return m, nil
+				}
+				m.nameMode = false
+				return m, m.finish(target, true)
+			case "backspace":
+				if len(m.nameInput) > 0 {
+					_, size := utf8.DecodeLastRuneInString(m.nameInput)
+					m.nameInput = m.nameInput[:len(m.nameInput)-size]
+				}
+			default:
+				if msg.Type == tea.KeyRunes {
+					m.nameInput += string(msg.Runes)
+				} else if msg.Type == tea.KeySpace {
+					m.nameInput += " "
+				}
+			}
+			return m, nil
+		}
+
+		// Handle the local path prompt of a checksum comparison
+		if m.compareMode {
+			switch msg.String() {
+			case "esc", "ctrl+c":
+				m.compareMode = false
+				m.status = ""
+			case "enter":
+				m.compareMode = false
+				localPath := strings.TrimSpace(m.comparePath)
+				if localPath == "" {
+					return m, nil
+				}
+				m.status = "Comparing " + m.compareFile.Name + " with " + localPath + "..."
+				if m.compareFile.IsDir {
+					return m, m.compareDirectory(m.compareFile, localPath)
+				}
+				return m, m.checksumFile(m.compareFile, localPath)
+			case "backspace":
+				if len(m.comparePath) > 0 {
+					m.comparePath = m.comparePath[:len(m.comparePath)-1]
+				}
+			default:
+				char := msg.String()
+				if len(char) == 1 && char[0] >= 32 && char[0] < 127 {
+					m.comparePath += char
+				}
+			}
+			return m, nil
+		}
+
+		// Handle the mode prompt of a mode change
+		if m.chmodMode {
+			switch msg.String() {
+			case "esc", "ctrl+c":
+				m.chmodMode = false
+				m.err = ""
+			case "enter":
+				mode := strings.TrimSpace(m.chmodInput)
+				if err := transfer.ValidateChmodMode(mode); err != nil {
+					m.err = err.Error()
+					return m, nil
+				}
+				m.chmodMode = false
+				m.chmodInput = mode
+				m.err = ""
+				if m.chmodFile.IsDir {
+					// Ask before changing everything below a directory
+					m.chmodRecursive = true
+					return m, nil
+				}
+				return m, m.chmod(m.chmodFile, mode, false)
+			case "backspace":
+				if len(m.chmodInput) > 0 {
+					m.chmodInput = m.chmodInput[:len(m.chmodInput)-1]
+				}
+			default:
+				char := msg.String()
+				if len(char) == 1 && char[0] > 32 && char[0] < 127 {
+					m.chmodInput += char
+				}
+			}
+			return m, nil
+		}
+
+		// Handle the recursive confirmation of a directory mode change
+		if m.chmodRecursive {
+			switch msg.String() {
+			case "y", "Y":
+				m.chmodRecursive = false
+				return m, m.chmod(m.chmodFile, m.chmodInput, true)
+			case "n", "N":
+				m.chmodRecursive = false
+				return m, m.chmod(m.chmodFile, m.chmodInput, false)
+			case "esc", "q", "ctrl+c":
+				m.chmodRecursive = false
+			}
+			return m, nil
+		}
+
+		// Handle the patterns prompt of the extension filter
+		if m.filterMode {
+			switch msg.String() {
+			case "esc", "ctrl+c":
+				m.filterMode = false
+			case "enter":
+				m.filterMode = false
+				m.filter = parseExtensionFilter(m.filterInput)
+				lastRemoteFilter = m.filter
+				m.filterFiles()
+				m.clampCursor()
+				m.err = ""
+				if m.filter.active() {
+					m.status = "Showing directories and " + m.filter.String() + " files"
+				} else {
+					m.status = "Filter cleared"
+				}
+			case "backspace":
+				if len(m.filterInput) > 0 {
+					m.filterInput = m.filterInput[:len(m.filterInput)-1]
+				}
+			default:
+				char := msg.String()
+				if len(char) == 1 && char[0] >= 32 && char[0] < 127 {
+					m.filterInput += char
+				} else if msg.Type == tea.KeySpace {
+					m.filterInput += " "
+				}
+			}
+			return m, nil
+		}
+
 		// Handle search mode input
 		if m.searchMode {
 			switch msg.String() {
@@ -326,16 +1083,19 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 						return m, m.loadDirectory(file.Path)
 					} else if m.mode == BrowseFiles {
 						// Select file
-						if m.session != nil {
-							m.session.Close()
-						}
-						return m, func() tea.Msg {
-							return remoteBrowserResultMsg{path: file.Path, selected: true}
-						}
+						return m, m.finish(file.Path, true)
+					} else if m.mode == BrowseSaveAs {
+						// Name the new file after this one, in its directory
+						m.openNamePrompt(path.Dir(file.Path), file.Name)
 					}
 				}
 				return m, nil
 
+			case "ctrl+t":
+				// Toggle absolute/relative display of result paths
+				m.relativePaths = !m.relativePaths
+				return m, nil
+
 			case "up", "ctrl+p":
 				if m.cursor > 0 {
 					m.cursor--
@@ -382,16 +1142,21 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 			}
 		}
 
+		// Character typed after ', jumped to even when it is bound to an action
+		if m.initialPending {
+			m.initialPending = false
+			m.status = ""
+			if char := msg.String(); isTypeAheadChar(char) {
+				m.jumpToInitial(char)
+			}
+			return m, nil
+		}
+
 		// Normal mode
 		switch msg.String() {
 		case "q", "ctrl+c":
 			// Cancel
-			if m.session != nil {
-				m.session.Close()
-			}
-			return m, func() tea.Msg {
-				return remoteBrowserResultMsg{selected: false}
-			}
+			return m, m.finish("", false)
 
 		case "esc":
 			// Cancel or exit search
@@ -401,12 +1166,169 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 				m.searchFiles = nil
 				return m, nil
 			}
-			if m.session != nil {
-				m.session.Close()
+			return m, m.finish("", false)
+
+		case "y":
+			// Copy the selected file's contents to the clipboard
+			if len(m.visibleFiles) == 0 || m.session == nil {
+				return m, nil
+			}
+			file := m.visibleFiles[m.cursor]
+			if file.IsDir {
+				return m, nil
+			}
+			if err := transfer.CheckTextSize(file.Size); err != nil {
+				m.err = fmt.Sprintf("cannot copy %s: %v", file.Name, err)
+				m.status = ""
+				return m, nil
+			}
+			m.status = "Copying " + file.Name + "..."
+			return m, m.copyFileContents(file)
+
+		case "o":
+			// Download the selected file to a temporary directory and open it
+			if len(m.visibleFiles) == 0 || m.session == nil {
+				return m, nil
+			}
+			file := m.visibleFiles[m.cursor]
+			if file.IsDir {
+				return m, nil
+			}
+			if err := transfer.CheckOpenSize(file.Size, transfer.OpenMaxSize()); err != nil {
+				m.err = fmt.Sprintf("cannot open %s: %v", file.Name, err)
+				m.status = ""
+				return m, nil
+			}
+			if m.openDir == "" {
+				dir, err := transfer.NewOpenDir()
+				if err != nil {
+					m.err = fmt.Sprintf("cannot open %s: %v", file.Name, err)
+					m.status = ""
+					return m, nil
+				}
+				m.openDir = dir
+			}
+			m.err = ""
+			m.status = "Opening " + file.Name + "..."
+			return m, m.openFile(file)
+
+		case "E":
+			// Edit the selected file in $EDITOR, on an SSHFS mount or a copy
+			if len(m.visibleFiles) == 0 || m.session == nil {
+				return m, nil
+			}
+			file := m.visibleFiles[m.cursor]
+			if file.IsDir {
+				return m, nil
+			}
+			if !transfer.IsSSHFSAvailable() {
+				if err := transfer.CheckOpenSize(file.Size, transfer.OpenMaxSize()); err != nil {
+					m.err = fmt.Sprintf("cannot edit %s: %v", file.Name, err)
+					m.status = ""
+					return m, nil
+				}
+			}
+			// Also needed with sshfs, to edit a copy when the mount fails
+			if m.openDir == "" {
+				dir, err := transfer.NewOpenDir()
+				if err != nil {
+					m.err = fmt.Sprintf("cannot edit %s: %v", file.Name, err)
+					m.status = ""
+					return m, nil
+				}
+				m.openDir = dir
+			}
+			m.err = ""
+			m.status = ""
+			return m, m.editFile(file)
+
+		case "Y":
+			// Copy an scp command downloading the selected entry
+			if len(m.visibleFiles) == 0 {
+				return m, nil
+			}
+			m.copySCPCommand(m.visibleFiles[m.cursor])
+			return m, nil
+
+		case "c", "C":
+			// Show the selected file's SHA-256, or compare it (or a directory) with a local one
+			if len(m.visibleFiles) == 0 || m.session == nil {
+				return m, nil
+			}
+			file := m.visibleFiles[m.cursor]
+			if file.IsDir && msg.String() == "c" {
+				return m, nil
+			}
+			m.err = ""
+			if msg.String() == "C" {
+				m.compareMode = true
+				m.compareFile = file
+				m.comparePath = "./" + file.Name
+				return m, nil
+			}
+			if sum, ok := m.checksums[checksumKey(file)]; ok {
+				m.status = checksumStatus(file, sum)
+				return m, nil
+			}
+			m.status = "Computing SHA-256 of " + file.Name + "..."
+			return m, m.checksumFile(file, "")
+
+		case "J":
+			// Show recently used directories on this host
+			if m.pathStore == nil {
+				return m, nil
+			}
+			m.jumpPaths = nil
+			for _, p := range m.pathStore.Suggestions(m.host) {
+				if p != m.currentDir {
+					m.jumpPaths = append(m.jumpPaths, p)
+				}
+			}
+			if len(m.jumpPaths) == 0 {
+				m.status = "No recent directories for this host yet"
+				return m, nil
+			}
+			m.jumpMode = true
+			m.jumpCursor = 0
+			return m, nil
+
+		case "b":
+			// Bookmark the current directory
+			if m.historyManager == nil || m.loading {
+				return m, nil
+			}
+			m.err = ""
+			for _, p := range m.historyManager.GetRemoteBookmarks(m.host) {
+				if p == m.currentDir {
+					m.status = m.currentDir + " is already bookmarked"
+					return m, nil
+				}
+			}
+			if err := m.historyManager.RecordRemoteBookmark(m.host, m.currentDir); err != nil {
+				m.err = fmt.Sprintf("Cannot bookmark %s: %v", m.currentDir, err)
+				return m, nil
+			}
+			m.status = "★ Bookmarked " + m.currentDir
+			return m, nil
+
+		case "B":
+			// Show the bookmarked directories of this host
+			if m.historyManager == nil {
+				return m, nil
+			}
+			m.bookmarks = m.historyManager.GetRemoteBookmarks(m.host)
+			if len(m.bookmarks) == 0 {
+				m.status = "No bookmarks for this host yet: press b to bookmark a directory"
+				return m, nil
 			}
-			return m, func() tea.Msg {
-				return remoteBrowserResultMsg{selected: false}
+			m.bookmarkMode = true
+			m.bookmarkCursor = 0
+			for i, p := range m.bookmarks {
+				if p == m.currentDir {
+					m.bookmarkCursor = i
+				}
 			}
+			return m, nil
 
 		case "/":
 			// Enter search mode
@@ -420,13 +1342,15 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 			// Toggle hidden files
 			m.showHidden = !m.showHidden
 			m.filterFiles()
-			// Adjust cursor if it's now out of bounds
-			if m.cursor >= len(m.visibleFiles) {
-				m.cursor = len(m.visibleFiles) - 1
-				if m.cursor < 0 {
-					m.cursor = 0
-				}
-			}
+			m.clampCursor()
+			return m, nil
+
+		case "*":
+			// Edit the extension filter, starting from the current one
+			m.filterMode = true
+			m.filterInput = m.filter.String()
+			m.err = ""
+			m.status = ""
 			return m, nil
 
 		case "r", "R":
@@ -454,16 +1378,20 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 			}
 			// File selected
 			if m.mode == BrowseFiles {
-				if m.session != nil {
-					m.session.Close()
-				}
-				return m, func() tea.Msg {
-					return remoteBrowserResultMsg{path: file.Path, selected: true}
-				}
+				return m, m.finish(file.Path, true)
+			}
+			if m.mode == BrowseSaveAs {
+				// Start from the name of the existing file, to replace or vary it
+				m.openNamePrompt(m.currentDir, file.Name)
 			}
 			return m, nil
 
 		case "s", " ":
+			// Type the name of the new file (for BrowseSaveAs mode)
+			if m.mode == BrowseSaveAs {
+				m.openNamePrompt(m.currentDir, m.nameDefault)
+				return m, nil
+			}
 			// Select current directory (for BrowseDirectories mode)
 			if m.mode == BrowseDirectories {
 				path := m.currentDir
@@ -471,12 +1399,7 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 				if m.searchMode && len(m.searchFiles) > 0 && m.searchFiles[m.cursor].IsDir {
 					path = m.searchFiles[m.cursor].Path
 				}
-				if m.session != nil {
-					m.session.Close()
-				}
-				return m, func() tea.Msg {
-					return remoteBrowserResultMsg{path: path, selected: true}
-				}
+				return m, m.finish(path, true)
 			}
 			return m, nil
 
@@ -504,7 +1427,7 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 
 		case "backspace", "h", "left":
 			// Go to parent directory
-			parent := filepath.Dir(m.currentDir)
+			parent := path.Dir(path.Clean(m.currentDir))
 			if parent != m.currentDir {
 				m.loading = true
 				return m, m.loadDirectory(parent)
@@ -516,6 +1439,15 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 			m.loading = true
 			return m, m.loadDirectory("~")
 
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			// Jump up N levels using the breadcrumb
+			levels := int(msg.String()[0] - '0')
+			if ancestor, ok := breadcrumbAncestor(m.currentDir, levels); ok {
+				m.loading = true
+				return m, m.loadDirectory(ancestor)
+			}
+			return m, nil
+
 		case "right", "l":
 			// Enter directory if on one
 			if len(m.visibleFiles) > 0 && m.visibleFiles[m.cursor].IsDir {
@@ -523,12 +1455,81 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 				return m, m.loadDirectory(m.visibleFiles[m.cursor].Path)
 			}
 			return m, nil
+
+		case "X":
+			// Change the mode of the selected entry
+			if len(m.visibleFiles) == 0 || m.session == nil {
+				return m, nil
+			}
+			file := m.visibleFiles[m.cursor]
+			if file.Name == ".." {
+				return m, nil
+			}
+			m.chmodMode = true
+			m.chmodFile = file
+			m.chmodInput = defaultChmodInput(file)
+			m.err = ""
+			m.status = ""
+			return m, nil
+
+		case "O":
+			// Cycle the order of the listing
+			m.cycleSort()
+			return m, nil
+
+		case "'":
+			// Jump to the character typed next, for initials bound to other actions
+			m.initialPending = true
+			m.err = ""
+			m.status = "Jump to entries starting with..."
+			return m, nil
+
+		default:
+			// Type-ahead: jump to the next entry starting with the typed character
+			if char := msg.String(); isTypeAheadChar(char) {
+				m.jumpToInitial(char)
+			}
+			return m, nil
 		}
 	}
 
 	return m, nil
 }
 
+// isTypeAheadChar reports whether a key is a single printable character
+func isTypeAheadChar(key string) bool {
+	r, size := utf8.DecodeRuneInString(key)
+	return size == len(key) && r != utf8.RuneError && unicode.IsPrint(r) && !unicode.IsSpace(r)
+}
+
+// hasInitial reports whether name starts with char, ignoring case
+func hasInitial(name, char string) bool {
+	return strings.HasPrefix(strings.ToLower(name), strings.ToLower(char))
+}
+
+// jumpToInitial moves the cursor to the first visible entry starting with
+// char, in the current sort order. When the cursor is already on such an
+// entry it moves to the next one, wrapping around, so repeating cycles.
+func (m *remoteBrowserModel) jumpToInitial(char string) {
+	n := len(m.visibleFiles)
+	start := 0
+	if m.cursor >= 0 && m.cursor < n && hasInitial(m.visibleFiles[m.cursor].Name, char) {
+		start = m.cursor + 1
+	}
+
+	for i := 0; i < n; i++ {
+		idx := (start + i) % n
+		if f := m.visibleFiles[idx]; f.Name != ".." && hasInitial(f.Name, char) {
+			m.cursor = idx
+			m.err = ""
+			m.status = ""
+			return
+		}
+	}
+	m.err = ""
+	m.status = fmt.Sprintf("No entry starting with '%s'", char)
+}
+
 func (m *remoteBrowserModel) View() string {
 	var b strings.Builder
 
@@ -549,17 +1550,67 @@ func (m *remoteBrowserModel) View() string {
 		}
 		b.WriteString("  in: " + m.currentDir + "\n")
 	} else {
-		b.WriteString(m.styles.DirStyle.Render("  "+m.currentDir) + "\n")
+		b.WriteString("  " + m.renderBreadcrumb() + "\n")
+	}
+	if m.showAuthMethod && m.authMethod != "" {
+		b.WriteString(m.styles.HelpText.Render("  🔑 Logged in with "+m.authMethod) + "\n")
 	}
 	b.WriteString("\n")
 
 	// Error message
 	if m.err != "" {
 		b.WriteString(m.styles.Error.Render("Error: "+m.err) + "\n\n")
+	} else if m.status != "" {
+		b.WriteString(m.styles.HelpText.Render("  "+m.status) + "\n\n")
+	}
+
+	// Local path prompt of a checksum comparison
+	if m.compareMode && m.compareFile.IsDir {
+		b.WriteString(fmt.Sprintf("  Compare %s/ with local directory: %s_\n\n", m.compareFile.Name, m.comparePath))
+	} else if m.compareMode {
+		b.WriteString(fmt.Sprintf("  Compare %s with local file: %s_\n\n", m.compareFile.Name, m.comparePath))
 	}
 
-	// Loading indicator or file list
-	if m.loading {
+	// File name prompt of BrowseSaveAs
+	if m.nameMode {
+		b.WriteString(fmt.Sprintf("  New file in %s: %s_\n\n", m.nameDir, m.nameInput))
+	}
+
+	// Mode prompt and recursive confirmation of a mode change
+	if m.chmodMode {
+		b.WriteString(fmt.Sprintf("  New mode of %s: %s_\n\n", m.chmodFile.Name, m.chmodInput))
+	} else if m.chmodRecursive {
+		b.WriteString(fmt.Sprintf("  Apply %s to everything inside %s/ too? (y/n)\n\n", m.chmodInput, m.chmodFile.Name))
+	}
+
+	// Patterns prompt of the extension filter
+	if m.filterMode {
+		b.WriteString(fmt.Sprintf("  Show only files matching: %s_\n\n", m.filterInput))
+	}
+
+	// Differences with a local directory, loading indicator, recent directories, bookmarks or file list
+	if m.dirCompare != nil {
+		m.dirCompare.fit(m.width, m.height)
+		b.WriteString(m.dirCompare.View(m.styles))
+	} else if m.jumpMode {
+		b.WriteString("  Recent directories:\n")
+		for i, p := range m.jumpPaths {
+			if i == m.jumpCursor {
+				b.WriteString(ansiSelected + "▶ " + p + ansiReset + "\n")
+			} else {
+				b.WriteString("  " + ansiDir + p + ansiReset + "\n")
+			}
+		}
+	} else if m.bookmarkMode {
+		b.WriteString("  Bookmarks:\n")
+		for i, p := range m.bookmarks {
+			if i == m.bookmarkCursor {
+				b.WriteString(ansiSelected + "▶ " + p + ansiReset + "\n")
+			} else {
+				b.WriteString("  " + ansiDir + p + ansiReset + "\n")
+			}
+		}
+	} else if m.loading {
 		if m.searchMode {
 			b.WriteString("  Searching...\n")
 		} else {
@@ -571,7 +1622,7 @@ func (m *remoteBrowserModel) View() string {
 		if m.searchMode && len(m.searchFiles) > 0 {
 			displayFiles = m.searchFiles
 		} else if m.searchMode && len(m.searchQuery) >= 3 && m.searchTriggered && len(m.searchFiles) == 0 {
-			b.WriteString("  No files found\n")
+			b.WriteString(fmt.Sprintf("  No matches for '%s' — edit the search or press Esc to go back\n", m.searchQuery))
 			displayFiles = nil
 		} else if m.searchMode {
 			displayFiles = nil
@@ -605,30 +1656,130 @@ func (m *remoteBrowserModel) View() string {
 				b.WriteString(fmt.Sprintf("  [%d/%d]\n", m.cursor+1, len(displayFiles)))
 			}
 		}
+
+		if !m.searchMode && !m.streaming && m.err == "" {
+			if emptyState := m.emptyState(); emptyState != "" {
+				b.WriteString(m.styles.HelpText.Render("  "+emptyState) + "\n")
+			}
+		}
+
+		if !m.searchMode {
+			if m.streaming {
+				b.WriteString(fmt.Sprintf("  Loading more... (%d entries so far)\n", len(m.files)))
+			} else if m.truncated {
+				b.WriteString(m.styles.HelpText.Render(fmt.Sprintf("  Showing first %d entries, refine with / search", transfer.DefaultListLimit)) + "\n")
+			}
+		}
 	}
 
 	b.WriteString("\n")
 
 	// Hidden files indicator and help
-	if !m.searchMode {
+	if !m.searchMode && m.dirCompare == nil {
+		indicator := "  [hidden: off]"
 		if m.showHidden {
-			b.WriteString("  [hidden: on]\n")
-		} else {
-			b.WriteString("  [hidden: off]\n")
+			indicator = "  [hidden: on]"
+		}
+		if m.sortMode != remoteSortName {
+			indicator += " [sort: " + m.sortMode.String() + "]"
 		}
+		if m.filter.active() {
+			indicator += " [filter: " + m.filter.String() + "]"
+		}
+		b.WriteString(indicator + "\n")
 	}
 
-	if m.searchMode {
-		b.WriteString(" ↑/↓: navigate | Enter: select | Esc: back\n")
+	if m.dirCompare != nil {
+		b.WriteString(" ~: changed | <: only remote | >: only local | ↑/↓: scroll | Esc: back to the listing\n")
+	} else if m.compareMode && m.compareFile.IsDir {
+		b.WriteString(" Enter: compare names, sizes and dates | Esc: cancel\n")
+	} else if m.compareMode {
+		b.WriteString(" Enter: compare SHA-256 | Esc: cancel\n")
+	} else if m.nameMode {
+		b.WriteString(" Enter: use this name | Esc: back to the listing\n")
+	} else if m.chmodMode {
+		b.WriteString(" Octal such as 755 or symbolic such as +x, -x, go-w | Enter: apply | Esc: cancel\n")
+	} else if m.chmodRecursive {
+		b.WriteString(" y: recursive (chmod -R) | n: the directory only | Esc: cancel\n")
+	} else if m.filterMode {
+		b.WriteString(" Patterns such as *.log *.tar.gz, or extensions such as log | Enter: apply (empty clears) | Esc: cancel\n")
+	} else if m.jumpMode {
+		b.WriteString(" ↑/↓: navigate | Enter: jump | Esc: back\n")
+	} else if m.bookmarkMode {
+		b.WriteString(" ↑/↓: navigate | Enter: jump | d: remove | Esc: back\n")
+	} else if m.searchMode {
+		b.WriteString(" ↑/↓: navigate | Enter: select | Ctrl+T: relative/absolute paths | Esc: back\n")
+	} else if m.mode == BrowseNavigate {
+		b.WriteString(" ↑/↓: navigate | Enter: open | /: search | *: filter | O: sort | 1-9: up N levels | J: recent | b/B: bookmark/bookmarks | a-z: jump ('x for bound keys) | y: copy contents | o: open | E: edit | Y: copy scp command | c/C: checksum/compare (C on a directory: diff) | X: chmod | r: retry | Esc: quit\n")
+	} else if m.mode == BrowseSaveAs {
+		b.WriteString(" ↑/↓: navigate | Enter: open, or name after a file | s: new file here | *: filter | O: sort | 1-9: up N levels | J: recent | b/B: bookmark/bookmarks | r: retry | Esc: cancel\n")
 	} else if m.mode == BrowseDirectories {
-		b.WriteString(" ↑/↓: navigate | Enter: open | s: select | r: retry | Esc: cancel\n")
+		b.WriteString(" ↑/↓: navigate | Enter: open | s: select | *: filter | O: sort | 1-9: up N levels | J: recent | b/B: bookmark/bookmarks | C: compare with local | r: retry | Esc: cancel\n")
 	} else {
-		b.WriteString(" ↑/↓: navigate | Enter: select | /: search | r: retry | Esc: cancel\n")
+		b.WriteString(" ↑/↓: navigate | Enter: select | /: search | *: filter | O: sort | 1-9: up N levels | J: recent | b/B: bookmark/bookmarks | a-z: jump ('x for bound keys) | y: copy contents | o: open | E: edit | Y: copy scp command | c/C: checksum/compare (C on a directory: diff) | X: chmod | r: retry | Esc: cancel\n")
 	}
 
 	return b.String()
 }
 
+// breadcrumbSegment is one directory of the current path
+type breadcrumbSegment struct {
+	name string
+	path string
+}
+
+// breadcrumbSegments splits dir into its segments, from the root down to dir itself
+func breadcrumbSegments(dir string) []breadcrumbSegment {
+	if dir == "" {
+		return nil
+	}
+	dir = path.Clean(dir)
+
+	var segments []breadcrumbSegment
+	current := ""
+	if strings.HasPrefix(dir, "/") {
+		segments = append(segments, breadcrumbSegment{name: "/", path: "/"})
+		current = "/"
+	}
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		current = path.Join(current, part)
+		segments = append(segments, breadcrumbSegment{name: part, path: current})
+	}
+	return segments
+}
+
+// breadcrumbAncestor returns the directory levels above dir, as selected with the number keys
+func breadcrumbAncestor(dir string, levels int) (string, bool) {
+	segments := breadcrumbSegments(dir)
+	index := len(segments) - 1 - levels
+	if levels < 1 || index < 0 {
+		return "", false
+	}
+	return segments[index].path, true
+}
+
+// renderBreadcrumb renders the current path with ancestors labelled by their
+// number key (1 = parent, 2 = grandparent, ...)
+func (m *remoteBrowserModel) renderBreadcrumb() string {
+	segments := breadcrumbSegments(m.currentDir)
+
+	var parts []string
+	for i, seg := range segments {
+		levels := len(segments) - 1 - i
+		name := seg.name
+		if levels >= 1 && levels <= 9 {
+			name = m.styles.HelpText.Render(fmt.Sprintf("%d:", levels)) + m.styles.DirStyle.Render(name)
+		} else {
+			name = m.styles.DirStyle.Render(name)
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, " › ")
+}
+
 // ANSI escape codes for fast rendering (avoid lipgloss.Render in hot loop)
 const (
 	ansiReset    = "\x1b[0m"
@@ -636,6 +1787,61 @@ const (
 	ansiDir      = "\x1b[38;5;39m"                 // blue (matches DirStyle)
 )
 
+// Widths of the size and modified columns of the listing
+const (
+	sizeColumnWidth = 8  // "1023.9KB"
+	timeColumnWidth = 14 // "59 seconds ago"
+)
+
+// listColumns returns the width left for names in a listing of the terminal
+// width, and whether the size and modified columns fit next to them
+func (m *remoteBrowserModel) listColumns() (nameWidth int, showSize, showTime bool) {
+	// Indent, icon and the space after it
+	nameWidth = m.width - 6
+	if nameWidth >= 20+sizeColumnWidth+1 {
+		showSize = true
+		nameWidth -= sizeColumnWidth + 1
+	}
+	if showSize && nameWidth >= 20+timeColumnWidth+2 {
+		showTime = true
+		nameWidth -= timeColumnWidth + 2
+	}
+	if nameWidth < 20 {
+		nameWidth = 20
+	}
+	return nameWidth, showSize, showTime
+}
+
+// fitName pads name to width, keeping its start and ending it with "..."
+// when it is longer
+func fitName(name string, width int) string {
+	runes := []rune(name)
+	if len(runes) > width {
+		return string(runes[:width-3]) + "..."
+	}
+	return name + strings.Repeat(" ", width-len(runes))
+}
+
+// fitPathEnd pads path to width, keeping its end and starting it with "..."
+// when it is longer
+func fitPathEnd(path string, width int) string {
+	runes := []rune(path)
+	if len(runes) > width {
+		return "..." + string(runes[len(runes)-width+3:])
+	}
+	return path + strings.Repeat(" ", width-len(runes))
+}
+
+// sizeColumn returns the right-aligned size of a file, blank for directories
+// and entries listed without attributes
+func sizeColumn(file transfer.RemoteFile) string {
+	size := ""
+	if !file.IsDir && (file.Info != nil || file.Size > 0) {
+		size = transfer.FormatByteSize(file.Size)
+	}
+	return fmt.Sprintf("%*s", sizeColumnWidth, size)
+}
+
 func (m *remoteBrowserModel) renderFileLine(file transfer.RemoteFile, selected bool) string {
 	var icon, name string
 
@@ -650,21 +1856,29 @@ func (m *remoteBrowserModel) renderFileLine(file transfer.RemoteFile, selected b
 		name = file.Name
 	}
 
-	// Simple truncation
-	if len(name) > 40 {
-		name = name[:37] + "..."
+	line := "  " + icon + " " + name
+	if file.Name != ".." {
+		nameWidth, showSize, showTime := m.listColumns()
+		line = "  " + icon + " " + fitName(name, nameWidth)
+		if showSize {
+			line += " " + sizeColumn(file)
+		}
+		if showTime && !file.ModTime.IsZero() {
+			line += "  " + fmt.Sprintf("%-*s", timeColumnWidth, formatTimeAgo(file.ModTime))
+		}
 	}
 
 	if selected {
-		return ansiSelected + "  " + icon + " " + name + ansiReset
+		return ansiSelected + line + ansiReset
 	}
 	if file.IsDir {
-		return ansiDir + "  " + icon + " " + name + ansiReset
+		return ansiDir + line + ansiReset
 	}
-	return "  " + icon + " " + name
+	return line
 }
 
-// renderSearchResultLine renders a search result showing the full path
+// renderSearchResultLine renders a search result showing the full path,
+// or the path relative to the current directory when relativePaths is on
 func (m *remoteBrowserModel) renderSearchResultLine(file transfer.RemoteFile, selected bool) string {
 	icon := "📁"
 	if !file.IsDir {
@@ -672,36 +1886,48 @@ func (m *remoteBrowserModel) renderSearchResultLine(file transfer.RemoteFile, se
 	}
 
 	path := file.Path
-	if len(path) > 50 {
-		path = "..." + path[len(path)-47:]
+	if m.relativePaths {
+		path = relativeDisplayPath(m.currentDir, file.Path)
+	}
+
+	pathWidth, showSize, _ := m.listColumns()
+	line := "  " + icon + " " + fitPathEnd(path, pathWidth)
+	if showSize {
+		line += " " + sizeColumn(file)
 	}
 
 	if selected {
-		return ansiSelected + "  " + icon + " " + path + ansiReset
+		return ansiSelected + line + ansiReset
 	}
 	if file.IsDir {
-		return ansiDir + "  " + icon + " " + path + ansiReset
+		return ansiDir + line + ansiReset
 	}
-	return "  " + icon + " " + path
+	return line
 }
 
-func formatSize(size int64) string {
-	const (
-		KB = 1024
-		MB = KB * 1024
-		GB = MB * 1024
-	)
+// remoteErrorText formats a remote error, telling the user how to retry when
+// the remote command timed out
+func remoteErrorText(err error, retryHint string) string {
+	if transfer.IsTimeout(err) {
+		return err.Error() + " (" + retryHint + ")"
+	}
+	return err.Error()
+}
 
-	switch {
-	case size >= GB:
-		return fmt.Sprintf("%.1fG", float64(size)/float64(GB))
-	case size >= MB:
-		return fmt.Sprintf("%.1fM", float64(size)/float64(MB))
-	case size >= KB:
-		return fmt.Sprintf("%.1fK", float64(size)/float64(KB))
-	default:
-		return fmt.Sprintf("%dB", size)
+// relativeDisplayPath returns p relative to base when p is inside base,
+// otherwise p unchanged. Only used for display; selection keeps the absolute path.
+func relativeDisplayPath(base, p string) string {
+	if base == "" || !strings.HasPrefix(p, "/") || !strings.HasPrefix(base, "/") {
+		return p
+	}
+	base = path.Clean(base)
+	if base == "/" {
+		return strings.TrimPrefix(p, "/")
 	}
+	if strings.HasPrefix(p, base+"/") {
+		return "./" + strings.TrimPrefix(p, base+"/")
+	}
+	return p
 }
 
 // Standalone browser for CLI use
@@ -744,13 +1970,22 @@ func (m standaloneRemoteBrowser) View() string {
 
 // RunRemoteBrowser runs the remote browser as a standalone TUI and returns the selected path
 func RunRemoteBrowser(host, startPath, configFile string, mode BrowserMode) (string, bool, error) {
-	styles := NewStyles(80)
-	browser := NewRemoteBrowser(host, startPath, configFile, mode, styles, 80, 24)
+	return runRemoteBrowser(NewRemoteBrowser(host, startPath, configFile, mode, NewStyles(80), 80, 24))
+}
+
+// RunRemoteSaveAs runs the remote browser in BrowseSaveAs mode, offering name
+// for the new file, and returns its path
+func RunRemoteSaveAs(host, startPath, configFile, name string) (string, bool, error) {
+	browser := NewRemoteBrowser(host, startPath, configFile, BrowseSaveAs, NewStyles(80), 80, 24)
+	browser.nameDefault = name
+	return runRemoteBrowser(browser)
+}
+
+// runRemoteBrowser runs a browser as its own program and returns its selection
+func runRemoteBrowser(browser *remoteBrowserModel) (string, bool, error) {
 	m := standaloneRemoteBrowser{browser}
 
-	p := tea.NewProgram(m,
-		tea.WithAltScreen(),
-	)
+	p := newProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
 		return "", false, err
@@ -0,0 +1,949 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRelativeDisplayPath(t *testing.T) {
+	tests := []struct {
+		base     string
+		path     string
+		expected string
+	}{
+		{"/var/www", "/var/www/app/index.php", "./app/index.php"},
+		{"/var/www/", "/var/www/app", "./app"},
+		{"/var/www", "/var/wwwroot/file", "/var/wwwroot/file"},
+		{"/var/www", "/etc/hosts", "/etc/hosts"},
+		{"/", "/etc/hosts", "etc/hosts"},
+		{"/mnt//nas/./media", "/mnt/nas/media/films", "./films"},
+		{"", "/etc/hosts", "/etc/hosts"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeDisplayPath(tt.base, tt.path); got != tt.expected {
+			t.Errorf("relativeDisplayPath(%q, %q) = %q, want %q", tt.base, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestRemoteBrowserFileColumns(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := NewRemoteBrowser("server1", "/srv", "", BrowseFiles, NewStyles(100), 100, 24)
+	file := transfer.RemoteFile{Name: "access.log", Path: "/srv/access.log", Size: 1536, ModTime: time.Now().Add(-3 * time.Hour)}
+	dir := transfer.RemoteFile{Name: "logs", Path: "/srv/logs", IsDir: true, ModTime: time.Now().Add(-2 * 24 * time.Hour)}
+
+	line := m.renderFileLine(file, false)
+	if !strings.Contains(line, "  1.5KB  3 hours ago") {
+		t.Errorf("Expected the size and age columns, got %q", line)
+	}
+	if got := len([]rune(strings.TrimRight(line, " "))); got > m.width {
+		t.Errorf("Expected the line to fit in %d columns, got %d", m.width, got)
+	}
+	// Columns line up whatever the name length
+	other := m.renderFileLine(transfer.RemoteFile{Name: "a", Size: 10, ModTime: file.ModTime}, false)
+	if strings.Index(line, "1.5KB")+len("1.5KB") != strings.Index(other, "10B")+len("10B") {
+		t.Errorf("Expected right-aligned sizes, got:\n%q\n%q", line, other)
+	}
+	if line := m.renderFileLine(dir, false); strings.Contains(line, "B ") || !strings.Contains(line, "2 days ago") {
+		t.Errorf("Expected a directory without size but with its age, got %q", line)
+	}
+
+	// Narrow terminals drop the age, then the size
+	m.width = 45
+	if line := m.renderFileLine(file, false); !strings.Contains(line, "1.5KB") || strings.Contains(line, "ago") {
+		t.Errorf("Expected only the size at 45 columns, got %q", line)
+	}
+	m.width = 30
+	if line := m.renderFileLine(file, false); strings.Contains(line, "1.5KB") {
+		t.Errorf("Expected no columns at 30 columns, got %q", line)
+	}
+
+	// Long names are cut to their column
+	m.width = 100
+	long := transfer.RemoteFile{Name: strings.Repeat("x", 120) + ".log", Size: 1, ModTime: file.ModTime}
+	if line := m.renderFileLine(long, false); !strings.Contains(line, "...       1B") {
+		t.Errorf("Expected the name to be truncated before the size, got %q", line)
+	}
+
+	// Search results show the size when it is known
+	m.searchMode = true
+	if line := m.renderSearchResultLine(file, false); !strings.Contains(line, "/srv/access.log") || !strings.Contains(line, "1.5KB") {
+		t.Errorf("Expected the path and size of the result, got %q", line)
+	}
+	if line := m.renderSearchResultLine(transfer.RemoteFile{Name: "a", Path: "/srv/a"}, false); strings.Contains(line, "0B") {
+		t.Errorf("Expected no size for a result listed without attributes, got %q", line)
+	}
+}
+
+func TestRemoteBrowserRelativeSearchResults(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := NewRemoteBrowser("server1", "/var/www", "", BrowseFiles, NewStyles(80), 80, 24)
+	m.currentDir = "/var/www"
+	m.loading = false
+	m.searchMode = true
+	m.searchFiles = []transfer.RemoteFile{
+		{Name: "index.php", Path: "/var/www/app/index.php"},
+	}
+
+	if line := m.renderSearchResultLine(m.searchFiles[0], false); !strings.Contains(line, "/var/www/app/index.php") {
+		t.Errorf("Expected absolute path by default, got %q", line)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if !m.relativePaths {
+		t.Fatal("Expected ctrl+t to enable relative paths")
+	}
+	line := m.renderSearchResultLine(m.searchFiles[0], false)
+	if !strings.Contains(line, "./app/index.php") || strings.Contains(line, "/var/www/") {
+		t.Errorf("Expected relative path, got %q", line)
+	}
+
+	// Selecting a result still returns the absolute path
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command when selecting a search result")
+	}
+	result, ok := cmd().(remoteBrowserResultMsg)
+	if !ok {
+		t.Fatal("Expected remoteBrowserResultMsg")
+	}
+	if result.path != "/var/www/app/index.php" || !result.selected {
+		t.Errorf("Expected absolute path selection, got %+v", result)
+	}
+}
+
+func TestBreadcrumbSegments(t *testing.T) {
+	segments := breadcrumbSegments("/var/log/nginx/")
+	expected := []breadcrumbSegment{
+		{name: "/", path: "/"},
+		{name: "var", path: "/var"},
+		{name: "log", path: "/var/log"},
+		{name: "nginx", path: "/var/log/nginx"},
+	}
+	if len(segments) != len(expected) {
+		t.Fatalf("Expected %d segments, got %v", len(expected), segments)
+	}
+	for i := range expected {
+		if segments[i] != expected[i] {
+			t.Errorf("Segment %d = %+v, want %+v", i, segments[i], expected[i])
+		}
+	}
+
+	if got := breadcrumbSegments("/"); len(got) != 1 || got[0].path != "/" {
+		t.Errorf("Expected only the root segment, got %v", got)
+	}
+
+	// Remote paths are always slash separated and cleaned, whatever the local OS
+	got := breadcrumbSegments("/mnt//nas/./media/../backups")
+	if last := got[len(got)-1]; len(got) != 4 || last.path != "/mnt/nas/backups" {
+		t.Errorf("Expected /mnt/nas/backups as the last of 4 segments, got %v", got)
+	}
+}
+
+func TestBreadcrumbAncestor(t *testing.T) {
+	tests := []struct {
+		dir      string
+		levels   int
+		expected string
+		ok       bool
+	}{
+		{"/var/log/nginx", 1, "/var/log", true},
+		{"/var/log/nginx", 2, "/var", true},
+		{"/var/log/nginx", 3, "/", true},
+		{"/var/log/nginx", 4, "", false},
+		{"/var/log/nginx", 0, "", false},
+		{"/", 1, "", false},
+		{"home/user", 1, "home", true},
+	}
+
+	for _, tt := range tests {
+		got, ok := breadcrumbAncestor(tt.dir, tt.levels)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("breadcrumbAncestor(%q, %d) = (%q, %v), want (%q, %v)", tt.dir, tt.levels, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestRemoteBrowserLogDirectorySortsNewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	older := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+	listing := []transfer.RemoteFile{
+		{Name: "access.log", ModTime: older},
+		{Name: "error.log", ModTime: newer},
+	}
+
+	tests := []struct {
+		setting string
+		dir     string
+		first   string
+	}{
+		{config.RemoteSortAuto, "/var/log/nginx", "error.log"},
+		{config.RemoteSortAuto, "/srv/data", "access.log"},
+		{config.RemoteSortName, "/var/log/nginx", "access.log"},
+		{config.RemoteSortModified, "/srv/data", "error.log"},
+	}
+
+	for _, tt := range tests {
+		m := NewRemoteBrowser("server1", tt.dir, "", BrowseFiles, NewStyles(80), 80, 24)
+		m.sortSetting = tt.setting
+
+		files := append([]transfer.RemoteFile(nil), listing...)
+		m, _ = m.Update(remoteBrowserLoadedMsg{files: files, dir: tt.dir, id: m.listingID})
+
+		if got := m.visibleFiles[0].Name; got != tt.first {
+			t.Errorf("setting %q in %s: first entry = %q, want %q", tt.setting, tt.dir, got, tt.first)
+		}
+	}
+}
+
+func TestRemoteBrowserChecksumUsesCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := NewRemoteBrowser("server1", "/srv", "", BrowseFiles, NewStyles(80), 80, 24)
+	m.loading = false
+	m.session = &transfer.SFTPSession{}
+	file := transfer.RemoteFile{Name: "app.tar", Path: "/srv/app.tar", Size: 42, ModTime: time.Unix(1700000000, 0)}
+	m.files = []transfer.RemoteFile{file}
+	m.filterFiles()
+
+	sum := strings.Repeat("ab", 32)
+	m, _ = m.Update(remoteBrowserChecksumMsg{file: file, sum: sum})
+	if !strings.Contains(m.status, sum) {
+		t.Fatalf("Expected the checksum in the status, got %q", m.status)
+	}
+
+	m.status = ""
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if cmd != nil {
+		t.Error("Expected the cached checksum to be reused without running a command")
+	}
+	if !strings.Contains(m.status, sum) {
+		t.Errorf("Expected the cached checksum in the status, got %q", m.status)
+	}
+
+	// A file changed since is hashed again
+	changed := file
+	changed.Size = 43
+	if _, ok := m.checksums[checksumKey(changed)]; ok {
+		t.Error("Expected a changed file to miss the cache")
+	}
+}
+
+func TestChecksumResultText(t *testing.T) {
+	file := transfer.RemoteFile{Name: "app.tar", Path: "/srv/app.tar"}
+	sum := strings.Repeat("ab", 32)
+	other := strings.Repeat("cd", 32)
+
+	errText, status := checksumResultText(remoteBrowserChecksumMsg{file: file, sum: sum, localPath: "./app.tar", localSum: strings.ToUpper(sum)})
+	if errText != "" || !strings.Contains(status, "matches ./app.tar") {
+		t.Errorf("Expected a match, got error %q, status %q", errText, status)
+	}
+
+	errText, status = checksumResultText(remoteBrowserChecksumMsg{file: file, sum: sum, localPath: "./app.tar", localSum: other})
+	if status != "" || !strings.Contains(errText, "differs from ./app.tar") {
+		t.Errorf("Expected a mismatch, got error %q, status %q", errText, status)
+	}
+
+	errText, status = checksumResultText(remoteBrowserChecksumMsg{file: file, sum: sum})
+	if errText != "" || status != "SHA-256 app.tar: "+sum {
+		t.Errorf("Expected the plain checksum, got error %q, status %q", errText, status)
+	}
+}
+
+func TestRemoteBrowserComparePrompt(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := NewRemoteBrowser("server1", "/srv", "", BrowseFiles, NewStyles(80), 80, 24)
+	m.loading = false
+	m.session = &transfer.SFTPSession{}
+	m.files = []transfer.RemoteFile{{Name: "app.tar", Path: "/srv/app.tar"}}
+	m.filterFiles()
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	if !m.compareMode || m.comparePath != "./app.tar" {
+		t.Fatalf("Expected the compare prompt prefilled with ./app.tar, got mode %v path %q", m.compareMode, m.comparePath)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	if m.comparePath != "./app.taz" {
+		t.Errorf("Expected typed path ./app.taz, got %q", m.comparePath)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.compareMode || cmd == nil {
+		t.Error("Expected Enter to start the comparison")
+	}
+}
+
+func TestRemoteBrowserCompareDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := NewRemoteBrowser("server1", "/srv", "", BrowseFiles, NewStyles(100), 100, 30)
+	m.loading = false
+	m.session = &transfer.SFTPSession{}
+	dir := transfer.RemoteFile{Name: "site", Path: "/srv/site", IsDir: true}
+	m.files = []transfer.RemoteFile{dir}
+	m.filterFiles()
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	if !m.compareMode || m.comparePath != "./site" || !strings.Contains(m.View(), "local directory") {
+		t.Fatalf("Expected the directory compare prompt, got mode %v path %q", m.compareMode, m.comparePath)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	modified := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	comparison := transfer.CompareListings(
+		[]transfer.RemoteFile{{Name: "index.html", Size: 2048, ModTime: modified}, {Name: "old.css", Size: 10}},
+		[]transfer.FileInfo{{Name: "index.html", Size: 1024, ModTime: modified}, {Name: "new.js", Size: 5}},
+	)
+	comparison.RemotePath, comparison.LocalPath = "/srv/site", "./site"
+	m, _ = m.Update(remoteBrowserDirCompareMsg{dir: dir, comparison: comparison})
+
+	view := m.View()
+	for _, want := range []string{"Remote: /srv/site", "Local: ./site", "index.html  2.0KB", "index.html  1.0KB", "old.css", "new.js", "1 changed, 1 only remote, 1 only local, 0 identical"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the comparison, got:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.dirCompare != nil || !strings.Contains(m.View(), "site/") {
+		t.Error("Expected Esc to go back to the listing")
+	}
+}
+
+func TestRemoteBrowserChmod(t *testing.T) {
+	m := typeAheadBrowser(t, "..", "deploy.sh", "site")
+	m.session = &transfer.SFTPSession{}
+	m.files[2].IsDir = true
+	m.filterFiles()
+	press := func(key string) {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	// A file without the execute bit is offered +x
+	m.cursor = 1
+	press("X")
+	if !m.chmodMode || m.chmodInput != "+x" || !strings.Contains(m.View(), "New mode of deploy.sh: +x_") {
+		t.Fatalf("Expected the mode prompt prefilled with +x, got mode %v input %q", m.chmodMode, m.chmodInput)
+	}
+
+	// An invalid mode keeps the prompt open
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	press("9")
+	press("9")
+	press("9")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !m.chmodMode || !strings.Contains(m.err, "invalid mode") {
+		t.Fatalf("Expected 999 to be rejected, got mode %v err %q", m.chmodMode, m.err)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.chmodMode {
+		t.Fatal("Expected Esc to cancel the mode prompt")
+	}
+
+	// A directory asks before going recursive
+	m.cursor = 2
+	press("X")
+	press("7")
+	press("5")
+	press("5")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !m.chmodRecursive || !strings.Contains(m.View(), "Apply 755 to everything inside site/ too?") {
+		t.Fatalf("Expected the recursive confirmation, got recursive %v", m.chmodRecursive)
+	}
+	press("n")
+	if m.chmodRecursive {
+		t.Fatal("Expected n to apply the mode to the directory only")
+	}
+
+	// The reload after a success keeps the confirmation and the cursor
+	m.cursor = 0
+	m.loading = true
+	m.reloadStatus = chmodStatus(remoteBrowserChmodMsg{file: m.files[2], mode: "755"})
+	m.reloadCursor = "site"
+	m, _ = m.Update(remoteBrowserLoadedMsg{
+		dir: "/srv",
+		id:  m.listingID,
+		files: []transfer.RemoteFile{
+			{Name: "..", Path: "/", IsDir: true},
+			{Name: "deploy.sh", Path: "/srv/deploy.sh"},
+			{Name: "site", Path: "/srv/site", IsDir: true},
+		},
+	})
+	if m.status != "✓ Mode of site set to 755" {
+		t.Errorf("Expected the confirmation to survive the reload, got %q", m.status)
+	}
+	if m.visibleFiles[m.cursor].Name != "site" {
+		t.Errorf("Expected the cursor to stay on site, got %q", m.visibleFiles[m.cursor].Name)
+	}
+
+	// A failure is reported without reloading
+	m, cmd = m.Update(remoteBrowserChmodMsg{file: m.files[1], mode: "+x", err: errors.New("chmod failed: Operation not permitted")})
+	if cmd != nil || !strings.Contains(m.err, "Operation not permitted") {
+		t.Errorf("Expected the chmod error, got %q", m.err)
+	}
+}
+
+func TestRemoteBrowserBookmarks(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	m := typeAheadBrowser(t, "..", "app")
+	press := func(key string) {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	press("B")
+	if m.bookmarkMode || !strings.Contains(m.status, "No bookmarks") {
+		t.Fatalf("Expected no bookmark list before bookmarking, got status %q", m.status)
+	}
+
+	press("b")
+	if m.status != "★ Bookmarked /srv" {
+		t.Errorf("Expected the current directory to be bookmarked, got status %q", m.status)
+	}
+	press("b")
+	if !strings.Contains(m.status, "already bookmarked") {
+		t.Errorf("Expected a second b to keep one bookmark, got status %q", m.status)
+	}
+
+	// Bookmarks are kept in the history file for the next sessions
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatalf("Failed to open the history: %v", err)
+	}
+	if got := hm.GetRemoteBookmarks("server1"); len(got) != 1 || got[0] != "/srv" {
+		t.Fatalf("Expected /srv bookmarked in the history, got %v", got)
+	}
+
+	press("B")
+	if !m.bookmarkMode || !strings.Contains(m.View(), "Bookmarks:") {
+		t.Fatal("Expected B to list the bookmarks")
+	}
+	press("d")
+	if m.bookmarkMode || len(m.historyManager.GetRemoteBookmarks("server1")) != 0 {
+		t.Errorf("Expected d to remove the last bookmark and close the list")
+	}
+}
+
+func TestSCPGrabCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		configFile string
+		file       transfer.RemoteFile
+		expected   string
+	}{
+		{
+			name:     "file",
+			host:     "web",
+			file:     transfer.RemoteFile{Name: "app.log", Path: "/var/log/app.log"},
+			expected: "scp web:/var/log/app.log ./",
+		},
+		{
+			name:       "custom config",
+			host:       "web",
+			configFile: "/home/me/.ssh/work config",
+			file:       transfer.RemoteFile{Name: "app.log", Path: "/var/log/app.log"},
+			expected:   "scp -F '/home/me/.ssh/work config' web:/var/log/app.log ./",
+		},
+		{
+			name:     "directory",
+			host:     "web",
+			file:     transfer.RemoteFile{Name: "www", Path: "/srv/www", IsDir: true},
+			expected: "scp -r web:/srv/www ./",
+		},
+		{
+			name:     "quoted path",
+			host:     "web",
+			file:     transfer.RemoteFile{Name: "it's.txt", Path: "/srv/it's.txt"},
+			expected: `scp 'web:/srv/it'\''s.txt' ./`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := scpGrabCommand(tt.host, tt.configFile, tt.file); got != tt.expected {
+			t.Errorf("%s: scpGrabCommand() = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+// typeAheadBrowser returns a browser showing a fixed listing in the given order
+func typeAheadBrowser(t *testing.T, names ...string) *remoteBrowserModel {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := NewRemoteBrowser("server1", "/srv", "", BrowseFiles, NewStyles(80), 80, 24)
+	m.loading = false
+	for _, name := range names {
+		m.files = append(m.files, transfer.RemoteFile{Name: name, Path: "/srv/" + name})
+	}
+	m.filterFiles()
+	return m
+}
+
+func TestRemoteBrowserTypeAheadJump(t *testing.T) {
+	m := typeAheadBrowser(t, "..", "apache", "data", "Dev", "deploy.sh", "etc", "ext")
+	press := func(key string) {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	press("d")
+	if m.cursor != 2 {
+		t.Fatalf("Expected d to jump to data, got cursor %d", m.cursor)
+	}
+
+	// Repeating cycles through the matches, ignoring case, and wraps around
+	for _, want := range []int{3, 4, 2} {
+		press("d")
+		if m.cursor != want {
+			t.Errorf("Expected d to cycle to %d, got %d", want, m.cursor)
+		}
+	}
+
+	// A different letter starts from the top
+	press("e")
+	if m.cursor != 5 {
+		t.Errorf("Expected e to jump to etc, got %d", m.cursor)
+	}
+
+	press("z")
+	if m.cursor != 5 || !strings.Contains(m.status, "No entry starting with 'z'") {
+		t.Errorf("Expected the cursor to stay with a status, got %d %q", m.cursor, m.status)
+	}
+}
+
+func TestRemoteBrowserTypeAheadRespectsSort(t *testing.T) {
+	// Matches are found in display order, here newest first as in a log directory
+	m := typeAheadBrowser(t, "..", "deploy-3", "app", "deploy-1")
+	m.sortMode = remoteSortModifiedDesc
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if m.visibleFiles[m.cursor].Name != "deploy-3" {
+		t.Errorf("Expected the first match in display order, got %s", m.visibleFiles[m.cursor].Name)
+	}
+}
+
+func TestRemoteBrowserTypeAheadBoundKeys(t *testing.T) {
+	m := typeAheadBrowser(t, "..", "cache", "config", "srv")
+
+	// c shows a checksum, so ' is needed to jump to it
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("'")})
+	if !m.initialPending {
+		t.Fatal("Expected ' to wait for a character")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if m.initialPending || m.cursor != 1 {
+		t.Errorf("Expected 'c to jump to cache, got cursor %d", m.cursor)
+	}
+	if m.status != "" {
+		t.Errorf("Expected no checksum to be computed, got status %q", m.status)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("'")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if m.cursor != 2 {
+		t.Errorf("Expected 'c again to cycle to config, got cursor %d", m.cursor)
+	}
+}
+
+func TestRemoteBrowserNavigateModeSelectsNothing(t *testing.T) {
+	m := typeAheadBrowser(t, "app.log")
+	m.mode = BrowseNavigate
+
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyEnter}, {Type: tea.KeyRunes, Runes: []rune("s")}} {
+		if _, cmd := m.Update(key); cmd != nil {
+			t.Errorf("Expected %q not to select anything in navigate mode", key.String())
+		}
+	}
+	if !strings.Contains(m.View(), "Esc: quit") {
+		t.Error("Expected the navigate mode help line")
+	}
+}
+
+func TestRemoteBrowserShowsAuthMethod(t *testing.T) {
+	m := typeAheadBrowser(t, "app.log")
+	m.authMethod = "SSH agent key me@laptop (ssh-ed25519)"
+
+	if strings.Contains(m.View(), "Logged in with") {
+		t.Error("The auth method should only be shown with show_auth_method")
+	}
+	m.showAuthMethod = true
+	if !strings.Contains(m.View(), "Logged in with SSH agent key me@laptop (ssh-ed25519)") {
+		t.Error("Expected the auth method in the header")
+	}
+}
+
+func TestRemoteBrowserRemovesOpenedFilesOnClose(t *testing.T) {
+	m := typeAheadBrowser(t, "report.pdf")
+	m.openDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(m.openDir, "report.pdf"), []byte("%PDF"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dir := m.openDir
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary copies to be removed when the browser closes, got %v", err)
+	}
+}
+
+func TestRemoteBrowserOpenedStatus(t *testing.T) {
+	m := typeAheadBrowser(t, "report.pdf")
+
+	m.Update(remoteBrowserOpenedMsg{name: "report.pdf"})
+	if !strings.Contains(m.View(), "Opened report.pdf") {
+		t.Errorf("Expected the opened status, got:\n%s", m.View())
+	}
+
+	m.Update(remoteBrowserOpenedMsg{name: "report.pdf", err: errors.New("xdg-open not found")})
+	if !strings.Contains(m.View(), "cannot open report.pdf: xdg-open not found") {
+		t.Errorf("Expected the open error, got:\n%s", m.View())
+	}
+}
+
+func TestExtensionFilterMatches(t *testing.T) {
+	listing := []transfer.RemoteFile{
+		{Name: "..", IsDir: true},
+		{Name: "logs", IsDir: true},
+		{Name: ".config", IsDir: true},
+		{Name: ".hidden.log"},
+		{Name: "app.LOG"},
+		{Name: "data.tar.gz"},
+		{Name: "notes.txt"},
+		{Name: "log"},
+	}
+	kept := func(f extensionFilter) []string {
+		var names []string
+		for _, file := range listing {
+			if f.matches(file) {
+				names = append(names, file.Name)
+			}
+		}
+		return names
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", []string{"..", "logs", ".config", ".hidden.log", "app.LOG", "data.tar.gz", "notes.txt", "log"}},
+		{"*.log", []string{"..", "logs", ".config", ".hidden.log", "app.LOG"}},
+		{"log", []string{"..", "logs", ".config", ".hidden.log", "app.LOG"}},
+		{".tar.gz, *.txt", []string{"..", "logs", ".config", "data.tar.gz", "notes.txt"}},
+		{"*.zip", []string{"..", "logs", ".config"}},
+		{"[ *.txt", []string{"..", "logs", ".config", "notes.txt"}},
+	}
+	for _, tt := range tests {
+		got := kept(parseExtensionFilter(tt.input))
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("parseExtensionFilter(%q) keeps %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteBrowserExtensionFilter(t *testing.T) {
+	defer func() { lastRemoteFilter = extensionFilter{} }()
+
+	m := typeAheadBrowser(t, "..", "logs", ".hidden.log", "app.log", "data.tar.gz")
+	m.files[1].IsDir = true
+	m.filterFiles()
+	press := func(key string) {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	press("*")
+	if !m.filterMode {
+		t.Fatal("Expected * to open the filter prompt")
+	}
+	for _, r := range "*.log" {
+		press(string(r))
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	var names []string
+	for _, f := range m.visibleFiles {
+		names = append(names, f.Name)
+	}
+	// Hidden files stay hidden until '.' shows them
+	if got := strings.Join(names, ","); got != "..,logs,app.log" {
+		t.Errorf("Expected directories and *.log files, got %s", got)
+	}
+	if !strings.Contains(m.View(), "[filter: *.log]") {
+		t.Error("Expected the filter to be shown")
+	}
+
+	// The next browser opened keeps the filter
+	next := typeAheadBrowser(t, "..", "app.log", "data.tar.gz")
+	if len(next.visibleFiles) != 2 || next.filter.String() != "*.log" {
+		t.Errorf("Expected the filter to persist, got %v", next.visibleFiles)
+	}
+
+	// An empty filter shows every file again
+	press("*")
+	if m.filterInput != "*.log" {
+		t.Errorf("Expected the prompt to start from the filter, got %q", m.filterInput)
+	}
+	for range m.filterInput {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.filter.active() || len(m.visibleFiles) != 4 {
+		t.Errorf("Expected the filter to be cleared, got %d files", len(m.visibleFiles))
+	}
+}
+
+func TestRemoteBrowserExtensionFilterEmptyState(t *testing.T) {
+	defer func() { lastRemoteFilter = extensionFilter{} }()
+	lastRemoteFilter = parseExtensionFilter("*.zip")
+
+	m := typeAheadBrowser(t, "app.log", "data.tar.gz")
+	if view := m.View(); !strings.Contains(view, "No files match *.zip") {
+		t.Errorf("Expected the filter to be named when it hides everything, got:\n%s", view)
+	}
+}
+
+func TestSortRemoteFiles(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	listing := func() []transfer.RemoteFile {
+		return []transfer.RemoteFile{
+			{Name: "b.log", Size: 300, ModTime: base.Add(2 * time.Hour)},
+			{Name: "..", IsDir: true},
+			{Name: "src", IsDir: true, ModTime: base.Add(time.Hour)},
+			{Name: "A.txt", Size: 10, ModTime: base},
+			{Name: "c.bin", Size: 5000, ModTime: base.Add(3 * time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		mode remoteSortMode
+		want string
+	}{
+		{remoteSortName, "..,src,A.txt,b.log,c.bin"},
+		{remoteSortNameDesc, "..,src,c.bin,b.log,A.txt"},
+		{remoteSortSizeDesc, "..,src,c.bin,b.log,A.txt"},
+		{remoteSortSize, "..,src,A.txt,b.log,c.bin"},
+		{remoteSortModifiedDesc, "..,c.bin,b.log,src,A.txt"},
+		{remoteSortModified, "..,A.txt,src,b.log,c.bin"},
+	}
+	for _, tt := range tests {
+		files := listing()
+		sortRemoteFiles(files, tt.mode)
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name)
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("sortRemoteFiles(%s) = %s, want %s", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteBrowserCycleSort(t *testing.T) {
+	defer func() { lastRemoteSort, remoteSortChosen = remoteSortName, false }()
+
+	m := typeAheadBrowser(t, "..", "small", "large")
+	m.files[1].Size, m.files[2].Size = 1, 100
+	m.cursor = 1 // small
+
+	press := func() {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	}
+	press()
+	if m.sortMode != remoteSortNameDesc {
+		t.Fatalf("Expected O to sort Z to A, got %s", m.sortMode)
+	}
+	press()
+	if m.sortMode != remoteSortSizeDesc || m.visibleFiles[1].Name != "large" {
+		t.Fatalf("Expected largest first, got %s with %s second", m.sortMode, m.visibleFiles[1].Name)
+	}
+	if m.visibleFiles[m.cursor].Name != "small" {
+		t.Errorf("Expected the cursor to stay on small, got %s", m.visibleFiles[m.cursor].Name)
+	}
+	if !strings.Contains(m.View(), "[sort: largest first]") {
+		t.Error("Expected the order in the indicator")
+	}
+
+	// The chosen order is kept for the directories and browsers that follow
+	m, _ = m.Update(remoteBrowserLoadedMsg{dir: "/var/log", files: []transfer.RemoteFile{{Name: "a", Size: 1}, {Name: "b", Size: 2}}, id: m.listingID})
+	if m.sortMode != remoteSortSizeDesc {
+		t.Errorf("Expected the chosen order over the log directory default, got %s", m.sortMode)
+	}
+	if next := typeAheadBrowser(t, "x"); next.sortMode != remoteSortSizeDesc || !next.sortChosen {
+		t.Errorf("Expected a new browser to keep the order, got %s", next.sortMode)
+	}
+
+	for i := 0; i < 4; i++ {
+		press()
+	}
+	if m.sortMode != remoteSortName {
+		t.Errorf("Expected the cycle to come back to name, got %s", m.sortMode)
+	}
+}
+
+func TestRemoteBrowserEdited(t *testing.T) {
+	m := typeAheadBrowser(t, "..", "nginx.conf", "site")
+	file := m.files[1]
+
+	// Closing the editor without saving uploads nothing and keeps the listing
+	m, cmd := m.Update(remoteBrowserEditedMsg{file: file})
+	if cmd != nil || m.loading || m.status != "nginx.conf unchanged, nothing uploaded" {
+		t.Errorf("Expected no upload and no reload, got status %q", m.status)
+	}
+
+	m, _ = m.Update(remoteBrowserEditedMsg{file: file, err: errors.New("permission denied")})
+	if !strings.Contains(m.err, "cannot edit nginx.conf: permission denied") || m.status != "" {
+		t.Errorf("Expected the edit error, got err %q status %q", m.err, m.status)
+	}
+
+	if got := editStatus(remoteBrowserEditedMsg{file: file, uploaded: true}); got != "✓ Saved nginx.conf to the host" {
+		t.Errorf("editStatus() after an upload = %q", got)
+	}
+	if got := editStatus(remoteBrowserEditedMsg{file: file, sshfs: true}); got != "✓ Edited nginx.conf over SSHFS" {
+		t.Errorf("editStatus() over SSHFS = %q", got)
+	}
+}
+
+func TestSaveAsPath(t *testing.T) {
+	tests := []struct {
+		dir, name string
+		want      string
+		wantErr   bool
+	}{
+		{dir: "/srv/www", name: "index.html", want: "/srv/www/index.html"},
+		{dir: "/", name: "notes.txt", want: "/notes.txt"},
+		{dir: "~", name: " report 2024.pdf ", want: "~/report 2024.pdf"},
+		{dir: "/srv/www/", name: ".env", want: "/srv/www/.env"},
+		{dir: "/srv", name: "", wantErr: true},
+		{dir: "/srv", name: "  ", wantErr: true},
+		{dir: "/srv", name: "..", wantErr: true},
+		{dir: "/srv", name: "logs/app.log", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := saveAsPath(tt.dir, tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("saveAsPath(%q, %q) = %q, %v, want %q (error %v)", tt.dir, tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRemoteBrowserSaveAs(t *testing.T) {
+	m := typeAheadBrowser(t, "..", "logs", "app.conf")
+	m.files[1].IsDir = true
+	m.mode = BrowseSaveAs
+	m.filterFiles()
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		m, cmd = m.Update(msg)
+		return cmd
+	}
+
+	// Enter on a file starts from its name
+	m.cursor = 2
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.nameMode || m.nameDir != "/srv" || m.nameInput != "app.conf" {
+		t.Fatalf("Expected the name prompt prefilled with app.conf, got %v %q %q", m.nameMode, m.nameDir, m.nameInput)
+	}
+	if !strings.Contains(m.View(), "New file in /srv: app.conf_") {
+		t.Error("Expected the name prompt in the view")
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.nameMode {
+		t.Fatal("Expected Esc to go back to the listing")
+	}
+
+	// s types a name in the current directory
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if cmd := press(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || m.err == "" || !m.nameMode {
+		t.Fatal("Expected an empty name to be refused")
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("app.conf.new")})
+	cmd := press(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected Enter to select the new file")
+	}
+	if msg, ok := cmd().(remoteBrowserResultMsg); !ok || msg.path != "/srv/app.conf.new" || !msg.selected {
+		t.Errorf("Expected /srv/app.conf.new to be selected, got %+v", msg)
+	}
+
+	// s starts from the name of the file uploaded
+	m.nameMode = false
+	m.nameDefault = "report.pdf"
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if !m.nameMode || m.nameInput != "report.pdf" {
+		t.Errorf("Expected the name prompt prefilled with report.pdf, got %v %q", m.nameMode, m.nameInput)
+	}
+}
+
+func TestKeepEditedCopy(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	openDir := t.TempDir()
+	localPath := filepath.Join(openDir, "nginx.conf")
+	if err := os.WriteFile(localPath, []byte("edited"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	kept, err := keepEditedCopy(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(kept) == openDir || filepath.Base(kept) != "nginx.conf" {
+		t.Errorf("Expected the copy moved out of the browser's directory, got %s", kept)
+	}
+	// Closing the browser removes its directory, the changes stay
+	os.RemoveAll(openDir)
+	if data, err := os.ReadFile(kept); err != nil || string(data) != "edited" {
+		t.Errorf("Expected the edits kept in %s, got %q (%v)", kept, data, err)
+	}
+}
+
+func TestRemoteBrowserHandsOverSession(t *testing.T) {
+	session := &transfer.SFTPSession{}
+	m := typeAheadBrowser(t, "app.log")
+	m.session = session
+	m.keepSession = true
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected picking a file to finish the browser")
+	}
+	result, ok := cmd().(remoteBrowserResultMsg)
+	if !ok || !result.selected || result.path != "/srv/app.log" {
+		t.Fatalf("Expected /srv/app.log to be picked, got %+v", result)
+	}
+	if result.session != session || m.session != nil {
+		t.Error("Expected the session to be handed over with the result")
+	}
+}
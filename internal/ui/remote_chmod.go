@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// remoteBrowserChmodMsg carries the result of a mode change
+type remoteBrowserChmodMsg struct {
+	file      transfer.RemoteFile
+	mode      string
+	recursive bool
+	err       error
+}
+
+// chmod changes the mode of file on the remote host
+func (m *remoteBrowserModel) chmod(file transfer.RemoteFile, mode string, recursive bool) tea.Cmd {
+	session := m.session
+	m.status = fmt.Sprintf("Changing the mode of %s...", file.Name)
+	return func() tea.Msg {
+		err := session.Chmod(file.Path, mode, recursive)
+		return remoteBrowserChmodMsg{file: file, mode: mode, recursive: recursive, err: err}
+	}
+}
+
+// defaultChmodInput prefills the mode prompt: toggling the execute bit of a
+// file, the current octal mode of a directory
+func defaultChmodInput(file transfer.RemoteFile) string {
+	if file.IsDir {
+		if file.Info != nil {
+			return fmt.Sprintf("%o", file.Info.Mode().Perm())
+		}
+		return ""
+	}
+	if file.Info != nil && file.Info.Mode()&0111 != 0 {
+		return "-x"
+	}
+	return "+x"
+}
+
+// chmodStatus confirms a mode change once the listing shows it
+func chmodStatus(msg remoteBrowserChmodMsg) string {
+	if msg.recursive {
+		return fmt.Sprintf("✓ Mode of %s/ and everything inside set to %s", msg.file.Name, msg.mode)
+	}
+	return fmt.Sprintf("✓ Mode of %s set to %s", msg.file.Name, msg.mode)
+}
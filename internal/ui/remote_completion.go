@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxCompletionHint caps how many candidates are listed under the remote path field
+const maxCompletionHint = 6
+
+// remoteCompletion holds the Tab completion state of the transfer form's remote path field
+type remoteCompletion struct {
+	session  *transfer.SFTPSession            // Reused for every completion until the form closes
+	listings map[string][]transfer.RemoteFile // Directory listings keyed by the typed directory
+	loading  bool                             // A listing is in flight
+	cycle    []string                         // Candidates cycled through on repeated Tab
+	cycleIdx int
+	hint     string
+}
+
+// remoteCompletionMsg carries the listing of a directory requested for completion
+type remoteCompletionMsg struct {
+	dir     string
+	files   []transfer.RemoteFile
+	err     error
+	session *transfer.SFTPSession // The session listed over, kept by the form
+}
+
+// splitCompletionPath splits a typed remote path into the directory to list
+// and the name prefix to complete. "~" alone completes inside the home directory.
+func splitCompletionPath(value string) (dir, prefix string) {
+	if value == "~" {
+		return "~/", ""
+	}
+	idx := strings.LastIndex(value, "/")
+	if idx < 0 {
+		return "", value
+	}
+	return value[:idx+1], value[idx+1:]
+}
+
+// completionListDir returns the remote directory to list for a typed directory.
+// scp resolves relative remote paths from the home directory.
+func completionListDir(dir string) string {
+	if dir == "" {
+		return "~"
+	}
+	return dir
+}
+
+// remoteCompletionCandidates returns the entries of files matching prefix as
+// full values, keeping the directory as typed (so ~ stays unexpanded).
+// Directories end with a slash. Dotfiles are only offered when asked for.
+func remoteCompletionCandidates(dir, prefix string, files []transfer.RemoteFile) []string {
+	var candidates []string
+	for _, f := range files {
+		if f.Name == ".." || f.Name == "." || !strings.HasPrefix(f.Name, prefix) {
+			continue
+		}
+		if strings.HasPrefix(f.Name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		candidate := dir + f.Name
+		if f.IsDir {
+			candidate += "/"
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// completeRemotePath completes value against the listing of its directory.
+// A single match is completed fully; several are completed to their common prefix.
+func completeRemotePath(value string, files []transfer.RemoteFile) (string, []string) {
+	dir, prefix := splitCompletionPath(value)
+	candidates := remoteCompletionCandidates(dir, prefix, files)
+	if len(candidates) == 0 {
+		return value, nil
+	}
+
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		common = commonPrefix(common, c)
+	}
+	return common, candidates
+}
+
+// commonPrefix returns the longest common prefix of a and b
+func commonPrefix(a, b string) string {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return a[:i]
+		}
+	}
+	return a[:n]
+}
+
+// completeRemoteInput handles Tab in the remote path field: it cycles through
+// candidates when the previous Tab left several, accepts a suggestion from the
+// path history, otherwise completes from the cached listing or requests the
+// directory from the host
+func (m *transferFormModel) completeRemoteInput() tea.Cmd {
+	c := &m.completion
+	value := m.inputs[tfRemotePathInput].Value()
+
+	if len(c.cycle) > 1 && value == c.cycle[c.cycleIdx] {
+		c.cycleIdx = (c.cycleIdx + 1) % len(c.cycle)
+		m.setRemoteInput(c.cycle[c.cycleIdx])
+		return nil
+	}
+	c.cycle = nil
+
+	if s := m.inputs[tfRemotePathInput].CurrentSuggestion(); len(s) > len(value) && strings.HasPrefix(s, value) {
+		c.hint = ""
+		m.setRemoteInput(s)
+		return nil
+	}
+
+	dir, _ := splitCompletionPath(value)
+	if files, ok := c.listings[dir]; ok {
+		m.applyRemoteCompletion(value, files)
+		return nil
+	}
+	if c.loading {
+		return nil
+	}
+
+	c.loading = true
+	c.hint = "Listing " + completionListDir(dir) + "..."
+	session, hostName, configFile := c.session, m.hostName, m.configFile
+	return func() tea.Msg {
+		// The session opened here is handed to Update, which keeps it
+		if session == nil {
+			var err error
+			session, err = transfer.NewSFTPSession(hostName, configFile)
+			if err != nil {
+				return remoteCompletionMsg{dir: dir, err: err}
+			}
+		}
+		files, err := session.ListDirectory(completionListDir(dir))
+		return remoteCompletionMsg{dir: dir, files: files, err: err, session: session}
+	}
+}
+
+// handleRemoteCompletion caches a completion listing and applies it if the
+// field still refers to the listed directory
+func (m *transferFormModel) handleRemoteCompletion(msg remoteCompletionMsg) {
+	c := &m.completion
+	c.loading = false
+	if msg.session != nil && msg.session != c.session {
+		m.closeCompletion()
+		c.session = msg.session
+	}
+	if msg.err != nil {
+		c.hint = remoteErrorText(fmt.Errorf("completion failed: %w", msg.err), "press Tab to retry")
+		var locked *transfer.KeyLockedError
+		if errors.As(msg.err, &locked) {
+			c.hint += " (press 'o' to browse and enter it)"
+		}
+		var unknown *transfer.UnknownHostKeyError
+		if errors.As(msg.err, &unknown) {
+			c.hint += " (press 'o' to browse and accept it)"
+		}
+		return
+	}
+	if c.listings == nil {
+		c.listings = make(map[string][]transfer.RemoteFile)
+	}
+	c.listings[msg.dir] = msg.files
+
+	value := m.inputs[tfRemotePathInput].Value()
+	if dir, _ := splitCompletionPath(value); dir == msg.dir {
+		m.applyRemoteCompletion(value, msg.files)
+	} else {
+		c.hint = ""
+	}
+}
+
+// applyRemoteCompletion completes value from files and updates the hint
+func (m *transferFormModel) applyRemoteCompletion(value string, files []transfer.RemoteFile) {
+	c := &m.completion
+	completed, candidates := completeRemotePath(value, files)
+
+	switch {
+	case len(candidates) == 0:
+		c.hint = "No matches"
+	case len(candidates) == 1:
+		c.hint = ""
+	case completed == value:
+		// Nothing left in common: start cycling through the candidates
+		c.cycle = candidates
+		c.cycleIdx = 0
+		completed = candidates[0]
+		c.hint = completionHint(candidates)
+	default:
+		c.hint = completionHint(candidates)
+	}
+	m.setRemoteInput(completed)
+}
+
+// setRemoteInput replaces the remote path, keeping the cursor at the end
+func (m *transferFormModel) setRemoteInput(value string) {
+	m.inputs[tfRemotePathInput].SetValue(value)
+	m.inputs[tfRemotePathInput].CursorEnd()
+}
+
+// completionHint lists candidate names under the remote path field
+func completionHint(candidates []string) string {
+	names := make([]string, 0, maxCompletionHint)
+	for i, c := range candidates {
+		if i == maxCompletionHint {
+			names = append(names, fmt.Sprintf("+%d more", len(candidates)-maxCompletionHint))
+			break
+		}
+		_, name := splitCompletionPath(strings.TrimSuffix(c, "/"))
+		if strings.HasSuffix(c, "/") {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	return fmt.Sprintf("%d matches: %s", len(candidates), strings.Join(names, "  "))
+}
+
+// closeCompletion closes the completion session, if one was opened
+func (m *transferFormModel) closeCompletion() {
+	if m.completion.session != nil {
+		m.completion.session.Close()
+		m.completion.session = nil
+	}
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var completionListing = []transfer.RemoteFile{
+	{Name: "..", IsDir: true},
+	{Name: ".bashrc"},
+	{Name: ".config", IsDir: true},
+	{Name: "deploy", IsDir: true},
+	{Name: "deploy.sh"},
+	{Name: "logs", IsDir: true},
+	{Name: "notes.txt"},
+}
+
+func TestSplitCompletionPath(t *testing.T) {
+	tests := []struct {
+		value, dir, prefix string
+	}{
+		{"~", "~/", ""},
+		{"~/", "~/", ""},
+		{"~/dep", "~/", "dep"},
+		{"/var/log/sys", "/var/log/", "sys"},
+		{"/", "/", ""},
+		{"notes", "", "notes"},
+		{"app/conf", "app/", "conf"},
+	}
+
+	for _, tt := range tests {
+		dir, prefix := splitCompletionPath(tt.value)
+		if dir != tt.dir || prefix != tt.prefix {
+			t.Errorf("splitCompletionPath(%q) = (%q, %q), want (%q, %q)", tt.value, dir, prefix, tt.dir, tt.prefix)
+		}
+	}
+}
+
+func TestRemoteCompletionCandidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		prefix   string
+		expected []string
+	}{
+		{"all visible entries", "~/", "", []string{"~/deploy.sh", "~/deploy/", "~/logs/", "~/notes.txt"}},
+		{"prefix", "~/", "dep", []string{"~/deploy.sh", "~/deploy/"}},
+		{"dotfiles when asked for", "/home/me/", ".", []string{"/home/me/.bashrc", "/home/me/.config/"}},
+		{"relative directory", "", "no", []string{"notes.txt"}},
+		{"no match", "~/", "zzz", nil},
+	}
+
+	for _, tt := range tests {
+		got := remoteCompletionCandidates(tt.dir, tt.prefix, completionListing)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestCompleteRemotePath(t *testing.T) {
+	tests := []struct {
+		value      string
+		completed  string
+		candidates int
+	}{
+		{"~/lo", "~/logs/", 1},
+		{"~/n", "~/notes.txt", 1},
+		{"~/d", "~/deploy", 2},
+		{"~", "~/", 4},
+		{"~/.c", "~/.config/", 1},
+		{"~/missing", "~/missing", 0},
+	}
+
+	for _, tt := range tests {
+		completed, candidates := completeRemotePath(tt.value, completionListing)
+		if completed != tt.completed || len(candidates) != tt.candidates {
+			t.Errorf("completeRemotePath(%q) = (%q, %d candidates), want (%q, %d)",
+				tt.value, completed, len(candidates), tt.completed, tt.candidates)
+		}
+	}
+}
+
+func TestTransferFormTabCompletesAndCycles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	form := NewTransferForm("server", NewStyles(80), 80, 24, "", transfer.Download)
+	form.inputs[form.focused].Blur()
+	form.focused = tfRemotePathInput
+	form.inputs[tfRemotePathInput].Focus()
+	form.inputs[tfRemotePathInput].SetSuggestions(nil)
+	form.completion.listings = map[string][]transfer.RemoteFile{"~/": completionListing}
+	form.setRemoteInput("~/d")
+
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	press := func() string {
+		var cmd tea.Cmd
+		form, cmd = form.Update(tab)
+		if cmd != nil {
+			t.Fatal("Expected the cached listing to be used without listing the host again")
+		}
+		return form.inputs[tfRemotePathInput].Value()
+	}
+
+	if got := press(); got != "~/deploy" {
+		t.Fatalf("First Tab: got %q, want the common prefix ~/deploy", got)
+	}
+	if !strings.Contains(form.completion.hint, "2 matches") {
+		t.Errorf("Expected the candidates to be listed, got hint %q", form.completion.hint)
+	}
+	if form.focused != tfRemotePathInput {
+		t.Error("Tab should complete instead of moving focus")
+	}
+
+	if got := press(); got != "~/deploy.sh" {
+		t.Errorf("Second Tab: got %q, want the first candidate", got)
+	}
+	if got := press(); got != "~/deploy/" {
+		t.Errorf("Third Tab: got %q, want the second candidate", got)
+	}
+	if got := press(); got != "~/deploy.sh" {
+		t.Errorf("Fourth Tab: got %q, want to cycle back", got)
+	}
+}
+
+func TestTransferFormTabRequestsListing(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	form := NewTransferForm("server", NewStyles(80), 80, 24, "", transfer.Download)
+	form.inputs[form.focused].Blur()
+	form.focused = tfRemotePathInput
+	form.inputs[tfRemotePathInput].Focus()
+	form.inputs[tfRemotePathInput].SetSuggestions(nil)
+	form.setRemoteInput("/srv/a")
+
+	form, cmd := form.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if cmd == nil || !form.completion.loading {
+		t.Fatal("Expected Tab to request a listing of /srv/")
+	}
+
+	session := &transfer.SFTPSession{}
+	form, _ = form.Update(remoteCompletionMsg{dir: "/srv/", files: []transfer.RemoteFile{{Name: "app", IsDir: true}}, session: session})
+	if got := form.inputs[tfRemotePathInput].Value(); got != "/srv/app/" {
+		t.Errorf("Expected the listing to complete the path, got %q", got)
+	}
+	if _, ok := form.completion.listings["/srv/"]; !ok {
+		t.Error("Expected the listing to be cached")
+	}
+	if form.completion.session != session {
+		t.Error("Expected the form to keep the session the listing was made over")
+	}
+}
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// remoteBrowserEditedMsg carries the outcome of editing a remote file
+type remoteBrowserEditedMsg struct {
+	file     transfer.RemoteFile
+	sshfs    bool // Edited in place on an SSHFS mount
+	uploaded bool // The edited copy replaced the remote file
+	err      error
+}
+
+// remoteEditSession edits a remote file with the user's editor while the
+// TUI gives up the terminal: on an SSHFS mount of its directory when sshfs
+// is installed, or on a downloaded copy uploaded back once changed
+type remoteEditSession struct {
+	host       string
+	configFile string
+	session    *transfer.SFTPSession
+	file       transfer.RemoteFile
+	dir        string // Where the copy is downloaded
+	sshfs      bool
+
+	uploaded bool
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (e *remoteEditSession) SetStdin(r io.Reader)  { e.stdin = r }
+func (e *remoteEditSession) SetStdout(w io.Writer) { e.stdout = w }
+func (e *remoteEditSession) SetStderr(w io.Writer) { e.stderr = w }
+
+func (e *remoteEditSession) Run() error {
+	if e.sshfs {
+		mount, err := e.mount()
+		if err == nil {
+			defer mount.Unmount()
+			return e.runEditor(filepath.Join(mount.MountPoint, e.file.Name))
+		}
+		// The mount failed, edit a copy as without sshfs
+		e.sshfs = false
+	}
+	return e.editCopy()
+}
+
+// runEditor edits a local path attached to the terminal
+func (e *remoteEditSession) runEditor(path string) error {
+	editor := transfer.EditorCommand(path)
+	editor.Stdin, editor.Stdout, editor.Stderr = e.stdin, e.stdout, e.stderr
+	if err := editor.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor.Path, err)
+	}
+	return nil
+}
+
+// mount mounts the directory of the file with SSHFS
+func (e *remoteEditSession) mount() (*transfer.SSHFSMount, error) {
+	mount, err := transfer.NewSSHFSMount(e.host, pathpkg.Dir(e.file.Path), e.configFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := mount.Mount(); err != nil {
+		return nil, err
+	}
+	return mount, nil
+}
+
+// editCopy downloads the file, edits the copy and uploads it back only
+// when the editor changed it. A copy that could not be uploaded is kept,
+// so the changes are not lost.
+func (e *remoteEditSession) editCopy() error {
+	localPath, err := e.session.DownloadForOpen(e.file.Path, e.dir, transfer.OpenMaxSize())
+	if err != nil {
+		return err
+	}
+
+	sumBefore, err := transfer.LocalChecksum(localPath)
+	if err != nil {
+		os.Remove(localPath)
+		return err
+	}
+	if err := e.runEditor(localPath); err != nil {
+		os.Remove(localPath)
+		return err
+	}
+
+	changed, err := transfer.NeedsReupload(sumBefore, localPath)
+	if err != nil || !changed {
+		os.Remove(localPath)
+		return err
+	}
+	if err := e.session.UploadEdited(localPath, e.file.Path); err != nil {
+		kept, keepErr := keepEditedCopy(localPath)
+		if keepErr != nil {
+			return fmt.Errorf("%w; your changes are still in %s until the browser closes", err, kept)
+		}
+		return fmt.Errorf("%w; your changes are kept in %s", err, kept)
+	}
+	os.Remove(localPath)
+	e.uploaded = true
+	return nil
+}
+
+// keepEditedCopy moves an edited copy whose upload failed out of the
+// browser's temporary directory, which is removed when the browser closes,
+// and returns where the copy now is
+func keepEditedCopy(localPath string) (string, error) {
+	dir, err := os.MkdirTemp("", "sshm-unsaved-")
+	if err != nil {
+		return localPath, err
+	}
+	kept := filepath.Join(dir, filepath.Base(localPath))
+	if err := os.Rename(localPath, kept); err != nil {
+		os.Remove(dir)
+		return localPath, err
+	}
+	return kept, nil
+}
+
+// editFile edits the selected remote file in the user's editor
+func (m *remoteBrowserModel) editFile(file transfer.RemoteFile) tea.Cmd {
+	edit := &remoteEditSession{
+		host:       m.host,
+		configFile: m.configFile,
+		session:    m.session,
+		file:       file,
+		dir:        m.openDir,
+		sshfs:      transfer.IsSSHFSAvailable(),
+	}
+	return tea.Exec(edit, func(err error) tea.Msg {
+		return remoteBrowserEditedMsg{file: file, sshfs: edit.sshfs, uploaded: edit.uploaded, err: err}
+	})
+}
+
+// editStatus confirms an edit once the listing shows it
+func editStatus(msg remoteBrowserEditedMsg) string {
+	switch {
+	case msg.sshfs:
+		return "✓ Edited " + msg.file.Name + " over SSHFS"
+	case msg.uploaded:
+		return "✓ Saved " + msg.file.Name + " to the host"
+	}
+	return msg.file.Name + " unchanged, nothing uploaded"
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"path"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+// lastRemoteFilter is the extension filter of the last remote browser, applied
+// again to the next one opened while sshm runs
+var lastRemoteFilter extensionFilter
+
+// extensionFilter hides the files of a listing whose name matches none of its
+// patterns, such as "*.log" or "*.tar.gz". Directories stay visible.
+type extensionFilter struct {
+	patterns []string // Lower case glob patterns
+}
+
+// parseExtensionFilter reads patterns separated by spaces or commas. A bare
+// extension (".log" or "log") stands for "*.log".
+func parseExtensionFilter(input string) extensionFilter {
+	var f extensionFilter
+	for _, p := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		p = strings.ToLower(p)
+		if !strings.ContainsAny(p, "*?[") {
+			p = "*." + strings.TrimPrefix(p, ".")
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			continue // Malformed pattern
+		}
+		f.patterns = append(f.patterns, p)
+	}
+	return f
+}
+
+// active reports whether the filter hides anything
+func (f extensionFilter) active() bool {
+	return len(f.patterns) > 0
+}
+
+// String returns the patterns as typed back in the filter prompt
+func (f extensionFilter) String() string {
+	return strings.Join(f.patterns, " ")
+}
+
+// matches reports whether file is kept by the filter, ignoring case
+func (f extensionFilter) matches(file transfer.RemoteFile) bool {
+	if !f.active() || file.IsDir || file.Name == ".." {
+		return true
+	}
+	name := strings.ToLower(file.Name)
+	for _, p := range f.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+// remoteSortMode is the order of the remote browser listing
+type remoteSortMode int
+
+const (
+	remoteSortName         remoteSortMode = iota // A to Z, directories first
+	remoteSortNameDesc                           // Z to A, directories first
+	remoteSortSizeDesc                           // Largest files first, directories first
+	remoteSortSize                               // Smallest files first, directories first
+	remoteSortModifiedDesc                       // Newest first, directories mixed in
+	remoteSortModified                           // Oldest first, directories mixed in
+	remoteSortModeCount
+)
+
+// lastRemoteSort is the order last chosen with O, kept for every directory
+// and remote browser opened while sshm runs
+var (
+	lastRemoteSort   remoteSortMode
+	remoteSortChosen bool
+)
+
+func (s remoteSortMode) String() string {
+	switch s {
+	case remoteSortNameDesc:
+		return "name, Z to A"
+	case remoteSortSizeDesc:
+		return "largest first"
+	case remoteSortSize:
+		return "smallest first"
+	case remoteSortModifiedDesc:
+		return "newest first"
+	case remoteSortModified:
+		return "oldest first"
+	default:
+		return "name"
+	}
+}
+
+// next returns the order following s in the cycle of O: name, size, then
+// modification time, each descending after ascending
+func (s remoteSortMode) next() remoteSortMode {
+	return (s + 1) % remoteSortModeCount
+}
+
+// sortRemoteFiles sorts a listing in the given order, keeping ".." first
+func sortRemoteFiles(files []transfer.RemoteFile, mode remoteSortMode) {
+	switch mode {
+	case remoteSortName:
+		transfer.SortRemoteFiles(files)
+		return
+	case remoteSortModifiedDesc:
+		transfer.SortRemoteFilesByModified(files)
+		return
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if a.Name == ".." || b.Name == ".." {
+			return a.Name == ".." && b.Name != ".."
+		}
+		if mode != remoteSortModified && a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+
+		switch mode {
+		case remoteSortSizeDesc:
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+		case remoteSortSize:
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case remoteSortModified:
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.Before(b.ModTime)
+			}
+		case remoteSortNameDesc:
+			return strings.ToLower(a.Name) > strings.ToLower(b.Name)
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+// remoteStartDirs are the directories the remote browser of a transfer can
+// open in, empty when unknown
+type remoteStartDirs struct {
+	upload string // Configured destination of uploads
+	last   string // Directory browsed or transferred with last on the host
+}
+
+// loadRemoteStartDirs reads the upload directory of a host, its own before
+// the one of the app config, and the remote directory it used last
+func loadRemoteStartDirs(hostName string, pathStore *history.RemotePathStore) remoteStartDirs {
+	var dirs remoteStartDirs
+	if hostDefaults, err := config.LoadHostTransferDefaults(hostName); err == nil {
+		dirs.upload = hostDefaults.UploadDir
+	}
+	if dirs.upload == "" {
+		if appConfig, err := config.LoadAppConfig(); err == nil {
+			dirs.upload = appConfig.RemoteUploadDir
+		}
+	}
+	if pathStore != nil {
+		dirs.last = pathStore.Last(hostName)
+	}
+	return dirs
+}
+
+// startPath picks where the remote browser opens: uploads in the configured
+// upload directory, downloads in the directory used last, else the home
+// directory
+func (d remoteStartDirs) startPath(direction transfer.Direction) string {
+	dir := d.last
+	if direction == transfer.Upload {
+		dir = d.upload
+	}
+	if dir == "" {
+		return "~"
+	}
+	return dir
+}
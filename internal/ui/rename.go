@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openRename shows a prompt in place of the footer to rename hostName
+func (m *Model) openRename(hostName string) tea.Cmd {
+	ti := textinput.New()
+	ti.Prompt = fmt.Sprintf("Rename %s to: ", hostName)
+	ti.CharLimit = 50
+	ti.SetValue(hostName)
+	ti.CursorEnd()
+	ti.Focus()
+	m.renameInput = ti
+	m.renameHost = hostName
+	m.table.Blur()
+	return textinput.Blink
+}
+
+// closeRename hides the rename prompt and gives the focus back to the table
+func (m *Model) closeRename() {
+	m.renameHost = ""
+	m.renameInput.Blur()
+	m.table.Focus()
+}
+
+// handleRenameKeys handles the keys typed in the rename prompt
+func (m Model) handleRenameKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.closeRename()
+		return m, nil
+	case "enter":
+		oldName, newName := m.renameHost, strings.TrimSpace(m.renameInput.Value())
+		if newName == oldName {
+			m.closeRename()
+			return m, nil
+		}
+		if !validation.ValidateHostName(newName) {
+			return m, m.showError(fmt.Sprintf("%q is not a valid host name", newName))
+		}
+		if m.hasHost(newName) {
+			return m, m.showError(fmt.Sprintf("A host named %q already exists", newName))
+		}
+		m.closeRename()
+		if err := m.renameSSHHost(oldName, newName); err != nil {
+			return m, m.showError(fmt.Sprintf("Could not rename %s: %v", oldName, err))
+		}
+		text := fmt.Sprintf("Renamed %s to %s", oldName, newName)
+		if jumping := hostsJumpingThrough(m.hosts, oldName); len(jumping) > 0 {
+			text += fmt.Sprintf(" — the ProxyJump of %s still names %s", strings.Join(jumping, ", "), oldName)
+			return m, m.pushNotification(NotifyWarn, text)
+		}
+		return m, m.pushNotification(NotifySuccess, text)
+	}
+
+	var cmd tea.Cmd
+	m.renameInput, cmd = m.renameInput.Update(msg)
+	return m, cmd
+}
+
+// renameSSHHost renames a host on the Host line of its config file, then
+// moves what sshm keeps under its name: history and bookmarks, remote paths,
+// favorite mark, web URL, transfer defaults, mounts and background tunnels. The config is rewritten first
+// so that a failure there leaves everything under the old name.
+func (m *Model) renameSSHHost(oldName, newName string) error {
+	configPath := m.activeConfigFile()
+	for _, host := range m.hosts {
+		if host.Name == oldName && host.SourceFile != "" {
+			configPath = host.SourceFile
+		}
+	}
+	if err := config.RenameSSHHostInFile(oldName, newName, configPath); err != nil {
+		return err
+	}
+
+	if err := config.RenameHostSettings(oldName, newName); err != nil {
+		return fmt.Errorf("renamed in the config, but not its settings: %w", err)
+	}
+	if m.historyManager != nil {
+		if err := m.historyManager.RenameHost(oldName, newName); err != nil {
+			return fmt.Errorf("renamed in the config, but not its history: %w", err)
+		}
+	}
+	if remotePaths, err := history.NewRemotePathStore(); err == nil {
+		if err := remotePaths.RenameHost(oldName, newName); err != nil {
+			return fmt.Errorf("renamed in the config, but not its remote paths: %w", err)
+		}
+	}
+	// Mounts and background tunnels are found by host name when stopped
+	if err := transfer.RenameMountsHost(oldName, newName); err != nil {
+		return fmt.Errorf("renamed in the config, but not its mounts: %w", err)
+	}
+	if registry, err := transfer.NewForwardRegistry(); err == nil {
+		if err := registry.RenameHost(oldName, newName); err != nil {
+			return fmt.Errorf("renamed in the config, but not its background tunnels: %w", err)
+		}
+	}
+
+	if m.favorites[oldName] {
+		delete(m.favorites, oldName)
+		m.favorites[newName] = true
+	}
+	if m.markedHosts[oldName] {
+		delete(m.markedHosts, oldName)
+		m.markedHosts[newName] = true
+	}
+
+	var hosts []config.SSHHost
+	var err error
+	if m.configFile != "" {
+		hosts, err = config.ParseSSHConfigFile(m.configFile)
+	} else {
+		hosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		return err
+	}
+	m.hosts = m.sortHosts(hosts)
+	m.refreshFilteredHosts()
+	m.selectHost(newName)
+	return nil
+}
+
+// hostsJumpingThrough returns the hosts whose ProxyJump goes through hostName
+func hostsJumpingThrough(hosts []config.SSHHost, hostName string) []string {
+	var names []string
+	for _, host := range hosts {
+		for _, hop := range strings.Split(host.ProxyJump, ",") {
+			// A hop is [user@]host[:port]
+			if i := strings.LastIndex(hop, "@"); i >= 0 {
+				hop = hop[i+1:]
+			}
+			if i := strings.LastIndex(hop, ":"); i >= 0 {
+				hop = hop[:i]
+			}
+			if strings.TrimSpace(hop) == hostName {
+				names = append(names, host.Name)
+				break
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRenameHost(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	configPath := filepath.Join(dir, "config")
+	content := "Host web\n    HostName 10.0.0.1\n\nHost db\n    HostName 10.0.0.2\n    ProxyJump admin@web:2222\n"
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := config.ParseSSHConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := transfer.RecordMount(&transfer.SSHFSMount{Host: "web", RemotePath: "/srv", MountPoint: filepath.Join(dir, "mnt")}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := createTestModel()
+	m.configFile = configPath
+	m.historyManager = hm
+	m.hosts = m.sortHosts(hosts)
+	m.refreshFilteredHosts()
+	m.selectHost("web")
+
+	m = pressKey(t, m, "N")
+	if m.renameHost != "web" || m.renameInput.Value() != "web" {
+		t.Fatalf("Expected N to open the rename prompt for web, got %q", m.renameHost)
+	}
+	if !strings.Contains(m.View(), "Rename web to:") {
+		t.Error("Expected the prompt in the footer")
+	}
+
+	// A taken name keeps the prompt open
+	m.renameInput.SetValue("db")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.renameHost != "web" {
+		t.Fatal("Expected the prompt to stay open on a taken name")
+	}
+
+	m.renameInput.SetValue("front")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.renameHost != "" {
+		t.Fatal("Expected the prompt closed after the rename")
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(content, "Host web\n", "Host front\n", 1); string(data) != want {
+		t.Errorf("Expected only the Host line rewritten, got:\n%s", data)
+	}
+	if selected, ok := m.selectedSSHHost(); !ok || selected.Name != "front" {
+		t.Error("Expected the renamed host selected")
+	}
+	if hm.GetConnectionCount("front") != 1 || hm.GetConnectionCount("web") != 0 {
+		t.Error("Expected the history moved to the new name")
+	}
+	if mounts, _ := transfer.HostMounts("front"); len(mounts) != 1 {
+		t.Error("Expected the mount moved to the new name")
+	}
+	if items := m.notifications.items; len(items) == 0 || items[len(items)-1].level != NotifyWarn {
+		t.Error("Expected a warning about the ProxyJump of db")
+	}
+}
+
+func TestHostsJumpingThrough(t *testing.T) {
+	hosts := []config.SSHHost{
+		{Name: "a", ProxyJump: "web"},
+		{Name: "b", ProxyJump: "bastion,admin@web:2222"},
+		{Name: "c", ProxyJump: "web-server"},
+		{Name: "d"},
+	}
+	if got := hostsJumpingThrough(hosts, "web"); strings.Join(got, ",") != "a,b" {
+		t.Errorf("Expected a and b, got %v", got)
+	}
+}
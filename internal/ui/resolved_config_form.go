@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// resolvedConfigModel displays the fully resolved `ssh -G` options for a host
+type resolvedConfigModel struct {
+	hostName string
+	options  []config.ResolvedOption
+	err      string
+	status   string
+	viewport viewport.Model
+	styles   Styles
+	width    int
+	height   int
+}
+
+// resolvedConfigCloseMsg is sent when the resolved config view is closed
+type resolvedConfigCloseMsg struct{}
+
+// NewResolvedConfigForm creates a view of the resolved configuration for a host
+func NewResolvedConfigForm(hostName string, styles Styles, width, height int, configFile string) *resolvedConfigModel {
+	m := &resolvedConfigModel{
+		hostName: hostName,
+		styles:   styles,
+		width:    width,
+		height:   height,
+		viewport: newScrollViewport(),
+	}
+
+	if err := transfer.RequireBinary("ssh"); err != nil {
+		m.err = err.Error()
+		return m
+	}
+
+	options, err := config.GetResolvedConfig(hostName, configFile)
+	if err != nil {
+		m.err = err.Error()
+	}
+	m.options = options
+
+	return m
+}
+
+func (m *resolvedConfigModel) Init() tea.Cmd {
+	return nil
+}
+
+// fitViewport sizes the scrollable options to the window, leaving room for
+// the title, the help and the borders
+func (m *resolvedConfigModel) fitViewport() {
+	fitScrollViewport(&m.viewport, m.optionLines(), m.height, 10)
+}
+
+// optionLines renders the options with their keys aligned
+func (m *resolvedConfigModel) optionLines() string {
+	keyWidth := 0
+	for _, opt := range m.options {
+		if len(opt.Key) > keyWidth {
+			keyWidth = len(opt.Key)
+		}
+	}
+
+	keyStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")). // Bright blue
+		Width(keyWidth).
+		AlignHorizontal(lipgloss.Right)
+	valueStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("255")) // White
+
+	lines := make([]string, 0, len(m.options))
+	for _, opt := range m.options {
+		lines = append(lines, keyStyle.Render(opt.Key)+"  "+valueStyle.Render(opt.Value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *resolvedConfigModel) Update(msg tea.Msg) (*resolvedConfigModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = NewStyles(m.width)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return resolvedConfigCloseMsg{} }
+
+		case "home", "g":
+			m.fitViewport()
+			m.viewport.GotoTop()
+			return m, nil
+
+		case "end", "G":
+			m.fitViewport()
+			m.viewport.GotoBottom()
+			return m, nil
+
+		case "c", "y":
+			if len(m.options) == 0 {
+				return m, nil
+			}
+			if err := clipboard.WriteAll(config.FormatResolvedConfig(m.options)); err != nil {
+				m.status = "Copy failed: " + err.Error()
+			} else {
+				m.status = "Copied to clipboard"
+			}
+			return m, nil
+		}
+
+		m.fitViewport()
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m *resolvedConfigModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Resolved Configuration: %s", m.hostName)))
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("Output of ssh -G, after all config blocks are applied"))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render("Error: " + m.err))
+		b.WriteString("\n")
+	} else {
+		m.fitViewport()
+		b.WriteString(m.viewport.View())
+		b.WriteString("\n")
+
+		if total := m.viewport.TotalLineCount(); total > m.viewport.Height {
+			end := m.viewport.YOffset + m.viewport.Height
+			if end > total {
+				end = total
+			}
+			b.WriteString(m.styles.HelpText.Render(fmt.Sprintf("[%d-%d/%d]", m.viewport.YOffset+1, end, total)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	if m.status != "" {
+		b.WriteString(m.styles.HelpText.Render(m.status))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.styles.HelpText.Render("↑/↓: scroll • PgUp/PgDn: page • c: copy to clipboard • q/Esc: back"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
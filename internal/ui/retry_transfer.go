@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// retryTransferModel shows the last attempted transfer and asks before running it again
+type retryTransferModel struct {
+	attempt *history.TransferAttempt
+	styles  Styles
+	width   int
+	height  int
+}
+
+// retryTransferConfirmMsg is sent when the retry is confirmed
+type retryTransferConfirmMsg struct {
+	request *transfer.TransferRequest
+}
+
+// retryTransferCancelMsg is sent when the retry is dismissed
+type retryTransferCancelMsg struct{}
+
+// retryTransferDoneMsg is sent when the retried scp exits
+type retryTransferDoneMsg struct {
+	request *transfer.TransferRequest
+	err     error
+}
+
+// NewRetryTransfer creates the confirmation for retrying attempt
+func NewRetryTransfer(attempt *history.TransferAttempt, styles Styles, width, height int) *retryTransferModel {
+	return &retryTransferModel{
+		attempt: attempt,
+		styles:  styles,
+		width:   width,
+		height:  height,
+	}
+}
+
+func (m *retryTransferModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *retryTransferModel) Update(msg tea.Msg) (*retryTransferModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "esc", "q", "n":
+		return m, func() tea.Msg { return retryTransferCancelMsg{} }
+
+	case "enter", "y":
+		req := m.attempt.Request()
+		return m, func() tea.Msg { return retryTransferConfirmMsg{request: req} }
+	}
+
+	return m, nil
+}
+
+func (m *retryTransferModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render("Retry Last Transfer"))
+	b.WriteString("\n\n")
+
+	for _, line := range m.attempt.Parameters() {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render(m.attempt.Request().CommandString()))
+	b.WriteString("\n\n")
+	b.WriteString(m.styles.HelpText.Render("Enter: retry • Esc: cancel"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
+
+// recordTransferAttempt saves req as the last attempted transfer so it can be retried
+func recordTransferAttempt(hm *history.HistoryManager, req *transfer.TransferRequest, err error) {
+	if hm == nil || req == nil {
+		return
+	}
+	_ = hm.RecordTransferAttempt(history.NewTransferAttempt(req, err))
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createRetryTestModel returns a test model whose history lives in a temp dir
+func createRetryTestModel(t *testing.T) Model {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := createTestModel()
+	m.historyManager = hm
+	return m
+}
+
+func TestRetryTransferWithoutAttempt(t *testing.T) {
+	m := createRetryTestModel(t)
+
+	m = pressKey(t, m, "R")
+	if m.viewMode != ViewList || m.retryTransferForm != nil {
+		t.Fatalf("Expected to stay on the list without a previous transfer, got view %v", m.viewMode)
+	}
+	if len(m.notifications.items) != 1 || !strings.Contains(m.notifications.items[0].text, "No transfer to retry") {
+		t.Errorf("Expected a notification, got %+v", m.notifications.items)
+	}
+}
+
+func TestRetryTransferShowsAndRebuildsFailedRequest(t *testing.T) {
+	m := createRetryTestModel(t)
+
+	req := &transfer.TransferRequest{
+		Host:       "server1",
+		Direction:  transfer.Upload,
+		LocalPath:  "/tmp/site",
+		RemotePath: "/srv/www",
+		Recursive:  true,
+		ExtraArgs:  []string{"-O"},
+		Port:       "2222",
+	}
+	recordTransferAttempt(m.historyManager, req, errors.New("lost connection"))
+
+	m = pressKey(t, m, "R")
+	if m.viewMode != ViewRetryTransfer || m.retryTransferForm == nil {
+		t.Fatalf("Expected the retry confirmation, got view %v", m.viewMode)
+	}
+
+	view := m.View()
+	for _, want := range []string{"server1", "/tmp/site", "/srv/www", "lost connection", "Enter: retry"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the retry view", want)
+		}
+	}
+
+	_, cmd := m.retryTransferForm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected Enter to confirm the retry")
+	}
+	msg, ok := cmd().(retryTransferConfirmMsg)
+	if !ok {
+		t.Fatalf("Expected retryTransferConfirmMsg, got %T", cmd())
+	}
+	if !reflect.DeepEqual(msg.request, req) {
+		t.Errorf("Rebuilt request = %+v, want %+v", msg.request, req)
+	}
+}
+
+func TestRetryTransferCancel(t *testing.T) {
+	m := createRetryTestModel(t)
+	recordTransferAttempt(m.historyManager, &transfer.TransferRequest{
+		Host: "server1", Direction: transfer.Download, LocalPath: "/tmp", RemotePath: "/etc/hosts",
+	}, errors.New("failed"))
+
+	m = pressKey(t, m, "R")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected Esc to cancel the retry")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.viewMode != ViewList || m.retryTransferForm != nil {
+		t.Errorf("Expected to return to the list, got view %v", m.viewMode)
+	}
+}
+
+func TestRetryTransferDoneRecordsAttempt(t *testing.T) {
+	m := createRetryTestModel(t)
+	req := &transfer.TransferRequest{Host: "server1", Direction: transfer.Upload, LocalPath: "/tmp/a", RemotePath: "/srv/"}
+
+	updated, _ := m.Update(retryTransferDoneMsg{request: req, err: errors.New("still down")})
+	m = updated.(Model)
+
+	attempt := m.historyManager.GetLastTransferAttempt()
+	if attempt == nil || attempt.Error != "still down" {
+		t.Fatalf("Expected the failed retry to be recorded, got %+v", attempt)
+	}
+	if len(m.notifications.items) != 1 || m.notifications.items[0].level != NotifyError {
+		t.Errorf("Expected an error notification, got %+v", m.notifications.items)
+	}
+}
@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// savedViewsModel lists saved host searches and saves the current one
+type savedViewsModel struct {
+	views     []config.SavedView
+	cursor    int
+	query     string   // Current search query, offered for saving
+	sortMode  SortMode // Current sort mode, saved with the query
+	naming    bool     // Whether the name prompt for a new view is shown
+	nameInput textinput.Model
+	err       string
+	status    string
+	styles    Styles
+	width     int
+	height    int
+}
+
+// savedViewApplyMsg is sent when a saved view is selected
+type savedViewApplyMsg struct {
+	view config.SavedView
+}
+
+// savedViewsCancelMsg is sent when the menu is closed without selecting a view
+type savedViewsCancelMsg struct{}
+
+// NewSavedViews creates the saved views menu for the current search and sort
+func NewSavedViews(query string, sortMode SortMode, styles Styles, width, height int) *savedViewsModel {
+	nameInput := textinput.New()
+	nameInput.Placeholder = "e.g. production"
+	nameInput.CharLimit = 50
+	nameInput.Width = 30
+
+	m := &savedViewsModel{
+		query:     strings.TrimSpace(query),
+		sortMode:  sortMode,
+		nameInput: nameInput,
+		styles:    styles,
+		width:     width,
+		height:    height,
+	}
+	m.reload()
+	return m
+}
+
+// reload reads the saved views from disk
+func (m *savedViewsModel) reload() {
+	views, err := config.LoadSavedViews()
+	if err != nil {
+		m.err = err.Error()
+		return
+	}
+	m.views = views
+	if m.cursor >= len(m.views) {
+		m.cursor = len(m.views) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m *savedViewsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *savedViewsModel) Update(msg tea.Msg) (*savedViewsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.naming {
+		return m.updateNaming(keyMsg)
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "esc", "q":
+		return m, func() tea.Msg { return savedViewsCancelMsg{} }
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.views)-1 {
+			m.cursor++
+		}
+
+	case "enter":
+		if len(m.views) == 0 {
+			return m, nil
+		}
+		view := m.views[m.cursor]
+		return m, func() tea.Msg { return savedViewApplyMsg{view: view} }
+
+	case "s":
+		if m.query == "" {
+			m.err = "No active search to save, filter the host list with / first"
+			return m, nil
+		}
+		m.err = ""
+		m.status = ""
+		m.naming = true
+		m.nameInput.SetValue("")
+		m.nameInput.Focus()
+		return m, textinput.Blink
+
+	case "d", "x":
+		if len(m.views) == 0 {
+			return m, nil
+		}
+		name := m.views[m.cursor].Name
+		if err := config.DeleteSavedView(name); err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		m.err = ""
+		m.status = fmt.Sprintf("Deleted view %q", name)
+		m.reload()
+	}
+
+	return m, nil
+}
+
+// updateNaming handles keys while the name of a new view is typed
+func (m *savedViewsModel) updateNaming(msg tea.KeyMsg) (*savedViewsModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.naming = false
+		m.nameInput.Blur()
+		return m, nil
+
+	case "enter":
+		view := config.SavedView{
+			Name:  m.nameInput.Value(),
+			Query: m.query,
+			Sort:  m.sortMode.Key(),
+		}
+		if err := config.SaveView(view); err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		m.naming = false
+		m.nameInput.Blur()
+		m.err = ""
+		m.status = fmt.Sprintf("Saved view %q", strings.TrimSpace(view.Name))
+		m.reload()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	return m, cmd
+}
+
+func (m *savedViewsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render("Saved Views"))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render(m.err))
+		b.WriteString("\n\n")
+	} else if m.status != "" {
+		b.WriteString(m.styles.HelpText.Render(m.status))
+		b.WriteString("\n\n")
+	}
+
+	if len(m.views) == 0 {
+		b.WriteString(m.styles.HelpText.Render("No saved views yet"))
+		b.WriteString("\n")
+	}
+
+	visibleHeight := m.height - 14
+	if visibleHeight < 5 {
+		visibleHeight = 5
+	}
+	start := 0
+	if m.cursor >= visibleHeight {
+		start = m.cursor - visibleHeight + 1
+	}
+	end := start + visibleHeight
+	if end > len(m.views) {
+		end = len(m.views)
+	}
+
+	for i := start; i < end; i++ {
+		view := m.views[i]
+		line := fmt.Sprintf("%-20s %-30s %s", view.Name, view.Query, sortModeFromKey(view.Sort))
+		if i == m.cursor && !m.naming {
+			b.WriteString(m.styles.Selected.Render("▶ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.naming {
+		b.WriteString(m.styles.FocusedLabel.Render(fmt.Sprintf("Save %q as:", m.query)))
+		b.WriteString("\n")
+		b.WriteString(m.nameInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.HelpText.Render("Enter: save • Esc: back"))
+	} else {
+		if m.query != "" {
+			b.WriteString(m.styles.HelpText.Render(fmt.Sprintf("Current search: %s (%s)", m.query, m.sortMode)))
+			b.WriteString("\n")
+		}
+		b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Enter: apply • s: save current search • d: delete • Esc: close"))
+	}
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestApplySavedView(t *testing.T) {
+	m := createTestModel()
+
+	updated, _ := m.Update(savedViewApplyMsg{view: config.SavedView{Name: "web", Query: "web", Sort: "last_used"}})
+	m = updated.(Model)
+
+	if m.searchInput.Value() != "web" {
+		t.Errorf("Expected search query 'web', got %q", m.searchInput.Value())
+	}
+	if m.sortMode != SortByLastUsed {
+		t.Errorf("Expected sort mode %v, got %v", SortByLastUsed, m.sortMode)
+	}
+	if len(m.filteredHosts) != 1 || m.filteredHosts[0].Name != "web-server" {
+		t.Errorf("Expected only web-server, got %v", m.filteredHosts)
+	}
+	if m.viewMode != ViewList || m.searchMode {
+		t.Error("Expected to return to the list with the table focused")
+	}
+}
+
+func TestSortModeKeyRoundTrip(t *testing.T) {
+	for _, mode := range []SortMode{SortByName, SortByLastUsed} {
+		if got := sortModeFromKey(mode.Key()); got != mode {
+			t.Errorf("sortModeFromKey(%q) = %v, want %v", mode.Key(), got, mode)
+		}
+	}
+	if got := sortModeFromKey("unknown"); got != SortByName {
+		t.Errorf("Expected unknown keys to sort by name, got %v", got)
+	}
+}
+
+func TestSavedViewsSaveAndSelect(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	m := NewSavedViews("tag:prod", SortByLastUsed, NewStyles(80), 80, 24)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if !m.naming {
+		t.Fatal("Expected 's' to prompt for a view name")
+	}
+	for _, r := range "prod" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.naming || m.err != "" {
+		t.Fatalf("Expected the view to be saved, err = %q", m.err)
+	}
+
+	views, err := config.LoadSavedViews()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 1 || views[0] != (config.SavedView{Name: "prod", Query: "tag:prod", Sort: "last_used"}) {
+		t.Fatalf("Unexpected saved views: %+v", views)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command when selecting a view")
+	}
+	applied, ok := cmd().(savedViewApplyMsg)
+	if !ok || applied.view.Name != "prod" {
+		t.Errorf("Expected savedViewApplyMsg for 'prod', got %#v", cmd())
+	}
+}
+
+func TestSavedViewsRequireQuery(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	m := NewSavedViews("  ", SortByName, NewStyles(80), 80, 24)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if m.naming || m.err == "" {
+		t.Error("Expected saving without a search to be refused")
+	}
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newScrollViewport returns a viewport for content that may be taller than
+// the terminal. Only arrows, k/j and page keys scroll it, leaving letters to
+// the actions of the view.
+func newScrollViewport() viewport.Model {
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewport.KeyMap{
+		PageDown:     key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:       key.NewBinding(key.WithKeys("pgup")),
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d")),
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u")),
+		Down:         key.NewBinding(key.WithKeys("down", "j")),
+		Up:           key.NewBinding(key.WithKeys("up", "k")),
+	}
+	return vp
+}
+
+// fitScrollViewport sets the content of vp and sizes it to the content,
+// limited to the lines of a terminal of height left by chrome lines (title,
+// borders, help). The scroll offset is kept when the content still allows it.
+func fitScrollViewport(vp *viewport.Model, content string, height, chrome int) {
+	available := height - chrome
+	if available < 3 {
+		available = 3
+	}
+	lines := lipgloss.Height(content)
+	if lines > available {
+		lines = available
+	}
+
+	vp.Width = lipgloss.Width(content)
+	vp.Height = lines
+	vp.SetContent(content)
+}
+
+// scrollIndicator tells how to scroll content taller than vp, empty when it all fits
+func scrollIndicator(vp viewport.Model) string {
+	if vp.TotalLineCount() <= vp.Height {
+		return ""
+	}
+	return fmt.Sprintf("↑/↓ PgUp/PgDn: scroll • %d%%", int(vp.ScrollPercent()*100))
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHelpFormScrolls(t *testing.T) {
+	keys := config.GetDefaultKeyBindings()
+	m := NewHelpForm(NewStyles(120), 120, 16, &keys)
+	m.View()
+
+	if indicator := scrollIndicator(m.viewport); indicator == "" {
+		t.Fatal("Expected the help to need scrolling in a short window")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.viewport.YOffset != 1 {
+		t.Errorf("Expected down to scroll one line, got offset %d", m.viewport.YOffset)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.viewport.YOffset <= 1 {
+		t.Errorf("Expected PgDn to scroll a page, got offset %d", m.viewport.YOffset)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if m.viewport.YOffset != 0 {
+		t.Errorf("Expected to be back at the top, got offset %d", m.viewport.YOffset)
+	}
+
+	// Keys closing the help are not taken by the viewport
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")}); cmd == nil {
+		t.Error("Expected h to close the help")
+	}
+}
+
+func TestHelpFormFitsTallWindow(t *testing.T) {
+	keys := config.GetDefaultKeyBindings()
+	m := NewHelpForm(NewStyles(120), 120, 80, &keys)
+	m.View()
+
+	if indicator := scrollIndicator(m.viewport); indicator != "" {
+		t.Errorf("Expected no scroll indicator when the help fits, got %q", indicator)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.viewport.YOffset != 0 {
+		t.Errorf("Expected content that fits not to scroll, got offset %d", m.viewport.YOffset)
+	}
+}
+
+func TestInfoFormScrolls(t *testing.T) {
+	m := &infoFormModel{
+		host: &config.SSHHost{
+			Name:     "web",
+			Hostname: "10.0.0.1",
+			Options:  "ServerAliveInterval 60",
+		},
+		hostName: "web",
+		styles:   NewStyles(80),
+		width:    80,
+		height:   16,
+		viewport: newScrollViewport(),
+	}
+	m.View()
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if m.viewport.YOffset != 1 {
+		t.Errorf("Expected j to scroll one line, got offset %d", m.viewport.YOffset)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if want := m.viewport.TotalLineCount() - m.viewport.Height; m.viewport.YOffset != want {
+		t.Errorf("Expected PgDn to reach the last fields at offset %d, got %d", want, m.viewport.YOffset)
+	}
+	if !strings.Contains(m.View(), "Status") {
+		t.Error("Expected the last field to be shown once scrolled down")
+	}
+}
+
+func TestResolvedConfigFormScrolls(t *testing.T) {
+	m := &resolvedConfigModel{
+		hostName: "web",
+		styles:   NewStyles(80),
+		width:    80,
+		height:   20,
+		viewport: newScrollViewport(),
+	}
+	for i := 0; i < 40; i++ {
+		m.options = append(m.options, config.ResolvedOption{Key: fmt.Sprintf("option%02d", i), Value: "yes"})
+	}
+
+	if view := m.View(); !strings.Contains(view, "[1-10/40]") {
+		t.Errorf("Expected the first page to be shown, got:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.viewport.YOffset != 1 {
+		t.Errorf("Expected down to scroll one option, got offset %d", m.viewport.YOffset)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.viewport.YOffset != 11 {
+		t.Errorf("Expected PgDn to scroll a page, got offset %d", m.viewport.YOffset)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if view := m.View(); !strings.Contains(view, "[31-40/40]") || !strings.Contains(view, "option39") {
+		t.Errorf("Expected G to show the last page, got:\n%s", view)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if m.viewport.YOffset != 0 {
+		t.Errorf("Expected g to go back to the top, got offset %d", m.viewport.YOffset)
+	}
+}
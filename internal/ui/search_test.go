@@ -1,9 +1,11 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -240,6 +242,85 @@ func TestEnterExitsSearchMode(t *testing.T) {
 	}
 }
 
+// searchAndPressEnter types query in search mode with the given Enter action and presses Enter
+func searchAndPressEnter(t *testing.T, action, query string) (Model, tea.Cmd) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := createTestModel()
+	m.historyManager = hm
+	m.appConfig = &config.AppConfig{SearchEnterAction: action}
+
+	m = pressKey(t, m, "/")
+	for _, r := range query {
+		m = pressKey(t, m, string(r))
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return newModel.(Model), cmd
+}
+
+func TestSearchEnterFocusesTable(t *testing.T) {
+	for _, action := range []string{"", config.SearchEnterFocusTable} {
+		m, cmd := searchAndPressEnter(t, action, "web")
+
+		if m.searchMode || !m.table.Focused() {
+			t.Errorf("%q: expected Enter to move to the table", action)
+		}
+		if cmd != nil || m.historyManager.GetConnectionCount("web-server") != 0 {
+			t.Errorf("%q: Enter should not connect", action)
+		}
+		if len(m.filteredHosts) == 0 || m.searchInput.Value() != "web" {
+			t.Errorf("%q: expected the search to be kept", action)
+		}
+	}
+}
+
+func TestSearchEnterConnectsTopMatch(t *testing.T) {
+	m, cmd := searchAndPressEnter(t, config.SearchEnterConnectTop, "web")
+
+	if m.searchMode {
+		t.Error("Should not be in search mode after Enter")
+	}
+	if cmd == nil {
+		t.Fatal("Expected Enter to connect to the top match")
+	}
+
+	// The identity files of the top match are checked before connecting
+	check, ok := cmd().(identityCheckMsg)
+	if !ok || check.hostName != "web-server" {
+		t.Fatalf("Expected an identity check for web-server, got %+v", check)
+	}
+	check.missing = nil
+	newModel, _ := m.Update(check)
+	m = newModel.(Model)
+
+	top := m.filteredHosts[0].Name
+	if top != "web-server" || m.historyManager.GetConnectionCount(top) != 1 {
+		t.Errorf("Expected a connection to web-server, got top match %q with %d connections", top, m.historyManager.GetConnectionCount(top))
+	}
+	if m.table.Cursor() != 0 {
+		t.Errorf("Expected the cursor on the top match, got %d", m.table.Cursor())
+	}
+}
+
+func TestSearchEnterConnectTopWithoutMatches(t *testing.T) {
+	m, cmd := searchAndPressEnter(t, config.SearchEnterConnectTop, "nothing-matches")
+
+	if cmd != nil {
+		t.Error("Enter should not connect when nothing matches")
+	}
+	if m.searchMode || !m.table.Focused() {
+		t.Error("Expected Enter to move to the table")
+	}
+}
+
 func TestSearchModeDoesNotTriggerOnEmptyInput(t *testing.T) {
 	m := createTestModel()
 	originalHostCount := len(m.hosts)
@@ -303,3 +384,37 @@ func TestSearchByUser(t *testing.T) {
 		t.Errorf("Expected 'server1' to match user search, got '%s'", m.filteredHosts[0].Name)
 	}
 }
+
+func TestSearchRanksBestMatchesFirst(t *testing.T) {
+	m := createTestModel()
+	m = pressKey(t, m, "/")
+	for _, char := range "server" {
+		m = pressKey(t, m, string(char))
+	}
+
+	// Names starting with the query come before the ones containing it
+	names := hostNames(m.filteredHosts)
+	want := []string{"server1", "server2", "server3", "db-server", "web-server"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
+
+func TestSearchFuzzyMatchHighlightsName(t *testing.T) {
+	m := createTestModel()
+	m = pressKey(t, m, "/")
+	for _, char := range "wsv" {
+		m = pressKey(t, m, string(char))
+	}
+
+	if names := hostNames(m.filteredHosts); len(names) != 1 || names[0] != "web-server" {
+		t.Fatalf("Expected wsv to match web-server only, got %v", names)
+	}
+	if name := extractHostNameFromTableRow(m.table.Rows()[0][0]); name != "web-server" {
+		t.Errorf("Expected the row to stay plain and name web-server, got %q", name)
+	}
+	view := m.highlightTableMatches(m.table.View())
+	if !strings.Contains(view, matchHighlightOn+"w"+matchHighlightOff) || !strings.Contains(view, matchHighlightOn+"v"+matchHighlightOff) {
+		t.Errorf("Expected the matched characters highlighted, got %q", view)
+	}
+}
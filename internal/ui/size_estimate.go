@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sizeEstimate is the measured size of what a transfer copies: the local path
+// of an upload or the remote path of a download
+type sizeEstimate struct {
+	path    string
+	loading bool
+	size    int64
+	files   int
+	err     error
+}
+
+// sizeEstimateMsg carries the size of a path measured in the background
+type sizeEstimateMsg struct {
+	path  string
+	size  int64
+	files int
+	err   error
+}
+
+// estimateRemoteSize measures a remote path over session, or over a new
+// session when it is nil; replaced in tests
+var estimateRemoteSize = func(session *transfer.SFTPSession, hostName, configFile, path string) (int64, int, error) {
+	if session == nil {
+		var err error
+		session, err = transfer.NewSFTPSession(hostName, configFile)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer session.Close()
+	}
+	return session.EstimateRemoteSize(path)
+}
+
+// startSizeEstimate measures path in the background, local for uploads and
+// remote for downloads, unless it is already measured or being measured.
+// Remote paths are measured over session when it is not nil.
+func startSizeEstimate(e *sizeEstimate, session *transfer.SFTPSession, hostName, configFile string, direction transfer.Direction, path string) tea.Cmd {
+	if path == "" || e.path == path {
+		return nil
+	}
+	*e = sizeEstimate{path: path, loading: true}
+
+	return func() tea.Msg {
+		var size int64
+		var files int
+		var err error
+		if direction == transfer.Upload {
+			size, files, err = transfer.EstimateLocalSize(path)
+		} else {
+			size, files, err = estimateRemoteSize(session, hostName, configFile, path)
+		}
+		return sizeEstimateMsg{path: path, size: size, files: files, err: err}
+	}
+}
+
+// apply records a measured size, ignoring results for a path changed since
+func (e *sizeEstimate) apply(msg sizeEstimateMsg) bool {
+	if msg.path != e.path {
+		return false
+	}
+	e.loading = false
+	e.size, e.files, e.err = msg.size, msg.files, msg.err
+	return true
+}
+
+// summary describes the estimate of path, empty when there is none or it failed
+func (e sizeEstimate) summary(path string) string {
+	switch {
+	case path == "" || e.path != path || e.err != nil:
+		return ""
+	case e.loading:
+		return "Measuring size..."
+	}
+	return transfer.FormatSizeEstimate(e.size, e.files)
+}
+
+// measured returns the size measured for path, 0 when it is not known
+func (e sizeEstimate) measured(path string) int64 {
+	if e.path != path || e.loading || e.err != nil {
+		return 0
+	}
+	return e.size
+}
+
+// exceeds reports whether path was measured larger than limit; a zero limit never is
+func (e sizeEstimate) exceeds(path string, limit int64) bool {
+	return limit > 0 && e.path == path && !e.loading && e.err == nil && e.size > limit
+}
+
+// transferConfirmSize returns the transfer size confirmed first, from the app config
+func transferConfirmSize() int64 {
+	appConfig, err := config.LoadAppConfig()
+	if err != nil || appConfig == nil {
+		return config.DefaultTransferConfirmSizeMB << 20
+	}
+	return appConfig.TransferConfirmSize()
+}
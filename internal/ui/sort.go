@@ -35,72 +35,57 @@ func sortHostsByName(hosts []config.SSHHost) []config.SSHHost {
 	return sorted
 }
 
-// filterHosts filters hosts according to the search query (name or tags)
-func (m Model) filterHosts(query string) []config.SSHHost {
-	subqueries := strings.Split(query, " ")
-	subqueriesLength := len(subqueries)
-	subfilteredHosts := make([][]config.SSHHost, subqueriesLength)
-	for i, subquery := range subqueries {
-		subfilteredHosts[i] = m.filterHostsByWord(subquery)
+// setSortMode switches the sort mode and re-applies the current filter. The
+// mode being replaced is remembered for toggleSortMode.
+func (m *Model) setSortMode(mode SortMode) {
+	if mode != m.sortMode {
+		m.previousSortMode = m.sortMode
+		m.hasPreviousSort = true
 	}
+	m.sortMode = mode
 
-	// return the intersection of search results
-	result := make([]config.SSHHost, 0)
-	tempMap := map[string]int{}
-	for _, hosts := range subfilteredHosts {
-		for _, host := range hosts {
-			if _, ok := tempMap[host.Name]; !ok {
-				tempMap[host.Name] = 1
-			} else {
-				tempMap[host.Name] = tempMap[host.Name] + 1
-			}
-
-			if tempMap[host.Name] == subqueriesLength {
-				result = append(result, host)
-			}
-		}
+	if m.searchInput.Value() != "" {
+		m.filteredHosts = m.filterHosts(m.searchInput.Value())
+	} else {
+		m.filteredHosts = m.sortHosts(m.visibleHosts())
 	}
-
-	return result
+	m.updateTableRows()
 }
 
-// filterHostsByWord filters hosts according to a single word
-func (m Model) filterHostsByWord(word string) []config.SSHHost {
-	var filtered []config.SSHHost
-
-	if word == "" {
-		filtered = m.hosts
-	} else {
-		word = strings.ToLower(word)
-
-		for _, host := range m.hosts {
-			// Check the hostname
-			if strings.Contains(strings.ToLower(host.Name), word) {
-				filtered = append(filtered, host)
-				continue
-			}
-
-			// Check the hostname
-			if strings.Contains(strings.ToLower(host.Hostname), word) {
-				filtered = append(filtered, host)
-				continue
-			}
-
-			// Check the user
-			if strings.Contains(strings.ToLower(host.User), word) {
-				filtered = append(filtered, host)
-				continue
-			}
+// toggleSortMode swaps between the current and the previously used sort mode,
+// falling back to the next mode of the cycle before any switch
+func (m *Model) toggleSortMode() {
+	previous := m.previousSortMode
+	if !m.hasPreviousSort {
+		previous = (m.sortMode + 1) % 2
+	}
+	m.setSortMode(previous)
+}
 
-			// Check the tags
-			for _, tag := range host.Tags {
-				if strings.Contains(strings.ToLower(tag), word) {
-					filtered = append(filtered, host)
-					break
-				}
-			}
-		}
+// applySavedView filters and sorts the host list with a saved view's query and sort mode
+func (m *Model) applySavedView(view config.SavedView) {
+	if mode := sortModeFromKey(view.Sort); mode != m.sortMode {
+		m.previousSortMode = m.sortMode
+		m.hasPreviousSort = true
+		m.sortMode = mode
 	}
+	m.searchInput.SetValue(view.Query)
+	m.searchMode = false
+	m.searchInput.Blur()
+	m.filteredHosts = m.filterHosts(view.Query)
+	m.updateTableStyles()
+	m.updateTableRows()
+	m.table.SetCursor(0)
+	m.table.Focus()
+}
 
-	return m.sortHosts(filtered)
+// filterHosts fuzzy matches the query against the hosts' name, hostname, user
+// and tags, best matches first. Hosts matching equally well keep the sort order.
+func (m Model) filterHosts(query string) []config.SSHHost {
+	matches := config.FuzzyMatchHosts(m.sortHosts(m.visibleHosts()), query)
+	filtered := make([]config.SSHHost, len(matches))
+	for i, match := range matches {
+		filtered[i] = match.Host
+	}
+	return filtered
 }
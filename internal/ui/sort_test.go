@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pressKey sends a single rune key to the model
+func pressKey(t *testing.T, m Model, key string) Model {
+	t.Helper()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return updated.(Model)
+}
+
+func TestToggleSortModeSwapsWithPrevious(t *testing.T) {
+	m := createTestModel()
+
+	// Before any switch, the toggle moves to the next mode
+	m = pressKey(t, m, "S")
+	if m.sortMode != SortByLastUsed {
+		t.Fatalf("Expected the first toggle to sort by last login, got %v", m.sortMode)
+	}
+
+	m = pressKey(t, m, "S")
+	if m.sortMode != SortByName || m.previousSortMode != SortByLastUsed {
+		t.Errorf("Expected to swap back to name, got %v (previous %v)", m.sortMode, m.previousSortMode)
+	}
+
+	m = pressKey(t, m, "S")
+	if m.sortMode != SortByLastUsed || m.previousSortMode != SortByName {
+		t.Errorf("Expected to swap again to last login, got %v (previous %v)", m.sortMode, m.previousSortMode)
+	}
+}
+
+func TestToggleSortModeRemembersDirectSwitch(t *testing.T) {
+	m := createTestModel()
+
+	m = pressKey(t, m, "r")
+	if m.sortMode != SortByLastUsed || m.previousSortMode != SortByName {
+		t.Fatalf("Expected r to remember name as previous, got %v (previous %v)", m.sortMode, m.previousSortMode)
+	}
+
+	// Selecting the active mode again keeps the remembered one
+	m = pressKey(t, m, "r")
+	if m.previousSortMode != SortByName {
+		t.Errorf("Re-selecting the active mode should keep the previous mode, got %v", m.previousSortMode)
+	}
+
+	m = pressKey(t, m, "S")
+	if m.sortMode != SortByName {
+		t.Errorf("Expected S to go back to name, got %v", m.sortMode)
+	}
+}
+
+func TestToggleSortModeRefreshesList(t *testing.T) {
+	m := createTestModel()
+	m.searchInput.SetValue("server")
+	m.filteredHosts = m.filterHosts("server")
+	m.updateTableRows()
+	before := len(m.table.Rows())
+
+	m = pressKey(t, m, "S")
+	m = pressKey(t, m, "S")
+	if len(m.table.Rows()) != before {
+		t.Errorf("Expected the active search to be kept, got %d rows instead of %d", len(m.table.Rows()), before)
+	}
+}
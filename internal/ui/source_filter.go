@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sourceFileEntry is a config file offered by the source filter, with the
+// number of hosts it defines. The empty path stands for all files.
+type sourceFileEntry struct {
+	path  string
+	hosts int
+}
+
+// sourceFilterModel lets the user list only the hosts of one of the config
+// files loaded through Include
+type sourceFilterModel struct {
+	entries []sourceFileEntry
+	active  string // File the list is filtered on, empty for all files
+	cursor  int
+	styles  Styles
+	width   int
+	height  int
+}
+
+// sourceFilterMsg is sent when a file is chosen, with an empty path for all files
+type sourceFilterMsg struct {
+	path string
+}
+
+// sourceFilterCancelMsg is sent when the menu is closed without choosing
+type sourceFilterCancelMsg struct{}
+
+// NewSourceFilter lists the config files the hosts come from, after an entry
+// for all of them, with the cursor on the file filtered on
+func NewSourceFilter(hosts []config.SSHHost, active string, styles Styles, width, height int) *sourceFilterModel {
+	m := &sourceFilterModel{
+		entries: sourceFileEntries(hosts),
+		active:  active,
+		styles:  styles,
+		width:   width,
+		height:  height,
+	}
+	for i, e := range m.entries {
+		if e.path == active {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// sourceFileEntries counts the hosts of each source file, files sorted by path
+// after the entry for all files
+func sourceFileEntries(hosts []config.SSHHost) []sourceFileEntry {
+	counts := make(map[string]int)
+	for _, host := range hosts {
+		counts[host.SourceFile]++
+	}
+	paths := make([]string, 0, len(counts))
+	for path := range counts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	entries := []sourceFileEntry{{hosts: len(hosts)}}
+	for _, path := range paths {
+		entries = append(entries, sourceFileEntry{path: path, hosts: counts[path]})
+	}
+	return entries
+}
+
+func (m *sourceFilterModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *sourceFilterModel) Update(msg tea.Msg) (*sourceFilterModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "esc", "q":
+		return m, func() tea.Msg { return sourceFilterCancelMsg{} }
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.entries)-1 {
+			m.cursor++
+		}
+
+	case "enter":
+		path := m.entries[m.cursor].path
+		return m, func() tea.Msg { return sourceFilterMsg{path: path} }
+	}
+
+	return m, nil
+}
+
+func (m *sourceFilterModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render("Show Hosts From"))
+	b.WriteString("\n\n")
+
+	visibleHeight := m.height - 12
+	if visibleHeight < 5 {
+		visibleHeight = 5
+	}
+	start := 0
+	if m.cursor >= visibleHeight {
+		start = m.cursor - visibleHeight + 1
+	}
+	end := start + visibleHeight
+	if end > len(m.entries) {
+		end = len(m.entries)
+	}
+
+	for i := start; i < end; i++ {
+		entry := m.entries[i]
+		name := "All config files"
+		if entry.path != "" {
+			name = displayConfigPath(entry.path)
+		}
+		if i == m.cursor {
+			b.WriteString(m.styles.Selected.Render("▶ " + name))
+		} else {
+			b.WriteString("  " + name)
+		}
+		label := fmt.Sprintf("%d hosts", entry.hosts)
+		if entry.path == m.active {
+			label += ", shown"
+		}
+		b.WriteString("  " + m.styles.HelpText.Render("("+label+")"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Enter: show • Esc: close"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
+
+// openSourceFilter opens the menu of the config files the hosts come from.
+// With a single file there is nothing to choose.
+func (m *Model) openSourceFilter() tea.Cmd {
+	if len(sourceFileEntries(m.hosts)) <= 2 && m.sourceFilter == "" {
+		return notify(NotifyInfo, "All hosts come from "+displayConfigPath(m.activeConfigFile())+" — Include other files to filter by file")
+	}
+	m.sourceFilterForm = NewSourceFilter(m.hosts, m.sourceFilter, m.styles, m.width, m.height)
+	m.viewMode = ViewSourceFilter
+	return nil
+}
+
+// setSourceFilter lists only the hosts defined in path, or all hosts when
+// path is empty
+func (m *Model) setSourceFilter(path string) {
+	m.sourceFilter = path
+	m.refreshFilteredHosts()
+	m.table.SetCursor(0)
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createMultiFileTestModel returns the test model with the web and db hosts
+// defined in included files
+func createMultiFileTestModel() Model {
+	m := createTestModel()
+	for i := range m.hosts {
+		switch m.hosts[i].Name {
+		case "web-server":
+			m.hosts[i].SourceFile = "/etc/ssh/conf.d/web.conf"
+		case "db-server":
+			m.hosts[i].SourceFile = "/etc/ssh/conf.d/db.conf"
+		default:
+			m.hosts[i].SourceFile = "/etc/ssh/config"
+		}
+	}
+	m.refreshFilteredHosts()
+	return m
+}
+
+func TestSourceFileEntries(t *testing.T) {
+	m := createMultiFileTestModel()
+
+	entries := sourceFileEntries(m.hosts)
+	want := []sourceFileEntry{
+		{path: "", hosts: 5},
+		{path: "/etc/ssh/conf.d/db.conf", hosts: 1},
+		{path: "/etc/ssh/conf.d/web.conf", hosts: 1},
+		{path: "/etc/ssh/config", hosts: 3},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("Entry %d: expected %v, got %v", i, want[i], entries[i])
+		}
+	}
+}
+
+func TestSourceFilterFromMenu(t *testing.T) {
+	m := createMultiFileTestModel()
+
+	m = pressKey(t, m, "g")
+	if m.viewMode != ViewSourceFilter || m.sourceFilterForm == nil {
+		t.Fatal("Expected g to open the menu of config files")
+	}
+	if view := m.View(); !strings.Contains(view, "All config files") || !strings.Contains(view, "/etc/ssh/config") {
+		t.Errorf("Expected the menu to list the loaded files, got %q", view)
+	}
+
+	// The main config, after all files and the two included ones
+	for i := 0; i < 3; i++ {
+		m = pressKey(t, m, "j")
+	}
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+
+	if m.viewMode != ViewList || m.sourceFilter != "/etc/ssh/config" {
+		t.Fatalf("Expected the list filtered on the main config, got %q", m.sourceFilter)
+	}
+	if names := hostNames(m.filteredHosts); len(names) != 3 || strings.Contains(strings.Join(names, ","), "web-server") {
+		t.Errorf("Expected only the hosts of the main config, got %v", names)
+	}
+	if !strings.Contains(m.View(), "Search in config") {
+		t.Error("Expected the search prompt to show the file")
+	}
+
+	// The search stays within the file
+	if found := hostNames(m.filterHosts("server")); len(found) != 3 {
+		t.Errorf("Expected the search to stay within the file, got %v", found)
+	}
+
+	// The menu opens on the file shown; going back to all files lists every host
+	m = pressKey(t, m, "g")
+	if m.sourceFilterForm.cursor != 3 {
+		t.Errorf("Expected the cursor on the file shown, got %d", m.sourceFilterForm.cursor)
+	}
+	updated, _ = m.Update(sourceFilterMsg{})
+	if m = updated.(Model); m.sourceFilter != "" || len(m.filteredHosts) != 5 {
+		t.Errorf("Expected all hosts again, got %v", hostNames(m.filteredHosts))
+	}
+}
+
+func TestSourceFilterSingleFile(t *testing.T) {
+	m := createTestModel()
+	for i := range m.hosts {
+		m.hosts[i].SourceFile = "/home/user/.ssh/config"
+	}
+
+	if cmd := m.openSourceFilter(); cmd == nil || m.viewMode == ViewSourceFilter {
+		t.Error("Expected a notice instead of the menu with a single config file")
+	}
+}
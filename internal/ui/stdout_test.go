@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestUIDoesNotWriteToStdout guards against fmt.Print* and os.Stdout in the UI
+// package: anything written to stdout while the alt-screen TUI runs garbles it.
+// Use notifications instead, or the writer handed over by tea.Exec.
+func TestUIDoesNotWriteToStdout(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", file, err)
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			pkg, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			switch {
+			case pkg.Name == "fmt" && strings.HasPrefix(sel.Sel.Name, "Print"):
+				t.Errorf("%s: fmt.%s writes to stdout while the TUI is running", fset.Position(sel.Pos()), sel.Sel.Name)
+			case pkg.Name == "os" && (sel.Sel.Name == "Stdout" || sel.Sel.Name == "Stderr"):
+				t.Errorf("%s: os.%s is written to while the TUI is running", fset.Position(sel.Pos()), sel.Sel.Name)
+			}
+			return true
+		})
+	}
+}
+
+// brokenHistoryManager returns a history manager whose writes fail
+func brokenHistoryManager(t *testing.T) *history.HistoryManager {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatalf("NewHistoryManager() error = %v", err)
+	}
+	// A directory where the history file should be makes every update fail
+	if err := os.MkdirAll(filepath.Join(dir, "sshm", "sshm_history.json"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return hm
+}
+
+func TestConnectHistoryFailureIsNotified(t *testing.T) {
+	m := Model{historyManager: brokenHistoryManager(t)}
+
+	var cmd tea.Cmd
+	output := captureStdout(t, func() {
+		cmd = m.connectToHost("web1", "")
+	})
+	if output != "" {
+		t.Errorf("Expected nothing written to stdout, got %q", output)
+	}
+
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatalf("Expected a batch of commands, got %T", cmd())
+	}
+	// Running the commands is safe: tea.ExecProcess only returns a message,
+	// ssh is started by the program loop
+	var warning *notifyMsg
+	for _, c := range batch {
+		if c == nil {
+			continue
+		}
+		if msg, ok := c().(notifyMsg); ok {
+			warning = &msg
+		}
+	}
+	if warning == nil || warning.level != NotifyWarn || !strings.Contains(warning.text, "connection history") {
+		t.Errorf("Expected a history warning notification, got %+v", warning)
+	}
+}
+
+func TestNewModelWarningsAreNotified(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "sshm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sshm", "config.json"), []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var m Model
+	output := captureStdout(t, func() {
+		m = NewModel(nil, "", "")
+	})
+	if output != "" {
+		t.Errorf("Expected nothing written to stdout, got %q", output)
+	}
+
+	if len(m.notifications.items) == 0 || m.notifications.items[0].level != NotifyWarn {
+		t.Fatalf("Expected a startup warning notification, got %+v", m.notifications.items)
+	}
+	if !strings.Contains(m.notifications.items[0].text, "application config") {
+		t.Errorf("Unexpected warning: %q", m.notifications.items[0].text)
+	}
+}
@@ -1,12 +1,14 @@
 package ui
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/history"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // calculateDynamicColumnWidths calculates optimal column widths based on terminal width
@@ -129,6 +131,10 @@ func (m *Model) updateTableRows() {
 		hostsToShow = m.hosts
 	}
 
+	query := strings.Fields(m.searchInput.Value())
+	m.nameHighlights = nil
+	var positions []int
+
 	for _, host := range hostsToShow {
 		// Get ping status indicator
 		statusIndicator := m.getPingStatusIndicator(host.Name)
@@ -152,6 +158,30 @@ func (m *Model) updateTableRows() {
 			}
 		}
 
+		// Marked and favorite hosts get a check or a star in front of their
+		// status, which keeps extractHostNameFromTableRow working
+		if m.markedHosts[host.Name] {
+			statusIndicator = "✓" + statusIndicator
+		}
+		if m.favorites[host.Name] {
+			statusIndicator = "★" + statusIndicator
+		}
+
+		if len(query) > 0 {
+			positions = positions[:0]
+			for _, word := range query {
+				if _, matched, ok := config.FuzzyMatch(host.Name, word, positions); ok {
+					positions = matched
+				}
+			}
+			if len(positions) > 0 {
+				if m.nameHighlights == nil {
+					m.nameHighlights = make(map[string]string)
+				}
+				m.nameHighlights[host.Name] = highlightMatches(host.Name, positions)
+			}
+		}
+
 		rows = append(rows, table.Row{
 			statusIndicator + " " + host.Name,
 			host.Hostname,
@@ -169,6 +199,72 @@ func (m *Model) updateTableRows() {
 	m.updateTableColumns()
 }
 
+// Search matches in host names are shown bold and underlined. Only these
+// attributes are switched off after a match, so a selected row keeps its colors.
+const (
+	matchHighlightOn  = "\x1b[1;4m"
+	matchHighlightOff = "\x1b[22;24m"
+)
+
+// highlightMatches returns the name with the characters at the given byte
+// offsets highlighted
+func highlightMatches(name string, positions []int) string {
+	var b strings.Builder
+	open := false
+	for i, r := range name {
+		matched := slices.Contains(positions, i)
+		if matched && !open {
+			b.WriteString(matchHighlightOn)
+		} else if !matched && open {
+			b.WriteString(matchHighlightOff)
+		}
+		open = matched
+		b.WriteRune(r)
+	}
+	if open {
+		b.WriteString(matchHighlightOff)
+	}
+	return b.String()
+}
+
+// highlightTableMatches highlights the search matches in the host names of
+// the rendered table. The table truncates cells without knowing about escape
+// sequences, so the rows themselves stay plain and the highlights are added
+// to the names it drew in full.
+func (m Model) highlightTableMatches(view string) string {
+	if len(m.nameHighlights) == 0 {
+		return view
+	}
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		// The name follows the status indicator in the first column
+		fields := strings.Fields(ansi.Strip(line))
+		if len(fields) < 2 {
+			continue
+		}
+		if highlighted, ok := m.nameHighlights[fields[1]]; ok {
+			lines[i] = strings.Replace(line, " "+fields[1]+" ", " "+highlighted+" ", 1)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// bannerHeight is how many lines the ASCII title takes above the table
+const bannerHeight = 5
+
+// toggleBanner collapses or expands the ASCII title, giving its rows to the
+// table, and remembers the choice in the app config
+func (m *Model) toggleBanner() error {
+	m.hideBanner = !m.hideBanner
+	m.updateTableHeight()
+
+	if m.appConfig == nil {
+		return nil
+	}
+	m.appConfig.HideBanner = m.hideBanner
+	return config.SetHideBanner(m.hideBanner)
+}
+
 // updateTableHeight dynamically adjusts table height based on terminal size
 func (m *Model) updateTableHeight() {
 	if !m.ready {
@@ -177,7 +273,7 @@ func (m *Model) updateTableHeight() {
 
 	// Calculate dynamic table height based on terminal size
 	// Layout breakdown:
-	// - ASCII title: 5 lines (1 empty + 4 text lines)
+	// - ASCII title: 5 lines (1 empty + 4 text lines), unless collapsed
 	// - Update banner : 1 line (if present)
 	// - Search bar: 1 line
 	// - Help text: 1 line
@@ -185,6 +281,9 @@ func (m *Model) updateTableHeight() {
 	// - Safety margin: 3 lines (to ensure UI elements are always visible)
 	// Total reserved: 14 lines minimum to preserve essential UI elements
 	reservedHeight := 14
+	if m.hideBanner {
+		reservedHeight -= bannerHeight
+	}
 	availableHeight := m.height - reservedHeight
 	hostCount := len(m.table.Rows())
 
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cycleTagFilter lists only the hosts of the next tag in alphabetical order,
+// and all hosts again after the last tag
+func (m *Model) cycleTagFilter() tea.Cmd {
+	tags := config.HostTags(m.hosts)
+	if len(tags) == 0 {
+		m.setTagFilter("")
+		return notify(NotifyInfo, "No host has tags — add a '# Tags: prod, web' comment above a Host")
+	}
+
+	next := tags[0]
+	for i, tag := range tags {
+		if strings.EqualFold(tag, m.tagFilter) {
+			next = ""
+			if i+1 < len(tags) {
+				next = tags[i+1]
+			}
+			break
+		}
+	}
+	m.setTagFilter(next)
+	return nil
+}
+
+// setTagFilter lists only the hosts of tag, or all hosts when tag is empty
+func (m *Model) setTagFilter(tag string) {
+	m.tagFilter = tag
+	m.refreshFilteredHosts()
+	m.table.SetCursor(0)
+}
+
+// findTag returns the tag of a host matching name, ignoring case
+func (m Model) findTag(name string) (string, bool) {
+	for _, tag := range config.HostTags(m.hosts) {
+		if strings.EqualFold(tag, name) {
+			return tag, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+// createTaggedTestModel returns the test model with web-server and server1
+// tagged prod, and db-server tagged db
+func createTaggedTestModel() Model {
+	m := createTestModel()
+	for i := range m.hosts {
+		switch m.hosts[i].Name {
+		case "web-server", "server1":
+			m.hosts[i].Tags = []string{"prod", "web"}
+		case "db-server":
+			m.hosts[i].Tags = []string{"db"}
+		}
+	}
+	m.refreshFilteredHosts()
+	return m
+}
+
+func TestTagFilterCycles(t *testing.T) {
+	m := createTaggedTestModel()
+
+	for _, want := range []struct {
+		tag   string
+		hosts int
+	}{{"db", 1}, {"prod", 2}, {"web", 2}, {"", 5}, {"db", 1}} {
+		m = pressKey(t, m, "#")
+		if m.tagFilter != want.tag || len(m.filteredHosts) != want.hosts {
+			t.Fatalf("Expected tag %q with %d hosts, got %q with %v", want.tag, want.hosts, m.tagFilter, hostNames(m.filteredHosts))
+		}
+	}
+	if !strings.Contains(m.View(), "Search #db") {
+		t.Error("Expected the search prompt to show the tag filter")
+	}
+
+	// The search only looks among the hosts of the tag
+	m.setTagFilter("prod")
+	if found := hostNames(m.filterHosts("server")); len(found) != 2 {
+		t.Errorf("Expected the search to stay within the tag, got %v", found)
+	}
+}
+
+func TestTagFilterWithoutTags(t *testing.T) {
+	m := createTestModel()
+	if cmd := m.cycleTagFilter(); cmd == nil || m.tagFilter != "" {
+		t.Errorf("Expected a notice and no filter without tags, got %q", m.tagFilter)
+	}
+}
+
+func TestTagCommand(t *testing.T) {
+	m := createTaggedTestModel()
+
+	updated, _ := m.runCommand("tag #PROD")
+	m = updated.(Model)
+	if m.tagFilter != "prod" || len(m.filteredHosts) != 2 {
+		t.Fatalf("Expected :tag to select prod, got %q with %v", m.tagFilter, hostNames(m.filteredHosts))
+	}
+
+	if _, cmd := m.runCommand("tag nope"); cmd == nil {
+		t.Error("Expected an error for an unknown tag")
+	}
+
+	if value, candidates := m.completeCommand("tag "); value != "tag " || strings.Join(candidates, ",") != "db,none,prod,web" {
+		t.Errorf("Expected the tags as completions, got %q %v", value, candidates)
+	}
+
+	// Selecting a host outside the tag clears the filter
+	if !m.selectHost("server2") || m.tagFilter != "" {
+		t.Errorf("Expected the tag filter cleared to reach server2, got %q", m.tagFilter)
+	}
+
+	updated, _ = m.runCommand("tag db")
+	m = updated.(Model)
+	updated, _ = m.runCommand("tag none")
+	if m = updated.(Model); m.tagFilter != "" || len(m.filteredHosts) != 5 {
+		t.Errorf("Expected :tag none to list all hosts, got %q", m.tagFilter)
+	}
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tmuxWindowName names the window holding the panes opened by sshm
+const tmuxWindowName = "sshm"
+
+// insideTmux reports whether sshm runs inside a tmux session
+func insideTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// tmuxCommands returns the tmux invocations opening a window with one pane
+// per host running ssh, tiled after every split so that any number of panes fits
+func tmuxCommands(hostNames []string, configFile string) [][]string {
+	var commands [][]string
+	for i, hostName := range hostNames {
+		ssh := transfer.ShellCommand("ssh", config.BuildConnectArgs(hostName, configFile, "")...)
+		if i == 0 {
+			commands = append(commands, []string{"tmux", "new-window", "-n", tmuxWindowName, ssh})
+			continue
+		}
+		// The new window is the current one, splits and layouts apply to it
+		commands = append(commands,
+			[]string{"tmux", "split-window", ssh},
+			[]string{"tmux", "select-layout", "tiled"})
+	}
+	return commands
+}
+
+// connectInTmux records the connections and opens a tmux window with a pane
+// per host, or explains that tmux is needed when sshm runs outside of it
+func (m Model) connectInTmux(hostNames []string) tea.Cmd {
+	if !insideTmux() {
+		return notify(NotifyWarn, "Not inside tmux: start sshm in a tmux session to open hosts in panes")
+	}
+
+	// Record the connections in history
+	var warn tea.Cmd
+	if m.historyManager != nil {
+		for _, hostName := range hostNames {
+			if err := m.historyManager.RecordConnection(hostName); err != nil {
+				// Report the error but don't prevent the connections
+				warn = notify(NotifyWarn, fmt.Sprintf("Could not record connection history: %v", err))
+				break
+			}
+		}
+	}
+
+	commands := tmuxCommands(hostNames, m.configFile)
+	return tea.Batch(warn, func() tea.Msg {
+		for _, args := range commands {
+			if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+				if msg := strings.TrimSpace(string(out)); msg != "" {
+					err = fmt.Errorf("%w: %s", err, msg)
+				}
+				return notifyMsg{level: NotifyError, text: fmt.Sprintf("tmux %s failed: %v", args[1], err)}
+			}
+		}
+		return notifyMsg{level: NotifySuccess, text: fmt.Sprintf("Opened %d host(s) in tmux panes", len(hostNames))}
+	})
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTmuxCommands(t *testing.T) {
+	got := tmuxCommands([]string{"web1", "web2", "db1"}, "")
+	expected := [][]string{
+		{"tmux", "new-window", "-n", "sshm", "ssh web1"},
+		{"tmux", "split-window", "ssh web2"},
+		{"tmux", "select-layout", "tiled"},
+		{"tmux", "split-window", "ssh db1"},
+		{"tmux", "select-layout", "tiled"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("tmuxCommands() = %q, want %q", got, expected)
+	}
+
+	// A single host only needs the window
+	got = tmuxCommands([]string{"web1"}, "/home/me/my configs/ssh")
+	expected = [][]string{{"tmux", "new-window", "-n", "sshm", "ssh -F '/home/me/my configs/ssh' web1"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("tmuxCommands() with -F = %q, want %q", got, expected)
+	}
+
+	if got := tmuxCommands(nil, ""); len(got) != 0 {
+		t.Errorf("Expected no commands for no hosts, got %q", got)
+	}
+}
+
+func TestConnectInTmuxOutsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "")
+	m := createTestModel()
+
+	msg := m.connectInTmux([]string{"server1"})()
+	n, ok := msg.(notifyMsg)
+	if !ok || n.level != NotifyWarn || !strings.Contains(n.text, "Not inside tmux") {
+		t.Errorf("Expected a warning outside of tmux, got %#v", msg)
+	}
+}
@@ -1,12 +1,16 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/history"
 	"github.com/Gu1llaum-3/sshm/internal/transfer"
 
@@ -18,9 +22,13 @@ import (
 // Input field indices for transfer form
 const (
 	tfDirectionInput = iota
-	tfUploadTypeInput // File or Folder toggle (only shown for uploads)
+	tfUploadTypeInput // File or Folder toggle
 	tfLocalPathInput
 	tfRemotePathInput
+	tfUserInput // Optional one-off user override
+	tfPortInput // Optional one-off port override
+	tfJumpHostInput // Optional one-off jump host, passed as -J
+	tfLimitInput // Optional bandwidth limit in KB/s
 )
 
 // UploadType determines whether to upload a file or folder
@@ -43,9 +51,65 @@ type transferFormModel struct {
 	height         int
 	configFile     string
 	historyManager *history.HistoryManager
+	pathStore      *history.RemotePathStore
+	scpExtraArgs   []string
+	preferTUI      bool // Use the TUI browser instead of the native picker
 	historyItems   []history.TransferHistoryEntry
 	historyIndex   int // -1 means no history item selected
 	showHistory    bool
+	historyOff     bool // Transfers are not recorded: there is no history to show
+	historyByCount bool // Order history by how often transfers were run
+	historyPaths   historyPathDisplay // How paths are shown in the history
+	completion     remoteCompletion
+
+	recursiveDefault bool            // The host defaults to folder transfers
+	startDirs        remoteStartDirs // Where the remote browser opens when no remote path is typed
+	saveAsPath       string          // Remote file picked as the destination of an upload
+
+	estimate            sizeEstimate // Size of the path being transferred
+	confirmSize         int64        // Transfers larger than this are confirmed first, 0 never
+	confirmLarge        bool         // Enter again transfers despite the size
+	submitAfterEstimate bool         // Enter was pressed while the upload was being measured
+}
+
+// historyPathDisplay is how paths are shown in the transfer history
+type historyPathDisplay int
+
+const (
+	historyPathsElided historyPathDisplay = iota // Long paths shortened in the middle
+	historyPathsBase                             // File names only
+	historyPathsFull                             // Whole paths, wrapped
+)
+
+func (d historyPathDisplay) String() string {
+	switch d {
+	case historyPathsBase:
+		return "names only"
+	case historyPathsFull:
+		return "full"
+	default:
+		return "shortened"
+	}
+}
+
+// next returns the display Ctrl+T switches to
+func (d historyPathDisplay) next() historyPathDisplay {
+	return (d + 1) % 3
+}
+
+// format shows a history path; remote paths always use forward slashes
+func (d historyPathDisplay) format(p string, remote bool) string {
+	switch d {
+	case historyPathsBase:
+		if remote {
+			return path.Base(p)
+		}
+		return filepath.Base(p)
+	case historyPathsFull:
+		return p
+	default:
+		return truncatePath(p, 25)
+	}
 }
 
 // transferSubmitMsg is sent when the transfer form is submitted
@@ -62,6 +126,7 @@ type filePickerResultMsg struct {
 	path     string
 	selected bool
 	isLocal  bool // true for local path, false for remote
+	saveAs   bool // The remote path names a new file, picked in BrowseSaveAs mode
 }
 
 // NewTransferForm creates a new transfer form model
@@ -69,7 +134,15 @@ func NewTransferForm(hostName string, styles Styles, width, height int, configFi
 	// Initialize history manager
 	historyManager, _ := history.NewHistoryManager()
 
-	inputs := make([]textinput.Model, 4)
+	// Load extra scp arguments and picker preference from the app config
+	var scpExtraArgs []string
+	var preferTUI bool
+	if appConfig, err := config.LoadAppConfig(); err == nil {
+		scpExtraArgs = appConfig.ScpExtraArgs
+		preferTUI = appConfig.PreferTUIPicker
+	}
+
+	inputs := make([]textinput.Model, 8)
 
 	// Direction input (display only, controlled by arrow keys)
 	inputs[tfDirectionInput] = textinput.New()
@@ -91,26 +164,75 @@ func NewTransferForm(hostName string, styles Styles, width, height int, configFi
 	inputs[tfLocalPathInput].CharLimit = 500
 	inputs[tfLocalPathInput].Width = 60
 
-	// Remote path input
+	// Remote path input, suggesting directories used before on this host.
+	// Tab accepts a suggestion or completes from the remote directory listing.
 	inputs[tfRemotePathInput] = textinput.New()
 	inputs[tfRemotePathInput].Placeholder = "~/"
 	inputs[tfRemotePathInput].CharLimit = 500
 	inputs[tfRemotePathInput].Width = 60
 
+	pathStore, _ := history.NewRemotePathStore()
+	if pathStore != nil {
+		var suggestions []string
+		for _, p := range pathStore.Suggestions(hostName) {
+			suggestions = append(suggestions, strings.TrimSuffix(p, "/")+"/")
+		}
+		inputs[tfRemotePathInput].ShowSuggestions = true
+		inputs[tfRemotePathInput].SetSuggestions(suggestions)
+	}
+
+	// User and port overrides, left empty to use the SSH config
+	inputs[tfUserInput] = textinput.New()
+	inputs[tfUserInput].Placeholder = "from SSH config"
+	inputs[tfUserInput].CharLimit = 100
+	inputs[tfUserInput].Width = 20
+
+	inputs[tfPortInput] = textinput.New()
+	inputs[tfPortInput].Placeholder = "from SSH config"
+	inputs[tfPortInput].CharLimit = 5
+	inputs[tfPortInput].Width = 20
+
+	inputs[tfJumpHostInput] = textinput.New()
+	inputs[tfJumpHostInput].Placeholder = "config host or user@host (default: ProxyJump from SSH config)"
+	inputs[tfJumpHostInput].CharLimit = 200
+	inputs[tfJumpHostInput].Width = 60
+
+	inputs[tfLimitInput] = textinput.New()
+	inputs[tfLimitInput].Placeholder = "unlimited"
+	inputs[tfLimitInput].CharLimit = 9
+	inputs[tfLimitInput].Width = 20
+
+	// Hosts can default to folder transfers
+	hostDefaults, _ := config.LoadHostTransferDefaults(hostName)
+	uploadType := UploadFile
+	if hostDefaults.Recursive {
+		uploadType = UploadFolder
+	}
+
 	m := &transferFormModel{
 		inputs:         inputs,
 		focused:        0,
 		direction:      direction,
-		uploadType:     UploadFile, // Default to file
+		uploadType:     uploadType,
 		hostName:       hostName,
 		styles:         styles,
 		width:          width,
 		height:         height,
 		configFile:     configFile,
 		historyManager: historyManager,
+		pathStore:      pathStore,
+		scpExtraArgs:   scpExtraArgs,
+		preferTUI:      preferTUI,
 		historyIndex:   -1,
 		showHistory:    true,
+		confirmSize:    transferConfirmSize(),
 	}
+	m.recursiveDefault = hostDefaults.Recursive
+	if historyManager != nil && !historyManager.RecordsTransfers() {
+		m.historyOff = true
+		m.showHistory = false
+	}
+	m.startDirs = loadRemoteStartDirs(hostName, pathStore)
 
 	// Set initial direction display
 	if direction == transfer.Upload {
@@ -129,8 +251,12 @@ func NewTransferForm(hostName string, styles Styles, width, height int, configFi
 }
 
 func (m *transferFormModel) loadHistory() {
-	if m.historyManager != nil {
-		m.historyItems = m.historyManager.GetTransferHistory(m.hostName)
+	if m.historyManager != nil && !m.historyOff {
+		if m.historyByCount {
+			m.historyItems = m.historyManager.GetTransferHistoryByFrequency(m.hostName)
+		} else {
+			m.historyItems = m.historyManager.GetTransferHistory(m.hostName)
+		}
 	}
 }
 
@@ -175,6 +301,15 @@ func (m *transferFormModel) openLocalFilePicker() tea.Cmd {
 			}
 		}
 
+		// Use the TUI browser when there is no native dialog or it is disabled
+		if !transfer.UseNativePicker(m.preferTUI) {
+			path, selected, err := RunLocalBrowser(startDir, browserModeFor(mode))
+			if err != nil || !selected {
+				return filePickerResultMsg{selected: false, isLocal: true}
+			}
+			return filePickerResultMsg{path: path, selected: true, isLocal: true}
+		}
+
 		result, err := transfer.OpenFilePicker(mode, title, startDir)
 		if err != nil || result == nil || !result.Selected {
 			return filePickerResultMsg{selected: false, isLocal: true}
@@ -190,21 +325,32 @@ func (m *transferFormModel) openLocalFilePicker() tea.Cmd {
 
 func (m *transferFormModel) openRemoteFilePicker() tea.Cmd {
 	return func() tea.Msg {
-		// Determine browser mode based on direction
-		var mode BrowserMode
-		if m.direction == transfer.Upload {
-			mode = BrowseDirectories
-		} else {
-			mode = BrowseFiles
-		}
-
-		// Get starting path
+		// Start in the typed path, else where this direction usually goes
 		startPath := m.inputs[tfRemotePathInput].Value()
+		if startPath != "" && startPath == m.saveAsPath {
+			startPath = path.Dir(startPath)
+		}
 		if startPath == "" {
-			startPath = "~"
+			startPath = m.startDirs.startPath(m.direction)
 		}
 
-		// Run the TUI browser
+		// A file is uploaded under the name picked, starting from its own;
+		// folders go into a directory and downloads pick what they copy
+		if m.direction == transfer.Upload && m.uploadType != UploadFolder {
+			var name string
+			if local := strings.TrimSpace(m.inputs[tfLocalPathInput].Value()); local != "" {
+				name = filepath.Base(local)
+			}
+			path, selected, err := RunRemoteSaveAs(m.hostName, startPath, m.configFile, name)
+			if err != nil || !selected {
+				return filePickerResultMsg{selected: false, isLocal: false}
+			}
+			return filePickerResultMsg{path: path, selected: true, isLocal: false, saveAs: true}
+		}
+		mode := BrowseFiles
+		if m.direction == transfer.Upload || m.uploadType == UploadFolder {
+			mode = BrowseDirectories
+		}
 		path, selected, err := RunRemoteBrowser(m.hostName, startPath, m.configFile, mode)
 		if err != nil {
 			return filePickerResultMsg{selected: false, isLocal: false}
@@ -224,12 +370,8 @@ func (m *transferFormModel) openRemoteFilePicker() tea.Cmd {
 // getNextFocusField returns the next focusable field index
 func (m *transferFormModel) getNextFocusField(current int) int {
 	next := current + 1
-	// Skip upload type field if in download mode
-	if next == tfUploadTypeInput && m.direction == transfer.Download {
-		next++
-	}
-	if next > tfRemotePathInput {
-		next = tfRemotePathInput
+	if next > tfLimitInput {
+		next = tfLimitInput
 	}
 	return next
 }
@@ -237,10 +379,6 @@ func (m *transferFormModel) getNextFocusField(current int) int {
 // getPrevFocusField returns the previous focusable field index
 func (m *transferFormModel) getPrevFocusField(current int) int {
 	prev := current - 1
-	// Skip upload type field if in download mode
-	if prev == tfUploadTypeInput && m.direction == transfer.Download {
-		prev--
-	}
 	if prev < tfDirectionInput {
 		prev = tfDirectionInput
 	}
@@ -257,13 +395,36 @@ func (m *transferFormModel) Update(msg tea.Msg) (*transferFormModel, tea.Cmd) {
 				m.inputs[tfLocalPathInput].SetValue(msg.path)
 			} else {
 				m.inputs[tfRemotePathInput].SetValue(msg.path)
+				m.saveAsPath = ""
+				if msg.saveAs {
+					m.saveAsPath = msg.path
+				}
 			}
+			return m, m.estimateSize()
+		}
+		return m, nil
+
+	case remoteCompletionMsg:
+		m.handleRemoteCompletion(msg)
+		return m, nil
+
+	case sizeEstimateMsg:
+		if m.estimate.apply(msg) && m.submitAfterEstimate {
+			m.submitAfterEstimate = false
+			return m, m.confirmOrSubmit()
 		}
 		return m, nil
 
 	case tea.KeyMsg:
+		// A large transfer is confirmed by pressing Enter right away
+		if msg.String() != "enter" {
+			m.confirmLarge = false
+			m.submitAfterEstimate = false
+		}
+
 		switch msg.String() {
 		case "esc", "ctrl+c":
+			m.closeCompletion()
 			return m, func() tea.Msg { return transferCancelMsg{} }
 
 		case "enter":
@@ -288,8 +449,8 @@ func (m *transferFormModel) Update(msg tea.Msg) (*transferFormModel, tea.Cmd) {
 				m.inputs[m.focused].Focus()
 				return m, textinput.Blink
 			}
-			// If on remote path, submit
-			return m, m.submitForm()
+			// If on remote path or an override, submit
+			return m, m.confirmOrSubmit()
 
 		case "shift+tab", "up":
 			prev := m.getPrevFocusField(m.focused)
@@ -301,6 +462,9 @@ func (m *transferFormModel) Update(msg tea.Msg) (*transferFormModel, tea.Cmd) {
 			}
 
 		case "tab", "down":
+			if msg.String() == "tab" && m.focused == tfRemotePathInput && m.inputs[tfRemotePathInput].Value() != "" {
+				return m, m.completeRemoteInput()
+			}
 			next := m.getNextFocusField(m.focused)
 			if next != m.focused {
 				m.inputs[m.focused].Blur()
@@ -334,7 +498,38 @@ func (m *transferFormModel) Update(msg tea.Msg) (*transferFormModel, tea.Cmd) {
 
 		case "ctrl+h":
 			// Toggle history display
-			m.showHistory = !m.showHistory
+			m.showHistory = !m.showHistory && !m.historyOff
+			return m, nil
+
+		case "ctrl+r":
+			// Make the current File/Folder choice the default for this host
+			if m.focused == tfUploadTypeInput {
+				recursive := !m.recursiveDefault
+				if err := config.SetHostRecursiveDefault(m.hostName, recursive); err != nil {
+					m.err = err.Error()
+					return m, nil
+				}
+				m.recursiveDefault = recursive
+				if recursive {
+					m.uploadType = UploadFolder
+				} else {
+					m.uploadType = UploadFile
+				}
+				return m, nil
+			}
+
+		case "ctrl+t":
+			// Cycle the history between shortened paths, file names and full paths
+			if m.showHistory {
+				m.historyPaths = m.historyPaths.next()
+				return m, nil
+			}
+
+		case "ctrl+f":
+			// Switch the history between most recent and most frequent first
+			m.historyByCount = !m.historyByCount
+			m.historyIndex = -1
+			m.loadHistory()
 			return m, nil
 
 		case "ctrl+p", "ctrl+n":
@@ -345,12 +540,14 @@ func (m *transferFormModel) Update(msg tea.Msg) (*transferFormModel, tea.Cmd) {
 					if m.historyIndex < len(m.historyItems)-1 {
 						m.historyIndex++
 						m.applyHistoryItem(m.historyIndex)
+						return m, m.estimateSize()
 					}
 				} else {
 					// Next (newer) history item
 					if m.historyIndex > 0 {
 						m.historyIndex--
 						m.applyHistoryItem(m.historyIndex)
+						return m, m.estimateSize()
 					} else if m.historyIndex == 0 {
 						m.historyIndex = -1
 						// Clear inputs
@@ -368,12 +565,12 @@ func (m *transferFormModel) Update(msg tea.Msg) (*transferFormModel, tea.Cmd) {
 				if idx < len(m.historyItems) {
 					m.historyIndex = idx
 					m.applyHistoryItem(idx)
-					return m, nil
+					return m, m.estimateSize()
 				}
 			}
 
 		case "o", "O":
-			// Open native file picker
+			// Open file picker (native or TUI browser)
 			if m.focused == tfLocalPathInput {
 				return m, m.openLocalFilePicker()
 			}
@@ -397,6 +594,49 @@ func (m *transferFormModel) Update(msg tea.Msg) (*transferFormModel, tea.Cmd) {
 	return m, cmd
 }
 
+// estimateTarget returns the path a transfer copies from: the expanded local
+// path of an upload or the remote path of a download
+func (m *transferFormModel) estimateTarget() string {
+	if m.direction == transfer.Upload {
+		localPath := strings.TrimSpace(m.inputs[tfLocalPathInput].Value())
+		if localPath == "" {
+			return ""
+		}
+		expanded, err := transfer.ExpandPath(localPath)
+		if err != nil {
+			return ""
+		}
+		return expanded
+	}
+	return strings.TrimSpace(m.inputs[tfRemotePathInput].Value())
+}
+
+// estimateSize measures the path the transfer copies from in the background
+func (m *transferFormModel) estimateSize() tea.Cmd {
+	return startSizeEstimate(&m.estimate, nil, m.hostName, m.configFile, m.direction, m.estimateTarget())
+}
+
+// confirmOrSubmit submits the form, unless the transfer was measured larger
+// than confirmSize: then Enter must be pressed again. Uploads are measured
+// first when they were not yet; downloads only when picked in the browser,
+// to avoid connecting to the host on every submit.
+func (m *transferFormModel) confirmOrSubmit() tea.Cmd {
+	target := m.estimateTarget()
+	if m.confirmLarge || m.confirmSize <= 0 || target == "" {
+		m.confirmLarge = false
+		return m.submitForm()
+	}
+	if m.estimate.exceeds(target, m.confirmSize) {
+		m.confirmLarge = true
+		return nil
+	}
+	if m.direction == transfer.Upload && (m.estimate.path != target || m.estimate.loading) {
+		m.submitAfterEstimate = true
+		return m.estimateSize()
+	}
+	return m.submitForm()
+}
+
 func (m *transferFormModel) applyHistoryItem(idx int) {
 	if idx >= 0 && idx < len(m.historyItems) {
 		item := m.historyItems[idx]
@@ -457,9 +697,12 @@ func (m *transferFormModel) View() string {
 	}
 	sections = append(sections, "")
 
-	// Upload type selector (only shown for uploads)
-	if m.direction == transfer.Upload {
+	// File/Folder selector
+	{
 		typeLabel := "Upload Type:"
+		if m.direction == transfer.Download {
+			typeLabel = "Download Type:"
+		}
 		if m.focused == tfUploadTypeInput {
 			typeLabel = m.styles.FocusedLabel.Render(typeLabel)
 		} else {
@@ -480,7 +723,11 @@ func (m *transferFormModel) View() string {
 		typeButtons := lipgloss.JoinHorizontal(lipgloss.Center, fileBtn, "  ", folderBtn)
 		sections = append(sections, typeButtons)
 		if m.focused == tfUploadTypeInput {
-			sections = append(sections, m.styles.HelpText.Render("Use ←/→ to change type"))
+			if m.recursiveDefault {
+				sections = append(sections, m.styles.HelpText.Render("Use ←/→ to change type • Folder is the default for this host, Ctrl+R to go back to File"))
+			} else {
+				sections = append(sections, m.styles.HelpText.Render("Use ←/→ to change type • Ctrl+R: default to Folder for this host"))
+			}
 		}
 		sections = append(sections, "")
 	}
@@ -496,9 +743,12 @@ func (m *transferFormModel) View() string {
 	sections = append(sections, m.inputs[tfLocalPathInput].View())
 
 	// Show file picker hint when focused on local path
-	if m.focused == tfLocalPathInput && transfer.IsPickerAvailable() {
+	if m.focused == tfLocalPathInput {
 		sections = append(sections, m.styles.HelpText.Render("Press 'o' to browse"))
 	}
+	if summary := m.estimate.summary(m.estimateTarget()); summary != "" && m.direction == transfer.Upload {
+		sections = append(sections, m.styles.HelpText.Render(summary))
+	}
 	sections = append(sections, "")
 
 	// Remote path
@@ -511,15 +761,69 @@ func (m *transferFormModel) View() string {
 	sections = append(sections, remoteLabel)
 	sections = append(sections, m.inputs[tfRemotePathInput].View())
 
-	// Show file picker hint when focused on remote path
+	// Show completion candidates and the file picker hint when focused on remote path
 	if m.focused == tfRemotePathInput {
-		sections = append(sections, m.styles.HelpText.Render("Press 'o' to browse remote files"))
+		if m.completion.hint != "" {
+			sections = append(sections, m.styles.HelpText.Render(m.completion.hint))
+		}
+		sections = append(sections, m.styles.HelpText.Render("Press Tab to complete, 'o' to browse remote files"))
 	}
+	if summary := m.estimate.summary(m.estimateTarget()); summary != "" && m.direction == transfer.Download {
+		sections = append(sections, m.styles.HelpText.Render(summary))
+	}
+	sections = append(sections, "")
+
+	// User and port overrides, side by side
+	userLabel := "User (optional):"
+	if m.focused == tfUserInput {
+		userLabel = m.styles.FocusedLabel.Render(userLabel)
+	} else {
+		userLabel = m.styles.Label.Render(userLabel)
+	}
+	portLabel := "Port (optional):"
+	if m.focused == tfPortInput {
+		portLabel = m.styles.FocusedLabel.Render(portLabel)
+	} else {
+		portLabel = m.styles.Label.Render(portLabel)
+	}
+	userColumn := lipgloss.JoinVertical(lipgloss.Left, userLabel, m.inputs[tfUserInput].View())
+	portColumn := lipgloss.JoinVertical(lipgloss.Left, portLabel, m.inputs[tfPortInput].View())
+	sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Top, userColumn, "    ", portColumn))
+	sections = append(sections, "")
+
+	// Jump host override
+	jumpLabel := "Jump Host (optional):"
+	if m.focused == tfJumpHostInput {
+		jumpLabel = m.styles.FocusedLabel.Render(jumpLabel)
+	} else {
+		jumpLabel = m.styles.Label.Render(jumpLabel)
+	}
+	sections = append(sections, jumpLabel)
+	sections = append(sections, m.inputs[tfJumpHostInput].View())
+	sections = append(sections, "")
+
+	// Bandwidth limit
+	limitLabel := "Bandwidth Limit in KB/s (optional):"
+	if m.focused == tfLimitInput {
+		limitLabel = m.styles.FocusedLabel.Render(limitLabel)
+	} else {
+		limitLabel = m.styles.Label.Render(limitLabel)
+	}
+	sections = append(sections, limitLabel)
+	sections = append(sections, m.inputs[tfLimitInput].View())
 	sections = append(sections, "")
 
 	// Transfer history
-	if m.showHistory && len(m.historyItems) > 0 {
-		sections = append(sections, m.styles.Label.Render("Recent Transfers (press 1-5 to select):"))
+	if m.showHistory && len(m.historyItems) == 0 {
+		sections = append(sections, m.styles.Label.Render("Recent Transfers:"))
+		sections = append(sections, m.styles.HelpText.Render(" No transfers yet — completed transfers show up here for reuse"))
+		sections = append(sections, "")
+	} else if m.showHistory {
+		if m.historyByCount {
+			sections = append(sections, m.styles.Label.Render("Frequent Transfers (press 1-5 to select, Ctrl+F for recent):"))
+		} else {
+			sections = append(sections, m.styles.Label.Render("Recent Transfers (press 1-5 to select, Ctrl+F for frequent):"))
+		}
 
 		maxItems := 5
 		if len(m.historyItems) < maxItems {
@@ -533,25 +837,41 @@ func (m *transferFormModel) View() string {
 				arrow = "↓"
 			}
 			timeAgo := formatTimeAgo(item.Timestamp)
+			if m.historyByCount {
+				timeAgo = fmt.Sprintf("%d×, last %s", item.Occurrences(), timeAgo)
+			}
 
 			historyLine := fmt.Sprintf(" %d. %s %s → %s (%s)",
 				i+1, arrow,
-				truncatePath(item.LocalPath, 25),
-				truncatePath(item.RemotePath, 25),
+				m.historyPaths.format(item.LocalPath, false),
+				m.historyPaths.format(item.RemotePath, true),
 				timeAgo)
 
+			lineStyle := m.styles.HelpText
 			if i == m.historyIndex {
-				historyLine = m.styles.Selected.Render(historyLine)
-			} else {
-				historyLine = m.styles.HelpText.Render(historyLine)
+				lineStyle = m.styles.Selected
 			}
-			sections = append(sections, historyLine)
+			if m.historyPaths == historyPathsFull {
+				// Wrap long paths inside the form
+				lineStyle = lineStyle.Width(m.historyLineWidth())
+			}
+			sections = append(sections, lineStyle.Render(historyLine))
 		}
+		sections = append(sections, m.styles.HelpText.Render(fmt.Sprintf(" Paths: %s (Ctrl+T: %s)", m.historyPaths, m.historyPaths.next())))
+		sections = append(sections, "")
+	}
+
+	if m.confirmLarge {
+		sections = append(sections, m.styles.Error.Render(fmt.Sprintf("%s, more than %s. Press Enter again to transfer.",
+			m.estimate.summary(m.estimateTarget()), transfer.FormatByteSize(m.confirmSize))))
 		sections = append(sections, "")
 	}
 
 	// Help text
-	helpText := " Tab/↓: next • Shift+Tab/↑: prev • Enter: transfer • Ctrl+H: toggle history • Esc: cancel"
+	helpText := " Tab/↓: next (completes recent remote path) • Shift+Tab/↑: prev • Enter: transfer • Ctrl+H: toggle history • Esc: cancel"
+	if m.historyOff {
+		helpText = " Tab/↓: next • Shift+Tab/↑: prev • Enter: transfer • Esc: cancel"
+	}
 	sections = append(sections, m.styles.HelpText.Render(helpText))
 
 	// Join all sections
@@ -567,10 +887,35 @@ func (m *transferFormModel) View() string {
 	)
 }
 
+// historyLineWidth is the width full history paths wrap at, inside the form border and padding
+func (m *transferFormModel) historyLineWidth() int {
+	width := m.width - 8
+	if width < 40 {
+		width = 40
+	}
+	return width
+}
+
 func (m *transferFormModel) submitForm() tea.Cmd {
 	return func() tea.Msg {
 		localPath := strings.TrimSpace(m.inputs[tfLocalPathInput].Value())
 		remotePath := strings.TrimSpace(m.inputs[tfRemotePathInput].Value())
+		user := strings.TrimSpace(m.inputs[tfUserInput].Value())
+		port := strings.TrimSpace(m.inputs[tfPortInput].Value())
+		jumpHost := strings.TrimSpace(m.inputs[tfJumpHostInput].Value())
+
+		if err := transfer.ValidateOverrides(user, port); err != nil {
+			return transferSubmitMsg{err: err}
+		}
+		limit, err := transfer.ParseBandwidthLimit(m.inputs[tfLimitInput].Value())
+		if err != nil {
+			return transferSubmitMsg{err: err}
+		}
+		if jumpHost != "" {
+			if err := transfer.ValidateJumpHost(jumpHost, m.configFile); err != nil {
+				return transferSubmitMsg{err: err}
+			}
+		}
 
 		// Validate inputs based on direction
 		if m.direction == transfer.Upload {
@@ -605,35 +950,96 @@ func (m *transferFormModel) submitForm() tea.Cmd {
 			localPath = expandedPath
 		}
 
-		// Check if local path is a directory for uploads
-		recursive := false
-		if m.direction == transfer.Upload {
-			info, err := os.Stat(localPath)
-			if err == nil && info.IsDir() {
-				recursive = true
-			}
-		}
+		recursive := transferRecursive(m.direction, localPath, m.uploadType == UploadFolder)
 
 		req := &transfer.TransferRequest{
 			Host:       m.hostName,
 			Direction:  m.direction,
 			LocalPath:  localPath,
 			RemotePath: remotePath,
+			RemoteFile: m.direction == transfer.Upload && !recursive && remotePath == m.saveAsPath,
 			Recursive:  recursive,
 			ConfigFile: m.configFile,
+			ExtraArgs:  m.scpExtraArgs,
+			User:       user,
+			Port:       port,
+			JumpHost:   jumpHost,
+
+			BandwidthLimitKBps: limit,
+			Concurrency:        transfer.DefaultConcurrency(),
+		}
+
+		if err := transfer.ValidateSCPExtraArgs(req.ExtraArgs); err != nil {
+			return transferSubmitMsg{err: fmt.Errorf("invalid scp_extra_args in config: %w", err)}
 		}
 
 		return transferSubmitMsg{err: nil, request: req}
 	}
 }
 
-// truncatePath truncates a path to fit in maxLen characters
+// transferRecursive decides whether scp copies recursively. For uploads an
+// existing local path settles it, so a file is never sent with -r; otherwise
+// the File/Folder choice applies.
+func transferRecursive(direction transfer.Direction, localPath string, folder bool) bool {
+	if direction == transfer.Upload {
+		if info, err := os.Stat(localPath); err == nil {
+			return info.IsDir()
+		}
+	}
+	return folder
+}
+
+// recordRemotePath remembers the remote directory used by a transfer
+func recordRemotePath(store *history.RemotePathStore, req *transfer.TransferRequest) {
+	if store == nil || req == nil {
+		return
+	}
+	if req.RemoteFile {
+		// A file picked as the destination of an upload: remember its directory
+		_ = store.RecordParent(req.Host, req.RemotePath)
+		return
+	}
+	direction := "upload"
+	if req.Direction == transfer.Download {
+		direction = "download"
+	}
+	_ = store.RecordTransfer(req.Host, direction, req.RemotePath, req.Recursive)
+}
+
+// truncatePath shortens a path to maxLen characters by eliding its middle,
+// so both the start of the path and the file name stay visible
 func truncatePath(path string, maxLen int) string {
-	if len(path) <= maxLen {
+	runes := []rune(path)
+	if len(runes) <= maxLen {
 		return path
 	}
-	// Keep the last part of the path
-	return "..." + path[len(path)-maxLen+3:]
+	budget := maxLen - 3 // Room left around "..."
+	if budget < 2 {
+		return string(runes[len(runes)-maxLen:])
+	}
+
+	// Keep the last element whole when it leaves room for a start
+	tailLen := len(runes) - lastSeparator(runes)
+	if tailLen > budget-budget/3 {
+		tailLen = budget - budget/3
+	}
+	head := runes[:budget-tailLen]
+
+	// End the start on a directory boundary when there is one
+	if i := lastSeparator(head); i > 0 {
+		head = head[:i+1]
+	}
+	return string(head) + "..." + string(runes[len(runes)-tailLen:])
+}
+
+// lastSeparator returns the index of the last / or \ in runes, -1 if none
+func lastSeparator(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == '/' || runes[i] == '\\' {
+			return i
+		}
+	}
+	return -1
 }
 
 // formatTimeAgo formats a time as "X ago" (already exists in tui.go, but we need it here too)
@@ -687,31 +1093,45 @@ func (m standaloneTransferForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.transferFormModel.err = msg.err.Error()
 			return m, nil
 		}
-		// Execute the transfer
+		m.transferFormModel.closeCompletion()
+		// Execute the transfer with the terminal handed over to scp
 		if msg.request != nil {
-			fmt.Printf("\nTransferring %s...\n", msg.request.LocalPath)
-			result := msg.request.ExecuteWithProgress()
-			if !result.Success {
-				m.transferFormModel.err = result.Error.Error()
+			if err := transfer.ValidateSCPExtraArgs(msg.request.ExtraArgs); err != nil {
+				m.transferFormModel.err = err.Error()
 				return m, nil
 			}
+			request := msg.request
+			return m, tea.Exec(newTransferExec(request), func(err error) tea.Msg {
+				return standaloneTransferDoneMsg{request: request, err: err}
+			})
+		}
+		return m, tea.Quit
 
-			// Record in history
-			if m.transferFormModel.historyManager != nil {
-				direction := "upload"
-				if msg.request.Direction == transfer.Download {
-					direction = "download"
-				}
-				_ = m.transferFormModel.historyManager.RecordTransfer(
-					m.transferFormModel.hostName,
-					direction,
-					msg.request.LocalPath,
-					msg.request.RemotePath,
-				)
-			}
+	case standaloneTransferDoneMsg:
+		if errors.Is(msg.err, errDownloadSkipped) {
+			m.transferFormModel.err = msg.err.Error()
+			return m, nil
+		}
+		recordTransferAttempt(m.transferFormModel.historyManager, msg.request, msg.err)
+		if msg.err != nil {
+			m.transferFormModel.err = msg.err.Error()
+			return m, nil
+		}
 
-			fmt.Println("Transfer complete!")
+		// Record in history
+		if m.transferFormModel.historyManager != nil {
+			direction := "upload"
+			if msg.request.Direction == transfer.Download {
+				direction = "download"
+			}
+			_ = m.transferFormModel.historyManager.RecordTransfer(
+				m.transferFormModel.hostName,
+				direction,
+				msg.request.LocalPath,
+				msg.request.RemotePath,
+			)
 		}
+		recordRemotePath(m.transferFormModel.pathStore, msg.request)
 		return m, tea.Quit
 
 	case transferCancelMsg:
@@ -723,6 +1143,55 @@ func (m standaloneTransferForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// standaloneTransferDoneMsg is sent when the scp run by the standalone form exits
+type standaloneTransferDoneMsg struct {
+	request *transfer.TransferRequest
+	err     error
+}
+
+// errDownloadSkipped is returned when a download is skipped to keep an
+// existing local file
+var errDownloadSkipped = errors.New("download skipped")
+
+// transferExec runs scp while Bubble Tea has released the terminal. Progress
+// lines go to the terminal handed over by Bubble Tea, never straight to stdout.
+// Downloads onto an existing local file first ask to overwrite, rename or skip.
+type transferExec struct {
+	req      *transfer.TransferRequest
+	onExists transfer.OnExists
+	in       io.Reader
+	out      io.Writer
+	errOut   io.Writer
+}
+
+func newTransferExec(req *transfer.TransferRequest) *transferExec {
+	return &transferExec{req: req, onExists: transfer.DefaultOnExists(), out: io.Discard, errOut: io.Discard}
+}
+
+func (e *transferExec) SetStdin(r io.Reader)  { e.in = r }
+func (e *transferExec) SetStdout(w io.Writer) { e.out = w }
+func (e *transferExec) SetStderr(w io.Writer) { e.errOut = w }
+
+func (e *transferExec) Run() error {
+	if existing := e.req.ExistingDestination(); existing != "" {
+		action := e.onExists
+		if action == transfer.OnExistsAsk {
+			action = transfer.PromptOnExists(e.in, e.out, existing)
+		}
+		if !e.req.ApplyOnExists(action) {
+			fmt.Fprintf(e.out, "Skipped, %s already exists\n", existing)
+			return fmt.Errorf("%w: %s already exists", errDownloadSkipped, existing)
+		}
+	}
+
+	fmt.Fprintf(e.out, "\nTransferring %s...\n", e.req.LocalPath)
+	if result := e.req.ExecuteTo(e.in, e.out, e.errOut); !result.Success {
+		return result.Error
+	}
+	fmt.Fprintln(e.out, "Transfer complete!")
+	return nil
+}
+
 func (m standaloneTransferForm) View() string {
 	return m.transferFormModel.View()
 }
@@ -733,7 +1202,7 @@ func RunTransferForm(hostName, configFile string) error {
 	form := NewTransferForm(hostName, styles, 80, 24, configFile, transfer.Upload)
 	m := standaloneTransferForm{form}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := newProgram(m)
 	_, err := p.Run()
 	return err
 }
@@ -744,7 +1213,7 @@ func RunTransferFormWithDirection(hostName, configFile string, direction transfe
 	form := NewTransferForm(hostName, styles, 80, 24, configFile, direction)
 	m := standaloneTransferForm{form}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := newProgram(m)
 	_, err := p.Run()
 	return err
 }
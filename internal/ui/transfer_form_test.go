@@ -0,0 +1,403 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTransferFormHistoryByFrequency(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Upload)
+	if form.historyManager == nil {
+		t.Fatal("Expected a history manager")
+	}
+	_ = form.historyManager.RecordTransfer("server1", "upload", "/tmp/common.tar", "/srv/")
+	_ = form.historyManager.RecordTransfer("server1", "upload", "/tmp/common.tar", "/srv/")
+	_ = form.historyManager.RecordTransfer("server1", "download", "/tmp/", "/var/log/latest.log")
+	form.loadHistory()
+
+	if form.historyItems[0].RemotePath != "/var/log/latest.log" {
+		t.Fatalf("Expected the most recent transfer first by default, got %+v", form.historyItems[0])
+	}
+
+	form.historyIndex = 0
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if !form.historyByCount || form.historyIndex != -1 {
+		t.Fatalf("Expected Ctrl+F to order by frequency and clear the selection")
+	}
+	if form.historyItems[0].LocalPath != "/tmp/common.tar" {
+		t.Errorf("Expected the repeated transfer first, got %+v", form.historyItems[0])
+	}
+	if view := form.View(); !strings.Contains(view, "Frequent Transfers") || !strings.Contains(view, "2×") {
+		t.Errorf("Expected the frequency view with counts, got:\n%s", view)
+	}
+
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if form.historyByCount || form.historyItems[0].RemotePath != "/var/log/latest.log" {
+		t.Error("Expected Ctrl+F to switch back to the most recent first")
+	}
+}
+
+func TestTransferFormWithoutHistory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv(config.NoHistoryEnv, "1")
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Upload)
+	if !form.historyOff || form.showHistory {
+		t.Fatal("Expected the history to be left out when transfers are not recorded")
+	}
+	_ = form.historyManager.RecordTransfer("server1", "upload", "/tmp/app.tar", "/srv/")
+	form.loadHistory()
+	if len(form.historyItems) != 0 {
+		t.Errorf("Expected no history items, got %+v", form.historyItems)
+	}
+
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyCtrlH})
+	view := form.View()
+	if form.showHistory || strings.Contains(view, "Recent Transfers") || strings.Contains(view, "Ctrl+H") {
+		t.Errorf("Expected no history section nor toggle, got:\n%s", view)
+	}
+
+	// The form still submits transfers
+	local := filepath.Join(dir, "app.tar")
+	if err := os.WriteFile(local, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if req := submitTransferForm(t, form, local, "/srv/"); req.RemotePath != "/srv/" {
+		t.Errorf("Expected the upload to /srv/, got %+v", req)
+	}
+}
+
+// submitTransferForm fills in the paths and returns the submitted request
+func submitTransferForm(t *testing.T, form *transferFormModel, localPath, remotePath string) *transfer.TransferRequest {
+	t.Helper()
+	form.inputs[tfLocalPathInput].SetValue(localPath)
+	form.inputs[tfRemotePathInput].SetValue(remotePath)
+
+	msg, ok := form.submitForm()().(transferSubmitMsg)
+	if !ok || msg.err != nil || msg.request == nil {
+		t.Fatalf("Expected a transfer request, got %+v", msg)
+	}
+	return msg.request
+}
+
+func TestTransferFormHostRecursiveDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	if err := config.SetHostRecursiveDefault("server1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	download := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Download)
+	if download.uploadType != UploadFolder || !download.recursiveDefault {
+		t.Fatal("Expected the form to be prefilled with Folder")
+	}
+	if req := submitTransferForm(t, download, dir, "/srv/www"); !req.Recursive {
+		t.Error("Expected a recursive download by default")
+	}
+
+	other := NewTransferForm("server2", NewStyles(120), 120, 60, "", transfer.Download)
+	if other.uploadType != UploadFile {
+		t.Error("Other hosts should default to File")
+	}
+	if req := submitTransferForm(t, other, dir, "/etc/hosts"); req.Recursive {
+		t.Error("Expected a plain download without a default")
+	}
+}
+
+func TestTransferFormRecursiveStatOverride(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	file := filepath.Join(dir, "app.tar")
+	if err := os.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SetHostRecursiveDefault("server1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// A local file is never sent with -r, even when the host defaults to folders
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Upload)
+	if req := submitTransferForm(t, form, file, "/srv/"); req.Recursive {
+		t.Error("Expected a file upload not to be recursive")
+	}
+
+	// A local directory is always recursive, even with File selected
+	form.uploadType = UploadFile
+	if req := submitTransferForm(t, form, dir, "/srv/"); !req.Recursive {
+		t.Error("Expected a directory upload to be recursive")
+	}
+}
+
+func TestTransferFormToggleRecursiveDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Download)
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if form.focused != tfUploadTypeInput {
+		t.Fatalf("Expected the File/Folder choice to be offered for downloads, got field %d", form.focused)
+	}
+
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !form.recursiveDefault || form.uploadType != UploadFolder {
+		t.Fatal("Expected Ctrl+R to make Folder the default")
+	}
+	if defaults, _ := config.LoadHostTransferDefaults("server1"); !defaults.Recursive {
+		t.Error("Expected the default to be saved for the host")
+	}
+
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if defaults, _ := config.LoadHostTransferDefaults("server1"); defaults.Recursive || form.uploadType != UploadFile {
+		t.Error("Expected Ctrl+R again to go back to File")
+	}
+}
+
+func TestTransferFormJumpHost(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	configFile := filepath.Join(dir, "ssh_config")
+	if err := os.WriteFile(configFile, []byte("Host bastion\n    HostName 203.0.113.10\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, configFile, transfer.Download)
+	form.inputs[tfJumpHostInput].SetValue(" bastion ")
+	req := submitTransferForm(t, form, dir, "/var/log/app.log")
+	if req.JumpHost != "bastion" {
+		t.Fatalf("Expected the jump host in the request, got %q", req.JumpHost)
+	}
+	if cmd := req.CommandString(); !strings.Contains(cmd, " -J bastion ") {
+		t.Errorf("Expected -J in the scp command, got %q", cmd)
+	}
+
+	form.inputs[tfJumpHostInput].SetValue("")
+	if req := submitTransferForm(t, form, dir, "/var/log/app.log"); req.JumpHost != "" || strings.Contains(req.CommandString(), "-J") {
+		t.Errorf("Expected no -J without a jump host, got %q", req.CommandString())
+	}
+
+	form.inputs[tfJumpHostInput].SetValue("unknown")
+	msg := form.submitForm()().(transferSubmitMsg)
+	if msg.err == nil || !strings.Contains(msg.err.Error(), "not in the SSH config") {
+		t.Errorf("Expected an unknown jump host to be rejected, got %v", msg.err)
+	}
+}
+
+func TestTransferFormBandwidthLimit(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Download)
+	form.inputs[tfLimitInput].SetValue("250")
+	req := submitTransferForm(t, form, dir, "/var/log/app.log")
+	if req.BandwidthLimitKBps != 250 {
+		t.Fatalf("Expected the limit in the request, got %d", req.BandwidthLimitKBps)
+	}
+	if cmd := req.CommandString(); !strings.Contains(cmd, " -l 2000 ") {
+		t.Errorf("Expected -l in Kbit/s in the scp command, got %q", cmd)
+	}
+
+	form.inputs[tfLimitInput].SetValue("-1")
+	msg := form.submitForm()().(transferSubmitMsg)
+	if msg.err == nil || !strings.Contains(msg.err.Error(), "invalid bandwidth limit") {
+		t.Errorf("Expected a negative limit to be rejected, got %v", msg.err)
+	}
+}
+
+func TestTruncatePathElidesMiddle(t *testing.T) {
+	tests := []struct {
+		path   string
+		maxLen int
+		want   string
+	}{
+		{"/srv/app.conf", 25, "/srv/app.conf"},
+		{"/home/user/projects/app/config/settings.yaml", 25, "/home/.../settings.yaml"},
+		{"/var/www/html/wp-content/uploads/2024/photo.jpg", 25, "/var/www/.../photo.jpg"},
+		{`C:\Users\me\Documents\Reports\summary.pdf`, 25, `C:\Users\...\summary.pdf`},
+		{"/data/a-very-long-file-name-for-the-export.tar.gz", 25, "/data/...e-export.tar.gz"},
+		{"/opt/журнал/архив/отчёт-за-март.txt", 25, "/opt/...чёт-за-март.txt"},
+	}
+
+	for _, tt := range tests {
+		got := truncatePath(tt.path, tt.maxLen)
+		if got != tt.want {
+			t.Errorf("truncatePath(%q, %d) = %q, want %q", tt.path, tt.maxLen, got, tt.want)
+		}
+		if n := len([]rune(got)); n > tt.maxLen {
+			t.Errorf("truncatePath(%q) is %d characters long, limit is %d", tt.path, n, tt.maxLen)
+		}
+	}
+}
+
+func TestTransferFormHistoryPathDisplay(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Download)
+	localPath := "/home/user/downloads/reports/quarterly/summary.pdf"
+	remotePath := "/srv/exports/finance/2024/quarterly/summary.pdf"
+	_ = form.historyManager.RecordTransfer("server1", "download", localPath, remotePath)
+	form.loadHistory()
+	form.showHistory = true
+
+	if view := form.View(); !strings.Contains(view, "/home/.../summary.pdf") || !strings.Contains(view, "Paths: shortened") {
+		t.Fatalf("Expected middle-elided paths by default, got:\n%s", view)
+	}
+
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if view := form.View(); !strings.Contains(view, "summary.pdf → summary.pdf") {
+		t.Errorf("Expected file names only, got:\n%s", view)
+	}
+
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if form.historyPaths != historyPathsFull || !strings.Contains(form.View(), localPath) {
+		t.Errorf("Expected full paths, got:\n%s", form.View())
+	}
+
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if form.historyPaths != historyPathsElided {
+		t.Error("Expected Ctrl+T to cycle back to shortened paths")
+	}
+}
+
+func TestTransferFormConfirmsLargeUpload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	site := filepath.Join(dir, "site")
+	if err := os.MkdirAll(filepath.Join(site, "assets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"index.html", "assets/app.js"} {
+		if err := os.WriteFile(filepath.Join(site, name), make([]byte, 2000), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Upload)
+	if form.confirmSize != config.DefaultTransferConfirmSizeMB<<20 {
+		t.Fatalf("Expected the default confirm size, got %d", form.confirmSize)
+	}
+	form.confirmSize = 1000
+	form.inputs[tfLocalPathInput].SetValue(site)
+	form.focused = tfRemotePathInput
+
+	// The upload is measured before being submitted
+	form, cmd := form.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !form.submitAfterEstimate {
+		t.Fatal("Expected Enter to measure the upload first")
+	}
+	msg, ok := cmd().(sizeEstimateMsg)
+	if !ok || msg.size != 4000 || msg.files != 2 {
+		t.Fatalf("Expected the size of the folder, got %+v", msg)
+	}
+
+	form, cmd = form.Update(msg)
+	if cmd != nil || !form.confirmLarge {
+		t.Fatal("Expected an upload over the confirm size to wait for a confirmation")
+	}
+	if view := form.View(); !strings.Contains(view, "About 3.9KB in 2 files") || !strings.Contains(view, "Press Enter again") {
+		t.Errorf("Expected the size and the confirmation in the view, got:\n%s", view)
+	}
+
+	// Any other key cancels the confirmation
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if form.confirmLarge {
+		t.Error("Expected typing to cancel the confirmation")
+	}
+	form.inputs[tfRemotePathInput].SetValue("/srv/")
+
+	form, _ = form.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	form, cmd = form.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected Enter pressed again to submit")
+	}
+	if submit, ok := cmd().(transferSubmitMsg); !ok || submit.err != nil || submit.request.LocalPath != site {
+		t.Errorf("Expected the upload to be submitted, got %+v", submit)
+	}
+}
+
+func TestTransferFormSubmitsSmallUpload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	local := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(local, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Upload)
+	form.inputs[tfLocalPathInput].SetValue(local)
+	form.focused = tfRemotePathInput
+
+	form, cmd := form.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	form, cmd = form.Update(cmd())
+	if cmd == nil || form.confirmLarge {
+		t.Fatal("Expected a small upload to be submitted once measured")
+	}
+	if _, ok := cmd().(transferSubmitMsg); !ok {
+		t.Error("Expected a transferSubmitMsg")
+	}
+	if !strings.Contains(form.View(), "About 5B in 1 file") {
+		t.Error("Expected the size of the upload to be shown")
+	}
+}
+
+func TestTransferFormUploadToSavedFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	local := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(local, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	form := NewTransferForm("server1", NewStyles(120), 120, 60, "", transfer.Upload)
+	form.inputs[tfLocalPathInput].SetValue(local)
+	form, _ = form.Update(filePickerResultMsg{path: "/srv/notes.txt.new", selected: true, saveAs: true})
+	msg := form.submitForm()().(transferSubmitMsg)
+	if msg.err != nil || !msg.request.RemoteFile {
+		t.Fatalf("Expected the picked file to be the destination, got %+v (err %v)", msg.request, msg.err)
+	}
+
+	// The directory of the file is remembered, not the file
+	store, err := history.NewRemotePathStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordRemotePath(store, msg.request)
+	if got := store.Last("server1"); got != "/srv" {
+		t.Errorf("Expected /srv to be remembered, got %q", got)
+	}
+
+	// A path typed over it is a directory again
+	form.inputs[tfRemotePathInput].SetValue("/srv/uploads")
+	if msg := form.submitForm()().(transferSubmitMsg); msg.err != nil || msg.request.RemoteFile {
+		t.Errorf("Expected a typed path to be a directory, got %+v (err %v)", msg.request, msg.err)
+	}
+}
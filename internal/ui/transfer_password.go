@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errPasswordCancelled is returned when no password was entered for a transfer
+var errPasswordCancelled = errors.New("password entry cancelled")
+
+// transferNeedsPassword tells whether a host only takes a password (replaced in tests)
+var transferNeedsPassword = transfer.NeedsPassword
+
+// transferLoginCheckedMsg is sent once it is known whether the host of a
+// transfer only takes a password
+type transferLoginCheckedMsg struct {
+	request *transfer.TransferRequest
+	needed  bool
+}
+
+// transferPasswordMsg is sent once the password prompt of a transfer is done
+type transferPasswordMsg struct {
+	request  *transfer.TransferRequest
+	password string
+	err      error
+}
+
+// checkTransferLogin finds out in the background whether scp would prompt for
+// a password, which it cannot do while the TUI holds the terminal. A failed
+// check lets the transfer run and scp report the problem. The check logs in
+// with the host's config, so one-off overrides skip it.
+func checkTransferLogin(req *transfer.TransferRequest) tea.Cmd {
+	return func() tea.Msg {
+		if req.Password != "" || req.User != "" || req.Port != "" || req.JumpHost != "" {
+			return transferLoginCheckedMsg{request: req}
+		}
+		needed, _ := transferNeedsPassword(req.Host, req.ConfigFile)
+		return transferLoginCheckedMsg{request: req, needed: needed}
+	}
+}
+
+// transferPasswordExec asks for the password of a host on the terminal
+// handed over by tea.Exec, outside of the alt screen
+type transferPasswordExec struct {
+	host     string
+	password string
+	in       io.Reader
+	out      io.Writer
+}
+
+// promptTransferPassword returns a command asking for the password req logs in with
+func promptTransferPassword(req *transfer.TransferRequest) tea.Cmd {
+	e := &transferPasswordExec{host: req.Host, out: io.Discard}
+	return tea.Exec(e, func(err error) tea.Msg {
+		return transferPasswordMsg{request: req, password: e.password, err: err}
+	})
+}
+
+func (e *transferPasswordExec) SetStdin(r io.Reader)  { e.in = r }
+func (e *transferPasswordExec) SetStdout(w io.Writer) { e.out = w }
+func (e *transferPasswordExec) SetStderr(io.Writer)   {}
+
+func (e *transferPasswordExec) Run() error {
+	fmt.Fprintf(e.out, "\n%s accepts none of your SSH keys and asks for a password.\n", e.host)
+	fmt.Fprintf(e.out, "Password for %s (empty to cancel): ", e.host)
+	password, err := readPassphrase(e.in)
+	fmt.Fprintln(e.out)
+	if err != nil {
+		return err
+	}
+	if len(password) == 0 {
+		return errPasswordCancelled
+	}
+	e.password = string(password)
+	return nil
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+// stubNeedsPassword makes every host report whether it only takes a password
+func stubNeedsPassword(t *testing.T, needed bool) {
+	t.Helper()
+	orig := transferNeedsPassword
+	transferNeedsPassword = func(host, configFile string) (bool, error) { return needed, nil }
+	t.Cleanup(func() { transferNeedsPassword = orig })
+}
+
+func TestQuickTransferAsksForPassword(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	// No scp on the PATH, so the transfer fails right away instead of connecting
+	t.Setenv("PATH", dir)
+	stubNeedsPassword(t, true)
+
+	m := NewQuickTransfer("nas", NewStyles(80), 80, 24, "")
+	req := &transfer.TransferRequest{Host: "nas", Direction: transfer.Upload, LocalPath: dir, RemotePath: "/tmp"}
+	cmd := m.startTransfer(req)
+	if m.state != QTStateCheckingLogin || cmd == nil {
+		t.Fatalf("Expected the login to be checked first, got state %v", m.state)
+	}
+	if !strings.Contains(m.View(), "Checking how to log in to nas") {
+		t.Error("Expected the view to show the login check")
+	}
+
+	msg := cmd()
+	if checked, ok := msg.(transferLoginCheckedMsg); !ok || !checked.needed {
+		t.Fatalf("Expected the host to need a password, got %#v", msg)
+	}
+	m, cmd = m.Update(msg)
+	if cmd == nil || m.runningTransfer != nil {
+		t.Fatal("Expected a password prompt before the transfer starts")
+	}
+
+	m, _ = m.Update(transferPasswordMsg{request: req, password: "s3cret"})
+	if req.Password != "s3cret" || m.state != QTStateTransferring || m.runningTransfer == nil {
+		t.Errorf("Expected the transfer to start with the password, got state %v", m.state)
+	}
+}
+
+func TestQuickTransferPasswordCancelled(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+
+	m := NewQuickTransfer("nas", NewStyles(80), 80, 24, "")
+	req := &transfer.TransferRequest{Host: "nas"}
+	m.state = QTStateCheckingLogin
+
+	m, _ = m.Update(transferPasswordMsg{request: req, err: errPasswordCancelled})
+	if m.state != QTStateDone || !strings.Contains(m.err, "cancelled") || m.runningTransfer != nil {
+		t.Errorf("Expected the transfer to end without running, got state %v, err %q", m.state, m.err)
+	}
+}
+
+func TestCheckTransferLoginSkipsOverrides(t *testing.T) {
+	orig := transferNeedsPassword
+	transferNeedsPassword = func(host, configFile string) (bool, error) {
+		t.Errorf("Expected no check for %s", host)
+		return false, nil
+	}
+	defer func() { transferNeedsPassword = orig }()
+
+	for _, req := range []*transfer.TransferRequest{
+		{Host: "nas", JumpHost: "bastion"},
+		{Host: "nas", Port: "2222"},
+		{Host: "nas", Password: "known"},
+	} {
+		if msg := checkTransferLogin(req)().(transferLoginCheckedMsg); msg.needed {
+			t.Errorf("Expected %+v to go ahead without a password prompt", req)
+		}
+	}
+}
+
+func TestTransferPasswordExecReadsPassword(t *testing.T) {
+	var out bytes.Buffer
+	e := &transferPasswordExec{host: "nas", in: strings.NewReader("s3cret\n"), out: &out}
+	if err := e.Run(); err != nil || e.password != "s3cret" {
+		t.Fatalf("Expected the password to be read, got %q, %v", e.password, err)
+	}
+	if !strings.Contains(out.String(), "Password for nas") || strings.Contains(out.String(), "s3cret") {
+		t.Errorf("Expected a prompt that does not echo the password, got %q", out.String())
+	}
+
+	e = &transferPasswordExec{host: "nas", in: strings.NewReader("\n"), out: &out}
+	if err := e.Run(); !errors.Is(err, errPasswordCancelled) {
+		t.Errorf("Expected an empty password to cancel, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// transferProgressInterval is how often the progress of a running transfer is redrawn
+const transferProgressInterval = 500 * time.Millisecond
+
+// transferProgressMsg is a tick of the progress of a running transfer
+type transferProgressMsg struct {
+	bytesDone  int64
+	bytesTotal int64 // 0 when the size of the transfer is not known
+}
+
+// tickTransferProgress returns a command reporting the progress of tracker
+// after transferProgressInterval
+func tickTransferProgress(tracker *transfer.ProgressTracker) tea.Cmd {
+	return tea.Tick(transferProgressInterval, func(time.Time) tea.Msg {
+		done, total := tracker.Snapshot()
+		return transferProgressMsg{bytesDone: done, bytesTotal: total}
+	})
+}
+
+// measureTransfer returns a command setting the source of tracker as
+// MeasureSource does, off the update loop since it may open a session
+func measureTransfer(req *transfer.TransferRequest, tracker *transfer.ProgressTracker, session *transfer.SFTPSession, total int64) tea.Cmd {
+	return func() tea.Msg {
+		req.MeasureSource(tracker, session, total)
+		return nil
+	}
+}
+
+// transferProgressView renders the progress of a transfer started at started
+func transferProgressView(bar progress.Model, styles Styles, done, total int64, started time.Time) string {
+	elapsed := time.Since(started)
+	rate := int64(0)
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		rate = int64(float64(done) / seconds)
+	}
+
+	if total <= 0 {
+		// Unknown size: no bar, only what went through
+		return styles.HelpText.Render(fmt.Sprintf("%s transferred • %s/s", transfer.FormatByteSize(done), transfer.FormatByteSize(rate)))
+	}
+
+	eta := "--:--"
+	if rate > 0 {
+		eta = formatTransferDuration(time.Duration(float64(total-done) / float64(rate) * float64(time.Second)))
+	}
+	stats := fmt.Sprintf("%s of %s • %s/s • ETA %s", transfer.FormatByteSize(done), transfer.FormatByteSize(total), transfer.FormatByteSize(rate), eta)
+	return bar.ViewAs(float64(done)/float64(total)) + "\n" + styles.HelpText.Render(stats)
+}
+
+// formatTransferDuration formats a duration as mm:ss, or hh:mm:ss past an hour
+func formatTransferDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
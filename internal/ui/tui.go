@@ -11,26 +11,41 @@ import (
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // NewModel creates a new TUI model with the given SSH hosts
 func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
+	return newModel(hosts, configFile, currentVersion, false)
+}
+
+// newModel creates a TUI model as NewModel, listing only the favorite hosts
+// when favoritesOnly is set, whatever the app config says
+func newModel(hosts []config.SSHHost, configFile, currentVersion string, favoritesOnly bool) Model {
+	// Startup problems are shown as notifications once the TUI is running,
+	// printing them would be wiped out or garble the alt screen
+	var warnings []string
+
 	// Load application configuration
 	appConfig, err := config.LoadAppConfig()
 	if err != nil {
-		// Log the error but continue with default configuration
-		fmt.Printf("Warning: Could not load application config: %v, using defaults\n", err)
+		// Report the error but continue with default configuration
+		warnings = append(warnings, fmt.Sprintf("Could not load application config: %v, using defaults", err))
 		defaultConfig := config.GetDefaultAppConfig()
 		appConfig = &defaultConfig
 	}
 
+	// Conflicting key bindings would leave actions unreachable: use the defaults
+	if err := appConfig.KeyBindings.Validate(); err != nil {
+		warnings = append(warnings, fmt.Sprintf("%v, using the default keys", err))
+		appConfig.KeyBindings.Actions = nil
+	}
+
 	// Initialize the history manager
 	historyManager, err := history.NewHistoryManager()
 	if err != nil {
-		// Log the error but continue without the history functionality
-		fmt.Printf("Warning: Could not initialize history manager: %v\n", err)
+		// Report the error but continue without the history functionality
+		warnings = append(warnings, fmt.Sprintf("Could not initialize history manager: %v", err))
 		historyManager = nil
 	}
 
@@ -39,6 +54,7 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 
 	// Initialize ping manager with 5 second timeout
 	pingManager := connectivity.NewPingManager(5 * time.Second)
+	pingManager.SetCacheTTL(appConfig.PingCacheTTL())
 
 	// Create the model with default sorting by name
 	m := Model{
@@ -49,6 +65,7 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		configFile:     configFile,
 		currentVersion: currentVersion,
 		appConfig:      appConfig,
+		hideBanner:     appConfig.HideBanner,
 		styles:         styles,
 		width:          80,
 		height:         24,
@@ -56,8 +73,32 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		viewMode:       ViewList,
 	}
 
+	// Let the user know when history had to be restored from its backup
+	if historyManager != nil {
+		if warning := historyManager.RecoveryWarning(); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	for _, warning := range warnings {
+		m.notifications.push(NotifyWarn, warning)
+	}
+
+	// Start with the favorite hosts only when asked and some are marked
+	favorites, err := config.LoadFavorites()
+	if err != nil {
+		m.notifications.push(NotifyWarn, fmt.Sprintf("Could not load favorite hosts: %v", err))
+	}
+	m.favorites = favorites
+	if appConfig.FavoritesOnly || favoritesOnly {
+		if len(favorites) > 0 {
+			m.favoritesOnly = true
+		} else {
+			m.notifications.push(NotifyInfo, "No favorite hosts yet, showing all (* marks one)")
+		}
+	}
+
 	// Sort hosts according to the default sort mode
-	sortedHosts := m.sortHosts(hosts)
+	sortedHosts := m.sortHosts(m.visibleHosts())
 
 	// Create the search input
 	ti := textinput.New()
@@ -103,6 +144,10 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 			}
 		}
 
+		if m.favorites[host.Name] {
+			statusIndicator = "★" + statusIndicator
+		}
+
 		rows = append(rows, table.Row{
 			statusIndicator + " " + host.Name,
 			host.Hostname,
@@ -131,6 +176,7 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 	s.Selected = m.styles.Selected
 
 	t.SetStyles(s)
+	applyNavigationKeys(&t, &appConfig.KeyBindings)
 
 	// Update the model with the table and other properties
 	m.table = t
@@ -146,12 +192,16 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 	return m
 }
 
-// RunInteractiveMode starts the interactive TUI interface
-func RunInteractiveMode(hosts []config.SSHHost, configFile, currentVersion string) error {
-	m := NewModel(hosts, configFile, currentVersion)
+// RunInteractiveMode starts the interactive TUI interface, listing only the
+// favorite hosts when favoritesOnly is set (the --favorites flag)
+func RunInteractiveMode(hosts []config.SSHHost, configFile, currentVersion string, favoritesOnly bool) error {
+	m := newModel(hosts, configFile, currentVersion, favoritesOnly)
+
+	// Remember the config file for the config switcher
+	_ = config.RecordConfigFile(configFile)
 
-	// Start the application in alt screen mode for clean output
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	// Start the application in alt screen mode for clean output, unless disabled
+	p := newProgram(m)
 	_, err := p.Run()
 	if err != nil {
 		return fmt.Errorf("error running TUI: %w", err)
@@ -2,12 +2,15 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"time"
 
 	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/connectivity"
+	"github.com/Gu1llaum-3/sshm/internal/history"
 	"github.com/Gu1llaum-3/sshm/internal/transfer"
 	"github.com/Gu1llaum-3/sshm/internal/version"
 
@@ -17,41 +20,97 @@ import (
 
 // Messages for SSH ping functionality and version checking
 type (
-	pingResultMsg   *connectivity.HostPingResult
 	versionCheckMsg *version.UpdateInfo
 	versionErrorMsg error
-	errorMsg        string
 )
 
-// startPingAllCmd creates a command to ping all hosts concurrently
-func (m Model) startPingAllCmd() tea.Cmd {
-	if m.pingManager == nil {
+// pingResultMsg is one host checked by the ping run numbered run
+type pingResultMsg struct {
+	run    int
+	result *connectivity.HostPingResult
+}
+
+// pingDoneMsg reports that every host of the ping run numbered run was checked
+type pingDoneMsg struct {
+	run int
+}
+
+// startPingAllCmd pings the hosts without a cached result through a bounded
+// worker pool, cancelling the previous run if it is still going. Results
+// stream in one by one.
+func (m *Model) startPingAllCmd() tea.Cmd {
+	if m.pingManager == nil || len(m.hosts) == 0 {
 		return nil
 	}
+	hosts := m.pingManager.StaleHosts(m.hosts)
+	if len(hosts) == 0 {
+		return func() tea.Msg {
+			return notifyMsg{level: NotifyInfo, text: fmt.Sprintf("All hosts were checked less than %s ago", m.pingManager.CacheTTL())}
+		}
+	}
+	if m.pingCancel != nil {
+		m.pingCancel()
+	}
 
-	return tea.Batch(
-		// Create individual ping commands for each host
-		func() tea.Cmd {
-			var cmds []tea.Cmd
-			for _, host := range m.hosts {
-				cmds = append(cmds, pingSingleHostCmd(m.pingManager, host))
-			}
-			return tea.Batch(cmds...)
-		}(),
-	)
-}
+	concurrency := 0
+	if m.appConfig != nil {
+		concurrency = m.appConfig.PingConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.pingRun++
+	m.pingCancel = cancel
+	m.pingResults = m.pingManager.PingHosts(ctx, hosts, concurrency)
+	m.pingTotal = len(hosts)
+	m.pingDone = 0
+	m.pingTally = pingTally{}
 
-// listenForPingResultsCmd is no longer needed since we use individual ping commands
+	return waitForPingResult(m.pingResults, m.pingRun)
+}
 
-// pingSingleHostCmd creates a command to ping a single host
-func pingSingleHostCmd(pingManager *connectivity.PingManager, host config.SSHHost) tea.Cmd {
+// waitForPingResult returns a command waiting for the next result of a ping run
+func waitForPingResult(results <-chan *connectivity.HostPingResult, run int) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+		result, ok := <-results
+		if !ok {
+			return pingDoneMsg{run: run}
+		}
+		return pingResultMsg{run: run, result: result}
+	}
+}
+
+// pingInProgress reports whether a ping run is still checking hosts
+func (m Model) pingInProgress() bool {
+	return m.pingResults != nil
+}
+
+// pingTally counts the results of a ping run by status
+type pingTally struct {
+	up      int
+	down    int
+	unknown int
+}
+
+// add counts one result
+func (t *pingTally) add(result *connectivity.HostPingResult) {
+	switch {
+	case result == nil:
+		t.unknown++
+	case result.Status == connectivity.StatusOnline:
+		t.up++
+	case result.Status == connectivity.StatusOffline:
+		t.down++
+	default:
+		t.unknown++
+	}
+}
 
-		result := pingManager.PingHost(ctx, host)
-		return pingResultMsg(result)
+func (t pingTally) String() string {
+	summary := fmt.Sprintf("%d up, %d down", t.up, t.down)
+	if t.unknown > 0 {
+		summary += fmt.Sprintf(", %d unknown", t.unknown)
 	}
+	return summary
 }
 
 // checkVersionCmd creates a command to check for version updates
@@ -80,6 +139,11 @@ func (m Model) Init() tea.Cmd {
 		cmds = append(cmds, checkVersionCmd(m.currentVersion))
 	}
 
+	// Expire startup notifications after the usual delay
+	for _, n := range m.notifications.items {
+		cmds = append(cmds, expireNotificationAfter(n.id, m.notificationDuration()))
+	}
+
 	return tea.Batch(cmds...)
 }
 
@@ -136,6 +200,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.quickTransferForm.height = m.height
 			m.quickTransferForm.styles = m.styles
 		}
+		if m.localBrowserForm != nil {
+			m.localBrowserForm.width = m.width
+			m.localBrowserForm.height = m.height
+			m.localBrowserForm.styles = m.styles
+		}
 		if m.helpForm != nil {
 			m.helpForm.width = m.width
 			m.helpForm.height = m.height
@@ -146,13 +215,80 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.fileSelectorForm.height = m.height
 			m.fileSelectorForm.styles = m.styles
 		}
+		if m.resolvedConfigForm != nil {
+			m.resolvedConfigForm.width = m.width
+			m.resolvedConfigForm.height = m.height
+			m.resolvedConfigForm.styles = m.styles
+		}
+		if m.identityPicker != nil {
+			m.identityPicker.width = m.width
+			m.identityPicker.height = m.height
+			m.identityPicker.styles = m.styles
+		}
+		if m.savedViewsForm != nil {
+			m.savedViewsForm.width = m.width
+			m.savedViewsForm.height = m.height
+			m.savedViewsForm.styles = m.styles
+		}
+		if m.configSwitcher != nil {
+			m.configSwitcher.width = m.width
+			m.configSwitcher.height = m.height
+			m.configSwitcher.styles = m.styles
+		}
+		if m.sourceFilterForm != nil {
+			m.sourceFilterForm.width = m.width
+			m.sourceFilterForm.height = m.height
+			m.sourceFilterForm.styles = m.styles
+		}
+		if m.retryTransferForm != nil {
+			m.retryTransferForm.width = m.width
+			m.retryTransferForm.height = m.height
+			m.retryTransferForm.styles = m.styles
+		}
+		if m.identityWarning != nil {
+			m.identityWarning.width = m.width
+			m.identityWarning.height = m.height
+			m.identityWarning.styles = m.styles
+		}
+		if m.copyIDPicker != nil {
+			m.copyIDPicker.width = m.width
+			m.copyIDPicker.height = m.height
+			m.copyIDPicker.styles = m.styles
+		}
+		if m.forwardPicker != nil {
+			m.forwardPicker.width = m.width
+			m.forwardPicker.height = m.height
+			m.forwardPicker.styles = m.styles
+		}
+		if m.forwardsList != nil {
+			m.forwardsList.width = m.width
+			m.forwardsList.height = m.height
+			m.forwardsList.styles = m.styles
+		}
+		if m.webURLForm != nil {
+			m.webURLForm.width = m.width
+			m.webURLForm.height = m.height
+			m.webURLForm.styles = m.styles
+		}
 		return m, nil
 
 	case pingResultMsg:
-		// Handle ping result - update table display
-		if msg != nil {
-			// Update the table to reflect the new ping status
-			m.updateTableRows()
+		// Ignore results of a run replaced by a newer one
+		if msg.run != m.pingRun {
+			return m, nil
+		}
+		m.pingDone++
+		m.pingTally.add(msg.result)
+		// Update the table to reflect the new ping status
+		m.updateTableRows()
+		return m, waitForPingResult(m.pingResults, m.pingRun)
+
+	case pingDoneMsg:
+		if msg.run == m.pingRun {
+			m.pingCancel()
+			m.pingResults = nil
+			m.pingCancel = nil
+			return m, m.pushNotification(NotifyInfo, "Ping done: "+m.pingTally.String())
 		}
 		return m, nil
 
@@ -169,12 +305,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// as it might disrupt the user experience
 		return m, nil
 
-	case errorMsg:
-		// Handle general error messages
-		if string(msg) == "clear" {
-			m.showingError = false
-			m.errorMessage = ""
-		}
+	case notifyMsg:
+		return m, m.pushNotification(msg.level, msg.text)
+
+	case expireNotificationMsg:
+		m.notifications.expire(msg.id)
 		return m, nil
 
 	case addFormSubmitMsg:
@@ -204,7 +339,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.searchInput.Value() != "" {
 				m.filteredHosts = m.filterHosts(m.searchInput.Value())
 			} else {
-				m.filteredHosts = m.hosts
+				m.filteredHosts = m.visibleHosts()
 			}
 
 			m.updateTableRows()
@@ -248,7 +383,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.searchInput.Value() != "" {
 				m.filteredHosts = m.filterHosts(m.searchInput.Value())
 			} else {
-				m.filteredHosts = m.hosts
+				m.filteredHosts = m.visibleHosts()
 			}
 
 			m.updateTableRows()
@@ -267,12 +402,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case moveFormSubmitMsg:
 		if msg.err != nil {
-			// En cas d'erreur, on pourrait afficher une notification ou retourner à la liste
-			// Pour l'instant, on retourne simplement à la liste
+			// Return to the list and show why the move or copy failed (e.g. name conflict)
 			m.viewMode = ViewList
 			m.moveForm = nil
 			m.table.Focus()
-			return m, nil
+			return m, m.showError(msg.err.Error())
 		} else {
 			// Success: refresh hosts and return to list view
 			var hosts []config.SSHHost
@@ -293,14 +427,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.searchInput.Value() != "" {
 				m.filteredHosts = m.filterHosts(m.searchInput.Value())
 			} else {
-				m.filteredHosts = m.hosts
+				m.filteredHosts = m.visibleHosts()
 			}
 
 			m.updateTableRows()
 			m.viewMode = ViewList
+			verb := "Moved"
+			if m.moveForm != nil && m.moveForm.duplicate {
+				verb = "Copied"
+			}
 			m.moveForm = nil
 			m.table.Focus()
-			return m, nil
+			return m, m.pushNotification(NotifySuccess, fmt.Sprintf("%s %s to %s", verb, msg.hostName, filepath.Base(msg.targetFile)))
 		}
 
 	case moveFormCancelMsg:
@@ -317,6 +455,225 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.table.Focus()
 		return m, nil
 
+	case infoFormResolvedMsg:
+		// Show resolved config on top of the info view
+		m.resolvedConfigForm = NewResolvedConfigForm(msg.hostName, m.styles, m.width, m.height, m.configFile)
+		m.viewMode = ViewResolvedConfig
+		return m, nil
+
+	case resolvedConfigCloseMsg:
+		// Return to the info view it was opened from
+		m.resolvedConfigForm = nil
+		if m.infoForm != nil {
+			m.viewMode = ViewInfo
+			return m, nil
+		}
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case savedViewApplyMsg:
+		m.savedViewsForm = nil
+		m.viewMode = ViewList
+		m.applySavedView(msg.view)
+		return m, nil
+
+	case savedViewsCancelMsg:
+		m.savedViewsForm = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case configSwitchMsg:
+		m.configSwitcher = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		if err := m.switchConfigFile(msg.path); err != nil {
+			return m, m.showError(fmt.Sprintf("Could not load %s: %v", msg.path, err))
+		}
+		return m, m.pushNotification(NotifyInfo, "Switched to "+displayConfigPath(msg.path))
+
+	case configSwitcherCancelMsg:
+		m.configSwitcher = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case sourceFilterMsg:
+		m.sourceFilterForm = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		m.setSourceFilter(msg.path)
+		return m, nil
+
+	case sourceFilterCancelMsg:
+		m.sourceFilterForm = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case identityCheckMsg:
+		if len(msg.missing) == 0 {
+			return m, m.connectToHost(msg.hostName, "")
+		}
+		m.identityWarning = NewIdentityWarning(msg.hostName, msg.missing, m.styles, m.width, m.height)
+		m.viewMode = ViewIdentityWarning
+		return m, nil
+
+	case identityWarningProceedMsg:
+		m.identityWarning = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, m.connectToHost(msg.hostName, "")
+
+	case identityWarningCancelMsg:
+		m.identityWarning = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case retryTransferConfirmMsg:
+		m.retryTransferForm = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		if err := transfer.ValidateSCPExtraArgs(msg.request.ExtraArgs); err != nil {
+			return m, m.showError("Cannot retry transfer: " + err.Error())
+		}
+		request := msg.request
+		return m, tea.Exec(newTransferExec(request), func(err error) tea.Msg {
+			return retryTransferDoneMsg{request: request, err: err}
+		})
+
+	case retryTransferCancelMsg:
+		m.retryTransferForm = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case retryTransferDoneMsg:
+		recordTransferAttempt(m.historyManager, msg.request, msg.err)
+		if msg.err != nil {
+			return m, m.showError("Transfer failed again: " + msg.err.Error())
+		}
+		if m.historyManager != nil {
+			direction := "upload"
+			if msg.request.Direction == transfer.Download {
+				direction = "download"
+			}
+			_ = m.historyManager.RecordTransfer(msg.request.Host, direction, msg.request.LocalPath, msg.request.RemotePath)
+		}
+		pathStore, _ := history.NewRemotePathStore()
+		recordRemotePath(pathStore, msg.request)
+		return m, m.pushNotification(NotifySuccess, "Transfer to "+msg.request.Host+" complete")
+
+	case configuredForwardsMsg:
+		if msg.err != nil {
+			return m, m.showError(msg.err.Error())
+		}
+		if len(config.ConfiguredForwards(msg.options)) == 0 {
+			return m, m.pushNotification(NotifyInfo, "No LocalForward, RemoteForward or DynamicForward configured for "+msg.hostName)
+		}
+		m.forwardPicker = NewForwardPicker(msg.hostName, msg.options, m.styles, m.width, m.height)
+		m.viewMode = ViewForwardPicker
+		return m, nil
+
+	case forwardPickerConnectMsg:
+		m.forwardPicker = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, m.connectWithForwards(msg)
+
+	case forwardPickerCancelMsg:
+		m.forwardPicker = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case backgroundForwardsMsg:
+		if msg.err != nil {
+			return m, m.showError("Could not list background tunnels: " + msg.err.Error())
+		}
+		m.forwardsList = NewForwardsList(msg.registry, msg.forwards, m.styles, m.width, m.height)
+		m.viewMode = ViewForwardsList
+		return m, nil
+
+	case backgroundForwardStoppedMsg:
+		if m.forwardsList != nil {
+			var cmd tea.Cmd
+			m.forwardsList, cmd = m.forwardsList.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case forwardsListCloseMsg:
+		m.forwardsList = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case webURLSavedMsg:
+		m.webURLForm = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		if msg.url == "" {
+			return m, m.pushNotification(NotifySuccess, "Web URL of "+msg.hostName+" cleared")
+		}
+		return m, m.pushNotification(NotifySuccess, "Web URL of "+msg.hostName+" set to "+msg.url)
+
+	case webURLCancelMsg:
+		m.webURLForm = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case copyIDCheckMsg:
+		if m.copyIDPicker != nil {
+			m.copyIDPicker, _ = m.copyIDPicker.Update(msg)
+		}
+		return m, nil
+
+	case copyIDPickerCancelMsg:
+		m.copyIDPicker = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case copyIDPickedMsg:
+		m.copyIDPicker = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+		if err := transfer.RequireBinary("ssh-copy-id"); err != nil {
+			return m, m.showError(err.Error())
+		}
+		hostName, publicKey := msg.hostName, msg.publicKey
+		return m, tea.ExecProcess(copyIDCommand(hostName, m.configFile, publicKey), func(err error) tea.Msg {
+			return copyIDDoneMsg{hostName: hostName, publicKey: publicKey, err: err}
+		})
+
+	case copyIDDoneMsg:
+		if msg.err != nil {
+			return m, m.showError("ssh-copy-id failed: " + msg.err.Error())
+		}
+		return m, m.pushNotification(NotifySuccess, fmt.Sprintf("Installed %s on %s", filepath.Base(msg.publicKey), msg.hostName))
+
+	case identityPickerCancelMsg:
+		// Cancel: return to list view
+		m.viewMode = ViewList
+		m.identityPicker = nil
+		m.table.Focus()
+		return m, nil
+
+	case identityPickedMsg:
+		m.identityPicker = nil
+		if msg.save {
+			if err := config.SetHostIdentity(msg.hostName, msg.identity, m.configFile); err != nil {
+				m.viewMode = ViewList
+				m.table.Focus()
+				return m, m.showError(err.Error())
+			}
+		}
+		return m, m.connectToHost(msg.hostName, msg.identity)
+
 	case fileSelectorMsg:
 		if msg.cancelled {
 			// Cancel: return to list view
@@ -355,21 +712,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		} else {
+			// Background: start the tunnel detached and return to the list
+			if msg.background && len(msg.sshArgs) > 0 {
+				hostName := ""
+				if m.portForwardForm != nil {
+					hostName = m.portForwardForm.hostName
+				}
+				m.viewMode = ViewList
+				m.portForwardForm = nil
+				m.table.Focus()
+				return m, m.startBackgroundForward(hostName, msg.forward, msg.sshArgs)
+			}
+
 			// Success: execute SSH command with port forwarding
 			if len(msg.sshArgs) > 0 {
 				sshCmd := exec.Command("ssh", msg.sshArgs...)
+				if err := transfer.CheckCommand(sshCmd); err != nil {
+					if m.portForwardForm != nil {
+						m.portForwardForm.err = err.Error()
+					}
+					return m, nil
+				}
 
 				// Record the connection in history
+				var warn tea.Cmd
 				if m.historyManager != nil && m.portForwardForm != nil {
 					err := m.historyManager.RecordConnection(m.portForwardForm.hostName)
 					if err != nil {
-						fmt.Printf("Warning: Could not record connection history: %v\n", err)
+						warn = m.pushNotification(NotifyWarn, fmt.Sprintf("Could not record connection history: %v", err))
 					}
 				}
 
-				return m, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
+				return m, tea.Batch(warn, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
 					return tea.Quit()
-				})
+				}))
 			}
 
 			// If no SSH args, just return to list view
@@ -394,6 +770,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		} else {
+			if m.transferForm != nil {
+				m.transferForm.closeCompletion()
+			}
 			// Success: execute transfer command
 			if msg.request != nil {
 				// Record the transfer in history
@@ -409,10 +788,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						msg.request.RemotePath,
 					)
 				}
+				if m.transferForm != nil {
+					recordRemotePath(m.transferForm.pathStore, msg.request)
+				}
 
-				// Build and execute scp command
-				scpCmd := msg.request.BuildSCPCommand()
-				return m, tea.ExecProcess(scpCmd, func(err error) tea.Msg {
+				// Build and execute scp command, asking first before
+				// overwriting an existing local file
+				request := msg.request
+				historyManager := m.historyManager
+				return m, tea.Exec(newTransferExec(request), func(err error) tea.Msg {
+					if !errors.Is(err, errDownloadSkipped) {
+						recordTransferAttempt(historyManager, request, err)
+					}
 					return tea.Quit()
 				})
 			}
@@ -424,6 +811,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+	case remoteCompletionMsg:
+		// A listing that finishes once the form is closed still holds its session
+		if m.viewMode != ViewTransfer || m.transferForm == nil {
+			if msg.session != nil {
+				msg.session.Close()
+			}
+			return m, nil
+		}
+		var newForm *transferFormModel
+		newForm, cmd = m.transferForm.Update(msg)
+		m.transferForm = newForm
+		return m, cmd
+
 	case transferCancelMsg:
 		// Cancel: return to list view
 		m.viewMode = ViewList
@@ -434,11 +834,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case quickTransferCancelMsg:
 		// Quick transfer cancelled or done: return to list view
 		m.viewMode = ViewList
+		if m.quickTransferForm != nil {
+			m.quickTransferForm.close()
+		}
 		m.quickTransferForm = nil
 		m.table.Focus()
 		return m, nil
 
-	case quickLocalPickedMsg, quickRemotePickedMsg, quickTransferDoneMsg:
+	case quickLocalPickedMsg, quickRemotePickedMsg, quickTransferDoneMsg, transferProgressMsg,
+		transferLoginCheckedMsg, transferPasswordMsg:
 		// Route quick transfer async messages to the form
 		if m.viewMode == ViewQuickTransfer && m.quickTransferForm != nil {
 			var newForm *quickTransferModel
@@ -448,19 +852,53 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case sizeEstimateMsg:
+		// Sizes are measured while a browser picks the other path of the transfer
+		if m.quickTransferForm != nil {
+			var newForm *quickTransferModel
+			newForm, cmd = m.quickTransferForm.Update(msg)
+			m.quickTransferForm = newForm
+			return m, cmd
+		}
+		return m, nil
+
 	case openRemoteBrowserMsg:
 		// Open the remote browser as a sub-view (not a nested program)
 		m.remoteBrowserForm = NewRemoteBrowser(msg.host, msg.startPath, msg.configFile, msg.mode, m.styles, m.width, m.height)
+		m.remoteBrowserForm.keepSession = msg.keepSession
+		m.remoteBrowserForm.nameDefault = msg.saveAsName
 		m.viewMode = ViewRemoteBrowser
 		return m, m.remoteBrowserForm.Init()
 
 	case remoteBrowserResultMsg:
 		// Remote browser completed - route result back to quick transfer
 		m.remoteBrowserForm = nil
+		if m.quickTransferForm == nil && msg.session != nil {
+			msg.session.Close()
+		}
 		m.viewMode = ViewQuickTransfer
 		if m.quickTransferForm != nil {
 			// Convert to quickRemotePickedMsg
-			pickedMsg := quickRemotePickedMsg{path: msg.path, selected: msg.selected}
+			pickedMsg := quickRemotePickedMsg{path: msg.path, selected: msg.selected, session: msg.session}
+			var newForm *quickTransferModel
+			newForm, cmd = m.quickTransferForm.Update(pickedMsg)
+			m.quickTransferForm = newForm
+			return m, cmd
+		}
+		return m, nil
+
+	case openLocalBrowserMsg:
+		// Open the local browser as a sub-view (not a nested program)
+		m.localBrowserForm = NewLocalBrowser(msg.startDir, msg.mode, m.styles, m.width, m.height)
+		m.viewMode = ViewLocalBrowser
+		return m, m.localBrowserForm.Init()
+
+	case localBrowserResultMsg:
+		// Local browser completed - route result back to quick transfer
+		m.localBrowserForm = nil
+		m.viewMode = ViewQuickTransfer
+		if m.quickTransferForm != nil {
+			pickedMsg := quickLocalPickedMsg{path: msg.path, selected: msg.selected}
 			var newForm *quickTransferModel
 			newForm, cmd = m.quickTransferForm.Update(pickedMsg)
 			m.quickTransferForm = newForm
@@ -468,7 +906,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case remoteBrowserLoadedMsg, remoteBrowserSearchMsg, searchDebounceMsg:
+	case remoteBrowserLoadedMsg, remoteBrowserSearchMsg, searchDebounceMsg, remoteBrowserCopiedMsg, remoteBrowserChecksumMsg, keyUnlockedMsg:
 		// Route remote browser async messages to the form
 		if m.viewMode == ViewRemoteBrowser && m.remoteBrowserForm != nil {
 			var newForm *remoteBrowserModel
@@ -544,6 +982,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.remoteBrowserForm = newForm
 				return m, cmd
 			}
+		case ViewLocalBrowser:
+			if m.localBrowserForm != nil {
+				var newForm *localBrowserModel
+				newForm, cmd = m.localBrowserForm.Update(msg)
+				m.localBrowserForm = newForm
+				return m, cmd
+			}
 		case ViewHelp:
 			if m.helpForm != nil {
 				var newForm *helpModel
@@ -558,6 +1003,83 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.fileSelectorForm = newForm
 				return m, cmd
 			}
+		case ViewResolvedConfig:
+			if m.resolvedConfigForm != nil {
+				var newForm *resolvedConfigModel
+				newForm, cmd = m.resolvedConfigForm.Update(msg)
+				m.resolvedConfigForm = newForm
+				return m, cmd
+			}
+		case ViewIdentityPicker:
+			if m.identityPicker != nil {
+				var newForm *identityPickerModel
+				newForm, cmd = m.identityPicker.Update(msg)
+				m.identityPicker = newForm
+				return m, cmd
+			}
+		case ViewSavedViews:
+			if m.savedViewsForm != nil {
+				var newForm *savedViewsModel
+				newForm, cmd = m.savedViewsForm.Update(msg)
+				m.savedViewsForm = newForm
+				return m, cmd
+			}
+		case ViewConfigSwitcher:
+			if m.configSwitcher != nil {
+				var newForm *configSwitcherModel
+				newForm, cmd = m.configSwitcher.Update(msg)
+				m.configSwitcher = newForm
+				return m, cmd
+			}
+		case ViewSourceFilter:
+			if m.sourceFilterForm != nil {
+				var newForm *sourceFilterModel
+				newForm, cmd = m.sourceFilterForm.Update(msg)
+				m.sourceFilterForm = newForm
+				return m, cmd
+			}
+		case ViewRetryTransfer:
+			if m.retryTransferForm != nil {
+				var newForm *retryTransferModel
+				newForm, cmd = m.retryTransferForm.Update(msg)
+				m.retryTransferForm = newForm
+				return m, cmd
+			}
+		case ViewIdentityWarning:
+			if m.identityWarning != nil {
+				var newForm *identityWarningModel
+				newForm, cmd = m.identityWarning.Update(msg)
+				m.identityWarning = newForm
+				return m, cmd
+			}
+		case ViewCopyID:
+			if m.copyIDPicker != nil {
+				var newForm *copyIDPickerModel
+				newForm, cmd = m.copyIDPicker.Update(msg)
+				m.copyIDPicker = newForm
+				return m, cmd
+			}
+		case ViewForwardPicker:
+			if m.forwardPicker != nil {
+				var newForm *forwardPickerModel
+				newForm, cmd = m.forwardPicker.Update(msg)
+				m.forwardPicker = newForm
+				return m, cmd
+			}
+		case ViewWebURL:
+			if m.webURLForm != nil {
+				var newForm *webURLFormModel
+				newForm, cmd = m.webURLForm.Update(msg)
+				m.webURLForm = newForm
+				return m, cmd
+			}
+		case ViewForwardsList:
+			if m.forwardsList != nil {
+				var newForm *forwardsListModel
+				newForm, cmd = m.forwardsList.Update(msg)
+				m.forwardsList = newForm
+				return m, cmd
+			}
 		case ViewList:
 			// Handle list view keys
 			return m.handleListViewKeys(msg)
@@ -571,6 +1093,28 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	key := msg.String()
 
+	// Any key dismisses the notifications early; Esc does nothing else
+	if len(m.notifications.items) > 0 {
+		m.notifications.clear()
+		if key == "esc" {
+			return m, nil
+		}
+	}
+
+	// The command line takes every key while it is open
+	if m.commandMode {
+		return m.handleCommandKeys(msg)
+	}
+	if m.renameHost != "" {
+		return m.handleRenameKeys(msg)
+	}
+
+	// Quit keys other than Esc and Ctrl+C, which also leave delete mode
+	keys := m.keyBindings()
+	if key != "esc" && key != "ctrl+c" && !m.searchMode && !m.deleteMode && keys.ShouldQuitOnKey(key) {
+		return m, tea.Quit
+	}
+
 	switch key {
 	case "esc", "ctrl+c":
 		if m.deleteMode {
@@ -581,17 +1125,10 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		// Use configurable key bindings for quit
-		if m.appConfig != nil && m.appConfig.KeyBindings.ShouldQuitOnKey(key) {
+		if keys.ShouldQuitOnKey(key) {
 			return m, tea.Quit
 		}
-	case "q":
-		if !m.searchMode && !m.deleteMode {
-			// Use configurable key bindings for quit
-			if m.appConfig != nil && m.appConfig.KeyBindings.ShouldQuitOnKey(key) {
-				return m, tea.Quit
-			}
-		}
-	case "/", "ctrl+f":
+	case keys.KeyFor(config.ActionSearch), "ctrl+f":
 		if !m.searchMode && !m.deleteMode {
 			// Enter search mode
 			m.searchMode = true
@@ -628,6 +1165,12 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.updateTableStyles()
 			m.searchInput.Blur()
 			m.table.Focus()
+
+			// Optionally connect straight to the top match, the same way as Enter on the table
+			if m.appConfig != nil && m.appConfig.SearchEnterAction == config.SearchEnterConnectTop && len(m.filteredHosts) > 0 {
+				m.table.SetCursor(0)
+				return m, m.connectWithIdentityCheck(m.filteredHosts[0].Name)
+			}
 			return m, nil
 		} else if m.deleteMode {
 			// Confirm deletion
@@ -667,7 +1210,7 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.searchInput.Value() != "" {
 				m.filteredHosts = m.filterHosts(m.searchInput.Value())
 			} else {
-				m.filteredHosts = m.hosts
+				m.filteredHosts = m.visibleHosts()
 			}
 
 			m.updateTableRows()
@@ -680,30 +1223,10 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			selected := m.table.SelectedRow()
 			if len(selected) > 0 {
 				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
-
-				// Record the connection in history
-				if m.historyManager != nil {
-					err := m.historyManager.RecordConnection(hostName)
-					if err != nil {
-						// Log the error but don't prevent the connection
-						fmt.Printf("Warning: Could not record connection history: %v\n", err)
-					}
-				}
-
-				// Build the SSH command with the appropriate config file
-				var sshCmd *exec.Cmd
-				if m.configFile != "" {
-					sshCmd = exec.Command("ssh", "-F", m.configFile, hostName)
-				} else {
-					sshCmd = exec.Command("ssh", hostName)
-				}
-
-				return m, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
-					return tea.Quit()
-				})
+				return m, m.connectWithIdentityCheck(hostName)
 			}
 		}
-	case "e":
+	case keys.KeyFor(config.ActionEdit):
 		if !m.searchMode && !m.deleteMode {
 			// Edit the selected host
 			selected := m.table.SelectedRow()
@@ -719,7 +1242,7 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, textinput.Blink
 			}
 		}
-	case "m":
+	case keys.KeyFor(config.ActionMove):
 		if !m.searchMode && !m.deleteMode {
 			// Move the selected host to another config file
 			selected := m.table.SelectedRow()
@@ -728,19 +1251,29 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				moveForm, err := NewMoveForm(hostName, m.styles, m.width, m.height, m.configFile)
 				if err != nil {
 					// Show error message to user
-					m.errorMessage = err.Error()
-					m.showingError = true
-					return m, func() tea.Msg {
-						time.Sleep(3 * time.Second) // Show error for 3 seconds
-						return errorMsg("clear")
-					}
+					return m, m.showError(err.Error())
 				}
 				m.moveForm = moveForm
 				m.viewMode = ViewMove
 				return m, textinput.Blink
 			}
 		}
-	case "i":
+	case keys.KeyFor(config.ActionCopy):
+		if !m.searchMode && !m.deleteMode {
+			// Duplicate the selected host into another config file
+			selected := m.table.SelectedRow()
+			if len(selected) > 0 {
+				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+				copyForm, err := NewCopyForm(hostName, m.styles, m.width, m.height, m.configFile)
+				if err != nil {
+					return m, m.showError(err.Error())
+				}
+				m.moveForm = copyForm
+				m.viewMode = ViewMove
+				return m, textinput.Blink
+			}
+		}
+	case keys.KeyFor(config.ActionInfo):
 		if !m.searchMode && !m.deleteMode {
 			// Show info for the selected host
 			selected := m.table.SelectedRow()
@@ -751,12 +1284,104 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					// Handle error - could show in UI
 					return m, nil
 				}
+				if m.pingManager != nil {
+					infoForm.ping, _ = m.pingManager.GetResult(hostName)
+				}
 				m.infoForm = infoForm
 				m.viewMode = ViewInfo
 				return m, nil
 			}
 		}
-	case "a":
+	case keys.KeyFor(config.ActionConnectWithKey):
+		if !m.searchMode && !m.deleteMode {
+			// Pick an identity file for a one-off connection
+			selected := m.table.SelectedRow()
+			if len(selected) > 0 {
+				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+				m.identityPicker = NewIdentityPicker(hostName, m.styles, m.width, m.height)
+				m.viewMode = ViewIdentityPicker
+				return m, nil
+			}
+		}
+	case keys.KeyFor(config.ActionMark):
+		if !m.searchMode && !m.deleteMode {
+			// Mark the selected host for actions on several hosts
+			selected := m.table.SelectedRow()
+			if len(selected) > 0 {
+				m.toggleMark(extractHostNameFromTableRow(selected[0]))
+				return m, nil
+			}
+		}
+	case keys.KeyFor(config.ActionExport):
+		if !m.searchMode && !m.deleteMode {
+			// Export the connect commands of the marked hosts, or of the selected one
+			hostNames := m.markedHostNames()
+			if len(hostNames) == 0 {
+				selected := m.table.SelectedRow()
+				if len(selected) == 0 {
+					return m, nil
+				}
+				hostNames = []string{extractHostNameFromTableRow(selected[0])}
+			}
+			path, err := exportConnectCommands(connectCommands(hostNames, m.configFile))
+			if err != nil {
+				return m, m.showError("Could not export connect commands: " + err.Error())
+			}
+			if path != "" {
+				return m, m.pushNotification(NotifyInfo, fmt.Sprintf("Clipboard unavailable, wrote %d connect command(s) to %s", len(hostNames), path))
+			}
+			return m, m.pushNotification(NotifySuccess, fmt.Sprintf("Copied %d connect command(s)", len(hostNames)))
+		}
+	case keys.KeyFor(config.ActionTmux):
+		if !m.searchMode && !m.deleteMode {
+			// Connect to the marked hosts, or to the selected one, in tmux panes
+			hostNames := m.markedHostNames()
+			if len(hostNames) == 0 {
+				selected := m.table.SelectedRow()
+				if len(selected) == 0 {
+					return m, nil
+				}
+				hostNames = []string{extractHostNameFromTableRow(selected[0])}
+			}
+			return m, m.connectInTmux(hostNames)
+		}
+	case keys.KeyFor(config.ActionConnectForward):
+		if !m.searchMode && !m.deleteMode {
+			// Connect choosing which configured port forwardings to open
+			selected := m.table.SelectedRow()
+			if len(selected) > 0 {
+				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+				return m, loadConfiguredForwards(hostName, m.configFile)
+			}
+		}
+	case keys.KeyFor(config.ActionWeb):
+		if !m.searchMode && !m.deleteMode {
+			// Open the web UI of the selected host in the default browser
+			if host, ok := m.selectedSSHHost(); ok {
+				return m, openWebURL(host)
+			}
+		}
+	case keys.KeyFor(config.ActionWebURL):
+		if !m.searchMode && !m.deleteMode {
+			// Set the web URL opened with w for the selected host
+			if host, ok := m.selectedSSHHost(); ok {
+				m.webURLForm = NewWebURLForm(host, m.styles, m.width, m.height)
+				m.viewMode = ViewWebURL
+				return m, textinput.Blink
+			}
+		}
+	case keys.KeyFor(config.ActionCopyID):
+		if !m.searchMode && !m.deleteMode {
+			// Install a public key on the selected host with ssh-copy-id
+			selected := m.table.SelectedRow()
+			if len(selected) > 0 {
+				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+				m.copyIDPicker = NewCopyIDPicker(hostName, m.configFile, m.styles, m.width, m.height)
+				m.viewMode = ViewCopyID
+				return m, checkAuthorizedKeys(hostName, m.configFile)
+			}
+		}
+	case keys.KeyFor(config.ActionAdd):
 		if !m.searchMode && !m.deleteMode {
 			// Check if there are multiple config files starting from the current base config
 			var configFiles []string
@@ -794,7 +1419,7 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			return m, textinput.Blink
 		}
-	case "d":
+	case keys.KeyFor(config.ActionDelete):
 		if !m.searchMode && !m.deleteMode {
 			// Delete the selected host
 			selected := m.table.SelectedRow()
@@ -806,12 +1431,13 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		}
-	case "p":
+	case keys.KeyFor(config.ActionPing):
 		if !m.searchMode && !m.deleteMode {
 			// Ping all hosts
-			return m, m.startPingAllCmd()
+			cmd := m.startPingAllCmd()
+			return m, cmd
 		}
-	case "f":
+	case keys.KeyFor(config.ActionPortForward):
 		if !m.searchMode && !m.deleteMode {
 			// Port forwarding for the selected host
 			selected := m.table.SelectedRow()
@@ -822,7 +1448,61 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, textinput.Blink
 			}
 		}
-	case "t":
+	case keys.KeyFor(config.ActionFavorite):
+		if !m.searchMode && !m.deleteMode {
+			// Star or unstar the selected host
+			if host, ok := m.selectedSSHHost(); ok {
+				if err := m.toggleFavorite(host.Name); err != nil {
+					return m, m.showError("Could not save favorite hosts: " + err.Error())
+				}
+				return m, nil
+			}
+		}
+	case keys.KeyFor(config.ActionFavoritesOnly):
+		if !m.searchMode && !m.deleteMode {
+			// Switch between the favorite hosts and all hosts
+			m.toggleFavoritesOnly()
+			return m, nil
+		}
+	case keys.KeyFor(config.ActionTagFilter):
+		if !m.searchMode && !m.deleteMode {
+			// List the hosts of the next tag, then all hosts again
+			return m, m.cycleTagFilter()
+		}
+	case keys.KeyFor(config.ActionSourceFilter):
+		if !m.searchMode && !m.deleteMode {
+			// Choose the config file whose hosts only are listed
+			return m, m.openSourceFilter()
+		}
+	case keys.KeyFor(config.ActionRename):
+		if !m.searchMode && !m.deleteMode {
+			// Rename the selected host where it is defined
+			if host, ok := m.selectedSSHHost(); ok {
+				return m, m.openRename(host.Name)
+			}
+		}
+	case keys.KeyFor(config.ActionBanner):
+		if !m.searchMode && !m.deleteMode {
+			// Collapse or expand the ASCII title
+			if err := m.toggleBanner(); err != nil {
+				return m, m.showError("Could not save the banner setting: " + err.Error())
+			}
+			return m, nil
+		}
+	case keys.KeyFor(config.ActionSOCKSProxy):
+		if !m.searchMode && !m.deleteMode {
+			// Start a SOCKS proxy through the selected host in the background
+			selected := m.table.SelectedRow()
+			if len(selected) > 0 {
+				return m, m.startSOCKSProxy(extractHostNameFromTableRow(selected[0]))
+			}
+		}
+	case keys.KeyFor(config.ActionTunnels):
+		if !m.searchMode && !m.deleteMode {
+			// List the tunnels running in the background
+			return m, loadBackgroundForwards()
+		}
+	case keys.KeyFor(config.ActionTransfer):
 		if !m.searchMode && !m.deleteMode {
 			// Quick file transfer for the selected host
 			selected := m.table.SelectedRow()
@@ -833,50 +1513,68 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		}
-	case "h":
+	case keys.KeyFor(config.ActionCommand):
+		if !m.searchMode && !m.deleteMode {
+			// Type a command such as "edit web1" instead of its key
+			return m, m.openCommandLine()
+		}
+	case keys.KeyFor(config.ActionHelp):
 		if !m.searchMode && !m.deleteMode {
 			// Show help
-			m.helpForm = NewHelpForm(m.styles, m.width, m.height)
+			m.helpForm = NewHelpForm(m.styles, m.width, m.height, keys)
 			m.viewMode = ViewHelp
 			return m, nil
 		}
-	case "s":
+	case keys.KeyFor(config.ActionSort):
 		if !m.searchMode && !m.deleteMode {
 			// Cycle through sort modes (only 2 modes now)
-			m.sortMode = (m.sortMode + 1) % 2
-			// Re-apply the current filter with the new sort mode
-			if m.searchInput.Value() != "" {
-				m.filteredHosts = m.filterHosts(m.searchInput.Value())
-			} else {
-				m.filteredHosts = m.sortHosts(m.hosts)
-			}
-			m.updateTableRows()
+			m.setSortMode((m.sortMode + 1) % 2)
 			return m, nil
 		}
-	case "r":
+	case keys.KeyFor(config.ActionSortRecent):
 		if !m.searchMode && !m.deleteMode {
 			// Switch to sort by recent (last used)
-			m.sortMode = SortByLastUsed
-			// Re-apply the current filter with the new sort mode
-			if m.searchInput.Value() != "" {
-				m.filteredHosts = m.filterHosts(m.searchInput.Value())
-			} else {
-				m.filteredHosts = m.sortHosts(m.hosts)
-			}
-			m.updateTableRows()
+			m.setSortMode(SortByLastUsed)
 			return m, nil
 		}
-	case "n":
+	case keys.KeyFor(config.ActionSavedViews):
+		if !m.searchMode && !m.deleteMode {
+			// Open saved views, offering to save the current search
+			m.savedViewsForm = NewSavedViews(m.searchInput.Value(), m.sortMode, m.styles, m.width, m.height)
+			m.viewMode = ViewSavedViews
+			return m, nil
+		}
+	case keys.KeyFor(config.ActionSwitchConfig):
+		if !m.searchMode && !m.deleteMode {
+			// Switch to another SSH config file
+			m.configSwitcher = NewConfigSwitcher(m.activeConfigFile(), m.styles, m.width, m.height)
+			m.viewMode = ViewConfigSwitcher
+			return m, nil
+		}
+	case keys.KeyFor(config.ActionSortName):
 		if !m.searchMode && !m.deleteMode {
 			// Switch to sort by name
-			m.sortMode = SortByName
-			// Re-apply the current filter with the new sort mode
-			if m.searchInput.Value() != "" {
-				m.filteredHosts = m.filterHosts(m.searchInput.Value())
-			} else {
-				m.filteredHosts = m.sortHosts(m.hosts)
+			m.setSortMode(SortByName)
+			return m, nil
+		}
+	case keys.KeyFor(config.ActionSortSwap):
+		if !m.searchMode && !m.deleteMode {
+			// Swap back to the previously used sort mode
+			m.toggleSortMode()
+			return m, nil
+		}
+	case keys.KeyFor(config.ActionRetryTransfer):
+		if !m.searchMode && !m.deleteMode {
+			// Show the last attempted transfer before running it again
+			var attempt *history.TransferAttempt
+			if m.historyManager != nil {
+				attempt = m.historyManager.GetLastTransferAttempt()
 			}
-			m.updateTableRows()
+			if attempt == nil {
+				return m, m.pushNotification(NotifyInfo, "No transfer to retry yet")
+			}
+			m.retryTransferForm = NewRetryTransfer(attempt, m.styles, m.width, m.height)
+			m.viewMode = ViewRetryTransfer
 			return m, nil
 		}
 	}
@@ -891,7 +1589,7 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.searchInput.Value() != "" {
 				m.filteredHosts = m.filterHosts(m.searchInput.Value())
 			} else {
-				m.filteredHosts = m.sortHosts(m.hosts)
+				m.filteredHosts = m.sortHosts(m.visibleHosts())
 			}
 			m.updateTableRows()
 			// If the current cursor position is beyond the filtered results, reset to 0
@@ -905,3 +1603,25 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	return m, cmd
 }
+
+// connectToHost records the connection and runs ssh for the host, optionally
+// forcing an identity file for this connection only
+func (m Model) connectToHost(hostName, identity string) tea.Cmd {
+	// Record the connection in history
+	var warn tea.Cmd
+	if m.historyManager != nil {
+		err := m.historyManager.RecordConnection(hostName)
+		if err != nil {
+			// Report the error but don't prevent the connection
+			warn = notify(NotifyWarn, fmt.Sprintf("Could not record connection history: %v", err))
+		}
+	}
+
+	sshCmd := exec.Command("ssh", config.BuildConnectArgs(hostName, m.configFile, identity)...)
+	if err := transfer.CheckCommand(sshCmd); err != nil {
+		return tea.Batch(warn, notify(NotifyError, err.Error()))
+	}
+	return tea.Batch(warn, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
+		return tea.Quit()
+	}))
+}
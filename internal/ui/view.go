@@ -2,8 +2,11 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -47,6 +50,10 @@ func (m Model) View() string {
 		if m.remoteBrowserForm != nil {
 			return m.remoteBrowserForm.View()
 		}
+	case ViewLocalBrowser:
+		if m.localBrowserForm != nil {
+			return m.localBrowserForm.View()
+		}
 	case ViewHelp:
 		if m.helpForm != nil {
 			return m.helpForm.View()
@@ -55,6 +62,50 @@ func (m Model) View() string {
 		if m.fileSelectorForm != nil {
 			return m.fileSelectorForm.View()
 		}
+	case ViewResolvedConfig:
+		if m.resolvedConfigForm != nil {
+			return m.resolvedConfigForm.View()
+		}
+	case ViewIdentityPicker:
+		if m.identityPicker != nil {
+			return m.identityPicker.View()
+		}
+	case ViewSavedViews:
+		if m.savedViewsForm != nil {
+			return m.savedViewsForm.View()
+		}
+	case ViewConfigSwitcher:
+		if m.configSwitcher != nil {
+			return m.configSwitcher.View()
+		}
+	case ViewSourceFilter:
+		if m.sourceFilterForm != nil {
+			return m.sourceFilterForm.View()
+		}
+	case ViewRetryTransfer:
+		if m.retryTransferForm != nil {
+			return m.retryTransferForm.View()
+		}
+	case ViewIdentityWarning:
+		if m.identityWarning != nil {
+			return m.identityWarning.View()
+		}
+	case ViewCopyID:
+		if m.copyIDPicker != nil {
+			return m.copyIDPicker.View()
+		}
+	case ViewForwardPicker:
+		if m.forwardPicker != nil {
+			return m.forwardPicker.View()
+		}
+	case ViewWebURL:
+		if m.webURLForm != nil {
+			return m.webURLForm.View()
+		}
+	case ViewForwardsList:
+		if m.forwardsList != nil {
+			return m.forwardsList.View()
+		}
 	case ViewList:
 		return m.renderListView()
 	}
@@ -67,8 +118,10 @@ func (m Model) renderListView() string {
 	// Build the interface components
 	components := []string{}
 
-	// Add the ASCII title
-	components = append(components, m.styles.Header.Render(asciiTitle))
+	// Add the ASCII title, unless collapsed with B
+	if !m.hideBanner {
+		components = append(components, m.styles.Header.Render(asciiTitle))
+	}
 
 	// Add update notification if available (between title and search)
 	if m.updateInfo != nil && m.updateInfo.Available {
@@ -84,22 +137,23 @@ func (m Model) renderListView() string {
 		components = append(components, updateStyle.Render(updateText))
 	}
 
-	// Add error message if there's one to show
-	if m.showingError && m.errorMessage != "" {
-		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("9")). // Red color
-			Background(lipgloss.Color("1")). // Dark red background
-			Bold(true).
-			Padding(0, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("9")).
-			Align(lipgloss.Center)
-
-		components = append(components, errorStyle.Render("❌ "+m.errorMessage))
+	// Add notifications if there are any to show
+	if toasts := m.renderNotifications(); toasts != "" {
+		components = append(components, toasts)
 	}
 
 	// Add the search bar with the appropriate style based on focus
-	searchPrompt := "Search (/ to focus): "
+	searchPrompt := "Search"
+	if m.favoritesOnly {
+		searchPrompt += " favorites"
+	}
+	if m.tagFilter != "" {
+		searchPrompt += " #" + m.tagFilter
+	}
+	if m.sourceFilter != "" {
+		searchPrompt += " in " + filepath.Base(m.sourceFilter)
+	}
+	searchPrompt += " (/ to focus): "
 	if m.searchMode {
 		components = append(components, m.styles.SearchFocused.Render(searchPrompt+m.searchInput.View()))
 	} else {
@@ -109,16 +163,36 @@ func (m Model) renderListView() string {
 	// Add the table with the appropriate style based on focus
 	if m.searchMode {
 		// The table is not focused, use the unfocused style
-		components = append(components, m.styles.TableUnfocused.Render(m.table.View()))
+		components = append(components, m.styles.TableUnfocused.Render(m.highlightTableMatches(m.table.View())))
 	} else {
 		// The table is focused, use the focused style with the primary color
-		components = append(components, m.styles.TableFocused.Render(m.table.View()))
+		components = append(components, m.styles.TableFocused.Render(m.highlightTableMatches(m.table.View())))
+	}
+
+	// Show how far a ping of all hosts got
+	if m.pingInProgress() {
+		components = append(components, m.styles.HelpText.Render(fmt.Sprintf(" Pinging hosts: checked %d/%d (%s)", m.pingDone, m.pingTotal, m.pingTally)))
+	}
+
+	// Explain an empty table and how to fill it
+	if emptyState := m.listEmptyState(); emptyState != "" {
+		components = append(components, m.styles.HelpText.Render(emptyState))
 	}
 
 	// Add the help text
 	var helpText string
-	if !m.searchMode {
-		helpText = " ↑/↓: navigate • Enter: connect • p: ping all • i: info • h: help • q: quit"
+	if m.renameHost != "" {
+		helpText = " " + m.renameInput.View() + "  Enter: rename • Esc: cancel"
+	} else if m.commandMode {
+		helpText = " " + m.commandInput.View()
+		if m.commandHint != "" {
+			helpText += "  " + m.commandHint
+		}
+	} else if !m.searchMode {
+		keys := m.keyBindings()
+		helpText = fmt.Sprintf(" ↑/↓: navigate • Enter: connect • %s: ping all • %s: info • %s: help • %s: quit • %s: %s",
+			keyName(keys.KeyFor(config.ActionPing)), keyName(keys.KeyFor(config.ActionInfo)), keyName(keys.KeyFor(config.ActionHelp)),
+			keyName(quitKey(keys)), keyName(keys.KeyFor(config.ActionSwitchConfig)), displayConfigPath(m.activeConfigFile()))
 	} else {
 		helpText = " Type to filter • Enter: validate • Tab: switch • ESC: quit"
 	}
@@ -152,6 +226,34 @@ func (m Model) renderListView() string {
 	return mainView
 }
 
+// listEmptyState returns the guidance shown when the host list has no rows
+func (m Model) listEmptyState() string {
+	if len(m.filteredHosts) > 0 {
+		return ""
+	}
+	if len(m.hosts) == 0 {
+		return fmt.Sprintf(" No hosts found in %s — press 'a' to add one", displayConfigPath(m.activeConfigFile()))
+	}
+	query := strings.TrimSpace(m.searchInput.Value())
+	if query == "" && m.favoritesOnly {
+		keys := m.keyBindings()
+		return fmt.Sprintf(" No favorite hosts — press %s to show all hosts, then %s to star one",
+			keyName(keys.KeyFor(config.ActionFavoritesOnly)), keyName(keys.KeyFor(config.ActionFavorite)))
+	}
+	if query == "" && m.sourceFilter != "" {
+		return fmt.Sprintf(" No hosts to show from %s — press %s to pick another file", displayConfigPath(m.sourceFilter),
+			keyName(m.keyBindings().KeyFor(config.ActionSourceFilter)))
+	}
+	if query == "" && m.tagFilter != "" {
+		return fmt.Sprintf(" No hosts tagged #%s here — press %s for the next tag", m.tagFilter,
+			keyName(m.keyBindings().KeyFor(config.ActionTagFilter)))
+	}
+	if m.searchMode {
+		return fmt.Sprintf(" No matches for '%s' — edit the search or clear it to see all %d hosts", query, len(m.hosts))
+	}
+	return fmt.Sprintf(" No matches for '%s' — press / to change the search", query)
+}
+
 // renderDeleteConfirmation renders a clean delete confirmation dialog
 func (m Model) renderDeleteConfirmation() string {
 	// Remove emojis (uncertain width depending on terminal) to stabilize the frame
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// webURLFormModel sets the URL of the web UI a host serves
+type webURLFormModel struct {
+	hostName   string
+	defaultURL string // Opened while no URL is set
+	input      textinput.Model
+	err        string
+	styles     Styles
+	width      int
+	height     int
+}
+
+// webURLSavedMsg is sent once the web URL of a host is saved, empty when cleared
+type webURLSavedMsg struct {
+	hostName string
+	url      string
+}
+
+// webURLCancelMsg is sent when the form is closed without saving
+type webURLCancelMsg struct{}
+
+// NewWebURLForm creates the form editing the web URL of a host
+func NewWebURLForm(host config.SSHHost, styles Styles, width, height int) *webURLFormModel {
+	input := textinput.New()
+	input.Placeholder = config.DefaultWebURL(host)
+	input.CharLimit = 256
+	input.Width = 40
+	if current, err := config.LoadHostWebURL(host.Name); err == nil {
+		input.SetValue(current)
+	}
+	input.Focus()
+
+	return &webURLFormModel{
+		hostName:   host.Name,
+		defaultURL: config.DefaultWebURL(host),
+		input:      input,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+}
+
+func (m *webURLFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *webURLFormModel) Update(msg tea.Msg) (*webURLFormModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c", "esc":
+			return m, func() tea.Msg { return webURLCancelMsg{} }
+
+		case "enter":
+			if err := config.SetHostWebURL(m.hostName, m.input.Value()); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			saved := webURLSavedMsg{hostName: m.hostName}
+			if strings.TrimSpace(m.input.Value()) != "" {
+				saved.url, _ = config.LoadHostWebURL(m.hostName)
+			}
+			return m, func() tea.Msg { return saved }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *webURLFormModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Web URL of %s", m.hostName)))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render(m.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(m.styles.FocusedLabel.Render("URL opened with w:"))
+	b.WriteString("\n")
+	b.WriteString(m.input.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.styles.HelpText.Render("Leave empty to open " + m.defaultURL))
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("Enter: save • Esc: cancel"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1).
+		Margin(1)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(b.String()),
+	)
+}
+
+// openWebURL opens the web URL of a host in the default browser
+func openWebURL(host config.SSHHost) tea.Cmd {
+	webURL := config.HostWebURL(host)
+	return func() tea.Msg {
+		name, args := config.OpenURLCommand(runtime.GOOS, webURL)
+		if err := exec.Command(name, args...).Start(); err != nil {
+			return notifyMsg{level: NotifyError, text: fmt.Sprintf("Could not open %s: %v", webURL, err)}
+		}
+		return notifyMsg{level: NotifyInfo, text: "Opened " + webURL}
+	}
+}
+
+// selectedSSHHost returns the host of the selected table row
+func (m Model) selectedSSHHost() (config.SSHHost, bool) {
+	selected := m.table.SelectedRow()
+	if len(selected) == 0 {
+		return config.SSHHost{}, false
+	}
+	hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+	for _, host := range m.hosts {
+		if host.Name == hostName {
+			return host, true
+		}
+	}
+	return config.SSHHost{}, false
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestWebURLFormSavesURL(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	host := config.SSHHost{Name: "nas", Hostname: "10.0.0.20"}
+	form := NewWebURLForm(host, NewStyles(120), 120, 40)
+	if !strings.Contains(form.View(), "https://10.0.0.20") {
+		t.Error("Expected the default URL to be shown")
+	}
+
+	for _, r := range "10.0.0.20:5001" {
+		form, _ = form.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, cmd := form.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	saved, ok := cmd().(webURLSavedMsg)
+	if !ok || saved.hostName != "nas" || saved.url != "https://10.0.0.20:5001" {
+		t.Fatalf("Unexpected message %+v", saved)
+	}
+	if got := config.HostWebURL(host); got != "https://10.0.0.20:5001" {
+		t.Errorf("Expected the URL to be stored, got %q", got)
+	}
+
+	// Reopening the form starts from the stored URL
+	form = NewWebURLForm(host, NewStyles(120), 120, 40)
+	if form.input.Value() != "https://10.0.0.20:5001" {
+		t.Errorf("Expected the stored URL in the input, got %q", form.input.Value())
+	}
+}
+
+func TestWebURLFormRejectsInvalidURL(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	form := NewWebURLForm(config.SSHHost{Name: "nas"}, NewStyles(120), 120, 40)
+	form.input.SetValue("ftp://nas")
+	form, cmd := form.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || form.err == "" {
+		t.Error("Expected the form to stay open with an error")
+	}
+}
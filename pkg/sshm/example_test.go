@@ -0,0 +1,46 @@
+package sshm_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/pkg/sshm"
+)
+
+func ExampleListHosts() {
+	hosts, err := sshm.ListHosts("testdata/ssh_config")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, h := range hosts {
+		fmt.Printf("%s %s@%s\n", h.Name, h.User, h.Hostname)
+	}
+	// Output:
+	// web1 deploy@10.0.0.10
+	// db1 postgres@db.internal
+}
+
+func ExampleConnect() {
+	// Run a command on a host from ~/.ssh/config and record it in sshm's history
+	err := sshm.Connect("web1", sshm.ConnectOptions{
+		Command:       []string{"uptime"},
+		RecordHistory: true,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleTransfer() {
+	result, err := sshm.Transfer(sshm.TransferRequest{
+		Host:       "web1",
+		Direction:  sshm.Download,
+		RemotePath: "/var/log/app.log",
+		LocalPath:  "./app.log",
+	}, sshm.NewWriterReporter(os.Stderr))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("downloaded %d bytes\n", result.BytesSent)
+}
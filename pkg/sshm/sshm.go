@@ -0,0 +1,187 @@
+// Package sshm exposes sshm's host listing, connection and file transfer logic
+// to other Go programs, without the TUI or the command line interface.
+//
+// Hosts are read from an SSH config file. Every function takes the config file
+// path explicitly; an empty path means the default ~/.ssh/config.
+package sshm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/history"
+	"github.com/Gu1llaum-3/sshm/internal/transfer"
+)
+
+// ErrHostNotFound is returned when a host is not defined in the SSH config
+var ErrHostNotFound = errors.New("host not found in SSH configuration")
+
+// Host is a host defined in an SSH config file
+type Host struct {
+	Name       string // Host alias used to connect
+	Hostname   string // HostName, empty when the alias is the address
+	User       string
+	Port       string
+	Identity   string // IdentityFile
+	ProxyJump  string
+	Tags       []string // Tags from the "# Tags:" comment above the host
+	SourceFile string   // Config file the host is defined in (may be an Include)
+}
+
+// ListHosts returns the hosts defined in configFile and the files it includes.
+// Wildcard patterns are skipped, and a missing file has no hosts.
+func ListHosts(configFile string) ([]Host, error) {
+	var hosts []config.SSHHost
+	var err error
+	if configFile != "" {
+		hosts, err = config.ParseSSHConfigFile(configFile)
+	} else {
+		hosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Host, 0, len(hosts))
+	for _, h := range hosts {
+		result = append(result, Host{
+			Name:       h.Name,
+			Hostname:   h.Hostname,
+			User:       h.User,
+			Port:       h.Port,
+			Identity:   h.Identity,
+			ProxyJump:  h.ProxyJump,
+			Tags:       h.Tags,
+			SourceFile: h.SourceFile,
+		})
+	}
+	return result, nil
+}
+
+// ConnectOptions configures Connect
+type ConnectOptions struct {
+	ConfigFile string   // SSH config file, empty for the default
+	Identity   string   // Private key forced for this connection, overriding the config
+	Command    []string // Remote command to run instead of an interactive shell
+
+	// Streams attached to ssh; nil uses the process's own stdin, stdout and stderr
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// RecordHistory records the connection in sshm's history, as the CLI does
+	RecordHistory bool
+}
+
+// ConnectCommand returns the ssh command that Connect would run, for callers
+// that want to manage the process themselves
+func ConnectCommand(host string, opts ConnectOptions) (*exec.Cmd, error) {
+	if err := checkHost(host, opts.ConfigFile); err != nil {
+		return nil, err
+	}
+
+	args := config.BuildConnectArgs(host, opts.ConfigFile, opts.Identity)
+	args = append(args, opts.Command...)
+
+	cmd := exec.Command("ssh", args...)
+	if err := transfer.CheckCommand(cmd); err != nil {
+		return nil, err
+	}
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	if cmd.Stdin == nil {
+		cmd.Stdin = os.Stdin
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	return cmd, nil
+}
+
+// Connect runs ssh to host and waits for the session to end. A non-zero exit
+// status of ssh or the remote command is returned as an *exec.ExitError.
+func Connect(host string, opts ConnectOptions) error {
+	cmd, err := ConnectCommand(host, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.RecordHistory {
+		historyManager, err := history.NewHistoryManager()
+		if err != nil {
+			return fmt.Errorf("could not initialize connection history: %w", err)
+		}
+		if err := historyManager.RecordConnection(host); err != nil {
+			return fmt.Errorf("could not record connection history: %w", err)
+		}
+	}
+
+	return cmd.Run()
+}
+
+// Transfer types, shared with the transfer engine used by the CLI and the TUI
+type (
+	Direction        = transfer.Direction
+	TransferRequest  = transfer.TransferRequest
+	TransferResult   = transfer.TransferResult
+	Progress         = transfer.Progress
+	ProgressReporter = transfer.ProgressReporter
+)
+
+// Transfer directions
+const (
+	Upload   = transfer.Upload
+	Download = transfer.Download
+)
+
+// NewWriterReporter returns a reporter that prints transfer events to w
+func NewWriterReporter(w io.Writer) ProgressReporter {
+	return transfer.NewWriterReporter(w)
+}
+
+// Transfer copies files with scp as described by req, sending its events to
+// reporter (nil discards them). The error is set whenever the transfer did not
+// succeed; the result is nil only when the request is rejected before scp runs.
+func Transfer(req TransferRequest, reporter ProgressReporter) (*TransferResult, error) {
+	if err := checkHost(req.Host, req.ConfigFile); err != nil {
+		return nil, err
+	}
+	if err := transfer.ValidateOverrides(req.User, req.Port); err != nil {
+		return nil, err
+	}
+	if err := transfer.ValidateLocalPath(req.LocalPath, req.Direction); err != nil {
+		return nil, err
+	}
+
+	result := req.ExecuteWithProgress(reporter)
+	if !result.Success {
+		return result, result.Error
+	}
+	return result, nil
+}
+
+// checkHost returns ErrHostNotFound unless host is defined in configFile
+func checkHost(host, configFile string) error {
+	var found bool
+	var err error
+	if configFile != "" {
+		found, err = config.QuickHostExistsInFile(host, configFile)
+	} else {
+		found, err = config.QuickHostExists(host)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking SSH config: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrHostNotFound, host)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package sshm
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const fixtureConfig = "testdata/ssh_config"
+
+// fakeBinary installs an executable shell script named name first in PATH
+func fakeBinary(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestListHosts(t *testing.T) {
+	hosts, err := ListHosts(fixtureConfig)
+	if err != nil {
+		t.Fatalf("ListHosts() error = %v", err)
+	}
+
+	if len(hosts) != 2 {
+		t.Fatalf("Expected 2 hosts (wildcards excluded), got %+v", hosts)
+	}
+
+	web := hosts[0]
+	if web.Name != "web1" || web.Hostname != "10.0.0.10" || web.User != "deploy" || web.Port != "2222" {
+		t.Errorf("Unexpected web1 host: %+v", web)
+	}
+	if len(web.Tags) != 2 || web.Tags[0] != "production" || web.Tags[1] != "web" {
+		t.Errorf("Expected web1 tags [production web], got %v", web.Tags)
+	}
+	if hosts[1].Name != "db1" || hosts[1].ProxyJump != "web1" {
+		t.Errorf("Unexpected db1 host: %+v", hosts[1])
+	}
+}
+
+func TestListHostsMissingFile(t *testing.T) {
+	hosts, err := ListHosts(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(hosts) != 0 {
+		t.Errorf("ListHosts() = (%v, %v), want no hosts for a missing config file", hosts, err)
+	}
+}
+
+func TestConnectCommand(t *testing.T) {
+	cmd, err := ConnectCommand("web1", ConnectOptions{
+		ConfigFile: fixtureConfig,
+		Identity:   "/keys/id_ed25519",
+		Command:    []string{"uptime"},
+	})
+	if err != nil {
+		t.Fatalf("ConnectCommand() error = %v", err)
+	}
+
+	args := strings.Join(cmd.Args[1:], " ")
+	expected := "-F " + fixtureConfig + " -i /keys/id_ed25519 -o IdentitiesOnly=yes web1 uptime"
+	if args != expected {
+		t.Errorf("ConnectCommand() args = %q, want %q", args, expected)
+	}
+	if cmd.Stdout != os.Stdout {
+		t.Error("Expected nil streams to default to the process streams")
+	}
+}
+
+func TestConnectUnknownHost(t *testing.T) {
+	err := Connect("nope", ConnectOptions{ConfigFile: fixtureConfig})
+	if !errors.Is(err, ErrHostNotFound) {
+		t.Errorf("Connect() error = %v, want ErrHostNotFound", err)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	fakeBinary(t, "ssh", `echo "ssh $*"`)
+
+	var out bytes.Buffer
+	err := Connect("db1", ConnectOptions{
+		ConfigFile:    fixtureConfig,
+		Command:       []string{"hostname"},
+		Stdout:        &out,
+		RecordHistory: true,
+	})
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	if got := strings.TrimSpace(out.String()); got != "ssh -F "+fixtureConfig+" db1 hostname" {
+		t.Errorf("ssh ran with %q", got)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(home, "sshm"))
+	if err != nil || len(entries) == 0 {
+		t.Errorf("Expected the connection to be recorded in history, got %v (%v)", entries, err)
+	}
+}
+
+func TestConnectExitStatus(t *testing.T) {
+	fakeBinary(t, "ssh", "exit 3")
+
+	err := Connect("web1", ConnectOptions{ConfigFile: fixtureConfig, Stdout: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Connect() error = %v, want exit status 3", err)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	fakeBinary(t, "scp", `printf 'notes.txt  100%%  2KB  2.0KB/s   00:00\n'; echo "$@" > "$SCP_ARGS_FILE"`)
+	argsFile := filepath.Join(t.TempDir(), "args")
+	t.Setenv("SCP_ARGS_FILE", argsFile)
+
+	local := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(local, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	result, err := Transfer(TransferRequest{
+		Host:       "web1",
+		Direction:  Upload,
+		LocalPath:  local,
+		RemotePath: "/tmp/",
+		ConfigFile: fixtureConfig,
+	}, NewWriterReporter(&out))
+	if err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+	if !result.Success || result.BytesSent != 2048 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !strings.Contains(out.String(), "notes.txt 100%") || !strings.Contains(out.String(), "Transfer complete") {
+		t.Errorf("Unexpected reporter output: %q", out.String())
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "-F " + fixtureConfig + " " + local + " web1:/tmp/"; strings.TrimSpace(string(args)) != expected {
+		t.Errorf("scp ran with %q, want %q", strings.TrimSpace(string(args)), expected)
+	}
+}
+
+func TestTransferFailure(t *testing.T) {
+	fakeBinary(t, "scp", `echo 'scp: /missing: No such file or directory' >&2; exit 1`)
+
+	result, err := Transfer(TransferRequest{
+		Host:       "web1",
+		Direction:  Download,
+		LocalPath:  t.TempDir(),
+		RemotePath: "/missing",
+		ConfigFile: fixtureConfig,
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "No such file") {
+		t.Errorf("Transfer() error = %v, want scp's message", err)
+	}
+	if result == nil || result.Success {
+		t.Errorf("Expected a failed result, got %+v", result)
+	}
+}
+
+func TestTransferRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		req  TransferRequest
+	}{
+		{"unknown host", TransferRequest{Host: "nope", Direction: Download, LocalPath: ".", RemotePath: "/tmp", ConfigFile: fixtureConfig}},
+		{"invalid port", TransferRequest{Host: "web1", Direction: Download, LocalPath: ".", RemotePath: "/tmp", ConfigFile: fixtureConfig, Port: "99999"}},
+		{"missing upload source", TransferRequest{Host: "web1", Direction: Upload, LocalPath: "/does/not/exist", RemotePath: "/tmp", ConfigFile: fixtureConfig}},
+	}
+
+	for _, tt := range tests {
+		result, err := Transfer(tt.req, nil)
+		if err == nil || result != nil {
+			t.Errorf("%s: Transfer() = (%+v, %v), want a rejection", tt.name, result, err)
+		}
+	}
+}